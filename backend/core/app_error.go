@@ -0,0 +1,87 @@
+package core
+
+import (
+	"errors"
+	"net/http"
+)
+
+// AppError はアプリケーション全体で使用するエラーを表す
+// Codeはクライアントが判別に使う機械可読な識別子、Messageはクライアントに返してよい文言で、
+// 内部の詳細はCauseにのみ保持しレスポンスには含めない
+type AppError struct {
+	Code    string
+	Status  int
+	Message string
+	Cause   error
+}
+
+var (
+	ErrBadRequest      = NewAppError("bad_request", http.StatusBadRequest, "Invalid request")
+	ErrUnauthorized    = NewAppError("unauthorized", http.StatusUnauthorized, "Authentication required")
+	ErrForbidden       = NewAppError("forbidden", http.StatusForbidden, "Insufficient permissions")
+	ErrNotFound        = NewAppError("not_found", http.StatusNotFound, "Resource not found")
+	ErrConflict        = NewAppError("conflict", http.StatusConflict, "Resource conflict")
+	ErrTooManyRequests = NewAppError("too_many_requests", http.StatusTooManyRequests, "Too many requests")
+	ErrInternal        = NewAppError("internal_error", http.StatusInternalServerError, "Internal server error")
+	ErrBadGateway      = NewAppError("bad_gateway", http.StatusBadGateway, "Upstream service error")
+)
+
+// NewAppError は新しいAppErrorを作成する
+func NewAppError(code string, status int, message string) *AppError {
+	return &AppError{
+		Code:    code,
+		Status:  status,
+		Message: message,
+	}
+}
+
+// Error はエラー文字列を返す（ログ用で、原因のエラーも含む）
+func (e *AppError) Error() string {
+	if e.Cause != nil {
+		return e.Code + ": " + e.Message + ": " + e.Cause.Error()
+	}
+	return e.Code + ": " + e.Message
+}
+
+// Unwrap は原因のエラーを返す
+func (e *AppError) Unwrap() error {
+	return e.Cause
+}
+
+// Is はCodeが一致するAppErrorを同じエラーとみなす
+// これによりWrapやWithMessageで派生したエラーもerrors.Isで元の定義と比較できる
+func (e *AppError) Is(target error) bool {
+	var appErr *AppError
+	if !errors.As(target, &appErr) {
+		return false
+	}
+	return e.Code == appErr.Code
+}
+
+// Wrap は原因のエラーを保持した複製を返す
+func (e *AppError) Wrap(cause error) *AppError {
+	wrapped := *e
+	wrapped.Cause = cause
+	return &wrapped
+}
+
+// WithMessage はクライアント向けの文言を差し替えた複製を返す
+func (e *AppError) WithMessage(message string) *AppError {
+	replaced := *e
+	replaced.Message = message
+	return &replaced
+}
+
+// AsAppError はエラーをAppErrorとして取り出す
+// AppErrorでないエラーは内部エラーとして包み、詳細がクライアントに漏れないようにする
+func AsAppError(err error) *AppError {
+	if err == nil {
+		return nil
+	}
+
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return ErrInternal.Wrap(err)
+}
@@ -0,0 +1,66 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppError_Is(t *testing.T) {
+	errInvalidToken := NewAppError("invalid_token", http.StatusUnauthorized, "Invalid token")
+	cause := errors.New("signature is invalid")
+
+	tests := []struct {
+		testName string
+		err      error
+		target   error
+		want     bool
+	}{
+		{testName: "同じ定義", err: errInvalidToken, target: errInvalidToken, want: true},
+		{testName: "Wrapした複製", err: errInvalidToken.Wrap(cause), target: errInvalidToken, want: true},
+		{testName: "WithMessageした複製", err: errInvalidToken.WithMessage("Token expired"), target: errInvalidToken, want: true},
+		{testName: "fmt.Errorfで包んだ場合", err: fmt.Errorf("refresh: %w", errInvalidToken), target: errInvalidToken, want: true},
+		{testName: "原因のエラー", err: errInvalidToken.Wrap(cause), target: cause, want: true},
+		{testName: "異なるコード", err: errInvalidToken, target: ErrUnauthorized, want: false},
+		{testName: "AppError以外", err: errors.New("invalid_token"), target: errInvalidToken, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			assert.Equal(t, tt.want, errors.Is(tt.err, tt.target))
+		})
+	}
+}
+
+func TestAppError_Error(t *testing.T) {
+	err := NewAppError("invalid_token", http.StatusUnauthorized, "Invalid token")
+
+	assert.Equal(t, "invalid_token: Invalid token", err.Error())
+	assert.Equal(t, "invalid_token: Invalid token: expired", err.Wrap(errors.New("expired")).Error())
+	// 複製しても元の定義は変更されない
+	assert.Nil(t, err.Cause)
+}
+
+func TestAsAppError(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		assert.Nil(t, AsAppError(nil))
+	})
+
+	t.Run("AppErrorはそのまま返す", func(t *testing.T) {
+		wrapped := fmt.Errorf("handler: %w", ErrNotFound)
+		assert.Same(t, ErrNotFound, AsAppError(wrapped))
+	})
+
+	t.Run("AppError以外は内部エラーとして包む", func(t *testing.T) {
+		cause := errors.New("pq: connection refused")
+		appErr := AsAppError(cause)
+
+		assert.Equal(t, "internal_error", appErr.Code)
+		assert.Equal(t, http.StatusInternalServerError, appErr.Status)
+		assert.Equal(t, "Internal server error", appErr.Message)
+		assert.ErrorIs(t, appErr, cause)
+	})
+}
@@ -0,0 +1,823 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/url"
+	"os"
+	"stackies-backend/core/logger"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// Environment はアプリケーションの実行環境（プロファイル）を表す
+type Environment string
+
+const (
+	// EnvironmentDevelopment はローカル開発環境
+	EnvironmentDevelopment Environment = "development"
+	// EnvironmentTest はテスト環境
+	EnvironmentTest Environment = "test"
+	// EnvironmentProduction は本番環境
+	EnvironmentProduction Environment = "production"
+)
+
+// minProductionJWTSecretLength は本番環境で要求するJWTシークレットの最小長（HS256の鍵長）
+const minProductionJWTSecretLength = 32
+
+// minSCIMTokenLength はSCIMのトークンの最小長（推測されにくいランダムな値を要求する）
+const minSCIMTokenLength = 32
+
+type (
+	// Config はアプリケーション全体の設定を表す
+	Config struct {
+		Environment Environment     `yaml:"-"`
+		Server      ServerConfig    `yaml:"server"`
+		Log         LogConfig       `yaml:"log"`
+		Auth        AuthConfig      `yaml:"auth"`
+		Google      GoogleConfig    `yaml:"google"`
+		RateLimit   RateLimitConfig `yaml:"rateLimit"`
+		Security    SecurityConfig  `yaml:"security"`
+		SMTP        SMTPConfig      `yaml:"smtp"`
+		Health      HealthConfig    `yaml:"health"`
+		Tracing     TracingConfig   `yaml:"tracing"`
+		Outbound    OutboundConfig  `yaml:"outbound"`
+		Storage     StorageConfig   `yaml:"storage"`
+		Avatar      AvatarConfig    `yaml:"avatar"`
+		Account     AccountConfig   `yaml:"account"`
+		Events      EventsConfig    `yaml:"events"`
+		Webhook     WebhookConfig   `yaml:"webhook"`
+		SCIM        SCIMConfig      `yaml:"scim"`
+	}
+
+	// ServerConfig はHTTPサーバーの設定を表す
+	ServerConfig struct {
+		Port             int      `yaml:"port"`
+		CORSAllowOrigins []string `yaml:"corsAllowOrigins"`
+		// ReadHeaderTimeout・ReadTimeout・WriteTimeout・IdleTimeoutはhttp.Serverのタイムアウト
+		ReadHeaderTimeout time.Duration `yaml:"readHeaderTimeout"`
+		ReadTimeout       time.Duration `yaml:"readTimeout"`
+		WriteTimeout      time.Duration `yaml:"writeTimeout"`
+		IdleTimeout       time.Duration `yaml:"idleTimeout"`
+		// ShutdownTimeout は停止時に処理中のリクエストの完了と接続のクローズを待つ最大時間
+		ShutdownTimeout time.Duration `yaml:"shutdownTimeout"`
+		// ShutdownHookTimeout はリクエストの処理が完了した後、停止処理（リポジトリのクローズなど）をそれぞれ待つ最大時間
+		ShutdownHookTimeout time.Duration `yaml:"shutdownHookTimeout"`
+		// TLSCertFile・TLSKeyFileを指定した場合はHTTPSで待ち受ける
+		TLSCertFile string `yaml:"tlsCertFile"`
+		TLSKeyFile  string `yaml:"tlsKeyFile"`
+		// MetricsToken を指定した場合は/metricsの取得にBearerトークンを要求する
+		MetricsToken string `yaml:"metricsToken"`
+		// OpenAPIResponseValidation はレスポンスをOpenAPIドキュメントで検証する方法（off, log, strict）
+		OpenAPIResponseValidation string `yaml:"openAPIResponseValidation"`
+		// BodyLimit はリクエストボディの最大サイズ（バイト）。超える場合は読み込まずに413を返す
+		BodyLimit int `yaml:"bodyLimit"`
+		// TrustedProxies はX-Forwarded-Forを信頼するリバースプロキシのCIDR
+		// 空の場合は接続元のアドレスをクライアントのIPアドレスとする（レート制限・監査ログに使う）
+		TrustedProxies []string `yaml:"trustedProxies"`
+	}
+
+	// LogConfig はログ出力の設定を表す
+	LogConfig struct {
+		Level string `yaml:"level"`
+	}
+
+	// AuthConfig は認証の設定を表す
+	AuthConfig struct {
+		JWTSecret string `yaml:"jwtSecret"`
+		// JWTPreviousSecrets はローテーション前のJWTシークレット（発行済みのトークンの検証にのみ使う）
+		JWTPreviousSecrets []string `yaml:"jwtPreviousSecrets"`
+		// AdminEmails はログイン時に管理者ロールを付与するメールアドレス
+		AdminEmails []string `yaml:"adminEmails"`
+		// CallbackMode はOAuthコールバック後にトークンを渡す方式（cookie または code）
+		CallbackMode string `yaml:"callbackMode"`
+		// FrontendRedirectURLs はOAuthコールバック後の戻り先として許可するURL（先頭がデフォルト）
+		FrontendRedirectURLs []string `yaml:"frontendRedirectURLs"`
+		// ImpersonationTTL は管理者がなりすましに使うアクセストークンの有効期間（最大1時間）
+		ImpersonationTTL time.Duration `yaml:"impersonationTTL"`
+	}
+
+	// GoogleConfig はGoogle OAuthの設定を表す
+	GoogleConfig struct {
+		ClientID     string `yaml:"clientID"`
+		ClientSecret string `yaml:"clientSecret"`
+		RedirectURL  string `yaml:"redirectURL"`
+		// EndpointBaseURL を指定した場合はGoogleの代わりにそのURLのfake IdPを使用する（開発・E2Eテスト用）
+		EndpointBaseURL string `yaml:"endpointBaseURL"`
+	}
+
+	// RateLimitConfig はレート制限の設定を表す
+	// 各ルールは "strategy:limit/window" 形式（例: token_bucket:30/1m）
+	RateLimitConfig struct {
+		Backend  string `yaml:"backend"`
+		RedisURL string `yaml:"redisURL"`
+		AuthURL  string `yaml:"authURL"`
+		Login    string `yaml:"login"`
+		Client   string `yaml:"client"`
+		Refresh  string `yaml:"refresh"`
+		User     string `yaml:"user"`
+	}
+
+	// SecurityConfig は不審なログインの検知の設定を表す
+	SecurityConfig struct {
+		GeoIPDBPath        string `yaml:"geoIPDBPath"`
+		LoginAlertNotifier string `yaml:"loginAlertNotifier"`
+		RevokeURL          string `yaml:"revokeURL"`
+	}
+
+	// HealthConfig はヘルスチェック（/livez・/readyz）の設定を表す
+	HealthConfig struct {
+		// CacheTTL は各チェックの結果を再利用する時間（オーケストレーターからの頻繁な確認で依存先に負荷をかけない）
+		CacheTTL time.Duration `yaml:"cacheTTL"`
+		// CheckTimeout は1つのチェックの最大実行時間
+		CheckTimeout time.Duration `yaml:"checkTimeout"`
+		// GoogleProbeURL はGoogleへの疎通確認に使用するURL（空の場合は確認しない）
+		GoogleProbeURL string `yaml:"googleProbeURL"`
+	}
+
+	// TracingConfig はOpenTelemetryのトレースの設定を表す
+	TracingConfig struct {
+		// Exporter はトレースの出力先（none, stdout, otlp）
+		Exporter    string `yaml:"exporter"`
+		ServiceName string `yaml:"serviceName"`
+		// OTLPEndpoint はOTLP/HTTPの送信先URL（例: http://localhost:4318/v1/traces）
+		// 空の場合はOTEL_EXPORTER_OTLP_ENDPOINTなどの標準の環境変数に従う
+		OTLPEndpoint string `yaml:"otlpEndpoint"`
+		// SampleRatio はトレースを記録するリクエストの割合（0〜1）
+		SampleRatio float64 `yaml:"sampleRatio"`
+	}
+
+	// OutboundConfig はGoogleなど外部サービスへのHTTP呼び出しの設定を表す
+	OutboundConfig struct {
+		// Timeout は再試行を含む1回の呼び出し全体の最大時間
+		Timeout time.Duration `yaml:"timeout"`
+		// DialTimeout・ResponseHeaderTimeoutは接続・レスポンスヘッダー受信の最大時間
+		DialTimeout           time.Duration `yaml:"dialTimeout"`
+		ResponseHeaderTimeout time.Duration `yaml:"responseHeaderTimeout"`
+		// MaxIdleConnsPerHost・IdleConnTimeoutは接続プールの設定
+		MaxIdleConnsPerHost int           `yaml:"maxIdleConnsPerHost"`
+		IdleConnTimeout     time.Duration `yaml:"idleConnTimeout"`
+		// MaxRetries は冪等なリクエストを再試行する最大回数
+		MaxRetries     int           `yaml:"maxRetries"`
+		RetryBaseDelay time.Duration `yaml:"retryBaseDelay"`
+		RetryMaxDelay  time.Duration `yaml:"retryMaxDelay"`
+		// BreakerThreshold は外部サービスへの呼び出しを遮断するまでの連続した失敗数（0の場合は遮断しない）
+		BreakerThreshold int           `yaml:"breakerThreshold"`
+		BreakerCooldown  time.Duration `yaml:"breakerCooldown"`
+	}
+
+	// StorageConfig はアップロードした画像などの保存先の設定を表す
+	StorageConfig struct {
+		// Backend は保存先（local または s3）
+		Backend string `yaml:"backend"`
+		// LocalDir はlocalの場合に保存するディレクトリ
+		LocalDir string `yaml:"localDir"`
+		// S3Endpoint はS3互換ストレージのURL（例: https://s3.ap-northeast-1.amazonaws.com, http://localhost:9000）
+		S3Endpoint        string `yaml:"s3Endpoint"`
+		S3Region          string `yaml:"s3Region"`
+		S3Bucket          string `yaml:"s3Bucket"`
+		S3AccessKeyID     string `yaml:"s3AccessKeyID"`
+		S3SecretAccessKey string `yaml:"s3SecretAccessKey"`
+		// S3PathStyle はバケット名をパスに含める（MinIOなど）
+		S3PathStyle bool `yaml:"s3PathStyle"`
+		// S3PublicBaseURL を指定した場合はCDNなどの公開URLにリダイレクトする（指定しない場合は署名付きURL）
+		S3PublicBaseURL string `yaml:"s3PublicBaseURL"`
+		// URLExpiry は署名付きURLの有効期限
+		URLExpiry time.Duration `yaml:"urlExpiry"`
+	}
+
+	// AvatarConfig はプロフィール画像のアップロードの設定を表す
+	AvatarConfig struct {
+		// MaxUploadBytes はアップロードできる画像の最大サイズ（バイト）
+		MaxUploadBytes int `yaml:"maxUploadBytes"`
+		// BaseURL はプロフィール画像のURLに使うバックエンドの公開URL（例: https://api.example.com）
+		BaseURL string `yaml:"baseURL"`
+	}
+
+	// AccountConfig はアカウントの削除とデータのエクスポートの設定を表す
+	AccountConfig struct {
+		// RecentAuthMaxAge はアカウントの削除などに必要な直近のログインからの最大経過時間
+		RecentAuthMaxAge time.Duration `yaml:"recentAuthMaxAge"`
+		// DeletionGracePeriod は削除したアカウントを完全に消去するまでの猶予期間（0の場合は次回の消去で消去する）
+		DeletionGracePeriod time.Duration `yaml:"deletionGracePeriod"`
+		// PurgeInterval は削除したアカウントと期限切れのエクスポートを消去する間隔（0の場合は消去しない）
+		PurgeInterval time.Duration `yaml:"purgeInterval"`
+		// ExportTTL はエクスポートしたアーカイブをダウンロードできる期間
+		ExportTTL time.Duration `yaml:"exportTTL"`
+	}
+
+	// EventsConfig はドメインイベントの配信の設定を表す
+	EventsConfig struct {
+		// DispatchInterval はアウトボックスのイベントを購読者に配信する間隔（0の場合は配信しない）
+		DispatchInterval time.Duration `yaml:"dispatchInterval"`
+	}
+
+	// WebhookConfig は外部サービスへのWebhookの配信の設定を表す
+	WebhookConfig struct {
+		// MaxAttempts は配信をデッドレターとするまでに送信を試みる回数
+		MaxAttempts int `yaml:"maxAttempts"`
+		// DeliveryInterval は配信待ちのWebhookを送信する間隔（0の場合は送信しない）
+		DeliveryInterval time.Duration `yaml:"deliveryInterval"`
+		// DeliveryRetention は成功した配信を保持する期間（0の場合は削除しない）
+		DeliveryRetention time.Duration `yaml:"deliveryRetention"`
+		// AllowPrivateTargets はhttpとループバック・プライベートアドレスへの送信を許可するかどうか（開発環境向け、本番では指定できない）
+		AllowPrivateTargets bool `yaml:"allowPrivateTargets"`
+	}
+
+	// SCIMConfig は連携元のディレクトリからのSCIMによるプロビジョニングの設定を表す
+	SCIMConfig struct {
+		// Token はSCIMのリクエストに要求するBearerトークン（空の場合はSCIMのエンドポイントを無効にする）
+		Token string `yaml:"token"`
+		// AdminGroup はメンバーに管理者ロールを付与するグループの表示名（空の場合はグループでロールを変更しない）
+		AdminGroup string `yaml:"adminGroup"`
+	}
+
+	// SMTPConfig はメール送信の設定を表す
+	SMTPConfig struct {
+		Addr     string `yaml:"addr"`
+		From     string `yaml:"from"`
+		Username string `yaml:"username"`
+		Password string `yaml:"password"`
+	}
+)
+
+// fileConfig は設定ファイルの構造を表す
+// profilesに環境ごとの差分を記述でき、共通の設定の後に上書きされる
+type fileConfig struct {
+	Config   `yaml:",inline"`
+	Profiles map[Environment]yaml.Node `yaml:"profiles"`
+}
+
+// Load は設定を読み込み、検証する
+// 環境（プロファイル）は環境変数ENVIRONMENTで指定する（デフォルトはdevelopment）
+// 優先順位は 環境変数 > .env > 設定ファイル（CONFIG_FILE、任意）> 環境ごとのデフォルト値
+func Load() (*Config, error) {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to load .env: %w", err)
+	}
+	return load(os.LookupEnv)
+}
+
+// load は指定された環境変数の取得関数から設定を読み込み、検証する
+func load(lookupEnv func(string) (string, bool)) (*Config, error) {
+	env := EnvironmentDevelopment
+	if value, ok := lookupEnv("ENVIRONMENT"); ok && value != "" {
+		env = Environment(value)
+	}
+
+	cfg := defaults(env)
+
+	if path, ok := lookupEnv("CONFIG_FILE"); ok && path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cfg.applyEnv(lookupEnv); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// defaults は環境ごとのデフォルト値を返す
+func defaults(env Environment) *Config {
+	cfg := &Config{
+		Environment: env,
+		Server: ServerConfig{
+			Port:              8080,
+			CORSAllowOrigins:  []string{"http://localhost:3000", "http://localhost:5173"},
+			ReadHeaderTimeout: 5 * time.Second,
+			ReadTimeout:       10 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       120 * time.Second,
+			ShutdownTimeout:   20 * time.Second,
+			// ShutdownTimeoutとは別に、停止処理ごとに待つ
+			ShutdownHookTimeout: 10 * time.Second,
+
+			OpenAPIResponseValidation: "log",
+			BodyLimit:                 8 << 20,
+		},
+		Log: LogConfig{Level: "info"},
+		Auth: AuthConfig{
+			CallbackMode:         "code",
+			FrontendRedirectURLs: []string{"http://localhost:5173/", "http://localhost:3000/"},
+			ImpersonationTTL:     15 * time.Minute,
+		},
+		Google: GoogleConfig{
+			RedirectURL: "http://localhost:8080/auth/google/callback",
+		},
+		RateLimit: RateLimitConfig{
+			Backend: "memory",
+			AuthURL: "token_bucket:30/1m",
+			Login:   "sliding_window:10/1m",
+			Client:  "sliding_window:300/1m",
+			Refresh: "token_bucket:30/1m",
+			User:    "token_bucket:120/1m",
+		},
+		Security: SecurityConfig{
+			LoginAlertNotifier: "log",
+			RevokeURL:          "http://localhost:5173/security/not-me",
+		},
+		Health: HealthConfig{
+			CacheTTL:       5 * time.Second,
+			CheckTimeout:   2 * time.Second,
+			GoogleProbeURL: "https://accounts.google.com/.well-known/openid-configuration",
+		},
+		Outbound: OutboundConfig{
+			Timeout:               10 * time.Second,
+			DialTimeout:           3 * time.Second,
+			ResponseHeaderTimeout: 5 * time.Second,
+			MaxIdleConnsPerHost:   10,
+			IdleConnTimeout:       90 * time.Second,
+			MaxRetries:            2,
+			RetryBaseDelay:        100 * time.Millisecond,
+			RetryMaxDelay:         time.Second,
+			BreakerThreshold:      5,
+			BreakerCooldown:       30 * time.Second,
+		},
+		Storage: StorageConfig{
+			Backend:   "local",
+			LocalDir:  "data/blobs",
+			URLExpiry: 15 * time.Minute,
+		},
+		Avatar: AvatarConfig{
+			MaxUploadBytes: 5 << 20,
+			BaseURL:        "http://localhost:8080",
+		},
+		Account: AccountConfig{
+			RecentAuthMaxAge:    10 * time.Minute,
+			DeletionGracePeriod: 30 * 24 * time.Hour,
+			PurgeInterval:       time.Hour,
+			ExportTTL:           24 * time.Hour,
+		},
+		Events: EventsConfig{
+			DispatchInterval: time.Second,
+		},
+		Webhook: WebhookConfig{
+			MaxAttempts:       8,
+			DeliveryInterval:  time.Second,
+			DeliveryRetention: 7 * 24 * time.Hour,
+		},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			ServiceName: "stackies-backend",
+			SampleRatio: 1,
+		},
+	}
+
+	switch env {
+	case EnvironmentDevelopment:
+		cfg.Log.Level = "debug"
+		// ローカルで動かす受信側に送信できるようにする
+		cfg.Webhook.AllowPrivateTargets = true
+	case EnvironmentTest:
+		// テストでは外部への疎通確認を行わない
+		cfg.Health.GoogleProbeURL = ""
+		// テストではOpenAPIドキュメントと一致しないレスポンスを失敗させる
+		cfg.Server.OpenAPIResponseValidation = "strict"
+	case EnvironmentProduction:
+		// 本番ではローカル開発用のURLを既定にしない
+		cfg.Server.CORSAllowOrigins = nil
+		cfg.Auth.FrontendRedirectURLs = nil
+		cfg.Google.RedirectURL = ""
+		cfg.Security.RevokeURL = ""
+		cfg.Avatar.BaseURL = ""
+	}
+	return cfg
+}
+
+// loadFile はYAMLの設定ファイルを読み込み、現在の環境のプロファイルを重ねる
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	file := fileConfig{Config: *c}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	*c = file.Config
+
+	if profile, ok := file.Profiles[c.Environment]; ok {
+		if err := profile.Decode(c); err != nil {
+			return fmt.Errorf("failed to parse profile %s in %s: %w", c.Environment, path, err)
+		}
+	}
+	return nil
+}
+
+// applyEnv は環境変数で設定を上書きする
+func (c *Config) applyEnv(lookupEnv func(string) (string, bool)) error {
+	var errs []error
+
+	setString := func(target *string, key string, aliases ...string) {
+		value, err := lookupWithAliases(lookupEnv, key, aliases...)
+		if err != nil {
+			errs = append(errs, err)
+			return
+		}
+		if value != "" {
+			*target = value
+		}
+	}
+	setList := func(target *[]string, key string) {
+		if value, ok := lookupEnv(key); ok && value != "" {
+			*target = splitList(value)
+		}
+	}
+	setDuration := func(target *time.Duration, key string) {
+		if value, ok := lookupEnv(key); ok && value != "" {
+			duration, err := time.ParseDuration(value)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s must be a duration such as 30s: %q", key, value))
+				return
+			}
+			*target = duration
+		}
+	}
+
+	setFloat := func(target *float64, key string) {
+		if value, ok := lookupEnv(key); ok && value != "" {
+			number, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s must be a number: %q", key, value))
+				return
+			}
+			*target = number
+		}
+	}
+
+	setInt := func(target *int, key string) {
+		if value, ok := lookupEnv(key); ok && value != "" {
+			number, err := strconv.Atoi(value)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s must be a number: %q", key, value))
+				return
+			}
+			*target = number
+		}
+	}
+
+	setBool := func(target *bool, key string) {
+		if value, ok := lookupEnv(key); ok && value != "" {
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s must be true or false: %q", key, value))
+				return
+			}
+			*target = b
+		}
+	}
+
+	if value, ok := lookupEnv("PORT"); ok && value != "" {
+		port, err := strconv.Atoi(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("PORT must be a number: %q", value))
+		} else {
+			c.Server.Port = port
+		}
+	}
+	setList(&c.Server.CORSAllowOrigins, "CORS_ALLOW_ORIGINS")
+	setList(&c.Server.TrustedProxies, "TRUSTED_PROXIES")
+	setDuration(&c.Server.ReadHeaderTimeout, "SERVER_READ_HEADER_TIMEOUT")
+	setDuration(&c.Server.ReadTimeout, "SERVER_READ_TIMEOUT")
+	setDuration(&c.Server.WriteTimeout, "SERVER_WRITE_TIMEOUT")
+	setDuration(&c.Server.IdleTimeout, "SERVER_IDLE_TIMEOUT")
+	setDuration(&c.Server.ShutdownTimeout, "SERVER_SHUTDOWN_TIMEOUT")
+	setDuration(&c.Server.ShutdownHookTimeout, "SERVER_SHUTDOWN_HOOK_TIMEOUT")
+	setString(&c.Server.TLSCertFile, "TLS_CERT_FILE")
+	setString(&c.Server.TLSKeyFile, "TLS_KEY_FILE")
+	setString(&c.Server.MetricsToken, "METRICS_TOKEN")
+	setString(&c.Server.OpenAPIResponseValidation, "OPENAPI_RESPONSE_VALIDATION")
+	setInt(&c.Server.BodyLimit, "SERVER_BODY_LIMIT")
+	setString(&c.Log.Level, "LOG_LEVEL")
+
+	// JWT_SECRET_KEY・GOOGLE_REDIRECT_URIは旧名として引き続き受け付ける
+	setString(&c.Auth.JWTSecret, "JWT_SECRET", "JWT_SECRET_KEY")
+	setList(&c.Auth.JWTPreviousSecrets, "JWT_PREVIOUS_SECRETS")
+	setList(&c.Auth.AdminEmails, "ADMIN_EMAILS")
+	setString(&c.Auth.CallbackMode, "AUTH_CALLBACK_MODE")
+	setList(&c.Auth.FrontendRedirectURLs, "FRONTEND_REDIRECT_URLS")
+	setDuration(&c.Auth.ImpersonationTTL, "AUTH_IMPERSONATION_TTL")
+
+	setString(&c.Google.ClientID, "GOOGLE_CLIENT_ID")
+	setString(&c.Google.ClientSecret, "GOOGLE_CLIENT_SECRET")
+	setString(&c.Google.RedirectURL, "GOOGLE_REDIRECT_URL", "GOOGLE_REDIRECT_URI")
+	setString(&c.Google.EndpointBaseURL, "GOOGLE_ENDPOINT_BASE_URL")
+
+	setString(&c.RateLimit.Backend, "RATE_LIMIT_BACKEND")
+	setString(&c.RateLimit.RedisURL, "REDIS_URL")
+	setString(&c.RateLimit.AuthURL, "RATE_LIMIT_AUTH_URL")
+	setString(&c.RateLimit.Login, "RATE_LIMIT_AUTH_LOGIN")
+	setString(&c.RateLimit.Client, "RATE_LIMIT_AUTH_CLIENT")
+	setString(&c.RateLimit.Refresh, "RATE_LIMIT_AUTH_REFRESH")
+	setString(&c.RateLimit.User, "RATE_LIMIT_AUTH_USER")
+
+	setString(&c.Security.GeoIPDBPath, "GEOIP_DB_PATH")
+	setString(&c.Security.LoginAlertNotifier, "LOGIN_ALERT_NOTIFIER")
+	setString(&c.Security.RevokeURL, "SECURITY_REVOKE_URL")
+
+	setString(&c.SMTP.Addr, "SMTP_ADDR")
+	setString(&c.SMTP.From, "SMTP_FROM")
+	setString(&c.SMTP.Username, "SMTP_USERNAME")
+	setString(&c.SMTP.Password, "SMTP_PASSWORD")
+
+	setDuration(&c.Health.CacheTTL, "HEALTH_CACHE_TTL")
+	setDuration(&c.Health.CheckTimeout, "HEALTH_CHECK_TIMEOUT")
+	setString(&c.Health.GoogleProbeURL, "HEALTH_GOOGLE_PROBE_URL")
+
+	setDuration(&c.Outbound.Timeout, "OUTBOUND_TIMEOUT")
+	setDuration(&c.Outbound.DialTimeout, "OUTBOUND_DIAL_TIMEOUT")
+	setDuration(&c.Outbound.ResponseHeaderTimeout, "OUTBOUND_RESPONSE_HEADER_TIMEOUT")
+	setInt(&c.Outbound.MaxIdleConnsPerHost, "OUTBOUND_MAX_IDLE_CONNS_PER_HOST")
+	setDuration(&c.Outbound.IdleConnTimeout, "OUTBOUND_IDLE_CONN_TIMEOUT")
+	setInt(&c.Outbound.MaxRetries, "OUTBOUND_MAX_RETRIES")
+	setDuration(&c.Outbound.RetryBaseDelay, "OUTBOUND_RETRY_BASE_DELAY")
+	setDuration(&c.Outbound.RetryMaxDelay, "OUTBOUND_RETRY_MAX_DELAY")
+	setInt(&c.Outbound.BreakerThreshold, "OUTBOUND_BREAKER_THRESHOLD")
+	setDuration(&c.Outbound.BreakerCooldown, "OUTBOUND_BREAKER_COOLDOWN")
+
+	setString(&c.Storage.Backend, "STORAGE_BACKEND")
+	setString(&c.Storage.LocalDir, "STORAGE_LOCAL_DIR")
+	setString(&c.Storage.S3Endpoint, "S3_ENDPOINT")
+	setString(&c.Storage.S3Region, "S3_REGION")
+	setString(&c.Storage.S3Bucket, "S3_BUCKET")
+	setString(&c.Storage.S3AccessKeyID, "S3_ACCESS_KEY_ID")
+	setString(&c.Storage.S3SecretAccessKey, "S3_SECRET_ACCESS_KEY")
+	setBool(&c.Storage.S3PathStyle, "S3_PATH_STYLE")
+	setString(&c.Storage.S3PublicBaseURL, "S3_PUBLIC_BASE_URL")
+	setDuration(&c.Storage.URLExpiry, "STORAGE_URL_EXPIRY")
+
+	setInt(&c.Avatar.MaxUploadBytes, "AVATAR_MAX_UPLOAD_BYTES")
+	setString(&c.Avatar.BaseURL, "AVATAR_BASE_URL")
+
+	setDuration(&c.Account.RecentAuthMaxAge, "ACCOUNT_RECENT_AUTH_MAX_AGE")
+	setDuration(&c.Account.DeletionGracePeriod, "ACCOUNT_DELETION_GRACE_PERIOD")
+	setDuration(&c.Account.PurgeInterval, "ACCOUNT_PURGE_INTERVAL")
+	setDuration(&c.Account.ExportTTL, "ACCOUNT_EXPORT_TTL")
+
+	setDuration(&c.Events.DispatchInterval, "EVENTS_DISPATCH_INTERVAL")
+
+	setInt(&c.Webhook.MaxAttempts, "WEBHOOK_MAX_ATTEMPTS")
+	setDuration(&c.Webhook.DeliveryInterval, "WEBHOOK_DELIVERY_INTERVAL")
+	setDuration(&c.Webhook.DeliveryRetention, "WEBHOOK_DELIVERY_RETENTION")
+	setBool(&c.Webhook.AllowPrivateTargets, "WEBHOOK_ALLOW_PRIVATE_TARGETS")
+
+	setString(&c.SCIM.Token, "SCIM_TOKEN")
+	setString(&c.SCIM.AdminGroup, "SCIM_ADMIN_GROUP")
+
+	setString(&c.Tracing.Exporter, "TRACING_EXPORTER")
+	setString(&c.Tracing.ServiceName, "TRACING_SERVICE_NAME")
+	setString(&c.Tracing.OTLPEndpoint, "TRACING_OTLP_ENDPOINT")
+	setFloat(&c.Tracing.SampleRatio, "TRACING_SAMPLE_RATIO")
+
+	return errors.Join(errs...)
+}
+
+// lookupWithAliases は環境変数を旧名も含めて取得する
+// 新旧の名前に異なる値が設定されている場合はエラーとする
+func lookupWithAliases(lookupEnv func(string) (string, bool), key string, aliases ...string) (string, error) {
+	value, _ := lookupEnv(key)
+	for _, alias := range aliases {
+		aliasValue, _ := lookupEnv(alias)
+		if aliasValue == "" {
+			continue
+		}
+		if value != "" && value != aliasValue {
+			return "", fmt.Errorf("%s and %s are both set with different values", key, alias)
+		}
+		value = aliasValue
+	}
+	return value, nil
+}
+
+// splitList はカンマ区切りの値を分割する（空要素は除く）
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Validate は設定値を検証する（起動時に全ての問題をまとめて返す）
+func (c *Config) Validate() error {
+	var errs []error
+
+	switch c.Environment {
+	case EnvironmentDevelopment, EnvironmentTest, EnvironmentProduction:
+	default:
+		errs = append(errs, fmt.Errorf("ENVIRONMENT must be one of development, test, production: %q", c.Environment))
+	}
+
+	if c.Server.Port <= 0 || c.Server.Port > 65535 {
+		errs = append(errs, fmt.Errorf("PORT must be between 1 and 65535: %d", c.Server.Port))
+	}
+	timeouts := []struct {
+		key   string
+		value time.Duration
+	}{
+		{"SERVER_READ_HEADER_TIMEOUT", c.Server.ReadHeaderTimeout},
+		{"SERVER_READ_TIMEOUT", c.Server.ReadTimeout},
+		{"SERVER_WRITE_TIMEOUT", c.Server.WriteTimeout},
+		{"SERVER_IDLE_TIMEOUT", c.Server.IdleTimeout},
+		{"SERVER_SHUTDOWN_TIMEOUT", c.Server.ShutdownTimeout},
+		{"SERVER_SHUTDOWN_HOOK_TIMEOUT", c.Server.ShutdownHookTimeout},
+		{"HEALTH_CHECK_TIMEOUT", c.Health.CheckTimeout},
+		{"OUTBOUND_TIMEOUT", c.Outbound.Timeout},
+		{"OUTBOUND_DIAL_TIMEOUT", c.Outbound.DialTimeout},
+		{"OUTBOUND_RESPONSE_HEADER_TIMEOUT", c.Outbound.ResponseHeaderTimeout},
+		{"OUTBOUND_IDLE_CONN_TIMEOUT", c.Outbound.IdleConnTimeout},
+		{"OUTBOUND_RETRY_BASE_DELAY", c.Outbound.RetryBaseDelay},
+		{"OUTBOUND_RETRY_MAX_DELAY", c.Outbound.RetryMaxDelay},
+		{"OUTBOUND_BREAKER_COOLDOWN", c.Outbound.BreakerCooldown},
+		{"STORAGE_URL_EXPIRY", c.Storage.URLExpiry},
+		{"ACCOUNT_RECENT_AUTH_MAX_AGE", c.Account.RecentAuthMaxAge},
+		{"ACCOUNT_EXPORT_TTL", c.Account.ExportTTL},
+		{"AUTH_IMPERSONATION_TTL", c.Auth.ImpersonationTTL},
+	}
+	for _, timeout := range timeouts {
+		if timeout.value <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be positive: %s", timeout.key, timeout.value))
+		}
+	}
+	if c.Auth.ImpersonationTTL > time.Hour {
+		errs = append(errs, fmt.Errorf("AUTH_IMPERSONATION_TTL must not exceed 1h: %s", c.Auth.ImpersonationTTL))
+	}
+	if c.Health.CacheTTL < 0 {
+		errs = append(errs, fmt.Errorf("HEALTH_CACHE_TTL must not be negative: %s", c.Health.CacheTTL))
+	}
+	if c.Health.GoogleProbeURL != "" && !isAbsoluteURL(c.Health.GoogleProbeURL) {
+		errs = append(errs, fmt.Errorf("HEALTH_GOOGLE_PROBE_URL must be an absolute URL: %q", c.Health.GoogleProbeURL))
+	}
+	if c.Outbound.MaxRetries < 0 || c.Outbound.BreakerThreshold < 0 || c.Outbound.MaxIdleConnsPerHost < 0 {
+		errs = append(errs, errors.New("OUTBOUND_MAX_RETRIES, OUTBOUND_BREAKER_THRESHOLD and OUTBOUND_MAX_IDLE_CONNS_PER_HOST must not be negative"))
+	}
+	switch c.Server.OpenAPIResponseValidation {
+	case "off", "log", "strict":
+	default:
+		errs = append(errs, fmt.Errorf("OPENAPI_RESPONSE_VALIDATION must be off, log or strict: %q", c.Server.OpenAPIResponseValidation))
+	}
+	switch c.Tracing.Exporter {
+	case "none", "stdout", "otlp":
+	default:
+		errs = append(errs, fmt.Errorf("TRACING_EXPORTER must be none, stdout or otlp: %q", c.Tracing.Exporter))
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		errs = append(errs, fmt.Errorf("TRACING_SAMPLE_RATIO must be between 0 and 1: %v", c.Tracing.SampleRatio))
+	}
+	if c.Tracing.OTLPEndpoint != "" && !isAbsoluteURL(c.Tracing.OTLPEndpoint) {
+		errs = append(errs, fmt.Errorf("TRACING_OTLP_ENDPOINT must be an absolute URL: %q", c.Tracing.OTLPEndpoint))
+	}
+	for _, proxy := range c.Server.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil {
+			errs = append(errs, fmt.Errorf("TRUSTED_PROXIES contains an invalid CIDR: %q", proxy))
+		}
+	}
+	if (c.Server.TLSCertFile == "") != (c.Server.TLSKeyFile == "") {
+		errs = append(errs, errors.New("TLS_CERT_FILE and TLS_KEY_FILE must be set together"))
+	}
+	if _, err := logger.ParseLevel(c.Log.Level); err != nil {
+		errs = append(errs, fmt.Errorf("LOG_LEVEL is invalid: %q", c.Log.Level))
+	}
+
+	if c.Auth.JWTSecret == "" {
+		errs = append(errs, errors.New("JWT_SECRET is required"))
+	} else if c.Environment == EnvironmentProduction && len(c.Auth.JWTSecret) < minProductionJWTSecretLength {
+		errs = append(errs, fmt.Errorf("JWT_SECRET must be at least %d bytes in production", minProductionJWTSecretLength))
+	}
+	for _, previous := range c.Auth.JWTPreviousSecrets {
+		if previous == c.Auth.JWTSecret {
+			errs = append(errs, errors.New("JWT_PREVIOUS_SECRETS must not contain JWT_SECRET"))
+			break
+		}
+	}
+	if c.Auth.CallbackMode != "cookie" && c.Auth.CallbackMode != "code" {
+		errs = append(errs, fmt.Errorf("AUTH_CALLBACK_MODE must be cookie or code: %q", c.Auth.CallbackMode))
+	}
+	if len(c.Auth.FrontendRedirectURLs) == 0 {
+		errs = append(errs, errors.New("FRONTEND_REDIRECT_URLS is required"))
+	}
+	for _, redirectURL := range c.Auth.FrontendRedirectURLs {
+		if !isAbsoluteURL(redirectURL) {
+			errs = append(errs, fmt.Errorf("FRONTEND_REDIRECT_URLS contains an invalid URL: %q", redirectURL))
+		}
+	}
+
+	if c.Environment == EnvironmentProduction {
+		if c.Google.ClientID == "" || c.Google.ClientSecret == "" {
+			errs = append(errs, errors.New("GOOGLE_CLIENT_ID and GOOGLE_CLIENT_SECRET are required in production"))
+		}
+		if c.Google.EndpointBaseURL != "" {
+			errs = append(errs, errors.New("GOOGLE_ENDPOINT_BASE_URL must not be set in production"))
+		}
+	}
+	if c.Google.EndpointBaseURL != "" && !isAbsoluteURL(c.Google.EndpointBaseURL) {
+		errs = append(errs, fmt.Errorf("GOOGLE_ENDPOINT_BASE_URL must be an absolute URL: %q", c.Google.EndpointBaseURL))
+	}
+	if !isAbsoluteURL(c.Google.RedirectURL) {
+		errs = append(errs, fmt.Errorf("GOOGLE_REDIRECT_URL must be an absolute URL: %q", c.Google.RedirectURL))
+	}
+
+	switch c.RateLimit.Backend {
+	case "memory":
+	case "redis":
+		if c.RateLimit.RedisURL == "" {
+			errs = append(errs, errors.New("REDIS_URL is required when RATE_LIMIT_BACKEND is redis"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("RATE_LIMIT_BACKEND must be memory or redis: %q", c.RateLimit.Backend))
+	}
+
+	switch c.Security.LoginAlertNotifier {
+	case "log":
+	case "smtp":
+		if c.SMTP.Addr == "" || c.SMTP.From == "" {
+			errs = append(errs, errors.New("SMTP_ADDR and SMTP_FROM are required when LOGIN_ALERT_NOTIFIER is smtp"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("LOGIN_ALERT_NOTIFIER must be log or smtp: %q", c.Security.LoginAlertNotifier))
+	}
+	if !isAbsoluteURL(c.Security.RevokeURL) {
+		errs = append(errs, fmt.Errorf("SECURITY_REVOKE_URL must be an absolute URL: %q", c.Security.RevokeURL))
+	}
+
+	switch c.Storage.Backend {
+	case "local":
+		if c.Storage.LocalDir == "" {
+			errs = append(errs, errors.New("STORAGE_LOCAL_DIR is required when STORAGE_BACKEND is local"))
+		}
+	case "s3":
+		if c.Storage.S3Bucket == "" {
+			errs = append(errs, errors.New("S3_BUCKET is required when STORAGE_BACKEND is s3"))
+		}
+		if !isAbsoluteURL(c.Storage.S3Endpoint) {
+			errs = append(errs, fmt.Errorf("S3_ENDPOINT must be an absolute URL: %q", c.Storage.S3Endpoint))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("STORAGE_BACKEND must be local or s3: %q", c.Storage.Backend))
+	}
+	if c.Storage.S3PublicBaseURL != "" && !isAbsoluteURL(c.Storage.S3PublicBaseURL) {
+		errs = append(errs, fmt.Errorf("S3_PUBLIC_BASE_URL must be an absolute URL: %q", c.Storage.S3PublicBaseURL))
+	}
+	if c.Avatar.MaxUploadBytes <= 0 {
+		errs = append(errs, fmt.Errorf("AVATAR_MAX_UPLOAD_BYTES must be positive: %d", c.Avatar.MaxUploadBytes))
+	}
+	// マルチパートのヘッダーの分を含めて画像をアップロードできる必要がある
+	if c.Avatar.MaxUploadBytes >= c.Server.BodyLimit {
+		errs = append(errs, fmt.Errorf("AVATAR_MAX_UPLOAD_BYTES must be less than SERVER_BODY_LIMIT: %d", c.Server.BodyLimit))
+	}
+	if !isAbsoluteURL(c.Avatar.BaseURL) {
+		errs = append(errs, fmt.Errorf("AVATAR_BASE_URL must be an absolute URL: %q", c.Avatar.BaseURL))
+	}
+	if c.Account.DeletionGracePeriod < 0 || c.Account.PurgeInterval < 0 {
+		errs = append(errs, errors.New("ACCOUNT_DELETION_GRACE_PERIOD and ACCOUNT_PURGE_INTERVAL must not be negative"))
+	}
+	if c.Events.DispatchInterval < 0 {
+		errs = append(errs, fmt.Errorf("EVENTS_DISPATCH_INTERVAL must not be negative: %s", c.Events.DispatchInterval))
+	}
+	if c.Webhook.MaxAttempts <= 0 {
+		errs = append(errs, fmt.Errorf("WEBHOOK_MAX_ATTEMPTS must be positive: %d", c.Webhook.MaxAttempts))
+	}
+	if c.Webhook.DeliveryInterval < 0 {
+		errs = append(errs, fmt.Errorf("WEBHOOK_DELIVERY_INTERVAL must not be negative: %s", c.Webhook.DeliveryInterval))
+	}
+	if c.Webhook.DeliveryRetention < 0 {
+		errs = append(errs, fmt.Errorf("WEBHOOK_DELIVERY_RETENTION must not be negative: %s", c.Webhook.DeliveryRetention))
+	}
+	if c.Webhook.AllowPrivateTargets && c.Environment == EnvironmentProduction {
+		errs = append(errs, errors.New("WEBHOOK_ALLOW_PRIVATE_TARGETS must not be enabled in production"))
+	}
+	if c.SCIM.Token != "" && len(c.SCIM.Token) < minSCIMTokenLength {
+		errs = append(errs, fmt.Errorf("SCIM_TOKEN must be at least %d characters", minSCIMTokenLength))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// IsProduction は本番環境かを判定する
+func (c *Config) IsProduction() bool {
+	return c.Environment == EnvironmentProduction
+}
+
+// isAbsoluteURL はスキームとホストを持つURLかを判定する
+func isAbsoluteURL(value string) bool {
+	u, err := url.Parse(value)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
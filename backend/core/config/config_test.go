@@ -0,0 +1,408 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// envOf はmapから環境変数を返す取得関数を作成する
+func envOf(values map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := values[key]
+		return value, ok
+	}
+}
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	cfg, err := load(envOf(map[string]string{"JWT_SECRET": "secret"}))
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, EnvironmentDevelopment, cfg.Environment)
+		assert.Equal(t, 8080, cfg.Server.Port)
+		assert.Equal(t, "debug", cfg.Log.Level)
+		assert.Equal(t, "code", cfg.Auth.CallbackMode)
+		assert.Equal(t, "http://localhost:8080/auth/google/callback", cfg.Google.RedirectURL)
+		assert.Equal(t, "memory", cfg.RateLimit.Backend)
+		assert.Equal(t, "sliding_window:10/1m", cfg.RateLimit.Login)
+		assert.Equal(t, "log", cfg.Server.OpenAPIResponseValidation)
+		assert.False(t, cfg.IsProduction())
+	}
+
+	// テスト環境ではOpenAPIドキュメントと一致しないレスポンスを失敗させる
+	cfg, err = load(envOf(map[string]string{"JWT_SECRET": "secret", "ENVIRONMENT": "test"}))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "strict", cfg.Server.OpenAPIResponseValidation)
+	}
+}
+
+func TestLoad_Env(t *testing.T) {
+	cfg, err := load(envOf(map[string]string{
+		"JWT_SECRET":                    "secret",
+		"PORT":                          "9090",
+		"JWT_PREVIOUS_SECRETS":          "old-secret-1,old-secret-2",
+		"ADMIN_EMAILS":                  "admin@example.com, ops@example.com",
+		"FRONTEND_REDIRECT_URLS":        "https://app.example.com/",
+		"GOOGLE_REDIRECT_URL":           "https://api.example.com/auth/google/callback",
+		"RATE_LIMIT_AUTH_LOGIN":         "token_bucket:5/1m",
+		"SERVER_SHUTDOWN_TIMEOUT":       "45s",
+		"STORAGE_BACKEND":               "s3",
+		"S3_ENDPOINT":                   "http://localhost:9000",
+		"S3_BUCKET":                     "avatars",
+		"S3_PATH_STYLE":                 "true",
+		"ACCOUNT_DELETION_GRACE_PERIOD": "168h",
+		"SCIM_ADMIN_GROUP":              "Stackies Admins",
+	}))
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, 9090, cfg.Server.Port)
+		assert.Equal(t, []string{"old-secret-1", "old-secret-2"}, cfg.Auth.JWTPreviousSecrets)
+		assert.Equal(t, []string{"admin@example.com", "ops@example.com"}, cfg.Auth.AdminEmails)
+		assert.Equal(t, []string{"https://app.example.com/"}, cfg.Auth.FrontendRedirectURLs)
+		assert.Equal(t, "https://api.example.com/auth/google/callback", cfg.Google.RedirectURL)
+		assert.Equal(t, "token_bucket:5/1m", cfg.RateLimit.Login)
+		assert.Equal(t, 45*time.Second, cfg.Server.ShutdownTimeout)
+		assert.Equal(t, 30*time.Second, cfg.Server.WriteTimeout)
+		assert.Equal(t, "s3", cfg.Storage.Backend)
+		assert.Equal(t, "avatars", cfg.Storage.S3Bucket)
+		assert.True(t, cfg.Storage.S3PathStyle)
+		assert.Equal(t, 7*24*time.Hour, cfg.Account.DeletionGracePeriod)
+		assert.Equal(t, 10*time.Minute, cfg.Account.RecentAuthMaxAge)
+		assert.Equal(t, 15*time.Minute, cfg.Auth.ImpersonationTTL)
+		assert.Equal(t, time.Second, cfg.Events.DispatchInterval)
+		assert.Equal(t, 8, cfg.Webhook.MaxAttempts)
+		assert.Equal(t, time.Second, cfg.Webhook.DeliveryInterval)
+		assert.Empty(t, cfg.SCIM.Token)
+		assert.Equal(t, "Stackies Admins", cfg.SCIM.AdminGroup)
+	}
+}
+
+func TestLoad_LegacyNames(t *testing.T) {
+	tests := []struct {
+		testName string
+		env      map[string]string
+		wantErr  bool
+	}{
+		{
+			testName: "旧名のみ",
+			env: map[string]string{
+				"JWT_SECRET_KEY":      "legacy",
+				"GOOGLE_REDIRECT_URI": "https://api.example.com/callback",
+			},
+		},
+		{
+			testName: "新旧が同じ値",
+			env: map[string]string{
+				"JWT_SECRET":          "legacy",
+				"JWT_SECRET_KEY":      "legacy",
+				"GOOGLE_REDIRECT_URL": "https://api.example.com/callback",
+				"GOOGLE_REDIRECT_URI": "https://api.example.com/callback",
+			},
+		},
+		{
+			testName: "新旧で値が異なる",
+			env: map[string]string{
+				"JWT_SECRET":     "new",
+				"JWT_SECRET_KEY": "legacy",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			cfg, err := load(envOf(tt.env))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, "legacy", cfg.Auth.JWTSecret)
+				assert.Equal(t, "https://api.example.com/callback", cfg.Google.RedirectURL)
+			}
+		})
+	}
+}
+
+func TestLoad_FileAndProfile(t *testing.T) {
+	path := writeConfigFile(t, `
+server:
+  port: 7000
+auth:
+  jwtSecret: from-file
+  frontendRedirectURLs:
+    - https://app.example.com/
+log:
+  level: warn
+profiles:
+  production:
+    auth:
+      jwtSecret: production-secret-with-at-least-32-bytes
+    google:
+      clientID: client
+      clientSecret: secret
+      redirectURL: https://api.example.com/auth/google/callback
+    security:
+      revokeURL: https://app.example.com/security/not-me
+    avatar:
+      baseURL: https://api.example.com
+`)
+
+	t.Run("共通の設定", func(t *testing.T) {
+		cfg, err := load(envOf(map[string]string{"CONFIG_FILE": path}))
+		if assert.NoError(t, err) {
+			assert.Equal(t, 7000, cfg.Server.Port)
+			assert.Equal(t, "from-file", cfg.Auth.JWTSecret)
+			assert.Equal(t, "warn", cfg.Log.Level)
+			// ファイルにない項目はデフォルト値のまま
+			assert.Equal(t, "code", cfg.Auth.CallbackMode)
+		}
+	})
+
+	t.Run("プロファイルで上書き", func(t *testing.T) {
+		cfg, err := load(envOf(map[string]string{"CONFIG_FILE": path, "ENVIRONMENT": "production"}))
+		if assert.NoError(t, err) {
+			assert.True(t, cfg.IsProduction())
+			assert.Equal(t, "production-secret-with-at-least-32-bytes", cfg.Auth.JWTSecret)
+			assert.Equal(t, "client", cfg.Google.ClientID)
+			assert.Equal(t, 7000, cfg.Server.Port)
+		}
+	})
+
+	t.Run("環境変数が優先", func(t *testing.T) {
+		cfg, err := load(envOf(map[string]string{"CONFIG_FILE": path, "JWT_SECRET": "from-env", "PORT": "7001"}))
+		if assert.NoError(t, err) {
+			assert.Equal(t, "from-env", cfg.Auth.JWTSecret)
+			assert.Equal(t, 7001, cfg.Server.Port)
+		}
+	})
+}
+
+func TestLoad_FileErrors(t *testing.T) {
+	_, err := load(envOf(map[string]string{"CONFIG_FILE": filepath.Join(t.TempDir(), "missing.yaml")}))
+	assert.Error(t, err)
+
+	_, err = load(envOf(map[string]string{"CONFIG_FILE": writeConfigFile(t, "server: [")}))
+	assert.Error(t, err)
+}
+
+func TestLoad_Validation(t *testing.T) {
+	tests := []struct {
+		testName   string
+		env        map[string]string
+		wantErrMsg string
+	}{
+		{
+			testName:   "JWTシークレットが空",
+			env:        map[string]string{},
+			wantErrMsg: "JWT_SECRET is required",
+		},
+		{
+			testName:   "ローテーション前のJWTシークレットに現在のシークレットが含まれる",
+			env:        map[string]string{"JWT_SECRET": "secret", "JWT_PREVIOUS_SECRETS": "old,secret"},
+			wantErrMsg: "JWT_PREVIOUS_SECRETS must not contain JWT_SECRET",
+		},
+		{
+			testName:   "不正な環境",
+			env:        map[string]string{"JWT_SECRET": "secret", "ENVIRONMENT": "staging"},
+			wantErrMsg: "ENVIRONMENT must be one of",
+		},
+		{
+			testName:   "不正なポート",
+			env:        map[string]string{"JWT_SECRET": "secret", "PORT": "http"},
+			wantErrMsg: "PORT must be a number",
+		},
+		{
+			testName:   "不正なタイムアウト",
+			env:        map[string]string{"JWT_SECRET": "secret", "SERVER_READ_TIMEOUT": "10"},
+			wantErrMsg: "SERVER_READ_TIMEOUT must be a duration",
+		},
+		{
+			testName:   "不正なヘルスチェックのタイムアウト",
+			env:        map[string]string{"JWT_SECRET": "secret", "HEALTH_CHECK_TIMEOUT": "0s"},
+			wantErrMsg: "HEALTH_CHECK_TIMEOUT must be positive",
+		},
+		{
+			testName:   "不正な再試行回数",
+			env:        map[string]string{"JWT_SECRET": "secret", "OUTBOUND_MAX_RETRIES": "many"},
+			wantErrMsg: "OUTBOUND_MAX_RETRIES must be a number",
+		},
+		{
+			testName:   "負の再試行回数",
+			env:        map[string]string{"JWT_SECRET": "secret", "OUTBOUND_MAX_RETRIES": "-1"},
+			wantErrMsg: "must not be negative",
+		},
+		{
+			testName:   "不正なレスポンスの検証方法",
+			env:        map[string]string{"JWT_SECRET": "secret", "OPENAPI_RESPONSE_VALIDATION": "warn"},
+			wantErrMsg: "OPENAPI_RESPONSE_VALIDATION must be off, log or strict",
+		},
+		{
+			testName:   "不正なトレースの出力先",
+			env:        map[string]string{"JWT_SECRET": "secret", "TRACING_EXPORTER": "jaeger"},
+			wantErrMsg: "TRACING_EXPORTER must be none, stdout or otlp",
+		},
+		{
+			testName:   "範囲外のサンプリング率",
+			env:        map[string]string{"JWT_SECRET": "secret", "TRACING_SAMPLE_RATIO": "1.5"},
+			wantErrMsg: "TRACING_SAMPLE_RATIO must be between 0 and 1",
+		},
+		{
+			testName:   "不正な信頼するプロキシ",
+			env:        map[string]string{"JWT_SECRET": "secret", "TRUSTED_PROXIES": "10.0.0.0/8,proxy.internal"},
+			wantErrMsg: "TRUSTED_PROXIES contains an invalid CIDR",
+		},
+		{
+			testName:   "TLSの証明書のみ指定",
+			env:        map[string]string{"JWT_SECRET": "secret", "TLS_CERT_FILE": "cert.pem"},
+			wantErrMsg: "TLS_CERT_FILE and TLS_KEY_FILE must be set together",
+		},
+		{
+			testName:   "不正なログレベル",
+			env:        map[string]string{"JWT_SECRET": "secret", "LOG_LEVEL": "verbose"},
+			wantErrMsg: "LOG_LEVEL is invalid",
+		},
+		{
+			testName:   "不正なコールバック方式",
+			env:        map[string]string{"JWT_SECRET": "secret", "AUTH_CALLBACK_MODE": "token"},
+			wantErrMsg: "AUTH_CALLBACK_MODE must be cookie or code",
+		},
+		{
+			testName:   "相対URLのリダイレクト先",
+			env:        map[string]string{"JWT_SECRET": "secret", "FRONTEND_REDIRECT_URLS": "/home"},
+			wantErrMsg: "FRONTEND_REDIRECT_URLS contains an invalid URL",
+		},
+		{
+			testName:   "RedisのURLがない",
+			env:        map[string]string{"JWT_SECRET": "secret", "RATE_LIMIT_BACKEND": "redis"},
+			wantErrMsg: "REDIS_URL is required",
+		},
+		{
+			testName:   "SMTPの設定がない",
+			env:        map[string]string{"JWT_SECRET": "secret", "LOGIN_ALERT_NOTIFIER": "smtp"},
+			wantErrMsg: "SMTP_ADDR and SMTP_FROM are required",
+		},
+		{
+			testName:   "本番で短いJWTシークレット",
+			env:        map[string]string{"JWT_SECRET": "short", "ENVIRONMENT": "production"},
+			wantErrMsg: "JWT_SECRET must be at least 32 bytes in production",
+		},
+		{
+			testName:   "本番でGoogleの認証情報がない",
+			env:        map[string]string{"JWT_SECRET": "secret", "ENVIRONMENT": "production"},
+			wantErrMsg: "GOOGLE_CLIENT_ID and GOOGLE_CLIENT_SECRET are required in production",
+		},
+		{
+			testName:   "本番ではローカルのURLを既定にしない",
+			env:        map[string]string{"JWT_SECRET": "secret", "ENVIRONMENT": "production"},
+			wantErrMsg: "FRONTEND_REDIRECT_URLS is required",
+		},
+		{
+			testName:   "本番ではfake IdPを使用できない",
+			env:        map[string]string{"JWT_SECRET": "secret", "ENVIRONMENT": "production", "GOOGLE_ENDPOINT_BASE_URL": "http://localhost:9999"},
+			wantErrMsg: "GOOGLE_ENDPOINT_BASE_URL must not be set in production",
+		},
+		{
+			testName:   "不明なストレージ",
+			env:        map[string]string{"JWT_SECRET": "secret", "STORAGE_BACKEND": "gcs"},
+			wantErrMsg: "STORAGE_BACKEND must be local or s3",
+		},
+		{
+			testName:   "S3のバケットがない",
+			env:        map[string]string{"JWT_SECRET": "secret", "STORAGE_BACKEND": "s3", "S3_ENDPOINT": "http://localhost:9000"},
+			wantErrMsg: "S3_BUCKET is required when STORAGE_BACKEND is s3",
+		},
+		{
+			testName:   "S3のエンドポイントがない",
+			env:        map[string]string{"JWT_SECRET": "secret", "STORAGE_BACKEND": "s3", "S3_BUCKET": "avatars"},
+			wantErrMsg: "S3_ENDPOINT must be an absolute URL",
+		},
+		{
+			testName:   "真偽値でないS3_PATH_STYLE",
+			env:        map[string]string{"JWT_SECRET": "secret", "S3_PATH_STYLE": "yes please"},
+			wantErrMsg: "S3_PATH_STYLE must be true or false",
+		},
+		{
+			testName:   "リクエストボディの上限より大きい画像",
+			env:        map[string]string{"JWT_SECRET": "secret", "SERVER_BODY_LIMIT": "1048576", "AVATAR_MAX_UPLOAD_BYTES": "2097152"},
+			wantErrMsg: "AVATAR_MAX_UPLOAD_BYTES must be less than SERVER_BODY_LIMIT",
+		},
+		{
+			testName:   "本番では画像のURLを既定にしない",
+			env:        map[string]string{"JWT_SECRET": "secret", "ENVIRONMENT": "production"},
+			wantErrMsg: "AVATAR_BASE_URL must be an absolute URL",
+		},
+		{
+			testName:   "直近のログインの最大経過時間が0",
+			env:        map[string]string{"JWT_SECRET": "secret", "ACCOUNT_RECENT_AUTH_MAX_AGE": "0s"},
+			wantErrMsg: "ACCOUNT_RECENT_AUTH_MAX_AGE must be positive",
+		},
+		{
+			testName:   "負の削除の猶予期間",
+			env:        map[string]string{"JWT_SECRET": "secret", "ACCOUNT_DELETION_GRACE_PERIOD": "-1h"},
+			wantErrMsg: "ACCOUNT_DELETION_GRACE_PERIOD and ACCOUNT_PURGE_INTERVAL must not be negative",
+		},
+		{
+			testName:   "なりすましのトークンの有効期間が長すぎる",
+			env:        map[string]string{"JWT_SECRET": "secret", "AUTH_IMPERSONATION_TTL": "2h"},
+			wantErrMsg: "AUTH_IMPERSONATION_TTL must not exceed 1h",
+		},
+		{
+			testName:   "負のイベントの配信間隔",
+			env:        map[string]string{"JWT_SECRET": "secret", "EVENTS_DISPATCH_INTERVAL": "-1s"},
+			wantErrMsg: "EVENTS_DISPATCH_INTERVAL must not be negative",
+		},
+		{
+			testName:   "Webhookの送信回数が0",
+			env:        map[string]string{"JWT_SECRET": "secret", "WEBHOOK_MAX_ATTEMPTS": "0"},
+			wantErrMsg: "WEBHOOK_MAX_ATTEMPTS must be positive",
+		},
+		{
+			testName:   "負のWebhookの送信間隔",
+			env:        map[string]string{"JWT_SECRET": "secret", "WEBHOOK_DELIVERY_INTERVAL": "-1s"},
+			wantErrMsg: "WEBHOOK_DELIVERY_INTERVAL must not be negative",
+		},
+		{
+			testName:   "負のWebhookの配信の保持期間",
+			env:        map[string]string{"JWT_SECRET": "secret", "WEBHOOK_DELIVERY_RETENTION": "-1h"},
+			wantErrMsg: "WEBHOOK_DELIVERY_RETENTION must not be negative",
+		},
+		{
+			testName:   "本番で内部ネットワークへのWebhookを許可",
+			env:        map[string]string{"JWT_SECRET": "secret", "ENVIRONMENT": "production", "WEBHOOK_ALLOW_PRIVATE_TARGETS": "true"},
+			wantErrMsg: "WEBHOOK_ALLOW_PRIVATE_TARGETS must not be enabled in production",
+		},
+		{
+			testName:   "短いSCIMのトークン",
+			env:        map[string]string{"JWT_SECRET": "secret", "SCIM_TOKEN": "short"},
+			wantErrMsg: "SCIM_TOKEN must be at least 32 characters",
+		},
+		{
+			testName:   "不正なfake IdPのURL",
+			env:        map[string]string{"JWT_SECRET": "secret", "GOOGLE_ENDPOINT_BASE_URL": "localhost:9999"},
+			wantErrMsg: "GOOGLE_ENDPOINT_BASE_URL must be an absolute URL",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			cfg, err := load(envOf(tt.env))
+			assert.Nil(t, cfg)
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.wantErrMsg)
+			}
+		})
+	}
+}
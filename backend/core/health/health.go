@@ -0,0 +1,153 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Kind はヘルスチェックの種類を表す
+type Kind string
+
+const (
+	// KindLiveness はプロセスが動作しているかの確認（失敗した場合は再起動の対象）
+	KindLiveness Kind = "liveness"
+	// KindReadiness はリクエストを処理できるかの確認（失敗した場合はトラフィックを振り分けない）
+	KindReadiness Kind = "readiness"
+)
+
+// Status はヘルスチェックの結果を表す
+type Status string
+
+const (
+	// StatusUp は正常
+	StatusUp Status = "up"
+	// StatusDown は異常
+	StatusDown Status = "down"
+)
+
+// Check は依存先の状態を確認する関数を表す（正常な場合はnilを返す）
+type Check func(ctx context.Context) error
+
+type (
+	// Result は1つのヘルスチェックの結果を表す
+	Result struct {
+		Name      string `json:"name"`
+		Status    Status `json:"status"`
+		LatencyMs int64  `json:"latencyMs"`
+		// Error は失敗の理由（内部のアドレスなどを含むためレスポンスには含めない）
+		Error     string    `json:"-"`
+		CheckedAt time.Time `json:"checkedAt"`
+		// Cached はキャッシュされた結果を返した場合にtrue
+		Cached bool `json:"cached"`
+	}
+
+	// Report はヘルスチェック全体の結果を表す（1つでも異常があればdown）
+	Report struct {
+		Status Status   `json:"status"`
+		Checks []Result `json:"checks"`
+	}
+)
+
+// Registry はヘルスチェックを登録し、結果を一定時間キャッシュして実行する
+type Registry struct {
+	mu       sync.RWMutex
+	checks   []*entry
+	cacheTTL time.Duration
+	timeout  time.Duration
+	now      func() time.Time
+}
+
+// entry は登録されたヘルスチェックと直近の結果を表す
+type entry struct {
+	name  string
+	kind  Kind
+	check Check
+
+	mu     sync.Mutex
+	last   Result
+	cached bool
+}
+
+// NewRegistry は新しいRegistryを作成する
+// cacheTTLの間は前回の結果を返し、各チェックはtimeoutで打ち切る
+func NewRegistry(cacheTTL, timeout time.Duration) *Registry {
+	return &Registry{
+		cacheTTL: cacheTTL,
+		timeout:  timeout,
+		now:      time.Now,
+	}
+}
+
+// Register はヘルスチェックを登録する
+func (r *Registry) Register(name string, kind Kind, check Check) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks = append(r.checks, &entry{name: name, kind: kind, check: check})
+}
+
+// Run は指定した種類のヘルスチェックを並行して実行し、結果をまとめる
+// Readinessの確認にはLivenessのチェックも含める
+func (r *Registry) Run(ctx context.Context, kind Kind) *Report {
+	r.mu.RLock()
+	var targets []*entry
+	for _, e := range r.checks {
+		if e.kind == kind || (kind == KindReadiness && e.kind == KindLiveness) {
+			targets = append(targets, e)
+		}
+	}
+	r.mu.RUnlock()
+
+	results := make([]Result, len(targets))
+	var wg sync.WaitGroup
+	for i, e := range targets {
+		wg.Add(1)
+		go func(i int, e *entry) {
+			defer wg.Done()
+			results[i] = r.runEntry(ctx, e)
+		}(i, e)
+	}
+	wg.Wait()
+
+	report := &Report{Status: StatusUp, Checks: results}
+	for _, result := range results {
+		if result.Status != StatusUp {
+			report.Status = StatusDown
+		}
+	}
+	return report
+}
+
+// runEntry はキャッシュが有効であれば前回の結果を、そうでなければチェックを実行した結果を返す
+// 同じチェックが同時に要求された場合は1回だけ実行する
+func (r *Registry) runEntry(ctx context.Context, e *entry) Result {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.cached && r.now().Sub(e.last.CheckedAt) < r.cacheTTL {
+		result := e.last
+		result.Cached = true
+		return result
+	}
+
+	// 結果は他の呼び出し元にも返すため、最初の呼び出し元の切断によるキャンセルではチェックを中断しない
+	checkCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), r.timeout)
+	defer cancel()
+
+	start := r.now()
+	err := e.check(checkCtx)
+	result := Result{
+		Name:      e.name,
+		Status:    StatusUp,
+		LatencyMs: r.now().Sub(start).Milliseconds(),
+		CheckedAt: start,
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+
+	e.last = result
+	e.cached = true
+	return result
+}
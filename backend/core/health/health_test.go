@@ -0,0 +1,106 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_Run(t *testing.T) {
+	tests := []struct {
+		testName       string
+		kind           Kind
+		expectedStatus Status
+		expectedChecks []string
+	}{
+		{testName: "Livenessのみ", kind: KindLiveness, expectedStatus: StatusUp, expectedChecks: []string{"process"}},
+		{testName: "ReadinessはLivenessも含む", kind: KindReadiness, expectedStatus: StatusDown, expectedChecks: []string{"process", "redis", "google"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			registry := NewRegistry(time.Second, time.Second)
+			registry.Register("process", KindLiveness, func(ctx context.Context) error { return nil })
+			registry.Register("redis", KindReadiness, func(ctx context.Context) error { return nil })
+			registry.Register("google", KindReadiness, func(ctx context.Context) error { return errors.New("unreachable") })
+
+			report := registry.Run(context.Background(), tt.kind)
+
+			assert.Equal(t, tt.expectedStatus, report.Status)
+			var names []string
+			for _, result := range report.Checks {
+				names = append(names, result.Name)
+				if result.Name == "google" {
+					assert.Equal(t, StatusDown, result.Status)
+					assert.Equal(t, "unreachable", result.Error)
+				}
+			}
+			assert.Equal(t, tt.expectedChecks, names)
+		})
+	}
+}
+
+func TestRegistry_Empty(t *testing.T) {
+	report := NewRegistry(time.Second, time.Second).Run(context.Background(), KindReadiness)
+
+	assert.Equal(t, StatusUp, report.Status)
+	assert.Empty(t, report.Checks)
+}
+
+func TestRegistry_Cache(t *testing.T) {
+	registry := NewRegistry(5*time.Second, time.Second)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	registry.now = func() time.Time { return now }
+
+	var calls atomic.Int32
+	registry.Register("redis", KindReadiness, func(ctx context.Context) error {
+		calls.Add(1)
+		return nil
+	})
+
+	first := registry.Run(context.Background(), KindReadiness)
+	second := registry.Run(context.Background(), KindReadiness)
+	assert.Equal(t, int32(1), calls.Load())
+	assert.False(t, first.Checks[0].Cached)
+	assert.True(t, second.Checks[0].Cached)
+
+	// キャッシュの有効期限が切れたら再実行する
+	now = now.Add(5 * time.Second)
+	third := registry.Run(context.Background(), KindReadiness)
+	assert.Equal(t, int32(2), calls.Load())
+	assert.False(t, third.Checks[0].Cached)
+}
+
+func TestRegistry_Timeout(t *testing.T) {
+	registry := NewRegistry(time.Second, 20*time.Millisecond)
+	registry.Register("slow", KindReadiness, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	report := registry.Run(context.Background(), KindReadiness)
+
+	assert.Equal(t, StatusDown, report.Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks[0].Error)
+}
+
+func TestRegistry_CallerCancel(t *testing.T) {
+	registry := NewRegistry(5*time.Second, time.Second)
+	registry.Register("redis", KindReadiness, func(ctx context.Context) error {
+		return ctx.Err()
+	})
+
+	// 呼び出し元の切断によるキャンセルでは失敗とせず、キャッシュにも失敗を残さない
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	first := registry.Run(ctx, KindReadiness)
+	second := registry.Run(context.Background(), KindReadiness)
+
+	assert.Equal(t, StatusUp, first.Status)
+	assert.Equal(t, StatusUp, second.Status)
+	assert.True(t, second.Checks[0].Cached)
+}
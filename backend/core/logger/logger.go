@@ -0,0 +1,122 @@
+package logger
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+	userIDKey
+	actorIDKey
+)
+
+const (
+	// RequestIDKey はログに出力するリクエストIDの属性名
+	RequestIDKey = "request_id"
+	// UserIDKey はログに出力するユーザーIDの属性名
+	UserIDKey = "user_id"
+	// ActorIDKey はログに出力する、なりすましを行っている管理者のIDの属性名
+	ActorIDKey = "actor_id"
+	// TraceIDKey・SpanIDKey はログに出力するトレースID・スパンIDの属性名（トレースとログを突き合わせる）
+	TraceIDKey = "trace_id"
+	SpanIDKey  = "span_id"
+)
+
+// New はJSON形式で出力するロガーを作成する
+// contextに設定されたリクエストID・ユーザーID・トレースIDを各ログに付与し、秘匿情報を伏せ字にする
+func New(w io.Writer, level slog.Level) *slog.Logger {
+	handler := slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: redactAttr,
+	})
+	return slog.New(&contextHandler{Handler: handler})
+}
+
+// ParseLevel はログレベルの文字列（debug, info, warn, error）を解析する（空の場合はinfo）
+func ParseLevel(value string) (slog.Level, error) {
+	var level slog.Level
+	if value == "" {
+		return slog.LevelInfo, nil
+	}
+	if err := level.UnmarshalText([]byte(strings.TrimSpace(value))); err != nil {
+		return slog.LevelInfo, err
+	}
+	return level, nil
+}
+
+// WithRequestID はリクエストIDを設定したcontextを返す
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestIDFromContext はcontextに設定されたリクエストIDを返す
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+// WithUserID は認証済みユーザーのIDを設定したcontextを返す
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDKey, userID)
+}
+
+// UserIDFromContext はcontextに設定されたユーザーIDを返す
+func UserIDFromContext(ctx context.Context) string {
+	userID, _ := ctx.Value(userIDKey).(string)
+	return userID
+}
+
+// WithActorID はなりすましを行っている管理者のIDを設定したcontextを返す
+func WithActorID(ctx context.Context, actorID string) context.Context {
+	return context.WithValue(ctx, actorIDKey, actorID)
+}
+
+// ActorIDFromContext はcontextに設定された、なりすましを行っている管理者のIDを返す
+func ActorIDFromContext(ctx context.Context) string {
+	actorID, _ := ctx.Value(actorIDKey).(string)
+	return actorID
+}
+
+// contextHandler はcontextのリクエストID・ユーザーID・トレースIDをログに付与するslog.Handler
+type contextHandler struct {
+	slog.Handler
+}
+
+// Handle はcontextの値を属性として追加してからログを出力する
+func (h *contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if ctx != nil {
+		if requestID := RequestIDFromContext(ctx); requestID != "" {
+			record.AddAttrs(slog.String(RequestIDKey, requestID))
+		}
+		if userID := UserIDFromContext(ctx); userID != "" {
+			record.AddAttrs(slog.String(UserIDKey, userID))
+		}
+		if actorID := ActorIDFromContext(ctx); actorID != "" {
+			record.AddAttrs(slog.String(ActorIDKey, actorID))
+		}
+		if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+			record.AddAttrs(
+				slog.String(TraceIDKey, spanContext.TraceID().String()),
+				slog.String(SpanIDKey, spanContext.SpanID().String()),
+			)
+		}
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+// WithAttrs は属性を追加したHandlerを返す
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup はグループを追加したHandlerを返す
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
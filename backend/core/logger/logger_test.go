@@ -0,0 +1,129 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func decodeLog(t *testing.T, buf *bytes.Buffer) map[string]any {
+	t.Helper()
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("invalid json log: %v: %s", err, buf.String())
+	}
+	return entry
+}
+
+func TestNew_ContextAttrs(t *testing.T) {
+	var buf bytes.Buffer
+	log := New(&buf, slog.LevelInfo)
+
+	ctx := WithUserID(WithRequestID(context.Background(), "req_1"), "user_1")
+	log.InfoContext(ctx, "hello", slog.String("route", "auth_login"))
+
+	entry := decodeLog(t, &buf)
+	assert.Equal(t, "hello", entry["msg"])
+	assert.Equal(t, "INFO", entry["level"])
+	assert.Equal(t, "req_1", entry[RequestIDKey])
+	assert.Equal(t, "user_1", entry[UserIDKey])
+	assert.Equal(t, "auth_login", entry["route"])
+	assert.NotContains(t, entry, TraceIDKey)
+	assert.NotContains(t, entry, ActorIDKey)
+
+	// なりすましの場合は操作している管理者も出力する
+	buf.Reset()
+	log.InfoContext(WithActorID(ctx, "admin_1"), "impersonated")
+	entry = decodeLog(t, &buf)
+	assert.Equal(t, "user_1", entry[UserIDKey])
+	assert.Equal(t, "admin_1", entry[ActorIDKey])
+}
+
+func TestNew_TraceAttrs(t *testing.T) {
+	var buf bytes.Buffer
+	log := New(&buf, slog.LevelInfo)
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}))
+	log.InfoContext(ctx, "hello")
+
+	entry := decodeLog(t, &buf)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", entry[TraceIDKey])
+	assert.Equal(t, "00f067aa0ba902b7", entry[SpanIDKey])
+}
+
+func TestNew_Level(t *testing.T) {
+	var buf bytes.Buffer
+	log := New(&buf, slog.LevelWarn)
+
+	log.Info("ignored")
+	assert.Empty(t, buf.String())
+
+	log.With(slog.String("component", "test")).Warn("kept")
+	entry := decodeLog(t, &buf)
+	assert.Equal(t, "test", entry["component"])
+}
+
+func TestNew_Redaction(t *testing.T) {
+	tests := []struct {
+		testName string
+		attr     slog.Attr
+		want     string
+	}{
+		{testName: "トークン属性", attr: slog.String("refresh_token", "abc"), want: Redacted},
+		{testName: "認可コード属性", attr: slog.String("code", "4/0AX4"), want: Redacted},
+		{testName: "メール属性", attr: slog.String("email", "test@example.com"), want: Redacted},
+		{testName: "クエリ内のコード", attr: slog.String("uri", "/auth/google/callback?code=4/0AX4&state=xyz"), want: "/auth/google/callback?code=" + Redacted + "&state=" + Redacted},
+		{testName: "文中のメール", attr: slog.String("detail", "user test@example.com not found"), want: "user " + Redacted + " not found"},
+		{testName: "Bearerトークン", attr: slog.String("header", "Bearer abc.def"), want: "Bearer " + Redacted},
+		{testName: "JWT", attr: slog.String("value", "eyJhbGciOiJIUzI1NiJ9.eyJzdWIiOiIxIn0.sig"), want: Redacted},
+		{testName: "エラー", attr: slog.Any("error", errors.New("send to test@example.com failed")), want: "send to " + Redacted + " failed"},
+		{testName: "通常の値", attr: slog.String("route", "auth_login"), want: "auth_login"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			var buf bytes.Buffer
+			New(&buf, slog.LevelInfo).LogAttrs(context.Background(), slog.LevelInfo, "msg", tt.attr)
+
+			entry := decodeLog(t, &buf)
+			assert.Equal(t, tt.want, entry[tt.attr.Key])
+		})
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		testName string
+		value    string
+		want     slog.Level
+		wantErr  bool
+	}{
+		{testName: "空はinfo", value: "", want: slog.LevelInfo},
+		{testName: "debug", value: "debug", want: slog.LevelDebug},
+		{testName: "大文字", value: "WARN", want: slog.LevelWarn},
+		{testName: "不正な値", value: "verbose", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			got, err := ParseLevel(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
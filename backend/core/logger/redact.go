@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// Redacted は伏せ字にした値の代わりに出力する文字列
+const Redacted = "[REDACTED]"
+
+// sensitiveKeys は値を丸ごと伏せ字にする属性名（小文字）
+var sensitiveKeys = map[string]bool{
+	"code":               true,
+	"authorization_code": true,
+	"login_code":         true,
+	"state":              true,
+	"authorization":      true,
+	"cookie":             true,
+	"set-cookie":         true,
+}
+
+// sensitiveSuffixes は値を丸ごと伏せ字にする属性名の接尾辞（例: refresh_token, client_secret）
+var sensitiveSuffixes = []string{"token", "secret", "password", "email"}
+
+var (
+	// sensitiveParamPattern はURLのクエリやフォームに含まれる秘匿パラメータ
+	sensitiveParamPattern = regexp.MustCompile(`(?i)\b((?:access_|refresh_)?token|code|state|password|secret)=[^&\s"]+`)
+	// bearerPattern はAuthorizationヘッダーのBearerトークン
+	bearerPattern = regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9\-._~+/]+=*`)
+	// jwtPattern はJWT形式の文字列
+	jwtPattern = regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+	// emailPattern はメールアドレス
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+)
+
+// Redact は文字列に含まれるトークン・認可コード・メールアドレスを伏せ字にする
+func Redact(value string) string {
+	value = sensitiveParamPattern.ReplaceAllString(value, "$1="+Redacted)
+	value = bearerPattern.ReplaceAllString(value, "Bearer "+Redacted)
+	value = jwtPattern.ReplaceAllString(value, Redacted)
+	value = emailPattern.ReplaceAllString(value, Redacted)
+	return value
+}
+
+// isSensitiveKey は属性名が秘匿情報を表すかを判定する
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	if sensitiveKeys[key] {
+		return true
+	}
+	for _, suffix := range sensitiveSuffixes {
+		if strings.HasSuffix(key, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactAttr はslog.HandlerOptions.ReplaceAttrとして秘匿情報を伏せ字にする
+func redactAttr(groups []string, attr slog.Attr) slog.Attr {
+	if attr.Value.Kind() == slog.KindGroup {
+		return attr
+	}
+	if isSensitiveKey(attr.Key) {
+		return slog.String(attr.Key, Redacted)
+	}
+
+	switch attr.Value.Kind() {
+	case slog.KindString:
+		return slog.String(attr.Key, Redact(attr.Value.String()))
+	case slog.KindAny:
+		if err, ok := attr.Value.Any().(error); ok {
+			return slog.String(attr.Key, Redact(err.Error()))
+		}
+	}
+	return attr
+}
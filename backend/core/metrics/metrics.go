@@ -0,0 +1,183 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace はすべてのメトリクス名の接頭辞
+const namespace = "stackies"
+
+// Outcome はメトリクスのoutcomeラベルの値
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+// Metrics はPrometheusのメトリクスを保持する
+// nilのMetricsに対する記録は何もしないため、テストでは省略できる
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequestDuration  *prometheus.HistogramVec
+	loginAttempts        *prometheus.CounterVec
+	refreshRotations     prometheus.Counter
+	tokenReuseDetections prometheus.Counter
+	logouts              prometheus.Counter
+	outboundDuration     *prometheus.HistogramVec
+	outboundErrors       *prometheus.CounterVec
+	domainEvents         *prometheus.CounterVec
+}
+
+// New は新しいMetricsを作成する（Goランタイムとプロセスのメトリクスも含む）
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by method, route and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		loginAttempts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "auth_login_attempts_total",
+			Help:      "Login attempts by provider and outcome.",
+		}, []string{"provider", "outcome"}),
+		refreshRotations: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "auth_refresh_rotations_total",
+			Help:      "Refresh tokens rotated successfully.",
+		}),
+		tokenReuseDetections: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "auth_token_reuse_detections_total",
+			Help:      "Reuse of already rotated refresh tokens.",
+		}),
+		logouts: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "auth_logouts_total",
+			Help:      "Logouts.",
+		}),
+		outboundDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "outbound_request_duration_seconds",
+			Help:      "Latency of calls to external providers by provider, operation and outcome.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"provider", "operation", "outcome"}),
+		outboundErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "outbound_request_errors_total",
+			Help:      "Failed calls to external providers by provider and operation.",
+		}, []string{"provider", "operation"}),
+		domainEvents: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "domain_events_total",
+			Help:      "Domain events delivered to subscribers by type.",
+		}, []string{"type"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequestDuration,
+		m.loginAttempts,
+		m.refreshRotations,
+		m.tokenReuseDetections,
+		m.logouts,
+		m.outboundDuration,
+		m.outboundErrors,
+		m.domainEvents,
+	)
+	return m
+}
+
+// Handler は/metricsで公開するHTTPハンドラーを返す
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Registry はメトリクスのレジストリを返す（テストでの値の確認用）
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// RegisterActiveSessions は有効なセッション数を返す関数をゲージとして登録する
+// 値は収集のたびに取得する
+func (m *Metrics) RegisterActiveSessions(count func() float64) {
+	if m == nil {
+		return
+	}
+	m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "auth_active_sessions",
+		Help:      "Sessions with a stored refresh token.",
+	}, count))
+}
+
+// ObserveHTTPRequest はHTTPリクエストの処理時間を記録する
+// routeには実際のパスではなくルートのパターン（/admin/users/:id/roleなど）を渡す
+func (m *Metrics) ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	m.httpRequestDuration.WithLabelValues(method, route, strconv.Itoa(status)).Observe(duration.Seconds())
+}
+
+// IncLoginAttempt はログインの試行を記録する
+func (m *Metrics) IncLoginAttempt(provider, outcome string) {
+	if m == nil {
+		return
+	}
+	m.loginAttempts.WithLabelValues(provider, outcome).Inc()
+}
+
+// IncRefreshRotation はリフレッシュトークンのローテーションを記録する
+func (m *Metrics) IncRefreshRotation() {
+	if m == nil {
+		return
+	}
+	m.refreshRotations.Inc()
+}
+
+// IncTokenReuseDetection はリフレッシュトークンの再利用の検知を記録する
+func (m *Metrics) IncTokenReuseDetection() {
+	if m == nil {
+		return
+	}
+	m.tokenReuseDetections.Inc()
+}
+
+// IncLogout はログアウトを記録する
+func (m *Metrics) IncLogout() {
+	if m == nil {
+		return
+	}
+	m.logouts.Inc()
+}
+
+// ObserveOutbound は外部サービスの呼び出しの処理時間と失敗を記録する
+func (m *Metrics) ObserveOutbound(provider, operation string, duration time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	outcome := OutcomeSuccess
+	if err != nil {
+		outcome = OutcomeFailure
+		m.outboundErrors.WithLabelValues(provider, operation).Inc()
+	}
+	m.outboundDuration.WithLabelValues(provider, operation, outcome).Observe(duration.Seconds())
+}
+
+// IncDomainEvent は購読者に配信したドメインイベントを記録する
+func (m *Metrics) IncDomainEvent(eventType string) {
+	if m == nil {
+		return
+	}
+	m.domainEvents.WithLabelValues(eventType).Inc()
+}
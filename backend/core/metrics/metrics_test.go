@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics_Record(t *testing.T) {
+	m := New()
+
+	m.IncLoginAttempt("google", OutcomeSuccess)
+	m.IncLoginAttempt("google", OutcomeFailure)
+	m.IncLoginAttempt("google", OutcomeFailure)
+	m.IncRefreshRotation()
+	m.IncTokenReuseDetection()
+	m.IncLogout()
+	m.IncDomainEvent("user.registered")
+	m.ObserveOutbound("google", "exchange_code", 10*time.Millisecond, nil)
+	m.ObserveOutbound("google", "userinfo", 10*time.Millisecond, errors.New("timeout"))
+	m.ObserveHTTPRequest(http.MethodGet, "/auth/me", http.StatusOK, 5*time.Millisecond)
+	m.RegisterActiveSessions(func() float64 { return 3 })
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.loginAttempts.WithLabelValues("google", OutcomeSuccess)))
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.loginAttempts.WithLabelValues("google", OutcomeFailure)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.refreshRotations))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.tokenReuseDetections))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.logouts))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.domainEvents.WithLabelValues("user.registered")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.outboundErrors.WithLabelValues("google", "userinfo")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(m.outboundErrors.WithLabelValues("google", "exchange_code")))
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+	assert.Contains(t, body, `stackies_http_request_duration_seconds_count{method="GET",route="/auth/me",status="200"} 1`)
+	assert.Contains(t, body, `stackies_outbound_request_duration_seconds_count{operation="userinfo",outcome="failure",provider="google"} 1`)
+	assert.Contains(t, body, "stackies_auth_active_sessions 3")
+	assert.Contains(t, body, "go_goroutines")
+}
+
+func TestMetrics_Nil(t *testing.T) {
+	var m *Metrics
+
+	// nilの場合は何も記録せずpanicしない
+	assert.NotPanics(t, func() {
+		m.IncLoginAttempt("google", OutcomeSuccess)
+		m.IncRefreshRotation()
+		m.IncTokenReuseDetection()
+		m.IncLogout()
+		m.IncDomainEvent("user.registered")
+		m.ObserveOutbound("google", "userinfo", time.Millisecond, nil)
+		m.ObserveHTTPRequest(http.MethodGet, "/", http.StatusOK, time.Millisecond)
+		m.RegisterActiveSessions(func() float64 { return 0 })
+	})
+}
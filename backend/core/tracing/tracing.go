@@ -0,0 +1,110 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"stackies-backend/core/logger"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// エクスポーターの種類
+const (
+	// ExporterNone はトレースを出力しない（テストやトレースを収集しない環境向け）
+	ExporterNone = "none"
+	// ExporterStdout はトレースを標準出力に出力する（ローカルでの確認向け）
+	ExporterStdout = "stdout"
+	// ExporterOTLP はOTLP/HTTPでコレクターに送信する
+	ExporterOTLP = "otlp"
+)
+
+// Options はトレーサーの設定を表す
+type Options struct {
+	ServiceName string
+	Exporter    string
+	// OTLPEndpoint はOTLPの送信先URL（空の場合はOTEL_EXPORTER_OTLP_ENDPOINTなどの標準の環境変数に従う）
+	OTLPEndpoint string
+	// SampleRatio はサンプリングする割合（0〜1）。親スパンがある場合は親の判定に従う
+	SampleRatio float64
+	// Writer はstdoutエクスポーターの出力先（nilの場合は標準出力）
+	Writer io.Writer
+}
+
+// NewTracerProvider は設定に応じたTracerProviderを作成する
+// 返す関数は送信待ちのスパンを送信してから停止する
+func NewTracerProvider(ctx context.Context, opts Options) (trace.TracerProvider, func(ctx context.Context) error, error) {
+	var exporter sdktrace.SpanExporter
+	switch opts.Exporter {
+	case ExporterNone, "":
+		return noop.NewTracerProvider(), func(ctx context.Context) error { return nil }, nil
+	case ExporterStdout:
+		var stdoutOpts []stdouttrace.Option
+		if opts.Writer != nil {
+			stdoutOpts = append(stdoutOpts, stdouttrace.WithWriter(opts.Writer))
+		}
+		stdout, err := stdouttrace.New(stdoutOpts...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create stdout exporter: %w", err)
+		}
+		exporter = stdout
+	case ExporterOTLP:
+		var otlpOpts []otlptracehttp.Option
+		if opts.OTLPEndpoint != "" {
+			otlpOpts = append(otlpOpts, otlptracehttp.WithEndpointURL(opts.OTLPEndpoint))
+		}
+		otlp, err := otlptracehttp.New(ctx, otlpOpts...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+		}
+		exporter = otlp
+	default:
+		return nil, nil, fmt.Errorf("unknown tracing exporter: %q", opts.Exporter)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(opts.ServiceName),
+	))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+	)
+	return provider, provider.Shutdown, nil
+}
+
+// SetGlobal はTracerProviderとW3C Trace Contextのプロパゲーターをグローバルに設定する
+// 各パッケージはotel.Tracerでトレーサーを取得するため、設定前に取得したトレーサーにも反映される
+func SetGlobal(provider trace.TracerProvider) {
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+}
+
+// RecordError はエラーをスパンに記録し、スパンの状態をエラーにする（errがnilの場合は何もしない）
+// トレースはログと同様に外部へ送信されるため、トークンやメールアドレスはマスクする
+func RecordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	message := logger.Redact(err.Error())
+	span.RecordError(errors.New(message))
+	span.SetStatus(codes.Error, message)
+}
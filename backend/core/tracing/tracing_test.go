@@ -0,0 +1,91 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestNewTracerProvider(t *testing.T) {
+	tests := []struct {
+		testName string
+		exporter string
+		wantErr  bool
+	}{
+		{testName: "出力しない", exporter: ExporterNone},
+		{testName: "未指定は出力しない", exporter: ""},
+		{testName: "OTLP", exporter: ExporterOTLP},
+		{testName: "不明なエクスポーター", exporter: "jaeger", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			provider, shutdown, err := NewTracerProvider(context.Background(), Options{
+				ServiceName:  "stackies-backend",
+				Exporter:     tt.exporter,
+				OTLPEndpoint: "http://127.0.0.1:4318",
+				SampleRatio:  1,
+			})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.NotNil(t, provider)
+				assert.NoError(t, shutdown(context.Background()))
+			}
+		})
+	}
+}
+
+func TestNewTracerProvider_Stdout(t *testing.T) {
+	var buf bytes.Buffer
+	provider, shutdown, err := NewTracerProvider(context.Background(), Options{
+		ServiceName: "stackies-backend",
+		Exporter:    ExporterStdout,
+		SampleRatio: 1,
+		Writer:      &buf,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	_, span := provider.Tracer("test").Start(context.Background(), "AuthUsecase.GoogleLogin")
+	span.End()
+
+	// 停止時に送信待ちのスパンが出力される
+	assert.NoError(t, shutdown(context.Background()))
+	assert.Contains(t, buf.String(), "AuthUsecase.GoogleLogin")
+	assert.Contains(t, buf.String(), "stackies-backend")
+}
+
+func TestRecordError(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	_, span := provider.Tracer("test").Start(context.Background(), "ok")
+	RecordError(span, nil)
+	span.End()
+
+	_, span = provider.Tracer("test").Start(context.Background(), "failed")
+	RecordError(span, errors.New("user not found: test@example.com"))
+	span.End()
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 2) {
+		assert.Equal(t, codes.Unset, spans[0].Status().Code)
+		assert.Equal(t, codes.Error, spans[1].Status().Code)
+		assert.NotContains(t, spans[1].Status().Description, "test@example.com")
+		if assert.Len(t, spans[1].Events(), 1) {
+			for _, attr := range spans[1].Events()[0].Attributes {
+				assert.NotContains(t, attr.Value.Emit(), "test@example.com")
+			}
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// AuditEventType は監査イベントの種類を表す
+type AuditEventType string
+
+const (
+	AuditEventLogin      AuditEventType = "login"
+	AuditEventRefresh    AuditEventType = "token_refresh"
+	AuditEventLogout     AuditEventType = "logout"
+	AuditEventTokenReuse AuditEventType = "token_reuse"
+	AuditEventRoleChange AuditEventType = "role_change"
+	// AuditEventSessionRevoke はユーザーの全セッション無効化を表す
+	AuditEventSessionRevoke AuditEventType = "session_revoke"
+	// AuditEventUserStatusChange はユーザーの状態（一時停止・無効化など）の変更を表す
+	AuditEventUserStatusChange AuditEventType = "user_status_change"
+	// AuditEventAccountDelete はユーザー自身によるアカウントの削除を表す
+	AuditEventAccountDelete AuditEventType = "account_delete"
+	// AuditEventAccountPurge は猶予期間を過ぎたアカウントの消去を表す
+	AuditEventAccountPurge AuditEventType = "account_purge"
+	// AuditEventDataExport はユーザー自身によるデータのエクスポートを表す
+	AuditEventDataExport AuditEventType = "data_export"
+	// AuditEventImpersonationStart は管理者によるなりすましのトークンの発行を表す
+	AuditEventImpersonationStart AuditEventType = "impersonation_start"
+	// AuditEventImpersonatedRequest はなりすましのトークンによるAPIリクエストを表す
+	AuditEventImpersonatedRequest AuditEventType = "impersonated_request"
+	// AuditEventWebhookChange は管理者によるWebhookの購読の作成・変更・削除と配信の再送を表す
+	AuditEventWebhookChange AuditEventType = "webhook_change"
+	// AuditEventSCIMProvision は連携元のディレクトリからのSCIMによるユーザー・グループの登録・変更・削除を表す
+	AuditEventSCIMProvision AuditEventType = "scim_provision"
+)
+
+// AuditOutcome は監査イベントの結果を表す
+type AuditOutcome string
+
+const (
+	AuditOutcomeSuccess AuditOutcome = "success"
+	AuditOutcomeFailure AuditOutcome = "failure"
+)
+
+// SystemActorID はユーザー以外（設定による自動付与など）が操作した場合のアクターID
+const SystemActorID = "system"
+
+type (
+	// AuditEvent は認証・認可に関する監査イベントを表す（追記のみで更新しない）
+	AuditEvent struct {
+		ID        string            `json:"id"`
+		Type      AuditEventType    `json:"type"`
+		Outcome   AuditOutcome      `json:"outcome"`
+		ActorID   string            `json:"actor_id,omitempty"`
+		SubjectID string            `json:"subject_id,omitempty"`
+		IPAddress string            `json:"ip_address,omitempty"`
+		UserAgent string            `json:"user_agent,omitempty"`
+		Reason    string            `json:"reason,omitempty"`
+		Metadata  map[string]string `json:"metadata,omitempty"`
+		CreatedAt time.Time         `json:"created_at"`
+	}
+
+	// AuditEventFilter は監査イベントの検索条件を表す
+	// UserIDはActorIDまたはSubjectIDのいずれかに一致するイベントを対象とする
+	AuditEventFilter struct {
+		UserID    string
+		ActorID   string
+		IPAddress string
+		Type      AuditEventType
+		Outcome   AuditOutcome
+		From      time.Time
+		To        time.Time
+		Limit     int
+		Cursor    string
+	}
+
+	// AuditEventPage は監査イベントの検索結果の1ページを表す
+	AuditEventPage struct {
+		Events     []*AuditEvent `json:"events"`
+		NextCursor string        `json:"next_cursor,omitempty"`
+	}
+)
+
+const (
+	// DefaultAuditPageSize は1ページあたりのデフォルト件数
+	DefaultAuditPageSize = 50
+	// MaxAuditPageSize は1ページあたりの最大件数
+	MaxAuditPageSize = 200
+)
+
+// NewAuditEvent は新しい監査イベントを作成する
+func NewAuditEvent(eventType AuditEventType, outcome AuditOutcome, actorID, subjectID string) (*AuditEvent, error) {
+	if strings.TrimSpace(string(eventType)) == "" {
+		return nil, errors.New("event type cannot be empty")
+	}
+	if outcome != AuditOutcomeSuccess && outcome != AuditOutcomeFailure {
+		return nil, errors.New("invalid audit outcome")
+	}
+
+	return &AuditEvent{
+		Type:      eventType,
+		Outcome:   outcome,
+		ActorID:   actorID,
+		SubjectID: subjectID,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// Matches はイベントが検索条件に一致するかどうかを確認する
+func (f *AuditEventFilter) Matches(event *AuditEvent) bool {
+	if f.UserID != "" && event.ActorID != f.UserID && event.SubjectID != f.UserID {
+		return false
+	}
+	if f.ActorID != "" && event.ActorID != f.ActorID {
+		return false
+	}
+	if f.IPAddress != "" && event.IPAddress != f.IPAddress {
+		return false
+	}
+	if f.Type != "" && event.Type != f.Type {
+		return false
+	}
+	if f.Outcome != "" && event.Outcome != f.Outcome {
+		return false
+	}
+	if !f.From.IsZero() && event.CreatedAt.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !event.CreatedAt.Before(f.To) {
+		return false
+	}
+	return true
+}
+
+// Pseudonymize は消去したユーザーをイベントから識別できないようにする
+// ユーザーIDを仮名に置き換え、IPアドレス・User-Agentと端末を特定するメタデータを削除する
+// イベントがユーザーに関係しない場合はfalseを返す
+func (e *AuditEvent) Pseudonymize(userID, pseudonym string) bool {
+	if userID == "" || (e.ActorID != userID && e.SubjectID != userID) {
+		return false
+	}
+
+	if e.ActorID == userID {
+		e.ActorID = pseudonym
+	}
+	if e.SubjectID == userID {
+		e.SubjectID = pseudonym
+	}
+	e.IPAddress = ""
+	e.UserAgent = ""
+	if len(e.Metadata) > 0 {
+		metadata := make(map[string]string, len(e.Metadata))
+		for key, value := range e.Metadata {
+			if key != "device" && key != "ip_range" {
+				metadata[key] = value
+			}
+		}
+		e.Metadata = metadata
+	}
+	return true
+}
+
+// PageSize は上限を考慮した1ページあたりの件数を返す
+func (f *AuditEventFilter) PageSize() int {
+	if f.Limit <= 0 {
+		return DefaultAuditPageSize
+	}
+	if f.Limit > MaxAuditPageSize {
+		return MaxAuditPageSize
+	}
+	return f.Limit
+}
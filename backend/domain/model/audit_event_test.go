@@ -0,0 +1,118 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditEvent_NewAuditEvent(t *testing.T) {
+	tests := []struct {
+		testName  string
+		eventType AuditEventType
+		outcome   AuditOutcome
+		wantErr   bool
+	}{
+		{
+			testName:  "正常なイベント作成",
+			eventType: AuditEventLogin,
+			outcome:   AuditOutcomeSuccess,
+			wantErr:   false,
+		},
+		{
+			testName:  "空の種類でエラー",
+			eventType: "",
+			outcome:   AuditOutcomeSuccess,
+			wantErr:   true,
+		},
+		{
+			testName:  "無効な結果でエラー",
+			eventType: AuditEventLogin,
+			outcome:   AuditOutcome("unknown"),
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			got, err := NewAuditEvent(tt.eventType, tt.outcome, "actor", "subject")
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, got)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.eventType, got.Type)
+				assert.WithinDuration(t, time.Now(), got.CreatedAt, time.Second)
+			}
+		})
+	}
+}
+
+func TestAuditEventFilter_Matches(t *testing.T) {
+	now := time.Now()
+	event := &AuditEvent{
+		Type:      AuditEventRoleChange,
+		Outcome:   AuditOutcomeSuccess,
+		ActorID:   "admin_1",
+		SubjectID: "user_1",
+		IPAddress: "192.0.2.1",
+		CreatedAt: now,
+	}
+
+	tests := []struct {
+		testName string
+		filter   AuditEventFilter
+		want     bool
+	}{
+		{testName: "条件なし", filter: AuditEventFilter{}, want: true},
+		{testName: "対象ユーザーで一致", filter: AuditEventFilter{UserID: "user_1"}, want: true},
+		{testName: "アクターで一致", filter: AuditEventFilter{UserID: "admin_1"}, want: true},
+		{testName: "無関係なユーザー", filter: AuditEventFilter{UserID: "user_2"}, want: false},
+		{testName: "アクター指定で不一致", filter: AuditEventFilter{ActorID: "user_1"}, want: false},
+		{testName: "IPアドレスで一致", filter: AuditEventFilter{IPAddress: "192.0.2.1"}, want: true},
+		{testName: "IPアドレスで不一致", filter: AuditEventFilter{IPAddress: "192.0.2.2"}, want: false},
+		{testName: "種類で不一致", filter: AuditEventFilter{Type: AuditEventLogin}, want: false},
+		{testName: "結果で不一致", filter: AuditEventFilter{Outcome: AuditOutcomeFailure}, want: false},
+		{testName: "期間内", filter: AuditEventFilter{From: now.Add(-time.Minute), To: now.Add(time.Minute)}, want: true},
+		{testName: "期間外", filter: AuditEventFilter{From: now.Add(time.Minute)}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.filter.Matches(event))
+		})
+	}
+}
+
+func TestAuditEventFilter_PageSize(t *testing.T) {
+	assert.Equal(t, DefaultAuditPageSize, (&AuditEventFilter{}).PageSize())
+	assert.Equal(t, 10, (&AuditEventFilter{Limit: 10}).PageSize())
+	assert.Equal(t, MaxAuditPageSize, (&AuditEventFilter{Limit: 1000}).PageSize())
+}
+
+func TestAuditEvent_Pseudonymize(t *testing.T) {
+	event := &AuditEvent{
+		ActorID:   "user_1",
+		SubjectID: "user_1",
+		IPAddress: "192.0.2.1",
+		UserAgent: "Mozilla/5.0",
+		Metadata:  map[string]string{"provider": "google", "device": "abc", "ip_range": "192.0.2.0/24"},
+	}
+	assert.True(t, event.Pseudonymize("user_1", "deleted_1"))
+	assert.Equal(t, "deleted_1", event.ActorID)
+	assert.Equal(t, "deleted_1", event.SubjectID)
+	assert.Empty(t, event.IPAddress)
+	assert.Empty(t, event.UserAgent)
+	assert.Equal(t, map[string]string{"provider": "google"}, event.Metadata)
+
+	// 管理者の操作ではアクターを残す
+	roleChange := &AuditEvent{ActorID: "admin", SubjectID: "user_1", IPAddress: "192.0.2.9"}
+	assert.True(t, roleChange.Pseudonymize("user_1", "deleted_1"))
+	assert.Equal(t, "admin", roleChange.ActorID)
+	assert.Equal(t, "deleted_1", roleChange.SubjectID)
+
+	other := &AuditEvent{ActorID: "user_2", SubjectID: "user_2", IPAddress: "192.0.2.2"}
+	assert.False(t, other.Pseudonymize("user_1", "deleted_1"))
+	assert.Equal(t, "192.0.2.2", other.IPAddress)
+}
@@ -13,6 +13,19 @@ type (
 		RefreshToken string `json:"refresh_token"`
 		ExpiresIn    int64  `json:"expires_in"`
 		TokenType    string `json:"token_type"`
+		// AuthenticatedAt はセッションを開始した（IdPで認証した）日時で、リフレッシュでは更新しない
+		AuthenticatedAt time.Time `json:"authenticated_at"`
+	}
+
+	// TokenClaims は検証済みのトークンのクレームを表す
+	TokenClaims struct {
+		// UserID はトークンで認証するユーザー（なりすましの場合はなりすまされたユーザー）
+		UserID string
+		// ActorID はなりすましを行っている管理者（RFC 8693のactクレーム、なりすましでない場合は空）
+		ActorID   string
+		ExpiresAt time.Time
+		// IssuedAt はトークンの発行日時（iatクレーム、秒単位）
+		IssuedAt time.Time
 	}
 
 	// GoogleUserInfo はGoogleから取得するユーザー情報を表す
@@ -53,6 +66,19 @@ func (a *AuthToken) IsExpired() bool {
 	return time.Now().Unix() >= a.ExpiresIn
 }
 
+// AuthenticatedWithin はセッションの開始から指定した時間が経過していないかを確認する
+func (a *AuthToken) AuthenticatedWithin(maxAge time.Duration, now time.Time) bool {
+	if a.AuthenticatedAt.IsZero() {
+		return false
+	}
+	return now.Sub(a.AuthenticatedAt) <= maxAge
+}
+
+// IsImpersonation はなりすましのトークンかどうかを確認する
+func (c *TokenClaims) IsImpersonation() bool {
+	return c.ActorID != ""
+}
+
 // ToUser はGoogleユーザー情報からユーザーエンティティを作成する
 func (g *GoogleUserInfo) ToUser() *User {
 	return &User{
@@ -60,6 +86,9 @@ func (g *GoogleUserInfo) ToUser() *User {
 		Email:     g.Email,
 		Name:      g.Name,
 		Picture:   g.Picture,
+		Role:      RoleUser,
+		Provider:  ProviderGoogle,
+		Status:    UserStatusActive,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
@@ -105,6 +105,17 @@ func TestAuthToken_IsExpired(t *testing.T) {
 	}
 }
 
+func TestAuthToken_AuthenticatedWithin(t *testing.T) {
+	now := time.Now()
+
+	token := &AuthToken{AuthenticatedAt: now.Add(-5 * time.Minute)}
+	assert.True(t, token.AuthenticatedWithin(10*time.Minute, now))
+	assert.False(t, token.AuthenticatedWithin(time.Minute, now))
+
+	// 認証日時を記録していないセッションは直近の認証とみなさない
+	assert.False(t, (&AuthToken{}).AuthenticatedWithin(10*time.Minute, now))
+}
+
 func TestGoogleUserInfo_ToUser(t *testing.T) {
 	googleUser := &GoogleUserInfo{
 		ID:            "google_123",
@@ -123,6 +134,7 @@ func TestGoogleUserInfo_ToUser(t *testing.T) {
 	assert.Equal(t, googleUser.Email, user.Email)
 	assert.Equal(t, googleUser.Name, user.Name)
 	assert.Equal(t, googleUser.Picture, user.Picture)
+	assert.Equal(t, ProviderGoogle, user.Provider)
 	assert.WithinDuration(t, time.Now(), user.CreatedAt, time.Second)
 	assert.WithinDuration(t, time.Now(), user.UpdatedAt, time.Second)
 }
@@ -0,0 +1,75 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// AvatarSizes はアップロードした画像から作成する正方形のサイズ（ピクセル、昇順）
+var AvatarSizes = []int{64, 128, 256}
+
+// AvatarFormat は保存する画像の形式を表す
+type AvatarFormat string
+
+const (
+	// AvatarFormatJPEG は透過のない画像の保存形式
+	AvatarFormatJPEG AvatarFormat = "jpeg"
+	// AvatarFormatPNG は透過のある画像の保存形式
+	AvatarFormatPNG AvatarFormat = "png"
+)
+
+// ContentType は形式に対応するMIMEタイプを返す
+func (f AvatarFormat) ContentType() string {
+	if f == AvatarFormatPNG {
+		return "image/png"
+	}
+	return "image/jpeg"
+}
+
+// Extension は形式に対応するファイルの拡張子を返す
+func (f AvatarFormat) Extension() string {
+	if f == AvatarFormatPNG {
+		return "png"
+	}
+	return "jpg"
+}
+
+// Avatar はユーザーがアップロードしたプロフィール画像を表す
+// Versionはアップロードごとに変わり、保存先のキーと画像URLのキャッシュ無効化に使う
+type Avatar struct {
+	Version    string       `json:"version"`
+	Format     AvatarFormat `json:"format"`
+	UploadedAt time.Time    `json:"uploaded_at"`
+}
+
+// Key はサイズごとの画像の保存先のキーを返す
+func (a *Avatar) Key(userID string, size int) string {
+	return fmt.Sprintf("avatars/%s/%s/%d.%s", userID, a.Version, size, a.Format.Extension())
+}
+
+// AvatarImage はリサイズ済みの1つのサイズの画像を表す
+type AvatarImage struct {
+	Size int
+	Data []byte
+}
+
+// AvatarSizeFor は要求されたサイズ以上で最も小さい保存済みのサイズを返す
+// 要求が最大のサイズを超える場合や0以下の場合は最大のサイズを返す
+func AvatarSizeFor(requested int) int {
+	largest := AvatarSizes[len(AvatarSizes)-1]
+	if requested <= 0 {
+		return largest
+	}
+	for _, size := range AvatarSizes {
+		if size >= requested {
+			return size
+		}
+	}
+	return largest
+}
+
+// Blob はBlobStoreに保存するデータを表す
+type Blob struct {
+	ContentType string
+	Data        []byte
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAvatar_Key(t *testing.T) {
+	jpeg := &Avatar{Version: "v1", Format: AvatarFormatJPEG}
+	png := &Avatar{Version: "v2", Format: AvatarFormatPNG}
+
+	assert.Equal(t, "avatars/user-1/v1/128.jpg", jpeg.Key("user-1", 128))
+	assert.Equal(t, "avatars/user-1/v2/64.png", png.Key("user-1", 64))
+	assert.Equal(t, "image/jpeg", jpeg.Format.ContentType())
+	assert.Equal(t, "image/png", png.Format.ContentType())
+}
+
+func TestAvatarSizeFor(t *testing.T) {
+	tests := []struct {
+		testName  string
+		requested int
+		want      int
+	}{
+		{testName: "指定なしは最大のサイズ", requested: 0, want: 256},
+		{testName: "保存済みのサイズと一致", requested: 128, want: 128},
+		{testName: "間のサイズは大きい方に丸める", requested: 100, want: 128},
+		{testName: "最小のサイズ未満", requested: 16, want: 64},
+		{testName: "最大のサイズを超える", requested: 1024, want: 256},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			assert.Equal(t, tt.want, AvatarSizeFor(tt.requested))
+		})
+	}
+}
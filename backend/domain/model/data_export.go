@@ -0,0 +1,78 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// DataExportStatus はデータのエクスポートの状態を表す
+type DataExportStatus string
+
+const (
+	DataExportPending DataExportStatus = "pending"
+	DataExportReady   DataExportStatus = "ready"
+	DataExportFailed  DataExportStatus = "failed"
+)
+
+// DataExport はユーザーが保有するデータのZIPアーカイブの作成要求を表す（ユーザーごとに最新の1件のみ保持する）
+type DataExport struct {
+	ID          string           `json:"id"`
+	UserID      string           `json:"-"`
+	Status      DataExportStatus `json:"status"`
+	RequestedAt time.Time        `json:"requested_at"`
+	CompletedAt *time.Time       `json:"completed_at,omitempty"`
+	// ExpiresAt はアーカイブを削除する日時（作成が完了するまではnil）
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// NewDataExport は作成待ちのエクスポートを作成する
+func NewDataExport(id, userID string) (*DataExport, error) {
+	if strings.TrimSpace(id) == "" {
+		return nil, errors.New("export id cannot be empty")
+	}
+	if strings.TrimSpace(userID) == "" {
+		return nil, errors.New("user id cannot be empty")
+	}
+
+	return &DataExport{
+		ID:          id,
+		UserID:      userID,
+		Status:      DataExportPending,
+		RequestedAt: time.Now(),
+	}, nil
+}
+
+// Key はアーカイブの保存先のキーを返す
+func (e *DataExport) Key() string {
+	return "exports/" + e.UserID + "/" + e.ID + ".zip"
+}
+
+// Complete はアーカイブの作成の完了を記録する（ttlの経過後に削除する）
+func (e *DataExport) Complete(ttl time.Duration) {
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+	e.Status = DataExportReady
+	e.CompletedAt = &now
+	e.ExpiresAt = &expiresAt
+}
+
+// Fail はアーカイブの作成の失敗を記録する
+func (e *DataExport) Fail() {
+	now := time.Now()
+	e.Status = DataExportFailed
+	e.CompletedAt = &now
+}
+
+// IsReusable は同じエクスポートを返せるかどうかを確認する
+// 失敗・期限切れのもの、timeoutを過ぎても作成が完了しないもの（作成中に停止した場合）は作り直す
+func (e *DataExport) IsReusable(now time.Time, timeout time.Duration) bool {
+	switch e.Status {
+	case DataExportPending:
+		return now.Sub(e.RequestedAt) < timeout
+	case DataExportReady:
+		return e.ExpiresAt != nil && now.Before(*e.ExpiresAt)
+	default:
+		return false
+	}
+}
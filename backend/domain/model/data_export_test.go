@@ -0,0 +1,44 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataExport_NewDataExport(t *testing.T) {
+	export, err := NewDataExport("export_1", "user_1")
+	require.NoError(t, err)
+	assert.Equal(t, DataExportPending, export.Status)
+	assert.Equal(t, "exports/user_1/export_1.zip", export.Key())
+
+	_, err = NewDataExport("", "user_1")
+	assert.Error(t, err)
+	_, err = NewDataExport("export_1", " ")
+	assert.Error(t, err)
+}
+
+func TestDataExport_IsReusable(t *testing.T) {
+	now := time.Now()
+	timeout := 10 * time.Minute
+
+	pending := &DataExport{Status: DataExportPending, RequestedAt: now.Add(-time.Minute)}
+	assert.True(t, pending.IsReusable(now, timeout))
+
+	// 作成中に停止した場合は作り直す
+	stalled := &DataExport{Status: DataExportPending, RequestedAt: now.Add(-time.Hour)}
+	assert.False(t, stalled.IsReusable(now, timeout))
+
+	ready := &DataExport{Status: DataExportPending, RequestedAt: now}
+	ready.Complete(time.Hour)
+	assert.Equal(t, DataExportReady, ready.Status)
+	assert.True(t, ready.IsReusable(now, timeout))
+	assert.False(t, ready.IsReusable(now.Add(2*time.Hour), timeout))
+
+	failed := &DataExport{Status: DataExportPending, RequestedAt: now}
+	failed.Fail()
+	assert.Equal(t, DataExportFailed, failed.Status)
+	assert.False(t, failed.IsReusable(now, timeout))
+}
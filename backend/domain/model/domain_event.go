@@ -0,0 +1,64 @@
+package model
+
+import (
+	"time"
+)
+
+// DomainEventType はドメインイベントの種類を表す
+type DomainEventType string
+
+const (
+	DomainEventUserRegistered    DomainEventType = "user.registered"
+	DomainEventUserLoggedIn      DomainEventType = "user.logged_in"
+	DomainEventSessionRevoked    DomainEventType = "session.revoked"
+	DomainEventProfileUpdated    DomainEventType = "profile.updated"
+	DomainEventUserRoleChanged   DomainEventType = "user.role_changed"
+	DomainEventUserStatusChanged DomainEventType = "user.status_changed"
+)
+
+// IsValid はイベントの種類が定義済みの値かどうかを確認する
+func (t DomainEventType) IsValid() bool {
+	switch t {
+	case DomainEventUserRegistered, DomainEventUserLoggedIn, DomainEventSessionRevoked,
+		DomainEventProfileUpdated, DomainEventUserRoleChanged, DomainEventUserStatusChanged:
+		return true
+	default:
+		return false
+	}
+}
+
+type (
+	// DomainEvent はユースケースで発生したユーザー・認証に関する出来事を表す
+	// 購読者には少なくとも1回配信されるため、IDで重複を判定する
+	DomainEvent struct {
+		ID   string          `json:"id"`
+		Type DomainEventType `json:"type"`
+		// UserID はイベントの対象のユーザー
+		UserID string `json:"user_id"`
+		// ActorID は操作したユーザー（本人の操作の場合はUserIDと同じ、システムの場合はsystem）
+		ActorID    string            `json:"actor_id,omitempty"`
+		Data       map[string]string `json:"data,omitempty"`
+		OccurredAt time.Time         `json:"occurred_at"`
+	}
+
+	// OutboxMessage はアウトボックスに保存された配信待ちのドメインイベントを表す
+	OutboxMessage struct {
+		Event *DomainEvent
+		// Attempts は配信に失敗した回数
+		Attempts int
+		// NextAttemptAt は次に配信する時刻（これより前には配信しない）
+		NextAttemptAt time.Time
+		LastError     string
+	}
+)
+
+// NewDomainEvent は現在時刻で新しいドメインイベントを作成する（IDは保存時に採番する）
+func NewDomainEvent(eventType DomainEventType, userID, actorID string, data map[string]string) *DomainEvent {
+	return &DomainEvent{
+		Type:       eventType,
+		UserID:     userID,
+		ActorID:    actorID,
+		Data:       data,
+		OccurredAt: time.Now(),
+	}
+}
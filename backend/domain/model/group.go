@@ -0,0 +1,80 @@
+package model
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Group は連携元のディレクトリからSCIMでプロビジョニングする組織・グループを表す
+type Group struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"display_name"`
+	// ExternalID は連携元のディレクトリでのID
+	ExternalID string `json:"external_id,omitempty"`
+	// MemberIDs は所属するユーザーのID（追加した順）
+	MemberIDs []string  `json:"member_ids"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewGroup は新しいグループを作成する
+func NewGroup(id, displayName, externalID string, now time.Time) (*Group, error) {
+	if strings.TrimSpace(id) == "" {
+		return nil, errors.New("group ID cannot be empty")
+	}
+	group := &Group{
+		ID:         id,
+		ExternalID: externalID,
+		MemberIDs:  []string{},
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := group.Rename(displayName, now); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// Rename はグループの表示名を変更する
+func (g *Group) Rename(displayName string, now time.Time) error {
+	displayName = strings.TrimSpace(displayName)
+	if displayName == "" {
+		return errors.New("display name cannot be empty")
+	}
+
+	g.DisplayName = displayName
+	g.UpdatedAt = now
+
+	return nil
+}
+
+// HasMember はユーザーがグループに所属しているかどうかを確認する
+func (g *Group) HasMember(userID string) bool {
+	return slices.Contains(g.MemberIDs, userID)
+}
+
+// AddMembers はユーザーをグループに追加する（所属済みのユーザーは無視する）
+func (g *Group) AddMembers(userIDs []string, now time.Time) {
+	for _, userID := range userIDs {
+		if !g.HasMember(userID) {
+			g.MemberIDs = append(g.MemberIDs, userID)
+		}
+	}
+	g.UpdatedAt = now
+}
+
+// RemoveMembers はユーザーをグループから外す（所属していないユーザーは無視する）
+func (g *Group) RemoveMembers(userIDs []string, now time.Time) {
+	g.MemberIDs = slices.DeleteFunc(g.MemberIDs, func(memberID string) bool {
+		return slices.Contains(userIDs, memberID)
+	})
+	g.UpdatedAt = now
+}
+
+// ReplaceMembers は所属するユーザーを置き換える
+func (g *Group) ReplaceMembers(userIDs []string, now time.Time) {
+	g.MemberIDs = []string{}
+	g.AddMembers(userIDs, now)
+}
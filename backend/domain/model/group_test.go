@@ -0,0 +1,41 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroup_NewGroup(t *testing.T) {
+	now := time.Now()
+
+	group, err := NewGroup("group-1", " Sales ", "dir-1", now)
+	assert.NoError(t, err)
+	assert.Equal(t, "Sales", group.DisplayName)
+	assert.Equal(t, "dir-1", group.ExternalID)
+	assert.Empty(t, group.MemberIDs)
+
+	_, err = NewGroup("group-1", " ", "", now)
+	assert.Error(t, err)
+	_, err = NewGroup("", "Sales", "", now)
+	assert.Error(t, err)
+}
+
+func TestGroup_Members(t *testing.T) {
+	now := time.Now()
+	group, err := NewGroup("group-1", "Sales", "", now)
+	assert.NoError(t, err)
+
+	// 所属済みのユーザーは重複して追加しない
+	group.AddMembers([]string{"user-1", "user-2", "user-1"}, now)
+	assert.Equal(t, []string{"user-1", "user-2"}, group.MemberIDs)
+	assert.True(t, group.HasMember("user-2"))
+
+	group.RemoveMembers([]string{"user-1", "user-3"}, now)
+	assert.Equal(t, []string{"user-2"}, group.MemberIDs)
+	assert.False(t, group.HasMember("user-1"))
+
+	group.ReplaceMembers([]string{"user-3"}, now)
+	assert.Equal(t, []string{"user-3"}, group.MemberIDs)
+}
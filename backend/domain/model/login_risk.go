@@ -0,0 +1,212 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"math"
+	"net"
+	"strings"
+	"time"
+)
+
+// RiskSignal はログインのリスク判定に寄与する兆候を表す
+type RiskSignal string
+
+const (
+	// RiskSignalNewDevice は過去にログインしたことのない端末からのログイン
+	RiskSignalNewDevice RiskSignal = "new_device"
+	// RiskSignalNewIPRange は過去にログインしたことのないIPアドレス帯からのログイン
+	RiskSignalNewIPRange RiskSignal = "new_ip_range"
+	// RiskSignalImpossibleTravel は前回ログイン地点から物理的に移動できない距離でのログイン
+	RiskSignalImpossibleTravel RiskSignal = "impossible_travel"
+	// RiskSignalFailureBurst は同じIPアドレスからのログイン失敗が短時間に集中している
+	RiskSignalFailureBurst RiskSignal = "failure_burst"
+)
+
+// riskSignalWeights は兆候ごとのスコア
+var riskSignalWeights = map[RiskSignal]int{
+	RiskSignalNewDevice:        30,
+	RiskSignalNewIPRange:       20,
+	RiskSignalImpossibleTravel: 60,
+	RiskSignalFailureBurst:     40,
+}
+
+// RiskLevel はログインのリスクレベルを表す
+type RiskLevel string
+
+const (
+	RiskLevelLow    RiskLevel = "low"
+	RiskLevelMedium RiskLevel = "medium"
+	RiskLevelHigh   RiskLevel = "high"
+)
+
+const (
+	// MediumRiskScore はmediumと判定するスコアの下限
+	MediumRiskScore = 30
+	// HighRiskScore はhighと判定するスコアの下限
+	HighRiskScore = 60
+	// MaxRiskScore はスコアの上限
+	MaxRiskScore = 100
+)
+
+// LoginAlertAction は不審なログインに対して通知で求める対応を表す
+type LoginAlertAction string
+
+const (
+	// LoginAlertActionEmail はユーザーへのメール通知のみを行う
+	LoginAlertActionEmail LoginAlertAction = "email"
+	// LoginAlertActionUrgentEmail は警告を強調したメール通知を送る（追加の本人確認は行わない）
+	LoginAlertActionUrgentEmail LoginAlertAction = "urgent_email"
+)
+
+type (
+	// RiskAssessment はログインのリスク判定結果を表す
+	RiskAssessment struct {
+		Score   int          `json:"score"`
+		Level   RiskLevel    `json:"level"`
+		Signals []RiskSignal `json:"signals"`
+	}
+
+	// GeoLocation はIPアドレスから推定した位置を表す
+	GeoLocation struct {
+		Country   string  `json:"country,omitempty"`
+		City      string  `json:"city,omitempty"`
+		Latitude  float64 `json:"latitude"`
+		Longitude float64 `json:"longitude"`
+	}
+
+	// LoginAlert は不審なログインの通知内容を表す
+	LoginAlert struct {
+		Action     LoginAlertAction
+		UserID     string
+		Email      string
+		Name       string
+		Assessment *RiskAssessment
+		IPAddress  string
+		UserAgent  string
+		Location   *GeoLocation
+		OccurredAt time.Time
+		// RevokeURL は「心当たりがない」場合にすべてのセッションを無効化するリンク
+		RevokeURL string
+	}
+
+	// RevocationToken は通知に含める「心当たりがない」リンク用のワンタイムトークンを表す
+	RevocationToken struct {
+		Token     string
+		UserID    string
+		Device    string
+		IPRange   string
+		ExpiresAt time.Time
+	}
+)
+
+// NewRiskAssessment は検出した兆候からリスク判定結果を作成する
+func NewRiskAssessment(signals []RiskSignal) *RiskAssessment {
+	score := 0
+	for _, signal := range signals {
+		score += riskSignalWeights[signal]
+	}
+	score = min(score, MaxRiskScore)
+
+	level := RiskLevelLow
+	switch {
+	case score >= HighRiskScore:
+		level = RiskLevelHigh
+	case score >= MediumRiskScore:
+		level = RiskLevelMedium
+	}
+
+	if signals == nil {
+		signals = []RiskSignal{}
+	}
+	return &RiskAssessment{
+		Score:   score,
+		Level:   level,
+		Signals: signals,
+	}
+}
+
+// HasSignal は指定した兆候が検出されたかどうかを確認する
+func (r *RiskAssessment) HasSignal(signal RiskSignal) bool {
+	for _, s := range r.Signals {
+		if s == signal {
+			return true
+		}
+	}
+	return false
+}
+
+// AlertAction はリスクレベルに応じた通知の種類を返す（通知不要の場合は空文字）
+func (r *RiskAssessment) AlertAction() LoginAlertAction {
+	switch r.Level {
+	case RiskLevelHigh:
+		return LoginAlertActionUrgentEmail
+	case RiskLevelMedium:
+		return LoginAlertActionEmail
+	default:
+		return ""
+	}
+}
+
+// DistanceKm は2地点間の大圏距離をキロメートルで返す
+func (g *GeoLocation) DistanceKm(other *GeoLocation) float64 {
+	const earthRadiusKm = 6371.0
+
+	lat1 := g.Latitude * math.Pi / 180
+	lat2 := other.Latitude * math.Pi / 180
+	dLat := lat2 - lat1
+	dLon := (other.Longitude - g.Longitude) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// DeviceFingerprint はUser-Agentから端末の識別子を算出する（User-Agentが空の場合は空文字）
+func DeviceFingerprint(userAgent string) string {
+	normalized := strings.ToLower(strings.TrimSpace(userAgent))
+	if normalized == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:8])
+}
+
+// IPRange はIPアドレスが属するネットワーク帯（IPv4は/24、IPv6は/48）を返す
+// 不正なアドレスの場合は空文字を返す
+func IPRange(ip string) string {
+	parsed := net.ParseIP(strings.TrimSpace(ip))
+	if parsed == nil {
+		return ""
+	}
+	if v4 := parsed.To4(); v4 != nil {
+		return v4.Mask(net.CIDRMask(24, 32)).String() + "/24"
+	}
+	return parsed.Mask(net.CIDRMask(48, 128)).String() + "/48"
+}
+
+// NewRevocationToken は新しいRevocationTokenを作成する
+func NewRevocationToken(token, userID, device, ipRange string, ttl time.Duration) (*RevocationToken, error) {
+	if strings.TrimSpace(token) == "" {
+		return nil, errors.New("token cannot be empty")
+	}
+	if strings.TrimSpace(userID) == "" {
+		return nil, errors.New("userID cannot be empty")
+	}
+	if ttl <= 0 {
+		return nil, errors.New("ttl must be positive")
+	}
+
+	return &RevocationToken{
+		Token:     token,
+		UserID:    userID,
+		Device:    device,
+		IPRange:   ipRange,
+		ExpiresAt: time.Now().Add(ttl),
+	}, nil
+}
+
+// IsExpired はトークンが期限切れかどうかを確認する
+func (r *RevocationToken) IsExpired() bool {
+	return !time.Now().Before(r.ExpiresAt)
+}
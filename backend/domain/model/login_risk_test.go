@@ -0,0 +1,138 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRiskAssessment_NewRiskAssessment(t *testing.T) {
+	tests := []struct {
+		testName       string
+		signals        []RiskSignal
+		expectedScore  int
+		expectedLevel  RiskLevel
+		expectedAction LoginAlertAction
+	}{
+		{
+			testName:       "兆候なし",
+			signals:        nil,
+			expectedScore:  0,
+			expectedLevel:  RiskLevelLow,
+			expectedAction: "",
+		},
+		{
+			testName:       "新しいIPアドレス帯のみ",
+			signals:        []RiskSignal{RiskSignalNewIPRange},
+			expectedScore:  20,
+			expectedLevel:  RiskLevelLow,
+			expectedAction: "",
+		},
+		{
+			testName:       "新しい端末と新しいIPアドレス帯",
+			signals:        []RiskSignal{RiskSignalNewDevice, RiskSignalNewIPRange},
+			expectedScore:  50,
+			expectedLevel:  RiskLevelMedium,
+			expectedAction: LoginAlertActionEmail,
+		},
+		{
+			testName:       "不可能な移動",
+			signals:        []RiskSignal{RiskSignalImpossibleTravel},
+			expectedScore:  60,
+			expectedLevel:  RiskLevelHigh,
+			expectedAction: LoginAlertActionUrgentEmail,
+		},
+		{
+			testName:       "スコアは上限で打ち止め",
+			signals:        []RiskSignal{RiskSignalNewDevice, RiskSignalNewIPRange, RiskSignalImpossibleTravel, RiskSignalFailureBurst},
+			expectedScore:  MaxRiskScore,
+			expectedLevel:  RiskLevelHigh,
+			expectedAction: LoginAlertActionUrgentEmail,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			got := NewRiskAssessment(tt.signals)
+			assert.Equal(t, tt.expectedScore, got.Score)
+			assert.Equal(t, tt.expectedLevel, got.Level)
+			assert.Equal(t, tt.expectedAction, got.AlertAction())
+			assert.NotNil(t, got.Signals)
+			for _, signal := range tt.signals {
+				assert.True(t, got.HasSignal(signal))
+			}
+		})
+	}
+}
+
+func TestGeoLocation_DistanceKm(t *testing.T) {
+	tokyo := &GeoLocation{Latitude: 35.6762, Longitude: 139.6503}
+	osaka := &GeoLocation{Latitude: 34.6937, Longitude: 135.5023}
+	newYork := &GeoLocation{Latitude: 40.7128, Longitude: -74.0060}
+
+	assert.InDelta(t, 0, tokyo.DistanceKm(tokyo), 0.001)
+	assert.InDelta(t, 397, tokyo.DistanceKm(osaka), 5)
+	assert.InDelta(t, 10850, tokyo.DistanceKm(newYork), 50)
+	assert.InDelta(t, tokyo.DistanceKm(newYork), newYork.DistanceKm(tokyo), 0.001)
+}
+
+func TestDeviceFingerprint(t *testing.T) {
+	chrome := "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) Chrome/120.0"
+
+	assert.Len(t, DeviceFingerprint(chrome), 16)
+	assert.Equal(t, DeviceFingerprint(chrome), DeviceFingerprint("  "+chrome+" "))
+	assert.NotEqual(t, DeviceFingerprint(chrome), DeviceFingerprint("curl/8.0"))
+	assert.Empty(t, DeviceFingerprint(""))
+}
+
+func TestIPRange(t *testing.T) {
+	tests := []struct {
+		ip       string
+		expected string
+	}{
+		{ip: "192.0.2.15", expected: "192.0.2.0/24"},
+		{ip: "192.0.2.200", expected: "192.0.2.0/24"},
+		{ip: "2001:db8:1234:5678::1", expected: "2001:db8:1234::/48"},
+		{ip: "::ffff:198.51.100.7", expected: "198.51.100.0/24"},
+		{ip: "invalid", expected: ""},
+		{ip: "", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IPRange(tt.ip))
+		})
+	}
+}
+
+func TestRevocationToken_NewRevocationToken(t *testing.T) {
+	tests := []struct {
+		testName string
+		token    string
+		userID   string
+		ttl      time.Duration
+		wantErr  bool
+	}{
+		{testName: "正常な作成", token: "token", userID: "user_1", ttl: time.Hour},
+		{testName: "空のトークンでエラー", token: "", userID: "user_1", ttl: time.Hour, wantErr: true},
+		{testName: "空のユーザーIDでエラー", token: "token", userID: "", ttl: time.Hour, wantErr: true},
+		{testName: "不正なTTLでエラー", token: "token", userID: "user_1", ttl: 0, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			got, err := NewRevocationToken(tt.token, tt.userID, "device", "192.0.2.0/24", tt.ttl)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, got)
+				return
+			}
+			assert.NoError(t, err)
+			assert.False(t, got.IsExpired())
+
+			got.ExpiresAt = time.Now().Add(-time.Second)
+			assert.True(t, got.IsExpired())
+		})
+	}
+}
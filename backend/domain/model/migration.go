@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// MigrationStatus はデータストアのマイグレーションと適用状況を表す
+type MigrationStatus struct {
+	Version int
+	Name    string
+	// AppliedAt は適用した日時（未適用の場合はnil）
+	AppliedAt *time.Time
+}
+
+// IsApplied はマイグレーションが適用済みかどうかを確認する
+func (m *MigrationStatus) IsApplied() bool {
+	return m.AppliedAt != nil
+}
@@ -0,0 +1,101 @@
+package model
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+)
+
+type (
+	// OAuthState はOAuth認可リクエストのstateと戻り先を表す
+	// NonceHashはフローを開始したブラウザのCookieに保存したnonceのハッシュで、
+	// コールバックを別のブラウザで開かせるログインCSRFを防ぐために使用する
+	OAuthState struct {
+		State       string
+		NonceHash   string
+		RedirectURL string
+		ExpiresAt   time.Time
+	}
+
+	// LoginCode はSPAがトークンと交換するワンタイムコードを表す
+	LoginCode struct {
+		Code         string
+		UserID       string
+		AccessToken  string
+		RefreshToken string
+		ExpiresIn    int64
+		ExpiresAt    time.Time
+	}
+)
+
+// NewOAuthState は新しいOAuthStateを作成する（nonceはハッシュのみを保持する）
+func NewOAuthState(state, nonce, redirectURL string, ttl time.Duration) (*OAuthState, error) {
+	if strings.TrimSpace(state) == "" {
+		return nil, errors.New("state cannot be empty")
+	}
+	if strings.TrimSpace(nonce) == "" {
+		return nil, errors.New("nonce cannot be empty")
+	}
+	if ttl <= 0 {
+		return nil, errors.New("ttl must be positive")
+	}
+
+	return &OAuthState{
+		State:       state,
+		NonceHash:   hashOAuthNonce(nonce),
+		RedirectURL: redirectURL,
+		ExpiresAt:   time.Now().Add(ttl),
+	}, nil
+}
+
+// IsExpired はstateが期限切れかどうかを確認する
+func (s *OAuthState) IsExpired() bool {
+	return !time.Now().Before(s.ExpiresAt)
+}
+
+// MatchesNonce はブラウザから送られたnonceがstateの発行時のものと一致するかを確認する
+func (s *OAuthState) MatchesNonce(nonce string) bool {
+	if nonce == "" || s.NonceHash == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(hashOAuthNonce(nonce)), []byte(s.NonceHash)) == 1
+}
+
+// hashOAuthNonce はnonceのハッシュを計算する
+func hashOAuthNonce(nonce string) string {
+	sum := sha256.Sum256([]byte(nonce))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewLoginCode は新しいLoginCodeを作成する
+func NewLoginCode(code, userID, accessToken, refreshToken string, expiresIn int64, ttl time.Duration) (*LoginCode, error) {
+	if strings.TrimSpace(code) == "" {
+		return nil, errors.New("code cannot be empty")
+	}
+	if strings.TrimSpace(userID) == "" {
+		return nil, errors.New("userID cannot be empty")
+	}
+	if strings.TrimSpace(accessToken) == "" {
+		return nil, errors.New("access token cannot be empty")
+	}
+	if ttl <= 0 {
+		return nil, errors.New("ttl must be positive")
+	}
+
+	return &LoginCode{
+		Code:         code,
+		UserID:       userID,
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    expiresIn,
+		ExpiresAt:    time.Now().Add(ttl),
+	}, nil
+}
+
+// IsExpired はコードが期限切れかどうかを確認する
+func (l *LoginCode) IsExpired() bool {
+	return !time.Now().Before(l.ExpiresAt)
+}
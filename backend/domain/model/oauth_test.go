@@ -0,0 +1,154 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOAuthState_NewOAuthState(t *testing.T) {
+	tests := []struct {
+		testName    string
+		state       string
+		nonce       string
+		redirectURL string
+		ttl         time.Duration
+		wantErr     bool
+	}{
+		{
+			testName:    "正常なstate作成",
+			state:       "state_123",
+			nonce:       "nonce_123",
+			redirectURL: "http://localhost:5173/auth/callback",
+			ttl:         10 * time.Minute,
+			wantErr:     false,
+		},
+		{
+			testName:    "空のstateでエラー",
+			state:       "",
+			nonce:       "nonce_123",
+			redirectURL: "http://localhost:5173/auth/callback",
+			ttl:         10 * time.Minute,
+			wantErr:     true,
+		},
+		{
+			testName:    "空のnonceでエラー",
+			state:       "state_123",
+			nonce:       "",
+			redirectURL: "http://localhost:5173/auth/callback",
+			ttl:         10 * time.Minute,
+			wantErr:     true,
+		},
+		{
+			testName:    "無効なTTLでエラー",
+			state:       "state_123",
+			nonce:       "nonce_123",
+			redirectURL: "http://localhost:5173/auth/callback",
+			ttl:         0,
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			got, err := NewOAuthState(tt.state, tt.nonce, tt.redirectURL, tt.ttl)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, got)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.state, got.State)
+				assert.Equal(t, tt.redirectURL, got.RedirectURL)
+				assert.NotEqual(t, tt.nonce, got.NonceHash)
+				assert.False(t, got.IsExpired())
+			}
+		})
+	}
+}
+
+func TestOAuthState_MatchesNonce(t *testing.T) {
+	state, err := NewOAuthState("state_123", "nonce_123", "http://localhost:5173/", time.Minute)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		testName string
+		nonce    string
+		expected bool
+	}{
+		{testName: "発行時のnonceと一致", nonce: "nonce_123", expected: true},
+		{testName: "異なるnonceは不一致", nonce: "nonce_456", expected: false},
+		{testName: "nonceがない場合は不一致", nonce: "", expected: false},
+		{testName: "ハッシュそのものは不一致", nonce: state.NonceHash, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			assert.Equal(t, tt.expected, state.MatchesNonce(tt.nonce))
+		})
+	}
+}
+
+func TestOAuthState_IsExpired(t *testing.T) {
+	state := &OAuthState{State: "state_123", ExpiresAt: time.Now().Add(-time.Second)}
+	assert.True(t, state.IsExpired())
+}
+
+func TestLoginCode_NewLoginCode(t *testing.T) {
+	tests := []struct {
+		testName    string
+		code        string
+		userID      string
+		accessToken string
+		ttl         time.Duration
+		wantErr     bool
+	}{
+		{
+			testName:    "正常なコード作成",
+			code:        "code_123",
+			userID:      "user_123",
+			accessToken: "access_token",
+			ttl:         time.Minute,
+			wantErr:     false,
+		},
+		{
+			testName:    "空のコードでエラー",
+			code:        "",
+			userID:      "user_123",
+			accessToken: "access_token",
+			ttl:         time.Minute,
+			wantErr:     true,
+		},
+		{
+			testName:    "空のユーザーIDでエラー",
+			code:        "code_123",
+			userID:      "",
+			accessToken: "access_token",
+			ttl:         time.Minute,
+			wantErr:     true,
+		},
+		{
+			testName:    "空のアクセストークンでエラー",
+			code:        "code_123",
+			userID:      "user_123",
+			accessToken: "",
+			ttl:         time.Minute,
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			got, err := NewLoginCode(tt.code, tt.userID, tt.accessToken, "refresh_token", 3600, tt.ttl)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, got)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.code, got.Code)
+				assert.Equal(t, tt.userID, got.UserID)
+				assert.False(t, got.IsExpired())
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimitStrategy はレート制限のアルゴリズムを表す
+type RateLimitStrategy string
+
+const (
+	// RateLimitStrategyTokenBucket はトークンバケット方式（バーストを許容しつつ平均レートを制限）
+	RateLimitStrategyTokenBucket RateLimitStrategy = "token_bucket"
+	// RateLimitStrategySlidingWindow はスライディングウィンドウ方式（直近の期間内のリクエスト数を厳密に制限）
+	RateLimitStrategySlidingWindow RateLimitStrategy = "sliding_window"
+)
+
+type (
+	// RateLimitRule はレート制限のルールを表す
+	// トークンバケットの場合はLimitが容量、Window毎にLimit個のトークンが補充される
+	RateLimitRule struct {
+		Strategy RateLimitStrategy
+		Limit    int
+		Window   time.Duration
+	}
+
+	// RateLimitResult はレート制限の判定結果を表す
+	RateLimitResult struct {
+		Allowed    bool
+		Limit      int
+		Remaining  int
+		ResetAfter time.Duration
+		RetryAfter time.Duration
+	}
+)
+
+// NewRateLimitRule は新しいRateLimitRuleを作成する
+func NewRateLimitRule(strategy RateLimitStrategy, limit int, window time.Duration) (*RateLimitRule, error) {
+	if strategy != RateLimitStrategyTokenBucket && strategy != RateLimitStrategySlidingWindow {
+		return nil, fmt.Errorf("unknown rate limit strategy: %s", strategy)
+	}
+	if limit <= 0 {
+		return nil, errors.New("limit must be positive")
+	}
+	if window <= 0 {
+		return nil, errors.New("window must be positive")
+	}
+
+	return &RateLimitRule{
+		Strategy: strategy,
+		Limit:    limit,
+		Window:   window,
+	}, nil
+}
+
+// ParseRateLimitRule は "sliding_window:10/1m" 形式の文字列からルールを作成する
+func ParseRateLimitRule(value string) (*RateLimitRule, error) {
+	strategy, rest, found := strings.Cut(strings.TrimSpace(value), ":")
+	if !found {
+		return nil, fmt.Errorf("invalid rate limit rule %q: expected <strategy>:<limit>/<window>", value)
+	}
+
+	limitStr, windowStr, found := strings.Cut(rest, "/")
+	if !found {
+		return nil, fmt.Errorf("invalid rate limit rule %q: expected <strategy>:<limit>/<window>", value)
+	}
+
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rate limit %q: %w", limitStr, err)
+	}
+
+	window, err := time.ParseDuration(windowStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rate limit window %q: %w", windowStr, err)
+	}
+
+	return NewRateLimitRule(RateLimitStrategy(strategy), limit, window)
+}
+
+// String はルールを "sliding_window:10/1m0s" 形式で返す
+func (r RateLimitRule) String() string {
+	return fmt.Sprintf("%s:%d/%s", r.Strategy, r.Limit, r.Window)
+}
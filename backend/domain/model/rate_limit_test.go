@@ -0,0 +1,61 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimitRule_ParseRateLimitRule(t *testing.T) {
+	tests := []struct {
+		testName string
+		value    string
+		want     *RateLimitRule
+		wantErr  bool
+	}{
+		{
+			testName: "スライディングウィンドウ",
+			value:    "sliding_window:10/1m",
+			want:     &RateLimitRule{Strategy: RateLimitStrategySlidingWindow, Limit: 10, Window: time.Minute},
+		},
+		{
+			testName: "トークンバケット",
+			value:    "token_bucket:30/30s",
+			want:     &RateLimitRule{Strategy: RateLimitStrategyTokenBucket, Limit: 30, Window: 30 * time.Second},
+		},
+		{
+			testName: "未知の方式でエラー",
+			value:    "fixed_window:10/1m",
+			wantErr:  true,
+		},
+		{
+			testName: "形式不正でエラー",
+			value:    "10/1m",
+			wantErr:  true,
+		},
+		{
+			testName: "0件の制限でエラー",
+			value:    "token_bucket:0/1m",
+			wantErr:  true,
+		},
+		{
+			testName: "無効な期間でエラー",
+			value:    "token_bucket:10/forever",
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			got, err := ParseRateLimitRule(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, got)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package model
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// SCIMActorID はSCIMによるプロビジョニングで操作した場合のアクターID
+const SCIMActorID = "scim"
+
+const (
+	// MaxSCIMPageSize はSCIMの一覧の1ページあたりの最大件数
+	MaxSCIMPageSize = 200
+	// DefaultSCIMPageSize はSCIMの一覧の1ページあたりのデフォルト件数
+	DefaultSCIMPageSize = 100
+)
+
+// SCIMFilter はSCIMの一覧の絞り込み条件を表す
+// 連携元のディレクトリが既存のリソースを探すのに使う「属性 eq "値"」の形式のみに対応する
+type SCIMFilter struct {
+	// Attribute は小文字にした属性名（例: username, externalid）
+	Attribute string
+	Value     string
+}
+
+// ParseSCIMFilter はSCIMのfilterパラメータを解析する（空の場合はnilを返す）
+func ParseSCIMFilter(filter string) (*SCIMFilter, error) {
+	filter = strings.TrimSpace(filter)
+	if filter == "" {
+		return nil, nil
+	}
+
+	attribute, rest, ok := strings.Cut(filter, " ")
+	if !ok {
+		return nil, errors.New("filter must be in the form: attribute eq \"value\"")
+	}
+	operator, value, ok := strings.Cut(strings.TrimSpace(rest), " ")
+	if !ok || !strings.EqualFold(operator, "eq") {
+		return nil, errors.New("only the eq operator is supported")
+	}
+	value, err := strconv.Unquote(strings.TrimSpace(value))
+	if err != nil {
+		return nil, errors.New("filter value must be a quoted string")
+	}
+
+	return &SCIMFilter{Attribute: strings.ToLower(attribute), Value: value}, nil
+}
+
+// SCIMPageBounds はSCIMの一覧のstartIndex（1始まり）とcountから、スライスの範囲を返す
+// startIndexが1未満の場合は1、countが負の場合は0、未指定（nil）の場合はデフォルト件数とする
+func SCIMPageBounds(total, startIndex int, count *int) (int, int) {
+	size := DefaultSCIMPageSize
+	if count != nil {
+		size = min(max(*count, 0), MaxSCIMPageSize)
+	}
+	start := min(max(startIndex, 1)-1, total)
+	return start, min(start+size, total)
+}
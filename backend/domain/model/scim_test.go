@@ -0,0 +1,86 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSCIMFilter(t *testing.T) {
+	tests := []struct {
+		testName string
+		filter   string
+		want     *SCIMFilter
+		wantErr  bool
+	}{
+		{
+			testName: "空の場合は絞り込まない",
+			filter:   " ",
+			want:     nil,
+		},
+		{
+			testName: "属性名は大文字・小文字を区別しない",
+			filter:   `userName eq "test@example.com"`,
+			want:     &SCIMFilter{Attribute: "username", Value: "test@example.com"},
+		},
+		{
+			testName: "値に空白を含む",
+			filter:   `displayName EQ "Sales Team"`,
+			want:     &SCIMFilter{Attribute: "displayname", Value: "Sales Team"},
+		},
+		{
+			testName: "eq以外の演算子でエラー",
+			filter:   `userName co "example.com"`,
+			wantErr:  true,
+		},
+		{
+			testName: "引用符のない値でエラー",
+			filter:   `userName eq test@example.com`,
+			wantErr:  true,
+		},
+		{
+			testName: "条件のない属性でエラー",
+			filter:   `userName`,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			got, err := ParseSCIMFilter(tt.filter)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSCIMPageBounds(t *testing.T) {
+	count := func(n int) *int { return &n }
+
+	tests := []struct {
+		testName   string
+		total      int
+		startIndex int
+		count      *int
+		wantStart  int
+		wantEnd    int
+	}{
+		{testName: "未指定の場合は先頭からデフォルト件数", total: 150, startIndex: 0, count: nil, wantStart: 0, wantEnd: DefaultSCIMPageSize},
+		{testName: "startIndexは1始まり", total: 10, startIndex: 3, count: count(2), wantStart: 2, wantEnd: 4},
+		{testName: "countが0の場合は件数のみ", total: 10, startIndex: 1, count: count(0), wantStart: 0, wantEnd: 0},
+		{testName: "最大件数で切り詰める", total: 500, startIndex: 1, count: count(1000), wantStart: 0, wantEnd: MaxSCIMPageSize},
+		{testName: "範囲外のstartIndex", total: 10, startIndex: 20, count: nil, wantStart: 10, wantEnd: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			start, end := SCIMPageBounds(tt.total, tt.startIndex, tt.count)
+			assert.Equal(t, tt.wantStart, start)
+			assert.Equal(t, tt.wantEnd, end)
+		})
+	}
+}
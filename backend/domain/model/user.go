@@ -3,18 +3,79 @@ package model
 import (
 	"errors"
 	"net/mail"
+	"net/url"
 	"strings"
 	"time"
 )
 
+// MaxPictureURLLength はユーザーが指定できるプロフィール画像のURLの最大長
+const MaxPictureURLLength = 2048
+
+// Role はユーザーの権限を表す
+type Role string
+
+const (
+	RoleUser  Role = "user"
+	RoleAdmin Role = "admin"
+)
+
+// IsValid はロールが定義済みの値かどうかを確認する
+func (r Role) IsValid() bool {
+	return r == RoleUser || r == RoleAdmin
+}
+
+// Provider はユーザーがログインに使うIDプロバイダーを表す
+type Provider string
+
+const (
+	ProviderGoogle Provider = "google"
+)
+
+// IsValid はIDプロバイダーが定義済みの値かどうかを確認する
+func (p Provider) IsValid() bool {
+	return p == ProviderGoogle
+}
+
 // User はユーザーエンティティを表す
 type User struct {
-	ID        string    `json:"id"`
-	Email     string    `json:"email"`
-	Name      string    `json:"name"`
-	Picture   string    `json:"picture"`
+	ID      string `json:"id"`
+	Email   string `json:"email"`
+	Name    string `json:"name"`
+	Picture string `json:"picture"`
+	Role    Role   `json:"role"`
+	// Provider はログインに使うIDプロバイダー（IDはプロバイダーでのユーザーID）
+	Provider  Provider  `json:"provider"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	// Status はアカウントの状態（停止・無効化されたユーザーはログイン・APIの利用ができない）
+	Status UserStatus `json:"status"`
+	// StatusReason は停止・無効化の理由
+	StatusReason string `json:"status_reason,omitempty"`
+	// SuspendedUntil は一時停止の期限（無期限の場合はnil）
+	SuspendedUntil *time.Time `json:"suspended_until,omitempty"`
+	// StatusChangedAt は最後に状態を変更した日時（作成後に変更していない場合はnil）
+	StatusChangedAt *time.Time `json:"status_changed_at,omitempty"`
+	// DeletedAt はユーザー自身が削除した日時（猶予期間の後に完全に消去する）
+	DeletedAt *time.Time `json:"-"`
+	// NameEdited・PictureEditedはユーザー自身が編集した項目で、ログイン時にIdPの値で上書きしない
+	NameEdited    bool `json:"-"`
+	PictureEdited bool `json:"-"`
+	// Avatar はアップロードしたプロフィール画像（未アップロードの場合はnil）
+	Avatar *Avatar `json:"-"`
+	// ExternalID はSCIMでプロビジョニングした連携元のディレクトリでのID
+	ExternalID string `json:"-"`
+	// SessionsRevokedAt はすべてのセッションを無効化した日時（これ以前に発行したアクセストークンは使えない）
+	SessionsRevokedAt *time.Time `json:"-"`
+	// RoleProvisioned はSCIMの管理者グループによって管理者ロールが付与されたかどうか
+	// 管理者がロールを変更した場合はfalseに戻し、グループから外れても降格しない
+	RoleProvisioned bool `json:"-"`
+}
+
+// PublicProfile は他のユーザーに公開するプロフィールを表す（メールアドレスやロールは含めない）
+type PublicProfile struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Picture string `json:"picture"`
 }
 
 // NewUser は新しいユーザーを作成する
@@ -24,6 +85,9 @@ func NewUser(id, email, name, picture string) (*User, error) {
 		Email:     email,
 		Name:      name,
 		Picture:   picture,
+		Role:      RoleUser,
+		Provider:  ProviderGoogle,
+		Status:    UserStatusActive,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
@@ -35,6 +99,18 @@ func NewUser(id, email, name, picture string) (*User, error) {
 	return user, nil
 }
 
+// NewProvisionedUser はSCIMで連携元のディレクトリから登録するユーザーを作成する
+// 本人が認証済みのメールアドレスでログインするまではpending_verificationとする
+func NewProvisionedUser(id, email, name, externalID string) (*User, error) {
+	user, err := NewUser(id, email, name, "")
+	if err != nil {
+		return nil, err
+	}
+	user.Status = UserStatusPendingVerification
+	user.ExternalID = externalID
+	return user, nil
+}
+
 // IsValid はユーザーデータが有効かどうかを検証する
 func (u *User) IsValid() bool {
 	if strings.TrimSpace(u.ID) == "" {
@@ -65,6 +141,181 @@ func (u *User) UpdateProfile(name, picture string) error {
 	return nil
 }
 
+// EditProfile はユーザー自身によるプロフィールの編集を反映する（nilの項目は変更しない）
+// 編集した項目は以降のログインでIdPの値により上書きしない
+func (u *User) EditProfile(name, picture *string) error {
+	newName, newPicture := u.Name, u.Picture
+	if name != nil {
+		newName = *name
+	}
+	if picture != nil {
+		if err := ValidatePictureURL(*picture); err != nil {
+			return err
+		}
+		newPicture = *picture
+	}
+
+	if err := u.UpdateProfile(newName, newPicture); err != nil {
+		return err
+	}
+
+	if name != nil {
+		u.NameEdited = true
+	}
+	if picture != nil {
+		// 画像のURLを指定した場合はアップロードした画像を使わない
+		u.PictureEdited = true
+		u.Avatar = nil
+	}
+	return nil
+}
+
+// ValidatePictureURL はユーザーが指定したプロフィール画像のURLがhttpまたはhttpsの絶対URLかを検証する（空の場合は画像を使わない）
+// 公開プロフィールで他のユーザーにも表示するため、javascript:やdata:などのスキームは許可しない
+func ValidatePictureURL(picture string) error {
+	if picture == "" {
+		return nil
+	}
+	if len(picture) > MaxPictureURLLength {
+		return errors.New("picture url is too long")
+	}
+	parsed, err := url.Parse(picture)
+	if err != nil {
+		return errors.New("picture must be a valid url")
+	}
+	if parsed.Scheme != "https" && parsed.Scheme != "http" {
+		return errors.New("picture url must use http or https")
+	}
+	if parsed.Host == "" || parsed.User != nil {
+		return errors.New("picture url must have a host and no user info")
+	}
+	return nil
+}
+
+// SyncProfile はIdPから取得したプロフィールを反映する（ユーザーが編集した項目は変更しない）
+func (u *User) SyncProfile(name, picture string) error {
+	if u.NameEdited {
+		name = u.Name
+	}
+	if u.PictureEdited {
+		picture = u.Picture
+	}
+	return u.UpdateProfile(name, picture)
+}
+
+// SetAvatar はアップロードした画像をプロフィール画像にする
+// 以降のログインでIdPの画像により上書きしない
+func (u *User) SetAvatar(avatar *Avatar, pictureURL string) {
+	u.Avatar = avatar
+	u.Picture = pictureURL
+	u.PictureEdited = true
+	u.UpdatedAt = time.Now()
+}
+
+// RemoveAvatar はアップロードした画像を削除する（次回のログインでIdPの画像に戻る）
+func (u *User) RemoveAvatar() {
+	u.Avatar = nil
+	u.Picture = ""
+	u.PictureEdited = false
+	u.UpdatedAt = time.Now()
+}
+
+// Provision はSCIMで連携元のディレクトリから受け取ったメールアドレス・名前・外部IDを反映する
+func (u *User) Provision(email, name, externalID string) error {
+	email = strings.TrimSpace(email)
+	name = strings.TrimSpace(name)
+	if !u.isValidEmail(email) {
+		return errors.New("invalid email")
+	}
+	if name == "" {
+		return errors.New("name cannot be empty")
+	}
+
+	u.Email = email
+	u.Name = name
+	u.ExternalID = externalID
+	u.UpdatedAt = time.Now()
+
+	return nil
+}
+
+// PublicProfile は他のユーザーに公開するプロフィールを返す
+func (u *User) PublicProfile() *PublicProfile {
+	return &PublicProfile{
+		ID:      u.ID,
+		Name:    u.Name,
+		Picture: u.Picture,
+	}
+}
+
+// ChangeRole はユーザーのロールを変更する
+func (u *User) ChangeRole(role Role) error {
+	if !role.IsValid() {
+		return errors.New("invalid role")
+	}
+
+	u.Role = role
+	u.RoleProvisioned = false
+	u.UpdatedAt = time.Now()
+
+	return nil
+}
+
+// RevokeSessions はすべてのセッションを無効化した日時を記録する
+// リフレッシュトークンの削除だけでは有効期限内のアクセストークンが使えてしまうため、認証時にこの日時と発行日時を比較する
+func (u *User) RevokeSessions(now time.Time) {
+	// iatクレームは秒単位のため、同じ秒に発行したトークンも無効とする
+	revokedAt := now.Truncate(time.Second)
+	u.SessionsRevokedAt = &revokedAt
+	u.UpdatedAt = now
+}
+
+// IsSessionRevoked は指定した日時に発行したトークンがセッションの無効化より前のものかどうかを確認する
+func (u *User) IsSessionRevoked(issuedAt time.Time) bool {
+	return u.SessionsRevokedAt != nil && !issuedAt.After(*u.SessionsRevokedAt)
+}
+
+// ProvisionRole はSCIMの管理者グループのメンバーの増減によるロールの変更を反映する
+// 管理者ロールを付与した場合は、グループから外れた時に戻せるように付与元を記録する
+func (u *User) ProvisionRole(role Role) error {
+	if err := u.ChangeRole(role); err != nil {
+		return err
+	}
+	u.RoleProvisioned = role == RoleAdmin
+	return nil
+}
+
+// MarkDeleted はユーザー自身による削除を受け付ける（猶予期間の後に完全に消去する）
+func (u *User) MarkDeleted() error {
+	if u.IsDeleted() {
+		return errors.New("user is already deleted")
+	}
+
+	now := time.Now()
+	u.DeletedAt = &now
+	u.UpdatedAt = now
+
+	return nil
+}
+
+// IsDeleted はユーザーが削除済み（消去待ち）かどうかを確認する
+func (u *User) IsDeleted() bool {
+	return u.DeletedAt != nil
+}
+
+// PurgeAt は削除済みのユーザーを完全に消去する日時を返す（削除されていない場合はゼロ値）
+func (u *User) PurgeAt(gracePeriod time.Duration) time.Time {
+	if !u.IsDeleted() {
+		return time.Time{}
+	}
+	return u.DeletedAt.Add(gracePeriod)
+}
+
+// HasRole はユーザーが指定されたロールを持つかどうかを確認する
+func (u *User) HasRole(role Role) bool {
+	return u.Role == role
+}
+
 // isValidEmail はメールアドレスの形式が有効かどうかを検証する
 func (u *User) isValidEmail(email string) bool {
 	_, err := mail.ParseAddress(email)
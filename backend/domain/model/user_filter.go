@@ -0,0 +1,184 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// UserSort はユーザー一覧の並び順を表す（先頭に-を付けると降順）
+type UserSort string
+
+const (
+	UserSortCreatedAsc  UserSort = "created_at"
+	UserSortCreatedDesc UserSort = "-created_at"
+	UserSortEmailAsc    UserSort = "email"
+	UserSortEmailDesc   UserSort = "-email"
+	UserSortNameAsc     UserSort = "name"
+	UserSortNameDesc    UserSort = "-name"
+)
+
+const (
+	// DefaultUserPageSize は1ページあたりのデフォルト件数
+	DefaultUserPageSize = 50
+	// MaxUserPageSize は1ページあたりの最大件数
+	MaxUserPageSize = 200
+)
+
+// IsValid は並び順が定義済みの値かどうかを確認する（空の場合は作成日時の昇順）
+func (s UserSort) IsValid() bool {
+	switch s {
+	case "", UserSortCreatedAsc, UserSortCreatedDesc, UserSortEmailAsc, UserSortEmailDesc, UserSortNameAsc, UserSortNameDesc:
+		return true
+	default:
+		return false
+	}
+}
+
+// sortKeyTimeLayout は作成日時の並び替えキーの書式（固定長のため文字列の比較で時刻の順になる）
+const sortKeyTimeLayout = "2006-01-02T15:04:05.000000000Z"
+
+// ErrInvalidUserCursor はカーソルを解釈できない、または並び順が異なる場合のエラー
+var ErrInvalidUserCursor = errors.New("invalid user cursor")
+
+// SortKey は並び順で比較するユーザーの値を返す
+func (s UserSort) SortKey(user *User) string {
+	switch strings.TrimPrefix(string(s), "-") {
+	case "email":
+		return strings.ToLower(user.Email)
+	case "name":
+		return strings.ToLower(user.Name)
+	default:
+		return user.CreatedAt.UTC().Format(sortKeyTimeLayout)
+	}
+}
+
+// Less は並び順でaがbより前になるかどうかを返す（値が同じ場合はIDの昇順）
+func (s UserSort) Less(a, b *User) bool {
+	return s.compare(s.SortKey(a), a.ID, s.SortKey(b), b.ID) < 0
+}
+
+// After はユーザーが並び順でカーソルより後ろにあるかどうかを返す
+// カーソルに保存した値と比較するため、ページの間に並び替えの値が変わっても重複・欠落しない
+func (s UserSort) After(cursor *UserCursor, user *User) bool {
+	return s.compare(cursor.SortKey, cursor.ID, s.SortKey(user), user.ID) < 0
+}
+
+// orDefault は未指定の並び順をデフォルトの作成日時の昇順に置き換える
+func (s UserSort) orDefault() UserSort {
+	if s == "" {
+		return UserSortCreatedAsc
+	}
+	return s
+}
+
+// compare は並び替えの値とIDの組を比較する
+func (s UserSort) compare(aKey, aID, bKey, bID string) int {
+	cmp := strings.Compare(aKey, bKey)
+	if strings.HasPrefix(string(s), "-") {
+		cmp = -cmp
+	}
+	if cmp != 0 {
+		return cmp
+	}
+	return strings.Compare(aID, bID)
+}
+
+// UserCursor はユーザー一覧のページの位置（前のページの最後のユーザーの並び替えの値とID）を表す
+type UserCursor struct {
+	Sort    UserSort `json:"s"`
+	SortKey string   `json:"k"`
+	ID      string   `json:"id"`
+}
+
+// NewUserCursor はユーザーの位置を表すカーソルを作成する
+func NewUserCursor(sort UserSort, user *User) *UserCursor {
+	return &UserCursor{Sort: sort.orDefault(), SortKey: sort.SortKey(user), ID: user.ID}
+}
+
+// Encode はカーソルをクライアントに返す不透明な文字列に変換する
+func (c *UserCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeUserCursor はEncodeした文字列からカーソルを復元する
+// 並び順が異なるカーソルは位置を比較できないため、ErrInvalidUserCursorを返す
+func DecodeUserCursor(sort UserSort, value string) (*UserCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidUserCursor
+	}
+	var cursor UserCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == "" || cursor.Sort != sort.orDefault() {
+		return nil, ErrInvalidUserCursor
+	}
+	return &cursor, nil
+}
+
+type (
+	// UserFilter はユーザーの検索条件を表す
+	// 削除済み（消去待ち）のユーザーは対象としない
+	UserFilter struct {
+		// Status は指定した時刻での状態（期限を過ぎた一時停止はactive）で絞り込む
+		Status   UserStatus
+		Role     Role
+		Provider Provider
+		// CreatedFrom・CreatedToは作成日時の範囲（Fromを含み、Toを含まない）
+		CreatedFrom time.Time
+		CreatedTo   time.Time
+		// Query はメールアドレスまたは表示名の前方一致（大文字・小文字を区別しない）
+		Query string
+		Sort  UserSort
+		Limit int
+		// Cursor は前のページのNextCursor（UserCursorをエンコードした不透明な文字列）
+		Cursor string
+	}
+
+	// UserPage はユーザーの検索結果の1ページを表す
+	UserPage struct {
+		Users      []*User `json:"users"`
+		NextCursor string  `json:"next_cursor,omitempty"`
+	}
+)
+
+// Matches はユーザーが検索条件に一致するかどうかを判定する
+func (f *UserFilter) Matches(user *User, now time.Time) bool {
+	if user.IsDeleted() {
+		return false
+	}
+	if f.Status != "" && user.EffectiveStatus(now) != f.Status {
+		return false
+	}
+	if f.Role != "" && user.Role != f.Role {
+		return false
+	}
+	if f.Provider != "" && user.Provider != f.Provider {
+		return false
+	}
+	if !f.CreatedFrom.IsZero() && user.CreatedAt.Before(f.CreatedFrom) {
+		return false
+	}
+	if !f.CreatedTo.IsZero() && !user.CreatedAt.Before(f.CreatedTo) {
+		return false
+	}
+	if query := strings.ToLower(strings.TrimSpace(f.Query)); query != "" &&
+		!strings.HasPrefix(strings.ToLower(user.Email), query) &&
+		!strings.HasPrefix(strings.ToLower(user.Name), query) {
+		return false
+	}
+	return true
+}
+
+// PageSize は1ページの件数を返す（未指定の場合はデフォルト値、上限を超える場合は上限値）
+func (f *UserFilter) PageSize() int {
+	if f.Limit <= 0 {
+		return DefaultUserPageSize
+	}
+	if f.Limit > MaxUserPageSize {
+		return MaxUserPageSize
+	}
+	return f.Limit
+}
@@ -0,0 +1,111 @@
+package model
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserFilter_Matches(t *testing.T) {
+	now := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	expired := now.Add(-time.Hour)
+	deletedAt := now.Add(-time.Hour)
+	user := &User{
+		ID:        "user_1",
+		Email:     "Alice@example.com",
+		Name:      "Bob Smith",
+		Role:      RoleAdmin,
+		Provider:  ProviderGoogle,
+		Status:    UserStatusActive,
+		CreatedAt: now.Add(-24 * time.Hour),
+	}
+
+	tests := []struct {
+		testName string
+		filter   UserFilter
+		user     *User
+		want     bool
+	}{
+		{testName: "条件なし", user: user, want: true},
+		{testName: "状態が一致", filter: UserFilter{Status: UserStatusActive}, user: user, want: true},
+		{testName: "状態が不一致", filter: UserFilter{Status: UserStatusDisabled}, user: user, want: false},
+		{
+			testName: "期限を過ぎた一時停止はactive",
+			filter:   UserFilter{Status: UserStatusActive},
+			user:     &User{ID: "user_2", Status: UserStatusSuspended, SuspendedUntil: &expired},
+			want:     true,
+		},
+		{testName: "ロールが不一致", filter: UserFilter{Role: RoleUser}, user: user, want: false},
+		{testName: "IDプロバイダーが一致", filter: UserFilter{Provider: ProviderGoogle}, user: user, want: true},
+		{testName: "作成日時の範囲内", filter: UserFilter{CreatedFrom: now.Add(-48 * time.Hour), CreatedTo: now}, user: user, want: true},
+		{testName: "作成日時の範囲の終端は含まない", filter: UserFilter{CreatedTo: user.CreatedAt}, user: user, want: false},
+		{testName: "メールアドレスの前方一致（大文字・小文字を区別しない）", filter: UserFilter{Query: "alice@"}, user: user, want: true},
+		{testName: "表示名の前方一致", filter: UserFilter{Query: "bob"}, user: user, want: true},
+		{testName: "部分一致は対象外", filter: UserFilter{Query: "smith"}, user: user, want: false},
+		{testName: "削除済みのユーザーは対象外", user: &User{ID: "user_3", DeletedAt: &deletedAt}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.filter.Matches(tt.user, now))
+		})
+	}
+}
+
+func TestUserSort_Less(t *testing.T) {
+	now := time.Now()
+	users := []*User{
+		{ID: "c", Email: "a@example.com", Name: "Carol", CreatedAt: now},
+		{ID: "a", Email: "c@example.com", Name: "alice", CreatedAt: now.Add(-time.Hour)},
+		{ID: "b", Email: "B@example.com", Name: "Bob", CreatedAt: now},
+	}
+	ids := func(sortBy UserSort) []string {
+		sorted := append([]*User(nil), users...)
+		sort.Slice(sorted, func(i, j int) bool { return sortBy.Less(sorted[i], sorted[j]) })
+		result := make([]string, 0, len(sorted))
+		for _, user := range sorted {
+			result = append(result, user.ID)
+		}
+		return result
+	}
+
+	// 値が同じ場合はIDの昇順
+	assert.Equal(t, []string{"a", "b", "c"}, ids(""))
+	assert.Equal(t, []string{"b", "c", "a"}, ids(UserSortCreatedDesc))
+	assert.Equal(t, []string{"c", "b", "a"}, ids(UserSortEmailAsc))
+	assert.Equal(t, []string{"a", "b", "c"}, ids(UserSortNameAsc))
+	assert.Equal(t, []string{"c", "b", "a"}, ids(UserSortNameDesc))
+	assert.False(t, UserSort("role").IsValid())
+}
+
+func TestDecodeUserCursor(t *testing.T) {
+	user := &User{ID: "user_1", Email: "Alice@example.com", CreatedAt: time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)}
+
+	tests := []struct {
+		testName    string
+		sort        UserSort
+		value       string
+		expectError bool
+	}{
+		{testName: "同じ並び順のカーソル", sort: UserSortEmailAsc, value: NewUserCursor(UserSortEmailAsc, user).Encode()},
+		{testName: "未指定の並び順は作成日時の昇順と同じ", sort: "", value: NewUserCursor(UserSortCreatedAsc, user).Encode()},
+		{testName: "並び順が異なるカーソル", sort: UserSortNameAsc, value: NewUserCursor(UserSortEmailAsc, user).Encode(), expectError: true},
+		{testName: "ユーザーIDそのものはカーソルではない", sort: "", value: "user_1", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			cursor, err := DecodeUserCursor(tt.sort, tt.value)
+
+			if tt.expectError {
+				assert.ErrorIs(t, err, ErrInvalidUserCursor)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "user_1", cursor.ID)
+			assert.True(t, tt.sort.After(cursor, &User{ID: "user_2", Email: "alice@example.com", CreatedAt: user.CreatedAt}))
+		})
+	}
+}
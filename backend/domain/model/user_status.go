@@ -0,0 +1,104 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// UserStatus はユーザーアカウントの状態を表す
+type UserStatus string
+
+const (
+	// UserStatusActive はログイン・APIの利用ができる状態
+	UserStatusActive UserStatus = "active"
+	// UserStatusSuspended は理由を付けて一時的に停止した状態（期限を過ぎると自動的にactiveに戻る）
+	UserStatusSuspended UserStatus = "suspended"
+	// UserStatusDisabled は管理者が無効化した状態（activeに戻すまでログインできない）
+	UserStatusDisabled UserStatus = "disabled"
+	// UserStatusPendingVerification は管理者が登録し、本人が認証済みのメールアドレスでログインするのを待つ状態
+	UserStatusPendingVerification UserStatus = "pending_verification"
+)
+
+// userStatusTransitions は各状態から変更できる状態を表す
+// suspendedからsuspendedへの変更は停止の理由・期限の更新を表す
+var userStatusTransitions = map[UserStatus][]UserStatus{
+	UserStatusPendingVerification: {UserStatusActive, UserStatusSuspended, UserStatusDisabled},
+	UserStatusActive:              {UserStatusSuspended, UserStatusDisabled},
+	UserStatusSuspended:           {UserStatusActive, UserStatusSuspended, UserStatusDisabled},
+	UserStatusDisabled:            {UserStatusActive},
+}
+
+// IsValid は状態が定義済みの値かどうかを確認する
+func (s UserStatus) IsValid() bool {
+	_, ok := userStatusTransitions[s]
+	return ok
+}
+
+// CanTransitionTo は指定した状態に変更できるかどうかを確認する
+func (s UserStatus) CanTransitionTo(to UserStatus) bool {
+	for _, next := range userStatusTransitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// EffectiveStatus は指定した時刻での状態を返す
+// 期限を過ぎた一時停止はactiveとみなし、状態が未設定の場合（状態の導入前のデータ）もactiveとする
+func (u *User) EffectiveStatus(now time.Time) UserStatus {
+	switch {
+	case u.Status == "":
+		return UserStatusActive
+	case u.Status == UserStatusSuspended && u.SuspendedUntil != nil && !now.Before(*u.SuspendedUntil):
+		return UserStatusActive
+	default:
+		return u.Status
+	}
+}
+
+// IsActive はユーザーがログイン・APIの利用をできる状態かどうかを確認する
+func (u *User) IsActive(now time.Time) bool {
+	return u.EffectiveStatus(now) == UserStatusActive
+}
+
+// IsBlocked はユーザーが停止・無効化されているかどうかを確認する（公開プロフィールなどを表示しない）
+func (u *User) IsBlocked(now time.Time) bool {
+	status := u.EffectiveStatus(now)
+	return status == UserStatusSuspended || status == UserStatusDisabled
+}
+
+// ChangeStatus はユーザーの状態を変更する
+// suspended・disabledには理由が必要で、期限（until）はsuspendedの場合のみ指定できる（nilの場合は無期限）
+func (u *User) ChangeStatus(to UserStatus, reason string, until *time.Time, now time.Time) error {
+	if !to.IsValid() {
+		return fmt.Errorf("invalid user status: %q", to)
+	}
+	from := u.EffectiveStatus(now)
+	if !from.CanTransitionTo(to) {
+		return fmt.Errorf("cannot change user status from %s to %s", from, to)
+	}
+
+	reason = strings.TrimSpace(reason)
+	if (to == UserStatusSuspended || to == UserStatusDisabled) && reason == "" {
+		return errors.New("reason is required to suspend or disable a user")
+	}
+	if until != nil {
+		if to != UserStatusSuspended {
+			return errors.New("until can only be set when suspending a user")
+		}
+		if !until.After(now) {
+			return errors.New("until must be in the future")
+		}
+	}
+
+	u.Status = to
+	u.StatusReason = reason
+	u.SuspendedUntil = until
+	u.StatusChangedAt = &now
+	u.UpdatedAt = now
+
+	return nil
+}
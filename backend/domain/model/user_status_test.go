@@ -0,0 +1,83 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUser_EffectiveStatus(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Hour)
+
+	tests := []struct {
+		testName string
+		user     *User
+		expected UserStatus
+	}{
+		{testName: "未設定はactive", user: &User{}, expected: UserStatusActive},
+		{testName: "期限なしの一時停止", user: &User{Status: UserStatusSuspended}, expected: UserStatusSuspended},
+		{testName: "期限内の一時停止", user: &User{Status: UserStatusSuspended, SuspendedUntil: &future}, expected: UserStatusSuspended},
+		{testName: "期限を過ぎた一時停止はactive", user: &User{Status: UserStatusSuspended, SuspendedUntil: &past}, expected: UserStatusActive},
+		{testName: "無効化", user: &User{Status: UserStatusDisabled}, expected: UserStatusDisabled},
+		{testName: "認証待ち", user: &User{Status: UserStatusPendingVerification}, expected: UserStatusPendingVerification},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.user.EffectiveStatus(now))
+			assert.Equal(t, tt.expected == UserStatusActive, tt.user.IsActive(now))
+			assert.Equal(t, tt.expected == UserStatusSuspended || tt.expected == UserStatusDisabled, tt.user.IsBlocked(now))
+		})
+	}
+}
+
+func TestUser_ChangeStatus(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Hour)
+
+	tests := []struct {
+		testName string
+		user     *User
+		to       UserStatus
+		reason   string
+		until    *time.Time
+		wantErr  bool
+	}{
+		{testName: "期限付きで一時停止", user: &User{Status: UserStatusActive}, to: UserStatusSuspended, reason: "spam", until: &future},
+		{testName: "一時停止の期限を延長", user: &User{Status: UserStatusSuspended, SuspendedUntil: &future}, to: UserStatusSuspended, reason: "spam again"},
+		{testName: "無効化", user: &User{Status: UserStatusActive}, to: UserStatusDisabled, reason: "left the company"},
+		{testName: "無効化を解除", user: &User{Status: UserStatusDisabled, StatusReason: "left the company"}, to: UserStatusActive},
+		{testName: "認証待ちを有効化", user: &User{Status: UserStatusPendingVerification}, to: UserStatusActive},
+		{testName: "期限切れの一時停止はactiveとして無効化できる", user: &User{Status: UserStatusSuspended, SuspendedUntil: &past}, to: UserStatusDisabled, reason: "abuse"},
+		{testName: "同じ状態には変更できない", user: &User{Status: UserStatusActive}, to: UserStatusActive, wantErr: true},
+		{testName: "認証待ちには戻せない", user: &User{Status: UserStatusActive}, to: UserStatusPendingVerification, wantErr: true},
+		{testName: "無効化から一時停止にはできない", user: &User{Status: UserStatusDisabled}, to: UserStatusSuspended, reason: "spam", wantErr: true},
+		{testName: "理由のない一時停止", user: &User{Status: UserStatusActive}, to: UserStatusSuspended, reason: " ", wantErr: true},
+		{testName: "無効化に期限は指定できない", user: &User{Status: UserStatusActive}, to: UserStatusDisabled, reason: "abuse", until: &future, wantErr: true},
+		{testName: "過去の期限", user: &User{Status: UserStatusActive}, to: UserStatusSuspended, reason: "spam", until: &past, wantErr: true},
+		{testName: "不明な状態", user: &User{Status: UserStatusActive}, to: UserStatus("banned"), reason: "spam", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			before := *tt.user
+			err := tt.user.ChangeStatus(tt.to, tt.reason, tt.until, now)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, before, *tt.user)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.to, tt.user.Status)
+			assert.Equal(t, tt.reason, tt.user.StatusReason)
+			assert.Equal(t, tt.until, tt.user.SuspendedUntil)
+			assert.Equal(t, now, *tt.user.StatusChangedAt)
+			assert.Equal(t, now, tt.user.UpdatedAt)
+		})
+	}
+}
@@ -0,0 +1,277 @@
+package model
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WebhookDeliveryStatus はWebhookの配信の状態を表す
+type WebhookDeliveryStatus string
+
+const (
+	// WebhookDeliveryPending は配信待ち（失敗して再試行を待っている場合を含む）
+	WebhookDeliveryPending WebhookDeliveryStatus = "pending"
+	// WebhookDeliverySucceeded は送信先が2xxを返した
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	// WebhookDeliveryDead は最大回数まで失敗し、再送を待っている（デッドレター）
+	WebhookDeliveryDead WebhookDeliveryStatus = "dead"
+)
+
+// IsValid は配信の状態が定義済みの値かどうかを確認する
+func (s WebhookDeliveryStatus) IsValid() bool {
+	switch s {
+	case WebhookDeliveryPending, WebhookDeliverySucceeded, WebhookDeliveryDead:
+		return true
+	default:
+		return false
+	}
+}
+
+const (
+	// WebhookSignatureHeader は署名を送るヘッダー（t=<UNIX時刻>,v1=<HMAC-SHA256の16進数>）
+	WebhookSignatureHeader = "X-Stackies-Signature"
+	// WebhookEventHeader はイベントの種類を送るヘッダー
+	WebhookEventHeader = "X-Stackies-Event"
+	// WebhookDeliveryHeader は配信のIDを送るヘッダー（再送では変わる）
+	WebhookDeliveryHeader = "X-Stackies-Delivery"
+
+	// MinWebhookSecretLength は署名に使うシークレットの最小の長さ
+	MinWebhookSecretLength = 16
+	// MaxWebhookDeliveryPageSize は配信履歴の1ページあたりの最大件数
+	MaxWebhookDeliveryPageSize = 200
+	// DefaultWebhookDeliveryPageSize は配信履歴の1ページあたりのデフォルト件数
+	DefaultWebhookDeliveryPageSize = 50
+)
+
+type (
+	// WebhookSubscription は外部サービスへのWebhookの購読を表す
+	WebhookSubscription struct {
+		ID  string `json:"id"`
+		URL string `json:"url"`
+		// EventTypes は送信するドメインイベントの種類
+		EventTypes  []DomainEventType `json:"event_types"`
+		Description string            `json:"description,omitempty"`
+		// Secret は署名に使うシークレット（作成時のみ返す）
+		Secret    string    `json:"-"`
+		Active    bool      `json:"active"`
+		CreatedAt time.Time `json:"created_at"`
+		UpdatedAt time.Time `json:"updated_at"`
+	}
+
+	// WebhookDelivery はドメインイベントの購読先への配信と、その結果を表す
+	WebhookDelivery struct {
+		ID             string          `json:"id"`
+		SubscriptionID string          `json:"subscription_id"`
+		EventID        string          `json:"event_id"`
+		EventType      DomainEventType `json:"event_type"`
+		// UserID はイベントの対象のユーザー（アカウントの消去時に配信を削除するために使用する）
+		UserID string `json:"-"`
+		// Payload は送信するリクエストボディ（作成時のイベントのJSON）
+		Payload []byte                `json:"-"`
+		Status  WebhookDeliveryStatus `json:"status"`
+		// Attempts は送信を試みた回数
+		Attempts      int       `json:"attempts"`
+		NextAttemptAt time.Time `json:"next_attempt_at"`
+		// ResponseStatus は最後の送信で送信先が返したステータスコード（接続できなかった場合は0）
+		ResponseStatus int    `json:"response_status,omitempty"`
+		LastError      string `json:"last_error,omitempty"`
+		// ReplayOf は再送元の配信のID
+		ReplayOf    string     `json:"replay_of,omitempty"`
+		CreatedAt   time.Time  `json:"created_at"`
+		DeliveredAt *time.Time `json:"delivered_at,omitempty"`
+	}
+
+	// WebhookDeliveryFilter は購読ごとの配信履歴の検索条件を表す
+	WebhookDeliveryFilter struct {
+		SubscriptionID string
+		Status         WebhookDeliveryStatus
+		Limit          int
+		// Cursor は前のページの最後の配信のID
+		Cursor string
+	}
+
+	// WebhookDeliveryPage は配信履歴の1ページを表す（新しい順）
+	WebhookDeliveryPage struct {
+		Deliveries []*WebhookDelivery `json:"deliveries"`
+		NextCursor string             `json:"next_cursor,omitempty"`
+	}
+)
+
+// ValidateWebhookURL は送信先のURLがhttpsの絶対URLで、内部ネットワークを指していないかを確認する
+// allowPrivateがtrueの場合（開発環境）はhttpとループバック・プライベートアドレスも許可する
+// ホスト名が解決するアドレスは送信時に検証する（IsPublicWebhookAddr）
+func ValidateWebhookURL(rawURL string, allowPrivate bool) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return errors.New("url must be an absolute http or https URL")
+	}
+	if u.User != nil {
+		return errors.New("url must not contain credentials")
+	}
+	if allowPrivate {
+		return nil
+	}
+	if u.Scheme != "https" {
+		return errors.New("url must use https")
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return errors.New("url must not point to a loopback address")
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !IsPublicWebhookAddr(addr) {
+		return errors.New("url must not point to a private, loopback or link-local address")
+	}
+	return nil
+}
+
+// nonPublicWebhookPrefixes はグローバルユニキャストに含まれるが、送信先として許可しないアドレス帯
+var nonPublicWebhookPrefixes = []netip.Prefix{
+	// 「このネットワーク」（0.0.0.0/8、Linuxでは自ホストに接続する）
+	netip.MustParsePrefix("0.0.0.0/8"),
+	// キャリアグレードNATの共有アドレス（RFC 6598）
+	netip.MustParsePrefix("100.64.0.0/10"),
+}
+
+// IsPublicWebhookAddr はWebhookの送信先として許可するグローバルなアドレスかどうかを返す
+// ループバック・プライベート（RFC 1918、ユニークローカル）・リンクローカル（169.254.169.254などのメタデータを含む）・
+// マルチキャスト・未指定のアドレスは、内部のサービスへのリクエストに悪用されるため許可しない
+func IsPublicWebhookAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range nonPublicWebhookPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateWebhookEventTypes は購読するイベントの種類が1つ以上の定義済みの値かどうかを確認する
+func ValidateWebhookEventTypes(types []DomainEventType) error {
+	if len(types) == 0 {
+		return errors.New("event_types must not be empty")
+	}
+	for _, eventType := range types {
+		if !eventType.IsValid() {
+			return errors.New("unknown event type: " + string(eventType))
+		}
+	}
+	return nil
+}
+
+// Subscribes は購読が有効で、イベントの種類を購読しているかどうかを確認する
+func (s *WebhookSubscription) Subscribes(eventType DomainEventType) bool {
+	return s.Active && slices.Contains(s.EventTypes, eventType)
+}
+
+// NewWebhookDelivery は購読先へのイベントの配信を作成する（直ちに配信する）
+func NewWebhookDelivery(id string, subscription *WebhookSubscription, event *DomainEvent, payload []byte) *WebhookDelivery {
+	now := time.Now()
+	return &WebhookDelivery{
+		ID:             id,
+		SubscriptionID: subscription.ID,
+		EventID:        event.ID,
+		EventType:      event.Type,
+		UserID:         event.UserID,
+		Payload:        payload,
+		Status:         WebhookDeliveryPending,
+		NextAttemptAt:  now,
+		CreatedAt:      now,
+	}
+}
+
+// Replay は同じ内容を送り直す新しい配信を作成する
+func (d *WebhookDelivery) Replay(id string) *WebhookDelivery {
+	now := time.Now()
+	return &WebhookDelivery{
+		ID:             id,
+		SubscriptionID: d.SubscriptionID,
+		EventID:        d.EventID,
+		EventType:      d.EventType,
+		UserID:         d.UserID,
+		Payload:        d.Payload,
+		Status:         WebhookDeliveryPending,
+		NextAttemptAt:  now,
+		ReplayOf:       d.ID,
+		CreatedAt:      now,
+	}
+}
+
+// Succeed は送信の成功を記録する
+func (d *WebhookDelivery) Succeed(responseStatus int, now time.Time) {
+	d.Attempts++
+	d.Status = WebhookDeliverySucceeded
+	d.ResponseStatus = responseStatus
+	d.LastError = ""
+	d.DeliveredAt = &now
+}
+
+// Fail は送信の失敗を記録する
+// maxAttemptsに達した場合はデッドレターとし、そうでなければretryAfter後に再試行する
+func (d *WebhookDelivery) Fail(responseStatus int, cause string, maxAttempts int, retryAfter time.Duration, now time.Time) {
+	d.Attempts++
+	d.ResponseStatus = responseStatus
+	d.LastError = cause
+	if d.Attempts >= maxAttempts {
+		d.Status = WebhookDeliveryDead
+		return
+	}
+	d.NextAttemptAt = now.Add(retryAfter)
+}
+
+// Abandon は送信せずにデッドレターとする（購読先が無効化された場合など）
+func (d *WebhookDelivery) Abandon(cause string) {
+	d.Status = WebhookDeliveryDead
+	d.LastError = cause
+}
+
+// SignWebhookPayload はタイムスタンプとボディのHMAC-SHA256による署名ヘッダーの値を返す
+// 受信側は "<t>.<ボディ>" のHMACを計算して比較し、tが古すぎるリクエストを拒否する
+func SignWebhookPayload(secret string, timestamp time.Time, payload []byte) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(t))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return "t=" + t + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// PageSize は1ページの件数を返す（未指定の場合はデフォルト値、上限を超える場合は上限値）
+func (f *WebhookDeliveryFilter) PageSize() int {
+	if f.Limit <= 0 {
+		return DefaultWebhookDeliveryPageSize
+	}
+	if f.Limit > MaxWebhookDeliveryPageSize {
+		return MaxWebhookDeliveryPageSize
+	}
+	return f.Limit
+}
+
+// Matches は配信が検索条件に一致するかどうかを判定する
+func (f *WebhookDeliveryFilter) Matches(delivery *WebhookDelivery) bool {
+	if f.SubscriptionID != "" && delivery.SubscriptionID != f.SubscriptionID {
+		return false
+	}
+	if f.Status != "" && delivery.Status != f.Status {
+		return false
+	}
+	return true
+}
+
+// ValidateWebhookSecret は指定されたシークレットが署名に使える長さかどうかを確認する
+func ValidateWebhookSecret(secret string) error {
+	if len(secret) < MinWebhookSecretLength || strings.TrimSpace(secret) != secret {
+		return errors.New("secret must be at least 16 characters without leading or trailing spaces")
+	}
+	return nil
+}
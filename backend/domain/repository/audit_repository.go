@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"net/http"
+	"stackies-backend/core"
+	"stackies-backend/domain/model"
+)
+
+var (
+	ErrInvalidCursor = core.NewAppError("invalid_cursor", http.StatusBadRequest, "Invalid cursor")
+)
+
+// AuditRepository は監査イベントの追記専用ストアを抽象化する
+type AuditRepository interface {
+	Append(ctx context.Context, event *model.AuditEvent) error
+	Query(ctx context.Context, filter *model.AuditEventFilter) (*model.AuditEventPage, error)
+	// Pseudonymize は消去したユーザーのイベントのユーザーIDを仮名に置き換え、個人を識別する情報を削除する
+	// 追記専用のストアに対する唯一の更新で、置き換えたイベントの数を返す
+	Pseudonymize(ctx context.Context, userID, pseudonym string) (int, error)
+}
@@ -2,13 +2,20 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"stackies-backend/domain/model"
 )
 
+var (
+	ErrTokenNotFound = errors.New("token not found")
+)
+
 // AuthRepository は認証関連のデータアクセスを抽象化する
 type AuthRepository interface {
 	SaveToken(ctx context.Context, userID string, token *model.AuthToken) error
 	GetToken(ctx context.Context, userID string) (*model.AuthToken, error)
 	DeleteToken(ctx context.Context, userID string) error
 	ValidateToken(ctx context.Context, token string) (string, error)
+	// CountTokens は保存されているトークン（有効なセッション）の数を返す
+	CountTokens(ctx context.Context) (int, error)
 }
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"stackies-backend/domain/model"
+	"time"
+)
+
+var (
+	ErrDataExportNotFound = errors.New("data export not found")
+)
+
+// DataExportRepository はデータのエクスポートの状態へのアクセスを抽象化する（ユーザーごとに最新の1件）
+type DataExportRepository interface {
+	Save(ctx context.Context, export *model.DataExport) error
+	FindByUserID(ctx context.Context, userID string) (*model.DataExport, error)
+	DeleteByUserID(ctx context.Context, userID string) error
+	// FindExpired は作成が完了し、保持期限を過ぎたエクスポートを返す
+	FindExpired(ctx context.Context, now time.Time) ([]*model.DataExport, error)
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+	"net/http"
+	"stackies-backend/core"
+	"stackies-backend/domain/model"
+)
+
+var (
+	ErrGroupNotFound = core.NewAppError("group_not_found", http.StatusNotFound, "Group not found")
+)
+
+// GroupRepository はSCIMでプロビジョニングするグループへのアクセスを抽象化する
+type GroupRepository interface {
+	Save(ctx context.Context, group *model.Group) error
+	FindByID(ctx context.Context, id string) (*model.Group, error)
+	// FindAll はすべてのグループを作成日時の昇順で返す
+	FindAll(ctx context.Context) ([]*model.Group, error)
+	Update(ctx context.Context, group *model.Group) error
+	Delete(ctx context.Context, id string) error
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"stackies-backend/domain/model"
+)
+
+var (
+	ErrStateNotFound     = errors.New("oauth state not found")
+	ErrLoginCodeNotFound = errors.New("login code not found")
+)
+
+// OAuthRepository はOAuthフローの一時データへのアクセスを抽象化する
+type OAuthRepository interface {
+	SaveState(ctx context.Context, state *model.OAuthState) error
+	ConsumeState(ctx context.Context, state string) (*model.OAuthState, error)
+	SaveLoginCode(ctx context.Context, code *model.LoginCode) error
+	ConsumeLoginCode(ctx context.Context, code string) (*model.LoginCode, error)
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"stackies-backend/domain/model"
+	"time"
+)
+
+var (
+	ErrOutboxMessageNotFound = errors.New("outbox message not found")
+)
+
+// Transactor は複数のリポジトリへの書き込みをひとつのトランザクションにまとめる
+// 書き込みの不可分性は実装に依存し、データベースのトランザクションを使う実装でのみ保証される
+type Transactor interface {
+	// WithinTransaction はfnの中の書き込みを、fnがエラーを返さなかった場合にまとめて確定する
+	// トランザクションの中で呼んだ場合は外側のトランザクションに含める
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// OutboxRepository はドメインイベントのアウトボックス（配信待ちのキュー）を抽象化する
+type OutboxRepository interface {
+	// Append はイベントを配信待ちとして保存する。IDが未設定の場合は採番する
+	// WithinTransactionの中で呼んだ場合はトランザクションの確定時に保存する
+	Append(ctx context.Context, events ...*model.DomainEvent) error
+	// FetchPending は配信時刻を過ぎたメッセージを古い順に最大limit件返す
+	FetchPending(ctx context.Context, now time.Time, limit int) ([]*model.OutboxMessage, error)
+	// Delete は配信が完了したメッセージを削除する
+	Delete(ctx context.Context, id string) error
+	// MarkFailed は配信の失敗を記録し、次に配信する時刻を設定する
+	MarkFailed(ctx context.Context, id string, cause string, nextAttemptAt time.Time) error
+	// DeleteByUserID はユーザーを対象とする配信待ちのメッセージを削除し、削除した件数を返す（アカウントの消去で使用する）
+	DeleteByUserID(ctx context.Context, userID string) (int, error)
+}
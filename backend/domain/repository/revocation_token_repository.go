@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"stackies-backend/domain/model"
+)
+
+var (
+	ErrRevocationTokenNotFound = errors.New("revocation token not found")
+)
+
+// RevocationTokenRepository は「心当たりがない」リンク用トークンへのアクセスを抽象化する
+type RevocationTokenRepository interface {
+	SaveRevocationToken(ctx context.Context, token *model.RevocationToken) error
+	ConsumeRevocationToken(ctx context.Context, token string) (*model.RevocationToken, error)
+}
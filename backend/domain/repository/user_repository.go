@@ -2,18 +2,26 @@ package repository
 
 import (
 	"context"
-	"errors"
+	"net/http"
+	"stackies-backend/core"
 	"stackies-backend/domain/model"
 )
 
 var (
-	ErrUserNotFound = errors.New("user not found")
+	ErrUserNotFound = core.NewAppError("user_not_found", http.StatusNotFound, "User not found")
 )
 
 // UserRepository はユーザー関連のデータアクセスを抽象化する
 type UserRepository interface {
 	Save(ctx context.Context, user *model.User) error
+	// FindByEmail はメールアドレスの大文字・小文字を区別せずに検索する（SCIMで登録したユーザーとGoogleのメールアドレスを一致させるため）
 	FindByEmail(ctx context.Context, email string) (*model.User, error)
 	FindByID(ctx context.Context, id string) (*model.User, error)
 	Update(ctx context.Context, user *model.User) error
+	// FindAll はすべてのユーザーを作成日時の昇順で返す
+	FindAll(ctx context.Context) ([]*model.User, error)
+	// List は検索条件に一致するユーザーを1ページ分返す（カーソルを解釈できない、または並び順が異なる場合はErrInvalidCursor）
+	List(ctx context.Context, filter *model.UserFilter) (*model.UserPage, error)
+	// Delete はユーザーを完全に削除する
+	Delete(ctx context.Context, id string) error
 }
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"net/http"
+	"stackies-backend/core"
+	"stackies-backend/domain/model"
+	"time"
+)
+
+var (
+	ErrWebhookNotFound         = core.NewAppError("webhook_not_found", http.StatusNotFound, "Webhook subscription not found")
+	ErrWebhookDeliveryNotFound = core.NewAppError("webhook_delivery_not_found", http.StatusNotFound, "Webhook delivery not found")
+)
+
+// WebhookRepository はWebhookの購読へのアクセスを抽象化する
+type WebhookRepository interface {
+	Save(ctx context.Context, subscription *model.WebhookSubscription) error
+	FindByID(ctx context.Context, id string) (*model.WebhookSubscription, error)
+	// FindAll はすべての購読を作成日時の昇順で返す
+	FindAll(ctx context.Context) ([]*model.WebhookSubscription, error)
+	Update(ctx context.Context, subscription *model.WebhookSubscription) error
+	Delete(ctx context.Context, id string) error
+}
+
+// WebhookDeliveryRepository はWebhookの配信と配信履歴へのアクセスを抽象化する
+type WebhookDeliveryRepository interface {
+	// Save は配信を保存する。同じ購読に同じイベントの配信（再送を除く）が保存済みの場合は保存せずfalseを返す
+	Save(ctx context.Context, delivery *model.WebhookDelivery) (bool, error)
+	FindByID(ctx context.Context, id string) (*model.WebhookDelivery, error)
+	Update(ctx context.Context, delivery *model.WebhookDelivery) error
+	// FindDue は送信時刻を過ぎた配信待ちの配信を古い順に最大limit件返す
+	FindDue(ctx context.Context, now time.Time, limit int) ([]*model.WebhookDelivery, error)
+	// Query は検索条件に一致する配信を新しい順に返す（カーソルが存在しない場合はErrInvalidCursor）
+	Query(ctx context.Context, filter *model.WebhookDeliveryFilter) (*model.WebhookDeliveryPage, error)
+	// DeleteBySubscriptionID は購読の配信をすべて削除する
+	DeleteBySubscriptionID(ctx context.Context, subscriptionID string) error
+	// DeleteSucceededBefore はbeforeより前に成功した配信を削除し、削除した件数を返す
+	DeleteSucceededBefore(ctx context.Context, before time.Time) (int, error)
+	// DeleteByUserID はユーザーを対象とするイベントの配信を状態にかかわらず削除し、削除した件数を返す（アカウントの消去で使用する）
+	DeleteByUserID(ctx context.Context, userID string) (int, error)
+}
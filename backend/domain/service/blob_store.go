@@ -0,0 +1,22 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"stackies-backend/domain/model"
+)
+
+// ErrBlobNotFound は指定したキーのデータが保存されていない場合のエラー
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobStore は画像などのバイナリデータの保存先（ローカルファイル・S3互換ストレージ）を抽象化する
+type BlobStore interface {
+	Put(ctx context.Context, key string, blob *model.Blob) error
+	// Get は保存したデータを返す（存在しない場合はErrBlobNotFound）
+	Get(ctx context.Context, key string) (*model.Blob, error)
+	// Delete はデータを削除する（存在しない場合も成功とする）
+	Delete(ctx context.Context, key string) error
+	// URL はクライアントが直接取得できるURLを返す
+	// 空の場合はバックエンドがGetで取得したデータを返す
+	URL(ctx context.Context, key string) (string, error)
+}
@@ -0,0 +1,9 @@
+package service
+
+import "stackies-backend/domain/model"
+
+// GeoIPService はIPアドレスからの位置推定を抽象化する
+type GeoIPService interface {
+	// Lookup はIPアドレスの位置を返す。位置が特定できない場合はnilを返す
+	Lookup(ip string) (*model.GeoLocation, error)
+}
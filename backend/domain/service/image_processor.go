@@ -0,0 +1,16 @@
+package service
+
+import (
+	"errors"
+	"stackies-backend/domain/model"
+)
+
+// ErrUnsupportedImage は画像として読み込めないか、対応していない形式の場合のエラー
+var ErrUnsupportedImage = errors.New("unsupported image")
+
+// ImageProcessor はアップロードされた画像の検証と変換を抽象化する
+type ImageProcessor interface {
+	// ProcessAvatar は画像の形式を内容から判定し、メタデータ（EXIFなど）を除いて
+	// model.AvatarSizesの各サイズの正方形に変換する
+	ProcessAvatar(data []byte) (model.AvatarFormat, []*model.AvatarImage, error)
+}
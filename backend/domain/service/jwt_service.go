@@ -1,8 +1,18 @@
 package service
 
+import (
+	"stackies-backend/domain/model"
+	"time"
+)
+
 // JWTService はJWT認証サービスを抽象化する
 type JWTService interface {
 	GenerateToken(userID string) (string, error)
+	// ValidateToken はトークンを検証してユーザーIDを返す（なりすましのトークンは無効とする）
 	ValidateToken(token string) (string, error)
 	GenerateRefreshToken(userID string) (string, error)
+	// GenerateImpersonationToken はactorIDの管理者がuserIDのユーザーになりすますためのアクセストークンを生成する
+	GenerateImpersonationToken(userID, actorID string, ttl time.Duration) (string, error)
+	// ParseToken はトークンを検証してクレームを返す（なりすましのトークンも有効とする）
+	ParseToken(token string) (*model.TokenClaims, error)
 }
@@ -0,0 +1,11 @@
+package service
+
+import (
+	"context"
+	"stackies-backend/domain/model"
+)
+
+// LoginAlertNotifier は不審なログインの通知（メール通知や追加認証の要求）を抽象化する
+type LoginAlertNotifier interface {
+	Notify(ctx context.Context, alert *model.LoginAlert) error
+}
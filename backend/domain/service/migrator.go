@@ -0,0 +1,16 @@
+package service
+
+import (
+	"context"
+	"stackies-backend/domain/model"
+)
+
+// Migrator はデータストアのスキーマのマイグレーションを抽象化する
+type Migrator interface {
+	// Status はすべてのマイグレーションと適用状況をバージョンの昇順で返す
+	Status(ctx context.Context) ([]*model.MigrationStatus, error)
+	// Up は未適用のマイグレーションをバージョンの昇順にすべて適用し、適用したものを返す
+	Up(ctx context.Context) ([]*model.MigrationStatus, error)
+	// Down は適用済みのマイグレーションを新しいものからsteps件取り消し、取り消したものを返す
+	Down(ctx context.Context, steps int) ([]*model.MigrationStatus, error)
+}
@@ -0,0 +1,11 @@
+package service
+
+import (
+	"context"
+	"stackies-backend/domain/model"
+)
+
+// RateLimiter はレート制限のカウンタ管理を抽象化する
+type RateLimiter interface {
+	Allow(ctx context.Context, key string, rule model.RateLimitRule) (*model.RateLimitResult, error)
+}
@@ -0,0 +1,14 @@
+package service
+
+import (
+	"context"
+	"stackies-backend/domain/model"
+)
+
+// WebhookSender は購読先へのWebhookの送信を抽象化する
+// 送信先が応答した場合はステータスコードを返し、接続できなかった場合はエラーを返す
+type WebhookSender interface {
+	Send(ctx context.Context, subscription *model.WebhookSubscription, delivery *model.WebhookDelivery) (int, error)
+	// Forget は削除した購読のために保持している送信の状態（HTTPクライアントなど）を破棄する
+	Forget(subscriptionID string)
+}
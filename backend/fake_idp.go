@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"stackies-backend/infra/external/fakeidp"
+	"syscall"
+	"time"
+)
+
+// runFakeIdP は開発・E2Eテスト用のfake IdPを起動する（fake-idpサブコマンド）
+// バックエンドはGOOGLE_ENDPOINT_BASE_URLにこのサーバーのURLを指定して起動する
+func runFakeIdP(args []string) error {
+	flags := flag.NewFlagSet("fake-idp", flag.ContinueOnError)
+	addr := flags.String("addr", "localhost:9999", "listen address")
+	issuer := flags.String("issuer", "", "issuer URL (defaults to the request host)")
+	clientID := flags.String("client-id", os.Getenv("GOOGLE_CLIENT_ID"), "expected OAuth client ID (not checked if empty)")
+	clientSecret := flags.String("client-secret", os.Getenv("GOOGLE_CLIENT_SECRET"), "expected OAuth client secret (not checked if empty)")
+	usersFile := flags.String("users", "", "YAML file listing test users (defaults to built-in users)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	options := fakeidp.Options{Issuer: *issuer, ClientID: *clientID, ClientSecret: *clientSecret}
+	if *usersFile != "" {
+		users, err := fakeidp.LoadUsers(*usersFile)
+		if err != nil {
+			return err
+		}
+		options.Users = users
+	}
+	idp, err := fakeidp.New(options)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	srv := &http.Server{Addr: *addr, Handler: idp, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	slog.Info("starting fake idp", slog.String("addr", *addr))
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
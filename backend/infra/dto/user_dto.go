@@ -7,23 +7,51 @@ import (
 
 // UserDTO はデータベース用のユーザー構造体を表す
 type UserDTO struct {
-	ID        string    `db:"id"`
-	Email     string    `db:"email"`
-	Name      string    `db:"name"`
-	Picture   string    `db:"picture"`
+	ID      string `db:"id"`
+	Email   string `db:"email"`
+	Name    string `db:"name"`
+	Picture string `db:"picture"`
+	Role    string `db:"role"`
+	// Provider はIDプロバイダーの導入前に作成したユーザーの場合に空文字（googleとして扱う）
+	Provider  string    `db:"provider"`
 	CreatedAt time.Time `db:"created_at"`
 	UpdatedAt time.Time `db:"updated_at"`
+	// Status は状態の導入前に作成したユーザーの場合に空文字（activeとして扱う）
+	Status string `db:"status"`
+	// StatusReason は停止・無効化されていない場合に空文字
+	StatusReason string `db:"status_reason"`
+	// SuspendedUntil・StatusChangedAtはNULLの場合に期限なし・未変更
+	SuspendedUntil  *time.Time `db:"suspended_until"`
+	StatusChangedAt *time.Time `db:"status_changed_at"`
+	NameEdited      bool       `db:"name_edited"`
+	PictureEdited   bool       `db:"picture_edited"`
 }
 
 // ToDomain はDTOからドメインモデルに変換する
 func (dto *UserDTO) ToDomain() *model.User {
+	status := model.UserStatus(dto.Status)
+	if status == "" {
+		status = model.UserStatusActive
+	}
+	provider := model.Provider(dto.Provider)
+	if provider == "" {
+		provider = model.ProviderGoogle
+	}
 	return &model.User{
-		ID:        dto.ID,
-		Email:     dto.Email,
-		Name:      dto.Name,
-		Picture:   dto.Picture,
-		CreatedAt: dto.CreatedAt,
-		UpdatedAt: dto.UpdatedAt,
+		ID:              dto.ID,
+		Email:           dto.Email,
+		Name:            dto.Name,
+		Picture:         dto.Picture,
+		Role:            model.Role(dto.Role),
+		Provider:        provider,
+		CreatedAt:       dto.CreatedAt,
+		UpdatedAt:       dto.UpdatedAt,
+		Status:          status,
+		StatusReason:    dto.StatusReason,
+		SuspendedUntil:  dto.SuspendedUntil,
+		StatusChangedAt: dto.StatusChangedAt,
+		NameEdited:      dto.NameEdited,
+		PictureEdited:   dto.PictureEdited,
 	}
 }
 
@@ -33,6 +61,14 @@ func (dto *UserDTO) FromDomain(user *model.User) {
 	dto.Email = user.Email
 	dto.Name = user.Name
 	dto.Picture = user.Picture
+	dto.Role = string(user.Role)
+	dto.Provider = string(user.Provider)
 	dto.CreatedAt = user.CreatedAt
 	dto.UpdatedAt = user.UpdatedAt
+	dto.Status = string(user.Status)
+	dto.StatusReason = user.StatusReason
+	dto.SuspendedUntil = user.SuspendedUntil
+	dto.StatusChangedAt = user.StatusChangedAt
+	dto.NameEdited = user.NameEdited
+	dto.PictureEdited = user.PictureEdited
 }
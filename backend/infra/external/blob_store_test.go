@@ -0,0 +1,202 @@
+package external
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/service"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeS3 はパス形式のPUT・GET・HEAD・DELETEのみに応答するS3互換サーバー
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string]*model.Blob
+}
+
+func newFakeS3(t *testing.T) *httptest.Server {
+	s := &fakeS3{objects: make(map[string]*model.Blob)}
+	server := httptest.NewServer(s)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func (s *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := r.URL.Path
+	switch r.Method {
+	case http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		if strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+			data = decodeAWSChunked(data)
+		}
+		s.objects[key] = &model.Blob{ContentType: r.Header.Get("Content-Type"), Data: data}
+		w.Header().Set("ETag", `"etag"`)
+		w.WriteHeader(http.StatusOK)
+	case http.MethodGet, http.MethodHead:
+		blob, ok := s.objects[key]
+		if !ok {
+			w.Header().Set("Content-Type", "application/xml")
+			w.WriteHeader(http.StatusNotFound)
+			if r.Method == http.MethodGet {
+				_, _ = io.WriteString(w, `<?xml version="1.0" encoding="UTF-8"?><Error><Code>NoSuchKey</Code><Message>not found</Message></Error>`)
+			}
+			return
+		}
+		w.Header().Set("Content-Type", blob.ContentType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(blob.Data)))
+		w.Header().Set("ETag", `"etag"`)
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(blob.Data)
+		}
+	case http.MethodDelete:
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// decodeAWSChunked は署名付きチャンク（平文のHTTPでminio-goが送信する形式）からデータを取り出す
+// 各チャンクは "サイズ(16進);chunk-signature=...\r\nデータ\r\n" の形式で、サイズ0で終わる
+func decodeAWSChunked(body []byte) []byte {
+	var data []byte
+	for len(body) > 0 {
+		header, rest, ok := bytes.Cut(body, []byte("\r\n"))
+		if !ok {
+			break
+		}
+		sizeHex, _, _ := bytes.Cut(header, []byte(";"))
+		size, err := strconv.ParseInt(string(sizeHex), 16, 64)
+		if err != nil || size == 0 || int(size) > len(rest) {
+			break
+		}
+		data = append(data, rest[:size]...)
+		body = bytes.TrimPrefix(rest[size:], []byte("\r\n"))
+	}
+	return data
+}
+
+// testBlobStore はBlobStoreの実装に共通する振る舞いを確認する
+func testBlobStore(t *testing.T, store service.BlobStore) {
+	ctx := context.Background()
+	key := "avatars/user-1/v1/64.png"
+
+	_, err := store.Get(ctx, key)
+	assert.ErrorIs(t, err, service.ErrBlobNotFound)
+
+	require.NoError(t, store.Put(ctx, key, &model.Blob{ContentType: "image/png", Data: []byte("png data")}))
+	blob, err := store.Get(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", blob.ContentType)
+	assert.Equal(t, []byte("png data"), blob.Data)
+
+	// 同じキーへの保存は上書きする
+	require.NoError(t, store.Put(ctx, key, &model.Blob{ContentType: "image/png", Data: []byte("new data")}))
+	blob, err = store.Get(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("new data"), blob.Data)
+
+	require.NoError(t, store.Delete(ctx, key))
+	_, err = store.Get(ctx, key)
+	assert.ErrorIs(t, err, service.ErrBlobNotFound)
+	// 存在しないデータの削除は成功とする
+	assert.NoError(t, store.Delete(ctx, key))
+}
+
+func TestLocalBlobStore(t *testing.T) {
+	store, err := NewLocalBlobStore(t.TempDir())
+	require.NoError(t, err)
+
+	t.Run("保存・取得・削除", func(t *testing.T) {
+		testBlobStore(t, store)
+	})
+
+	t.Run("バックエンドがデータを返すためURLは空", func(t *testing.T) {
+		signed, err := store.URL(context.Background(), "avatars/user-1/v1/64.png")
+		require.NoError(t, err)
+		assert.Empty(t, signed)
+	})
+
+	t.Run("ディレクトリの外を指すキーはエラー", func(t *testing.T) {
+		for _, key := range []string{"", "../secret", "/etc/passwd", "avatars/../../secret"} {
+			assert.Error(t, store.Put(context.Background(), key, &model.Blob{Data: []byte("x")}), key)
+			_, err := store.Get(context.Background(), key)
+			assert.Error(t, err, key)
+		}
+	})
+
+	t.Run("ディレクトリ未指定でエラー", func(t *testing.T) {
+		_, err := NewLocalBlobStore("")
+		assert.Error(t, err)
+	})
+}
+
+func TestS3BlobStore(t *testing.T) {
+	server := newFakeS3(t)
+	opts := S3BlobStoreOptions{
+		Endpoint:        server.URL,
+		Region:          "us-east-1",
+		Bucket:          "avatars",
+		AccessKeyID:     "access-key",
+		SecretAccessKey: "secret-key",
+		PathStyle:       true,
+		URLExpiry:       5 * time.Minute,
+	}
+
+	t.Run("保存・取得・削除", func(t *testing.T) {
+		store, err := NewS3BlobStore(opts)
+		require.NoError(t, err)
+		testBlobStore(t, store)
+	})
+
+	t.Run("公開URLが未指定の場合は署名付きURLを返す", func(t *testing.T) {
+		store, err := NewS3BlobStore(opts)
+		require.NoError(t, err)
+
+		signed, err := store.URL(context.Background(), "avatars/user-1/v1/64.png")
+		require.NoError(t, err)
+		parsed, err := url.Parse(signed)
+		require.NoError(t, err)
+		assert.Equal(t, "/avatars/avatars/user-1/v1/64.png", parsed.Path)
+		assert.Equal(t, "300", parsed.Query().Get("X-Amz-Expires"))
+		assert.NotEmpty(t, parsed.Query().Get("X-Amz-Signature"))
+	})
+
+	t.Run("公開URLを指定した場合はそのURLを返す", func(t *testing.T) {
+		withPublic := opts
+		withPublic.PublicBaseURL = "https://cdn.example.com/"
+		store, err := NewS3BlobStore(withPublic)
+		require.NoError(t, err)
+
+		publicURL, err := store.URL(context.Background(), "avatars/user-1/v1/64.png")
+		require.NoError(t, err)
+		assert.Equal(t, "https://cdn.example.com/avatars/user-1/v1/64.png", publicURL)
+	})
+
+	t.Run("設定が不正な場合はエラー", func(t *testing.T) {
+		noBucket := opts
+		noBucket.Bucket = ""
+		_, err := NewS3BlobStore(noBucket)
+		assert.Error(t, err)
+
+		noEndpoint := opts
+		noEndpoint.Endpoint = "not a url"
+		_, err = NewS3BlobStore(noEndpoint)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,127 @@
+package external
+
+import (
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen は連続した失敗により外部サービスの呼び出しを遮断している場合のエラー
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// circuitState はサーキットブレーカーの状態を表す
+type circuitState int
+
+const (
+	// circuitClosed は通常どおり呼び出す状態
+	circuitClosed circuitState = iota
+	// circuitOpen は呼び出しを遮断している状態
+	circuitOpen
+	// circuitHalfOpen は回復を確認するため1件だけ呼び出す状態
+	circuitHalfOpen
+)
+
+func (s circuitState) String() string {
+	switch s {
+	case circuitOpen:
+		return "open"
+	case circuitHalfOpen:
+		return "half_open"
+	default:
+		return "closed"
+	}
+}
+
+// CircuitBreaker は外部サービスごとの連続した失敗を数え、閾値を超えたら一定時間呼び出しを遮断する
+// 遮断期間が過ぎると1件だけ試行し、成功すれば再開、失敗すれば再び遮断する
+type CircuitBreaker struct {
+	name      string
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	state    circuitState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// NewCircuitBreaker は新しいCircuitBreakerを作成する
+// thresholdが0以下の場合は遮断しない
+func NewCircuitBreaker(name string, threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		name:      name,
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+// Allow は呼び出してよいかを返す（遮断中の場合はErrCircuitOpen）
+func (b *CircuitBreaker) Allow() error {
+	if b.threshold <= 0 {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case circuitOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return ErrCircuitOpen
+		}
+		b.transition(circuitHalfOpen)
+		b.probing = true
+		return nil
+	case circuitHalfOpen:
+		// 回復の確認中は他の呼び出しを遮断する
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// Record は呼び出しの結果を記録する
+func (b *CircuitBreaker) Record(success bool) {
+	if b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if success {
+		b.failures = 0
+		if b.state != circuitClosed {
+			b.transition(circuitClosed)
+		}
+		return
+	}
+
+	b.failures++
+	if b.state == circuitHalfOpen || b.failures >= b.threshold {
+		b.openedAt = b.now()
+		if b.state != circuitOpen {
+			b.transition(circuitOpen)
+		}
+	}
+}
+
+// transition は状態を変更し、変更をログに出力する（ロックを保持して呼び出す）
+func (b *CircuitBreaker) transition(state circuitState) {
+	slog.Warn("circuit breaker state changed",
+		slog.String("provider", b.name),
+		slog.String("from", b.state.String()),
+		slog.String("to", state.String()),
+		slog.Int("failures", b.failures),
+	)
+	b.state = state
+}
@@ -0,0 +1,75 @@
+package external
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	newBreaker := func(now *time.Time) *CircuitBreaker {
+		b := NewCircuitBreaker("google", 2, time.Minute)
+		b.now = func() time.Time { return *now }
+		return b
+	}
+
+	t.Run("連続した失敗が閾値に達すると遮断する", func(t *testing.T) {
+		now := time.Now()
+		b := newBreaker(&now)
+
+		b.Record(false)
+		assert.NoError(t, b.Allow())
+		b.Record(false)
+
+		assert.ErrorIs(t, b.Allow(), ErrCircuitOpen)
+	})
+
+	t.Run("成功すると失敗の数をリセットする", func(t *testing.T) {
+		now := time.Now()
+		b := newBreaker(&now)
+
+		b.Record(false)
+		b.Record(true)
+		b.Record(false)
+
+		assert.NoError(t, b.Allow())
+	})
+
+	t.Run("遮断期間が過ぎると1件だけ試行し、成功すれば再開する", func(t *testing.T) {
+		now := time.Now()
+		b := newBreaker(&now)
+		b.Record(false)
+		b.Record(false)
+
+		now = now.Add(time.Minute)
+		assert.NoError(t, b.Allow())
+		assert.ErrorIs(t, b.Allow(), ErrCircuitOpen)
+
+		b.Record(true)
+		assert.NoError(t, b.Allow())
+		assert.NoError(t, b.Allow())
+	})
+
+	t.Run("試行が失敗すると再び遮断する", func(t *testing.T) {
+		now := time.Now()
+		b := newBreaker(&now)
+		b.Record(false)
+		b.Record(false)
+
+		now = now.Add(time.Minute)
+		assert.NoError(t, b.Allow())
+		b.Record(false)
+
+		assert.ErrorIs(t, b.Allow(), ErrCircuitOpen)
+	})
+
+	t.Run("閾値が0の場合は遮断しない", func(t *testing.T) {
+		b := NewCircuitBreaker("google", 0, time.Minute)
+		for range 10 {
+			b.Record(false)
+		}
+
+		assert.NoError(t, b.Allow())
+	})
+}
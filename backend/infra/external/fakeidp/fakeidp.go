@@ -0,0 +1,461 @@
+// Package fakeidp は開発・E2Eテスト用のOAuth 2.0 / OpenID Connectプロバイダーを提供する
+// 実際のGoogleの認証情報やネットワークなしでGoogleログインのフローを動かすために使用し、本番環境では使用しない
+package fakeidp
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"gopkg.in/yaml.v3"
+)
+
+// エンドポイントのパス
+const (
+	PathDiscovery = "/.well-known/openid-configuration"
+	PathAuthorize = "/authorize"
+	PathToken     = "/token"
+	PathUserInfo  = "/userinfo"
+	PathJWKS      = "/jwks"
+)
+
+type (
+	// User はログインできるテストユーザーを表す
+	// JSONはGoogleのユーザー情報API（v2）と同じ形式
+	User struct {
+		ID            string `json:"id" yaml:"id"`
+		Email         string `json:"email" yaml:"email"`
+		VerifiedEmail bool   `json:"verified_email" yaml:"verifiedEmail"`
+		Name          string `json:"name" yaml:"name"`
+		GivenName     string `json:"given_name" yaml:"givenName"`
+		FamilyName    string `json:"family_name" yaml:"familyName"`
+		Picture       string `json:"picture" yaml:"picture"`
+		Locale        string `json:"locale" yaml:"locale"`
+	}
+
+	// Options はプロバイダーの設定を表す
+	Options struct {
+		// Issuer はプロバイダーのURL（空の場合はリクエストのホストから決める）
+		Issuer string
+		// ClientID・ClientSecretを指定した場合はクライアントを検証する
+		ClientID     string
+		ClientSecret string
+		// Users はログインできるユーザー（空の場合はDefaultUsers）
+		Users []User
+		// CodeTTL・TokenTTLは認可コード・アクセストークンの有効期間
+		CodeTTL  time.Duration
+		TokenTTL time.Duration
+	}
+)
+
+// DefaultUsers はメールアドレスが認証済みのユーザーと未認証のユーザーを返す
+func DefaultUsers() []User {
+	return []User{
+		{
+			ID:            "fake-user-1",
+			Email:         "alice@example.com",
+			VerifiedEmail: true,
+			Name:          "Alice Example",
+			GivenName:     "Alice",
+			FamilyName:    "Example",
+			Locale:        "ja",
+		},
+		{
+			ID:            "fake-user-2",
+			Email:         "unverified@example.com",
+			VerifiedEmail: false,
+			Name:          "Unverified User",
+			GivenName:     "Unverified",
+			FamilyName:    "User",
+			Locale:        "ja",
+		},
+	}
+}
+
+// LoadUsers はYAMLファイルからテストユーザーの一覧を読み込む
+func LoadUsers(path string) ([]User, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read users file: %w", err)
+	}
+	var users []User
+	if err := yaml.Unmarshal(data, &users); err != nil {
+		return nil, fmt.Errorf("failed to parse users file: %w", err)
+	}
+	return users, nil
+}
+
+type (
+	// authCode は発行した認可コードを表す
+	authCode struct {
+		user        User
+		clientID    string
+		redirectURI string
+		nonce       string
+		expiresAt   time.Time
+	}
+
+	// accessToken は発行したアクセストークンを表す
+	accessToken struct {
+		user      User
+		expiresAt time.Time
+	}
+)
+
+// Server はテスト用のOAuth 2.0 / OpenID Connectプロバイダー
+// http.Handlerとしてhttptest.NewServerに渡すか、fake-idpサブコマンドで起動する
+type Server struct {
+	options Options
+	users   map[string]User
+	key     *rsa.PrivateKey
+	keyID   string
+	mux     *http.ServeMux
+	now     func() time.Time
+
+	mu     sync.Mutex
+	codes  map[string]*authCode
+	tokens map[string]*accessToken
+}
+
+// New は新しいServerを作成する（IDトークンの署名鍵は起動のたびに生成する）
+func New(options Options) (*Server, error) {
+	if len(options.Users) == 0 {
+		options.Users = DefaultUsers()
+	}
+	if options.CodeTTL <= 0 {
+		options.CodeTTL = time.Minute
+	}
+	if options.TokenTTL <= 0 {
+		options.TokenTTL = time.Hour
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+	keyID, err := randomString(8)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		options: options,
+		users:   make(map[string]User, len(options.Users)),
+		key:     key,
+		keyID:   keyID,
+		mux:     http.NewServeMux(),
+		now:     time.Now,
+		codes:   make(map[string]*authCode),
+		tokens:  make(map[string]*accessToken),
+	}
+	for _, user := range options.Users {
+		s.users[user.Email] = user
+	}
+
+	s.mux.HandleFunc("GET "+PathDiscovery, s.discovery)
+	s.mux.HandleFunc("GET "+PathAuthorize, s.authorize)
+	s.mux.HandleFunc("POST "+PathToken, s.token)
+	s.mux.HandleFunc("GET "+PathUserInfo, s.userInfo)
+	s.mux.HandleFunc("GET "+PathJWKS, s.jwks)
+	return s, nil
+}
+
+// ServeHTTP はリクエストを各エンドポイントに振り分ける
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// PublicKey はIDトークンの署名を検証する公開鍵を返す
+func (s *Server) PublicKey() *rsa.PublicKey {
+	return &s.key.PublicKey
+}
+
+// issuer はプロバイダーのURLを返す
+func (s *Server) issuer(r *http.Request) string {
+	if s.options.Issuer != "" {
+		return s.options.Issuer
+	}
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+// discovery はOpenID Connect Discoveryのメタデータを返す
+func (s *Server) discovery(w http.ResponseWriter, r *http.Request) {
+	issuer := s.issuer(r)
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                issuer,
+		"authorization_endpoint":                issuer + PathAuthorize,
+		"token_endpoint":                        issuer + PathToken,
+		"userinfo_endpoint":                     issuer + PathUserInfo,
+		"jwks_uri":                              issuer + PathJWKS,
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"token_endpoint_auth_methods_supported": []string{"client_secret_basic", "client_secret_post"},
+	})
+}
+
+// loginPage はlogin_hintがない場合に表示するユーザー選択画面
+var loginPage = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Fake IdP</title></head>
+<body>
+<h1>Fake IdP</h1>
+<p>ログインするテストユーザーを選択してください</p>
+<ul>
+{{range .}}<li><a href="{{.URL}}" data-email="{{.Email}}">{{.Name}} &lt;{{.Email}}&gt;{{if not .Verified}}（未認証）{{end}}</a></li>
+{{end}}</ul>
+</body>
+</html>
+`))
+
+// authorize は認可リクエストを処理する
+// login_hintにテストユーザーのメールアドレスを指定した場合は画面を表示せずに認可コードを発行する
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	redirectURI := query.Get("redirect_uri")
+	if _, err := url.ParseRequestURI(redirectURI); err != nil {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	if s.options.ClientID != "" && query.Get("client_id") != s.options.ClientID {
+		http.Error(w, "unknown client_id", http.StatusBadRequest)
+		return
+	}
+	if query.Get("response_type") != "code" {
+		redirectWithParams(w, r, redirectURI, url.Values{"error": {"unsupported_response_type"}, "state": {query.Get("state")}})
+		return
+	}
+
+	email := query.Get("login_hint")
+	if email == "" {
+		s.renderLoginPage(w, r)
+		return
+	}
+	user, ok := s.users[email]
+	if !ok {
+		redirectWithParams(w, r, redirectURI, url.Values{"error": {"access_denied"}, "state": {query.Get("state")}})
+		return
+	}
+
+	code, err := randomString(16)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.mu.Lock()
+	s.codes[code] = &authCode{
+		user:        user,
+		clientID:    query.Get("client_id"),
+		redirectURI: redirectURI,
+		nonce:       query.Get("nonce"),
+		expiresAt:   s.now().Add(s.options.CodeTTL),
+	}
+	s.mu.Unlock()
+
+	slog.InfoContext(r.Context(), "fake idp issued authorization code", slog.String("user_id", user.ID))
+	redirectWithParams(w, r, redirectURI, url.Values{"code": {code}, "state": {query.Get("state")}})
+}
+
+// renderLoginPage はテストユーザーの一覧を表示する
+func (s *Server) renderLoginPage(w http.ResponseWriter, r *http.Request) {
+	type entry struct {
+		Name     string
+		Email    string
+		Verified bool
+		URL      string
+	}
+	entries := make([]entry, 0, len(s.options.Users))
+	for _, user := range s.options.Users {
+		query := r.URL.Query()
+		query.Set("login_hint", user.Email)
+		entries = append(entries, entry{
+			Name:     user.Name,
+			Email:    user.Email,
+			Verified: user.VerifiedEmail,
+			URL:      PathAuthorize + "?" + query.Encode(),
+		})
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := loginPage.Execute(w, entries); err != nil {
+		slog.ErrorContext(r.Context(), "failed to render fake idp login page", slog.Any("error", err))
+	}
+}
+
+// token は認可コードをアクセストークンとIDトークンに交換する
+// 認可コードは1回のみ使用できる
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request")
+		return
+	}
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if !s.validClient(clientID, clientSecret) {
+		writeOAuthError(w, http.StatusUnauthorized, "invalid_client")
+		return
+	}
+	if r.PostForm.Get("grant_type") != "authorization_code" {
+		writeOAuthError(w, http.StatusBadRequest, "unsupported_grant_type")
+		return
+	}
+
+	s.mu.Lock()
+	code, ok := s.codes[r.PostForm.Get("code")]
+	delete(s.codes, r.PostForm.Get("code"))
+	s.mu.Unlock()
+	if !ok || s.now().After(code.expiresAt) || code.clientID != clientID {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_grant")
+		return
+	}
+	if redirectURI := r.PostForm.Get("redirect_uri"); redirectURI != "" && redirectURI != code.redirectURI {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_grant")
+		return
+	}
+
+	value, err := randomString(32)
+	if err != nil {
+		writeOAuthError(w, http.StatusInternalServerError, "server_error")
+		return
+	}
+	idToken, err := s.signIDToken(r, code, clientID)
+	if err != nil {
+		writeOAuthError(w, http.StatusInternalServerError, "server_error")
+		return
+	}
+	s.mu.Lock()
+	s.tokens[value] = &accessToken{user: code.user, expiresAt: s.now().Add(s.options.TokenTTL)}
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": value,
+		"token_type":   "Bearer",
+		"expires_in":   int(s.options.TokenTTL.Seconds()),
+		"id_token":     idToken,
+		"scope":        "openid email profile",
+	})
+}
+
+// validClient はクライアントの認証情報を検証する（設定されていない場合は検証しない）
+func (s *Server) validClient(clientID, clientSecret string) bool {
+	if s.options.ClientID != "" && clientID != s.options.ClientID {
+		return false
+	}
+	if s.options.ClientSecret != "" && subtle.ConstantTimeCompare([]byte(clientSecret), []byte(s.options.ClientSecret)) != 1 {
+		return false
+	}
+	return true
+}
+
+// signIDToken はRS256で署名したIDトークンを発行する
+func (s *Server) signIDToken(r *http.Request, code *authCode, clientID string) (string, error) {
+	now := s.now()
+	claims := jwt.MapClaims{
+		"iss":            s.issuer(r),
+		"sub":            code.user.ID,
+		"aud":            clientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(s.options.TokenTTL).Unix(),
+		"email":          code.user.Email,
+		"email_verified": code.user.VerifiedEmail,
+		"name":           code.user.Name,
+		"picture":        code.user.Picture,
+	}
+	if code.nonce != "" {
+		claims["nonce"] = code.nonce
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = s.keyID
+	return token.SignedString(s.key)
+}
+
+// userInfo はアクセストークンに対応するユーザー情報を返す
+func (s *Server) userInfo(w http.ResponseWriter, r *http.Request) {
+	const prefix = "Bearer "
+	header := r.Header.Get("Authorization")
+	if len(header) <= len(prefix) || header[:len(prefix)] != prefix {
+		writeOAuthError(w, http.StatusUnauthorized, "invalid_token")
+		return
+	}
+
+	s.mu.Lock()
+	token, ok := s.tokens[header[len(prefix):]]
+	s.mu.Unlock()
+	if !ok || s.now().After(token.expiresAt) {
+		writeOAuthError(w, http.StatusUnauthorized, "invalid_token")
+		return
+	}
+	writeJSON(w, http.StatusOK, token.user)
+}
+
+// jwks はIDトークンの署名を検証する公開鍵をJWK Set形式で返す
+func (s *Server) jwks(w http.ResponseWriter, r *http.Request) {
+	encode := base64.RawURLEncoding.EncodeToString
+	writeJSON(w, http.StatusOK, map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"kid": s.keyID,
+			"n":   encode(s.key.PublicKey.N.Bytes()),
+			"e":   encode(big.NewInt(int64(s.key.PublicKey.E)).Bytes()),
+		}},
+	})
+}
+
+// redirectWithParams はredirectURIにクエリパラメータを付与してリダイレクトする
+func redirectWithParams(w http.ResponseWriter, r *http.Request, redirectURI string, params url.Values) {
+	target, err := url.Parse(redirectURI)
+	if err != nil {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+	query := target.Query()
+	for key, values := range params {
+		if values[0] != "" {
+			query.Set(key, values[0])
+		}
+	}
+	target.RawQuery = query.Encode()
+	http.Redirect(w, r, target.String(), http.StatusFound)
+}
+
+// writeOAuthError はOAuth 2.0のエラーレスポンスを書き込む
+func writeOAuthError(w http.ResponseWriter, status int, code string) {
+	writeJSON(w, status, map[string]string{"error": code})
+}
+
+// writeJSON はJSONレスポンスを書き込む
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// randomString はnバイトの乱数を16進数の文字列で返す
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate random value: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,185 @@
+package fakeidp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRedirectURI = "http://localhost:8080/auth/google/callback"
+
+// newTestServer はクライアントを検証するテスト用のプロバイダーを起動する
+func newTestServer(t *testing.T) (*Server, *httptest.Server) {
+	t.Helper()
+	idp, err := New(Options{ClientID: "client", ClientSecret: "secret"})
+	require.NoError(t, err)
+	server := httptest.NewServer(idp)
+	t.Cleanup(server.Close)
+	return idp, server
+}
+
+// noRedirectClient はリダイレクトをたどらないクライアント
+var noRedirectClient = &http.Client{
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// authorize はlogin_hintを指定して認可リクエストを送り、リダイレクト先のクエリを返す
+func authorize(t *testing.T, server *httptest.Server, email string) url.Values {
+	t.Helper()
+	query := url.Values{
+		"client_id":     {"client"},
+		"redirect_uri":  {testRedirectURI},
+		"response_type": {"code"},
+		"state":         {"state"},
+		"nonce":         {"nonce"},
+		"login_hint":    {email},
+	}
+	resp, err := noRedirectClient.Get(server.URL + PathAuthorize + "?" + query.Encode())
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, testRedirectURI, location.Scheme+"://"+location.Host+location.Path)
+	return location.Query()
+}
+
+// exchange は認可コードをトークンに交換する
+func exchange(t *testing.T, server *httptest.Server, code, secret string) (int, map[string]any) {
+	t.Helper()
+	form := url.Values{
+		"grant_type":   {"authorization_code"},
+		"code":         {code},
+		"redirect_uri": {testRedirectURI},
+	}
+	req, err := http.NewRequest(http.MethodPost, server.URL+PathToken, strings.NewReader(form.Encode()))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth("client", secret)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var body map[string]any
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	return resp.StatusCode, body
+}
+
+func TestServer_AuthorizationCodeFlow(t *testing.T) {
+	idp, server := newTestServer(t)
+
+	query := authorize(t, server, "alice@example.com")
+	assert.Equal(t, "state", query.Get("state"))
+	status, body := exchange(t, server, query.Get("code"), "secret")
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "Bearer", body["token_type"])
+
+	// IDトークンはJWKSで公開する鍵で署名されている
+	idToken, err := jwt.Parse(body["id_token"].(string), func(token *jwt.Token) (interface{}, error) {
+		return idp.PublicKey(), nil
+	})
+	require.NoError(t, err)
+	claims := idToken.Claims.(jwt.MapClaims)
+	assert.Equal(t, server.URL, claims["iss"])
+	assert.Equal(t, "client", claims["aud"])
+	assert.Equal(t, "fake-user-1", claims["sub"])
+	assert.Equal(t, "nonce", claims["nonce"])
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+PathUserInfo, nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+body["access_token"].(string))
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var user map[string]any
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&user))
+	assert.Equal(t, "alice@example.com", user["email"])
+	assert.Equal(t, true, user["verified_email"])
+
+	// 認可コードは1回のみ使用できる
+	status, body = exchange(t, server, query.Get("code"), "secret")
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "invalid_grant", body["error"])
+}
+
+func TestServer_Errors(t *testing.T) {
+	_, server := newTestServer(t)
+
+	t.Run("存在しないユーザーはaccess_denied", func(t *testing.T) {
+		query := authorize(t, server, "unknown@example.com")
+		assert.Equal(t, "access_denied", query.Get("error"))
+		assert.Empty(t, query.Get("code"))
+	})
+
+	t.Run("クライアントシークレットが異なる", func(t *testing.T) {
+		query := authorize(t, server, "alice@example.com")
+		status, body := exchange(t, server, query.Get("code"), "wrong")
+		assert.Equal(t, http.StatusUnauthorized, status)
+		assert.Equal(t, "invalid_client", body["error"])
+	})
+
+	t.Run("アクセストークンがない", func(t *testing.T) {
+		resp, err := http.Get(server.URL + PathUserInfo)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	t.Run("login_hintがない場合はユーザー選択画面を表示する", func(t *testing.T) {
+		resp, err := http.Get(server.URL + PathAuthorize + "?client_id=client&response_type=code&redirect_uri=" + url.QueryEscape(testRedirectURI))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Contains(t, resp.Header.Get("Content-Type"), "text/html")
+	})
+}
+
+func TestServer_Metadata(t *testing.T) {
+	_, server := newTestServer(t)
+
+	resp, err := http.Get(server.URL + PathDiscovery)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var discovery map[string]any
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&discovery))
+	assert.Equal(t, server.URL+PathToken, discovery["token_endpoint"])
+	assert.Equal(t, server.URL+PathJWKS, discovery["jwks_uri"])
+
+	resp, err = http.Get(server.URL + PathJWKS)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var jwks struct {
+		Keys []map[string]string `json:"keys"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&jwks))
+	require.Len(t, jwks.Keys, 1)
+	assert.Equal(t, "RS256", jwks.Keys[0]["alg"])
+	assert.Equal(t, "AQAB", jwks.Keys[0]["e"])
+}
+
+func TestLoadUsers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+- id: user-1
+  email: bob@example.com
+  verifiedEmail: false
+  name: Bob
+`), 0o600))
+
+	users, err := LoadUsers(path)
+
+	require.NoError(t, err)
+	assert.Equal(t, []User{{ID: "user-1", Email: "bob@example.com", Name: "Bob"}}, users)
+}
@@ -0,0 +1,62 @@
+package external
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/service"
+
+	"github.com/oschwald/geoip2-golang"
+)
+
+// GeoIPServiceImpl はローカルのMaxMind形式（GeoLite2-City等の.mmdb）データベースを使用したGeoIPServiceの実装
+type GeoIPServiceImpl struct {
+	reader *geoip2.Reader
+}
+
+// NewGeoIPService はデータベースファイルを開いて新しいGeoIPServiceを作成する
+func NewGeoIPService(path string) (service.GeoIPService, error) {
+	if path == "" {
+		return nil, errors.New("geoip database path cannot be empty")
+	}
+
+	reader, err := geoip2.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open geoip database: %w", err)
+	}
+
+	return &GeoIPServiceImpl{
+		reader: reader,
+	}, nil
+}
+
+// Lookup はIPアドレスの位置を返す
+func (g *GeoIPServiceImpl) Lookup(ip string) (*model.GeoLocation, error) {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return nil, fmt.Errorf("invalid ip address: %q", ip)
+	}
+
+	record, err := g.reader.City(parsed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lookup geoip: %w", err)
+	}
+
+	// プライベートアドレスなどデータベースに存在しない場合は位置を特定できない
+	if record.Location.AccuracyRadius == 0 && record.Location.Latitude == 0 && record.Location.Longitude == 0 {
+		return nil, nil
+	}
+
+	return &model.GeoLocation{
+		Country:   record.Country.IsoCode,
+		City:      record.City.Names["en"],
+		Latitude:  record.Location.Latitude,
+		Longitude: record.Location.Longitude,
+	}, nil
+}
+
+// Close はデータベースファイルを閉じる
+func (g *GeoIPServiceImpl) Close() error {
+	return g.reader.Close()
+}
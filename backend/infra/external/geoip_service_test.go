@@ -0,0 +1,26 @@
+package external
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewGeoIPService(t *testing.T) {
+	tests := []struct {
+		testName string
+		path     string
+	}{
+		{testName: "パス未指定でエラー", path: ""},
+		{testName: "存在しないファイルでエラー", path: filepath.Join(t.TempDir(), "GeoLite2-City.mmdb")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			svc, err := NewGeoIPService(tt.path)
+			assert.Error(t, err)
+			assert.Nil(t, svc)
+		})
+	}
+}
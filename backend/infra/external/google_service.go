@@ -5,41 +5,74 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"os"
+	"stackies-backend/core/metrics"
+	"stackies-backend/core/tracing"
 	"stackies-backend/domain/model"
 	"stackies-backend/domain/service"
+	"time"
 
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 )
 
+// GoogleEndpoints はGoogleの認可・トークン・ユーザー情報APIのURLを表す
+// テストではローカルのサーバーを指定する
+type GoogleEndpoints struct {
+	AuthURL     string
+	TokenURL    string
+	UserInfoURL string
+}
+
+// DefaultGoogleEndpoints はGoogleのAPIのURLを返す
+func DefaultGoogleEndpoints() GoogleEndpoints {
+	return GoogleEndpoints{
+		AuthURL:     google.Endpoint.AuthURL,
+		TokenURL:    google.Endpoint.TokenURL,
+		UserInfoURL: "https://www.googleapis.com/oauth2/v2/userinfo",
+	}
+}
+
+// tracer はGoogleへの呼び出しのスパンを作成するトレーサー
+var tracer = otel.Tracer("stackies-backend/infra/external")
+
 // GoogleServiceImpl はGoogleService interfaceの実装
 type GoogleServiceImpl struct {
-	config *oauth2.Config
+	config      *oauth2.Config
+	userInfoURL string
+	httpClient  *http.Client
+	metrics     *metrics.Metrics
 }
 
 // NewGoogleService は新しいGoogleServiceを作成する
-func NewGoogleService(clientID, clientSecret string) service.GoogleService {
-	// 環境変数からリダイレクトURIを取得、デフォルトはlocalhost:3000
-	redirectURI := os.Getenv("GOOGLE_REDIRECT_URI")
-	if redirectURI == "" {
-		redirectURI = "http://localhost:8080/auth/google/login"
-	}
-
+// redirectURLはGoogleに登録したサーバーサイドのコールバックURL
+// clientはトークン交換・ユーザー情報取得に使用する（nilの場合はトレースのみ行う既定のクライアント）
+// Googleへの呼び出しの処理時間と失敗をmに記録する（nilの場合は記録しない）
+func NewGoogleService(clientID, clientSecret, redirectURL string, endpoints GoogleEndpoints, client *http.Client, m *metrics.Metrics) service.GoogleService {
 	config := &oauth2.Config{
 		ClientID:     clientID,
 		ClientSecret: clientSecret,
-		RedirectURL:  redirectURI,
+		RedirectURL:  redirectURL,
 		Scopes: []string{
 			"openid",
 			"https://www.googleapis.com/auth/userinfo.email",
 			"https://www.googleapis.com/auth/userinfo.profile",
 		},
-		Endpoint: google.Endpoint,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  endpoints.AuthURL,
+			TokenURL: endpoints.TokenURL,
+		},
+	}
+	if client == nil {
+		client = &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport)}
 	}
 
 	return &GoogleServiceImpl{
-		config: config,
+		config:      config,
+		userInfoURL: endpoints.UserInfoURL,
+		httpClient:  client,
+		metrics:     m,
 	}
 }
 
@@ -50,7 +83,13 @@ func (g *GoogleServiceImpl) GenerateAuthURL(state string) string {
 
 // ExchangeCode は認証コードをアクセストークンに交換する
 func (g *GoogleServiceImpl) ExchangeCode(ctx context.Context, code, redirectURI string) (*model.AuthToken, error) {
-	token, err := g.config.Exchange(ctx, code)
+	ctx, span := tracer.Start(ctx, "GoogleService.ExchangeCode")
+	defer span.End()
+
+	start := time.Now()
+	token, err := g.config.Exchange(context.WithValue(ctx, oauth2.HTTPClient, g.httpClient), code)
+	g.metrics.ObserveOutbound("google", "exchange_code", time.Since(start), err)
+	tracing.RecordError(span, err)
 	if err != nil {
 		return nil, fmt.Errorf("failed to exchange code: %w", err)
 	}
@@ -64,16 +103,23 @@ func (g *GoogleServiceImpl) ExchangeCode(ctx context.Context, code, redirectURI
 }
 
 // GetUserInfo はアクセストークンを使用してユーザー情報を取得する
-func (g *GoogleServiceImpl) GetUserInfo(ctx context.Context, accessToken string) (*model.GoogleUserInfo, error) {
-	client := &http.Client{}
-	req, err := http.NewRequestWithContext(ctx, "GET", "https://www.googleapis.com/oauth2/v2/userinfo", nil)
+func (g *GoogleServiceImpl) GetUserInfo(ctx context.Context, accessToken string) (userInfo *model.GoogleUserInfo, err error) {
+	ctx, span := tracer.Start(ctx, "GoogleService.GetUserInfo")
+	start := time.Now()
+	defer func() {
+		g.metrics.ObserveOutbound("google", "userinfo", time.Since(start), err)
+		tracing.RecordError(span, err)
+		span.End()
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", g.userInfoURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Authorization", "Bearer "+accessToken)
 
-	resp, err := client.Do(req)
+	resp, err := g.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user info: %w", err)
 	}
@@ -83,10 +129,10 @@ func (g *GoogleServiceImpl) GetUserInfo(ctx context.Context, accessToken string)
 		return nil, fmt.Errorf("google API returned status %d", resp.StatusCode)
 	}
 
-	var userInfo model.GoogleUserInfo
-	if err := json.NewDecoder(resp.Body).Decode(&userInfo); err != nil {
+	userInfo = &model.GoogleUserInfo{}
+	if err := json.NewDecoder(resp.Body).Decode(userInfo); err != nil {
 		return nil, fmt.Errorf("failed to decode user info: %w", err)
 	}
 
-	return &userInfo, nil
+	return userInfo, nil
 }
@@ -2,11 +2,20 @@ package external
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
+	"stackies-backend/core/metrics"
 	"stackies-backend/domain/model"
 	"stackies-backend/domain/service"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
 // MockGoogleService はテスト用のモックサービス
@@ -138,3 +147,77 @@ func TestGoogleServiceImpl_GetUserInfo(t *testing.T) {
 		})
 	}
 }
+
+// testGoogleEndpoints はローカルのテスト用サーバーを指すGoogleEndpointsを返す
+func testGoogleEndpoints(baseURL string) GoogleEndpoints {
+	return GoogleEndpoints{
+		AuthURL:     baseURL + "/auth",
+		TokenURL:    baseURL + "/token",
+		UserInfoURL: baseURL + "/userinfo",
+	}
+}
+
+func TestGoogleServiceImpl_Metrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_grant"}`))
+		case "/userinfo":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"id":"google_123","email":"test@example.com","verified_email":true}`))
+		}
+	}))
+	defer server.Close()
+
+	m := metrics.New()
+	svc := NewGoogleService("client", "secret", "http://localhost:8080/auth/google/callback", testGoogleEndpoints(server.URL), server.Client(), m)
+
+	_, err := svc.ExchangeCode(context.Background(), "invalid_code", "")
+	assert.Error(t, err)
+	userInfo, err := svc.GetUserInfo(context.Background(), "access_token")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "google_123", userInfo.ID)
+	}
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+	assert.Contains(t, body, `stackies_outbound_request_errors_total{operation="exchange_code",provider="google"} 1`)
+	assert.Contains(t, body, `stackies_outbound_request_duration_seconds_count{operation="userinfo",outcome="success",provider="google"} 1`)
+}
+
+func TestGoogleServiceImpl_Tracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(noop.NewTracerProvider())
+	})
+
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/userinfo" {
+			traceparent = r.Header.Get("traceparent")
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	svc := NewGoogleService("client", "secret", "", testGoogleEndpoints(server.URL), nil, nil)
+
+	_, err := svc.GetUserInfo(context.Background(), "access_token")
+	assert.Error(t, err)
+
+	// Googleへのリクエストにトレースコンテキストを伝播する
+	assert.NotEmpty(t, traceparent)
+	var names []string
+	for _, span := range recorder.Ended() {
+		names = append(names, span.Name())
+		if span.Name() == "GoogleService.GetUserInfo" {
+			assert.Equal(t, codes.Error, span.Status().Code)
+		}
+	}
+	assert.Contains(t, names, "GoogleService.GetUserInfo")
+	assert.Len(t, names, 2)
+}
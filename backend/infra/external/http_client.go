@@ -0,0 +1,235 @@
+package external
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// HTTPClientOptions は外部サービス向けHTTPクライアントの設定を表す
+type HTTPClientOptions struct {
+	// Timeout はリトライを含む1回の呼び出し全体の最大時間
+	Timeout time.Duration
+	// DialTimeout・TLSHandshakeTimeout・ResponseHeaderTimeoutは接続の各段階の最大時間
+	DialTimeout           time.Duration
+	TLSHandshakeTimeout   time.Duration
+	ResponseHeaderTimeout time.Duration
+	// MaxIdleConnsPerHost・IdleConnTimeoutは接続プールの設定
+	MaxIdleConnsPerHost int
+	IdleConnTimeout     time.Duration
+	// MaxRetries は冪等なリクエストを再試行する最大回数
+	MaxRetries int
+	// RetryBaseDelay・RetryMaxDelayは再試行の待ち時間（指数バックオフにジッターを加える）
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+	// BreakerThreshold はサーキットブレーカーが遮断するまでの連続した失敗数（0の場合は遮断しない）
+	BreakerThreshold int
+	// BreakerCooldown は遮断してから回復を確認するまでの時間
+	BreakerCooldown time.Duration
+	// DialControl は接続する直前に、名前解決したアドレスを検証する（nilの場合は検証しない）
+	// プロキシを経由すると接続先を検証できないため、指定した場合は環境変数のプロキシを使わない
+	DialControl func(network, address string, conn syscall.RawConn) error
+}
+
+// HTTPClientFactory は外部サービスごとのHTTPクライアントを作成する
+// 接続プールはすべてのクライアントで共有し、サーキットブレーカーは外部サービスごとに持つ
+type HTTPClientFactory struct {
+	options   HTTPClientOptions
+	transport http.RoundTripper
+
+	mu      sync.Mutex
+	clients map[string]*http.Client
+}
+
+// NewHTTPClientFactory は新しいHTTPClientFactoryを作成する
+func NewHTTPClientFactory(options HTTPClientOptions) *HTTPClientFactory {
+	dialer := &net.Dialer{
+		Timeout:   options.DialTimeout,
+		KeepAlive: 30 * time.Second,
+		Control:   options.DialControl,
+	}
+	proxy := http.ProxyFromEnvironment
+	if options.DialControl != nil {
+		proxy = nil
+	}
+	transport := &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   options.MaxIdleConnsPerHost,
+		IdleConnTimeout:       options.IdleConnTimeout,
+		TLSHandshakeTimeout:   options.TLSHandshakeTimeout,
+		ResponseHeaderTimeout: options.ResponseHeaderTimeout,
+		ExpectContinueTimeout: time.Second,
+	}
+	return &HTTPClientFactory{
+		options:   options,
+		transport: transport,
+		clients:   make(map[string]*http.Client),
+	}
+}
+
+// Client は外部サービス（provider）向けのHTTPクライアントを返す（同じproviderには同じクライアントを返す）
+// 冪等なリクエストは一時的な失敗の場合に再試行し、失敗が続いた場合は遮断してErrCircuitOpenを返す
+// 再試行ごとにトレースのスパンを作成する
+func (f *HTTPClientFactory) Client(provider string) *http.Client {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if client, ok := f.clients[provider]; ok {
+		return client
+	}
+	client := &http.Client{
+		Timeout: f.options.Timeout,
+		Transport: &resilientTransport{
+			provider: provider,
+			next:     otelhttp.NewTransport(f.transport),
+			breaker:  NewCircuitBreaker(provider, f.options.BreakerThreshold, f.options.BreakerCooldown),
+			options:  f.options,
+			sleep:    sleepContext,
+		},
+	}
+	f.clients[provider] = client
+	return client
+}
+
+// Remove はproviderのクライアントを破棄する（次のClientの呼び出しでは新しいクライアントを作成する）
+// 購読ごとのクライアントのように、使われなくなったproviderのクライアントが残り続けないようにする
+func (f *HTTPClientFactory) Remove(provider string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.clients, provider)
+}
+
+// CloseIdleConnections はプール中の未使用の接続を閉じる
+func (f *HTTPClientFactory) CloseIdleConnections() {
+	if transport, ok := f.transport.(*http.Transport); ok {
+		transport.CloseIdleConnections()
+	}
+}
+
+// resilientTransport は再試行とサーキットブレーカーを適用するhttp.RoundTripper
+type resilientTransport struct {
+	provider string
+	next     http.RoundTripper
+	breaker  *CircuitBreaker
+	options  HTTPClientOptions
+	sleep    func(ctx context.Context, d time.Duration) error
+}
+
+// RoundTrip はリクエストを送信する
+func (t *resilientTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	attempts := 1
+	if isIdempotent(req) {
+		attempts += t.options.MaxRetries
+	}
+
+	for attempt := 0; ; attempt++ {
+		if err := t.breaker.Allow(); err != nil {
+			return nil, fmt.Errorf("%s: %w", t.provider, err)
+		}
+
+		attemptReq, err := rewindBody(req, attempt)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := t.next.RoundTrip(attemptReq)
+		retryable := isRetryable(resp, err)
+		// 呼び出し元のキャンセルは外部サービスの障害として数えない
+		if !errors.Is(err, context.Canceled) {
+			t.breaker.Record(!retryable)
+		}
+
+		if !retryable || attempt+1 >= attempts || req.Context().Err() != nil {
+			return resp, err
+		}
+
+		delay := t.backoff(attempt, resp)
+		if resp != nil {
+			// 再試行する前にレスポンスを読み捨てて接続を再利用できるようにする
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if err := t.sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// backoff は再試行までの待ち時間を返す
+// Retry-Afterが指定されている場合はそれに従い、そうでなければ指数バックオフにフルジッターを加える
+func (t *resilientTransport) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return min(time.Duration(seconds)*time.Second, t.options.RetryMaxDelay)
+		}
+	}
+	ceiling := min(t.options.RetryBaseDelay<<attempt, t.options.RetryMaxDelay)
+	if ceiling <= 0 {
+		return 0
+	}
+	return rand.N(ceiling) //nolint:gosec // 待ち時間のジッターに暗号学的な乱数は不要
+}
+
+// isIdempotent は再試行してよいリクエストかを返す
+// トークン交換のようなPOSTは二重に処理されるおそれがあるため、Idempotency-Keyがない限り再試行しない
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return req.Header.Get("Idempotency-Key") != ""
+}
+
+// isRetryable は一時的な失敗（接続エラー・タイムアウト・429・502・503・504）かを返す
+func isRetryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// rewindBody は再試行のためにリクエストボディを先頭から読み直せるリクエストを返す
+func rewindBody(req *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 0 || req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+	if req.GetBody == nil {
+		return nil, errors.New("request body cannot be replayed")
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, fmt.Errorf("failed to replay request body: %w", err)
+	}
+	clone := req.Clone(req.Context())
+	clone.Body = body
+	return clone, nil
+}
+
+// sleepContext はcontextがキャンセルされるまでの間、指定した時間待つ
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
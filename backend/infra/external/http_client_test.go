@@ -0,0 +1,193 @@
+package external
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestHTTPClient は待ち時間なしで再試行するテスト用のクライアントを返す
+func newTestHTTPClient(options HTTPClientOptions) (*http.Client, *[]time.Duration) {
+	client := NewHTTPClientFactory(options).Client("google")
+	var delays []time.Duration
+	client.Transport.(*resilientTransport).sleep = func(ctx context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return ctx.Err()
+	}
+	return client, &delays
+}
+
+func TestHTTPClientFactory_Retry(t *testing.T) {
+	options := HTTPClientOptions{
+		Timeout:        time.Second,
+		MaxRetries:     2,
+		RetryBaseDelay: 100 * time.Millisecond,
+		RetryMaxDelay:  time.Second,
+	}
+
+	tests := []struct {
+		testName     string
+		method       string
+		header       map[string]string
+		statuses     []int
+		wantStatus   int
+		wantRequests int32
+	}{
+		{
+			testName:     "GETは一時的な失敗の後に成功するまで再試行する",
+			method:       http.MethodGet,
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK},
+			wantStatus:   http.StatusOK,
+			wantRequests: 3,
+		},
+		{
+			testName:     "再試行の上限に達すると最後のレスポンスを返す",
+			method:       http.MethodGet,
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK},
+			wantStatus:   http.StatusServiceUnavailable,
+			wantRequests: 3,
+		},
+		{
+			testName:     "一時的でない失敗は再試行しない",
+			method:       http.MethodGet,
+			statuses:     []int{http.StatusBadRequest, http.StatusOK},
+			wantStatus:   http.StatusBadRequest,
+			wantRequests: 1,
+		},
+		{
+			testName:     "POSTは再試行しない",
+			method:       http.MethodPost,
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusOK},
+			wantStatus:   http.StatusServiceUnavailable,
+			wantRequests: 1,
+		},
+		{
+			testName:     "Idempotency-KeyのあるPOSTは本文を送り直して再試行する",
+			method:       http.MethodPost,
+			header:       map[string]string{"Idempotency-Key": "key"},
+			statuses:     []int{http.StatusServiceUnavailable, http.StatusOK},
+			wantStatus:   http.StatusOK,
+			wantRequests: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := requests.Add(1)
+				if r.Method == http.MethodPost {
+					body := make([]byte, 4)
+					_, _ = r.Body.Read(body)
+					assert.Equal(t, "body", string(body))
+				}
+				w.WriteHeader(tt.statuses[n-1])
+			}))
+			defer server.Close()
+			client, _ := newTestHTTPClient(options)
+
+			req, err := http.NewRequest(tt.method, server.URL, strings.NewReader("body"))
+			require.NoError(t, err)
+			for key, value := range tt.header {
+				req.Header.Set(key, value)
+			}
+			resp, err := client.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+			assert.Equal(t, tt.wantRequests, requests.Load())
+		})
+	}
+
+	t.Run("待ち時間は上限を超えず、Retry-Afterに従う", func(t *testing.T) {
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if requests.Add(1) == 1 {
+				w.Header().Set("Retry-After", "60")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+		client, delays := newTestHTTPClient(options)
+
+		resp, err := client.Get(server.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		require.Len(t, *delays, 2)
+		assert.Equal(t, time.Second, (*delays)[0])
+		assert.Less(t, (*delays)[1], 200*time.Millisecond)
+	})
+
+	t.Run("キャンセルされると再試行をやめる", func(t *testing.T) {
+		var requests atomic.Int32
+		ctx, cancel := context.WithCancel(context.Background())
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			cancel()
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+		client, _ := newTestHTTPClient(options)
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		if err == nil {
+			resp.Body.Close()
+		}
+
+		assert.Equal(t, int32(1), requests.Load())
+	})
+}
+
+func TestHTTPClientFactory_CircuitBreaker(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	factory := NewHTTPClientFactory(HTTPClientOptions{
+		Timeout:          time.Second,
+		BreakerThreshold: 2,
+		BreakerCooldown:  time.Minute,
+	})
+
+	for range 2 {
+		resp, err := factory.Client("google").Get(server.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	_, err := factory.Client("google").Get(server.URL)
+
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, int32(2), requests.Load())
+
+	t.Run("遮断は外部サービスごとに行う", func(t *testing.T) {
+		resp, err := factory.Client("other").Get(server.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+	})
+
+	t.Run("破棄したクライアントは遮断の状態を引き継がない", func(t *testing.T) {
+		previous := factory.Client("google")
+		factory.Remove("google")
+		client := factory.Client("google")
+		assert.NotSame(t, previous, client)
+
+		resp, err := client.Get(server.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+	})
+}
@@ -0,0 +1,32 @@
+package external
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"stackies-backend/core/health"
+)
+
+// NewHTTPProbe はURLへの疎通を確認するヘルスチェックを作成する
+// 5xxのレスポンスまたは接続できない場合を異常とする
+func NewHTTPProbe(client *http.Client, url string) health.Check {
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to reach %s: %w", req.URL.Host, err)
+		}
+		defer resp.Body.Close()
+		_, _ = io.Copy(io.Discard, resp.Body)
+
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return fmt.Errorf("%s returned status %d", req.URL.Host, resp.StatusCode)
+		}
+		return nil
+	}
+}
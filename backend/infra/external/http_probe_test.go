@@ -0,0 +1,47 @@
+package external
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPProbe(t *testing.T) {
+	tests := []struct {
+		testName string
+		status   int
+		wantErr  bool
+	}{
+		{testName: "正常", status: http.StatusOK},
+		{testName: "4xxは到達できているため正常", status: http.StatusNotFound},
+		{testName: "5xxは異常", status: http.StatusServiceUnavailable, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			err := NewHTTPProbe(server.Client(), server.URL)(context.Background())
+
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	t.Run("接続できない", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		url := server.URL
+		server.Close()
+
+		assert.Error(t, NewHTTPProbe(http.DefaultClient, url)(context.Background()))
+	})
+}
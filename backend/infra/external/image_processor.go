@@ -0,0 +1,226 @@
+package external
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/service"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/webp"
+)
+
+const (
+	// defaultMaxImagePixels はデコードを許可する画像の最大画素数（展開後のサイズが極端に大きい画像を拒否する）
+	defaultMaxImagePixels = 40 * 1000 * 1000
+	// avatarJPEGQuality は透過のない画像をJPEGで保存する際の品質
+	avatarJPEGQuality = 85
+)
+
+// imageDecoders はContent-Typeの判定結果ごとのデコーダー
+// ファイル名やリクエストのContent-Typeは信頼せず、データの内容から判定する
+var imageDecoders = map[string]struct {
+	decode       func(r *bytes.Reader) (image.Image, error)
+	decodeConfig func(r *bytes.Reader) (image.Config, error)
+}{
+	"image/jpeg": {
+		decode:       func(r *bytes.Reader) (image.Image, error) { return jpeg.Decode(r) },
+		decodeConfig: func(r *bytes.Reader) (image.Config, error) { return jpeg.DecodeConfig(r) },
+	},
+	"image/png": {
+		decode:       func(r *bytes.Reader) (image.Image, error) { return png.Decode(r) },
+		decodeConfig: func(r *bytes.Reader) (image.Config, error) { return png.DecodeConfig(r) },
+	},
+	"image/gif": {
+		// アニメーションGIFは先頭のフレームのみ使う
+		decode:       func(r *bytes.Reader) (image.Image, error) { return gif.Decode(r) },
+		decodeConfig: func(r *bytes.Reader) (image.Config, error) { return gif.DecodeConfig(r) },
+	},
+	"image/webp": {
+		decode:       func(r *bytes.Reader) (image.Image, error) { return webp.Decode(r) },
+		decodeConfig: func(r *bytes.Reader) (image.Config, error) { return webp.DecodeConfig(r) },
+	},
+}
+
+// ImageProcessorImpl は標準ライブラリとgolang.org/x/imageを使用したImageProcessorの実装
+type ImageProcessorImpl struct {
+	maxPixels int
+}
+
+// NewImageProcessor は新しいImageProcessorを作成する
+func NewImageProcessor() service.ImageProcessor {
+	return &ImageProcessorImpl{
+		maxPixels: defaultMaxImagePixels,
+	}
+}
+
+// ProcessAvatar は画像を中央で正方形に切り抜き、各サイズに縮小して再エンコードする
+// 再エンコードによりEXIFなどのメタデータは保存しない（JPEGの向きの指定は画素に反映する）
+// 透過のない画像はJPEG、透過のある画像はPNGで保存する
+func (p *ImageProcessorImpl) ProcessAvatar(data []byte) (model.AvatarFormat, []*model.AvatarImage, error) {
+	contentType := http.DetectContentType(data)
+	decoder, ok := imageDecoders[contentType]
+	if !ok {
+		return "", nil, fmt.Errorf("%w: %s", service.ErrUnsupportedImage, contentType)
+	}
+
+	config, err := decoder.decodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %v", service.ErrUnsupportedImage, err)
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > p.maxPixels {
+		return "", nil, fmt.Errorf("%w: %dx%d exceeds %d pixels", service.ErrUnsupportedImage, config.Width, config.Height, p.maxPixels)
+	}
+
+	src, err := decoder.decode(bytes.NewReader(data))
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %v", service.ErrUnsupportedImage, err)
+	}
+	orientation := 1
+	if contentType == "image/jpeg" {
+		orientation = jpegOrientation(data)
+	}
+
+	crop := centerSquare(src.Bounds())
+	resized := make([]*image.RGBA, len(model.AvatarSizes))
+	opaque := true
+	for i, size := range model.AvatarSizes {
+		dst := image.NewRGBA(image.Rect(0, 0, size, size))
+		draw.CatmullRom.Scale(dst, dst.Bounds(), src, crop, draw.Src, nil)
+		// 中央の正方形は回転・反転しても同じ範囲のため、縮小後の小さな画像に向きを反映する
+		resized[i] = orient(dst, orientation)
+		opaque = opaque && resized[i].Opaque()
+	}
+
+	format := model.AvatarFormatPNG
+	if opaque {
+		format = model.AvatarFormatJPEG
+	}
+
+	images := make([]*model.AvatarImage, len(resized))
+	for i, img := range resized {
+		var buf bytes.Buffer
+		if format == model.AvatarFormatJPEG {
+			err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: avatarJPEGQuality})
+		} else {
+			err = png.Encode(&buf, img)
+		}
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to encode avatar: %w", err)
+		}
+		images[i] = &model.AvatarImage{Size: model.AvatarSizes[i], Data: buf.Bytes()}
+	}
+
+	return format, images, nil
+}
+
+// centerSquare は画像の中央の正方形の範囲を返す
+func centerSquare(bounds image.Rectangle) image.Rectangle {
+	side := min(bounds.Dx(), bounds.Dy())
+	x := bounds.Min.X + (bounds.Dx()-side)/2
+	y := bounds.Min.Y + (bounds.Dy()-side)/2
+	return image.Rect(x, y, x+side, y+side)
+}
+
+// orient はEXIFのOrientation（1〜8）に従って正方形の画像を回転・反転する
+func orient(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+
+	n := src.Bounds().Dx()
+	dst := image.NewRGBA(src.Bounds())
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // 左右反転
+				sx, sy = n-1-x, y
+			case 3: // 180度回転
+				sx, sy = n-1-x, n-1-y
+			case 4: // 上下反転
+				sx, sy = x, n-1-y
+			case 5: // 左上から右下の対角線で反転
+				sx, sy = y, x
+			case 6: // 時計回りに90度回転
+				sx, sy = y, n-1-x
+			case 7: // 右上から左下の対角線で反転
+				sx, sy = n-1-y, n-1-x
+			case 8: // 反時計回りに90度回転
+				sx, sy = n-1-y, x
+			}
+			dst.SetRGBA(x, y, src.RGBAAt(sx, sy))
+		}
+	}
+	return dst
+}
+
+// jpegOrientation はJPEGのEXIF（APP1）からOrientationを読み取る（見つからない場合は1）
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	for pos := 2; pos+4 <= len(data); {
+		if data[pos] != 0xFF {
+			return 1
+		}
+		marker := data[pos+1]
+		// SOS以降は画像データのためメタデータはない
+		if marker == 0xDA || marker == 0xD9 {
+			return 1
+		}
+		length := int(binary.BigEndian.Uint16(data[pos+2 : pos+4]))
+		if length < 2 || pos+2+length > len(data) {
+			return 1
+		}
+		segment := data[pos+4 : pos+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return exifOrientation(segment[6:])
+		}
+		pos += 2 + length
+	}
+	return 1
+}
+
+// exifOrientation はTIFF形式のEXIFの先頭のIFDからOrientation（タグ0x0112）を読み取る
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	offset := int(order.Uint32(tiff[4:8]))
+	if offset < 8 || offset+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[offset : offset+2]))
+	for i := 0; i < count; i++ {
+		entry := offset + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:entry+2]) == 0x0112 {
+			orientation := int(order.Uint16(tiff[entry+8 : entry+10]))
+			if orientation < 1 || orientation > 8 {
+				return 1
+			}
+			return orientation
+		}
+	}
+	return 1
+}
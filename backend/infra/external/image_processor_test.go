@@ -0,0 +1,136 @@
+package external
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/service"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testImage は左半分が赤、右半分が青の画像を作成する（alphaが255未満の場合は透過する）
+func testImage(width, height int, alpha uint8) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if x < width/2 {
+				img.SetNRGBA(x, y, color.NRGBA{R: 255, A: alpha})
+			} else {
+				img.SetNRGBA(x, y, color.NRGBA{B: 255, A: alpha})
+			}
+		}
+	}
+	return img
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+// encodeJPEGWithOrientation はEXIFのOrientationを含むJPEGを作成する
+func encodeJPEGWithOrientation(t *testing.T, img image.Image, orientation uint16) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}))
+	encoded := buf.Bytes()
+
+	// ビッグエンディアンのTIFFヘッダーとOrientationのみのIFD
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08\x00\x01")
+	tiff = binary.BigEndian.AppendUint16(tiff, 0x0112)
+	tiff = binary.BigEndian.AppendUint16(tiff, 3)
+	tiff = binary.BigEndian.AppendUint32(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0)
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+
+	app1 := []byte{0xFF, 0xE1}
+	app1 = binary.BigEndian.AppendUint16(app1, uint16(len(segment)+2))
+	app1 = append(app1, segment...)
+
+	// SOIの直後にAPP1を挿入する
+	withExif := append([]byte{}, encoded[:2]...)
+	withExif = append(withExif, app1...)
+	return append(withExif, encoded[2:]...)
+}
+
+func TestImageProcessorImpl_ProcessAvatar(t *testing.T) {
+	processor := NewImageProcessor()
+
+	t.Run("透過のない画像はJPEGで各サイズに変換する", func(t *testing.T) {
+		format, images, err := processor.ProcessAvatar(encodePNG(t, testImage(300, 200, 255)))
+		require.NoError(t, err)
+		assert.Equal(t, model.AvatarFormatJPEG, format)
+		require.Len(t, images, len(model.AvatarSizes))
+
+		for i, img := range images {
+			assert.Equal(t, model.AvatarSizes[i], img.Size)
+			decoded, err := jpeg.Decode(bytes.NewReader(img.Data))
+			require.NoError(t, err)
+			assert.Equal(t, image.Rect(0, 0, img.Size, img.Size), decoded.Bounds())
+		}
+	})
+
+	t.Run("透過のある画像はPNGで保存する", func(t *testing.T) {
+		format, images, err := processor.ProcessAvatar(encodePNG(t, testImage(100, 100, 128)))
+		require.NoError(t, err)
+		assert.Equal(t, model.AvatarFormatPNG, format)
+
+		_, err = png.Decode(bytes.NewReader(images[0].Data))
+		assert.NoError(t, err)
+	})
+
+	t.Run("EXIFの向きを反映し、メタデータは保存しない", func(t *testing.T) {
+		// 時計回りに90度回転して表示する画像は、左の赤が上、右の青が下になる
+		data := encodeJPEGWithOrientation(t, testImage(200, 200, 255), 6)
+		require.Equal(t, 6, jpegOrientation(data))
+
+		format, images, err := processor.ProcessAvatar(data)
+		require.NoError(t, err)
+		assert.Equal(t, model.AvatarFormatJPEG, format)
+
+		largest := images[len(images)-1]
+		assert.NotContains(t, string(largest.Data), "Exif")
+		decoded, err := jpeg.Decode(bytes.NewReader(largest.Data))
+		require.NoError(t, err)
+
+		top := color.NRGBAModel.Convert(decoded.At(largest.Size/2, 10)).(color.NRGBA)
+		bottom := color.NRGBAModel.Convert(decoded.At(largest.Size/2, largest.Size-10)).(color.NRGBA)
+		assert.Greater(t, top.R, top.B)
+		assert.Greater(t, bottom.B, bottom.R)
+	})
+
+	t.Run("画像でないデータはエラー", func(t *testing.T) {
+		_, _, err := processor.ProcessAvatar([]byte("<svg xmlns=\"http://www.w3.org/2000/svg\"></svg>"))
+		assert.ErrorIs(t, err, service.ErrUnsupportedImage)
+	})
+
+	t.Run("形式と内容が一致しない壊れた画像はエラー", func(t *testing.T) {
+		data := encodePNG(t, testImage(100, 100, 255))
+		_, _, err := processor.ProcessAvatar(data[:len(data)/2])
+		assert.ErrorIs(t, err, service.ErrUnsupportedImage)
+	})
+
+	t.Run("画素数が上限を超える画像はデコードせずにエラー", func(t *testing.T) {
+		limited := &ImageProcessorImpl{maxPixels: 100 * 100}
+		_, _, err := limited.ProcessAvatar(encodePNG(t, testImage(101, 100, 255)))
+		assert.ErrorIs(t, err, service.ErrUnsupportedImage)
+	})
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, testImage(10, 10, 255), nil))
+
+	assert.Equal(t, 1, jpegOrientation(buf.Bytes()), "EXIFがない場合は1")
+	assert.Equal(t, 8, jpegOrientation(encodeJPEGWithOrientation(t, testImage(10, 10, 255), 8)))
+	assert.Equal(t, 1, jpegOrientation(encodeJPEGWithOrientation(t, testImage(10, 10, 255), 9)), "範囲外の値は1")
+	assert.Equal(t, 1, jpegOrientation([]byte("not a jpeg")))
+}
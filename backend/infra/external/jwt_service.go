@@ -1,7 +1,11 @@
 package external
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"stackies-backend/domain/model"
 	"stackies-backend/domain/service"
 	"time"
 
@@ -9,17 +13,38 @@ import (
 )
 
 // JWTServiceImpl はJWTService interfaceの実装
+// 署名には現在の鍵を使い、検証にはローテーション前の鍵も使う（ヘッダーのkidで鍵を選ぶ）
 type JWTServiceImpl struct {
 	secretKey string
+	keyID     string
+	// verifyKeys はkidごとの検証用の鍵（現在の鍵とローテーション前の鍵）
+	verifyKeys map[string]string
 }
 
 // NewJWTService は新しいJWTServiceを作成する
-func NewJWTService(secretKey string) service.JWTService {
+// previousSecretKeysにはローテーション前の鍵を指定し、発行済みのトークンが期限切れになるまで検証に使う
+func NewJWTService(secretKey string, previousSecretKeys ...string) service.JWTService {
+	keyID := KeyID(secretKey)
+	verifyKeys := map[string]string{keyID: secretKey}
+	for _, previous := range previousSecretKeys {
+		if previous != "" {
+			verifyKeys[KeyID(previous)] = previous
+		}
+	}
 	return &JWTServiceImpl{
-		secretKey: secretKey,
+		secretKey:  secretKey,
+		keyID:      keyID,
+		verifyKeys: verifyKeys,
 	}
 }
 
+// KeyID は鍵を識別するID（JWTヘッダーのkid）を返す
+// 鍵そのものを推測できないよう、SHA-256ハッシュの先頭8バイトを使う
+func KeyID(secretKey string) string {
+	sum := sha256.Sum256([]byte(secretKey))
+	return hex.EncodeToString(sum[:8])
+}
+
 // GenerateToken はJWTアクセストークンを生成する
 func (j *JWTServiceImpl) GenerateToken(userID string) (string, error) {
 	if userID == "" {
@@ -32,29 +57,54 @@ func (j *JWTServiceImpl) GenerateToken(userID string) (string, error) {
 		"exp":     time.Now().Add(time.Hour * 24).Unix(),
 		"iat":     time.Now().Unix(),
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(j.secretKey))
-	if err != nil {
-		return "", err
-	}
-
-	return tokenString, nil
+	return j.sign(claims)
 }
 
 // ValidateToken はJWTトークンを検証してユーザーIDを返す
+// なりすましのトークンはリフレッシュなどセッションの操作に使えないよう無効とする
 func (j *JWTServiceImpl) ValidateToken(token string) (string, error) {
-	parsedToken, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
-		return []byte(j.secretKey), nil
-	})
+	claims, err := j.ParseToken(token)
 	if err != nil {
 		return "", err
 	}
+	if claims.IsImpersonation() {
+		return "", errors.New("impersonation token is not accepted")
+	}
+	return claims.UserID, nil
+}
+
+// ParseToken はJWTトークンを検証してクレームを返す
+func (j *JWTServiceImpl) ParseToken(token string) (*model.TokenClaims, error) {
+	parsedToken, err := jwt.Parse(token, j.verifyKey)
+	if err != nil {
+		return nil, err
+	}
 	claims, ok := parsedToken.Claims.(jwt.MapClaims)
 	if !ok {
-		return "", errors.New("invalid token")
+		return nil, errors.New("invalid token")
+	}
+	userID, ok := claims["user_id"].(string)
+	if !ok || userID == "" {
+		return nil, errors.New("invalid token")
 	}
-	userID := claims["user_id"].(string)
-	return userID, nil
+
+	result := &model.TokenClaims{UserID: userID}
+	if exp, ok := claims["exp"].(float64); ok {
+		result.ExpiresAt = time.Unix(int64(exp), 0)
+	}
+	if iat, ok := claims["iat"].(float64); ok {
+		result.IssuedAt = time.Unix(int64(iat), 0)
+	}
+	if act, exists := claims["act"]; exists {
+		// RFC 8693のactクレーム（{"sub": "<管理者のID>"}）
+		actor, _ := act.(map[string]interface{})
+		actorID, _ := actor["sub"].(string)
+		if actorID == "" {
+			return nil, errors.New("invalid act claim")
+		}
+		result.ActorID = actorID
+	}
+	return result, nil
 }
 
 // GenerateRefreshToken はJWTリフレッシュトークンを生成する
@@ -69,10 +119,49 @@ func (j *JWTServiceImpl) GenerateRefreshToken(userID string) (string, error) {
 		"exp":     time.Now().Add(time.Hour * 24 * 30).Unix(),
 		"iat":     time.Now().Unix(),
 	}
+	return j.sign(claims)
+}
+
+// GenerateImpersonationToken はなりすましのためのJWTアクセストークンを生成する
+// 操作している管理者をRFC 8693のactクレームに含め、リフレッシュトークンは発行しない
+func (j *JWTServiceImpl) GenerateImpersonationToken(userID, actorID string, ttl time.Duration) (string, error) {
+	if userID == "" || actorID == "" {
+		return "", errors.New("userID and actorID cannot be empty")
+	}
+	if ttl <= 0 {
+		return "", errors.New("ttl must be positive")
+	}
+
+	claims := jwt.MapClaims{
+		"user_id": userID,
+		"type":    "access",
+		"act":     map[string]string{"sub": actorID},
+		"exp":     time.Now().Add(ttl).Unix(),
+		"iat":     time.Now().Unix(),
+	}
+	return j.sign(claims)
+}
+
+// sign は現在の鍵でクレームに署名する
+func (j *JWTServiceImpl) sign(claims jwt.MapClaims) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(j.secretKey))
-	if err != nil {
-		return "", err
+	token.Header["kid"] = j.keyID
+	return token.SignedString([]byte(j.secretKey))
+}
+
+// verifyKey はトークンのkidに対応する検証用の鍵を返す
+// kidのないトークン（鍵のローテーションに対応する前に発行したもの）は現在の鍵で検証する
+func (j *JWTServiceImpl) verifyKey(token *jwt.Token) (interface{}, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return []byte(j.secretKey), nil
+	}
+	key, ok := j.verifyKeys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id: %s", kid)
 	}
-	return tokenString, nil
+	return []byte(key), nil
 }
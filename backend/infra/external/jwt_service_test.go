@@ -1,9 +1,12 @@
 package external
 
 import (
+	"stackies-backend/domain/model"
 	"stackies-backend/domain/service"
 	"testing"
+	"time"
 
+	"github.com/golang-jwt/jwt"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -42,6 +45,23 @@ func (m *MockJWTService) GenerateRefreshToken(userID string) (string, error) {
 	return "mock_jwt_refresh_token_" + userID, nil
 }
 
+// GenerateImpersonationToken はモックのなりすましトークンを返す
+func (m *MockJWTService) GenerateImpersonationToken(userID, actorID string, ttl time.Duration) (string, error) {
+	if userID == "" || actorID == "" {
+		return "", assert.AnError
+	}
+	return "mock_jwt_impersonation_token_" + userID, nil
+}
+
+// ParseToken はモックの検証を行う
+func (m *MockJWTService) ParseToken(token string) (*model.TokenClaims, error) {
+	userID, err := m.ValidateToken(token)
+	if err != nil {
+		return nil, err
+	}
+	return &model.TokenClaims{UserID: userID}, nil
+}
+
 func TestJWTServiceImpl_GenerateToken(t *testing.T) {
 	tests := []struct {
 		testName    string
@@ -156,3 +176,92 @@ func TestJWTServiceImpl_GenerateRefreshToken(t *testing.T) {
 		})
 	}
 }
+
+func TestJWTServiceImpl_KeyRotation(t *testing.T) {
+	oldService := NewJWTService("old-secret")
+	oldToken, err := oldService.GenerateToken("user_123")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		testName    string
+		service     service.JWTService
+		token       string
+		expectError bool
+	}{
+		{
+			testName: "ローテーション前の鍵で署名したトークンを検証できる",
+			service:  NewJWTService("new-secret", "old-secret"),
+			token:    oldToken,
+		},
+		{
+			testName:    "ローテーション前の鍵を外すと検証できない",
+			service:     NewJWTService("new-secret"),
+			token:       oldToken,
+			expectError: true,
+		},
+		{
+			testName:    "別の署名方式のトークンは検証しない",
+			service:     NewJWTService("old-secret"),
+			token:       "eyJhbGciOiJub25lIiwidHlwIjoiSldUIn0.eyJ1c2VyX2lkIjoidXNlcl8xMjMifQ.",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			userID, err := tt.service.ValidateToken(tt.token)
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.Empty(t, userID)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "user_123", userID)
+			}
+		})
+	}
+
+	// 新しいトークンは現在の鍵のkidで署名する
+	newService := NewJWTService("new-secret", "old-secret")
+	newToken, err := newService.GenerateRefreshToken("user_123")
+	assert.NoError(t, err)
+	token, _, err := new(jwt.Parser).ParseUnverified(newToken, jwt.MapClaims{})
+	assert.NoError(t, err)
+	assert.Equal(t, KeyID("new-secret"), token.Header["kid"])
+}
+
+func TestJWTServiceImpl_Impersonation(t *testing.T) {
+	svc := NewJWTService("secret")
+
+	token, err := svc.GenerateImpersonationToken("user_123", "admin_1", 15*time.Minute)
+	assert.NoError(t, err)
+
+	// actクレームに操作している管理者を含める
+	claims, err := svc.ParseToken(token)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "user_123", claims.UserID)
+		assert.Equal(t, "admin_1", claims.ActorID)
+		assert.True(t, claims.IsImpersonation())
+		assert.WithinDuration(t, time.Now().Add(15*time.Minute), claims.ExpiresAt, 2*time.Second)
+		assert.WithinDuration(t, time.Now(), claims.IssuedAt, 2*time.Second)
+	}
+
+	// なりすましのトークンはリフレッシュなどに使えない
+	userID, err := svc.ValidateToken(token)
+	assert.Error(t, err)
+	assert.Empty(t, userID)
+
+	// 通常のトークンはなりすましではない
+	accessToken, err := svc.GenerateToken("user_123")
+	assert.NoError(t, err)
+	claims, err = svc.ParseToken(accessToken)
+	if assert.NoError(t, err) {
+		assert.False(t, claims.IsImpersonation())
+	}
+
+	_, err = svc.GenerateImpersonationToken("user_123", "", time.Minute)
+	assert.Error(t, err)
+	_, err = svc.GenerateImpersonationToken("user_123", "admin_1", 0)
+	assert.Error(t, err)
+	_, err = NewJWTService("other").ParseToken(token)
+	assert.Error(t, err)
+}
@@ -0,0 +1,107 @@
+package external
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/service"
+	"strings"
+)
+
+// LocalBlobStore はローカルのファイルシステムに保存するBlobStoreの実装（開発環境・単一インスタンス向け）
+// データはバックエンドが返すため、URLは空を返す
+type LocalBlobStore struct {
+	dir string
+}
+
+// NewLocalBlobStore は指定したディレクトリに保存するBlobStoreを作成する
+// ディレクトリは最初の保存時に作成する
+func NewLocalBlobStore(dir string) (service.BlobStore, error) {
+	if dir == "" {
+		return nil, errors.New("blob store directory cannot be empty")
+	}
+	return &LocalBlobStore{dir: dir}, nil
+}
+
+// Put はデータを一時ファイルに書き込んでから置き換える（読み込み中のリクエストに途中のデータを返さない）
+// Content-Typeはキーの拡張子から判定するため、拡張子を含むキーを指定する
+func (s *LocalBlobStore) Put(ctx context.Context, key string, blob *model.Blob) error {
+	filename, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filename), 0o750); err != nil {
+		return fmt.Errorf("failed to create blob directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filename), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create blob: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(blob.Data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write blob: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write blob: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filename); err != nil {
+		return fmt.Errorf("failed to write blob: %w", err)
+	}
+	return nil
+}
+
+// Get はファイルを読み込む
+func (s *LocalBlobStore) Get(ctx context.Context, key string) (*model.Blob, error) {
+	filename, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, service.ErrBlobNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read blob: %w", err)
+	}
+
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return &model.Blob{ContentType: contentType, Data: data}, nil
+}
+
+// Delete はファイルを削除する
+func (s *LocalBlobStore) Delete(ctx context.Context, key string) error {
+	filename, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filename); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete blob: %w", err)
+	}
+	return nil
+}
+
+// URL はバックエンドがデータを返すため空を返す
+func (s *LocalBlobStore) URL(ctx context.Context, key string) (string, error) {
+	return "", nil
+}
+
+// path はキーに対応するファイルのパスを返す（保存先のディレクトリの外を指すキーは拒否する）
+func (s *LocalBlobStore) path(key string) (string, error) {
+	if key == "" || !filepath.IsLocal(filepath.FromSlash(key)) || strings.Contains(key, "\\") {
+		return "", fmt.Errorf("invalid blob key: %q", key)
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
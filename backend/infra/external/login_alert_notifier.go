@@ -0,0 +1,115 @@
+package external
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/smtp"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/service"
+	"strings"
+	"time"
+)
+
+// LogLoginAlertNotifierImpl は通知内容をログに出力するLoginAlertNotifierの実装（開発用）
+type LogLoginAlertNotifierImpl struct{}
+
+// NewLogLoginAlertNotifier は新しいログ出力版LoginAlertNotifierを作成する
+func NewLogLoginAlertNotifier() service.LoginAlertNotifier {
+	return &LogLoginAlertNotifierImpl{}
+}
+
+// Notify は通知内容をログに出力する
+func (n *LogLoginAlertNotifierImpl) Notify(ctx context.Context, alert *model.LoginAlert) error {
+	if alert == nil {
+		return errors.New("alert cannot be nil")
+	}
+
+	slog.InfoContext(ctx, "login alert",
+		slog.String("action", string(alert.Action)),
+		slog.String("subject_id", alert.UserID),
+		slog.String("risk_level", string(alert.Assessment.Level)),
+		slog.Int("risk_score", alert.Assessment.Score),
+		slog.Any("risk_signals", alert.Assessment.Signals),
+		slog.String("ip", alert.IPAddress),
+		slog.String("revoke_url", alert.RevokeURL),
+	)
+	return nil
+}
+
+// SMTPLoginAlertNotifierImpl はSMTPでメールを送信するLoginAlertNotifierの実装
+type SMTPLoginAlertNotifierImpl struct {
+	addr string
+	from string
+	auth smtp.Auth
+	send func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewSMTPLoginAlertNotifier は新しいSMTP版LoginAlertNotifierを作成する
+// usernameが空の場合は認証なしで送信する
+func NewSMTPLoginAlertNotifier(addr, from, username, password string) service.LoginAlertNotifier {
+	var auth smtp.Auth
+	if username != "" {
+		host := addr
+		if i := strings.LastIndex(addr, ":"); i >= 0 {
+			host = addr[:i]
+		}
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &SMTPLoginAlertNotifierImpl{
+		addr: addr,
+		from: from,
+		auth: auth,
+		send: smtp.SendMail,
+	}
+}
+
+// Notify はユーザーに不審なログインの通知メールを送信する
+func (n *SMTPLoginAlertNotifierImpl) Notify(ctx context.Context, alert *model.LoginAlert) error {
+	if alert == nil {
+		return errors.New("alert cannot be nil")
+	}
+	if alert.Email == "" {
+		return errors.New("alert email cannot be empty")
+	}
+
+	if err := n.send(n.addr, n.auth, n.from, []string{alert.Email}, buildLoginAlertMessage(n.from, alert)); err != nil {
+		return fmt.Errorf("failed to send login alert: %w", err)
+	}
+	return nil
+}
+
+// buildLoginAlertMessage は通知メールの本文を組み立てる
+func buildLoginAlertMessage(from string, alert *model.LoginAlert) []byte {
+	subject := "新しいログインがありました"
+	lead := "お使いのアカウントに、これまでと異なる環境からのログインがありました。"
+	if alert.Action == model.LoginAlertActionUrgentEmail {
+		subject = "不審なログインを検出しました"
+		lead = "お使いのアカウントに不審なログインを検出しました。ご本人の操作であるか確認してください。"
+	}
+
+	location := "不明"
+	if alert.Location != nil {
+		location = strings.TrimSpace(alert.Location.City + " " + alert.Location.Country)
+	}
+
+	var body bytes.Buffer
+	fmt.Fprintf(&body, "From: %s\r\n", from)
+	fmt.Fprintf(&body, "To: %s\r\n", alert.Email)
+	fmt.Fprintf(&body, "Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", subject))
+	body.WriteString("MIME-Version: 1.0\r\n")
+	body.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	body.WriteString("\r\n")
+	fmt.Fprintf(&body, "%s\r\n\r\n", lead)
+	fmt.Fprintf(&body, "日時: %s\r\n", alert.OccurredAt.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&body, "IPアドレス: %s\r\n", alert.IPAddress)
+	fmt.Fprintf(&body, "場所: %s\r\n", location)
+	fmt.Fprintf(&body, "ブラウザ: %s\r\n\r\n", alert.UserAgent)
+	fmt.Fprintf(&body, "心当たりがない場合は、次のリンクからすべてのセッションを無効化してください。\r\n%s\r\n", alert.RevokeURL)
+
+	return body.Bytes()
+}
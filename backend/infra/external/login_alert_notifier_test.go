@@ -0,0 +1,74 @@
+package external
+
+import (
+	"context"
+	"errors"
+	"net/smtp"
+	"stackies-backend/domain/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSMTPLoginAlertNotifierImpl_Notify(t *testing.T) {
+	alert := &model.LoginAlert{
+		Action:     model.LoginAlertActionUrgentEmail,
+		UserID:     "user_1",
+		Email:      "user@example.com",
+		Assessment: model.NewRiskAssessment([]model.RiskSignal{model.RiskSignalImpossibleTravel}),
+		IPAddress:  "198.51.100.7",
+		UserAgent:  "Mozilla/5.0",
+		Location:   &model.GeoLocation{Country: "US", City: "New York"},
+		OccurredAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		RevokeURL:  "http://localhost:5173/security/not-me?token=abc",
+	}
+
+	t.Run("通知メールを送信", func(t *testing.T) {
+		notifier := NewSMTPLoginAlertNotifier("smtp.example.com:587", "security@example.com", "user", "pass").(*SMTPLoginAlertNotifierImpl)
+		var sentTo []string
+		var sentMsg string
+		notifier.send = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+			assert.Equal(t, "smtp.example.com:587", addr)
+			assert.NotNil(t, a)
+			assert.Equal(t, "security@example.com", from)
+			sentTo = to
+			sentMsg = string(msg)
+			return nil
+		}
+
+		assert.NoError(t, notifier.Notify(context.Background(), alert))
+		assert.Equal(t, []string{"user@example.com"}, sentTo)
+		assert.Contains(t, sentMsg, "To: user@example.com\r\n")
+		assert.Contains(t, sentMsg, "Subject: =?UTF-8?b?")
+		assert.Contains(t, sentMsg, "198.51.100.7")
+		assert.Contains(t, sentMsg, "New York US")
+		assert.Contains(t, sentMsg, alert.RevokeURL)
+	})
+
+	t.Run("送信失敗はエラー", func(t *testing.T) {
+		notifier := NewSMTPLoginAlertNotifier("smtp.example.com:25", "security@example.com", "", "").(*SMTPLoginAlertNotifierImpl)
+		assert.Nil(t, notifier.auth)
+		notifier.send = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+			return errors.New("connection refused")
+		}
+
+		assert.Error(t, notifier.Notify(context.Background(), alert))
+	})
+
+	t.Run("宛先がない場合はエラー", func(t *testing.T) {
+		notifier := NewSMTPLoginAlertNotifier("smtp.example.com:25", "security@example.com", "", "")
+		assert.Error(t, notifier.Notify(context.Background(), &model.LoginAlert{Assessment: alert.Assessment}))
+	})
+}
+
+func TestLogLoginAlertNotifierImpl_Notify(t *testing.T) {
+	notifier := NewLogLoginAlertNotifier()
+
+	assert.NoError(t, notifier.Notify(context.Background(), &model.LoginAlert{
+		Action:     model.LoginAlertActionEmail,
+		UserID:     "user_1",
+		Assessment: model.NewRiskAssessment([]model.RiskSignal{model.RiskSignalNewDevice}),
+	}))
+	assert.Error(t, notifier.Notify(context.Background(), nil))
+}
@@ -0,0 +1,157 @@
+package external
+
+import (
+	"context"
+	"errors"
+	"math"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/service"
+	"sync"
+	"time"
+)
+
+// sweepInterval は期限切れエントリを掃除する間隔
+const sweepInterval = time.Minute
+
+type (
+	// MemoryRateLimiterImpl はプロセス内メモリで動作するRateLimiterの実装
+	// 単一インスタンス構成向け。複数インスタンスで共有する場合はRedis実装を使用する
+	MemoryRateLimiterImpl struct {
+		buckets   map[string]*tokenBucket
+		windows   map[string]*slidingWindow
+		lastSweep time.Time
+		now       func() time.Time
+		mutex     sync.Mutex
+	}
+
+	// tokenBucket はトークンバケットの状態を表す
+	tokenBucket struct {
+		tokens    float64
+		updatedAt time.Time
+		expiresAt time.Time
+	}
+
+	// slidingWindow はスライディングウィンドウのリクエスト時刻ログを表す
+	slidingWindow struct {
+		hits      []time.Time
+		expiresAt time.Time
+	}
+)
+
+// NewMemoryRateLimiter は新しいメモリ版RateLimiterを作成する
+func NewMemoryRateLimiter() service.RateLimiter {
+	return &MemoryRateLimiterImpl{
+		buckets: make(map[string]*tokenBucket),
+		windows: make(map[string]*slidingWindow),
+		now:     time.Now,
+	}
+}
+
+// Allow はリクエストを許可するかどうかを判定する
+func (m *MemoryRateLimiterImpl) Allow(ctx context.Context, key string, rule model.RateLimitRule) (*model.RateLimitResult, error) {
+	if key == "" {
+		return nil, errors.New("key cannot be empty")
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := m.now()
+	m.sweep(now)
+
+	switch rule.Strategy {
+	case model.RateLimitStrategyTokenBucket:
+		return m.allowTokenBucket(key, rule, now), nil
+	case model.RateLimitStrategySlidingWindow:
+		return m.allowSlidingWindow(key, rule, now), nil
+	default:
+		return nil, errors.New("unknown rate limit strategy")
+	}
+}
+
+// allowTokenBucket はトークンバケット方式で判定する
+func (m *MemoryRateLimiterImpl) allowTokenBucket(key string, rule model.RateLimitRule, now time.Time) *model.RateLimitResult {
+	capacity := float64(rule.Limit)
+	ratePerSec := capacity / rule.Window.Seconds()
+
+	bucket, exists := m.buckets[key]
+	if !exists {
+		bucket = &tokenBucket{tokens: capacity, updatedAt: now}
+		m.buckets[key] = bucket
+	}
+
+	// 経過時間分のトークンを補充
+	elapsed := now.Sub(bucket.updatedAt).Seconds()
+	bucket.tokens = math.Min(capacity, bucket.tokens+elapsed*ratePerSec)
+	bucket.updatedAt = now
+	bucket.expiresAt = now.Add(rule.Window)
+
+	result := &model.RateLimitResult{Limit: rule.Limit}
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsToDuration((1 - bucket.tokens) / ratePerSec)
+	}
+	result.Remaining = int(math.Floor(bucket.tokens))
+	result.ResetAfter = secondsToDuration((capacity - bucket.tokens) / ratePerSec)
+
+	return result
+}
+
+// allowSlidingWindow はスライディングウィンドウ方式で判定する
+func (m *MemoryRateLimiterImpl) allowSlidingWindow(key string, rule model.RateLimitRule, now time.Time) *model.RateLimitResult {
+	window, exists := m.windows[key]
+	if !exists {
+		window = &slidingWindow{}
+		m.windows[key] = window
+	}
+
+	// ウィンドウ外のリクエストを除外
+	threshold := now.Add(-rule.Window)
+	kept := window.hits[:0]
+	for _, hit := range window.hits {
+		if hit.After(threshold) {
+			kept = append(kept, hit)
+		}
+	}
+	window.hits = kept
+
+	result := &model.RateLimitResult{Limit: rule.Limit}
+	if len(window.hits) < rule.Limit {
+		window.hits = append(window.hits, now)
+		result.Allowed = true
+	} else {
+		result.RetryAfter = window.hits[0].Add(rule.Window).Sub(now)
+	}
+	window.expiresAt = now.Add(rule.Window)
+
+	result.Remaining = rule.Limit - len(window.hits)
+	result.ResetAfter = window.hits[0].Add(rule.Window).Sub(now)
+
+	return result
+}
+
+// sweep は期限切れのエントリを削除する
+func (m *MemoryRateLimiterImpl) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < sweepInterval {
+		return
+	}
+	m.lastSweep = now
+
+	for key, bucket := range m.buckets {
+		if now.After(bucket.expiresAt) {
+			delete(m.buckets, key)
+		}
+	}
+	for key, window := range m.windows {
+		if now.After(window.expiresAt) {
+			delete(m.windows, key)
+		}
+	}
+}
+
+// secondsToDuration は秒数をDurationに変換する
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(math.Ceil(seconds * float64(time.Second)))
+}
@@ -0,0 +1,83 @@
+package external
+
+import (
+	"context"
+	"stackies-backend/domain/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryRateLimiterImpl_TokenBucket(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := NewMemoryRateLimiter().(*MemoryRateLimiterImpl)
+	limiter.now = func() time.Time { return now }
+	rule := model.RateLimitRule{Strategy: model.RateLimitStrategyTokenBucket, Limit: 2, Window: 10 * time.Second}
+
+	// 容量分はバーストで許可される
+	for i := 0; i < 2; i++ {
+		result, err := limiter.Allow(context.Background(), "ip:127.0.0.1", rule)
+		assert.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 1-i, result.Remaining)
+	}
+
+	// 容量を超えると拒否される
+	result, err := limiter.Allow(context.Background(), "ip:127.0.0.1", rule)
+	assert.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+	assert.Equal(t, 5*time.Second, result.RetryAfter)
+
+	// 別のキーは影響を受けない
+	result, err = limiter.Allow(context.Background(), "ip:127.0.0.2", rule)
+	assert.NoError(t, err)
+	assert.True(t, result.Allowed)
+
+	// 時間経過でトークンが補充される
+	now = now.Add(5 * time.Second)
+	result, err = limiter.Allow(context.Background(), "ip:127.0.0.1", rule)
+	assert.NoError(t, err)
+	assert.True(t, result.Allowed)
+}
+
+func TestMemoryRateLimiterImpl_SlidingWindow(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := NewMemoryRateLimiter().(*MemoryRateLimiterImpl)
+	limiter.now = func() time.Time { return now }
+	rule := model.RateLimitRule{Strategy: model.RateLimitStrategySlidingWindow, Limit: 2, Window: time.Minute}
+
+	result, err := limiter.Allow(context.Background(), "user:user_123", rule)
+	assert.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 1, result.Remaining)
+
+	now = now.Add(30 * time.Second)
+	result, err = limiter.Allow(context.Background(), "user:user_123", rule)
+	assert.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+
+	// 上限に達すると、最も古いリクエストがウィンドウ外に出るまで拒否される
+	now = now.Add(10 * time.Second)
+	result, err = limiter.Allow(context.Background(), "user:user_123", rule)
+	assert.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 20*time.Second, result.RetryAfter)
+
+	now = now.Add(20 * time.Second)
+	result, err = limiter.Allow(context.Background(), "user:user_123", rule)
+	assert.NoError(t, err)
+	assert.True(t, result.Allowed)
+}
+
+func TestMemoryRateLimiterImpl_Allow_Errors(t *testing.T) {
+	limiter := NewMemoryRateLimiter()
+
+	_, err := limiter.Allow(context.Background(), "", model.RateLimitRule{Strategy: model.RateLimitStrategyTokenBucket, Limit: 1, Window: time.Second})
+	assert.Error(t, err)
+
+	_, err = limiter.Allow(context.Background(), "key", model.RateLimitRule{Strategy: "unknown", Limit: 1, Window: time.Second})
+	assert.Error(t, err)
+}
@@ -0,0 +1,179 @@
+package external
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/service"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisRateLimitKeyPrefix はRedis上のキーのプレフィックス
+const redisRateLimitKeyPrefix = "ratelimit:"
+
+var (
+	// tokenBucketScript はトークンバケットの補充と消費をアトミックに行う
+	tokenBucketScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local window_ms = tonumber(ARGV[2])
+local now_ms = tonumber(ARGV[3])
+local rate = capacity / window_ms
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+  tokens = capacity
+  ts = now_ms
+end
+
+tokens = math.min(capacity, tokens + math.max(0, now_ms - ts) * rate)
+local allowed = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(now_ms))
+redis.call('PEXPIRE', KEYS[1], window_ms)
+return {allowed, tostring(tokens)}
+`)
+
+	// slidingWindowScript はウィンドウ外のリクエストを除外し、上限未満であれば記録する
+	slidingWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window_ms = tonumber(ARGV[2])
+local now_ms = tonumber(ARGV[3])
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now_ms - window_ms)
+local count = redis.call('ZCARD', KEYS[1])
+local allowed = 0
+if count < limit then
+  redis.call('ZADD', KEYS[1], now_ms, ARGV[4])
+  count = count + 1
+  allowed = 1
+end
+redis.call('PEXPIRE', KEYS[1], window_ms)
+
+local oldest_ms = now_ms
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+if #oldest > 0 then
+  oldest_ms = tonumber(oldest[2])
+end
+return {allowed, count, tostring(oldest_ms)}
+`)
+)
+
+// RedisRateLimiterImpl はRedisを使用したRateLimiterの実装
+// 複数インスタンス間でカウンタを共有できる
+type RedisRateLimiterImpl struct {
+	client redis.UniversalClient
+	now    func() time.Time
+}
+
+// NewRedisRateLimiter は新しいRedis版RateLimiterを作成する
+func NewRedisRateLimiter(client redis.UniversalClient) service.RateLimiter {
+	return &RedisRateLimiterImpl{
+		client: client,
+		now:    time.Now,
+	}
+}
+
+// Allow はリクエストを許可するかどうかを判定する
+func (r *RedisRateLimiterImpl) Allow(ctx context.Context, key string, rule model.RateLimitRule) (*model.RateLimitResult, error) {
+	if key == "" {
+		return nil, errors.New("key cannot be empty")
+	}
+
+	now := r.now()
+	nowMs := now.UnixMilli()
+	windowMs := rule.Window.Milliseconds()
+	redisKey := redisRateLimitKeyPrefix + string(rule.Strategy) + ":" + key
+
+	switch rule.Strategy {
+	case model.RateLimitStrategyTokenBucket:
+		values, err := tokenBucketScript.Run(ctx, r.client, []string{redisKey}, rule.Limit, windowMs, nowMs).Slice()
+		if err != nil {
+			return nil, fmt.Errorf("failed to run token bucket script: %w", err)
+		}
+		allowed, err := scriptInt(values, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse token bucket result: %w", err)
+		}
+		if len(values) < 2 {
+			return nil, fmt.Errorf("failed to parse token bucket state: unexpected reply length %d", len(values))
+		}
+		tokens, err := strconv.ParseFloat(fmt.Sprint(values[1]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse token bucket state: %w", err)
+		}
+
+		capacity := float64(rule.Limit)
+		ratePerSec := capacity / rule.Window.Seconds()
+		result := &model.RateLimitResult{
+			Allowed:    allowed == 1,
+			Limit:      rule.Limit,
+			Remaining:  int(math.Floor(tokens)),
+			ResetAfter: secondsToDuration((capacity - tokens) / ratePerSec),
+		}
+		if !result.Allowed {
+			result.RetryAfter = secondsToDuration((1 - tokens) / ratePerSec)
+		}
+		return result, nil
+
+	case model.RateLimitStrategySlidingWindow:
+		member := strconv.FormatInt(now.UnixNano(), 10)
+		values, err := slidingWindowScript.Run(ctx, r.client, []string{redisKey}, rule.Limit, windowMs, nowMs, member).Slice()
+		if err != nil {
+			return nil, fmt.Errorf("failed to run sliding window script: %w", err)
+		}
+		allowed, err := scriptInt(values, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse sliding window result: %w", err)
+		}
+		count, err := scriptInt(values, 1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse sliding window state: %w", err)
+		}
+		if len(values) < 3 {
+			return nil, fmt.Errorf("failed to parse sliding window state: unexpected reply length %d", len(values))
+		}
+		oldestMs, err := strconv.ParseInt(fmt.Sprint(values[2]), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse sliding window state: %w", err)
+		}
+
+		resetAfter := time.Duration(oldestMs+windowMs-nowMs) * time.Millisecond
+		result := &model.RateLimitResult{
+			Allowed:    allowed == 1,
+			Limit:      rule.Limit,
+			Remaining:  rule.Limit - int(count),
+			ResetAfter: resetAfter,
+		}
+		if !result.Allowed {
+			result.RetryAfter = resetAfter
+		}
+		return result, nil
+
+	default:
+		return nil, errors.New("unknown rate limit strategy")
+	}
+}
+
+// scriptInt はLuaスクリプトの戻り値から整数の要素を取り出す
+// 想定外の応答（要素の不足・整数以外）ではパニックせずにエラーを返す
+func scriptInt(values []any, index int) (int64, error) {
+	if index >= len(values) {
+		return 0, fmt.Errorf("unexpected reply length %d", len(values))
+	}
+	value, ok := values[index].(int64)
+	if !ok {
+		return 0, fmt.Errorf("unexpected reply type %T at index %d", values[index], index)
+	}
+	return value, nil
+}
@@ -0,0 +1,100 @@
+package external
+
+import (
+	"context"
+	"stackies-backend/domain/model"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRedisRateLimiter(t *testing.T, now *time.Time) *RedisRateLimiterImpl {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	limiter := NewRedisRateLimiter(client).(*RedisRateLimiterImpl)
+	limiter.now = func() time.Time { return *now }
+	return limiter
+}
+
+func TestRedisRateLimiterImpl_TokenBucket(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := newTestRedisRateLimiter(t, &now)
+	rule := model.RateLimitRule{Strategy: model.RateLimitStrategyTokenBucket, Limit: 2, Window: 10 * time.Second}
+
+	for i := 0; i < 2; i++ {
+		result, err := limiter.Allow(context.Background(), "ip:127.0.0.1", rule)
+		assert.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 1-i, result.Remaining)
+	}
+
+	result, err := limiter.Allow(context.Background(), "ip:127.0.0.1", rule)
+	assert.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 5*time.Second, result.RetryAfter)
+
+	now = now.Add(5 * time.Second)
+	result, err = limiter.Allow(context.Background(), "ip:127.0.0.1", rule)
+	assert.NoError(t, err)
+	assert.True(t, result.Allowed)
+}
+
+func TestRedisRateLimiterImpl_SlidingWindow(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := newTestRedisRateLimiter(t, &now)
+	rule := model.RateLimitRule{Strategy: model.RateLimitStrategySlidingWindow, Limit: 2, Window: time.Minute}
+
+	result, err := limiter.Allow(context.Background(), "client:web", rule)
+	assert.NoError(t, err)
+	assert.True(t, result.Allowed)
+
+	now = now.Add(30 * time.Second)
+	result, err = limiter.Allow(context.Background(), "client:web", rule)
+	assert.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+
+	now = now.Add(10 * time.Second)
+	result, err = limiter.Allow(context.Background(), "client:web", rule)
+	assert.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 20*time.Second, result.RetryAfter)
+
+	now = now.Add(20 * time.Second)
+	result, err = limiter.Allow(context.Background(), "client:web", rule)
+	assert.NoError(t, err)
+	assert.True(t, result.Allowed)
+}
+
+func TestScriptInt(t *testing.T) {
+	tests := []struct {
+		testName    string
+		values      []any
+		index       int
+		expected    int64
+		expectError bool
+	}{
+		{testName: "整数の要素", values: []any{int64(1), int64(3)}, index: 1, expected: 3},
+		{testName: "要素が不足している", values: []any{int64(1)}, index: 1, expectError: true},
+		{testName: "整数以外の要素", values: []any{"1"}, index: 0, expectError: true},
+		{testName: "nilの要素", values: []any{nil}, index: 0, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			value, err := scriptInt(tt.values, tt.index)
+
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, value)
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package external
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/service"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3BlobStoreOptions はS3互換ストレージの接続設定を表す
+type S3BlobStoreOptions struct {
+	// Endpoint はストレージのURL（例: https://s3.ap-northeast-1.amazonaws.com, http://localhost:9000）
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	// PathStyle はバケット名をホスト名ではなくパスに含める（MinIOなど）
+	PathStyle bool
+	// PublicBaseURL を指定した場合はCDNなど公開URLにリダイレクトし、指定しない場合は署名付きURLを発行する
+	PublicBaseURL string
+	// URLExpiry は署名付きURLの有効期限
+	URLExpiry time.Duration
+	// Transport は再試行・サーキットブレーカーを適用したトランスポート（nilの場合は標準）
+	Transport http.RoundTripper
+}
+
+// S3BlobStore はS3互換のオブジェクトストレージに保存するBlobStoreの実装
+type S3BlobStore struct {
+	client        *minio.Client
+	bucket        string
+	publicBaseURL string
+	urlExpiry     time.Duration
+}
+
+// NewS3BlobStore は新しいS3BlobStoreを作成する
+func NewS3BlobStore(opts S3BlobStoreOptions) (service.BlobStore, error) {
+	if opts.Bucket == "" {
+		return nil, errors.New("s3 bucket cannot be empty")
+	}
+	endpoint, err := url.Parse(opts.Endpoint)
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid s3 endpoint: %q", opts.Endpoint)
+	}
+
+	lookup := minio.BucketLookupAuto
+	if opts.PathStyle {
+		lookup = minio.BucketLookupPath
+	}
+	minioOpts := &minio.Options{
+		Creds:        credentials.NewStaticV4(opts.AccessKeyID, opts.SecretAccessKey, ""),
+		Secure:       endpoint.Scheme == "https",
+		Region:       opts.Region,
+		BucketLookup: lookup,
+	}
+	if opts.Transport != nil {
+		// 再試行はTransportで行うため、クライアントでは再試行しない
+		minioOpts.Transport = opts.Transport
+		minioOpts.MaxRetries = 1
+	}
+
+	client, err := minio.New(endpoint.Host, minioOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create s3 client: %w", err)
+	}
+
+	return &S3BlobStore{
+		client:        client,
+		bucket:        opts.Bucket,
+		publicBaseURL: strings.TrimSuffix(opts.PublicBaseURL, "/"),
+		urlExpiry:     opts.URLExpiry,
+	}, nil
+}
+
+// Put はオブジェクトを保存する
+func (s *S3BlobStore) Put(ctx context.Context, key string, blob *model.Blob) error {
+	_, err := s.client.PutObject(ctx, s.bucket, key, bytes.NewReader(blob.Data), int64(len(blob.Data)), minio.PutObjectOptions{
+		ContentType: blob.ContentType,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put s3 object: %w", err)
+	}
+	return nil
+}
+
+// Get はオブジェクトを取得する
+func (s *S3BlobStore) Get(ctx context.Context, key string) (*model.Blob, error) {
+	object, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, s.getError(err)
+	}
+	defer object.Close()
+
+	data, err := io.ReadAll(object)
+	if err != nil {
+		return nil, s.getError(err)
+	}
+	info, err := object.Stat()
+	if err != nil {
+		return nil, s.getError(err)
+	}
+
+	return &model.Blob{ContentType: info.ContentType, Data: data}, nil
+}
+
+// Delete はオブジェクトを削除する（S3は存在しないオブジェクトの削除も成功とする）
+func (s *S3BlobStore) Delete(ctx context.Context, key string) error {
+	if err := s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("failed to delete s3 object: %w", err)
+	}
+	return nil
+}
+
+// URL は公開URL、または有効期限付きの署名付きURLを返す
+func (s *S3BlobStore) URL(ctx context.Context, key string) (string, error) {
+	if s.publicBaseURL != "" {
+		return s.publicBaseURL + "/" + key, nil
+	}
+
+	signed, err := s.client.PresignedGetObject(ctx, s.bucket, key, s.urlExpiry, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to presign s3 object: %w", err)
+	}
+	return signed.String(), nil
+}
+
+// getError は取得時のエラーを変換する（オブジェクトが存在しない場合はErrBlobNotFound）
+func (s *S3BlobStore) getError(err error) error {
+	if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+		return service.ErrBlobNotFound
+	}
+	return fmt.Errorf("failed to get s3 object: %w", err)
+}
@@ -0,0 +1,94 @@
+package external
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/netip"
+	"stackies-backend/core/metrics"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/service"
+	"syscall"
+	"time"
+)
+
+// webhookResponseLimit は接続を再利用するために読み捨てるレスポンスボディの最大サイズ
+const webhookResponseLimit = 64 << 10
+
+// ErrWebhookTargetNotAllowed は送信先が内部ネットワークのアドレスに解決された場合のエラー
+var ErrWebhookTargetNotAllowed = errors.New("webhook target resolves to a non-public address")
+
+// WebhookDialControl はWebhookの送信先がループバック・プライベート・リンクローカルなどのアドレスであれば接続を拒否する
+// 名前解決した後のアドレスを検証するため、DNSの応答を書き換えて内部ネットワークに接続させる攻撃も防ぐ
+func WebhookDialControl(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrWebhookTargetNotAllowed, address)
+	}
+	if !model.IsPublicWebhookAddr(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrWebhookTargetNotAllowed, addrPort.Addr())
+	}
+	return nil
+}
+
+// HTTPWebhookSenderImpl はHMACで署名したJSONをPOSTするWebhookSenderの実装
+// 失敗の続く購読先が他の購読先への送信を妨げないよう、サーキットブレーカーは購読ごとに持つ
+type HTTPWebhookSenderImpl struct {
+	clients *HTTPClientFactory
+	metrics *metrics.Metrics
+	now     func() time.Time
+}
+
+// NewHTTPWebhookSender は新しいHTTPWebhookSenderを作成する
+func NewHTTPWebhookSender(clients *HTTPClientFactory, m *metrics.Metrics) service.WebhookSender {
+	return &HTTPWebhookSenderImpl{
+		clients: clients,
+		metrics: m,
+		now:     time.Now,
+	}
+}
+
+// Send は配信のペイロードを署名して購読先のURLにPOSTし、レスポンスのステータスコードを返す
+func (s *HTTPWebhookSenderImpl) Send(ctx context.Context, subscription *model.WebhookSubscription, delivery *model.WebhookDelivery) (int, error) {
+	if subscription == nil || delivery == nil {
+		return 0, errors.New("subscription and delivery cannot be nil")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Stackies-Webhook/1.0")
+	req.Header.Set(model.WebhookEventHeader, string(delivery.EventType))
+	req.Header.Set(model.WebhookDeliveryHeader, delivery.ID)
+	req.Header.Set(model.WebhookSignatureHeader, model.SignWebhookPayload(subscription.Secret, s.now(), delivery.Payload))
+
+	start := time.Now()
+	resp, err := s.clients.Client(webhookClientName(subscription.ID)).Do(req)
+	if err != nil {
+		s.metrics.ObserveOutbound("webhook", "deliver", time.Since(start), err)
+		return 0, fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, webhookResponseLimit))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		err = fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+	s.metrics.ObserveOutbound("webhook", "deliver", time.Since(start), err)
+	return resp.StatusCode, nil
+}
+
+// Forget は削除した購読のHTTPクライアントとサーキットブレーカーを破棄する
+func (s *HTTPWebhookSenderImpl) Forget(subscriptionID string) {
+	s.clients.Remove(webhookClientName(subscriptionID))
+}
+
+// webhookClientName は購読ごとのHTTPクライアントの名前を返す
+func webhookClientName(subscriptionID string) string {
+	return "webhook:" + subscriptionID
+}
@@ -0,0 +1,122 @@
+package external
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"stackies-backend/domain/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPWebhookSenderImpl_Send(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	payload := []byte(`{"id":"evt_1"}`)
+
+	tests := []struct {
+		testName   string
+		status     int
+		wantStatus int
+	}{
+		{
+			testName:   "署名したペイロードを送信し、ステータスコードを返す",
+			status:     http.StatusNoContent,
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			testName:   "送信先のエラーもステータスコードとして返す",
+			status:     http.StatusInternalServerError,
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			var received *http.Request
+			var body []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				received = r
+				body, _ = io.ReadAll(r.Body)
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			sender := NewHTTPWebhookSender(NewHTTPClientFactory(HTTPClientOptions{Timeout: time.Second}), nil).(*HTTPWebhookSenderImpl)
+			sender.now = func() time.Time { return now }
+
+			subscription := &model.WebhookSubscription{ID: "wh_1", URL: server.URL, Secret: "0123456789abcdef"}
+			delivery := &model.WebhookDelivery{ID: "del_1", EventType: model.DomainEventUserRegistered, Payload: payload}
+			status, err := sender.Send(context.Background(), subscription, delivery)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, status)
+			assert.Equal(t, http.MethodPost, received.Method)
+			assert.Equal(t, payload, body)
+			assert.Equal(t, "application/json", received.Header.Get("Content-Type"))
+			assert.Equal(t, "user.registered", received.Header.Get(model.WebhookEventHeader))
+			assert.Equal(t, "del_1", received.Header.Get(model.WebhookDeliveryHeader))
+			assert.Equal(t, model.SignWebhookPayload("0123456789abcdef", now, payload), received.Header.Get(model.WebhookSignatureHeader))
+		})
+	}
+}
+
+func TestHTTPWebhookSenderImpl_Send_BlocksNonPublicAddress(t *testing.T) {
+	received := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = true
+	}))
+	defer server.Close()
+
+	// 接続時にループバックアドレスを拒否する
+	factory := NewHTTPClientFactory(HTTPClientOptions{Timeout: time.Second, DialControl: WebhookDialControl})
+	sender := NewHTTPWebhookSender(factory, nil)
+	status, err := sender.Send(context.Background(),
+		&model.WebhookSubscription{ID: "wh_1", URL: server.URL, Secret: "0123456789abcdef"},
+		&model.WebhookDelivery{ID: "del_1", Payload: []byte(`{}`)})
+
+	assert.ErrorIs(t, err, ErrWebhookTargetNotAllowed)
+	assert.Zero(t, status)
+	assert.False(t, received)
+}
+
+func TestWebhookDialControl(t *testing.T) {
+	tests := []struct {
+		testName string
+		address  string
+		wantErr  bool
+	}{
+		{testName: "グローバルなアドレス", address: "203.0.113.10:443"},
+		{testName: "ループバック", address: "127.0.0.1:443", wantErr: true},
+		{testName: "メタデータのリンクローカルアドレス", address: "169.254.169.254:80", wantErr: true},
+		{testName: "RFC1918のアドレス", address: "192.168.1.1:443", wantErr: true},
+		{testName: "IPv6のループバック", address: "[::1]:443", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			err := WebhookDialControl("tcp", tt.address, nil)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrWebhookTargetNotAllowed)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestHTTPWebhookSenderImpl_Send_ConnectionError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	sender := NewHTTPWebhookSender(NewHTTPClientFactory(HTTPClientOptions{Timeout: time.Second}), nil)
+	status, err := sender.Send(context.Background(),
+		&model.WebhookSubscription{ID: "wh_1", URL: server.URL, Secret: "0123456789abcdef"},
+		&model.WebhookDelivery{ID: "del_1", Payload: []byte(`{}`)})
+
+	assert.Error(t, err)
+	assert.Zero(t, status)
+}
@@ -0,0 +1,117 @@
+package persistence
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/repository"
+	"sync"
+)
+
+// AuditRepositoryImpl はAuditRepository interfaceの実装
+// TODO: 実際のデータベース統合時にこのin-memory実装を置き換える
+type AuditRepositoryImpl struct {
+	events []*model.AuditEvent
+	index  map[string]int
+	mutex  sync.RWMutex
+}
+
+// NewAuditRepository は新しいAuditRepositoryを作成する
+func NewAuditRepository() repository.AuditRepository {
+	return &AuditRepositoryImpl{
+		index: make(map[string]int),
+	}
+}
+
+// Append は監査イベントを追記する。IDが未設定の場合は採番する
+func (r *AuditRepositoryImpl) Append(ctx context.Context, event *model.AuditEvent) error {
+	_, span := tracer.Start(ctx, "AuditRepository.Append")
+	defer span.End()
+
+	if event == nil {
+		return errors.New("event cannot be nil")
+	}
+
+	if event.ID == "" {
+		bytes := make([]byte, 16)
+		if _, err := rand.Read(bytes); err != nil {
+			return err
+		}
+		event.ID = hex.EncodeToString(bytes)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.index[event.ID]; exists {
+		return errors.New("audit event already exists")
+	}
+
+	// 呼び出し元での変更が履歴に影響しないようにコピーを保存する
+	stored := *event
+	r.index[stored.ID] = len(r.events)
+	r.events = append(r.events, &stored)
+	return nil
+}
+
+// Query は検索条件に一致する監査イベントを新しい順に返す
+func (r *AuditRepositoryImpl) Query(ctx context.Context, filter *model.AuditEventFilter) (*model.AuditEventPage, error) {
+	_, span := tracer.Start(ctx, "AuditRepository.Query")
+	defer span.End()
+
+	if filter == nil {
+		filter = &model.AuditEventFilter{}
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	start := len(r.events) - 1
+	if filter.Cursor != "" {
+		position, exists := r.index[filter.Cursor]
+		if !exists {
+			return nil, repository.ErrInvalidCursor
+		}
+		start = position - 1
+	}
+
+	size := filter.PageSize()
+	page := &model.AuditEventPage{Events: make([]*model.AuditEvent, 0, size)}
+	for i := start; i >= 0; i-- {
+		event := r.events[i]
+		if !filter.Matches(event) {
+			continue
+		}
+		if len(page.Events) == size {
+			page.NextCursor = page.Events[size-1].ID
+			break
+		}
+		copied := *event
+		page.Events = append(page.Events, &copied)
+	}
+
+	return page, nil
+}
+
+// Pseudonymize はユーザーに関係するイベントのユーザーIDを仮名に置き換え、個人を識別する情報を削除する
+func (r *AuditRepositoryImpl) Pseudonymize(ctx context.Context, userID, pseudonym string) (int, error) {
+	_, span := tracer.Start(ctx, "AuditRepository.Pseudonymize")
+	defer span.End()
+
+	if userID == "" || pseudonym == "" {
+		return 0, errors.New("userID and pseudonym cannot be empty")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	count := 0
+	for _, event := range r.events {
+		if event.Pseudonymize(userID, pseudonym) {
+			count++
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,126 @@
+package persistence
+
+import (
+	"context"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditRepositoryImpl_Append(t *testing.T) {
+	repo := NewAuditRepository()
+
+	event := &model.AuditEvent{Type: model.AuditEventLogin, Outcome: model.AuditOutcomeSuccess, ActorID: "user_123"}
+	assert.NoError(t, repo.Append(context.Background(), event))
+	assert.NotEmpty(t, event.ID)
+
+	// 同じIDの再追記はできない
+	assert.Error(t, repo.Append(context.Background(), event))
+	assert.Error(t, repo.Append(context.Background(), nil))
+}
+
+func TestAuditRepositoryImpl_Query(t *testing.T) {
+	repo := NewAuditRepository()
+	for _, event := range []*model.AuditEvent{
+		{ID: "1", Type: model.AuditEventLogin, Outcome: model.AuditOutcomeSuccess, ActorID: "user_1", SubjectID: "user_1"},
+		{ID: "2", Type: model.AuditEventLogin, Outcome: model.AuditOutcomeFailure},
+		{ID: "3", Type: model.AuditEventRefresh, Outcome: model.AuditOutcomeSuccess, ActorID: "user_1", SubjectID: "user_1"},
+		{ID: "4", Type: model.AuditEventRoleChange, Outcome: model.AuditOutcomeSuccess, ActorID: "admin", SubjectID: "user_1"},
+		{ID: "5", Type: model.AuditEventLogin, Outcome: model.AuditOutcomeSuccess, ActorID: "user_2", SubjectID: "user_2"},
+	} {
+		assert.NoError(t, repo.Append(context.Background(), event))
+	}
+
+	tests := []struct {
+		testName       string
+		filter         *model.AuditEventFilter
+		expectedIDs    []string
+		expectedCursor string
+		expectError    error
+	}{
+		{
+			testName:    "ユーザーの履歴を新しい順に取得",
+			filter:      &model.AuditEventFilter{UserID: "user_1"},
+			expectedIDs: []string{"4", "3", "1"},
+		},
+		{
+			testName:       "ページング",
+			filter:         &model.AuditEventFilter{UserID: "user_1", Limit: 2},
+			expectedIDs:    []string{"4", "3"},
+			expectedCursor: "3",
+		},
+		{
+			testName:    "カーソル以降を取得",
+			filter:      &model.AuditEventFilter{UserID: "user_1", Limit: 2, Cursor: "3"},
+			expectedIDs: []string{"1"},
+		},
+		{
+			testName:    "種類と結果で絞り込み",
+			filter:      &model.AuditEventFilter{Type: model.AuditEventLogin, Outcome: model.AuditOutcomeFailure},
+			expectedIDs: []string{"2"},
+		},
+		{
+			testName:    "存在しないカーソルでエラー",
+			filter:      &model.AuditEventFilter{Cursor: "unknown"},
+			expectError: repository.ErrInvalidCursor,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			page, err := repo.Query(context.Background(), tt.filter)
+
+			if tt.expectError != nil {
+				assert.ErrorIs(t, err, tt.expectError)
+				assert.Nil(t, page)
+				return
+			}
+
+			assert.NoError(t, err)
+			ids := make([]string, 0, len(page.Events))
+			for _, event := range page.Events {
+				ids = append(ids, event.ID)
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+			assert.Equal(t, tt.expectedCursor, page.NextCursor)
+		})
+	}
+}
+
+func TestAuditRepositoryImpl_Pseudonymize(t *testing.T) {
+	repo := NewAuditRepository()
+	for _, event := range []*model.AuditEvent{
+		{ID: "1", Type: model.AuditEventLogin, Outcome: model.AuditOutcomeSuccess, ActorID: "user_1", SubjectID: "user_1", IPAddress: "192.0.2.1"},
+		{ID: "2", Type: model.AuditEventRoleChange, Outcome: model.AuditOutcomeSuccess, ActorID: "admin", SubjectID: "user_1"},
+		{ID: "3", Type: model.AuditEventLogin, Outcome: model.AuditOutcomeSuccess, ActorID: "user_2", SubjectID: "user_2", IPAddress: "192.0.2.2"},
+	} {
+		assert.NoError(t, repo.Append(context.Background(), event))
+	}
+
+	count, err := repo.Pseudonymize(context.Background(), "user_1", "deleted_abc")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	page, err := repo.Query(context.Background(), &model.AuditEventFilter{UserID: "user_1"})
+	assert.NoError(t, err)
+	assert.Empty(t, page.Events)
+
+	page, err = repo.Query(context.Background(), &model.AuditEventFilter{UserID: "deleted_abc"})
+	assert.NoError(t, err)
+	if assert.Len(t, page.Events, 2) {
+		assert.Equal(t, "admin", page.Events[0].ActorID)
+		assert.Empty(t, page.Events[1].IPAddress)
+	}
+
+	// 他のユーザーのイベントは変更しない
+	page, err = repo.Query(context.Background(), &model.AuditEventFilter{UserID: "user_2"})
+	assert.NoError(t, err)
+	if assert.Len(t, page.Events, 1) {
+		assert.Equal(t, "192.0.2.2", page.Events[0].IPAddress)
+	}
+
+	_, err = repo.Pseudonymize(context.Background(), "", "deleted_abc")
+	assert.Error(t, err)
+}
@@ -24,6 +24,9 @@ func NewAuthRepository() repository.AuthRepository {
 
 // SaveToken はトークンを保存する
 func (r *AuthRepositoryImpl) SaveToken(ctx context.Context, userID string, token *model.AuthToken) error {
+	_, span := tracer.Start(ctx, "AuthRepository.SaveToken")
+	defer span.End()
+
 	if userID == "" {
 		return errors.New("userID cannot be empty")
 	}
@@ -40,6 +43,9 @@ func (r *AuthRepositoryImpl) SaveToken(ctx context.Context, userID string, token
 
 // GetToken はユーザーIDでトークンを取得する
 func (r *AuthRepositoryImpl) GetToken(ctx context.Context, userID string) (*model.AuthToken, error) {
+	_, span := tracer.Start(ctx, "AuthRepository.GetToken")
+	defer span.End()
+
 	if userID == "" {
 		return nil, errors.New("userID cannot be empty")
 	}
@@ -49,7 +55,7 @@ func (r *AuthRepositoryImpl) GetToken(ctx context.Context, userID string) (*mode
 
 	token, exists := r.tokens[userID]
 	if !exists {
-		return nil, errors.New("token not found")
+		return nil, repository.ErrTokenNotFound
 	}
 
 	return token, nil
@@ -57,6 +63,9 @@ func (r *AuthRepositoryImpl) GetToken(ctx context.Context, userID string) (*mode
 
 // DeleteToken はトークンを削除する
 func (r *AuthRepositoryImpl) DeleteToken(ctx context.Context, userID string) error {
+	_, span := tracer.Start(ctx, "AuthRepository.DeleteToken")
+	defer span.End()
+
 	if userID == "" {
 		return errors.New("userID cannot be empty")
 	}
@@ -70,6 +79,9 @@ func (r *AuthRepositoryImpl) DeleteToken(ctx context.Context, userID string) err
 
 // ValidateToken はトークンを検証してユーザーIDを返す
 func (r *AuthRepositoryImpl) ValidateToken(ctx context.Context, token string) (string, error) {
+	_, span := tracer.Start(ctx, "AuthRepository.ValidateToken")
+	defer span.End()
+
 	if token == "" {
 		return "", errors.New("token cannot be empty")
 	}
@@ -90,3 +102,14 @@ func (r *AuthRepositoryImpl) ValidateToken(ctx context.Context, token string) (s
 
 	return "", errors.New("invalid token")
 }
+
+// CountTokens は保存されているトークンの数を返す
+func (r *AuthRepositoryImpl) CountTokens(ctx context.Context) (int, error) {
+	_, span := tracer.Start(ctx, "AuthRepository.CountTokens")
+	defer span.End()
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return len(r.tokens), nil
+}
@@ -230,3 +230,16 @@ func TestAuthRepositoryImpl_ValidateToken(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthRepositoryImpl_CountTokens(t *testing.T) {
+	repo := NewAuthRepository()
+	token := &model.AuthToken{AccessToken: "access_token", ExpiresIn: time.Now().Add(time.Hour).Unix()}
+
+	assert.NoError(t, repo.SaveToken(context.Background(), "user_1", token))
+	assert.NoError(t, repo.SaveToken(context.Background(), "user_2", token))
+	assert.NoError(t, repo.DeleteToken(context.Background(), "user_1"))
+
+	count, err := repo.CountTokens(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
@@ -0,0 +1,92 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/repository"
+	"sync"
+	"time"
+)
+
+// DataExportRepositoryImpl はDataExportRepository interfaceの実装
+// TODO: 実際のデータベース統合時にこのin-memory実装を置き換える
+type DataExportRepositoryImpl struct {
+	exports map[string]*model.DataExport
+	mutex   sync.RWMutex
+}
+
+// NewDataExportRepository は新しいDataExportRepositoryを作成する
+func NewDataExportRepository() repository.DataExportRepository {
+	return &DataExportRepositoryImpl{
+		exports: make(map[string]*model.DataExport),
+	}
+}
+
+// Save はユーザーのエクスポートを保存する（以前のエクスポートは置き換える）
+func (r *DataExportRepositoryImpl) Save(ctx context.Context, export *model.DataExport) error {
+	_, span := tracer.Start(ctx, "DataExportRepository.Save")
+	defer span.End()
+
+	if export == nil {
+		return errors.New("export cannot be nil")
+	}
+	if export.UserID == "" {
+		return errors.New("userID cannot be empty")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// 作成中のエクスポートの更新が呼び出し元と競合しないようにコピーを保存する
+	stored := *export
+	r.exports[export.UserID] = &stored
+	return nil
+}
+
+// FindByUserID はユーザーの最新のエクスポートを返す
+func (r *DataExportRepositoryImpl) FindByUserID(ctx context.Context, userID string) (*model.DataExport, error) {
+	_, span := tracer.Start(ctx, "DataExportRepository.FindByUserID")
+	defer span.End()
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	export, exists := r.exports[userID]
+	if !exists {
+		return nil, repository.ErrDataExportNotFound
+	}
+
+	copied := *export
+	return &copied, nil
+}
+
+// DeleteByUserID はユーザーのエクスポートを削除する
+func (r *DataExportRepositoryImpl) DeleteByUserID(ctx context.Context, userID string) error {
+	_, span := tracer.Start(ctx, "DataExportRepository.DeleteByUserID")
+	defer span.End()
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	delete(r.exports, userID)
+	return nil
+}
+
+// FindExpired は保持期限を過ぎたエクスポートを返す
+func (r *DataExportRepositoryImpl) FindExpired(ctx context.Context, now time.Time) ([]*model.DataExport, error) {
+	_, span := tracer.Start(ctx, "DataExportRepository.FindExpired")
+	defer span.End()
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var expired []*model.DataExport
+	for _, export := range r.exports {
+		if export.ExpiresAt != nil && !now.Before(*export.ExpiresAt) {
+			copied := *export
+			expired = append(expired, &copied)
+		}
+	}
+	return expired, nil
+}
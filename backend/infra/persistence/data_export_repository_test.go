@@ -0,0 +1,50 @@
+package persistence
+
+import (
+	"context"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataExportRepositoryImpl(t *testing.T) {
+	repo := NewDataExportRepository()
+	ctx := context.Background()
+
+	_, err := repo.FindByUserID(ctx, "user_1")
+	assert.ErrorIs(t, err, repository.ErrDataExportNotFound)
+
+	export, err := model.NewDataExport("export_1", "user_1")
+	require.NoError(t, err)
+	require.NoError(t, repo.Save(ctx, export))
+
+	// 保存後の変更は保存済みのエクスポートに影響しない
+	export.Complete(time.Hour)
+	found, err := repo.FindByUserID(ctx, "user_1")
+	require.NoError(t, err)
+	assert.Equal(t, model.DataExportPending, found.Status)
+
+	// 新しいエクスポートで置き換える
+	require.NoError(t, repo.Save(ctx, export))
+	found, err = repo.FindByUserID(ctx, "user_1")
+	require.NoError(t, err)
+	assert.Equal(t, model.DataExportReady, found.Status)
+
+	expired, err := repo.FindExpired(ctx, time.Now())
+	require.NoError(t, err)
+	assert.Empty(t, expired)
+	expired, err = repo.FindExpired(ctx, time.Now().Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.Len(t, expired, 1)
+
+	require.NoError(t, repo.DeleteByUserID(ctx, "user_1"))
+	_, err = repo.FindByUserID(ctx, "user_1")
+	assert.ErrorIs(t, err, repository.ErrDataExportNotFound)
+
+	assert.Error(t, repo.Save(ctx, nil))
+	assert.Error(t, repo.Save(ctx, &model.DataExport{ID: "export_2"}))
+}
@@ -0,0 +1,121 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/repository"
+	"sync"
+)
+
+// GroupRepositoryImpl はGroupRepository interfaceの実装
+// TODO: 実際のデータベース統合時にこのin-memory実装を置き換える
+type GroupRepositoryImpl struct {
+	groups map[string]*model.Group
+	mutex  sync.RWMutex
+}
+
+// NewGroupRepository は新しいGroupRepositoryを作成する
+func NewGroupRepository() repository.GroupRepository {
+	return &GroupRepositoryImpl{
+		groups: make(map[string]*model.Group),
+	}
+}
+
+// Save はグループを保存する
+func (r *GroupRepositoryImpl) Save(ctx context.Context, group *model.Group) error {
+	_, span := tracer.Start(ctx, "GroupRepository.Save")
+	defer span.End()
+
+	if group == nil {
+		return errors.New("group cannot be nil")
+	}
+	if group.ID == "" {
+		return errors.New("group ID cannot be empty")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.groups[group.ID] = copyGroup(group)
+	return nil
+}
+
+// FindByID はIDでグループを検索する
+func (r *GroupRepositoryImpl) FindByID(ctx context.Context, id string) (*model.Group, error) {
+	_, span := tracer.Start(ctx, "GroupRepository.FindByID")
+	defer span.End()
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	group, exists := r.groups[id]
+	if !exists {
+		return nil, repository.ErrGroupNotFound
+	}
+	return copyGroup(group), nil
+}
+
+// FindAll はすべてのグループを作成日時の昇順で返す
+func (r *GroupRepositoryImpl) FindAll(ctx context.Context) ([]*model.Group, error) {
+	_, span := tracer.Start(ctx, "GroupRepository.FindAll")
+	defer span.End()
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	groups := make([]*model.Group, 0, len(r.groups))
+	for _, group := range r.groups {
+		groups = append(groups, copyGroup(group))
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if !groups[i].CreatedAt.Equal(groups[j].CreatedAt) {
+			return groups[i].CreatedAt.Before(groups[j].CreatedAt)
+		}
+		return groups[i].ID < groups[j].ID
+	})
+	return groups, nil
+}
+
+// Update はグループを更新する
+func (r *GroupRepositoryImpl) Update(ctx context.Context, group *model.Group) error {
+	_, span := tracer.Start(ctx, "GroupRepository.Update")
+	defer span.End()
+
+	if group == nil {
+		return errors.New("group cannot be nil")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.groups[group.ID]; !exists {
+		return repository.ErrGroupNotFound
+	}
+	r.groups[group.ID] = copyGroup(group)
+	return nil
+}
+
+// Delete はグループを削除する
+func (r *GroupRepositoryImpl) Delete(ctx context.Context, id string) error {
+	_, span := tracer.Start(ctx, "GroupRepository.Delete")
+	defer span.End()
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.groups[id]; !exists {
+		return repository.ErrGroupNotFound
+	}
+	delete(r.groups, id)
+	return nil
+}
+
+// copyGroup は呼び出し元での変更が保存済みのグループに影響しないようにコピーする
+func copyGroup(group *model.Group) *model.Group {
+	copied := *group
+	copied.MemberIDs = slices.Clone(group.MemberIDs)
+	return &copied
+}
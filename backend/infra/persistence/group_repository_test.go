@@ -0,0 +1,48 @@
+package persistence
+
+import (
+	"context"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupRepositoryImpl(t *testing.T) {
+	repo := NewGroupRepository()
+	ctx := context.Background()
+	now := time.Now()
+
+	second := &model.Group{ID: "group_2", DisplayName: "Engineering", MemberIDs: []string{}, CreatedAt: now.Add(time.Second)}
+	first := &model.Group{ID: "group_1", DisplayName: "Sales", MemberIDs: []string{"user_1"}, CreatedAt: now}
+	require.NoError(t, repo.Save(ctx, second))
+	require.NoError(t, repo.Save(ctx, first))
+
+	// 保存後の変更は保存済みのグループに影響しない
+	first.MemberIDs[0] = "user_2"
+	found, err := repo.FindByID(ctx, "group_1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user_1"}, found.MemberIDs)
+
+	all, err := repo.FindAll(ctx)
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, "group_1", all[0].ID)
+	assert.Equal(t, "group_2", all[1].ID)
+
+	found.AddMembers([]string{"user_3"}, now)
+	require.NoError(t, repo.Update(ctx, found))
+	found, err = repo.FindByID(ctx, "group_1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user_1", "user_3"}, found.MemberIDs)
+
+	require.NoError(t, repo.Delete(ctx, "group_1"))
+	_, err = repo.FindByID(ctx, "group_1")
+	assert.ErrorIs(t, err, repository.ErrGroupNotFound)
+	assert.ErrorIs(t, repo.Delete(ctx, "group_1"), repository.ErrGroupNotFound)
+	assert.ErrorIs(t, repo.Update(ctx, first), repository.ErrGroupNotFound)
+	assert.Error(t, repo.Save(ctx, nil))
+}
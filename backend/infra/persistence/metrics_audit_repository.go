@@ -0,0 +1,53 @@
+package persistence
+
+import (
+	"context"
+	"stackies-backend/core/metrics"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/repository"
+)
+
+// MetricsAuditRepository は監査イベントの追記時に認証フローのメトリクスを記録するAuditRepository
+// 認証の結果はすべて監査イベントとして記録されるため、ユースケースを変更せずに集計できる
+type MetricsAuditRepository struct {
+	repository.AuditRepository
+	metrics *metrics.Metrics
+}
+
+// NewMetricsAuditRepository はAuditRepositoryにメトリクスの記録を追加する
+func NewMetricsAuditRepository(inner repository.AuditRepository, m *metrics.Metrics) repository.AuditRepository {
+	return &MetricsAuditRepository{
+		AuditRepository: inner,
+		metrics:         m,
+	}
+}
+
+// Append はメトリクスを記録してから監査イベントを追記する
+// 監査ログの記録に失敗しても認証自体は行われているため、メトリクスは記録する
+func (r *MetricsAuditRepository) Append(ctx context.Context, event *model.AuditEvent) error {
+	if event != nil {
+		r.observe(event)
+	}
+	return r.AuditRepository.Append(ctx, event)
+}
+
+func (r *MetricsAuditRepository) observe(event *model.AuditEvent) {
+	switch event.Type {
+	case model.AuditEventLogin:
+		provider := event.Metadata["provider"]
+		if provider == "" {
+			provider = "unknown"
+		}
+		r.metrics.IncLoginAttempt(provider, string(event.Outcome))
+	case model.AuditEventRefresh:
+		if event.Outcome == model.AuditOutcomeSuccess {
+			r.metrics.IncRefreshRotation()
+		}
+	case model.AuditEventTokenReuse:
+		r.metrics.IncTokenReuseDetection()
+	case model.AuditEventLogout:
+		if event.Outcome == model.AuditOutcomeSuccess {
+			r.metrics.IncLogout()
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package persistence
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"stackies-backend/core/metrics"
+	"stackies-backend/domain/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsAuditRepository_Append(t *testing.T) {
+	m := metrics.New()
+	inner := NewAuditRepository()
+	repo := NewMetricsAuditRepository(inner, m)
+
+	for _, event := range []*model.AuditEvent{
+		{Type: model.AuditEventLogin, Outcome: model.AuditOutcomeSuccess, Metadata: map[string]string{"provider": "google"}},
+		{Type: model.AuditEventLogin, Outcome: model.AuditOutcomeFailure, Metadata: map[string]string{"provider": "google"}},
+		{Type: model.AuditEventRefresh, Outcome: model.AuditOutcomeSuccess},
+		{Type: model.AuditEventRefresh, Outcome: model.AuditOutcomeFailure},
+		{Type: model.AuditEventTokenReuse, Outcome: model.AuditOutcomeFailure},
+		{Type: model.AuditEventLogout, Outcome: model.AuditOutcomeSuccess},
+		{Type: model.AuditEventRoleChange, Outcome: model.AuditOutcomeSuccess},
+	} {
+		assert.NoError(t, repo.Append(context.Background(), event))
+	}
+
+	// 元のリポジトリにも追記されている
+	page, err := inner.Query(context.Background(), &model.AuditEventFilter{})
+	if assert.NoError(t, err) {
+		assert.Len(t, page.Events, 7)
+	}
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+	assert.Contains(t, body, `stackies_auth_login_attempts_total{outcome="success",provider="google"} 1`)
+	assert.Contains(t, body, `stackies_auth_login_attempts_total{outcome="failure",provider="google"} 1`)
+	assert.Contains(t, body, "stackies_auth_refresh_rotations_total 1")
+	assert.Contains(t, body, "stackies_auth_token_reuse_detections_total 1")
+	assert.Contains(t, body, "stackies_auth_logouts_total 1")
+}
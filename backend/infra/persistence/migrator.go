@@ -0,0 +1,127 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/service"
+	"sync"
+	"time"
+)
+
+// Migration はデータストアのスキーマの変更を表す
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(ctx context.Context) error
+	// Down は変更を取り消す処理（nilの場合は取り消せない）
+	Down func(ctx context.Context) error
+}
+
+// Migrations はデータストアのマイグレーションの一覧
+// 現在のリポジトリはメモリ上の実装でスキーマを持たないため、データベースに移行した時点で追加する
+func Migrations() []Migration {
+	return nil
+}
+
+// MigratorImpl はMigrator interfaceの実装
+// TODO: データベース統合時に適用状況をデータベースのテーブルに保存する
+type MigratorImpl struct {
+	migrations []Migration
+	applied    map[int]time.Time
+	mutex      sync.Mutex
+	now        func() time.Time
+}
+
+// NewMigrator は新しいMigratorを作成する（バージョンは1以上で重複しないこと）
+func NewMigrator(migrations []Migration) (service.Migrator, error) {
+	sorted := append([]Migration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+	for i, migration := range sorted {
+		if migration.Version <= 0 {
+			return nil, fmt.Errorf("migration %q has an invalid version: %d", migration.Name, migration.Version)
+		}
+		if migration.Up == nil {
+			return nil, fmt.Errorf("migration %d has no up function", migration.Version)
+		}
+		if i > 0 && sorted[i-1].Version == migration.Version {
+			return nil, fmt.Errorf("duplicate migration version: %d", migration.Version)
+		}
+	}
+
+	return &MigratorImpl{
+		migrations: sorted,
+		applied:    make(map[int]time.Time),
+		now:        time.Now,
+	}, nil
+}
+
+// Status はすべてのマイグレーションと適用状況をバージョンの昇順で返す
+func (m *MigratorImpl) Status(ctx context.Context) ([]*model.MigrationStatus, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	statuses := make([]*model.MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		statuses = append(statuses, m.status(migration))
+	}
+	return statuses, nil
+}
+
+// Up は未適用のマイグレーションをバージョンの昇順にすべて適用し、適用したものを返す
+// 途中で失敗した場合はそれまでに適用したものとエラーを返す
+func (m *MigratorImpl) Up(ctx context.Context) ([]*model.MigrationStatus, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var applied []*model.MigrationStatus
+	for _, migration := range m.migrations {
+		if _, ok := m.applied[migration.Version]; ok {
+			continue
+		}
+		if err := migration.Up(ctx); err != nil {
+			return applied, fmt.Errorf("migration %d (%s) failed: %w", migration.Version, migration.Name, err)
+		}
+		m.applied[migration.Version] = m.now()
+		applied = append(applied, m.status(migration))
+	}
+	return applied, nil
+}
+
+// Down は適用済みのマイグレーションを新しいものからsteps件取り消し、取り消したものを返す
+func (m *MigratorImpl) Down(ctx context.Context, steps int) ([]*model.MigrationStatus, error) {
+	if steps <= 0 {
+		return nil, errors.New("steps must be positive")
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var reverted []*model.MigrationStatus
+	for i := len(m.migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+		migration := m.migrations[i]
+		if _, ok := m.applied[migration.Version]; !ok {
+			continue
+		}
+		if migration.Down == nil {
+			return reverted, fmt.Errorf("migration %d (%s) is irreversible", migration.Version, migration.Name)
+		}
+		if err := migration.Down(ctx); err != nil {
+			return reverted, fmt.Errorf("migration %d (%s) rollback failed: %w", migration.Version, migration.Name, err)
+		}
+		delete(m.applied, migration.Version)
+		reverted = append(reverted, m.status(migration))
+	}
+	return reverted, nil
+}
+
+// status はマイグレーションの適用状況を返す（ロックを保持して呼び出す）
+func (m *MigratorImpl) status(migration Migration) *model.MigrationStatus {
+	status := &model.MigrationStatus{Version: migration.Version, Name: migration.Name}
+	if appliedAt, ok := m.applied[migration.Version]; ok {
+		status.AppliedAt = &appliedAt
+	}
+	return status
+}
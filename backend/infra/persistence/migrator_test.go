@@ -0,0 +1,129 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigratorImpl_UpDown(t *testing.T) {
+	var calls []string
+	migration := func(version int, name string, reversible bool) Migration {
+		m := Migration{
+			Version: version,
+			Name:    name,
+			Up: func(ctx context.Context) error {
+				calls = append(calls, "up:"+name)
+				return nil
+			},
+		}
+		if reversible {
+			m.Down = func(ctx context.Context) error {
+				calls = append(calls, "down:"+name)
+				return nil
+			}
+		}
+		return m
+	}
+
+	migrator, err := NewMigrator([]Migration{
+		migration(2, "add_index", true),
+		migration(1, "create_users", false),
+		migration(3, "add_column", true),
+	})
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	// バージョンの昇順に適用する
+	applied, err := migrator.Up(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, applied, 3)
+	assert.Equal(t, []string{"up:create_users", "up:add_index", "up:add_column"}, calls)
+
+	// 適用済みのものは再度適用しない
+	applied, err = migrator.Up(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, applied)
+
+	// 新しいものから取り消す
+	calls = nil
+	reverted, err := migrator.Down(ctx, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"down:add_column", "down:add_index"}, calls)
+	assert.Equal(t, 3, reverted[0].Version)
+	assert.False(t, reverted[0].IsApplied())
+
+	statuses, err := migrator.Status(ctx)
+	assert.NoError(t, err)
+	assert.True(t, statuses[0].IsApplied())
+	assert.False(t, statuses[1].IsApplied())
+	assert.False(t, statuses[2].IsApplied())
+
+	// Downのないマイグレーションは取り消せない
+	_, err = migrator.Down(ctx, 1)
+	assert.ErrorContains(t, err, "irreversible")
+
+	_, err = migrator.Down(ctx, 0)
+	assert.Error(t, err)
+}
+
+func TestMigratorImpl_UpFailure(t *testing.T) {
+	migrator, err := NewMigrator([]Migration{
+		{Version: 1, Name: "ok", Up: func(ctx context.Context) error { return nil }},
+		{Version: 2, Name: "broken", Up: func(ctx context.Context) error { return errors.New("boom") }},
+		{Version: 3, Name: "never", Up: func(ctx context.Context) error { return nil }},
+	})
+	assert.NoError(t, err)
+
+	applied, err := migrator.Up(context.Background())
+	assert.ErrorContains(t, err, "migration 2 (broken) failed")
+	assert.Len(t, applied, 1)
+
+	statuses, _ := migrator.Status(context.Background())
+	assert.True(t, statuses[0].IsApplied())
+	assert.False(t, statuses[1].IsApplied())
+	assert.False(t, statuses[2].IsApplied())
+}
+
+func TestNewMigrator(t *testing.T) {
+	up := func(ctx context.Context) error { return nil }
+
+	tests := []struct {
+		testName   string
+		migrations []Migration
+		wantErrMsg string
+	}{
+		{
+			testName:   "マイグレーションなし",
+			migrations: Migrations(),
+		},
+		{
+			testName:   "バージョンの重複",
+			migrations: []Migration{{Version: 1, Name: "a", Up: up}, {Version: 1, Name: "b", Up: up}},
+			wantErrMsg: "duplicate migration version: 1",
+		},
+		{
+			testName:   "不正なバージョン",
+			migrations: []Migration{{Version: 0, Name: "a", Up: up}},
+			wantErrMsg: "invalid version",
+		},
+		{
+			testName:   "Upがない",
+			migrations: []Migration{{Version: 1, Name: "a"}},
+			wantErrMsg: "has no up function",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			_, err := NewMigrator(tt.migrations)
+			if tt.wantErrMsg != "" {
+				assert.ErrorContains(t, err, tt.wantErrMsg)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
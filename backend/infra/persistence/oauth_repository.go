@@ -0,0 +1,124 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/repository"
+	"sync"
+	"time"
+)
+
+// oauthSweepInterval は期限切れのstateとワンタイムコードを掃除する間隔
+const oauthSweepInterval = time.Minute
+
+// OAuthRepositoryImpl はOAuthRepository interfaceの実装
+// 使用されずに期限切れになったエントリは保存時に定期的に削除する
+// TODO: 実際のRedis統合時にこのin-memory実装を置き換える
+type OAuthRepositoryImpl struct {
+	states     map[string]*model.OAuthState
+	loginCodes map[string]*model.LoginCode
+	lastSweep  time.Time
+	now        func() time.Time
+	mutex      sync.Mutex
+}
+
+// NewOAuthRepository は新しいOAuthRepositoryを作成する
+func NewOAuthRepository() repository.OAuthRepository {
+	return &OAuthRepositoryImpl{
+		states:     make(map[string]*model.OAuthState),
+		loginCodes: make(map[string]*model.LoginCode),
+		now:        time.Now,
+	}
+}
+
+// SaveState はstateを保存する
+func (r *OAuthRepositoryImpl) SaveState(ctx context.Context, state *model.OAuthState) error {
+	if state == nil {
+		return errors.New("state cannot be nil")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.sweep(r.now())
+	r.states[state.State] = state
+	return nil
+}
+
+// ConsumeState はstateを取得して削除する（一度しか使用できない）
+func (r *OAuthRepositoryImpl) ConsumeState(ctx context.Context, state string) (*model.OAuthState, error) {
+	if state == "" {
+		return nil, errors.New("state cannot be empty")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	stored, exists := r.states[state]
+	if !exists {
+		return nil, repository.ErrStateNotFound
+	}
+	delete(r.states, state)
+
+	if stored.IsExpired() {
+		return nil, repository.ErrStateNotFound
+	}
+
+	return stored, nil
+}
+
+// SaveLoginCode はワンタイムコードを保存する
+func (r *OAuthRepositoryImpl) SaveLoginCode(ctx context.Context, code *model.LoginCode) error {
+	if code == nil {
+		return errors.New("login code cannot be nil")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.sweep(r.now())
+	r.loginCodes[code.Code] = code
+	return nil
+}
+
+// ConsumeLoginCode はワンタイムコードを取得して削除する
+func (r *OAuthRepositoryImpl) ConsumeLoginCode(ctx context.Context, code string) (*model.LoginCode, error) {
+	if code == "" {
+		return nil, errors.New("code cannot be empty")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	stored, exists := r.loginCodes[code]
+	if !exists {
+		return nil, repository.ErrLoginCodeNotFound
+	}
+	delete(r.loginCodes, code)
+
+	if stored.IsExpired() {
+		return nil, repository.ErrLoginCodeNotFound
+	}
+
+	return stored, nil
+}
+
+// sweep は期限切れのstateとワンタイムコードを削除する（呼び出し側でロックを取得する）
+func (r *OAuthRepositoryImpl) sweep(now time.Time) {
+	if now.Sub(r.lastSweep) < oauthSweepInterval {
+		return
+	}
+	r.lastSweep = now
+
+	for key, state := range r.states {
+		if !now.Before(state.ExpiresAt) {
+			delete(r.states, key)
+		}
+	}
+	for key, code := range r.loginCodes {
+		if !now.Before(code.ExpiresAt) {
+			delete(r.loginCodes, key)
+		}
+	}
+}
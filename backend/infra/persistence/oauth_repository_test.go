@@ -0,0 +1,107 @@
+package persistence
+
+import (
+	"context"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOAuthRepositoryImpl_ConsumeState(t *testing.T) {
+	repo := NewOAuthRepository()
+	valid := &model.OAuthState{State: "valid_state", RedirectURL: "http://localhost:5173/", ExpiresAt: time.Now().Add(time.Minute)}
+	expired := &model.OAuthState{State: "expired_state", ExpiresAt: time.Now().Add(-time.Minute)}
+	assert.NoError(t, repo.SaveState(context.Background(), valid))
+	assert.NoError(t, repo.SaveState(context.Background(), expired))
+
+	tests := []struct {
+		testName    string
+		state       string
+		expectError error
+	}{
+		{
+			testName:    "正常なstate取得",
+			state:       "valid_state",
+			expectError: nil,
+		},
+		{
+			testName:    "使用済みstateでエラー",
+			state:       "valid_state",
+			expectError: repository.ErrStateNotFound,
+		},
+		{
+			testName:    "期限切れstateでエラー",
+			state:       "expired_state",
+			expectError: repository.ErrStateNotFound,
+		},
+		{
+			testName:    "存在しないstateでエラー",
+			state:       "notfound",
+			expectError: repository.ErrStateNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			result, err := repo.ConsumeState(context.Background(), tt.state)
+
+			if tt.expectError != nil {
+				assert.ErrorIs(t, err, tt.expectError)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, valid.RedirectURL, result.RedirectURL)
+			}
+		})
+	}
+}
+
+func TestOAuthRepositoryImpl_ConsumeLoginCode(t *testing.T) {
+	repo := NewOAuthRepository()
+	code := &model.LoginCode{
+		Code:        "login_code",
+		UserID:      "user_123",
+		AccessToken: "access_token",
+		ExpiresAt:   time.Now().Add(time.Minute),
+	}
+	assert.NoError(t, repo.SaveLoginCode(context.Background(), code))
+
+	result, err := repo.ConsumeLoginCode(context.Background(), "login_code")
+	assert.NoError(t, err)
+	assert.Equal(t, "user_123", result.UserID)
+
+	// 二回目は使用できない
+	result, err = repo.ConsumeLoginCode(context.Background(), "login_code")
+	assert.ErrorIs(t, err, repository.ErrLoginCodeNotFound)
+	assert.Nil(t, result)
+
+	_, err = repo.ConsumeLoginCode(context.Background(), "")
+	assert.Error(t, err)
+}
+
+func TestOAuthRepositoryImpl_SweepExpired(t *testing.T) {
+	repo := NewOAuthRepository().(*OAuthRepositoryImpl)
+	now := time.Now()
+	repo.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	// 使用されずに期限切れになるstateとワンタイムコード
+	assert.NoError(t, repo.SaveState(ctx, &model.OAuthState{State: "abandoned_state", ExpiresAt: now.Add(time.Minute)}))
+	assert.NoError(t, repo.SaveLoginCode(ctx, &model.LoginCode{Code: "abandoned_code", UserID: "user_123", AccessToken: "access_token", ExpiresAt: now.Add(time.Minute)}))
+
+	// 掃除の間隔内では削除しない
+	now = now.Add(30 * time.Second)
+	assert.NoError(t, repo.SaveState(ctx, &model.OAuthState{State: "state_1", ExpiresAt: now.Add(10 * time.Minute)}))
+	assert.Len(t, repo.states, 2)
+
+	// 掃除の間隔が経過した後の保存で期限切れのエントリを削除する
+	now = now.Add(2 * time.Minute)
+	assert.NoError(t, repo.SaveState(ctx, &model.OAuthState{State: "state_2", ExpiresAt: now.Add(10 * time.Minute)}))
+	assert.NotContains(t, repo.states, "abandoned_state")
+	assert.Contains(t, repo.states, "state_1")
+	assert.Contains(t, repo.states, "state_2")
+	assert.Empty(t, repo.loginCodes)
+}
@@ -0,0 +1,137 @@
+package persistence
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/repository"
+	"sync"
+	"time"
+)
+
+// OutboxRepositoryImpl はOutboxRepository interfaceの実装
+// TODO: 実際のデータベース統合時にこのin-memory実装を置き換える
+type OutboxRepositoryImpl struct {
+	// messages は保存した順（古い順）に並ぶ
+	messages []*model.OutboxMessage
+	mutex    sync.RWMutex
+}
+
+// NewOutboxRepository は新しいOutboxRepositoryを作成する
+func NewOutboxRepository() repository.OutboxRepository {
+	return &OutboxRepositoryImpl{}
+}
+
+// Append はイベントを配信待ちとして保存する
+func (r *OutboxRepositoryImpl) Append(ctx context.Context, events ...*model.DomainEvent) error {
+	_, span := tracer.Start(ctx, "OutboxRepository.Append")
+	defer span.End()
+
+	messages := make([]*model.OutboxMessage, 0, len(events))
+	for _, event := range events {
+		if event == nil {
+			return errors.New("event cannot be nil")
+		}
+		if event.ID == "" {
+			bytes := make([]byte, 16)
+			if _, err := rand.Read(bytes); err != nil {
+				return err
+			}
+			event.ID = hex.EncodeToString(bytes)
+		}
+		// 呼び出し元での変更が配信するイベントに影響しないようにコピーを保存する
+		stored := *event
+		messages = append(messages, &model.OutboxMessage{Event: &stored, NextAttemptAt: stored.OccurredAt})
+	}
+
+	afterCommit(ctx, func() {
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		r.messages = append(r.messages, messages...)
+	})
+	return nil
+}
+
+// FetchPending は配信時刻を過ぎたメッセージを古い順に最大limit件返す
+func (r *OutboxRepositoryImpl) FetchPending(ctx context.Context, now time.Time, limit int) ([]*model.OutboxMessage, error) {
+	_, span := tracer.Start(ctx, "OutboxRepository.FetchPending")
+	defer span.End()
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var pending []*model.OutboxMessage
+	for _, message := range r.messages {
+		if len(pending) >= limit {
+			break
+		}
+		if message.NextAttemptAt.After(now) {
+			continue
+		}
+		copied := *message
+		pending = append(pending, &copied)
+	}
+	return pending, nil
+}
+
+// Delete は配信が完了したメッセージを削除する
+func (r *OutboxRepositoryImpl) Delete(ctx context.Context, id string) error {
+	_, span := tracer.Start(ctx, "OutboxRepository.Delete")
+	defer span.End()
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for i, message := range r.messages {
+		if message.Event.ID == id {
+			r.messages = append(r.messages[:i], r.messages[i+1:]...)
+			return nil
+		}
+	}
+	return repository.ErrOutboxMessageNotFound
+}
+
+// MarkFailed は配信の失敗を記録し、次に配信する時刻を設定する
+func (r *OutboxRepositoryImpl) MarkFailed(ctx context.Context, id string, cause string, nextAttemptAt time.Time) error {
+	_, span := tracer.Start(ctx, "OutboxRepository.MarkFailed")
+	defer span.End()
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, message := range r.messages {
+		if message.Event.ID == id {
+			message.Attempts++
+			message.LastError = cause
+			message.NextAttemptAt = nextAttemptAt
+			return nil
+		}
+	}
+	return repository.ErrOutboxMessageNotFound
+}
+
+// DeleteByUserID はユーザーを対象とする配信待ちのメッセージを削除し、削除した件数を返す
+func (r *OutboxRepositoryImpl) DeleteByUserID(ctx context.Context, userID string) (int, error) {
+	_, span := tracer.Start(ctx, "OutboxRepository.DeleteByUserID")
+	defer span.End()
+
+	if userID == "" {
+		return 0, errors.New("userID cannot be empty")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	kept := r.messages[:0]
+	for _, message := range r.messages {
+		if message.Event.UserID != userID {
+			kept = append(kept, message)
+		}
+	}
+	deleted := len(r.messages) - len(kept)
+	clear(r.messages[len(kept):])
+	r.messages = kept
+	return deleted, nil
+}
@@ -0,0 +1,136 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutboxRepositoryImpl(t *testing.T) {
+	repo := NewOutboxRepository()
+	ctx := context.Background()
+
+	first := model.NewDomainEvent(model.DomainEventUserRegistered, "user_1", "user_1", nil)
+	second := model.NewDomainEvent(model.DomainEventUserLoggedIn, "user_1", "user_1", nil)
+	require.NoError(t, repo.Append(ctx, first, second))
+	assert.NotEmpty(t, first.ID)
+	now := time.Now()
+
+	// 保存後の変更は配信するイベントに影響しない
+	first.UserID = "user_2"
+	pending, err := repo.FetchPending(ctx, now, 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	assert.Equal(t, "user_1", pending[0].Event.UserID)
+	assert.Equal(t, model.DomainEventUserLoggedIn, pending[1].Event.Type)
+
+	pending, err = repo.FetchPending(ctx, now, 1)
+	require.NoError(t, err)
+	assert.Len(t, pending, 1)
+
+	// 失敗したメッセージは次の配信時刻まで返さない
+	require.NoError(t, repo.MarkFailed(ctx, first.ID, "timeout", now.Add(time.Minute)))
+	pending, err = repo.FetchPending(ctx, now, 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, second.ID, pending[0].Event.ID)
+	pending, err = repo.FetchPending(ctx, now.Add(time.Minute), 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	assert.Equal(t, 1, pending[0].Attempts)
+	assert.Equal(t, "timeout", pending[0].LastError)
+
+	require.NoError(t, repo.Delete(ctx, second.ID))
+	assert.ErrorIs(t, repo.Delete(ctx, second.ID), repository.ErrOutboxMessageNotFound)
+	assert.ErrorIs(t, repo.MarkFailed(ctx, "unknown", "timeout", now), repository.ErrOutboxMessageNotFound)
+	assert.Error(t, repo.Append(ctx, nil))
+}
+
+func TestOutboxRepositoryImpl_DeleteByUserID(t *testing.T) {
+	repo := NewOutboxRepository()
+	ctx := context.Background()
+
+	require.NoError(t, repo.Append(ctx,
+		model.NewDomainEvent(model.DomainEventUserRegistered, "user_1", "user_1", map[string]string{"email": "user1@example.com"}),
+		model.NewDomainEvent(model.DomainEventUserRegistered, "user_2", "user_2", map[string]string{"email": "user2@example.com"}),
+		model.NewDomainEvent(model.DomainEventUserLoggedIn, "user_1", "user_1", nil),
+	))
+
+	deleted, err := repo.DeleteByUserID(ctx, "user_1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+
+	pending, err := repo.FetchPending(ctx, time.Now(), 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, "user_2", pending[0].Event.UserID)
+
+	_, err = repo.DeleteByUserID(ctx, "")
+	assert.Error(t, err)
+}
+
+func TestTransactorImpl(t *testing.T) {
+	tests := []struct {
+		testName      string
+		fn            func(ctx context.Context, repo repository.OutboxRepository) error
+		expectedCount int
+		expectedError bool
+	}{
+		{
+			testName: "成功した場合はアウトボックスへの追記を確定する",
+			fn: func(ctx context.Context, repo repository.OutboxRepository) error {
+				return repo.Append(ctx, model.NewDomainEvent(model.DomainEventProfileUpdated, "user_1", "user_1", nil))
+			},
+			expectedCount: 1,
+		},
+		{
+			testName: "失敗した場合はアウトボックスへの追記を破棄する",
+			fn: func(ctx context.Context, repo repository.OutboxRepository) error {
+				if err := repo.Append(ctx, model.NewDomainEvent(model.DomainEventProfileUpdated, "user_1", "user_1", nil)); err != nil {
+					return err
+				}
+				return errors.New("update failed")
+			},
+			expectedError: true,
+		},
+		{
+			testName: "入れ子のトランザクションは外側のトランザクションで確定する",
+			fn: func(ctx context.Context, repo repository.OutboxRepository) error {
+				inner := NewTransactor().WithinTransaction(ctx, func(ctx context.Context) error {
+					return repo.Append(ctx, model.NewDomainEvent(model.DomainEventSessionRevoked, "user_1", "user_1", nil))
+				})
+				if inner != nil {
+					return inner
+				}
+				return errors.New("outer failed")
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			repo := NewOutboxRepository()
+			ctx := context.Background()
+
+			err := NewTransactor().WithinTransaction(ctx, func(ctx context.Context) error {
+				return tt.fn(ctx, repo)
+			})
+
+			if tt.expectedError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			pending, err := repo.FetchPending(ctx, time.Now(), 10)
+			require.NoError(t, err)
+			assert.Len(t, pending, tt.expectedCount)
+		})
+	}
+}
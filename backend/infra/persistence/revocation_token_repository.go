@@ -0,0 +1,58 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/repository"
+	"sync"
+)
+
+// RevocationTokenRepositoryImpl はRevocationTokenRepository interfaceの実装
+// TODO: 実際のRedis統合時にこのin-memory実装を置き換える
+type RevocationTokenRepositoryImpl struct {
+	tokens map[string]*model.RevocationToken
+	mutex  sync.Mutex
+}
+
+// NewRevocationTokenRepository は新しいRevocationTokenRepositoryを作成する
+func NewRevocationTokenRepository() repository.RevocationTokenRepository {
+	return &RevocationTokenRepositoryImpl{
+		tokens: make(map[string]*model.RevocationToken),
+	}
+}
+
+// SaveRevocationToken はトークンを保存する
+func (r *RevocationTokenRepositoryImpl) SaveRevocationToken(ctx context.Context, token *model.RevocationToken) error {
+	if token == nil {
+		return errors.New("token cannot be nil")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.tokens[token.Token] = token
+	return nil
+}
+
+// ConsumeRevocationToken はトークンを取得して削除する（一度しか使用できない）
+func (r *RevocationTokenRepositoryImpl) ConsumeRevocationToken(ctx context.Context, token string) (*model.RevocationToken, error) {
+	if token == "" {
+		return nil, errors.New("token cannot be empty")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	stored, exists := r.tokens[token]
+	if !exists {
+		return nil, repository.ErrRevocationTokenNotFound
+	}
+	delete(r.tokens, token)
+
+	if stored.IsExpired() {
+		return nil, repository.ErrRevocationTokenNotFound
+	}
+
+	return stored, nil
+}
@@ -0,0 +1,60 @@
+package persistence
+
+import (
+	"context"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRevocationTokenRepositoryImpl_ConsumeRevocationToken(t *testing.T) {
+	repo := NewRevocationTokenRepository()
+	valid := &model.RevocationToken{Token: "valid_token", UserID: "user_1", ExpiresAt: time.Now().Add(time.Hour)}
+	expired := &model.RevocationToken{Token: "expired_token", UserID: "user_1", ExpiresAt: time.Now().Add(-time.Minute)}
+	assert.NoError(t, repo.SaveRevocationToken(context.Background(), valid))
+	assert.NoError(t, repo.SaveRevocationToken(context.Background(), expired))
+
+	tests := []struct {
+		testName    string
+		token       string
+		expectError error
+	}{
+		{
+			testName:    "正常なトークン取得",
+			token:       "valid_token",
+			expectError: nil,
+		},
+		{
+			testName:    "使用済みトークンでエラー",
+			token:       "valid_token",
+			expectError: repository.ErrRevocationTokenNotFound,
+		},
+		{
+			testName:    "期限切れトークンでエラー",
+			token:       "expired_token",
+			expectError: repository.ErrRevocationTokenNotFound,
+		},
+		{
+			testName:    "存在しないトークンでエラー",
+			token:       "notfound",
+			expectError: repository.ErrRevocationTokenNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			result, err := repo.ConsumeRevocationToken(context.Background(), tt.token)
+
+			if tt.expectError != nil {
+				assert.ErrorIs(t, err, tt.expectError)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "user_1", result.UserID)
+			}
+		})
+	}
+}
@@ -0,0 +1,6 @@
+package persistence
+
+import "go.opentelemetry.io/otel"
+
+// tracer はリポジトリのスパンを作成するトレーサー
+var tracer = otel.Tracer("stackies-backend/infra/persistence")
@@ -0,0 +1,61 @@
+package persistence
+
+import (
+	"context"
+	"stackies-backend/domain/repository"
+	"sync"
+)
+
+// transactionKey はcontextにトランザクションを保持するキー
+type transactionKey struct{}
+
+// transaction は確定時に反映する書き込みを保持する
+type transaction struct {
+	onCommit []func()
+}
+
+// TransactorImpl はTransactor interfaceのin-memory実装
+// トランザクションは直列に実行し、アウトボックスへの追記はfnが成功した場合のみ確定する
+// ロールバックは行わないため、fnが途中で失敗してもそれまでのリポジトリへの書き込みは残る。
+// またリポジトリは共有のエンティティを返すため、確定前の変更が他のゴルーチンから見える場合がある。
+// 書き込みとイベントの保存を不可分にするには、データベースのトランザクションで実装する必要がある
+// TODO: 実際のデータベース統合時にこのin-memory実装を置き換える
+type TransactorImpl struct {
+	mutex sync.Mutex
+}
+
+// NewTransactor は新しいTransactorを作成する
+func NewTransactor() repository.Transactor {
+	return &TransactorImpl{}
+}
+
+// WithinTransaction はfnをトランザクションの中で実行する
+func (t *TransactorImpl) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(transactionKey{}).(*transaction); ok {
+		return fn(ctx)
+	}
+
+	ctx, span := tracer.Start(ctx, "Transactor.WithinTransaction")
+	defer span.End()
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	tx := &transaction{}
+	if err := fn(context.WithValue(ctx, transactionKey{}, tx)); err != nil {
+		return err
+	}
+	for _, commit := range tx.onCommit {
+		commit()
+	}
+	return nil
+}
+
+// afterCommit はトランザクションの中であれば確定時に、そうでなければ直ちにfnを実行する
+func afterCommit(ctx context.Context, fn func()) {
+	if tx, ok := ctx.Value(transactionKey{}).(*transaction); ok {
+		tx.onCommit = append(tx.onCommit, fn)
+		return
+	}
+	fn()
+}
@@ -3,9 +3,12 @@ package persistence
 import (
 	"context"
 	"errors"
+	"sort"
 	"stackies-backend/domain/model"
 	"stackies-backend/domain/repository"
+	"strings"
 	"sync"
+	"time"
 )
 
 // UserRepositoryImpl はUserRepository interfaceの実装
@@ -24,6 +27,9 @@ func NewUserRepository() repository.UserRepository {
 
 // Save はユーザーを保存する
 func (r *UserRepositoryImpl) Save(ctx context.Context, user *model.User) error {
+	_, span := tracer.Start(ctx, "UserRepository.Save")
+	defer span.End()
+
 	if user == nil {
 		return errors.New("user cannot be nil")
 	}
@@ -38,8 +44,11 @@ func (r *UserRepositoryImpl) Save(ctx context.Context, user *model.User) error {
 	return nil
 }
 
-// FindByEmail はメールアドレスでユーザーを検索する
+// FindByEmail はメールアドレスでユーザーを検索する（大文字・小文字を区別しない）
 func (r *UserRepositoryImpl) FindByEmail(ctx context.Context, email string) (*model.User, error) {
+	_, span := tracer.Start(ctx, "UserRepository.FindByEmail")
+	defer span.End()
+
 	if email == "" {
 		return nil, errors.New("email cannot be empty")
 	}
@@ -48,7 +57,7 @@ func (r *UserRepositoryImpl) FindByEmail(ctx context.Context, email string) (*mo
 	defer r.mutex.RUnlock()
 
 	for _, user := range r.users {
-		if user.Email == email {
+		if strings.EqualFold(user.Email, email) {
 			return user, nil
 		}
 	}
@@ -58,6 +67,9 @@ func (r *UserRepositoryImpl) FindByEmail(ctx context.Context, email string) (*mo
 
 // FindByID はIDでユーザーを検索する
 func (r *UserRepositoryImpl) FindByID(ctx context.Context, id string) (*model.User, error) {
+	_, span := tracer.Start(ctx, "UserRepository.FindByID")
+	defer span.End()
+
 	if id == "" {
 		return nil, errors.New("id cannot be empty")
 	}
@@ -75,6 +87,9 @@ func (r *UserRepositoryImpl) FindByID(ctx context.Context, id string) (*model.Us
 
 // Update はユーザー情報を更新する
 func (r *UserRepositoryImpl) Update(ctx context.Context, user *model.User) error {
+	_, span := tracer.Start(ctx, "UserRepository.Update")
+	defer span.End()
+
 	if user == nil {
 		return errors.New("user cannot be nil")
 	}
@@ -92,3 +107,91 @@ func (r *UserRepositoryImpl) Update(ctx context.Context, user *model.User) error
 	r.users[user.ID] = user
 	return nil
 }
+
+// FindAll はすべてのユーザーを作成日時の昇順で返す（作成日時が同じ場合はIDの昇順）
+func (r *UserRepositoryImpl) FindAll(ctx context.Context) ([]*model.User, error) {
+	_, span := tracer.Start(ctx, "UserRepository.FindAll")
+	defer span.End()
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	users := make([]*model.User, 0, len(r.users))
+	for _, user := range r.users {
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		if !users[i].CreatedAt.Equal(users[j].CreatedAt) {
+			return users[i].CreatedAt.Before(users[j].CreatedAt)
+		}
+		return users[i].ID < users[j].ID
+	})
+	return users, nil
+}
+
+// List は検索条件に一致するユーザーを指定した並び順で返す
+// カーソルに保存した(並び替えの値, ID)より後ろのユーザーを返すキーセット方式のため、
+// ページの間にユーザーが追加・変更されても重複・欠落しない
+// in-memory実装のため毎回全件を走査する。データベースでは (並び替えの値, id) の複合インデックスを使い
+// WHERE (key, id) > (:key, :id) ORDER BY key, id LIMIT n で同じ結果を返す
+func (r *UserRepositoryImpl) List(ctx context.Context, filter *model.UserFilter) (*model.UserPage, error) {
+	_, span := tracer.Start(ctx, "UserRepository.List")
+	defer span.End()
+
+	if filter == nil {
+		filter = &model.UserFilter{}
+	}
+
+	var after *model.UserCursor
+	if filter.Cursor != "" {
+		cursor, err := model.DecodeUserCursor(filter.Sort, filter.Cursor)
+		if err != nil {
+			return nil, repository.ErrInvalidCursor
+		}
+		after = cursor
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	now := time.Now()
+	users := make([]*model.User, 0)
+	for _, user := range r.users {
+		if after != nil && !filter.Sort.After(after, user) {
+			continue
+		}
+		if filter.Matches(user, now) {
+			users = append(users, user)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return filter.Sort.Less(users[i], users[j])
+	})
+
+	page := &model.UserPage{Users: users}
+	if size := filter.PageSize(); len(users) > size {
+		page.Users = users[:size]
+		page.NextCursor = model.NewUserCursor(filter.Sort, users[size-1]).Encode()
+	}
+	return page, nil
+}
+
+// Delete はユーザーを削除する
+func (r *UserRepositoryImpl) Delete(ctx context.Context, id string) error {
+	_, span := tracer.Start(ctx, "UserRepository.Delete")
+	defer span.End()
+
+	if id == "" {
+		return errors.New("id cannot be empty")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.users[id]; !exists {
+		return repository.ErrUserNotFound
+	}
+
+	delete(r.users, id)
+	return nil
+}
@@ -82,6 +82,12 @@ func TestUserRepositoryImpl_FindByEmail(t *testing.T) {
 			expectError: false,
 			expectUser:  true,
 		},
+		{
+			testName:    "大文字・小文字が異なるメール",
+			email:       "Test@Example.COM",
+			expectError: false,
+			expectUser:  true,
+		},
 		{
 			testName:    "存在しないメール",
 			email:       "notfound@example.com",
@@ -238,3 +244,163 @@ func TestUserRepositoryImpl_Update(t *testing.T) {
 		})
 	}
 }
+
+func TestUserRepositoryImpl_FindAll(t *testing.T) {
+	repo := NewUserRepository()
+
+	users, err := repo.FindAll(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, users)
+
+	base := time.Now()
+	for _, user := range []*model.User{
+		{ID: "user_3", Email: "c@example.com", Name: "C", CreatedAt: base.Add(time.Minute)},
+		{ID: "user_2", Email: "b@example.com", Name: "B", CreatedAt: base},
+		{ID: "user_1", Email: "a@example.com", Name: "A", CreatedAt: base},
+	} {
+		assert.NoError(t, repo.Save(context.Background(), user))
+	}
+
+	users, err = repo.FindAll(context.Background())
+	assert.NoError(t, err)
+	var ids []string
+	for _, user := range users {
+		ids = append(ids, user.ID)
+	}
+	assert.Equal(t, []string{"user_1", "user_2", "user_3"}, ids)
+}
+
+func TestUserRepositoryImpl_List(t *testing.T) {
+	repo := NewUserRepository()
+	base := time.Now()
+	deletedAt := base
+	carol := &model.User{ID: "user_3", Email: "carol@example.com", Name: "Carol", Role: model.RoleUser, Status: model.UserStatusActive, CreatedAt: base.Add(2 * time.Minute)}
+	carolCursor := model.NewUserCursor(model.UserSortCreatedDesc, carol).Encode()
+	for _, user := range []*model.User{
+		{ID: "user_1", Email: "alice@example.com", Name: "Alice", Role: model.RoleAdmin, Status: model.UserStatusActive, CreatedAt: base},
+		{ID: "user_2", Email: "bob@example.com", Name: "Bob", Role: model.RoleUser, Status: model.UserStatusDisabled, CreatedAt: base.Add(time.Minute)},
+		carol,
+		{ID: "user_4", Email: "dave@example.com", Name: "Dave", Role: model.RoleUser, Status: model.UserStatusActive, CreatedAt: base.Add(3 * time.Minute)},
+		{ID: "user_5", Email: "alex@example.com", Name: "Alex", Role: model.RoleUser, Status: model.UserStatusActive, CreatedAt: base, DeletedAt: &deletedAt},
+	} {
+		assert.NoError(t, repo.Save(context.Background(), user))
+	}
+
+	tests := []struct {
+		testName       string
+		filter         *model.UserFilter
+		expectedIDs    []string
+		expectedCursor string
+		expectError    error
+	}{
+		{
+			testName:    "条件なしは削除済みを除いて作成日時の昇順",
+			filter:      nil,
+			expectedIDs: []string{"user_1", "user_2", "user_3", "user_4"},
+		},
+		{
+			testName:       "件数を超える場合は次のカーソルを返す",
+			filter:         &model.UserFilter{Sort: model.UserSortCreatedDesc, Limit: 2},
+			expectedIDs:    []string{"user_4", "user_3"},
+			expectedCursor: carolCursor,
+		},
+		{
+			testName:    "カーソルの次から返す",
+			filter:      &model.UserFilter{Sort: model.UserSortCreatedDesc, Limit: 2, Cursor: carolCursor},
+			expectedIDs: []string{"user_2", "user_1"},
+		},
+		{
+			testName:    "状態とロールで絞り込む",
+			filter:      &model.UserFilter{Status: model.UserStatusActive, Role: model.RoleUser},
+			expectedIDs: []string{"user_3", "user_4"},
+		},
+		{
+			testName:    "前方一致で検索して名前の降順",
+			filter:      &model.UserFilter{Query: "a", Sort: model.UserSortNameDesc},
+			expectedIDs: []string{"user_1"},
+		},
+		{
+			testName:    "作成日時の範囲",
+			filter:      &model.UserFilter{CreatedFrom: base.Add(time.Minute), CreatedTo: base.Add(3 * time.Minute)},
+			expectedIDs: []string{"user_2", "user_3"},
+		},
+		{
+			testName:    "解釈できないカーソル",
+			filter:      &model.UserFilter{Cursor: "unknown"},
+			expectError: repository.ErrInvalidCursor,
+		},
+		{
+			testName:    "並び順が異なるカーソル",
+			filter:      &model.UserFilter{Sort: model.UserSortEmailAsc, Cursor: carolCursor},
+			expectError: repository.ErrInvalidCursor,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			page, err := repo.List(context.Background(), tt.filter)
+
+			if tt.expectError != nil {
+				assert.ErrorIs(t, err, tt.expectError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedIDs, userIDs(page.Users))
+			assert.Equal(t, tt.expectedCursor, page.NextCursor)
+		})
+	}
+}
+
+func TestUserRepositoryImpl_List_ChangedBetweenPages(t *testing.T) {
+	repo := NewUserRepository()
+	for _, user := range []*model.User{
+		{ID: "user_1", Email: "a@example.com", Name: "Alice"},
+		{ID: "user_2", Email: "b@example.com", Name: "Bob"},
+		{ID: "user_3", Email: "c@example.com", Name: "Carol"},
+		{ID: "user_4", Email: "d@example.com", Name: "Dave"},
+	} {
+		assert.NoError(t, repo.Save(context.Background(), user))
+	}
+
+	filter := &model.UserFilter{Sort: model.UserSortNameAsc, Limit: 2}
+	first, err := repo.List(context.Background(), filter)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"user_1", "user_2"}, userIDs(first.Users))
+
+	// 1ページ目の最後のユーザーの名前を変更しても、2ページ目はカーソルの位置から続く
+	bob, err := repo.FindByID(context.Background(), "user_2")
+	assert.NoError(t, err)
+	bob.Name = "Zed"
+	assert.NoError(t, repo.Update(context.Background(), bob))
+
+	filter.Cursor = first.NextCursor
+	second, err := repo.List(context.Background(), filter)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"user_3", "user_4"}, userIDs(second.Users))
+
+	filter.Cursor = second.NextCursor
+	third, err := repo.List(context.Background(), filter)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"user_2"}, userIDs(third.Users))
+	assert.Empty(t, third.NextCursor)
+}
+
+func userIDs(users []*model.User) []string {
+	ids := []string{}
+	for _, user := range users {
+		ids = append(ids, user.ID)
+	}
+	return ids
+}
+
+func TestUserRepositoryImpl_Delete(t *testing.T) {
+	repo := NewUserRepository()
+	assert.NoError(t, repo.Save(context.Background(), &model.User{ID: "user_1", Email: "a@example.com", Name: "A"}))
+
+	assert.NoError(t, repo.Delete(context.Background(), "user_1"))
+	_, err := repo.FindByID(context.Background(), "user_1")
+	assert.ErrorIs(t, err, repository.ErrUserNotFound)
+
+	assert.ErrorIs(t, repo.Delete(context.Background(), "user_1"), repository.ErrUserNotFound)
+	assert.Error(t, repo.Delete(context.Background(), ""))
+}
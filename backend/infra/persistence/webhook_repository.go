@@ -0,0 +1,347 @@
+package persistence
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/repository"
+	"sync"
+	"time"
+)
+
+// WebhookRepositoryImpl はWebhookRepository interfaceの実装
+// TODO: 実際のデータベース統合時にこのin-memory実装を置き換える
+type WebhookRepositoryImpl struct {
+	subscriptions map[string]*model.WebhookSubscription
+	mutex         sync.RWMutex
+}
+
+// NewWebhookRepository は新しいWebhookRepositoryを作成する
+func NewWebhookRepository() repository.WebhookRepository {
+	return &WebhookRepositoryImpl{
+		subscriptions: make(map[string]*model.WebhookSubscription),
+	}
+}
+
+// Save は購読を保存する
+func (r *WebhookRepositoryImpl) Save(ctx context.Context, subscription *model.WebhookSubscription) error {
+	_, span := tracer.Start(ctx, "WebhookRepository.Save")
+	defer span.End()
+
+	if subscription == nil {
+		return errors.New("subscription cannot be nil")
+	}
+	if subscription.ID == "" {
+		return errors.New("subscription ID cannot be empty")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.subscriptions[subscription.ID] = copyWebhookSubscription(subscription)
+	return nil
+}
+
+// FindByID はIDで購読を検索する
+func (r *WebhookRepositoryImpl) FindByID(ctx context.Context, id string) (*model.WebhookSubscription, error) {
+	_, span := tracer.Start(ctx, "WebhookRepository.FindByID")
+	defer span.End()
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	subscription, exists := r.subscriptions[id]
+	if !exists {
+		return nil, repository.ErrWebhookNotFound
+	}
+	return copyWebhookSubscription(subscription), nil
+}
+
+// FindAll はすべての購読を作成日時の昇順で返す
+func (r *WebhookRepositoryImpl) FindAll(ctx context.Context) ([]*model.WebhookSubscription, error) {
+	_, span := tracer.Start(ctx, "WebhookRepository.FindAll")
+	defer span.End()
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	subscriptions := make([]*model.WebhookSubscription, 0, len(r.subscriptions))
+	for _, subscription := range r.subscriptions {
+		subscriptions = append(subscriptions, copyWebhookSubscription(subscription))
+	}
+	sort.Slice(subscriptions, func(i, j int) bool {
+		if !subscriptions[i].CreatedAt.Equal(subscriptions[j].CreatedAt) {
+			return subscriptions[i].CreatedAt.Before(subscriptions[j].CreatedAt)
+		}
+		return subscriptions[i].ID < subscriptions[j].ID
+	})
+	return subscriptions, nil
+}
+
+// Update は購読を更新する
+func (r *WebhookRepositoryImpl) Update(ctx context.Context, subscription *model.WebhookSubscription) error {
+	_, span := tracer.Start(ctx, "WebhookRepository.Update")
+	defer span.End()
+
+	if subscription == nil {
+		return errors.New("subscription cannot be nil")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.subscriptions[subscription.ID]; !exists {
+		return repository.ErrWebhookNotFound
+	}
+	r.subscriptions[subscription.ID] = copyWebhookSubscription(subscription)
+	return nil
+}
+
+// Delete は購読を削除する
+func (r *WebhookRepositoryImpl) Delete(ctx context.Context, id string) error {
+	_, span := tracer.Start(ctx, "WebhookRepository.Delete")
+	defer span.End()
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.subscriptions[id]; !exists {
+		return repository.ErrWebhookNotFound
+	}
+	delete(r.subscriptions, id)
+	return nil
+}
+
+// copyWebhookSubscription は呼び出し元での変更が保存済みの購読に影響しないようにコピーする
+func copyWebhookSubscription(subscription *model.WebhookSubscription) *model.WebhookSubscription {
+	copied := *subscription
+	copied.EventTypes = slices.Clone(subscription.EventTypes)
+	return &copied
+}
+
+// WebhookDeliveryRepositoryImpl はWebhookDeliveryRepository interfaceの実装
+// TODO: 実際のデータベース統合時にこのin-memory実装を置き換える（(subscription_id, event_id)に再送を除く一意インデックスを張る）
+type WebhookDeliveryRepositoryImpl struct {
+	// deliveries は保存した順（古い順）に並ぶ
+	deliveries []*model.WebhookDelivery
+	index      map[string]int
+	// originals は購読とイベントの組ごとの再送でない配信のID
+	originals map[webhookDeliveryKey]string
+	mutex     sync.RWMutex
+}
+
+// webhookDeliveryKey は同じイベントの重複した配信を判定するキー
+type webhookDeliveryKey struct {
+	subscriptionID string
+	eventID        string
+}
+
+// NewWebhookDeliveryRepository は新しいWebhookDeliveryRepositoryを作成する
+func NewWebhookDeliveryRepository() repository.WebhookDeliveryRepository {
+	return &WebhookDeliveryRepositoryImpl{
+		index:     make(map[string]int),
+		originals: make(map[webhookDeliveryKey]string),
+	}
+}
+
+// Save は配信を保存する（同じ購読への同じイベントの配信は保存しない）
+func (r *WebhookDeliveryRepositoryImpl) Save(ctx context.Context, delivery *model.WebhookDelivery) (bool, error) {
+	_, span := tracer.Start(ctx, "WebhookDeliveryRepository.Save")
+	defer span.End()
+
+	if delivery == nil {
+		return false, errors.New("delivery cannot be nil")
+	}
+	if delivery.ID == "" {
+		return false, errors.New("delivery ID cannot be empty")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.index[delivery.ID]; exists {
+		return false, errors.New("webhook delivery already exists")
+	}
+	key := webhookDeliveryKey{subscriptionID: delivery.SubscriptionID, eventID: delivery.EventID}
+	if delivery.ReplayOf == "" {
+		if _, exists := r.originals[key]; exists {
+			return false, nil
+		}
+		r.originals[key] = delivery.ID
+	}
+
+	stored := *delivery
+	r.index[stored.ID] = len(r.deliveries)
+	r.deliveries = append(r.deliveries, &stored)
+	return true, nil
+}
+
+// FindByID はIDで配信を検索する
+func (r *WebhookDeliveryRepositoryImpl) FindByID(ctx context.Context, id string) (*model.WebhookDelivery, error) {
+	_, span := tracer.Start(ctx, "WebhookDeliveryRepository.FindByID")
+	defer span.End()
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	position, exists := r.index[id]
+	if !exists {
+		return nil, repository.ErrWebhookDeliveryNotFound
+	}
+	copied := *r.deliveries[position]
+	return &copied, nil
+}
+
+// Update は配信の結果を更新する
+func (r *WebhookDeliveryRepositoryImpl) Update(ctx context.Context, delivery *model.WebhookDelivery) error {
+	_, span := tracer.Start(ctx, "WebhookDeliveryRepository.Update")
+	defer span.End()
+
+	if delivery == nil {
+		return errors.New("delivery cannot be nil")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	position, exists := r.index[delivery.ID]
+	if !exists {
+		return repository.ErrWebhookDeliveryNotFound
+	}
+	stored := *delivery
+	r.deliveries[position] = &stored
+	return nil
+}
+
+// FindDue は送信時刻を過ぎた配信待ちの配信を古い順に最大limit件返す
+func (r *WebhookDeliveryRepositoryImpl) FindDue(ctx context.Context, now time.Time, limit int) ([]*model.WebhookDelivery, error) {
+	_, span := tracer.Start(ctx, "WebhookDeliveryRepository.FindDue")
+	defer span.End()
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var due []*model.WebhookDelivery
+	for _, delivery := range r.deliveries {
+		if len(due) >= limit {
+			break
+		}
+		if delivery.Status != model.WebhookDeliveryPending || delivery.NextAttemptAt.After(now) {
+			continue
+		}
+		copied := *delivery
+		due = append(due, &copied)
+	}
+	return due, nil
+}
+
+// Query は検索条件に一致する配信を新しい順に返す
+func (r *WebhookDeliveryRepositoryImpl) Query(ctx context.Context, filter *model.WebhookDeliveryFilter) (*model.WebhookDeliveryPage, error) {
+	_, span := tracer.Start(ctx, "WebhookDeliveryRepository.Query")
+	defer span.End()
+
+	if filter == nil {
+		filter = &model.WebhookDeliveryFilter{}
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	start := len(r.deliveries) - 1
+	if filter.Cursor != "" {
+		position, exists := r.index[filter.Cursor]
+		if !exists {
+			return nil, repository.ErrInvalidCursor
+		}
+		start = position - 1
+	}
+
+	size := filter.PageSize()
+	page := &model.WebhookDeliveryPage{Deliveries: make([]*model.WebhookDelivery, 0, size)}
+	for i := start; i >= 0; i-- {
+		delivery := r.deliveries[i]
+		if !filter.Matches(delivery) {
+			continue
+		}
+		if len(page.Deliveries) == size {
+			page.NextCursor = page.Deliveries[size-1].ID
+			break
+		}
+		copied := *delivery
+		page.Deliveries = append(page.Deliveries, &copied)
+	}
+
+	return page, nil
+}
+
+// DeleteBySubscriptionID は購読の配信をすべて削除する
+func (r *WebhookDeliveryRepositoryImpl) DeleteBySubscriptionID(ctx context.Context, subscriptionID string) error {
+	_, span := tracer.Start(ctx, "WebhookDeliveryRepository.DeleteBySubscriptionID")
+	defer span.End()
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.deleteWhere(func(delivery *model.WebhookDelivery) bool {
+		return delivery.SubscriptionID == subscriptionID
+	})
+	return nil
+}
+
+// DeleteSucceededBefore はbeforeより前に成功した配信を削除し、削除した件数を返す
+// 削除した配信のイベントは重複の判定の対象外になる
+func (r *WebhookDeliveryRepositoryImpl) DeleteSucceededBefore(ctx context.Context, before time.Time) (int, error) {
+	_, span := tracer.Start(ctx, "WebhookDeliveryRepository.DeleteSucceededBefore")
+	defer span.End()
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.deleteWhere(func(delivery *model.WebhookDelivery) bool {
+		return delivery.Status == model.WebhookDeliverySucceeded &&
+			delivery.DeliveredAt != nil &&
+			delivery.DeliveredAt.Before(before)
+	}), nil
+}
+
+// DeleteByUserID はユーザーを対象とするイベントの配信を状態にかかわらず削除し、削除した件数を返す
+func (r *WebhookDeliveryRepositoryImpl) DeleteByUserID(ctx context.Context, userID string) (int, error) {
+	_, span := tracer.Start(ctx, "WebhookDeliveryRepository.DeleteByUserID")
+	defer span.End()
+
+	if userID == "" {
+		return 0, errors.New("userID cannot be empty")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.deleteWhere(func(delivery *model.WebhookDelivery) bool {
+		return delivery.UserID == userID
+	}), nil
+}
+
+// deleteWhere は条件に一致する配信を削除してインデックスを作り直し、削除した件数を返す（ロックを取得して呼ぶ）
+func (r *WebhookDeliveryRepositoryImpl) deleteWhere(match func(*model.WebhookDelivery) bool) int {
+	kept := r.deliveries[:0]
+	for _, delivery := range r.deliveries {
+		if !match(delivery) {
+			kept = append(kept, delivery)
+		}
+	}
+	deleted := len(r.deliveries) - len(kept)
+	clear(r.deliveries[len(kept):])
+	r.deliveries = kept
+
+	r.index = make(map[string]int, len(kept))
+	r.originals = make(map[webhookDeliveryKey]string, len(kept))
+	for i, delivery := range kept {
+		r.index[delivery.ID] = i
+		if delivery.ReplayOf == "" {
+			r.originals[webhookDeliveryKey{subscriptionID: delivery.SubscriptionID, eventID: delivery.EventID}] = delivery.ID
+		}
+	}
+	return deleted
+}
@@ -0,0 +1,203 @@
+package persistence
+
+import (
+	"context"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookRepositoryImpl(t *testing.T) {
+	repo := NewWebhookRepository()
+	ctx := context.Background()
+	now := time.Now()
+
+	second := &model.WebhookSubscription{ID: "wh_2", URL: "https://example.com/b", EventTypes: []model.DomainEventType{model.DomainEventUserLoggedIn}, Active: true, CreatedAt: now.Add(time.Second)}
+	first := &model.WebhookSubscription{ID: "wh_1", URL: "https://example.com/a", EventTypes: []model.DomainEventType{model.DomainEventUserRegistered}, Active: true, CreatedAt: now}
+	require.NoError(t, repo.Save(ctx, second))
+	require.NoError(t, repo.Save(ctx, first))
+
+	// 保存後の変更は保存済みの購読に影響しない
+	first.EventTypes[0] = model.DomainEventProfileUpdated
+	found, err := repo.FindByID(ctx, "wh_1")
+	require.NoError(t, err)
+	assert.Equal(t, []model.DomainEventType{model.DomainEventUserRegistered}, found.EventTypes)
+
+	all, err := repo.FindAll(ctx)
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, "wh_1", all[0].ID)
+	assert.Equal(t, "wh_2", all[1].ID)
+
+	found.Active = false
+	require.NoError(t, repo.Update(ctx, found))
+	found, err = repo.FindByID(ctx, "wh_1")
+	require.NoError(t, err)
+	assert.False(t, found.Active)
+
+	require.NoError(t, repo.Delete(ctx, "wh_1"))
+	_, err = repo.FindByID(ctx, "wh_1")
+	assert.ErrorIs(t, err, repository.ErrWebhookNotFound)
+	assert.ErrorIs(t, repo.Delete(ctx, "wh_1"), repository.ErrWebhookNotFound)
+	assert.ErrorIs(t, repo.Update(ctx, first), repository.ErrWebhookNotFound)
+	assert.Error(t, repo.Save(ctx, nil))
+}
+
+func TestWebhookDeliveryRepositoryImpl(t *testing.T) {
+	repo := NewWebhookDeliveryRepository()
+	ctx := context.Background()
+
+	subscription := &model.WebhookSubscription{ID: "wh_1"}
+	other := &model.WebhookSubscription{ID: "wh_2"}
+	event := model.NewDomainEvent(model.DomainEventUserRegistered, "user_1", "user_1", nil)
+	event.ID = "evt_1"
+
+	first := model.NewWebhookDelivery("del_1", subscription, event, []byte(`{}`))
+	saved, err := repo.Save(ctx, first)
+	require.NoError(t, err)
+	assert.True(t, saved)
+
+	// 同じ購読への同じイベントの配信は保存しない
+	saved, err = repo.Save(ctx, model.NewWebhookDelivery("del_2", subscription, event, []byte(`{}`)))
+	require.NoError(t, err)
+	assert.False(t, saved)
+
+	saved, err = repo.Save(ctx, model.NewWebhookDelivery("del_3", other, event, []byte(`{}`)))
+	require.NoError(t, err)
+	assert.True(t, saved)
+
+	// 再送は同じイベントでも保存する
+	saved, err = repo.Save(ctx, first.Replay("del_4"))
+	require.NoError(t, err)
+	assert.True(t, saved)
+
+	now := time.Now()
+	due, err := repo.FindDue(ctx, now, 10)
+	require.NoError(t, err)
+	require.Len(t, due, 3)
+	assert.Equal(t, "del_1", due[0].ID)
+
+	first.Fail(500, "server error", 3, time.Minute, now)
+	require.NoError(t, repo.Update(ctx, first))
+	due, err = repo.FindDue(ctx, now, 10)
+	require.NoError(t, err)
+	assert.Len(t, due, 2)
+	due, err = repo.FindDue(ctx, now.Add(time.Minute), 1)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, "del_1", due[0].ID)
+
+	page, err := repo.Query(ctx, &model.WebhookDeliveryFilter{SubscriptionID: "wh_1", Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, page.Deliveries, 1)
+	assert.Equal(t, "del_4", page.Deliveries[0].ID)
+	assert.Equal(t, "del_4", page.NextCursor)
+
+	page, err = repo.Query(ctx, &model.WebhookDeliveryFilter{SubscriptionID: "wh_1", Limit: 1, Cursor: page.NextCursor})
+	require.NoError(t, err)
+	require.Len(t, page.Deliveries, 1)
+	assert.Equal(t, "del_1", page.Deliveries[0].ID)
+	assert.Equal(t, 1, page.Deliveries[0].Attempts)
+	assert.Empty(t, page.NextCursor)
+
+	_, err = repo.Query(ctx, &model.WebhookDeliveryFilter{Cursor: "unknown"})
+	assert.ErrorIs(t, err, repository.ErrInvalidCursor)
+
+	require.NoError(t, repo.DeleteBySubscriptionID(ctx, "wh_1"))
+	_, err = repo.FindByID(ctx, "del_1")
+	assert.ErrorIs(t, err, repository.ErrWebhookDeliveryNotFound)
+	found, err := repo.FindByID(ctx, "del_3")
+	require.NoError(t, err)
+	assert.Equal(t, "wh_2", found.SubscriptionID)
+	assert.ErrorIs(t, repo.Update(ctx, first), repository.ErrWebhookDeliveryNotFound)
+
+	// 削除した購読の配信は重複の判定の対象外になる
+	saved, err = repo.Save(ctx, model.NewWebhookDelivery("del_5", subscription, event, []byte(`{}`)))
+	require.NoError(t, err)
+	assert.True(t, saved)
+}
+
+func TestWebhookDeliveryRepositoryImpl_DeleteSucceededBefore(t *testing.T) {
+	repo := NewWebhookDeliveryRepository()
+	ctx := context.Background()
+	subscription := &model.WebhookSubscription{ID: "wh_1"}
+	now := time.Now()
+
+	deliveries := map[string]*model.WebhookDelivery{}
+	for _, id := range []string{"old", "recent", "dead", "pending"} {
+		event := model.NewDomainEvent(model.DomainEventUserRegistered, "user_1", "user_1", nil)
+		event.ID = "evt_" + id
+		deliveries[id] = model.NewWebhookDelivery("del_"+id, subscription, event, []byte(`{}`))
+		saved, err := repo.Save(ctx, deliveries[id])
+		require.NoError(t, err)
+		require.True(t, saved)
+	}
+	deliveries["old"].Succeed(200, now.Add(-48*time.Hour))
+	deliveries["recent"].Succeed(200, now)
+	deliveries["dead"].Fail(500, "server error", 1, time.Minute, now.Add(-48*time.Hour))
+	for _, delivery := range deliveries {
+		require.NoError(t, repo.Update(ctx, delivery))
+	}
+
+	deleted, err := repo.DeleteSucceededBefore(ctx, now.Add(-24*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	// 成功していない配信は期間を過ぎても再送できるよう残す
+	_, err = repo.FindByID(ctx, "del_old")
+	assert.ErrorIs(t, err, repository.ErrWebhookDeliveryNotFound)
+	for _, id := range []string{"del_recent", "del_dead", "del_pending"} {
+		_, err := repo.FindByID(ctx, id)
+		assert.NoError(t, err, id)
+	}
+
+	// 残った配信は引き続き重複を判定する
+	event := model.NewDomainEvent(model.DomainEventUserRegistered, "user_1", "user_1", nil)
+	event.ID = "evt_recent"
+	saved, err := repo.Save(ctx, model.NewWebhookDelivery("del_again", subscription, event, []byte(`{}`)))
+	require.NoError(t, err)
+	assert.False(t, saved)
+}
+
+func TestWebhookDeliveryRepositoryImpl_DeleteByUserID(t *testing.T) {
+	repo := NewWebhookDeliveryRepository()
+	ctx := context.Background()
+	subscription := &model.WebhookSubscription{ID: "wh_1"}
+	now := time.Now()
+
+	for _, target := range []struct{ id, userID string }{
+		{id: "user_1_pending", userID: "user_1"},
+		{id: "user_1_dead", userID: "user_1"},
+		{id: "user_2", userID: "user_2"},
+	} {
+		event := model.NewDomainEvent(model.DomainEventUserRegistered, target.userID, target.userID, nil)
+		event.ID = "evt_" + target.id
+		saved, err := repo.Save(ctx, model.NewWebhookDelivery("del_"+target.id, subscription, event, []byte(`{}`)))
+		require.NoError(t, err)
+		require.True(t, saved)
+	}
+	dead, err := repo.FindByID(ctx, "del_user_1_dead")
+	require.NoError(t, err)
+	dead.Fail(500, "server error", 1, time.Minute, now)
+	require.NoError(t, repo.Update(ctx, dead))
+	_, err = repo.Save(ctx, dead.Replay("del_user_1_replay"))
+	require.NoError(t, err)
+
+	// 状態や再送にかかわらずユーザーの配信をすべて削除する
+	deleted, err := repo.DeleteByUserID(ctx, "user_1")
+	require.NoError(t, err)
+	assert.Equal(t, 3, deleted)
+	for _, id := range []string{"del_user_1_pending", "del_user_1_dead", "del_user_1_replay"} {
+		_, err := repo.FindByID(ctx, id)
+		assert.ErrorIs(t, err, repository.ErrWebhookDeliveryNotFound, id)
+	}
+	_, err = repo.FindByID(ctx, "del_user_2")
+	assert.NoError(t, err)
+
+	_, err = repo.DeleteByUserID(ctx, "")
+	assert.Error(t, err)
+}
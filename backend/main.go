@@ -1,71 +1,109 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
-	"net/http"
+	"log/slog"
 	"os"
-	"stackies-backend/infra/external"
-	"stackies-backend/infra/persistence"
-	"stackies-backend/presentation/handler"
-	authMiddleware "stackies-backend/presentation/middleware"
+	"os/signal"
+	"stackies-backend/core/config"
+	"stackies-backend/core/logger"
+	"stackies-backend/presentation/cli"
+	"stackies-backend/presentation/server"
 	"stackies-backend/registry"
-	"stackies-backend/usecase"
-
-	"github.com/joho/godotenv"
-	"github.com/labstack/echo/v4"
-	"github.com/labstack/echo/v4/middleware"
+	"syscall"
 )
 
 func main() {
-	// 環境変数を読み込み
-	if err := godotenv.Load(); err != nil {
-		log.Println("Warning: .env file not found, using system environment variables")
+	// 開発用のサブコマンドは設定を読み込まずに実行する
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "fake-idp":
+			slog.SetDefault(logger.New(os.Stdout, slog.LevelInfo))
+			if err := runFakeIdP(os.Args[2:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		case "openapi-ts":
+			if err := runOpenAPITypeScript(os.Args[2:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		case "help", "-h", "-help", "--help":
+			cli.New(nil, os.Stdout, os.Stderr).Usage()
+			return
+		}
 	}
 
-	e := echo.New()
-
-	e.Use(middleware.Logger())
-	e.Use(middleware.Recover())
-
-	// CORS設定を追加
-	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
-		AllowOrigins: []string{"http://localhost:3000", "http://localhost:5173"},
-		AllowMethods: []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete, http.MethodOptions},
-		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization},
-	}))
+	// 設定を読み込み（環境変数・.env・CONFIG_FILE）、不正な場合は起動しない
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	container := registry.NewContainer()
-	userRepo := persistence.NewUserRepository()
-	authRepo := persistence.NewAuthRepository()
-	authUsecase := usecase.NewAuthUsecase(userRepo, authRepo, container.GetGoogleService(), container.GetJWTService())
-	googleSvc := external.NewGoogleService(os.Getenv("GOOGLE_CLIENT_ID"), os.Getenv("GOOGLE_CLIENT_SECRET"))
-	jwtSvc := external.NewJWTService(os.Getenv("JWT_SECRET"))
-	authMiddleware := authMiddleware.NewAuthMiddleware(jwtSvc)
+	// SIGINT・SIGTERMで処理中のリクエスト・コマンドを完了させてから停止する
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	container.SetGoogleService(googleSvc)
-	container.SetJWTService(jwtSvc)
+	// サブコマンドを省略した場合はサーバーを起動する
+	if len(os.Args) < 2 || os.Args[1] == "serve" {
+		if err := runServe(ctx, cfg); err != nil {
+			slog.Error("server stopped with error", slog.Any("error", err))
+			os.Exit(1)
+		}
+		return
+	}
 
-	authHandler := handler.NewAuthHandler(authUsecase, userRepo, googleSvc)
+	if err := runCLI(ctx, cfg, os.Args[1:]); err != nil {
+		if errors.Is(err, cli.ErrUsage) {
+			os.Exit(2)
+		}
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
 
-	e.GET("/health", healthCheck)
-	e.GET("/auth/google/url", authHandler.GoogleAuthURL)
-	e.POST("/auth/google/login", authHandler.GoogleLogin)
-	e.POST("/auth/refresh", authHandler.RefreshToken)
-	e.POST("/auth/logout", authHandler.Logout)
-	e.GET("/auth/me", authHandler.GetMe, authMiddleware.Authenticate)
+// runServe はAPIサーバーを起動し、ctxがキャンセルされるまで待つ（serveサブコマンド）
+func runServe(ctx context.Context, cfg *config.Config) error {
+	// 構造化ログ（JSON）。標準のlogパッケージの出力もこのロガーに流れる
+	logLevel, _ := logger.ParseLevel(cfg.Log.Level)
+	appLogger := logger.New(os.Stdout, logLevel)
+	slog.SetDefault(appLogger)
 
-	// ポート設定（環境変数から取得、デフォルトは8080）
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	container := registry.NewContainer(cfg)
+	if err := container.Build(); err != nil {
+		return err
 	}
+	if err := container.Start(ctx); err != nil {
+		return err
+	}
+
+	srv := server.New(cfg.Server, container, appLogger)
+	// リクエストの処理が完了した後にリポジトリ・外部接続を閉じる
+	srv.OnShutdown("registry", container.Stop)
 
-	e.Logger.Fatal(e.Start(":" + port))
+	slog.Info("starting server", slog.String("environment", string(cfg.Environment)), slog.Int("port", cfg.Server.Port))
+	return srv.Run(ctx)
 }
 
-func healthCheck(c echo.Context) error {
-	return c.JSON(http.StatusOK, map[string]string{
-		"status":  "OK",
-		"message": "Server is running",
-	})
+// runCLI はサーバーと同じレジストリを構築し、運用者向けの管理コマンドを実行する
+func runCLI(ctx context.Context, cfg *config.Config, args []string) (err error) {
+	// コマンドの出力と混ざらないよう、ログは標準エラー出力に出す
+	logLevel, _ := logger.ParseLevel(cfg.Log.Level)
+	slog.SetDefault(logger.New(os.Stderr, logLevel))
+
+	container := registry.NewContainer(cfg)
+	if err := container.Build(); err != nil {
+		return err
+	}
+	if err := container.Start(ctx); err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, container.Stop(context.WithoutCancel(ctx)))
+	}()
+
+	return cli.New(container, os.Stdout, os.Stderr).Run(ctx, args)
 }
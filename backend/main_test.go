@@ -1,37 +0,0 @@
-package main
-
-import (
-	"net/http"
-	"net/http/httptest"
-	"testing"
-
-	"github.com/labstack/echo/v4"
-	"github.com/stretchr/testify/assert"
-)
-
-func TestHealthCheck(t *testing.T) {
-	e := echo.New()
-	req := httptest.NewRequest(http.MethodGet, "/health", nil)
-	rec := httptest.NewRecorder()
-	c := e.NewContext(req, rec)
-
-	if assert.NoError(t, healthCheck(c)) {
-		assert.Equal(t, http.StatusOK, rec.Code)
-		assert.Contains(t, rec.Body.String(), "OK")
-		assert.Contains(t, rec.Body.String(), "Server is running")
-	}
-}
-
-func TestHealthCheckIntegration(t *testing.T) {
-	e := echo.New()
-	e.GET("/health", healthCheck)
-
-	req := httptest.NewRequest(http.MethodGet, "/health", nil)
-	rec := httptest.NewRecorder()
-
-	e.ServeHTTP(rec, req)
-
-	assert.Equal(t, http.StatusOK, rec.Code)
-	assert.Contains(t, rec.Body.String(), `"status":"OK"`)
-	assert.Contains(t, rec.Body.String(), `"message":"Server is running"`)
-}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"stackies-backend/presentation/openapi"
+)
+
+// defaultTypeScriptPath はフロントエンドの生成済みの型のパス（backendディレクトリからの相対パス）
+const defaultTypeScriptPath = "../frontend/src/shared/api/schema.gen.ts"
+
+// runOpenAPITypeScript はOpenAPIドキュメントからフロントエンドの型を生成する（openapi-tsサブコマンド）
+func runOpenAPITypeScript(args []string) error {
+	flags := flag.NewFlagSet("openapi-ts", flag.ContinueOnError)
+	output := flags.String("o", defaultTypeScriptPath, "output file")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	spec, err := openapi.Load()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(*output, spec.TypeScript(), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", *output, err)
+	}
+	return nil
+}
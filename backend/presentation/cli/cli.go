@@ -0,0 +1,153 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"stackies-backend/core/config"
+	"stackies-backend/domain/service"
+	"stackies-backend/usecase"
+)
+
+// userAgent はCLIからの操作を監査ログに記録する際のユーザーエージェント
+const userAgent = "stackies-cli"
+
+var (
+	// ErrUsage はサブコマンドや引数が不正な場合のエラー（使い方を表示して終了コード2で終了する）
+	ErrUsage = errors.New("invalid usage")
+	// ErrNoSharedStorage はリポジトリが稼働中のサーバーとデータを共有しない構成で、ユーザー・セッションを操作しようとした場合のエラー
+	// 操作してもCLIのプロセス内のデータが変わるだけのため、成功として扱わない
+	ErrNoSharedStorage = errors.New("this command needs a data store shared with the running server, but the repositories are in-memory; use the admin API instead")
+	// ErrNoMigrations はマイグレーションが1件も定義されていない状態で適用・取り消しをしようとした場合のエラー
+	ErrNoMigrations = errors.New("no migrations are defined for the configured data store")
+)
+
+// usage はサブコマンドの一覧
+const usage = `Usage: stackies-backend <command> [arguments]
+
+Commands:
+  serve                                  start the API server (default)
+  migrate up                             apply all pending migrations
+  migrate down [-steps n]                roll back the latest migrations
+  migrate status                         show applied and pending migrations
+  user list                              list users (requires a shared data store)
+  user show <id>                         show a user as JSON
+  user disable [-reason text] <id>       disable a user and revoke their sessions
+  user suspend [-reason t] [-for d] <id> suspend a user (until reactivated or for d) and revoke their sessions
+  user activate <id>                     reactivate a suspended or disabled user
+  user grant-role <id> <role>            change a user's role (user or admin)
+  sessions revoke -user <id>             revoke all sessions of a user (requires a shared data store)
+  keys rotate [-keep n]                  generate a new JWT secret
+  token inspect <jwt>                    decode a JWT and verify it with the configured keys
+  fake-idp                               start the fake OAuth/OIDC provider
+  openapi-ts                             generate the frontend types from the OpenAPI document
+`
+
+// Components はCLIが使うユースケース・サービスを提供する（registry.Containerが実装する）
+type Components interface {
+	Config() *config.Config
+	GetAdminUsecase() usecase.AdminUsecase
+	GetJWTService() service.JWTService
+	GetMigrator() service.Migrator
+	// HasSharedStorage はリポジトリが稼働中のサーバーと同じデータストアを使うかどうかを返す
+	HasSharedStorage() bool
+}
+
+// CLI は運用者向けの管理コマンドを表す
+// ユーザーの操作はシステムによる操作として監査ログに記録する
+type CLI struct {
+	components Components
+	stdout     io.Writer
+	stderr     io.Writer
+}
+
+// New は新しいCLIを作成する（結果はstdoutへ、使い方とエラーはstderrへ出力する）
+func New(components Components, stdout, stderr io.Writer) *CLI {
+	return &CLI{
+		components: components,
+		stdout:     stdout,
+		stderr:     stderr,
+	}
+}
+
+// Usage は使い方を出力する
+func (c *CLI) Usage() {
+	fmt.Fprint(c.stderr, usage)
+}
+
+// Run はサブコマンドを実行する（argsはサブコマンド名から始まる）
+func (c *CLI) Run(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return c.usageError("missing command")
+	}
+
+	switch args[0] {
+	case "migrate":
+		return c.runMigrate(ctx, args[1:])
+	case "user":
+		if err := c.requireSharedStorage(); err != nil {
+			return err
+		}
+		return c.runUser(ctx, args[1:])
+	case "sessions":
+		if err := c.requireSharedStorage(); err != nil {
+			return err
+		}
+		return c.runSessions(ctx, args[1:])
+	case "keys":
+		return c.runKeys(args[1:])
+	case "token":
+		return c.runToken(args[1:])
+	case "help", "-h", "-help", "--help":
+		c.Usage()
+		return nil
+	default:
+		return c.usageError(fmt.Sprintf("unknown command: %s", args[0]))
+	}
+}
+
+// requireSharedStorage はリポジトリが稼働中のサーバーとデータを共有しているかを確認する
+func (c *CLI) requireSharedStorage() error {
+	if !c.components.HasSharedStorage() {
+		return ErrNoSharedStorage
+	}
+	return nil
+}
+
+// usageError はエラーと使い方を出力し、ErrUsageを返す
+func (c *CLI) usageError(message string) error {
+	fmt.Fprintln(c.stderr, message)
+	c.Usage()
+	return ErrUsage
+}
+
+// newFlagSet はサブコマンドのフラグを作成する（エラーはstderrへ出力する）
+func (c *CLI) newFlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(c.stderr)
+	return flags
+}
+
+// parseFlags はフラグを解析し、残りの引数がwant個であることを確認する
+func (c *CLI) parseFlags(flags *flag.FlagSet, args []string, want int) ([]string, error) {
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil, ErrUsage
+		}
+		return nil, c.usageError(err.Error())
+	}
+	if flags.NArg() != want {
+		return nil, c.usageError(fmt.Sprintf("%s: expected %d argument(s), got %d", flags.Name(), want, flags.NArg()))
+	}
+	return flags.Args(), nil
+}
+
+// writeJSON は値をインデントしたJSONで出力する
+func (c *CLI) writeJSON(v any) error {
+	encoder := json.NewEncoder(c.stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
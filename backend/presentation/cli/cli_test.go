@@ -0,0 +1,336 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"stackies-backend/core/config"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/repository"
+	"stackies-backend/domain/service"
+	"stackies-backend/infra/external"
+	"stackies-backend/infra/persistence"
+	"stackies-backend/usecase"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testComponents はメモリ上のリポジトリでCLIの依存を提供する
+type testComponents struct {
+	cfg       *config.Config
+	userRepo  repository.UserRepository
+	authRepo  repository.AuthRepository
+	auditRepo repository.AuditRepository
+	jwtSvc    service.JWTService
+	migrator  service.Migrator
+	// sharedStorage はリポジトリを稼働中のサーバーと共有しているとみなすかどうか
+	sharedStorage bool
+}
+
+func newTestComponents(t *testing.T) *testComponents {
+	t.Helper()
+
+	cfg := &config.Config{}
+	cfg.Auth.JWTSecret = "current-secret"
+	cfg.Auth.JWTPreviousSecrets = []string{"previous-secret"}
+
+	migrator, err := persistence.NewMigrator(nil)
+	require.NoError(t, err)
+
+	components := &testComponents{
+		cfg:       cfg,
+		userRepo:  persistence.NewUserRepository(),
+		authRepo:  persistence.NewAuthRepository(),
+		auditRepo: persistence.NewAuditRepository(),
+		jwtSvc:    external.NewJWTService(cfg.Auth.JWTSecret, cfg.Auth.JWTPreviousSecrets...),
+		migrator:  migrator,
+		// テストではCLIとリポジトリを共有してコマンドの結果を確認する
+		sharedStorage: true,
+	}
+
+	now := time.Now()
+	for i, user := range []*model.User{
+		{ID: "user_1", Email: "alice@example.com", Name: "Alice", Role: model.RoleUser, CreatedAt: now},
+		{ID: "user_2", Email: "bob@example.com", Name: "Bob", Role: model.RoleAdmin, CreatedAt: now.Add(time.Minute)},
+	} {
+		require.NoError(t, components.userRepo.Save(context.Background(), user), i)
+	}
+	token, err := model.NewAuthToken("access", "refresh", now.Add(time.Hour).Unix(), "Bearer")
+	require.NoError(t, err)
+	require.NoError(t, components.authRepo.SaveToken(context.Background(), "user_1", token))
+
+	return components
+}
+
+func (c *testComponents) Config() *config.Config { return c.cfg }
+
+func (c *testComponents) GetAdminUsecase() usecase.AdminUsecase {
+	return usecase.NewAdminUsecase(c.userRepo, c.authRepo, c.auditRepo, nil, c.jwtSvc, c.cfg.Auth.ImpersonationTTL)
+}
+
+func (c *testComponents) GetJWTService() service.JWTService { return c.jwtSvc }
+
+func (c *testComponents) GetMigrator() service.Migrator { return c.migrator }
+
+func (c *testComponents) HasSharedStorage() bool { return c.sharedStorage }
+
+// run はCLIを実行し、標準出力の内容を返す
+func run(components *testComponents, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	err := New(components, &stdout, &stderr).Run(context.Background(), args)
+	return stdout.String(), err
+}
+
+func TestCLI_Usage(t *testing.T) {
+	tests := []struct {
+		testName string
+		args     []string
+	}{
+		{testName: "コマンドなし", args: nil},
+		{testName: "不明なコマンド", args: []string{"unknown"}},
+		{testName: "不明なサブコマンド", args: []string{"user", "delete", "user_1"}},
+		{testName: "引数の不足", args: []string{"user", "show"}},
+		{testName: "必須のフラグがない", args: []string{"sessions", "revoke"}},
+		{testName: "不正なフラグ", args: []string{"migrate", "down", "-steps", "0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			_, err := run(newTestComponents(t), tt.args...)
+			assert.ErrorIs(t, err, ErrUsage)
+		})
+	}
+}
+
+func TestCLI_User(t *testing.T) {
+	components := newTestComponents(t)
+
+	out, err := run(components, "user", "list")
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 3)
+	assert.Contains(t, lines[1], "alice@example.com")
+	assert.Contains(t, lines[1], "active")
+	assert.Contains(t, lines[2], "bob@example.com")
+
+	out, err = run(components, "user", "show", "user_1")
+	require.NoError(t, err)
+	var shown model.User
+	require.NoError(t, json.Unmarshal([]byte(out), &shown))
+	assert.Equal(t, "alice@example.com", shown.Email)
+
+	_, err = run(components, "user", "show", "unknown")
+	assert.ErrorIs(t, err, repository.ErrUserNotFound)
+
+	out, err = run(components, "user", "grant-role", "user_1", "admin")
+	require.NoError(t, err)
+	assert.Contains(t, out, "now has role admin")
+
+	_, err = run(components, "user", "grant-role", "user_1", "owner")
+	assert.ErrorIs(t, err, usecase.ErrInvalidRole)
+
+	out, err = run(components, "user", "disable", "-reason", "left the company", "user_1")
+	require.NoError(t, err)
+	assert.Contains(t, out, "user user_1 (alice@example.com) is now disabled")
+
+	// 無効化したユーザーはセッションも無効化される
+	user, _ := components.userRepo.FindByID(context.Background(), "user_1")
+	assert.Equal(t, model.UserStatusDisabled, user.Status)
+	_, err = components.authRepo.GetToken(context.Background(), "user_1")
+	assert.ErrorIs(t, err, repository.ErrTokenNotFound)
+
+	page, err := components.auditRepo.Query(context.Background(), &model.AuditEventFilter{Type: model.AuditEventUserStatusChange, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, page.Events, 1)
+	assert.Equal(t, model.SystemActorID, page.Events[0].ActorID)
+	assert.Equal(t, "left the company", page.Events[0].Reason)
+	assert.Equal(t, userAgent, page.Events[0].UserAgent)
+
+	// 無効化したユーザーは一時停止できない
+	_, err = run(components, "user", "suspend", "user_1")
+	assert.ErrorIs(t, err, usecase.ErrInvalidStatusTransition)
+
+	out, err = run(components, "user", "activate", "user_1")
+	require.NoError(t, err)
+	assert.Contains(t, out, "is now active")
+
+	out, err = run(components, "user", "suspend", "-reason", "spam", "-for", "24h", "user_1")
+	require.NoError(t, err)
+	assert.Contains(t, out, "is now suspended")
+	user, _ = components.userRepo.FindByID(context.Background(), "user_1")
+	require.NotNil(t, user.SuspendedUntil)
+	assert.WithinDuration(t, time.Now().Add(24*time.Hour), *user.SuspendedUntil, time.Minute)
+
+	out, err = run(components, "user", "list")
+	require.NoError(t, err)
+	assert.Contains(t, out, "suspended")
+
+	_, err = run(components, "user", "suspend", "-for", "-1h", "user_1")
+	assert.ErrorIs(t, err, ErrUsage)
+}
+
+func TestCLI_SessionsRevoke(t *testing.T) {
+	components := newTestComponents(t)
+
+	out, err := run(components, "sessions", "revoke", "--user", "user_1")
+	require.NoError(t, err)
+	assert.Contains(t, out, "revoked all sessions of user user_1")
+
+	_, err = components.authRepo.GetToken(context.Background(), "user_1")
+	assert.ErrorIs(t, err, repository.ErrTokenNotFound)
+
+	_, err = run(components, "sessions", "revoke", "-user", "unknown")
+	assert.ErrorIs(t, err, repository.ErrUserNotFound)
+}
+
+func TestCLI_NoSharedStorage(t *testing.T) {
+	tests := []struct {
+		testName string
+		args     []string
+	}{
+		{testName: "ユーザーの一覧", args: []string{"user", "list"}},
+		{testName: "ユーザーの一時停止", args: []string{"user", "suspend", "user_1"}},
+		{testName: "セッションの無効化", args: []string{"sessions", "revoke", "-user", "user_1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			components := newTestComponents(t)
+			components.sharedStorage = false
+
+			out, err := run(components, tt.args...)
+
+			// 稼働中のサーバーに反映されない操作を成功として報告しない
+			assert.ErrorIs(t, err, ErrNoSharedStorage)
+			assert.Empty(t, out)
+			user, _ := components.userRepo.FindByID(context.Background(), "user_1")
+			assert.Equal(t, model.UserStatus(""), user.Status)
+			_, err = components.authRepo.GetToken(context.Background(), "user_1")
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestCLI_KeysRotate(t *testing.T) {
+	tests := []struct {
+		testName     string
+		args         []string
+		wantPrevious string
+	}{
+		{
+			testName:     "現在の鍵をローテーション前の鍵として残す",
+			args:         []string{"keys", "rotate"},
+			wantPrevious: "JWT_PREVIOUS_SECRETS=current-secret",
+		},
+		{
+			testName:     "ローテーション前の鍵を複数残す",
+			args:         []string{"keys", "rotate", "-keep", "5"},
+			wantPrevious: "JWT_PREVIOUS_SECRETS=current-secret,previous-secret",
+		},
+		{
+			testName:     "ローテーション前の鍵を残さない",
+			args:         []string{"keys", "rotate", "-keep", "0"},
+			wantPrevious: "JWT_PREVIOUS_SECRETS=",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			out, err := run(newTestComponents(t), tt.args...)
+			require.NoError(t, err)
+
+			var secret string
+			for _, line := range strings.Split(out, "\n") {
+				if value, ok := strings.CutPrefix(line, "JWT_SECRET="); ok {
+					secret = value
+				}
+			}
+			assert.GreaterOrEqual(t, len(secret), 32)
+			assert.NotEqual(t, "current-secret", secret)
+			assert.Contains(t, out, tt.wantPrevious+"\n")
+		})
+	}
+}
+
+func TestCLI_TokenInspect(t *testing.T) {
+	components := newTestComponents(t)
+	previousToken, err := external.NewJWTService("previous-secret").GenerateToken("user_1")
+	require.NoError(t, err)
+	unknownToken, err := external.NewJWTService("unknown-secret").GenerateToken("user_1")
+	require.NoError(t, err)
+
+	tests := []struct {
+		testName  string
+		token     string
+		wantValid bool
+	}{
+		{testName: "ローテーション前の鍵で署名したトークン", token: previousToken, wantValid: true},
+		{testName: "設定にない鍵で署名したトークン", token: unknownToken, wantValid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			out, err := run(components, "token", "inspect", tt.token)
+			require.NoError(t, err)
+
+			var inspection tokenInspection
+			require.NoError(t, json.Unmarshal([]byte(out), &inspection))
+			assert.Equal(t, tt.wantValid, inspection.Valid)
+			assert.Equal(t, tt.wantValid, inspection.Error == "")
+			assert.Equal(t, "user_1", inspection.Claims["user_id"])
+			assert.Equal(t, "HS256", inspection.Header["alg"])
+			assert.False(t, inspection.Expired)
+			assert.NotNil(t, inspection.ExpiresAt)
+		})
+	}
+
+	_, err = run(components, "token", "inspect", "not-a-jwt")
+	assert.ErrorContains(t, err, "failed to decode token")
+}
+
+func TestCLI_Migrate(t *testing.T) {
+	components := newTestComponents(t)
+
+	out, err := run(components, "migrate", "status")
+	require.NoError(t, err)
+	assert.Equal(t, "no migrations defined\n", out)
+
+	// マイグレーションがない場合の適用・取り消しは成功として報告しない
+	out, err = run(components, "migrate", "up")
+	assert.ErrorIs(t, err, ErrNoMigrations)
+	assert.Empty(t, out)
+	_, err = run(components, "migrate", "down")
+	assert.ErrorIs(t, err, ErrNoMigrations)
+
+	var applied bool
+	migrator, err := persistence.NewMigrator([]persistence.Migration{{
+		Version: 1,
+		Name:    "create_users",
+		Up:      func(ctx context.Context) error { applied = true; return nil },
+		Down:    func(ctx context.Context) error { applied = false; return nil },
+	}})
+	require.NoError(t, err)
+	components.migrator = migrator
+
+	out, err = run(components, "migrate", "status")
+	require.NoError(t, err)
+	assert.Contains(t, out, "create_users  pending")
+
+	out, err = run(components, "migrate", "up")
+	require.NoError(t, err)
+	assert.Equal(t, "applied 1 create_users\n", out)
+	assert.True(t, applied)
+
+	out, err = run(components, "migrate", "up")
+	require.NoError(t, err)
+	assert.Equal(t, "no migrations applied\n", out)
+
+	out, err = run(components, "migrate", "down")
+	require.NoError(t, err)
+	assert.Equal(t, "reverted 1 create_users\n", out)
+	assert.False(t, applied)
+}
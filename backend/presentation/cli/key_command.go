@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// secretSize はkeys rotateで生成するJWTシークレットのバイト数（HS256の鍵長）
+const secretSize = 32
+
+// runKeys はkeysサブコマンドを実行する
+// 設定は環境変数・設定ファイルで管理するため、新しい鍵と更新後の設定値を出力し、反映は運用者が行う
+func (c *CLI) runKeys(args []string) error {
+	if len(args) == 0 || args[0] != "rotate" {
+		return c.usageError("keys: expected subcommand rotate")
+	}
+
+	flags := c.newFlagSet("keys rotate")
+	keep := flags.Int("keep", 1, "number of previous secrets kept for verifying issued tokens")
+	if _, err := c.parseFlags(flags, args[1:], 0); err != nil {
+		return err
+	}
+	if *keep < 0 {
+		return c.usageError("keys rotate: -keep must not be negative")
+	}
+
+	secret, err := generateSecret()
+	if err != nil {
+		return err
+	}
+
+	// 現在の鍵を先頭に、新しい順で保持する
+	auth := c.components.Config().Auth
+	previous := append([]string{auth.JWTSecret}, auth.JWTPreviousSecrets...)
+	previous = previous[:min(*keep, len(previous))]
+
+	fmt.Fprintln(c.stdout, "# Update the configuration with the following values and restart every server.")
+	fmt.Fprintln(c.stdout, "# Keep the previous secrets until the refresh tokens signed with them expire (30 days).")
+	fmt.Fprintf(c.stdout, "JWT_SECRET=%s\n", secret)
+	fmt.Fprintf(c.stdout, "JWT_PREVIOUS_SECRETS=%s\n", strings.Join(previous, ","))
+	return nil
+}
+
+// generateSecret はランダムなJWTシークレットを生成する
+func generateSecret() (string, error) {
+	b := make([]byte, secretSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
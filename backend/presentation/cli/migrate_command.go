@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/service"
+	"text/tabwriter"
+	"time"
+)
+
+// runMigrate はmigrateサブコマンドを実行する
+func (c *CLI) runMigrate(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return c.usageError("migrate: missing subcommand")
+	}
+
+	migrator := c.components.GetMigrator()
+	switch args[0] {
+	case "up":
+		if _, err := c.parseFlags(c.newFlagSet("migrate up"), args[1:], 0); err != nil {
+			return err
+		}
+		if err := requireMigrations(ctx, migrator); err != nil {
+			return err
+		}
+		applied, err := migrator.Up(ctx)
+		c.printMigrations("applied", applied)
+		return err
+	case "down":
+		flags := c.newFlagSet("migrate down")
+		steps := flags.Int("steps", 1, "number of migrations to roll back")
+		if _, err := c.parseFlags(flags, args[1:], 0); err != nil {
+			return err
+		}
+		if *steps <= 0 {
+			return c.usageError("migrate down: -steps must be positive")
+		}
+		if err := requireMigrations(ctx, migrator); err != nil {
+			return err
+		}
+		reverted, err := migrator.Down(ctx, *steps)
+		c.printMigrations("reverted", reverted)
+		return err
+	case "status":
+		if _, err := c.parseFlags(c.newFlagSet("migrate status"), args[1:], 0); err != nil {
+			return err
+		}
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		return c.printMigrationStatus(statuses)
+	default:
+		return c.usageError(fmt.Sprintf("migrate: unknown subcommand: %s", args[0]))
+	}
+}
+
+// requireMigrations はマイグレーションが定義されていることを確認する
+// 定義がない場合に「適用なし」と出力して成功すると、スキーマが最新であると誤解させるためエラーにする
+func requireMigrations(ctx context.Context, migrator service.Migrator) error {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+	if len(statuses) == 0 {
+		return ErrNoMigrations
+	}
+	return nil
+}
+
+// printMigrations は適用・取り消したマイグレーションを出力する
+func (c *CLI) printMigrations(action string, migrations []*model.MigrationStatus) {
+	if len(migrations) == 0 {
+		fmt.Fprintf(c.stdout, "no migrations %s\n", action)
+		return
+	}
+	for _, migration := range migrations {
+		fmt.Fprintf(c.stdout, "%s %d %s\n", action, migration.Version, migration.Name)
+	}
+}
+
+// printMigrationStatus はマイグレーションの適用状況を表形式で出力する
+func (c *CLI) printMigrationStatus(statuses []*model.MigrationStatus) error {
+	if len(statuses) == 0 {
+		fmt.Fprintln(c.stdout, "no migrations defined")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED")
+	for _, status := range statuses {
+		applied := "pending"
+		if status.IsApplied() {
+			applied = status.AppliedAt.UTC().Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\n", status.Version, status.Name, applied)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"stackies-backend/domain/model"
+	"stackies-backend/usecase"
+)
+
+// runSessions はsessionsサブコマンドを実行する
+func (c *CLI) runSessions(ctx context.Context, args []string) error {
+	if len(args) == 0 || args[0] != "revoke" {
+		return c.usageError("sessions: expected subcommand revoke")
+	}
+
+	flags := c.newFlagSet("sessions revoke")
+	userID := flags.String("user", "", "ID of the user whose sessions are revoked (required)")
+	reason := flags.String("reason", "revoked via cli", "reason recorded in the audit log")
+	if _, err := c.parseFlags(flags, args[1:], 0); err != nil {
+		return err
+	}
+	if *userID == "" {
+		return c.usageError("sessions revoke: -user is required")
+	}
+
+	err := c.components.GetAdminUsecase().RevokeSessions(ctx, &usecase.RevokeSessionsInput{
+		ActorID:   model.SystemActorID,
+		UserID:    *userID,
+		Reason:    *reason,
+		UserAgent: userAgent,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stdout, "revoked all sessions of user %s\n", *userID)
+	return nil
+}
@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// tokenInspection はtoken inspectの出力を表す
+type tokenInspection struct {
+	Header    map[string]any `json:"header"`
+	Claims    map[string]any `json:"claims"`
+	IssuedAt  *time.Time     `json:"issuedAt,omitempty"`
+	ExpiresAt *time.Time     `json:"expiresAt,omitempty"`
+	Expired   bool           `json:"expired"`
+	// Valid は設定された鍵で署名を検証でき、期限内の場合にtrue
+	Valid bool   `json:"valid"`
+	Error string `json:"error,omitempty"`
+}
+
+// runToken はtokenサブコマンドを実行する
+// トークンが無効な場合もデコードした内容を出力し、理由をerrorに含める
+func (c *CLI) runToken(args []string) error {
+	if len(args) == 0 || args[0] != "inspect" {
+		return c.usageError("token: expected subcommand inspect")
+	}
+
+	rest, err := c.parseFlags(c.newFlagSet("token inspect"), args[1:], 1)
+	if err != nil {
+		return err
+	}
+
+	token, _, err := new(jwt.Parser).ParseUnverified(rest[0], jwt.MapClaims{})
+	if err != nil {
+		return fmt.Errorf("failed to decode token: %w", err)
+	}
+	claims, _ := token.Claims.(jwt.MapClaims)
+
+	inspection := &tokenInspection{
+		Header:    token.Header,
+		Claims:    claims,
+		IssuedAt:  claimTime(claims, "iat"),
+		ExpiresAt: claimTime(claims, "exp"),
+	}
+	if inspection.ExpiresAt != nil {
+		inspection.Expired = time.Now().After(*inspection.ExpiresAt)
+	}
+	if _, err := c.components.GetJWTService().ValidateToken(rest[0]); err != nil {
+		inspection.Error = err.Error()
+	} else {
+		inspection.Valid = true
+	}
+
+	return c.writeJSON(inspection)
+}
+
+// claimTime はUNIX時刻のクレームを時刻に変換する（存在しない場合はnil）
+func claimTime(claims jwt.MapClaims, name string) *time.Time {
+	value, ok := claims[name].(float64)
+	if !ok {
+		return nil
+	}
+	t := time.Unix(int64(value), 0).UTC()
+	return &t
+}
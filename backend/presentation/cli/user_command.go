@@ -0,0 +1,156 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"stackies-backend/domain/model"
+	"stackies-backend/usecase"
+	"text/tabwriter"
+	"time"
+)
+
+// runUser はuserサブコマンドを実行する
+func (c *CLI) runUser(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return c.usageError("user: missing subcommand")
+	}
+
+	switch args[0] {
+	case "list":
+		return c.listUsers(ctx, args[1:])
+	case "show":
+		return c.showUser(ctx, args[1:])
+	case "disable":
+		return c.disableUser(ctx, args[1:])
+	case "suspend":
+		return c.suspendUser(ctx, args[1:])
+	case "activate":
+		return c.activateUser(ctx, args[1:])
+	case "grant-role":
+		return c.grantRole(ctx, args[1:])
+	default:
+		return c.usageError(fmt.Sprintf("user: unknown subcommand: %s", args[0]))
+	}
+}
+
+// listUsers はユーザーの一覧を表形式で出力する
+func (c *CLI) listUsers(ctx context.Context, args []string) error {
+	if _, err := c.parseFlags(c.newFlagSet("user list"), args, 0); err != nil {
+		return err
+	}
+
+	users, err := c.components.GetAdminUsecase().ListUsers(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tEMAIL\tNAME\tROLE\tSTATUS\tCREATED")
+	for _, user := range users {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			user.ID, user.Email, user.Name, user.Role, userStatus(user), user.CreatedAt.UTC().Format(time.RFC3339))
+	}
+	return w.Flush()
+}
+
+// showUser はユーザーをJSONで出力する
+func (c *CLI) showUser(ctx context.Context, args []string) error {
+	rest, err := c.parseFlags(c.newFlagSet("user show"), args, 1)
+	if err != nil {
+		return err
+	}
+
+	user, err := c.components.GetAdminUsecase().GetUser(ctx, rest[0])
+	if err != nil {
+		return err
+	}
+	return c.writeJSON(user)
+}
+
+// disableUser はユーザーを無効化し、セッションを無効化する
+func (c *CLI) disableUser(ctx context.Context, args []string) error {
+	flags := c.newFlagSet("user disable")
+	reason := flags.String("reason", "disabled via cli", "reason recorded in the audit log")
+	rest, err := c.parseFlags(flags, args, 1)
+	if err != nil {
+		return err
+	}
+	return c.changeUserStatus(ctx, rest[0], model.UserStatusDisabled, *reason, nil)
+}
+
+// suspendUser はユーザーを一時停止し、セッションを無効化する
+func (c *CLI) suspendUser(ctx context.Context, args []string) error {
+	flags := c.newFlagSet("user suspend")
+	reason := flags.String("reason", "suspended via cli", "reason recorded in the audit log")
+	duration := flags.Duration("for", 0, "suspend for the given duration (0 suspends until reactivated)")
+	rest, err := c.parseFlags(flags, args, 1)
+	if err != nil {
+		return err
+	}
+	if *duration < 0 {
+		return c.usageError("user suspend: -for must not be negative")
+	}
+
+	var until *time.Time
+	if *duration > 0 {
+		t := time.Now().Add(*duration)
+		until = &t
+	}
+	return c.changeUserStatus(ctx, rest[0], model.UserStatusSuspended, *reason, until)
+}
+
+// activateUser は停止・無効化したユーザーを有効に戻す
+func (c *CLI) activateUser(ctx context.Context, args []string) error {
+	rest, err := c.parseFlags(c.newFlagSet("user activate"), args, 1)
+	if err != nil {
+		return err
+	}
+	return c.changeUserStatus(ctx, rest[0], model.UserStatusActive, "", nil)
+}
+
+// changeUserStatus はユーザーの状態を変更して結果を出力する
+func (c *CLI) changeUserStatus(ctx context.Context, userID string, status model.UserStatus, reason string, until *time.Time) error {
+	user, err := c.components.GetAdminUsecase().ChangeStatus(ctx, &usecase.ChangeStatusInput{
+		ActorID:   model.SystemActorID,
+		UserID:    userID,
+		Status:    status,
+		Reason:    reason,
+		Until:     until,
+		UserAgent: userAgent,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stdout, "user %s (%s) is now %s\n", user.ID, user.Email, user.Status)
+	return nil
+}
+
+// grantRole はユーザーのロールを変更する
+func (c *CLI) grantRole(ctx context.Context, args []string) error {
+	rest, err := c.parseFlags(c.newFlagSet("user grant-role"), args, 2)
+	if err != nil {
+		return err
+	}
+
+	user, err := c.components.GetAdminUsecase().ChangeRole(ctx, &usecase.ChangeRoleInput{
+		ActorID:   model.SystemActorID,
+		UserID:    rest[0],
+		Role:      model.Role(rest[1]),
+		UserAgent: userAgent,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stdout, "user %s (%s) now has role %s\n", user.ID, user.Email, user.Role)
+	return nil
+}
+
+// userStatus は一覧に表示するユーザーの状態を返す
+func userStatus(user *model.User) string {
+	if user.IsDeleted() {
+		return "deleted"
+	}
+	return string(user.EffectiveStatus(time.Now()))
+}
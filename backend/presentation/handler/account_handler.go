@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"net/http"
+	"stackies-backend/domain/model"
+	"stackies-backend/usecase"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// exportRetryAfter は作成中のエクスポートを再度確認するまでの秒数
+const exportRetryAfter = "5"
+
+// AccountHandler はアカウントの削除とデータのエクスポートに関するHTTPハンドラーを表す
+type AccountHandler struct {
+	accountUsecase usecase.AccountUsecase
+}
+
+// NewAccountHandler はAccountHandlerの新しいインスタンスを作成する
+func NewAccountHandler(accountUsecase usecase.AccountUsecase) *AccountHandler {
+	return &AccountHandler{
+		accountUsecase: accountUsecase,
+	}
+}
+
+// AccountDeletionResponse はアカウント削除のレスポンス構造体を表す
+type AccountDeletionResponse struct {
+	PurgeAt time.Time `json:"purge_at"`
+}
+
+// DeleteMe は認証中のユーザーのアカウントを削除する
+// データは猶予期間の後に完全に消去され、セッションはすぐに無効になる
+func (h *AccountHandler) DeleteMe(c echo.Context) error {
+	output, err := h.accountUsecase.DeleteAccount(c.Request().Context(), &usecase.DeleteAccountInput{
+		UserID:    c.Get("user_id").(string),
+		ClientIP:  c.RealIP(),
+		UserAgent: c.Request().UserAgent(),
+	})
+	if err != nil {
+		return err
+	}
+
+	clearSessionCookies(c)
+	return c.JSON(http.StatusAccepted, &AccountDeletionResponse{PurgeAt: output.PurgeAt})
+}
+
+// ExportMe は認証中のユーザーのデータをZIPでエクスポートする
+// 作成中は202でエクスポートの状態を返すため、完了するまで同じURLを再度リクエストする
+func (h *AccountHandler) ExportMe(c echo.Context) error {
+	output, err := h.accountUsecase.RequestExport(c.Request().Context(), &usecase.RequestExportInput{
+		UserID:    c.Get("user_id").(string),
+		ClientIP:  c.RealIP(),
+		UserAgent: c.Request().UserAgent(),
+	})
+	if err != nil {
+		return err
+	}
+
+	header := c.Response().Header()
+	// 個人データを含むため、ブラウザ・プロキシにキャッシュさせない
+	header.Set(echo.HeaderCacheControl, "no-store")
+	if output.Export.Status != model.DataExportReady {
+		header.Set("Retry-After", exportRetryAfter)
+		return c.JSON(http.StatusAccepted, output.Export)
+	}
+	if output.RedirectURL != "" {
+		return c.Redirect(http.StatusFound, output.RedirectURL)
+	}
+
+	header.Set(echo.HeaderContentDisposition, `attachment; filename="stackies-export.zip"`)
+	return c.Blob(http.StatusOK, output.Blob.ContentType, output.Blob.Data)
+}
@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"stackies-backend/core"
+	"stackies-backend/domain/model"
+	"stackies-backend/usecase"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAccountUsecase はAccountUsecaseのモック
+type MockAccountUsecase struct {
+	mock.Mock
+}
+
+var _ usecase.AccountUsecase = (*MockAccountUsecase)(nil)
+
+func (m *MockAccountUsecase) DeleteAccount(ctx context.Context, input *usecase.DeleteAccountInput) (*usecase.DeleteAccountOutput, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*usecase.DeleteAccountOutput), args.Error(1)
+}
+
+func (m *MockAccountUsecase) PurgeDeletedAccounts(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockAccountUsecase) RequestExport(ctx context.Context, input *usecase.RequestExportInput) (*usecase.ExportOutput, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*usecase.ExportOutput), args.Error(1)
+}
+
+func (m *MockAccountUsecase) PurgeExpiredExports(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockAccountUsecase) Wait(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+func TestAccountHandler_DeleteMe(t *testing.T) {
+	t.Run("削除を受け付けてCookieを削除する", func(t *testing.T) {
+		purgeAt := time.Date(2026, 11, 18, 0, 0, 0, 0, time.UTC)
+		accountUC := new(MockAccountUsecase)
+		accountUC.On("DeleteAccount", mock.Anything, mock.MatchedBy(func(input *usecase.DeleteAccountInput) bool {
+			return input.UserID == "user_1" && input.UserAgent == "test-agent"
+		})).Return(&usecase.DeleteAccountOutput{PurgeAt: purgeAt}, nil)
+		handler := NewAccountHandler(accountUC)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodDelete, "/users/me", nil)
+		req.Header.Set("User-Agent", "test-agent")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("user_id", "user_1")
+
+		assert.NoError(t, handler.DeleteMe(c))
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+		assert.JSONEq(t, `{"purge_at":"2026-11-18T00:00:00Z"}`, rec.Body.String())
+		assert.Len(t, rec.Result().Cookies(), 2)
+		accountUC.AssertExpectations(t)
+	})
+
+	t.Run("削除済みのユーザー", func(t *testing.T) {
+		accountUC := new(MockAccountUsecase)
+		accountUC.On("DeleteAccount", mock.Anything, mock.Anything).Return(nil, usecase.ErrUserAlreadyDeleted)
+		handler := NewAccountHandler(accountUC)
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodDelete, "/users/me", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("user_id", "user_1")
+
+		err := handler.DeleteMe(c)
+		if appErr := core.AsAppError(err); assert.NotNil(t, appErr) {
+			assert.Equal(t, http.StatusConflict, appErr.Status)
+		}
+		assert.Empty(t, rec.Result().Cookies())
+	})
+}
+
+func TestAccountHandler_ExportMe(t *testing.T) {
+	requestedAt := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		testName       string
+		output         *usecase.ExportOutput
+		expectedStatus int
+		expectedHeader map[string]string
+		expectedBody   string
+	}{
+		{
+			testName:       "作成中",
+			output:         &usecase.ExportOutput{Export: &model.DataExport{ID: "export_1", Status: model.DataExportPending, RequestedAt: requestedAt}},
+			expectedStatus: http.StatusAccepted,
+			expectedHeader: map[string]string{"Retry-After": exportRetryAfter},
+			expectedBody:   `"status":"pending"`,
+		},
+		{
+			testName:       "作成に失敗した",
+			output:         &usecase.ExportOutput{Export: &model.DataExport{ID: "export_1", Status: model.DataExportFailed, RequestedAt: requestedAt}},
+			expectedStatus: http.StatusAccepted,
+			expectedBody:   `"status":"failed"`,
+		},
+		{
+			testName: "保存先にリダイレクトする",
+			output: &usecase.ExportOutput{
+				Export:      &model.DataExport{ID: "export_1", Status: model.DataExportReady, RequestedAt: requestedAt},
+				RedirectURL: "https://storage.example.com/export.zip",
+			},
+			expectedStatus: http.StatusFound,
+			expectedHeader: map[string]string{"Location": "https://storage.example.com/export.zip", "Cache-Control": "no-store"},
+		},
+		{
+			testName: "アーカイブを返す",
+			output: &usecase.ExportOutput{
+				Export: &model.DataExport{ID: "export_1", Status: model.DataExportReady, RequestedAt: requestedAt},
+				Blob:   &model.Blob{ContentType: "application/zip", Data: []byte("zip")},
+			},
+			expectedStatus: http.StatusOK,
+			expectedHeader: map[string]string{
+				"Content-Type":        "application/zip",
+				"Content-Disposition": `attachment; filename="stackies-export.zip"`,
+				"Cache-Control":       "no-store",
+			},
+			expectedBody: "zip",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			accountUC := new(MockAccountUsecase)
+			accountUC.On("RequestExport", mock.Anything, mock.MatchedBy(func(input *usecase.RequestExportInput) bool {
+				return input.UserID == "user_1"
+			})).Return(tt.output, nil)
+			handler := NewAccountHandler(accountUC)
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/users/me/export", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set("user_id", "user_1")
+
+			assert.NoError(t, handler.ExportMe(c))
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			for key, value := range tt.expectedHeader {
+				assert.Equal(t, value, rec.Header().Get(key), key)
+			}
+			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+			accountUC.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"net/http"
+	"stackies-backend/core"
+	"stackies-backend/domain/model"
+	"stackies-backend/usecase"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// AdminHandler は管理者向けのHTTPハンドラーを表す
+type AdminHandler struct {
+	adminUsecase usecase.AdminUsecase
+}
+
+// NewAdminHandler はAdminHandlerの新しいインスタンスを作成する
+func NewAdminHandler(adminUsecase usecase.AdminUsecase) *AdminHandler {
+	return &AdminHandler{
+		adminUsecase: adminUsecase,
+	}
+}
+
+// ListUsers は条件を指定してユーザーを検索する
+// status, role, provider, created_from, created_to, q（メールアドレス・表示名の前方一致）, sort, limit, cursorで絞り込む
+func (h *AdminHandler) ListUsers(c echo.Context) error {
+	input := &usecase.SearchUsersInput{
+		Status:   model.UserStatus(c.QueryParam("status")),
+		Role:     model.Role(c.QueryParam("role")),
+		Provider: model.Provider(c.QueryParam("provider")),
+		Query:    c.QueryParam("q"),
+		Sort:     model.UserSort(c.QueryParam("sort")),
+		Cursor:   c.QueryParam("cursor"),
+	}
+
+	if value := c.QueryParam("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 0 {
+			return core.ErrBadRequest.WithMessage("Invalid limit")
+		}
+		input.Limit = limit
+	}
+
+	var err error
+	if input.CreatedFrom, err = parseTimeParam(c, "created_from"); err != nil {
+		return err
+	}
+	if input.CreatedTo, err = parseTimeParam(c, "created_to"); err != nil {
+		return err
+	}
+
+	page, err := h.adminUsecase.SearchUsers(c.Request().Context(), input)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, page)
+}
+
+// ChangeRoleRequest はロール変更のリクエスト構造体を表す
+type ChangeRoleRequest struct {
+	Role string `json:"role"`
+}
+
+// ChangeRole は指定したユーザーのロールを変更する
+func (h *AdminHandler) ChangeRole(c echo.Context) error {
+	var req ChangeRoleRequest
+	if err := c.Bind(&req); err != nil {
+		return core.ErrBadRequest.Wrap(err)
+	}
+
+	input := &usecase.ChangeRoleInput{
+		ActorID:   c.Get("user_id").(string),
+		UserID:    c.Param("id"),
+		Role:      model.Role(req.Role),
+		ClientIP:  c.RealIP(),
+		UserAgent: c.Request().UserAgent(),
+	}
+
+	user, err := h.adminUsecase.ChangeRole(c.Request().Context(), input)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, user)
+}
+
+// ChangeStatusRequest はユーザーの状態変更のリクエスト構造体を表す
+type ChangeStatusRequest struct {
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+	// Until は一時停止の期限（RFC 3339、省略した場合は無期限）
+	Until *time.Time `json:"until"`
+}
+
+// ChangeStatus は指定したユーザーの状態を変更し、セッションを無効化する
+func (h *AdminHandler) ChangeStatus(c echo.Context) error {
+	var req ChangeStatusRequest
+	if err := c.Bind(&req); err != nil {
+		return core.ErrBadRequest.Wrap(err)
+	}
+
+	input := &usecase.ChangeStatusInput{
+		ActorID:   c.Get("user_id").(string),
+		UserID:    c.Param("id"),
+		Status:    model.UserStatus(req.Status),
+		Reason:    req.Reason,
+		Until:     req.Until,
+		ClientIP:  c.RealIP(),
+		UserAgent: c.Request().UserAgent(),
+	}
+
+	user, err := h.adminUsecase.ChangeStatus(c.Request().Context(), input)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, user)
+}
+
+// ImpersonateRequest はなりすましのリクエスト構造体を表す
+type ImpersonateRequest struct {
+	Reason string `json:"reason"`
+}
+
+// ImpersonationResponse はなりすましのレスポンス構造体を表す
+type ImpersonationResponse struct {
+	AccessToken string      `json:"access_token"`
+	TokenType   string      `json:"token_type"`
+	ExpiresAt   time.Time   `json:"expires_at"`
+	User        *model.User `json:"user"`
+}
+
+// Impersonate は指定したユーザーになりすますための短期間のアクセストークンを発行する
+func (h *AdminHandler) Impersonate(c echo.Context) error {
+	var req ImpersonateRequest
+	if err := c.Bind(&req); err != nil {
+		return core.ErrBadRequest.Wrap(err)
+	}
+
+	output, err := h.adminUsecase.Impersonate(c.Request().Context(), &usecase.ImpersonateInput{
+		ActorID:   c.Get("user_id").(string),
+		UserID:    c.Param("id"),
+		Reason:    req.Reason,
+		ClientIP:  c.RealIP(),
+		UserAgent: c.Request().UserAgent(),
+	})
+	if err != nil {
+		return err
+	}
+
+	c.Response().Header().Set("Cache-Control", "no-store")
+	return c.JSON(http.StatusOK, &ImpersonationResponse{
+		AccessToken: output.AccessToken,
+		TokenType:   "Bearer",
+		ExpiresAt:   output.ExpiresAt,
+		User:        output.User,
+	})
+}
@@ -0,0 +1,356 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"stackies-backend/core"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/repository"
+	"stackies-backend/usecase"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAdminUsecase はAdminUsecaseのモック
+type MockAdminUsecase struct {
+	mock.Mock
+}
+
+var _ usecase.AdminUsecase = (*MockAdminUsecase)(nil)
+
+func (m *MockAdminUsecase) ChangeRole(ctx context.Context, input *usecase.ChangeRoleInput) (*model.User, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.User), args.Error(1)
+}
+
+func (m *MockAdminUsecase) ListUsers(ctx context.Context) ([]*model.User, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.User), args.Error(1)
+}
+
+func (m *MockAdminUsecase) GetUser(ctx context.Context, userID string) (*model.User, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.User), args.Error(1)
+}
+
+func (m *MockAdminUsecase) SearchUsers(ctx context.Context, input *usecase.SearchUsersInput) (*model.UserPage, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.UserPage), args.Error(1)
+}
+
+func (m *MockAdminUsecase) Impersonate(ctx context.Context, input *usecase.ImpersonateInput) (*usecase.ImpersonateOutput, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*usecase.ImpersonateOutput), args.Error(1)
+}
+
+func (m *MockAdminUsecase) ChangeStatus(ctx context.Context, input *usecase.ChangeStatusInput) (*model.User, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.User), args.Error(1)
+}
+
+func (m *MockAdminUsecase) RevokeSessions(ctx context.Context, input *usecase.RevokeSessionsInput) error {
+	args := m.Called(ctx, input)
+	return args.Error(0)
+}
+
+func TestAdminHandler_ListUsers(t *testing.T) {
+	tests := []struct {
+		testName       string
+		query          string
+		setupMocks     func(*MockAdminUsecase)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			testName: "クエリの条件で検索する",
+			query:    "?status=active&role=user&provider=google&q=ali&sort=-created_at&limit=10&cursor=user_9&created_from=2025-01-01T00:00:00Z&created_to=2025-02-01T00:00:00Z",
+			setupMocks: func(adminUC *MockAdminUsecase) {
+				adminUC.On("SearchUsers", mock.Anything, &usecase.SearchUsersInput{
+					Status:      model.UserStatusActive,
+					Role:        model.RoleUser,
+					Provider:    model.ProviderGoogle,
+					CreatedFrom: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+					CreatedTo:   time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+					Query:       "ali",
+					Sort:        model.UserSortCreatedDesc,
+					Limit:       10,
+					Cursor:      "user_9",
+				}).Return(&model.UserPage{Users: []*model.User{{ID: "user_1"}}, NextCursor: "user_1"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"next_cursor":"user_1"`,
+		},
+		{
+			testName:       "不正なlimit",
+			query:          "?limit=abc",
+			setupMocks:     func(adminUC *MockAdminUsecase) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			testName:       "不正な作成日時",
+			query:          "?created_from=yesterday",
+			setupMocks:     func(adminUC *MockAdminUsecase) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			testName: "存在しないカーソル",
+			query:    "?cursor=unknown",
+			setupMocks: func(adminUC *MockAdminUsecase) {
+				adminUC.On("SearchUsers", mock.Anything, mock.Anything).Return(nil, repository.ErrInvalidCursor)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			adminUC := new(MockAdminUsecase)
+			tt.setupMocks(adminUC)
+			handler := NewAdminHandler(adminUC)
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/admin/users"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := handler.ListUsers(c)
+
+			if tt.expectedStatus != http.StatusOK {
+				if appErr := core.AsAppError(err); assert.NotNil(t, appErr) {
+					assert.Equal(t, tt.expectedStatus, appErr.Status)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedStatus, rec.Code)
+				assert.Contains(t, rec.Body.String(), tt.expectedBody)
+			}
+
+			adminUC.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAdminHandler_ChangeRole(t *testing.T) {
+	tests := []struct {
+		testName       string
+		body           string
+		err            error
+		expectedStatus int
+	}{
+		{
+			testName:       "正常なロール変更",
+			body:           `{"role":"admin"}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			testName:       "不正なロール",
+			body:           `{"role":"owner"}`,
+			err:            usecase.ErrInvalidRole,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			testName:       "自分自身のロール変更",
+			body:           `{"role":"user"}`,
+			err:            usecase.ErrCannotChangeOwnRole,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			testName:       "存在しないユーザー",
+			body:           `{"role":"admin"}`,
+			err:            repository.ErrUserNotFound,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			adminUC := new(MockAdminUsecase)
+			matchInput := mock.MatchedBy(func(input *usecase.ChangeRoleInput) bool {
+				return input.ActorID == "admin_1" && input.UserID == "user_1" && input.ClientIP == "192.0.2.1"
+			})
+			if tt.err != nil {
+				adminUC.On("ChangeRole", mock.Anything, matchInput).Return(nil, tt.err)
+			} else {
+				adminUC.On("ChangeRole", mock.Anything, matchInput).Return(&model.User{ID: "user_1", Role: model.RoleAdmin}, nil)
+			}
+			handler := NewAdminHandler(adminUC)
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPut, "/admin/users/user_1/role", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.RemoteAddr = "192.0.2.1:12345"
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues("user_1")
+			c.Set("user_id", "admin_1")
+
+			err := handler.ChangeRole(c)
+
+			if tt.err != nil {
+				if appErr := core.AsAppError(err); assert.NotNil(t, appErr) {
+					assert.Equal(t, tt.expectedStatus, appErr.Status)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedStatus, rec.Code)
+				assert.Contains(t, rec.Body.String(), `"role":"admin"`)
+			}
+
+			adminUC.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAdminHandler_ChangeStatus(t *testing.T) {
+	until := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		testName       string
+		body           string
+		setupMocks     func(*MockAdminUsecase)
+		expectedStatus int
+	}{
+		{
+			testName: "期限付きの一時停止",
+			body:     `{"status":"suspended","reason":"spam","until":"2030-01-02T03:04:05Z"}`,
+			setupMocks: func(adminUC *MockAdminUsecase) {
+				adminUC.On("ChangeStatus", mock.Anything, mock.MatchedBy(func(input *usecase.ChangeStatusInput) bool {
+					return input.ActorID == "admin_1" && input.UserID == "user_1" && input.ClientIP == "192.0.2.1" &&
+						input.Status == model.UserStatusSuspended && input.Reason == "spam" &&
+						input.Until != nil && input.Until.Equal(until)
+				})).Return(&model.User{ID: "user_1", Status: model.UserStatusSuspended, StatusReason: "spam", SuspendedUntil: &until}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			testName: "状態を変更できない",
+			body:     `{"status":"suspended","reason":"spam"}`,
+			setupMocks: func(adminUC *MockAdminUsecase) {
+				adminUC.On("ChangeStatus", mock.Anything, mock.Anything).Return(nil, usecase.ErrInvalidStatusTransition)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			testName:       "期限の形式が不正",
+			body:           `{"status":"suspended","reason":"spam","until":"tomorrow"}`,
+			setupMocks:     func(adminUC *MockAdminUsecase) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			adminUC := new(MockAdminUsecase)
+			tt.setupMocks(adminUC)
+			handler := NewAdminHandler(adminUC)
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPut, "/admin/users/user_1/status", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.RemoteAddr = "192.0.2.1:12345"
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues("user_1")
+			c.Set("user_id", "admin_1")
+
+			err := handler.ChangeStatus(c)
+
+			if tt.expectedStatus != http.StatusOK {
+				if appErr := core.AsAppError(err); assert.NotNil(t, appErr) {
+					assert.Equal(t, tt.expectedStatus, appErr.Status)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedStatus, rec.Code)
+				assert.Contains(t, rec.Body.String(), `"status":"suspended"`)
+				assert.Contains(t, rec.Body.String(), `"suspended_until":"2030-01-02T03:04:05Z"`)
+			}
+
+			adminUC.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAdminHandler_Impersonate(t *testing.T) {
+	expiresAt := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		testName       string
+		err            error
+		expectedStatus int
+	}{
+		{testName: "なりすましのトークンを返す", expectedStatus: http.StatusOK},
+		{testName: "管理者にはなりすませない", err: usecase.ErrCannotImpersonateAdmin, expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			adminUC := new(MockAdminUsecase)
+			matchInput := mock.MatchedBy(func(input *usecase.ImpersonateInput) bool {
+				return input.ActorID == "admin_1" && input.UserID == "user_1" && input.Reason == "ticket #42" && input.ClientIP == "192.0.2.1"
+			})
+			if tt.err != nil {
+				adminUC.On("Impersonate", mock.Anything, matchInput).Return(nil, tt.err)
+			} else {
+				adminUC.On("Impersonate", mock.Anything, matchInput).Return(&usecase.ImpersonateOutput{
+					AccessToken: "impersonation_token",
+					ExpiresAt:   expiresAt,
+					User:        &model.User{ID: "user_1"},
+				}, nil)
+			}
+			handler := NewAdminHandler(adminUC)
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/admin/users/user_1/impersonate", strings.NewReader(`{"reason":"ticket #42"}`))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.RemoteAddr = "192.0.2.1:12345"
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues("user_1")
+			c.Set("user_id", "admin_1")
+
+			err := handler.Impersonate(c)
+
+			if tt.err != nil {
+				if appErr := core.AsAppError(err); assert.NotNil(t, appErr) {
+					assert.Equal(t, tt.expectedStatus, appErr.Status)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedStatus, rec.Code)
+				assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+				assert.Contains(t, rec.Body.String(), `"access_token":"impersonation_token"`)
+				assert.Contains(t, rec.Body.String(), `"expires_at":"2030-01-02T03:04:05Z"`)
+			}
+
+			adminUC.AssertExpectations(t)
+		})
+	}
+}
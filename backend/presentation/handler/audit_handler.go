@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"net/http"
+	"stackies-backend/core"
+	"stackies-backend/domain/model"
+	"stackies-backend/usecase"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// AuditHandler は監査ログ関連のHTTPハンドラーを表す
+type AuditHandler struct {
+	auditUsecase usecase.AuditUsecase
+}
+
+// NewAuditHandler はAuditHandlerの新しいインスタンスを作成する
+func NewAuditHandler(auditUsecase usecase.AuditUsecase) *AuditHandler {
+	return &AuditHandler{
+		auditUsecase: auditUsecase,
+	}
+}
+
+// ListMyEvents は認証中のユーザー自身に関する監査ログを返す
+func (h *AuditHandler) ListMyEvents(c echo.Context) error {
+	input, err := bindAuditQuery(c)
+	if err != nil {
+		return err
+	}
+	// 他ユーザーの履歴を参照できないよう、対象は常に本人に固定する
+	input.UserID = c.Get("user_id").(string)
+	input.ActorID = ""
+
+	return h.listEvents(c, input)
+}
+
+// ListEvents は管理者向けに条件を指定して監査ログを返す
+func (h *AuditHandler) ListEvents(c echo.Context) error {
+	input, err := bindAuditQuery(c)
+	if err != nil {
+		return err
+	}
+	input.UserID = c.QueryParam("user_id")
+	input.ActorID = c.QueryParam("actor_id")
+
+	return h.listEvents(c, input)
+}
+
+func (h *AuditHandler) listEvents(c echo.Context, input *usecase.ListAuditEventsInput) error {
+	page, err := h.auditUsecase.ListEvents(c.Request().Context(), input)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, page)
+}
+
+// bindAuditQuery は共通の検索条件（type, outcome, from, to, limit, cursor）をクエリから読み取る
+func bindAuditQuery(c echo.Context) (*usecase.ListAuditEventsInput, error) {
+	input := &usecase.ListAuditEventsInput{
+		Type:    model.AuditEventType(c.QueryParam("type")),
+		Outcome: model.AuditOutcome(c.QueryParam("outcome")),
+		Cursor:  c.QueryParam("cursor"),
+	}
+
+	if value := c.QueryParam("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 0 {
+			return nil, core.ErrBadRequest.WithMessage("Invalid limit")
+		}
+		input.Limit = limit
+	}
+
+	var err error
+	if input.From, err = parseTimeParam(c, "from"); err != nil {
+		return nil, err
+	}
+	if input.To, err = parseTimeParam(c, "to"); err != nil {
+		return nil, err
+	}
+
+	return input, nil
+}
+
+// parseTimeParam はRFC3339形式のクエリパラメータを読み取る（未指定の場合はゼロ値）
+func parseTimeParam(c echo.Context, name string) (time.Time, error) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, core.ErrBadRequest.WithMessage("Invalid " + name + " parameter")
+	}
+	return t, nil
+}
@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"stackies-backend/core"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/repository"
+	"stackies-backend/usecase"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAuditUsecase はAuditUsecaseのモック
+type MockAuditUsecase struct {
+	mock.Mock
+}
+
+var _ usecase.AuditUsecase = (*MockAuditUsecase)(nil)
+
+func (m *MockAuditUsecase) ListEvents(ctx context.Context, input *usecase.ListAuditEventsInput) (*model.AuditEventPage, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.AuditEventPage), args.Error(1)
+}
+
+func TestAuditHandler_ListMyEvents(t *testing.T) {
+	auditUC := new(MockAuditUsecase)
+	auditUC.On("ListEvents", mock.Anything, &usecase.ListAuditEventsInput{
+		UserID: "user_123",
+		Type:   model.AuditEventLogin,
+		Limit:  20,
+	}).Return(&model.AuditEventPage{Events: []*model.AuditEvent{}}, nil)
+
+	handler := NewAuditHandler(auditUC)
+
+	e := echo.New()
+	// user_idやactor_idを指定しても本人の履歴に固定される
+	req := httptest.NewRequest(http.MethodGet, "/users/me/audit-events?type=login&limit=20&user_id=other&actor_id=other", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("user_id", "user_123")
+
+	err := handler.ListMyEvents(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	auditUC.AssertExpectations(t)
+}
+
+func TestAuditHandler_ListEvents(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		testName       string
+		query          string
+		setupMocks     func(*MockAuditUsecase)
+		expectedStatus int
+		expectError    bool
+	}{
+		{
+			testName: "条件を指定して検索",
+			query:    "user_id=user_1&actor_id=admin_1&type=role_change&outcome=success&from=2024-01-01T00:00:00Z&cursor=event_9",
+			setupMocks: func(auditUC *MockAuditUsecase) {
+				auditUC.On("ListEvents", mock.Anything, &usecase.ListAuditEventsInput{
+					UserID:  "user_1",
+					ActorID: "admin_1",
+					Type:    model.AuditEventRoleChange,
+					Outcome: model.AuditOutcomeSuccess,
+					From:    from,
+					Cursor:  "event_9",
+				}).Return(&model.AuditEventPage{Events: []*model.AuditEvent{{ID: "event_8"}}, NextCursor: "event_8"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			testName:       "不正な日時",
+			query:          "from=yesterday",
+			setupMocks:     func(auditUC *MockAuditUsecase) {},
+			expectedStatus: http.StatusBadRequest,
+			expectError:    true,
+		},
+		{
+			testName:       "不正なlimit",
+			query:          "limit=abc",
+			setupMocks:     func(auditUC *MockAuditUsecase) {},
+			expectedStatus: http.StatusBadRequest,
+			expectError:    true,
+		},
+		{
+			testName: "不正なカーソル",
+			query:    "cursor=unknown",
+			setupMocks: func(auditUC *MockAuditUsecase) {
+				auditUC.On("ListEvents", mock.Anything, mock.Anything).Return(nil, repository.ErrInvalidCursor)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectError:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			auditUC := new(MockAuditUsecase)
+			tt.setupMocks(auditUC)
+			handler := NewAuditHandler(auditUC)
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/admin/audit-events?"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set("user_id", "admin_1")
+
+			err := handler.ListEvents(c)
+
+			if tt.expectError {
+				if appErr := core.AsAppError(err); assert.NotNil(t, appErr) {
+					assert.Equal(t, tt.expectedStatus, appErr.Status)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedStatus, rec.Code)
+				assert.Contains(t, rec.Body.String(), `"next_cursor":"event_8"`)
+			}
+
+			auditUC.AssertExpectations(t)
+		})
+	}
+}
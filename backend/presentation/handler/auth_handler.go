@@ -1,16 +1,13 @@
 package handler
 
 import (
-	"crypto/rand"
-	"encoding/hex"
-	"fmt"
 	"net/http"
+	"stackies-backend/core"
 	"stackies-backend/domain/model"
 	"stackies-backend/domain/repository"
-	"stackies-backend/domain/service"
+	authMiddleware "stackies-backend/presentation/middleware"
 	"stackies-backend/usecase"
 
-	"github.com/davecgh/go-spew/spew"
 	"github.com/labstack/echo/v4"
 )
 
@@ -18,27 +15,17 @@ import (
 type AuthHandler struct {
 	authUsecase usecase.AuthUsecase
 	userRepo    repository.UserRepository
-	googleSvc   service.GoogleService
-	stateStore  map[string]bool // 本来はRedisなどを使用
 }
 
 // NewAuthHandler はAuthHandlerの新しいインスタンスを作成する
-func NewAuthHandler(authUsecase usecase.AuthUsecase, userRepo repository.UserRepository, googleSvc service.GoogleService) *AuthHandler {
+func NewAuthHandler(authUsecase usecase.AuthUsecase, userRepo repository.UserRepository) *AuthHandler {
 	return &AuthHandler{
 		authUsecase: authUsecase,
 		userRepo:    userRepo,
-		googleSvc:   googleSvc,
-		stateStore:  make(map[string]bool),
 	}
 }
 
 type (
-	// GoogleAuthURLResponse はGoogle認証URL生成のレスポンス構造体を表す
-	GoogleAuthURLResponse struct {
-		AuthURL string `json:"auth_url"`
-		State   string `json:"state"`
-	}
-
 	// GoogleLoginRequest はGoogleログインのリクエスト構造体を表す
 	GoogleLoginRequest struct {
 		State string `json:"state" validate:"required"`
@@ -54,8 +41,9 @@ type (
 	}
 
 	// RefreshTokenRequest はトークンリフレッシュのリクエスト構造体を表す
+	// Cookieセッションモードではボディを省略し、Cookieのリフレッシュトークンを使用できる
 	RefreshTokenRequest struct {
-		RefreshToken string `json:"refreshToken" validate:"required"`
+		RefreshToken string `json:"refreshToken"`
 	}
 
 	// RefreshTokenResponse はトークンリフレッシュのレスポンス構造体を表す
@@ -66,67 +54,22 @@ type (
 	}
 )
 
-// generateState はCSRF対策用のランダムなstateを生成する
-func (h *AuthHandler) generateState() (string, error) {
-	bytes := make([]byte, 16)
-	if _, err := rand.Read(bytes); err != nil {
-		return "", err
-	}
-	state := hex.EncodeToString(bytes)
-	h.stateStore[state] = true
-	return state, nil
-}
-
-// validateState はstateの有効性を検証する
-func (h *AuthHandler) validateState(state string) bool {
-	if _, exists := h.stateStore[state]; exists {
-		delete(h.stateStore, state) // 一度使用したら削除
-		return true
-	}
-	return false
-}
-
-// GoogleAuthURL はGoogle認証URLを生成するハンドラーメソッドを表す
-func (h *AuthHandler) GoogleAuthURL(c echo.Context) error {
-	state, err := h.generateState()
-	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to generate state")
-	}
-
-	authURL := h.googleSvc.GenerateAuthURL(state)
-
-	response := &GoogleAuthURLResponse{
-		AuthURL: authURL,
-		State:   state,
-	}
-
-	// return c.Redirect(http.StatusTemporaryRedirect, response.AuthURL)
-	return c.JSON(http.StatusOK, response)
-}
-
 // GoogleLogin はGoogleログインのハンドラーメソッドを表す
 func (h *AuthHandler) GoogleLogin(c echo.Context) error {
 	var req GoogleLoginRequest
 	if err := c.Bind(&req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request")
+		return core.ErrBadRequest.Wrap(err)
 	}
 
-	spew.Dump(req)
-
-	// CSRF対策：stateを検証
-	// if !h.validateState(req.State) {
-	// 	return echo.NewHTTPError(http.StatusBadRequest, "Invalid state parameter")
-	// }
-
 	input := &usecase.GoogleLoginInput{
 		AuthorizationCode: req.Code,
+		ClientIP:          c.RealIP(),
+		UserAgent:         c.Request().UserAgent(),
 	}
 
 	output, err := h.authUsecase.GoogleLogin(c.Request().Context(), input)
 	if err != nil {
-		spew.Dump(err)
-		fmt.Println(err)
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	response := &GoogleLoginResponse{
@@ -136,8 +79,6 @@ func (h *AuthHandler) GoogleLogin(c echo.Context) error {
 		ExpiresIn:    output.ExpiresIn,
 	}
 
-	spew.Dump(response)
-
 	return c.JSON(http.StatusOK, response)
 }
 
@@ -145,16 +86,33 @@ func (h *AuthHandler) GoogleLogin(c echo.Context) error {
 func (h *AuthHandler) RefreshToken(c echo.Context) error {
 	var req RefreshTokenRequest
 	if err := c.Bind(&req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request")
+		return core.ErrBadRequest.Wrap(err)
+	}
+
+	// ボディにない場合はCookieから取得する
+	fromCookie := false
+	if req.RefreshToken == "" {
+		cookie, err := c.Cookie(authMiddleware.RefreshTokenCookieName)
+		if err != nil || cookie.Value == "" {
+			return core.ErrBadRequest.WithMessage("Refresh token required")
+		}
+		req.RefreshToken = cookie.Value
+		fromCookie = true
 	}
 
 	input := &usecase.RefreshTokenInput{
 		RefreshToken: req.RefreshToken,
+		ClientIP:     c.RealIP(),
+		UserAgent:    c.Request().UserAgent(),
 	}
 
 	output, err := h.authUsecase.RefreshToken(c.Request().Context(), input)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
+	}
+
+	if fromCookie {
+		setSessionCookies(c, output.AccessToken, output.RefreshToken, output.ExpiresIn)
 	}
 
 	response := &RefreshTokenResponse{
@@ -171,13 +129,17 @@ func (h *AuthHandler) Logout(c echo.Context) error {
 	userID := c.Get("user_id").(string)
 
 	input := &usecase.LogoutInput{
-		UserID: userID,
+		UserID:    userID,
+		ClientIP:  c.RealIP(),
+		UserAgent: c.Request().UserAgent(),
 	}
 
 	if err := h.authUsecase.Logout(c.Request().Context(), input); err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
+	clearSessionCookies(c)
+
 	return c.JSON(http.StatusOK, map[string]string{"message": "Logged out successfully"})
 }
 
@@ -187,10 +149,7 @@ func (h *AuthHandler) GetMe(c echo.Context) error {
 
 	user, err := h.userRepo.FindByID(c.Request().Context(), userID)
 	if err != nil {
-		if err == repository.ErrUserNotFound {
-			return echo.NewHTTPError(http.StatusNotFound, "User not found")
-		}
-		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	return c.JSON(http.StatusOK, user)
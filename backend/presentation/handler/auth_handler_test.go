@@ -75,30 +75,25 @@ func (m *MockUserRepository) Update(ctx context.Context, user *model.User) error
 	return args.Error(0)
 }
 
-// MockGoogleService はGoogleServiceのモック
-type MockGoogleService struct {
-	mock.Mock
-}
-
-func (m *MockGoogleService) GenerateAuthURL(state string) string {
-	args := m.Called(state)
-	return args.String(0)
-}
-
-func (m *MockGoogleService) ExchangeCode(ctx context.Context, code, redirectURI string) (*model.AuthToken, error) {
-	args := m.Called(ctx, code, redirectURI)
+func (m *MockUserRepository) FindAll(ctx context.Context) ([]*model.User, error) {
+	args := m.Called(ctx)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*model.AuthToken), args.Error(1)
+	return args.Get(0).([]*model.User), args.Error(1)
 }
 
-func (m *MockGoogleService) GetUserInfo(ctx context.Context, accessToken string) (*model.GoogleUserInfo, error) {
-	args := m.Called(ctx, accessToken)
+func (m *MockUserRepository) List(ctx context.Context, filter *model.UserFilter) (*model.UserPage, error) {
+	args := m.Called(ctx, filter)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*model.GoogleUserInfo), args.Error(1)
+	return args.Get(0).(*model.UserPage), args.Error(1)
+}
+
+func (m *MockUserRepository) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
 }
 
 func TestAuthHandler_GoogleLogin(t *testing.T) {
@@ -151,8 +146,7 @@ func TestAuthHandler_GoogleLogin(t *testing.T) {
 			userRepo := new(MockUserRepository)
 			tt.setupMocks(authUC, userRepo)
 
-			googleSvc := new(MockGoogleService)
-			handler := NewAuthHandler(authUC, userRepo, googleSvc)
+			handler := NewAuthHandler(authUC, userRepo)
 
 			e := echo.New()
 			body, _ := json.Marshal(tt.requestBody)
@@ -216,8 +210,7 @@ func TestAuthHandler_RefreshToken(t *testing.T) {
 			userRepo := new(MockUserRepository)
 			tt.setupMocks(authUC, userRepo)
 
-			googleSvc := new(MockGoogleService)
-			handler := NewAuthHandler(authUC, userRepo, googleSvc)
+			handler := NewAuthHandler(authUC, userRepo)
 
 			e := echo.New()
 			body, _ := json.Marshal(tt.requestBody)
@@ -266,8 +259,7 @@ func TestAuthHandler_Logout(t *testing.T) {
 			userRepo := new(MockUserRepository)
 			tt.setupMocks(authUC, userRepo)
 
-			googleSvc := new(MockGoogleService)
-			handler := NewAuthHandler(authUC, userRepo, googleSvc)
+			handler := NewAuthHandler(authUC, userRepo)
 
 			e := echo.New()
 			req := httptest.NewRequest(http.MethodPost, "/auth/logout", nil)
@@ -332,8 +324,7 @@ func TestAuthHandler_GetMe(t *testing.T) {
 			userRepo := new(MockUserRepository)
 			tt.setupMocks(authUC, userRepo)
 
-			googleSvc := new(MockGoogleService)
-			handler := NewAuthHandler(authUC, userRepo, googleSvc)
+			handler := NewAuthHandler(authUC, userRepo)
 
 			e := echo.New()
 			req := httptest.NewRequest(http.MethodGet, "/auth/me", nil)
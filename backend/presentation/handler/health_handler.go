@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"stackies-backend/core/health"
+
+	"github.com/labstack/echo/v4"
+)
+
+// HealthHandler はオーケストレーター向けのヘルスチェックのHTTPハンドラーを表す
+type HealthHandler struct {
+	registry *health.Registry
+}
+
+type (
+	// HealthReportResponse はヘルスチェックのレスポンス構造体を表す
+	// 認証なしで公開するため、状態とチェック名のみを返す（失敗の理由などはログに出力する）
+	HealthReportResponse struct {
+		Status health.Status         `json:"status"`
+		Checks []HealthCheckResponse `json:"checks"`
+	}
+
+	// HealthCheckResponse は1つのヘルスチェックの結果のレスポンス構造体を表す
+	HealthCheckResponse struct {
+		Name   string        `json:"name"`
+		Status health.Status `json:"status"`
+	}
+)
+
+// NewHealthHandler はHealthHandlerの新しいインスタンスを作成する
+func NewHealthHandler(registry *health.Registry) *HealthHandler {
+	return &HealthHandler{
+		registry: registry,
+	}
+}
+
+// Livez はプロセスが動作しているかを返す（異常な場合は503）
+func (h *HealthHandler) Livez(c echo.Context) error {
+	return h.respond(c, health.KindLiveness)
+}
+
+// Readyz はリクエストを処理できるか（依存先に到達できるか）を返す（異常な場合は503）
+func (h *HealthHandler) Readyz(c echo.Context) error {
+	return h.respond(c, health.KindReadiness)
+}
+
+func (h *HealthHandler) respond(c echo.Context, kind health.Kind) error {
+	ctx := c.Request().Context()
+	report := h.registry.Run(ctx, kind)
+
+	res := HealthReportResponse{
+		Status: report.Status,
+		Checks: make([]HealthCheckResponse, 0, len(report.Checks)),
+	}
+	for _, result := range report.Checks {
+		res.Checks = append(res.Checks, HealthCheckResponse{Name: result.Name, Status: result.Status})
+		if result.Status != health.StatusUp && !result.Cached {
+			slog.WarnContext(ctx, "health check failed",
+				slog.String("kind", string(kind)),
+				slog.String("check", result.Name),
+				slog.Int64("latency_ms", result.LatencyMs),
+				slog.String("error", result.Error))
+		}
+	}
+
+	status := http.StatusOK
+	if report.Status != health.StatusUp {
+		status = http.StatusServiceUnavailable
+	}
+	return c.JSON(status, res)
+}
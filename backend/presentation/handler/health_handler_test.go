@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"stackies-backend/core/health"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthHandler(t *testing.T) {
+	tests := []struct {
+		testName       string
+		redisErr       error
+		handle         func(h *HealthHandler, c echo.Context) error
+		expectedStatus int
+		expectedBody   []string
+		unexpectedBody []string
+		expectedLog    []string
+	}{
+		{
+			testName:       "Readinessが正常",
+			handle:         (*HealthHandler).Readyz,
+			expectedStatus: http.StatusOK,
+			expectedBody:   []string{`"status":"up"`, `"name":"redis"`},
+			unexpectedBody: []string{"latencyMs", "checkedAt", "cached"},
+		},
+		{
+			testName:       "依存先に到達できない場合は503",
+			redisErr:       errors.New("dial tcp 10.0.0.1:6379: connection refused"),
+			handle:         (*HealthHandler).Readyz,
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   []string{`"status":"down"`, `"name":"redis"`},
+			unexpectedBody: []string{"10.0.0.1", "connection refused", "latencyMs"},
+			expectedLog:    []string{"health check failed", "check=redis", "10.0.0.1:6379: connection refused"},
+		},
+		{
+			testName:       "Livenessは依存先の影響を受けない",
+			redisErr:       errors.New("connection refused"),
+			handle:         (*HealthHandler).Livez,
+			expectedStatus: http.StatusOK,
+			expectedBody:   []string{`"status":"up"`, `"checks":[]`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			registry := health.NewRegistry(time.Second, time.Second)
+			registry.Register("redis", health.KindReadiness, func(ctx context.Context) error { return tt.redisErr })
+			h := NewHealthHandler(registry)
+
+			var logs bytes.Buffer
+			defaultLogger := slog.Default()
+			slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+			t.Cleanup(func() { slog.SetDefault(defaultLogger) })
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			if assert.NoError(t, tt.handle(h, c)) {
+				assert.Equal(t, tt.expectedStatus, rec.Code)
+				for _, body := range tt.expectedBody {
+					assert.Contains(t, rec.Body.String(), body)
+				}
+				for _, body := range tt.unexpectedBody {
+					assert.NotContains(t, rec.Body.String(), body)
+				}
+				for _, log := range tt.expectedLog {
+					assert.Contains(t, logs.String(), log)
+				}
+			}
+		})
+	}
+}
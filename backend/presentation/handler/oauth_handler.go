@@ -0,0 +1,227 @@
+package handler
+
+import (
+	"net/http"
+	"net/url"
+	"stackies-backend/core"
+	authMiddleware "stackies-backend/presentation/middleware"
+	"stackies-backend/usecase"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// CallbackMode はOAuthコールバック後にトークンをフロントエンドへ渡す方式を表す
+type CallbackMode string
+
+const (
+	// CallbackModeCookie はトークンをHttpOnly Cookieにセットしてリダイレクトする
+	CallbackModeCookie CallbackMode = "cookie"
+	// CallbackModeCode はワンタイムコードを付与してリダイレクトし、SPAがトークンと交換する
+	CallbackModeCode CallbackMode = "code"
+)
+
+const (
+	// OAuthNonceCookieName はOAuthフローを開始したブラウザを識別するnonceを保持するCookie名
+	OAuthNonceCookieName = "oauth_nonce"
+	// oauthCallbackPath はnonceのCookieを送信するコールバックのパス
+	oauthCallbackPath = "/auth/google/callback"
+)
+
+// OAuthHandler はサーバーサイドOAuthフローのHTTPハンドラーを表す
+type OAuthHandler struct {
+	oauthUsecase usecase.OAuthUsecase
+	callbackMode CallbackMode
+}
+
+// NewOAuthHandler はOAuthHandlerの新しいインスタンスを作成する
+func NewOAuthHandler(oauthUsecase usecase.OAuthUsecase, callbackMode CallbackMode) *OAuthHandler {
+	if callbackMode != CallbackModeCookie {
+		callbackMode = CallbackModeCode
+	}
+
+	return &OAuthHandler{
+		oauthUsecase: oauthUsecase,
+		callbackMode: callbackMode,
+	}
+}
+
+type (
+	// GoogleAuthURLResponse はGoogle認証URL生成のレスポンス構造体を表す
+	GoogleAuthURLResponse struct {
+		AuthURL string `json:"auth_url"`
+		State   string `json:"state"`
+	}
+
+	// ExchangeLoginCodeRequest はワンタイムコード交換のリクエスト構造体を表す
+	ExchangeLoginCodeRequest struct {
+		Code string `json:"code" validate:"required"`
+	}
+)
+
+// GoogleAuthURL はGoogle認証URLを生成するハンドラーメソッドを表す
+// redirect=trueの場合はJSONを返さずにGoogleへリダイレクトする
+// コールバックで照合するnonceをCookieにセットするため、ブラウザのトップレベルの遷移で呼び出す
+func (h *OAuthHandler) GoogleAuthURL(c echo.Context) error {
+	input := &usecase.GenerateAuthURLInput{
+		RedirectURL: c.QueryParam("redirect_url"),
+	}
+
+	output, err := h.oauthUsecase.GenerateAuthURL(c.Request().Context(), input)
+	if err != nil {
+		return err
+	}
+	c.SetCookie(&http.Cookie{
+		Name:     OAuthNonceCookieName,
+		Value:    output.Nonce,
+		Path:     oauthCallbackPath,
+		Expires:  output.ExpiresAt,
+		HttpOnly: true,
+		Secure:   c.Scheme() == "https",
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	if c.QueryParam("redirect") == "true" {
+		return c.Redirect(http.StatusFound, output.AuthURL)
+	}
+
+	response := &GoogleAuthURLResponse{
+		AuthURL: output.AuthURL,
+		State:   output.State,
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// GoogleCallback はGoogleからのリダイレクトを受け取りログインを完了させるハンドラーメソッドを表す
+// stateはフローを開始したブラウザのnonceのCookieと一致する場合のみ受け付ける
+func (h *OAuthHandler) GoogleCallback(c echo.Context) error {
+	var nonce string
+	if cookie, err := c.Cookie(OAuthNonceCookieName); err == nil {
+		nonce = cookie.Value
+	}
+	// nonceは一度しか使用できないため、結果にかかわらず削除する
+	c.SetCookie(&http.Cookie{
+		Name:     OAuthNonceCookieName,
+		Value:    "",
+		Path:     oauthCallbackPath,
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   c.Scheme() == "https",
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	input := &usecase.OAuthCallbackInput{
+		Code:           c.QueryParam("code"),
+		State:          c.QueryParam("state"),
+		Nonce:          nonce,
+		Error:          c.QueryParam("error"),
+		IssueLoginCode: h.callbackMode == CallbackModeCode,
+		ClientIP:       c.RealIP(),
+		UserAgent:      c.Request().UserAgent(),
+	}
+
+	output, err := h.oauthUsecase.HandleCallback(c.Request().Context(), input)
+	if err != nil {
+		return err
+	}
+
+	params := url.Values{}
+	switch {
+	case output.Error != "":
+		params.Set("error", output.Error)
+	case h.callbackMode == CallbackModeCode:
+		params.Set("login_code", output.LoginCode)
+	default:
+		setSessionCookies(c, output.Login.AccessToken, output.Login.RefreshToken, output.Login.ExpiresIn)
+	}
+
+	return c.Redirect(http.StatusFound, appendQuery(output.RedirectURL, params))
+}
+
+// ExchangeLoginCode はワンタイムコードをトークンと交換するハンドラーメソッドを表す
+func (h *OAuthHandler) ExchangeLoginCode(c echo.Context) error {
+	var req ExchangeLoginCodeRequest
+	if err := c.Bind(&req); err != nil {
+		return core.ErrBadRequest.Wrap(err)
+	}
+
+	output, err := h.oauthUsecase.ExchangeLoginCode(c.Request().Context(), &usecase.ExchangeLoginCodeInput{Code: req.Code})
+	if err != nil {
+		return err
+	}
+
+	response := &GoogleLoginResponse{
+		User:         output.User,
+		AccessToken:  output.AccessToken,
+		RefreshToken: output.RefreshToken,
+		ExpiresIn:    output.ExpiresIn,
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// appendQuery はURLにクエリパラメータを追加する
+func appendQuery(rawURL string, params url.Values) string {
+	if len(params) == 0 {
+		return rawURL
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	query := parsed.Query()
+	for key, values := range params {
+		for _, value := range values {
+			query.Add(key, value)
+		}
+	}
+	parsed.RawQuery = query.Encode()
+
+	return parsed.String()
+}
+
+// setSessionCookies はアクセストークンとリフレッシュトークンをHttpOnly Cookieにセットする
+// HTTPSでアクセスされている場合はSecure属性を付与する
+func setSessionCookies(c echo.Context, accessToken, refreshToken string, expiresIn int64) {
+	secure := c.Scheme() == "https"
+	c.SetCookie(&http.Cookie{
+		Name:     authMiddleware.AccessTokenCookieName,
+		Value:    accessToken,
+		Path:     "/",
+		Expires:  time.Unix(expiresIn, 0),
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+	c.SetCookie(&http.Cookie{
+		Name:     authMiddleware.RefreshTokenCookieName,
+		Value:    refreshToken,
+		Path:     "/auth",
+		Expires:  time.Now().Add(30 * 24 * time.Hour),
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// clearSessionCookies はセッションCookieを削除する
+func clearSessionCookies(c echo.Context) {
+	secure := c.Scheme() == "https"
+	for name, path := range map[string]string{
+		authMiddleware.AccessTokenCookieName:  "/",
+		authMiddleware.RefreshTokenCookieName: "/auth",
+	} {
+		c.SetCookie(&http.Cookie{
+			Name:     name,
+			Value:    "",
+			Path:     path,
+			MaxAge:   -1,
+			HttpOnly: true,
+			Secure:   secure,
+			SameSite: http.SameSiteLaxMode,
+		})
+	}
+}
@@ -0,0 +1,298 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"stackies-backend/core"
+	"stackies-backend/domain/model"
+	authMiddleware "stackies-backend/presentation/middleware"
+	"stackies-backend/usecase"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockOAuthUsecase はOAuthUsecaseのモック
+type MockOAuthUsecase struct {
+	mock.Mock
+}
+
+func (m *MockOAuthUsecase) GenerateAuthURL(ctx context.Context, input *usecase.GenerateAuthURLInput) (*usecase.GenerateAuthURLOutput, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*usecase.GenerateAuthURLOutput), args.Error(1)
+}
+
+func (m *MockOAuthUsecase) HandleCallback(ctx context.Context, input *usecase.OAuthCallbackInput) (*usecase.OAuthCallbackOutput, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*usecase.OAuthCallbackOutput), args.Error(1)
+}
+
+func (m *MockOAuthUsecase) ExchangeLoginCode(ctx context.Context, input *usecase.ExchangeLoginCodeInput) (*usecase.GoogleLoginOutput, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*usecase.GoogleLoginOutput), args.Error(1)
+}
+
+func TestOAuthHandler_GoogleAuthURL(t *testing.T) {
+	tests := []struct {
+		testName       string
+		query          string
+		setupMocks     func(*MockOAuthUsecase)
+		expectedStatus int
+		expectError    bool
+	}{
+		{
+			testName: "JSONで認証URLを返す",
+			query:    "redirect_url=" + url.QueryEscape("http://localhost:5173/"),
+			setupMocks: func(oauthUC *MockOAuthUsecase) {
+				oauthUC.On("GenerateAuthURL", mock.Anything, &usecase.GenerateAuthURLInput{RedirectURL: "http://localhost:5173/"}).
+					Return(&usecase.GenerateAuthURLOutput{AuthURL: "https://accounts.google.com/o/oauth2/auth", State: "state_123", Nonce: "nonce_123", ExpiresAt: time.Now().Add(time.Minute)}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			testName: "redirect=trueでGoogleへリダイレクト",
+			query:    "redirect=true",
+			setupMocks: func(oauthUC *MockOAuthUsecase) {
+				oauthUC.On("GenerateAuthURL", mock.Anything, &usecase.GenerateAuthURLInput{}).
+					Return(&usecase.GenerateAuthURLOutput{AuthURL: "https://accounts.google.com/o/oauth2/auth", State: "state_123", Nonce: "nonce_123", ExpiresAt: time.Now().Add(time.Minute)}, nil)
+			},
+			expectedStatus: http.StatusFound,
+		},
+		{
+			testName: "許可されていないリダイレクト先",
+			query:    "redirect_url=" + url.QueryEscape("https://evil.example.com/"),
+			setupMocks: func(oauthUC *MockOAuthUsecase) {
+				oauthUC.On("GenerateAuthURL", mock.Anything, mock.Anything).Return(nil, usecase.ErrRedirectNotAllowed)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectError:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			oauthUC := new(MockOAuthUsecase)
+			tt.setupMocks(oauthUC)
+			handler := NewOAuthHandler(oauthUC, CallbackModeCode)
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/auth/google/url?"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := handler.GoogleAuthURL(c)
+
+			if tt.expectError {
+				if appErr := core.AsAppError(err); assert.NotNil(t, appErr) {
+					assert.Equal(t, tt.expectedStatus, appErr.Status)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedStatus, rec.Code)
+
+				cookies := rec.Result().Cookies()
+				if assert.Len(t, cookies, 1) {
+					assert.Equal(t, OAuthNonceCookieName, cookies[0].Name)
+					assert.Equal(t, "nonce_123", cookies[0].Value)
+					assert.Equal(t, "/auth/google/callback", cookies[0].Path)
+					assert.True(t, cookies[0].HttpOnly)
+					assert.Equal(t, http.SameSiteLaxMode, cookies[0].SameSite)
+				}
+			}
+
+			oauthUC.AssertExpectations(t)
+		})
+	}
+}
+
+func TestOAuthHandler_GoogleCallback(t *testing.T) {
+	login := &usecase.GoogleLoginOutput{
+		User:         &model.User{ID: "user_123"},
+		AccessToken:  "access_token",
+		RefreshToken: "refresh_token",
+		ExpiresIn:    time.Now().Add(time.Hour).Unix(),
+	}
+
+	tests := []struct {
+		testName         string
+		mode             CallbackMode
+		output           *usecase.OAuthCallbackOutput
+		err              error
+		expectedStatus   int
+		expectedLocation string
+		expectCookies    bool
+	}{
+		{
+			testName:         "ワンタイムコードモード",
+			mode:             CallbackModeCode,
+			output:           &usecase.OAuthCallbackOutput{RedirectURL: "http://localhost:5173/", Login: login, LoginCode: "one_time"},
+			expectedStatus:   http.StatusFound,
+			expectedLocation: "http://localhost:5173/?login_code=one_time",
+		},
+		{
+			testName:         "Cookieモード",
+			mode:             CallbackModeCookie,
+			output:           &usecase.OAuthCallbackOutput{RedirectURL: "http://localhost:5173/", Login: login},
+			expectedStatus:   http.StatusFound,
+			expectedLocation: "http://localhost:5173/",
+			expectCookies:    true,
+		},
+		{
+			testName:         "ログイン失敗時はエラーを付与してリダイレクト",
+			mode:             CallbackModeCookie,
+			output:           &usecase.OAuthCallbackOutput{RedirectURL: "http://localhost:5173/?next=%2Fhome", Error: "access_denied"},
+			expectedStatus:   http.StatusFound,
+			expectedLocation: "http://localhost:5173/?error=access_denied&next=%2Fhome",
+		},
+		{
+			testName:       "無効なstate",
+			mode:           CallbackModeCode,
+			err:            usecase.ErrInvalidState,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			oauthUC := new(MockOAuthUsecase)
+			expectedInput := &usecase.OAuthCallbackInput{
+				Code:           "google_code",
+				State:          "state_123",
+				Nonce:          "nonce_123",
+				IssueLoginCode: tt.mode == CallbackModeCode,
+				ClientIP:       "192.0.2.1",
+				UserAgent:      "test-agent",
+			}
+			if tt.err != nil {
+				oauthUC.On("HandleCallback", mock.Anything, expectedInput).Return(nil, tt.err)
+			} else {
+				oauthUC.On("HandleCallback", mock.Anything, expectedInput).Return(tt.output, nil)
+			}
+			handler := NewOAuthHandler(oauthUC, tt.mode)
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/auth/google/callback?code=google_code&state=state_123", nil)
+			req.RemoteAddr = "192.0.2.1:12345"
+			req.Header.Set("User-Agent", "test-agent")
+			req.AddCookie(&http.Cookie{Name: OAuthNonceCookieName, Value: "nonce_123"})
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := handler.GoogleCallback(c)
+
+			cookies := map[string]*http.Cookie{}
+			for _, cookie := range rec.Result().Cookies() {
+				cookies[cookie.Name] = cookie
+			}
+			// nonceのCookieは結果にかかわらず削除する
+			if assert.Contains(t, cookies, OAuthNonceCookieName) {
+				assert.Equal(t, -1, cookies[OAuthNonceCookieName].MaxAge)
+				delete(cookies, OAuthNonceCookieName)
+			}
+
+			if tt.err != nil {
+				if appErr := core.AsAppError(err); assert.NotNil(t, appErr) {
+					assert.Equal(t, tt.expectedStatus, appErr.Status)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedStatus, rec.Code)
+				assert.Equal(t, tt.expectedLocation, rec.Header().Get(echo.HeaderLocation))
+
+				if tt.expectCookies {
+					if assert.Contains(t, cookies, authMiddleware.AccessTokenCookieName) {
+						assert.Equal(t, "access_token", cookies[authMiddleware.AccessTokenCookieName].Value)
+						assert.True(t, cookies[authMiddleware.AccessTokenCookieName].HttpOnly)
+					}
+					assert.Contains(t, cookies, authMiddleware.RefreshTokenCookieName)
+				} else {
+					assert.Empty(t, cookies)
+				}
+			}
+
+			oauthUC.AssertExpectations(t)
+		})
+	}
+}
+
+func TestOAuthHandler_ExchangeLoginCode(t *testing.T) {
+	tests := []struct {
+		testName       string
+		code           string
+		setupMocks     func(*MockOAuthUsecase)
+		expectedStatus int
+		expectError    bool
+	}{
+		{
+			testName: "正常なコード交換",
+			code:     "one_time",
+			setupMocks: func(oauthUC *MockOAuthUsecase) {
+				output := &usecase.GoogleLoginOutput{
+					User:         &model.User{ID: "user_123"},
+					AccessToken:  "access_token",
+					RefreshToken: "refresh_token",
+					ExpiresIn:    3600,
+				}
+				oauthUC.On("ExchangeLoginCode", mock.Anything, &usecase.ExchangeLoginCodeInput{Code: "one_time"}).Return(output, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			testName: "無効なコード",
+			code:     "used",
+			setupMocks: func(oauthUC *MockOAuthUsecase) {
+				oauthUC.On("ExchangeLoginCode", mock.Anything, &usecase.ExchangeLoginCodeInput{Code: "used"}).Return(nil, usecase.ErrInvalidLoginCode)
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectError:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			oauthUC := new(MockOAuthUsecase)
+			tt.setupMocks(oauthUC)
+			handler := NewOAuthHandler(oauthUC, CallbackModeCode)
+
+			e := echo.New()
+			body, _ := json.Marshal(ExchangeLoginCodeRequest{Code: tt.code})
+			req := httptest.NewRequest(http.MethodPost, "/auth/google/exchange", bytes.NewReader(body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := handler.ExchangeLoginCode(c)
+
+			if tt.expectError {
+				if appErr := core.AsAppError(err); assert.NotNil(t, appErr) {
+					assert.Equal(t, tt.expectedStatus, appErr.Status)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedStatus, rec.Code)
+
+				var response GoogleLoginResponse
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+				assert.Equal(t, "access_token", response.AccessToken)
+			}
+
+			oauthUC.AssertExpectations(t)
+		})
+	}
+}
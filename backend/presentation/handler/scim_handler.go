@@ -0,0 +1,479 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"stackies-backend/domain/model"
+	"stackies-backend/presentation/middleware"
+	"stackies-backend/usecase"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// SCIMのスキーマ（RFC 7643・RFC 7644）
+const (
+	scimUserSchema         = "urn:ietf:params:scim:schemas:core:2.0:User"
+	scimGroupSchema        = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	scimListResponseSchema = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+)
+
+// SCIMHandler は連携元のディレクトリからのSCIM 2.0によるプロビジョニングのHTTPハンドラーを表す
+type SCIMHandler struct {
+	scimUsecase usecase.SCIMUsecase
+}
+
+// NewSCIMHandler はSCIMHandlerの新しいインスタンスを作成する
+func NewSCIMHandler(scimUsecase usecase.SCIMUsecase) *SCIMHandler {
+	return &SCIMHandler{
+		scimUsecase: scimUsecase,
+	}
+}
+
+type (
+	// SCIMName はSCIMのユーザーの名前を表す（formattedのみ保存し、姓・名は表示名を組み立てるのに使う）
+	SCIMName struct {
+		Formatted  string `json:"formatted,omitempty"`
+		GivenName  string `json:"givenName,omitempty"`
+		FamilyName string `json:"familyName,omitempty"`
+	}
+
+	// SCIMEmail はSCIMのユーザーのメールアドレスを表す
+	SCIMEmail struct {
+		Value   string `json:"value"`
+		Type    string `json:"type,omitempty"`
+		Primary bool   `json:"primary,omitempty"`
+	}
+
+	// SCIMMember はSCIMのグループのメンバーを表す（valueはユーザーID）
+	SCIMMember struct {
+		Value   string `json:"value"`
+		Display string `json:"display,omitempty"`
+	}
+
+	// SCIMMeta はSCIMのリソースのメタデータを表す
+	SCIMMeta struct {
+		ResourceType string    `json:"resourceType"`
+		Created      time.Time `json:"created"`
+		LastModified time.Time `json:"lastModified"`
+		Location     string    `json:"location"`
+	}
+
+	// SCIMUserRequest はユーザーの登録・置き換えのリクエスト構造体を表す
+	SCIMUserRequest struct {
+		ExternalID  string      `json:"externalId"`
+		UserName    string      `json:"userName"`
+		DisplayName string      `json:"displayName"`
+		Name        *SCIMName   `json:"name"`
+		Emails      []SCIMEmail `json:"emails"`
+		// Active は省略した場合に状態を変更しない（置き換えでも管理者による停止・無効化を解除しない）
+		Active *bool `json:"active"`
+	}
+
+	// SCIMGroupRequest はグループの登録・置き換えのリクエスト構造体を表す
+	SCIMGroupRequest struct {
+		ExternalID  string       `json:"externalId"`
+		DisplayName string       `json:"displayName"`
+		Members     []SCIMMember `json:"members"`
+	}
+
+	// SCIMPatchRequest はPATCHのリクエスト構造体を表す
+	SCIMPatchRequest struct {
+		Operations []SCIMPatchOperation `json:"Operations"`
+	}
+
+	// SCIMPatchOperation はPATCHの操作を表す
+	SCIMPatchOperation struct {
+		Op    string          `json:"op"`
+		Path  string          `json:"path"`
+		Value json.RawMessage `json:"value"`
+	}
+
+	// SCIMUserResponse はSCIMのユーザーのレスポンス構造体を表す
+	SCIMUserResponse struct {
+		Schemas     []string    `json:"schemas"`
+		ID          string      `json:"id"`
+		ExternalID  string      `json:"externalId,omitempty"`
+		UserName    string      `json:"userName"`
+		DisplayName string      `json:"displayName"`
+		Name        SCIMName    `json:"name"`
+		Emails      []SCIMEmail `json:"emails"`
+		// Active は停止・無効化されていない場合にtrue（本人のログインを待っているユーザーを含む）
+		Active bool     `json:"active"`
+		Meta   SCIMMeta `json:"meta"`
+	}
+
+	// SCIMGroupResponse はSCIMのグループのレスポンス構造体を表す
+	SCIMGroupResponse struct {
+		Schemas     []string     `json:"schemas"`
+		ID          string       `json:"id"`
+		ExternalID  string       `json:"externalId,omitempty"`
+		DisplayName string       `json:"displayName"`
+		Members     []SCIMMember `json:"members,omitempty"`
+		Meta        SCIMMeta     `json:"meta"`
+	}
+
+	// SCIMListResponse はSCIMの一覧のレスポンス構造体を表す
+	SCIMListResponse struct {
+		Schemas      []string `json:"schemas"`
+		TotalResults int      `json:"totalResults"`
+		StartIndex   int      `json:"startIndex"`
+		ItemsPerPage int      `json:"itemsPerPage"`
+		Resources    any      `json:"Resources"`
+	}
+)
+
+// ListUsers はfilter・startIndex・countに応じてユーザーの一覧を返す
+func (h *SCIMHandler) ListUsers(c echo.Context) error {
+	input, err := scimListInput(c)
+	if err != nil {
+		return err
+	}
+	page, err := h.scimUsecase.ListUsers(c.Request().Context(), input)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	resources := make([]*SCIMUserResponse, 0, len(page.Users))
+	for _, user := range page.Users {
+		resources = append(resources, newSCIMUserResponse(c, user, now))
+	}
+	return scimJSON(c, http.StatusOK, &SCIMListResponse{
+		Schemas:      []string{scimListResponseSchema},
+		TotalResults: page.TotalResults,
+		StartIndex:   page.StartIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	})
+}
+
+// GetUser は指定したユーザーを返す
+func (h *SCIMHandler) GetUser(c echo.Context) error {
+	user, err := h.scimUsecase.GetUser(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return err
+	}
+
+	return scimJSON(c, http.StatusOK, newSCIMUserResponse(c, user, time.Now()))
+}
+
+// CreateUser はユーザーを登録する（本人が認証済みのメールアドレスでログインした時点で有効になる）
+func (h *SCIMHandler) CreateUser(c echo.Context) error {
+	var req SCIMUserRequest
+	if err := bindSCIM(c, &req); err != nil {
+		return err
+	}
+
+	user, err := h.scimUsecase.CreateUser(c.Request().Context(), req.input(c, ""))
+	if err != nil {
+		return err
+	}
+
+	response := newSCIMUserResponse(c, user, time.Now())
+	c.Response().Header().Set(echo.HeaderLocation, response.Meta.Location)
+	return scimJSON(c, http.StatusCreated, response)
+}
+
+// ReplaceUser は指定したユーザーの属性を置き換える
+func (h *SCIMHandler) ReplaceUser(c echo.Context) error {
+	var req SCIMUserRequest
+	if err := bindSCIM(c, &req); err != nil {
+		return err
+	}
+
+	user, err := h.scimUsecase.ReplaceUser(c.Request().Context(), req.input(c, c.Param("id")))
+	if err != nil {
+		return err
+	}
+
+	return scimJSON(c, http.StatusOK, newSCIMUserResponse(c, user, time.Now()))
+}
+
+// PatchUser は指定したユーザーにPATCHの操作を適用する（activeをfalseにすると無効化する）
+func (h *SCIMHandler) PatchUser(c echo.Context) error {
+	input, err := scimPatchInput(c)
+	if err != nil {
+		return err
+	}
+
+	user, err := h.scimUsecase.PatchUser(c.Request().Context(), input)
+	if err != nil {
+		return err
+	}
+
+	return scimJSON(c, http.StatusOK, newSCIMUserResponse(c, user, time.Now()))
+}
+
+// DeleteUser は指定したユーザーを無効化し、セッションを無効化する
+func (h *SCIMHandler) DeleteUser(c echo.Context) error {
+	err := h.scimUsecase.DeleteUser(c.Request().Context(), &usecase.SCIMDeleteInput{
+		ID:        c.Param("id"),
+		ClientIP:  c.RealIP(),
+		UserAgent: c.Request().UserAgent(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ListGroups はfilter・startIndex・countに応じてグループの一覧を返す
+// excludedAttributes=membersの場合はメンバーを含めない
+func (h *SCIMHandler) ListGroups(c echo.Context) error {
+	input, err := scimListInput(c)
+	if err != nil {
+		return err
+	}
+	page, err := h.scimUsecase.ListGroups(c.Request().Context(), input)
+	if err != nil {
+		return err
+	}
+
+	resources := make([]*SCIMGroupResponse, 0, len(page.Groups))
+	for _, group := range page.Groups {
+		resources = append(resources, newSCIMGroupResponse(c, group))
+	}
+	return scimJSON(c, http.StatusOK, &SCIMListResponse{
+		Schemas:      []string{scimListResponseSchema},
+		TotalResults: page.TotalResults,
+		StartIndex:   page.StartIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	})
+}
+
+// GetGroup は指定したグループを返す
+func (h *SCIMHandler) GetGroup(c echo.Context) error {
+	group, err := h.scimUsecase.GetGroup(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return err
+	}
+
+	return scimJSON(c, http.StatusOK, newSCIMGroupResponse(c, group))
+}
+
+// CreateGroup はグループを登録する
+func (h *SCIMHandler) CreateGroup(c echo.Context) error {
+	var req SCIMGroupRequest
+	if err := bindSCIM(c, &req); err != nil {
+		return err
+	}
+
+	group, err := h.scimUsecase.CreateGroup(c.Request().Context(), req.input(c, ""))
+	if err != nil {
+		return err
+	}
+
+	response := newSCIMGroupResponse(c, group)
+	c.Response().Header().Set(echo.HeaderLocation, response.Meta.Location)
+	return scimJSON(c, http.StatusCreated, response)
+}
+
+// ReplaceGroup は指定したグループの表示名・メンバーを置き換える
+func (h *SCIMHandler) ReplaceGroup(c echo.Context) error {
+	var req SCIMGroupRequest
+	if err := bindSCIM(c, &req); err != nil {
+		return err
+	}
+
+	group, err := h.scimUsecase.ReplaceGroup(c.Request().Context(), req.input(c, c.Param("id")))
+	if err != nil {
+		return err
+	}
+
+	return scimJSON(c, http.StatusOK, newSCIMGroupResponse(c, group))
+}
+
+// PatchGroup は指定したグループにPATCHの操作を適用する
+func (h *SCIMHandler) PatchGroup(c echo.Context) error {
+	input, err := scimPatchInput(c)
+	if err != nil {
+		return err
+	}
+
+	group, err := h.scimUsecase.PatchGroup(c.Request().Context(), input)
+	if err != nil {
+		return err
+	}
+
+	return scimJSON(c, http.StatusOK, newSCIMGroupResponse(c, group))
+}
+
+// DeleteGroup は指定したグループを削除する
+func (h *SCIMHandler) DeleteGroup(c echo.Context) error {
+	err := h.scimUsecase.DeleteGroup(c.Request().Context(), &usecase.SCIMDeleteInput{
+		ID:        c.Param("id"),
+		ClientIP:  c.RealIP(),
+		UserAgent: c.Request().UserAgent(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// input はリクエストをユースケースの入力に変換する
+// 表示名はdisplayName、name.formatted、姓・名、userNameの順に使い、userNameがメールアドレスでない場合はプライマリのメールアドレスを使う
+func (r *SCIMUserRequest) input(c echo.Context, id string) *usecase.SCIMUserInput {
+	userName := strings.TrimSpace(r.UserName)
+	if !strings.Contains(userName, "@") {
+		for _, email := range r.Emails {
+			if email.Primary || len(r.Emails) == 1 {
+				userName = email.Value
+				break
+			}
+		}
+	}
+
+	displayName := strings.TrimSpace(r.DisplayName)
+	if displayName == "" && r.Name != nil {
+		displayName = strings.TrimSpace(r.Name.Formatted)
+		if displayName == "" {
+			displayName = strings.TrimSpace(r.Name.GivenName + " " + r.Name.FamilyName)
+		}
+	}
+	if displayName == "" {
+		displayName = userName
+	}
+
+	return &usecase.SCIMUserInput{
+		ID:          id,
+		UserName:    userName,
+		ExternalID:  r.ExternalID,
+		DisplayName: displayName,
+		Active:      r.Active,
+		ClientIP:    c.RealIP(),
+		UserAgent:   c.Request().UserAgent(),
+	}
+}
+
+// input はリクエストをユースケースの入力に変換する
+func (r *SCIMGroupRequest) input(c echo.Context, id string) *usecase.SCIMGroupInput {
+	memberIDs := make([]string, 0, len(r.Members))
+	for _, member := range r.Members {
+		memberIDs = append(memberIDs, member.Value)
+	}
+	return &usecase.SCIMGroupInput{
+		ID:          id,
+		DisplayName: r.DisplayName,
+		ExternalID:  r.ExternalID,
+		MemberIDs:   memberIDs,
+		ClientIP:    c.RealIP(),
+		UserAgent:   c.Request().UserAgent(),
+	}
+}
+
+// newSCIMUserResponse はユーザーをSCIMのユーザーに変換する
+func newSCIMUserResponse(c echo.Context, user *model.User, now time.Time) *SCIMUserResponse {
+	return &SCIMUserResponse{
+		Schemas:     []string{scimUserSchema},
+		ID:          user.ID,
+		ExternalID:  user.ExternalID,
+		UserName:    user.Email,
+		DisplayName: user.Name,
+		Name:        SCIMName{Formatted: user.Name},
+		Emails:      []SCIMEmail{{Value: user.Email, Type: "work", Primary: true}},
+		Active:      !user.IsBlocked(now),
+		Meta: SCIMMeta{
+			ResourceType: "User",
+			Created:      user.CreatedAt,
+			LastModified: user.UpdatedAt,
+			Location:     scimLocation(c, "Users", user.ID),
+		},
+	}
+}
+
+// newSCIMGroupResponse はグループをSCIMのグループに変換する
+func newSCIMGroupResponse(c echo.Context, group *model.Group) *SCIMGroupResponse {
+	response := &SCIMGroupResponse{
+		Schemas:     []string{scimGroupSchema},
+		ID:          group.ID,
+		ExternalID:  group.ExternalID,
+		DisplayName: group.DisplayName,
+		Meta: SCIMMeta{
+			ResourceType: "Group",
+			Created:      group.CreatedAt,
+			LastModified: group.UpdatedAt,
+			Location:     scimLocation(c, "Groups", group.ID),
+		},
+	}
+	if strings.EqualFold(c.QueryParam("excludedAttributes"), "members") {
+		return response
+	}
+	response.Members = make([]SCIMMember, 0, len(group.MemberIDs))
+	for _, memberID := range group.MemberIDs {
+		response.Members = append(response.Members, SCIMMember{Value: memberID})
+	}
+	return response
+}
+
+// scimLocation はリソースのURLを返す
+func scimLocation(c echo.Context, resourceType, id string) string {
+	return c.Scheme() + "://" + c.Request().Host + "/scim/v2/" + resourceType + "/" + id
+}
+
+// scimListInput は一覧のクエリパラメータを解析する
+func scimListInput(c echo.Context) (*usecase.SCIMListInput, error) {
+	input := &usecase.SCIMListInput{Filter: c.QueryParam("filter"), StartIndex: 1}
+	if raw := c.QueryParam("startIndex"); raw != "" {
+		startIndex, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, usecase.ErrSCIMInvalidSyntax.WithMessage("Invalid startIndex")
+		}
+		input.StartIndex = startIndex
+	}
+	if raw := c.QueryParam("count"); raw != "" {
+		count, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, usecase.ErrSCIMInvalidSyntax.WithMessage("Invalid count")
+		}
+		input.Count = &count
+	}
+	return input, nil
+}
+
+// scimPatchInput はPATCHのリクエストをユースケースの入力に変換する
+func scimPatchInput(c echo.Context) (*usecase.SCIMPatchInput, error) {
+	var req SCIMPatchRequest
+	if err := bindSCIM(c, &req); err != nil {
+		return nil, err
+	}
+
+	operations := make([]usecase.SCIMPatchOperation, 0, len(req.Operations))
+	for _, operation := range req.Operations {
+		operations = append(operations, usecase.SCIMPatchOperation{
+			Op:    operation.Op,
+			Path:  operation.Path,
+			Value: operation.Value,
+		})
+	}
+	return &usecase.SCIMPatchInput{
+		ID:         c.Param("id"),
+		Operations: operations,
+		ClientIP:   c.RealIP(),
+		UserAgent:  c.Request().UserAgent(),
+	}, nil
+}
+
+// bindSCIM はリクエストボディを読み込む
+// EchoのBindはapplication/scim+jsonを扱えないため、Content-Typeによらずに解析する
+func bindSCIM(c echo.Context, v any) error {
+	if err := json.NewDecoder(c.Request().Body).Decode(v); err != nil {
+		return usecase.ErrSCIMInvalidSyntax.Wrap(err)
+	}
+	return nil
+}
+
+// scimJSON はapplication/scim+jsonでレスポンスを返す
+func scimJSON(c echo.Context, status int, v any) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.Blob(status, middleware.MIMEApplicationSCIMJSON, body)
+}
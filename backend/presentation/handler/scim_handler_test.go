@@ -0,0 +1,427 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"stackies-backend/core"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/repository"
+	"stackies-backend/presentation/middleware"
+	"stackies-backend/usecase"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockSCIMUsecase はSCIMUsecaseのモック
+type MockSCIMUsecase struct {
+	mock.Mock
+}
+
+var _ usecase.SCIMUsecase = (*MockSCIMUsecase)(nil)
+
+func (m *MockSCIMUsecase) ListUsers(ctx context.Context, input *usecase.SCIMListInput) (*usecase.SCIMUserPage, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*usecase.SCIMUserPage), args.Error(1)
+}
+
+func (m *MockSCIMUsecase) GetUser(ctx context.Context, id string) (*model.User, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.User), args.Error(1)
+}
+
+func (m *MockSCIMUsecase) CreateUser(ctx context.Context, input *usecase.SCIMUserInput) (*model.User, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.User), args.Error(1)
+}
+
+func (m *MockSCIMUsecase) ReplaceUser(ctx context.Context, input *usecase.SCIMUserInput) (*model.User, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.User), args.Error(1)
+}
+
+func (m *MockSCIMUsecase) PatchUser(ctx context.Context, input *usecase.SCIMPatchInput) (*model.User, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.User), args.Error(1)
+}
+
+func (m *MockSCIMUsecase) DeleteUser(ctx context.Context, input *usecase.SCIMDeleteInput) error {
+	args := m.Called(ctx, input)
+	return args.Error(0)
+}
+
+func (m *MockSCIMUsecase) ListGroups(ctx context.Context, input *usecase.SCIMListInput) (*usecase.SCIMGroupPage, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*usecase.SCIMGroupPage), args.Error(1)
+}
+
+func (m *MockSCIMUsecase) GetGroup(ctx context.Context, id string) (*model.Group, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Group), args.Error(1)
+}
+
+func (m *MockSCIMUsecase) CreateGroup(ctx context.Context, input *usecase.SCIMGroupInput) (*model.Group, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Group), args.Error(1)
+}
+
+func (m *MockSCIMUsecase) ReplaceGroup(ctx context.Context, input *usecase.SCIMGroupInput) (*model.Group, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Group), args.Error(1)
+}
+
+func (m *MockSCIMUsecase) PatchGroup(ctx context.Context, input *usecase.SCIMPatchInput) (*model.Group, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Group), args.Error(1)
+}
+
+func (m *MockSCIMUsecase) DeleteGroup(ctx context.Context, input *usecase.SCIMDeleteInput) error {
+	args := m.Called(ctx, input)
+	return args.Error(0)
+}
+
+func TestSCIMHandler_CreateUser(t *testing.T) {
+	tests := []struct {
+		testName       string
+		body           string
+		matchInput     func(*usecase.SCIMUserInput) bool
+		err            error
+		expectedStatus int
+	}{
+		{
+			testName: "姓・名から表示名を組み立てて登録する",
+			body:     `{"schemas":["urn:ietf:params:scim:schemas:core:2.0:User"],"userName":"alice@example.com","externalId":"emp-1","name":{"givenName":"Alice","familyName":"Smith"}}`,
+			matchInput: func(input *usecase.SCIMUserInput) bool {
+				return input.UserName == "alice@example.com" &&
+					input.DisplayName == "Alice Smith" &&
+					input.ExternalID == "emp-1" &&
+					input.Active == nil && input.ClientIP == "192.0.2.1"
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			testName: "userNameがメールアドレスでない場合はプライマリのメールアドレスを使う",
+			body:     `{"userName":"alice","displayName":"Alice","active":false,"emails":[{"value":"home@example.com"},{"value":"alice@example.com","primary":true}]}`,
+			matchInput: func(input *usecase.SCIMUserInput) bool {
+				return input.UserName == "alice@example.com" && input.DisplayName == "Alice" && input.Active != nil && !*input.Active
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			testName:       "同じメールアドレスのユーザーが存在する",
+			body:           `{"userName":"alice@example.com"}`,
+			matchInput:     func(input *usecase.SCIMUserInput) bool { return true },
+			err:            usecase.ErrSCIMUniqueness,
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			scimUC := new(MockSCIMUsecase)
+			if tt.err != nil {
+				scimUC.On("CreateUser", mock.Anything, mock.MatchedBy(tt.matchInput)).Return(nil, tt.err)
+			} else {
+				scimUC.On("CreateUser", mock.Anything, mock.MatchedBy(tt.matchInput)).Return(&model.User{
+					ID:        "user_1",
+					Email:     "alice@example.com",
+					Name:      "Alice Smith",
+					Status:    model.UserStatusPendingVerification,
+					CreatedAt: time.Now(),
+					UpdatedAt: time.Now(),
+				}, nil)
+			}
+			handler := NewSCIMHandler(scimUC)
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/scim/v2/Users", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, middleware.MIMEApplicationSCIMJSON)
+			req.RemoteAddr = "192.0.2.1:12345"
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := handler.CreateUser(c)
+
+			if tt.err != nil {
+				if appErr := core.AsAppError(err); assert.NotNil(t, appErr) {
+					assert.Equal(t, tt.expectedStatus, appErr.Status)
+				}
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectedStatus, rec.Code)
+				assert.Equal(t, middleware.MIMEApplicationSCIMJSON, rec.Header().Get(echo.HeaderContentType))
+				assert.Equal(t, "http://example.com/scim/v2/Users/user_1", rec.Header().Get(echo.HeaderLocation))
+
+				var body SCIMUserResponse
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+				assert.Equal(t, []string{scimUserSchema}, body.Schemas)
+				assert.Equal(t, "alice@example.com", body.UserName)
+				// 本人のログインを待っているユーザーはactiveとして返す
+				assert.True(t, body.Active)
+			}
+			scimUC.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSCIMHandler_ListUsers(t *testing.T) {
+	tests := []struct {
+		testName       string
+		query          string
+		setupMocks     func(*MockSCIMUsecase)
+		expectedStatus int
+	}{
+		{
+			testName: "filter・startIndex・countで絞り込む",
+			query:    `?filter=userName+eq+%22alice%40example.com%22&startIndex=2&count=10`,
+			setupMocks: func(scimUC *MockSCIMUsecase) {
+				scimUC.On("ListUsers", mock.Anything, mock.MatchedBy(func(input *usecase.SCIMListInput) bool {
+					return input.Filter == `userName eq "alice@example.com"` &&
+						input.StartIndex == 2 &&
+						input.Count != nil && *input.Count == 10
+				})).Return(&usecase.SCIMUserPage{
+					Users:        []*model.User{{ID: "user_1", Email: "alice@example.com", Name: "Alice", Status: model.UserStatusDisabled}},
+					TotalResults: 11,
+					StartIndex:   2,
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			testName:       "不正なcount",
+			query:          "?count=many",
+			setupMocks:     func(scimUC *MockSCIMUsecase) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			testName: "不正なfilter",
+			query:    "?filter=userName",
+			setupMocks: func(scimUC *MockSCIMUsecase) {
+				scimUC.On("ListUsers", mock.Anything, mock.Anything).Return(nil, usecase.ErrSCIMInvalidFilter)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			scimUC := new(MockSCIMUsecase)
+			tt.setupMocks(scimUC)
+			handler := NewSCIMHandler(scimUC)
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/scim/v2/Users"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := handler.ListUsers(c)
+
+			if tt.expectedStatus != http.StatusOK {
+				if appErr := core.AsAppError(err); assert.NotNil(t, appErr) {
+					assert.Equal(t, tt.expectedStatus, appErr.Status)
+				}
+			} else {
+				require.NoError(t, err)
+				var body struct {
+					Schemas      []string            `json:"schemas"`
+					TotalResults int                 `json:"totalResults"`
+					StartIndex   int                 `json:"startIndex"`
+					ItemsPerPage int                 `json:"itemsPerPage"`
+					Resources    []*SCIMUserResponse `json:"Resources"`
+				}
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+				assert.Equal(t, []string{scimListResponseSchema}, body.Schemas)
+				assert.Equal(t, 11, body.TotalResults)
+				assert.Equal(t, 2, body.StartIndex)
+				assert.Equal(t, 1, body.ItemsPerPage)
+				require.Len(t, body.Resources, 1)
+				assert.False(t, body.Resources[0].Active)
+			}
+			scimUC.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSCIMHandler_PatchUser(t *testing.T) {
+	scimUC := new(MockSCIMUsecase)
+	scimUC.On("PatchUser", mock.Anything, mock.MatchedBy(func(input *usecase.SCIMPatchInput) bool {
+		return input.ID == "user_1" &&
+			len(input.Operations) == 1 &&
+			input.Operations[0].Op == "Replace" &&
+			input.Operations[0].Path == "active" &&
+			string(input.Operations[0].Value) == "false"
+	})).Return(&model.User{ID: "user_1", Email: "alice@example.com", Name: "Alice", Status: model.UserStatusDisabled}, nil)
+	handler := NewSCIMHandler(scimUC)
+
+	e := echo.New()
+	body := `{"schemas":["urn:ietf:params:scim:api:messages:2.0:PatchOp"],"Operations":[{"op":"Replace","path":"active","value":false}]}`
+	req := httptest.NewRequest(http.MethodPatch, "/scim/v2/Users/user_1", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, middleware.MIMEApplicationSCIMJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("user_1")
+
+	err := handler.PatchUser(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"active":false`)
+	scimUC.AssertExpectations(t)
+}
+
+func TestSCIMHandler_DeleteUser(t *testing.T) {
+	tests := []struct {
+		testName       string
+		err            error
+		expectedStatus int
+	}{
+		{
+			testName:       "ユーザーを無効化する",
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			testName:       "存在しないユーザー",
+			err:            repository.ErrUserNotFound,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			scimUC := new(MockSCIMUsecase)
+			scimUC.On("DeleteUser", mock.Anything, mock.MatchedBy(func(input *usecase.SCIMDeleteInput) bool {
+				return input.ID == "user_1"
+			})).Return(tt.err)
+			handler := NewSCIMHandler(scimUC)
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodDelete, "/scim/v2/Users/user_1", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues("user_1")
+
+			err := handler.DeleteUser(c)
+
+			if tt.err != nil {
+				if appErr := core.AsAppError(err); assert.NotNil(t, appErr) {
+					assert.Equal(t, tt.expectedStatus, appErr.Status)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedStatus, rec.Code)
+			}
+			scimUC.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSCIMHandler_GetGroup(t *testing.T) {
+	tests := []struct {
+		testName        string
+		query           string
+		expectedMembers bool
+	}{
+		{testName: "メンバーを含めて返す", query: "", expectedMembers: true},
+		{testName: "excludedAttributesでメンバーを除く", query: "?excludedAttributes=members", expectedMembers: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			scimUC := new(MockSCIMUsecase)
+			scimUC.On("GetGroup", mock.Anything, "group_1").Return(&model.Group{
+				ID:          "group_1",
+				DisplayName: "Sales",
+				MemberIDs:   []string{"user_1"},
+			}, nil)
+			handler := NewSCIMHandler(scimUC)
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/scim/v2/Groups/group_1"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues("group_1")
+
+			err := handler.GetGroup(c)
+
+			require.NoError(t, err)
+			var body SCIMGroupResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			assert.Equal(t, "Sales", body.DisplayName)
+			assert.Equal(t, "Group", body.Meta.ResourceType)
+			if tt.expectedMembers {
+				assert.Equal(t, []SCIMMember{{Value: "user_1"}}, body.Members)
+			} else {
+				assert.Empty(t, body.Members)
+			}
+		})
+	}
+}
+
+func TestSCIMHandler_CreateGroup(t *testing.T) {
+	scimUC := new(MockSCIMUsecase)
+	scimUC.On("CreateGroup", mock.Anything, mock.MatchedBy(func(input *usecase.SCIMGroupInput) bool {
+		return input.DisplayName == "Admins" && input.ExternalID == "dir-1" &&
+			len(input.MemberIDs) == 2 && input.MemberIDs[0] == "user_1" && input.MemberIDs[1] == "user_2"
+	})).Return(&model.Group{ID: "group_1", DisplayName: "Admins", ExternalID: "dir-1", MemberIDs: []string{"user_1", "user_2"}}, nil)
+	handler := NewSCIMHandler(scimUC)
+
+	e := echo.New()
+	body := `{"displayName":"Admins","externalId":"dir-1","members":[{"value":"user_1"},{"value":"user_2","display":"Bob"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/scim/v2/Groups", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, middleware.MIMEApplicationSCIMJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	err := handler.CreateGroup(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "http://example.com/scim/v2/Groups/group_1", rec.Header().Get(echo.HeaderLocation))
+	scimUC.AssertExpectations(t)
+
+	// 不正なJSONは解析しない
+	req = httptest.NewRequest(http.MethodPost, "/scim/v2/Groups", strings.NewReader(`{"displayName":`))
+	c = e.NewContext(req, httptest.NewRecorder())
+	err = handler.CreateGroup(c)
+	assert.ErrorIs(t, err, usecase.ErrSCIMInvalidSyntax)
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"net/http"
+	"stackies-backend/core"
+	"stackies-backend/usecase"
+
+	"github.com/labstack/echo/v4"
+)
+
+// SecurityHandler はアカウント保護関連のHTTPハンドラーを表す
+type SecurityHandler struct {
+	loginRiskUsecase usecase.LoginRiskUsecase
+}
+
+// NewSecurityHandler はSecurityHandlerの新しいインスタンスを作成する
+func NewSecurityHandler(loginRiskUsecase usecase.LoginRiskUsecase) *SecurityHandler {
+	return &SecurityHandler{
+		loginRiskUsecase: loginRiskUsecase,
+	}
+}
+
+// ReportNotMeRequest は「心当たりがない」報告のリクエスト構造体を表す
+type ReportNotMeRequest struct {
+	Token string `json:"token"`
+}
+
+// ReportNotMe は不審なログイン通知の「心当たりがない」リンクから、ユーザーのすべてのセッションを無効化する
+// メールのリンク先（フロントエンド）からtokenをPOSTする想定で、認証は不要
+func (h *SecurityHandler) ReportNotMe(c echo.Context) error {
+	var req ReportNotMeRequest
+	if err := c.Bind(&req); err != nil {
+		return core.ErrBadRequest.Wrap(err)
+	}
+
+	input := &usecase.ReportNotMeInput{
+		Token:     req.Token,
+		ClientIP:  c.RealIP(),
+		UserAgent: c.Request().UserAgent(),
+	}
+
+	if err := h.loginRiskUsecase.ReportNotMe(c.Request().Context(), input); err != nil {
+		return err
+	}
+
+	clearSessionCookies(c)
+
+	return c.JSON(http.StatusOK, map[string]string{"message": "All sessions have been revoked"})
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"stackies-backend/core"
+	"stackies-backend/domain/model"
+	"stackies-backend/usecase"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockLoginRiskUsecase はLoginRiskUsecaseのモック
+type MockLoginRiskUsecase struct {
+	mock.Mock
+}
+
+var _ usecase.LoginRiskUsecase = (*MockLoginRiskUsecase)(nil)
+
+func (m *MockLoginRiskUsecase) Evaluate(ctx context.Context, input *usecase.EvaluateLoginRiskInput) (*model.RiskAssessment, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.RiskAssessment), args.Error(1)
+}
+
+func (m *MockLoginRiskUsecase) ReportNotMe(ctx context.Context, input *usecase.ReportNotMeInput) error {
+	args := m.Called(ctx, input)
+	return args.Error(0)
+}
+
+func TestSecurityHandler_ReportNotMe(t *testing.T) {
+	tests := []struct {
+		testName       string
+		err            error
+		expectedStatus int
+	}{
+		{
+			testName:       "全セッションを無効化",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			testName:       "無効なリンク",
+			err:            usecase.ErrInvalidRevocationToken,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			testName:       "内部エラー",
+			err:            errors.New("storage unavailable"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			loginRiskUC := new(MockLoginRiskUsecase)
+			loginRiskUC.On("ReportNotMe", mock.Anything, &usecase.ReportNotMeInput{
+				Token:     "revoke_token",
+				ClientIP:  "192.0.2.1",
+				UserAgent: "test-agent",
+			}).Return(tt.err)
+			handler := NewSecurityHandler(loginRiskUC)
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/auth/not-me", strings.NewReader(`{"token":"revoke_token"}`))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set("User-Agent", "test-agent")
+			req.RemoteAddr = "192.0.2.1:12345"
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := handler.ReportNotMe(c)
+
+			if tt.err != nil {
+				if appErr := core.AsAppError(err); assert.NotNil(t, appErr) {
+					assert.Equal(t, tt.expectedStatus, appErr.Status)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedStatus, rec.Code)
+				// このブラウザのセッションCookieも削除する
+				assert.NotEmpty(t, rec.Result().Cookies())
+			}
+
+			loginRiskUC.AssertExpectations(t)
+		})
+	}
+}
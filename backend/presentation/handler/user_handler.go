@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"stackies-backend/core"
+	"stackies-backend/usecase"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ErrAvatarTooLarge はアップロードされた画像が上限を超える場合のエラー
+var ErrAvatarTooLarge = core.NewAppError("avatar_too_large", http.StatusRequestEntityTooLarge, "Image is too large")
+
+// avatarCacheControl はプロフィール画像のキャッシュの指定
+// 画像のURLはアップロードのたびに変わるため、ブラウザ・CDNで1日キャッシュする
+const avatarCacheControl = "public, max-age=86400"
+
+// UserHandler はユーザーのプロフィールに関するHTTPハンドラーを表す
+type UserHandler struct {
+	userUsecase    usecase.UserUsecase
+	maxAvatarBytes int
+}
+
+// NewUserHandler はUserHandlerの新しいインスタンスを作成する
+// maxAvatarBytesはアップロードできる画像の最大サイズ
+func NewUserHandler(userUsecase usecase.UserUsecase, maxAvatarBytes int) *UserHandler {
+	return &UserHandler{
+		userUsecase:    userUsecase,
+		maxAvatarBytes: maxAvatarBytes,
+	}
+}
+
+// UpdateProfileRequest はプロフィール編集のリクエスト構造体を表す（省略した項目は変更しない）
+type UpdateProfileRequest struct {
+	Name    *string `json:"name"`
+	Picture *string `json:"picture"`
+}
+
+// UpdateMe は認証中のユーザーの表示名・画像を編集する
+func (h *UserHandler) UpdateMe(c echo.Context) error {
+	var req UpdateProfileRequest
+	if err := c.Bind(&req); err != nil {
+		return core.ErrBadRequest.Wrap(err)
+	}
+
+	user, err := h.userUsecase.UpdateProfile(c.Request().Context(), &usecase.UpdateProfileInput{
+		UserID:  c.Get("user_id").(string),
+		Name:    req.Name,
+		Picture: req.Picture,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, user)
+}
+
+// GetProfile は指定したユーザーの公開プロフィールを返す
+func (h *UserHandler) GetProfile(c echo.Context) error {
+	profile, err := h.userUsecase.GetPublicProfile(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, profile)
+}
+
+// UploadAvatar はマルチパートのfileの画像を認証中のユーザーのプロフィール画像にする
+func (h *UserHandler) UploadAvatar(c echo.Context) error {
+	header, err := c.FormFile("file")
+	if err != nil {
+		return core.ErrBadRequest.WithMessage("file is required").Wrap(err)
+	}
+	if header.Size > int64(h.maxAvatarBytes) {
+		return ErrAvatarTooLarge
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// 画像の形式はファイル名やContent-Typeではなく内容から判定するため、そのまま渡す
+	data, err := io.ReadAll(io.LimitReader(file, int64(h.maxAvatarBytes)+1))
+	if err != nil {
+		return err
+	}
+	if len(data) > h.maxAvatarBytes {
+		return ErrAvatarTooLarge
+	}
+
+	user, err := h.userUsecase.UploadAvatar(c.Request().Context(), c.Get("user_id").(string), data)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, user)
+}
+
+// DeleteAvatar は認証中のユーザーがアップロードした画像を削除する
+func (h *UserHandler) DeleteAvatar(c echo.Context) error {
+	user, err := h.userUsecase.DeleteAvatar(c.Request().Context(), c.Get("user_id").(string))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, user)
+}
+
+// GetAvatar は指定したユーザーのプロフィール画像を返す
+// sizeクエリで希望するサイズ（ピクセル）を指定でき、保存先によっては画像のURLにリダイレクトする
+func (h *UserHandler) GetAvatar(c echo.Context) error {
+	size := 0
+	if value := c.QueryParam("size"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return core.ErrBadRequest.WithMessage("size must be a number").Wrap(err)
+		}
+		size = parsed
+	}
+
+	output, err := h.userUsecase.GetAvatar(c.Request().Context(), c.Param("id"), size)
+	if err != nil {
+		return err
+	}
+
+	if output.RedirectURL != "" {
+		// 署名付きURLは有効期限があるため、リダイレクト自体はキャッシュさせない
+		c.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+		return c.Redirect(http.StatusFound, output.RedirectURL)
+	}
+
+	header := c.Response().Header()
+	header.Set(echo.HeaderCacheControl, avatarCacheControl)
+	header.Set("ETag", output.ETag)
+	// 保存したデータはサーバーで再エンコードした画像のみだが、念のためブラウザに内容を推測させない
+	header.Set("X-Content-Type-Options", "nosniff")
+	if match := c.Request().Header.Get("If-None-Match"); match == output.ETag {
+		return c.NoContent(http.StatusNotModified)
+	}
+	return c.Blob(http.StatusOK, output.Blob.ContentType, output.Blob.Data)
+}
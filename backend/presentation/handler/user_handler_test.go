@@ -0,0 +1,326 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"stackies-backend/core"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/repository"
+	"stackies-backend/usecase"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockUserUsecase はUserUsecaseのモック
+type MockUserUsecase struct {
+	mock.Mock
+}
+
+var _ usecase.UserUsecase = (*MockUserUsecase)(nil)
+
+func (m *MockUserUsecase) UpdateProfile(ctx context.Context, input *usecase.UpdateProfileInput) (*model.User, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.User), args.Error(1)
+}
+
+func (m *MockUserUsecase) GetPublicProfile(ctx context.Context, userID string) (*model.PublicProfile, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.PublicProfile), args.Error(1)
+}
+
+func (m *MockUserUsecase) UploadAvatar(ctx context.Context, userID string, data []byte) (*model.User, error) {
+	args := m.Called(ctx, userID, data)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.User), args.Error(1)
+}
+
+func (m *MockUserUsecase) DeleteAvatar(ctx context.Context, userID string) (*model.User, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.User), args.Error(1)
+}
+
+func (m *MockUserUsecase) GetAvatar(ctx context.Context, userID string, size int) (*usecase.AvatarOutput, error) {
+	args := m.Called(ctx, userID, size)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*usecase.AvatarOutput), args.Error(1)
+}
+
+// multipartFile はfileフィールドに指定したデータを含むマルチパートのリクエストボディを作成する
+func multipartFile(t *testing.T, data []byte) (*bytes.Buffer, string) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", "avatar.png")
+	require.NoError(t, err)
+	_, err = part.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	return body, writer.FormDataContentType()
+}
+
+func TestUserHandler_UpdateMe(t *testing.T) {
+	tests := []struct {
+		testName       string
+		body           string
+		matchInput     func(*usecase.UpdateProfileInput) bool
+		err            error
+		expectedStatus int
+	}{
+		{
+			testName: "表示名のみ編集",
+			body:     `{"name":"Edited User"}`,
+			matchInput: func(input *usecase.UpdateProfileInput) bool {
+				return input.UserID == "user_1" && *input.Name == "Edited User" && input.Picture == nil
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			testName: "不正なプロフィール",
+			body:     `{"name":""}`,
+			matchInput: func(input *usecase.UpdateProfileInput) bool {
+				return input.Name != nil && *input.Name == ""
+			},
+			err:            usecase.ErrInvalidProfile,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			userUC := new(MockUserUsecase)
+			if tt.err != nil {
+				userUC.On("UpdateProfile", mock.Anything, mock.MatchedBy(tt.matchInput)).Return(nil, tt.err)
+			} else {
+				userUC.On("UpdateProfile", mock.Anything, mock.MatchedBy(tt.matchInput)).Return(&model.User{ID: "user_1", Name: "Edited User"}, nil)
+			}
+			handler := NewUserHandler(userUC, 1<<20)
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPatch, "/users/me", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set("user_id", "user_1")
+
+			err := handler.UpdateMe(c)
+
+			if tt.err != nil {
+				if appErr := core.AsAppError(err); assert.NotNil(t, appErr) {
+					assert.Equal(t, tt.expectedStatus, appErr.Status)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedStatus, rec.Code)
+				assert.Contains(t, rec.Body.String(), `"name":"Edited User"`)
+			}
+
+			userUC.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUserHandler_GetProfile(t *testing.T) {
+	tests := []struct {
+		testName       string
+		profile        *model.PublicProfile
+		err            error
+		expectedStatus int
+	}{
+		{
+			testName:       "公開プロフィール",
+			profile:        &model.PublicProfile{ID: "user_1", Name: "Test User"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			testName:       "存在しないユーザー",
+			err:            repository.ErrUserNotFound,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			userUC := new(MockUserUsecase)
+			if tt.err != nil {
+				userUC.On("GetPublicProfile", mock.Anything, "user_1").Return(nil, tt.err)
+			} else {
+				userUC.On("GetPublicProfile", mock.Anything, "user_1").Return(tt.profile, nil)
+			}
+			handler := NewUserHandler(userUC, 1<<20)
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/users/user_1", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues("user_1")
+
+			err := handler.GetProfile(c)
+
+			if tt.err != nil {
+				if appErr := core.AsAppError(err); assert.NotNil(t, appErr) {
+					assert.Equal(t, tt.expectedStatus, appErr.Status)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedStatus, rec.Code)
+				assert.Contains(t, rec.Body.String(), `"name":"Test User"`)
+				assert.NotContains(t, rec.Body.String(), "email")
+			}
+
+			userUC.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUserHandler_UploadAvatar(t *testing.T) {
+	t.Run("アップロードした画像を渡す", func(t *testing.T) {
+		userUC := new(MockUserUsecase)
+		userUC.On("UploadAvatar", mock.Anything, "user_1", []byte("image data")).Return(&model.User{ID: "user_1", Name: "Test User", Picture: "http://localhost:8080/users/user_1/avatar?v=v1"}, nil)
+
+		body, contentType := multipartFile(t, []byte("image data"))
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/users/me/avatar", body)
+		req.Header.Set(echo.HeaderContentType, contentType)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("user_id", "user_1")
+
+		assert.NoError(t, NewUserHandler(userUC, 1<<20).UploadAvatar(c))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"picture":"http://localhost:8080/users/user_1/avatar?v=v1"`)
+		userUC.AssertExpectations(t)
+	})
+
+	t.Run("上限を超える画像", func(t *testing.T) {
+		userUC := new(MockUserUsecase)
+		body, contentType := multipartFile(t, bytes.Repeat([]byte("x"), 101))
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/users/me/avatar", body)
+		req.Header.Set(echo.HeaderContentType, contentType)
+		c := e.NewContext(req, httptest.NewRecorder())
+		c.Set("user_id", "user_1")
+
+		err := NewUserHandler(userUC, 100).UploadAvatar(c)
+		assert.ErrorIs(t, err, ErrAvatarTooLarge)
+		userUC.AssertNotCalled(t, "UploadAvatar", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("fileがない", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/users/me/avatar", strings.NewReader(`{}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		c := e.NewContext(req, httptest.NewRecorder())
+		c.Set("user_id", "user_1")
+
+		err := NewUserHandler(new(MockUserUsecase), 100).UploadAvatar(c)
+		assert.ErrorIs(t, err, core.ErrBadRequest)
+	})
+}
+
+func TestUserHandler_GetAvatar(t *testing.T) {
+	tests := []struct {
+		testName       string
+		query          string
+		ifNoneMatch    string
+		size           int
+		output         *usecase.AvatarOutput
+		err            error
+		expectedStatus int
+		expectedHeader map[string]string
+	}{
+		{
+			testName:       "保存した画像を返す",
+			query:          "?size=64",
+			size:           64,
+			output:         &usecase.AvatarOutput{Blob: &model.Blob{ContentType: "image/png", Data: []byte("png")}, ETag: `"v1-64"`},
+			expectedStatus: http.StatusOK,
+			expectedHeader: map[string]string{"Content-Type": "image/png", "ETag": `"v1-64"`, "Cache-Control": "public, max-age=86400"},
+		},
+		{
+			testName:       "変更がない場合は304",
+			ifNoneMatch:    `"v1-256"`,
+			output:         &usecase.AvatarOutput{Blob: &model.Blob{ContentType: "image/png", Data: []byte("png")}, ETag: `"v1-256"`},
+			expectedStatus: http.StatusNotModified,
+		},
+		{
+			testName:       "保存先のURLにリダイレクトする",
+			output:         &usecase.AvatarOutput{RedirectURL: "https://cdn.example.com/avatars/user_1/v1/256.jpg", ETag: `"v1-256"`},
+			expectedStatus: http.StatusFound,
+			expectedHeader: map[string]string{"Location": "https://cdn.example.com/avatars/user_1/v1/256.jpg"},
+		},
+		{
+			testName:       "画像がない",
+			err:            usecase.ErrAvatarNotFound,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			userUC := new(MockUserUsecase)
+			if tt.err != nil {
+				userUC.On("GetAvatar", mock.Anything, "user_1", tt.size).Return(nil, tt.err)
+			} else {
+				userUC.On("GetAvatar", mock.Anything, "user_1", tt.size).Return(tt.output, nil)
+			}
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/users/user_1/avatar"+tt.query, nil)
+			if tt.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues("user_1")
+
+			err := NewUserHandler(userUC, 1<<20).GetAvatar(c)
+
+			if tt.err != nil {
+				if appErr := core.AsAppError(err); assert.NotNil(t, appErr) {
+					assert.Equal(t, tt.expectedStatus, appErr.Status)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedStatus, rec.Code)
+				for key, value := range tt.expectedHeader {
+					assert.Equal(t, value, rec.Header().Get(key), key)
+				}
+			}
+
+			userUC.AssertExpectations(t)
+		})
+	}
+
+	t.Run("数値でないサイズ", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/users/user_1/avatar?size=large", nil)
+		c := e.NewContext(req, httptest.NewRecorder())
+		c.SetParamNames("id")
+		c.SetParamValues("user_1")
+
+		err := NewUserHandler(new(MockUserUsecase), 1<<20).GetAvatar(c)
+		assert.ErrorIs(t, err, core.ErrBadRequest)
+	})
+}
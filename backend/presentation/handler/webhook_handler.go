@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"net/http"
+	"stackies-backend/core"
+	"stackies-backend/domain/model"
+	"stackies-backend/usecase"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// WebhookHandler は管理者向けのWebhookの購読と配信履歴のHTTPハンドラーを表す
+type WebhookHandler struct {
+	webhookUsecase usecase.WebhookUsecase
+}
+
+// NewWebhookHandler はWebhookHandlerの新しいインスタンスを作成する
+func NewWebhookHandler(webhookUsecase usecase.WebhookUsecase) *WebhookHandler {
+	return &WebhookHandler{
+		webhookUsecase: webhookUsecase,
+	}
+}
+
+// CreateWebhookRequest は購読の作成のリクエスト構造体を表す
+type CreateWebhookRequest struct {
+	URL         string                  `json:"url"`
+	EventTypes  []model.DomainEventType `json:"event_types"`
+	Description string                  `json:"description"`
+	// Secret は署名に使うシークレット（省略した場合は生成する）
+	Secret string `json:"secret"`
+}
+
+// UpdateWebhookRequest は購読の変更のリクエスト構造体を表す（省略した項目は変更しない）
+type UpdateWebhookRequest struct {
+	URL         *string                 `json:"url"`
+	EventTypes  []model.DomainEventType `json:"event_types"`
+	Description *string                 `json:"description"`
+	Active      *bool                   `json:"active"`
+	Secret      *string                 `json:"secret"`
+}
+
+// WebhookWithSecretResponse は作成した購読とシークレットのレスポンス構造体を表す
+type WebhookWithSecretResponse struct {
+	*model.WebhookSubscription
+	Secret string `json:"secret"`
+}
+
+// WebhookListResponse は購読の一覧のレスポンス構造体を表す
+type WebhookListResponse struct {
+	Webhooks []*model.WebhookSubscription `json:"webhooks"`
+}
+
+// CreateWebhook は購読を作成し、署名に使うシークレットを返す（シークレットはこのレスポンスでのみ返す）
+func (h *WebhookHandler) CreateWebhook(c echo.Context) error {
+	var req CreateWebhookRequest
+	if err := c.Bind(&req); err != nil {
+		return core.ErrBadRequest.Wrap(err)
+	}
+
+	subscription, err := h.webhookUsecase.CreateSubscription(c.Request().Context(), &usecase.CreateWebhookInput{
+		ActorID:     c.Get("user_id").(string),
+		URL:         req.URL,
+		EventTypes:  req.EventTypes,
+		Description: req.Description,
+		Secret:      req.Secret,
+		ClientIP:    c.RealIP(),
+		UserAgent:   c.Request().UserAgent(),
+	})
+	if err != nil {
+		return err
+	}
+
+	c.Response().Header().Set("Cache-Control", "no-store")
+	return c.JSON(http.StatusCreated, &WebhookWithSecretResponse{
+		WebhookSubscription: subscription,
+		Secret:              subscription.Secret,
+	})
+}
+
+// ListWebhooks はすべての購読を作成日時の昇順で返す
+func (h *WebhookHandler) ListWebhooks(c echo.Context) error {
+	subscriptions, err := h.webhookUsecase.ListSubscriptions(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, &WebhookListResponse{Webhooks: subscriptions})
+}
+
+// GetWebhook は指定した購読を返す
+func (h *WebhookHandler) GetWebhook(c echo.Context) error {
+	subscription, err := h.webhookUsecase.GetSubscription(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, subscription)
+}
+
+// UpdateWebhook は指定した購読の送信先・イベント・有効状態・シークレットを変更する
+func (h *WebhookHandler) UpdateWebhook(c echo.Context) error {
+	var req UpdateWebhookRequest
+	if err := c.Bind(&req); err != nil {
+		return core.ErrBadRequest.Wrap(err)
+	}
+
+	subscription, err := h.webhookUsecase.UpdateSubscription(c.Request().Context(), &usecase.UpdateWebhookInput{
+		ActorID:     c.Get("user_id").(string),
+		ID:          c.Param("id"),
+		URL:         req.URL,
+		EventTypes:  req.EventTypes,
+		Description: req.Description,
+		Active:      req.Active,
+		Secret:      req.Secret,
+		ClientIP:    c.RealIP(),
+		UserAgent:   c.Request().UserAgent(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, subscription)
+}
+
+// DeleteWebhook は指定した購読と配信履歴を削除する
+func (h *WebhookHandler) DeleteWebhook(c echo.Context) error {
+	err := h.webhookUsecase.DeleteSubscription(c.Request().Context(), &usecase.DeleteWebhookInput{
+		ActorID:   c.Get("user_id").(string),
+		ID:        c.Param("id"),
+		ClientIP:  c.RealIP(),
+		UserAgent: c.Request().UserAgent(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ListDeliveries は購読の配信履歴を新しい順に返す
+// status, limit, cursorで絞り込む
+func (h *WebhookHandler) ListDeliveries(c echo.Context) error {
+	input := &usecase.ListWebhookDeliveriesInput{
+		SubscriptionID: c.Param("id"),
+		Status:         model.WebhookDeliveryStatus(c.QueryParam("status")),
+		Cursor:         c.QueryParam("cursor"),
+	}
+	if value := c.QueryParam("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 0 {
+			return core.ErrBadRequest.WithMessage("Invalid limit")
+		}
+		input.Limit = limit
+	}
+
+	page, err := h.webhookUsecase.ListDeliveries(c.Request().Context(), input)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, page)
+}
+
+// ReplayDelivery は配信と同じ内容を新しい配信として送り直す
+func (h *WebhookHandler) ReplayDelivery(c echo.Context) error {
+	delivery, err := h.webhookUsecase.ReplayDelivery(c.Request().Context(), &usecase.ReplayWebhookDeliveryInput{
+		ActorID:        c.Get("user_id").(string),
+		SubscriptionID: c.Param("id"),
+		DeliveryID:     c.Param("deliveryId"),
+		ClientIP:       c.RealIP(),
+		UserAgent:      c.Request().UserAgent(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusAccepted, delivery)
+}
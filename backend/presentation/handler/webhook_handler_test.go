@@ -0,0 +1,312 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"stackies-backend/core"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/repository"
+	"stackies-backend/usecase"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockWebhookUsecase はWebhookUsecaseのモック
+type MockWebhookUsecase struct {
+	mock.Mock
+}
+
+var _ usecase.WebhookUsecase = (*MockWebhookUsecase)(nil)
+
+func (m *MockWebhookUsecase) CreateSubscription(ctx context.Context, input *usecase.CreateWebhookInput) (*model.WebhookSubscription, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookUsecase) ListSubscriptions(ctx context.Context) ([]*model.WebhookSubscription, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookUsecase) GetSubscription(ctx context.Context, id string) (*model.WebhookSubscription, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookUsecase) UpdateSubscription(ctx context.Context, input *usecase.UpdateWebhookInput) (*model.WebhookSubscription, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookUsecase) DeleteSubscription(ctx context.Context, input *usecase.DeleteWebhookInput) error {
+	args := m.Called(ctx, input)
+	return args.Error(0)
+}
+
+func (m *MockWebhookUsecase) ListDeliveries(ctx context.Context, input *usecase.ListWebhookDeliveriesInput) (*model.WebhookDeliveryPage, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.WebhookDeliveryPage), args.Error(1)
+}
+
+func (m *MockWebhookUsecase) ReplayDelivery(ctx context.Context, input *usecase.ReplayWebhookDeliveryInput) (*model.WebhookDelivery, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookUsecase) HandleEvent(ctx context.Context, event *model.DomainEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (m *MockWebhookUsecase) DeliverDue(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockWebhookUsecase) PurgeDeliveries(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+func TestWebhookHandler_CreateWebhook(t *testing.T) {
+	tests := []struct {
+		testName       string
+		body           string
+		err            error
+		expectedStatus int
+	}{
+		{
+			testName:       "購読を作成してシークレットを返す",
+			body:           `{"url":"https://example.com/hooks","event_types":["user.registered"]}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			testName:       "不正な購読",
+			body:           `{"url":"example.com","event_types":["user.registered"]}`,
+			err:            usecase.ErrInvalidWebhook,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			webhookUC := new(MockWebhookUsecase)
+			matchInput := mock.MatchedBy(func(input *usecase.CreateWebhookInput) bool {
+				return input.ActorID == "admin_1" &&
+					input.ClientIP == "192.0.2.1" &&
+					len(input.EventTypes) == 1 && input.EventTypes[0] == model.DomainEventUserRegistered
+			})
+			if tt.err != nil {
+				webhookUC.On("CreateSubscription", mock.Anything, matchInput).Return(nil, tt.err)
+			} else {
+				webhookUC.On("CreateSubscription", mock.Anything, matchInput).Return(&model.WebhookSubscription{
+					ID:         "wh_1",
+					URL:        "https://example.com/hooks",
+					EventTypes: []model.DomainEventType{model.DomainEventUserRegistered},
+					Secret:     "0123456789abcdef",
+					Active:     true,
+				}, nil)
+			}
+			handler := NewWebhookHandler(webhookUC)
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/admin/webhooks", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.RemoteAddr = "192.0.2.1:12345"
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set("user_id", "admin_1")
+
+			err := handler.CreateWebhook(c)
+
+			if tt.err != nil {
+				if appErr := core.AsAppError(err); assert.NotNil(t, appErr) {
+					assert.Equal(t, tt.expectedStatus, appErr.Status)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedStatus, rec.Code)
+				assert.Contains(t, rec.Body.String(), `"id":"wh_1"`)
+				assert.Contains(t, rec.Body.String(), `"secret":"0123456789abcdef"`)
+				assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+			}
+
+			webhookUC.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWebhookHandler_UpdateWebhook(t *testing.T) {
+	webhookUC := new(MockWebhookUsecase)
+	webhookUC.On("UpdateSubscription", mock.Anything, mock.MatchedBy(func(input *usecase.UpdateWebhookInput) bool {
+		// 省略した項目は変更しない
+		return input.ID == "wh_1" && input.ActorID == "admin_1" &&
+			input.Active != nil && !*input.Active &&
+			input.URL == nil && input.EventTypes == nil && input.Secret == nil
+	})).Return(&model.WebhookSubscription{ID: "wh_1", Active: false}, nil)
+	handler := NewWebhookHandler(webhookUC)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPatch, "/admin/webhooks/wh_1", strings.NewReader(`{"active":false}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("wh_1")
+	c.Set("user_id", "admin_1")
+
+	err := handler.UpdateWebhook(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"active":false`)
+	assert.NotContains(t, rec.Body.String(), "secret")
+	webhookUC.AssertExpectations(t)
+}
+
+func TestWebhookHandler_ListDeliveries(t *testing.T) {
+	tests := []struct {
+		testName       string
+		query          string
+		setupMocks     func(*MockWebhookUsecase)
+		expectedStatus int
+	}{
+		{
+			testName: "クエリの条件で配信履歴を返す",
+			query:    "?status=dead&limit=10&cursor=del_9",
+			setupMocks: func(webhookUC *MockWebhookUsecase) {
+				webhookUC.On("ListDeliveries", mock.Anything, &usecase.ListWebhookDeliveriesInput{
+					SubscriptionID: "wh_1",
+					Status:         model.WebhookDeliveryDead,
+					Limit:          10,
+					Cursor:         "del_9",
+				}).Return(&model.WebhookDeliveryPage{Deliveries: []*model.WebhookDelivery{{ID: "del_1"}}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			testName:       "不正なlimit",
+			query:          "?limit=-1",
+			setupMocks:     func(webhookUC *MockWebhookUsecase) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			testName: "存在しない購読",
+			setupMocks: func(webhookUC *MockWebhookUsecase) {
+				webhookUC.On("ListDeliveries", mock.Anything, mock.Anything).Return(nil, repository.ErrWebhookNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			webhookUC := new(MockWebhookUsecase)
+			tt.setupMocks(webhookUC)
+			handler := NewWebhookHandler(webhookUC)
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/admin/webhooks/wh_1/deliveries"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues("wh_1")
+
+			err := handler.ListDeliveries(c)
+
+			if tt.expectedStatus != http.StatusOK {
+				if appErr := core.AsAppError(err); assert.NotNil(t, appErr) {
+					assert.Equal(t, tt.expectedStatus, appErr.Status)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedStatus, rec.Code)
+				assert.Contains(t, rec.Body.String(), `"id":"del_1"`)
+			}
+
+			webhookUC.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWebhookHandler_ReplayDelivery(t *testing.T) {
+	tests := []struct {
+		testName       string
+		err            error
+		expectedStatus int
+	}{
+		{
+			testName:       "配信を再送する",
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			testName:       "配信待ちの配信は再送できない",
+			err:            usecase.ErrWebhookDeliveryPending,
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			webhookUC := new(MockWebhookUsecase)
+			matchInput := mock.MatchedBy(func(input *usecase.ReplayWebhookDeliveryInput) bool {
+				return input.ActorID == "admin_1" && input.SubscriptionID == "wh_1" && input.DeliveryID == "del_1"
+			})
+			if tt.err != nil {
+				webhookUC.On("ReplayDelivery", mock.Anything, matchInput).Return(nil, tt.err)
+			} else {
+				webhookUC.On("ReplayDelivery", mock.Anything, matchInput).Return(&model.WebhookDelivery{
+					ID:       "del_2",
+					Status:   model.WebhookDeliveryPending,
+					ReplayOf: "del_1",
+				}, nil)
+			}
+			handler := NewWebhookHandler(webhookUC)
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/admin/webhooks/wh_1/deliveries/del_1/replay", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id", "deliveryId")
+			c.SetParamValues("wh_1", "del_1")
+			c.Set("user_id", "admin_1")
+
+			err := handler.ReplayDelivery(c)
+
+			if tt.err != nil {
+				if appErr := core.AsAppError(err); assert.NotNil(t, appErr) {
+					assert.Equal(t, tt.expectedStatus, appErr.Status)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedStatus, rec.Code)
+				assert.Contains(t, rec.Body.String(), `"replay_of":"del_1"`)
+			}
+
+			webhookUC.AssertExpectations(t)
+		})
+	}
+}
@@ -1,44 +1,195 @@
 package middleware
 
 import (
+	"context"
+	"errors"
+	"log/slog"
 	"net/http"
+	"stackies-backend/core"
+	"stackies-backend/core/logger"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/repository"
 	"stackies-backend/domain/service"
+	"stackies-backend/usecase"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
 )
 
+const (
+	// AccessTokenCookieName はCookieセッションモードでアクセストークンを保持するCookie名
+	AccessTokenCookieName = "access_token"
+	// RefreshTokenCookieName はCookieセッションモードでリフレッシュトークンを保持するCookie名
+	RefreshTokenCookieName = "refresh_token"
+)
+
+var (
+	// ErrMissingToken はアクセストークンが指定されていないエラー
+	ErrMissingToken = core.NewAppError("missing_token", http.StatusUnauthorized, "Authorization header required")
+	// ErrInvalidAuthorizationHeader はAuthorizationヘッダーの形式が不正なエラー
+	ErrInvalidAuthorizationHeader = core.NewAppError("invalid_authorization_header", http.StatusUnauthorized, "Invalid authorization header format")
+	// ErrInvalidToken はアクセストークンが無効または期限切れのエラー
+	ErrInvalidToken = core.NewAppError("invalid_token", http.StatusUnauthorized, "Invalid token")
+	// ErrImpersonationNotAllowed はなりすましのトークンで本人にしか許可しない操作をしたエラー
+	ErrImpersonationNotAllowed = core.NewAppError("impersonation_not_allowed", http.StatusForbidden, "This operation is not allowed while impersonating a user")
+)
+
+// ActorIDContextKey はなりすましを行っている管理者のIDを保持するecho.Contextのキー
+const ActorIDContextKey = "actor_id"
+
 // AuthMiddleware は認証ミドルウェアを表す
 type AuthMiddleware struct {
-	jwtSvc service.JWTService
+	jwtSvc    service.JWTService
+	userRepo  repository.UserRepository
+	auditRepo repository.AuditRepository
 }
 
 // NewAuthMiddleware はAuthMiddlewareの新しいインスタンスを作成する
-func NewAuthMiddleware(jwtSvc service.JWTService) *AuthMiddleware {
+func NewAuthMiddleware(jwtSvc service.JWTService, userRepo repository.UserRepository, auditRepo repository.AuditRepository) *AuthMiddleware {
 	return &AuthMiddleware{
-		jwtSvc: jwtSvc,
+		jwtSvc:    jwtSvc,
+		userRepo:  userRepo,
+		auditRepo: auditRepo,
 	}
 }
 
 // Authenticate は認証ミドルウェアを表す
+// 状態は保存済みのユーザー情報から判定するため、停止・無効化は有効期限内のアクセストークンにも即時に反映される
+// なりすましのトークンの場合は、user_idになりすまされたユーザー、actor_idに管理者をセットし、リクエストを監査ログに記録する
 func (m *AuthMiddleware) Authenticate(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
-		authHeader := c.Request().Header.Get("Authorization")
-		if authHeader == "" {
-			return echo.NewHTTPError(http.StatusUnauthorized, "Authorization header required")
+		token, err := extractToken(c)
+		if err != nil {
+			return err
 		}
 
-		token := strings.TrimPrefix(authHeader, "Bearer ")
-		if token == authHeader {
-			return echo.NewHTTPError(http.StatusUnauthorized, "Invalid authorization header format")
+		claims, err := m.jwtSvc.ParseToken(token)
+		if err != nil {
+			return ErrInvalidToken.Wrap(err)
 		}
 
-		userID, err := m.jwtSvc.ValidateToken(token)
+		ctx := c.Request().Context()
+		user, err := m.userRepo.FindByID(ctx, claims.UserID)
 		if err != nil {
-			return echo.NewHTTPError(http.StatusUnauthorized, "Invalid token")
+			if errors.Is(err, repository.ErrUserNotFound) {
+				return ErrInvalidToken.Wrap(err)
+			}
+			return err
+		}
+		if err := usecase.CheckUserStatus(user, time.Now()); err != nil {
+			return err
+		}
+		// 「心当たりがない」報告などですべてのセッションを無効化した場合は、発行済みのアクセストークンも使えない
+		if user.IsSessionRevoked(claims.IssuedAt) {
+			return ErrInvalidToken.WithMessage("Session has been revoked")
+		}
+
+		c.Set("user_id", claims.UserID)
+		ctx = logger.WithUserID(ctx, claims.UserID)
+		if !claims.IsImpersonation() {
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
 		}
 
-		c.Set("user_id", userID)
+		// 管理者の権限を外した場合や停止した場合は、発行済みのなりすましのトークンも使えない
+		if err := m.checkImpersonator(ctx, claims.ActorID); err != nil {
+			return err
+		}
+		c.Set(ActorIDContextKey, claims.ActorID)
+		c.SetRequest(c.Request().WithContext(logger.WithActorID(ctx, claims.ActorID)))
+
+		err = next(c)
+		m.recordImpersonatedRequest(c, claims, err)
+		return err
+	}
+}
+
+// DenyImpersonation はなりすましのトークンによるリクエストを拒否するミドルウェア
+// アカウントの削除やセッションの操作など、本人にしか許可しない操作に使う。Authenticateの後段に配置する
+func (m *AuthMiddleware) DenyImpersonation(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if ActorID(c) != "" {
+			return ErrImpersonationNotAllowed
+		}
 		return next(c)
 	}
 }
+
+// ActorID はなりすましを行っている管理者のIDを返す（なりすましでない場合は空）
+func ActorID(c echo.Context) string {
+	actorID, _ := c.Get(ActorIDContextKey).(string)
+	return actorID
+}
+
+// checkImpersonator はなりすましを行っている管理者が有効な管理者のままかどうかを確認する
+func (m *AuthMiddleware) checkImpersonator(ctx context.Context, actorID string) error {
+	actor, err := m.userRepo.FindByID(ctx, actorID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return ErrInvalidToken.Wrap(err)
+		}
+		return err
+	}
+	if !actor.HasRole(model.RoleAdmin) || actor.IsDeleted() || !actor.IsActive(time.Now()) {
+		return ErrInvalidToken.WithMessage("Impersonation is no longer allowed")
+	}
+	return nil
+}
+
+// recordImpersonatedRequest はなりすましのトークンによるリクエストを監査ログに記録する
+func (m *AuthMiddleware) recordImpersonatedRequest(c echo.Context, claims *model.TokenClaims, err error) {
+	status := c.Response().Status
+	if err != nil {
+		status = http.StatusInternalServerError
+		if appErr := core.AsAppError(err); appErr != nil {
+			status = appErr.Status
+		} else if httpErr, ok := err.(*echo.HTTPError); ok {
+			status = httpErr.Code
+		}
+	}
+
+	outcome := model.AuditOutcomeSuccess
+	if status >= http.StatusBadRequest {
+		outcome = model.AuditOutcomeFailure
+	}
+	event := &model.AuditEvent{
+		Type:      model.AuditEventImpersonatedRequest,
+		Outcome:   outcome,
+		ActorID:   claims.ActorID,
+		SubjectID: claims.UserID,
+		IPAddress: c.RealIP(),
+		UserAgent: c.Request().UserAgent(),
+		Metadata: map[string]string{
+			"method": c.Request().Method,
+			"route":  c.Path(),
+			"path":   c.Request().URL.Path,
+			"status": strconv.Itoa(status),
+		},
+		CreatedAt: time.Now(),
+	}
+
+	ctx := c.Request().Context()
+	if err := m.auditRepo.Append(ctx, event); err != nil {
+		slog.ErrorContext(ctx, "failed to record audit event", slog.String("event_type", string(event.Type)), slog.Any("error", err))
+	}
+}
+
+// extractToken はAuthorizationヘッダー、なければCookieからアクセストークンを取り出す
+func extractToken(c echo.Context) (string, error) {
+	authHeader := c.Request().Header.Get("Authorization")
+	if authHeader == "" {
+		if cookie, err := c.Cookie(AccessTokenCookieName); err == nil && cookie.Value != "" {
+			return cookie.Value, nil
+		}
+		return "", ErrMissingToken
+	}
+
+	token := strings.TrimPrefix(authHeader, "Bearer ")
+	if token == authHeader {
+		return "", ErrInvalidAuthorizationHeader
+	}
+
+	return token, nil
+}
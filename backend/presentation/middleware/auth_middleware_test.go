@@ -1,11 +1,18 @@
 package middleware
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"stackies-backend/core"
+	"stackies-backend/core/logger"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/repository"
 	"stackies-backend/domain/service"
+	"stackies-backend/infra/persistence"
 	"testing"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
@@ -34,11 +41,26 @@ func (m *MockJWTService) GenerateRefreshToken(userID string) (string, error) {
 	return args.String(0), args.Error(1)
 }
 
+func (m *MockJWTService) GenerateImpersonationToken(userID, actorID string, ttl time.Duration) (string, error) {
+	args := m.Called(userID, actorID, ttl)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockJWTService) ParseToken(token string) (*model.TokenClaims, error) {
+	args := m.Called(token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.TokenClaims), args.Error(1)
+}
+
 func TestAuthMiddleware_Authenticate(t *testing.T) {
 	tests := []struct {
 		testName       string
 		authHeader     string
-		setupMocks     func(*MockJWTService)
+		cookie         string
+		setupMocks     func(*MockJWTService, *MockUserRepository)
+		expectedCode   string
 		expectedStatus int
 		expectNext     bool
 		expectedUserID string
@@ -46,8 +68,9 @@ func TestAuthMiddleware_Authenticate(t *testing.T) {
 		{
 			testName:   "正常な認証",
 			authHeader: "Bearer valid_token",
-			setupMocks: func(jwtSvc *MockJWTService) {
-				jwtSvc.On("ValidateToken", "valid_token").Return("user_123", nil)
+			setupMocks: func(jwtSvc *MockJWTService, userRepo *MockUserRepository) {
+				jwtSvc.On("ParseToken", "valid_token").Return(&model.TokenClaims{UserID: "user_123"}, nil)
+				userRepo.On("FindByID", mock.Anything, "user_123").Return(&model.User{ID: "user_123", Status: model.UserStatusActive}, nil)
 			},
 			expectedStatus: http.StatusOK,
 			expectNext:     true,
@@ -56,17 +79,28 @@ func TestAuthMiddleware_Authenticate(t *testing.T) {
 		{
 			testName:   "Authorizationヘッダーなし",
 			authHeader: "",
-			setupMocks: func(jwtSvc *MockJWTService) {
+			setupMocks: func(jwtSvc *MockJWTService, userRepo *MockUserRepository) {
 				// モックの設定なし
 			},
 			expectedStatus: http.StatusUnauthorized,
 			expectNext:     false,
 			expectedUserID: "",
 		},
+		{
+			testName: "Cookieによる認証",
+			cookie:   "cookie_token",
+			setupMocks: func(jwtSvc *MockJWTService, userRepo *MockUserRepository) {
+				jwtSvc.On("ParseToken", "cookie_token").Return(&model.TokenClaims{UserID: "user_123"}, nil)
+				userRepo.On("FindByID", mock.Anything, "user_123").Return(&model.User{ID: "user_123", Status: model.UserStatusActive}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectNext:     true,
+			expectedUserID: "user_123",
+		},
 		{
 			testName:   "無効なAuthorizationヘッダー形式",
 			authHeader: "invalid_header",
-			setupMocks: func(jwtSvc *MockJWTService) {
+			setupMocks: func(jwtSvc *MockJWTService, userRepo *MockUserRepository) {
 				// モックの設定なし
 			},
 			expectedStatus: http.StatusUnauthorized,
@@ -76,29 +110,97 @@ func TestAuthMiddleware_Authenticate(t *testing.T) {
 		{
 			testName:   "無効なトークン",
 			authHeader: "Bearer invalid_token",
-			setupMocks: func(jwtSvc *MockJWTService) {
-				jwtSvc.On("ValidateToken", "invalid_token").Return("", errors.New("invalid token"))
+			setupMocks: func(jwtSvc *MockJWTService, userRepo *MockUserRepository) {
+				jwtSvc.On("ParseToken", "invalid_token").Return(nil, errors.New("invalid token"))
 			},
 			expectedStatus: http.StatusUnauthorized,
 			expectNext:     false,
 			expectedUserID: "",
 		},
+		{
+			testName:   "期限を過ぎた一時停止は解除されたものとして扱う",
+			authHeader: "Bearer valid_token",
+			setupMocks: func(jwtSvc *MockJWTService, userRepo *MockUserRepository) {
+				until := time.Now().Add(-time.Minute)
+				jwtSvc.On("ParseToken", "valid_token").Return(&model.TokenClaims{UserID: "user_123"}, nil)
+				userRepo.On("FindByID", mock.Anything, "user_123").Return(&model.User{ID: "user_123", Status: model.UserStatusSuspended, SuspendedUntil: &until}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectNext:     true,
+			expectedUserID: "user_123",
+		},
+		{
+			testName:   "セッションの無効化より前に発行したトークン",
+			authHeader: "Bearer valid_token",
+			setupMocks: func(jwtSvc *MockJWTService, userRepo *MockUserRepository) {
+				revokedAt := time.Now().Truncate(time.Second)
+				jwtSvc.On("ParseToken", "valid_token").Return(&model.TokenClaims{UserID: "user_123", IssuedAt: revokedAt.Add(-time.Hour)}, nil)
+				userRepo.On("FindByID", mock.Anything, "user_123").Return(&model.User{ID: "user_123", Status: model.UserStatusActive, SessionsRevokedAt: &revokedAt}, nil)
+			},
+			expectedCode:   "invalid_token",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			testName:   "セッションの無効化の後に発行したトークン",
+			authHeader: "Bearer valid_token",
+			setupMocks: func(jwtSvc *MockJWTService, userRepo *MockUserRepository) {
+				revokedAt := time.Now().Add(-time.Hour).Truncate(time.Second)
+				jwtSvc.On("ParseToken", "valid_token").Return(&model.TokenClaims{UserID: "user_123", IssuedAt: revokedAt.Add(time.Second)}, nil)
+				userRepo.On("FindByID", mock.Anything, "user_123").Return(&model.User{ID: "user_123", Status: model.UserStatusActive, SessionsRevokedAt: &revokedAt}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectNext:     true,
+			expectedUserID: "user_123",
+		},
+		{
+			testName:   "一時停止されたユーザー",
+			authHeader: "Bearer valid_token",
+			setupMocks: func(jwtSvc *MockJWTService, userRepo *MockUserRepository) {
+				jwtSvc.On("ParseToken", "valid_token").Return(&model.TokenClaims{UserID: "user_123"}, nil)
+				userRepo.On("FindByID", mock.Anything, "user_123").Return(&model.User{ID: "user_123", Status: model.UserStatusSuspended}, nil)
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedCode:   "user_suspended",
+		},
+		{
+			testName:   "無効化されたユーザー",
+			authHeader: "Bearer valid_token",
+			setupMocks: func(jwtSvc *MockJWTService, userRepo *MockUserRepository) {
+				jwtSvc.On("ParseToken", "valid_token").Return(&model.TokenClaims{UserID: "user_123"}, nil)
+				userRepo.On("FindByID", mock.Anything, "user_123").Return(&model.User{ID: "user_123", Status: model.UserStatusDisabled}, nil)
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedCode:   "user_disabled",
+		},
+		{
+			testName:   "消去されたユーザーのトークンは無効",
+			authHeader: "Bearer valid_token",
+			setupMocks: func(jwtSvc *MockJWTService, userRepo *MockUserRepository) {
+				jwtSvc.On("ParseToken", "valid_token").Return(&model.TokenClaims{UserID: "user_123"}, nil)
+				userRepo.On("FindByID", mock.Anything, "user_123").Return(nil, repository.ErrUserNotFound)
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "invalid_token",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.testName, func(t *testing.T) {
 			jwtSvc := new(MockJWTService)
-			tt.setupMocks(jwtSvc)
+			userRepo := new(MockUserRepository)
+			tt.setupMocks(jwtSvc, userRepo)
 
-			middleware := NewAuthMiddleware(jwtSvc)
+			middleware := NewAuthMiddleware(jwtSvc, userRepo, nil)
 
 			// next関数が呼ばれたかチェックするフラグ
 			nextCalled := false
 			var capturedUserID interface{}
+			var contextUserID string
 
 			next := func(c echo.Context) error {
 				nextCalled = true
 				capturedUserID = c.Get("user_id")
+				contextUserID = logger.UserIDFromContext(c.Request().Context())
 				return c.JSON(http.StatusOK, map[string]string{"message": "success"})
 			}
 
@@ -107,6 +209,9 @@ func TestAuthMiddleware_Authenticate(t *testing.T) {
 			if tt.authHeader != "" {
 				req.Header.Set("Authorization", tt.authHeader)
 			}
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: AccessTokenCookieName, Value: tt.cookie})
+			}
 			rec := httptest.NewRecorder()
 			c := e.NewContext(req, rec)
 
@@ -117,18 +222,126 @@ func TestAuthMiddleware_Authenticate(t *testing.T) {
 				assert.NoError(t, err)
 				assert.True(t, nextCalled)
 				assert.Equal(t, tt.expectedUserID, capturedUserID)
+				assert.Equal(t, tt.expectedUserID, contextUserID)
 				assert.Equal(t, tt.expectedStatus, rec.Code)
 			} else {
 				assert.Error(t, err)
 				assert.False(t, nextCalled)
 
-				// Echo HTTPErrorの場合はステータスコードをチェック
-				if httpErr, ok := err.(*echo.HTTPError); ok {
-					assert.Equal(t, tt.expectedStatus, httpErr.Code)
+				if appErr := core.AsAppError(err); assert.NotNil(t, appErr) {
+					assert.Equal(t, tt.expectedStatus, appErr.Status)
+					if tt.expectedCode != "" {
+						assert.Equal(t, tt.expectedCode, appErr.Code)
+					}
 				}
 			}
 
 			jwtSvc.AssertExpectations(t)
+			userRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuthMiddleware_Impersonation(t *testing.T) {
+	user := &model.User{ID: "user_123", Role: model.RoleUser, Status: model.UserStatusActive}
+	admin := &model.User{ID: "admin_1", Role: model.RoleAdmin, Status: model.UserStatusActive}
+
+	tests := []struct {
+		testName        string
+		actor           *model.User
+		handlerErr      error
+		deny            bool
+		expectNext      bool
+		expectedCode    string
+		expectedOutcome model.AuditOutcome
+		expectedStatus  string
+	}{
+		{
+			testName:        "両方のIDをハンドラーに渡し、リクエストを記録する",
+			actor:           admin,
+			expectNext:      true,
+			expectedOutcome: model.AuditOutcomeSuccess,
+			expectedStatus:  "200",
+		},
+		{
+			testName:        "失敗したリクエストも記録する",
+			actor:           admin,
+			handlerErr:      core.ErrForbidden,
+			expectNext:      true,
+			expectedOutcome: model.AuditOutcomeFailure,
+			expectedStatus:  "403",
+		},
+		{
+			testName:     "管理者でなくなった場合はトークンを無効とする",
+			actor:        &model.User{ID: "admin_1", Role: model.RoleUser, Status: model.UserStatusActive},
+			expectedCode: "invalid_token",
+		},
+		{
+			testName:     "本人にしか許可しない操作は拒否する",
+			actor:        admin,
+			deny:         true,
+			expectedCode: "impersonation_not_allowed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			jwtSvc := new(MockJWTService)
+			jwtSvc.On("ParseToken", "impersonation_token").Return(&model.TokenClaims{UserID: "user_123", ActorID: "admin_1"}, nil)
+			userRepo := new(MockUserRepository)
+			userRepo.On("FindByID", mock.Anything, "user_123").Return(user, nil)
+			userRepo.On("FindByID", mock.Anything, "admin_1").Return(tt.actor, nil)
+			auditRepo := persistence.NewAuditRepository()
+			authMW := NewAuthMiddleware(jwtSvc, userRepo, auditRepo)
+
+			nextCalled := false
+			var userID, actorID, logActorID string
+			next := func(c echo.Context) error {
+				nextCalled = true
+				userID = c.Get("user_id").(string)
+				actorID = ActorID(c)
+				logActorID = logger.ActorIDFromContext(c.Request().Context())
+				if tt.handlerErr != nil {
+					return tt.handlerErr
+				}
+				return c.JSON(http.StatusOK, map[string]string{"message": "success"})
+			}
+			handler := authMW.Authenticate(next)
+			if tt.deny {
+				handler = authMW.Authenticate(authMW.DenyImpersonation(next))
+			}
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/users/me/audit-events", nil)
+			req.Header.Set("Authorization", "Bearer impersonation_token")
+			c := e.NewContext(req, httptest.NewRecorder())
+			c.SetPath("/users/me/audit-events")
+
+			err := handler(c)
+
+			assert.Equal(t, tt.expectNext, nextCalled)
+			if !tt.expectNext {
+				if appErr := core.AsAppError(err); assert.NotNil(t, appErr) {
+					assert.Equal(t, tt.expectedCode, appErr.Code)
+				}
+			}
+			if tt.expectedStatus == "" {
+				return
+			}
+
+			assert.Equal(t, "user_123", userID)
+			assert.Equal(t, "admin_1", actorID)
+			assert.Equal(t, "admin_1", logActorID)
+			page, err := auditRepo.Query(context.Background(), &model.AuditEventFilter{Type: model.AuditEventImpersonatedRequest})
+			assert.NoError(t, err)
+			if assert.Len(t, page.Events, 1) {
+				event := page.Events[0]
+				assert.Equal(t, "admin_1", event.ActorID)
+				assert.Equal(t, "user_123", event.SubjectID)
+				assert.Equal(t, tt.expectedOutcome, event.Outcome)
+				assert.Equal(t, tt.expectedStatus, event.Metadata["status"])
+				assert.Equal(t, "/users/me/audit-events", event.Metadata["route"])
+			}
 		})
 	}
 }
@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"stackies-backend/core"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// MIMEApplicationProblemJSON はRFC 7807のエラーレスポンスのContent-Type
+const MIMEApplicationProblemJSON = "application/problem+json"
+
+// Problem はRFC 7807のProblem Detailsを表す
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	// Code はクライアントがエラーを判別するための拡張メンバー
+	Code string `json:"code"`
+}
+
+// NewHTTPErrorHandler はエラーをapplication/problem+jsonで返すEchoのHTTPErrorHandlerを作成する
+// 5xxの場合は原因をログに残し、レスポンスには汎用的な文言のみを含める
+func NewHTTPErrorHandler() echo.HTTPErrorHandler {
+	return func(err error, c echo.Context) {
+		if c.Response().Committed {
+			return
+		}
+
+		appErr := toAppError(err)
+		if appErr.Status >= http.StatusInternalServerError {
+			slog.ErrorContext(c.Request().Context(), "request failed",
+				slog.String("method", c.Request().Method),
+				slog.String("path", c.Request().URL.Path),
+				slog.Any("error", err),
+			)
+		}
+
+		problem := &Problem{
+			Type:     "about:blank",
+			Title:    http.StatusText(appErr.Status),
+			Status:   appErr.Status,
+			Detail:   appErr.Message,
+			Instance: c.Request().URL.Path,
+			Code:     appErr.Code,
+		}
+
+		if c.Request().Method == http.MethodHead {
+			err = c.NoContent(appErr.Status)
+		} else {
+			err = writeProblem(c, problem)
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request().Context(), "failed to write error response", slog.Any("error", err))
+		}
+	}
+}
+
+// toAppError はハンドラーやミドルウェアが返したエラーをAppErrorに変換する
+// EchoのHTTPError（ルーティングの404/405など）はステータスに応じたコードを割り当てる
+func toAppError(err error) *core.AppError {
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		var appErr *core.AppError
+		if errors.As(httpErr.Internal, &appErr) {
+			return appErr
+		}
+
+		message := http.StatusText(httpErr.Code)
+		if m, ok := httpErr.Message.(string); ok && httpErr.Code < http.StatusInternalServerError {
+			message = m
+		}
+		return core.NewAppError(codeForStatus(httpErr.Code), httpErr.Code, message).Wrap(err)
+	}
+
+	return core.AsAppError(err)
+}
+
+// codeForStatus はHTTPステータスから汎用のエラーコードを作成する（例: 404 -> not_found）
+func codeForStatus(status int) string {
+	text := http.StatusText(status)
+	if text == "" {
+		return "error"
+	}
+	return strings.ToLower(strings.NewReplacer(" ", "_", "-", "_", "'", "").Replace(text))
+}
+
+// writeProblem はProblem Detailsをレスポンスに書き込む
+func writeProblem(c echo.Context, problem *Problem) error {
+	body, err := json.Marshal(problem)
+	if err != nil {
+		return err
+	}
+	return c.Blob(problem.Status, MIMEApplicationProblemJSON, body)
+}
@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"stackies-backend/core"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewHTTPErrorHandler(t *testing.T) {
+	tests := []struct {
+		testName       string
+		err            error
+		expectedStatus int
+		expectedCode   string
+		expectedDetail string
+	}{
+		{
+			testName:       "AppError",
+			err:            core.NewAppError("user_not_found", http.StatusNotFound, "User not found"),
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   "user_not_found",
+			expectedDetail: "User not found",
+		},
+		{
+			testName:       "ラップされたAppError",
+			err:            core.ErrBadRequest.Wrap(errors.New("bind failed")),
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   "bad_request",
+			expectedDetail: "Invalid request",
+		},
+		{
+			testName:       "EchoのHTTPError",
+			err:            echo.ErrNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedCode:   "not_found",
+			expectedDetail: "Not Found",
+		},
+		{
+			testName:       "AppErrorを内包したHTTPError",
+			err:            echo.NewHTTPError(http.StatusBadRequest).SetInternal(core.ErrForbidden),
+			expectedStatus: http.StatusForbidden,
+			expectedCode:   "forbidden",
+			expectedDetail: "Insufficient permissions",
+		},
+		{
+			testName:       "未知のエラーは詳細を含めない",
+			err:            errors.New("dial tcp 10.0.0.1:5432: connection refused"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   "internal_error",
+			expectedDetail: "Internal server error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/auth/me", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			NewHTTPErrorHandler()(tt.err, c)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Equal(t, MIMEApplicationProblemJSON, rec.Header().Get(echo.HeaderContentType))
+
+			var problem Problem
+			if assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem)) {
+				assert.Equal(t, "about:blank", problem.Type)
+				assert.Equal(t, http.StatusText(tt.expectedStatus), problem.Title)
+				assert.Equal(t, tt.expectedStatus, problem.Status)
+				assert.Equal(t, tt.expectedCode, problem.Code)
+				assert.Equal(t, tt.expectedDetail, problem.Detail)
+				assert.Equal(t, "/auth/me", problem.Instance)
+			}
+			assert.NotContains(t, rec.Body.String(), "connection refused")
+		})
+	}
+}
+
+func TestNewHTTPErrorHandler_Head(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodHead, "/auth/me", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	NewHTTPErrorHandler()(core.ErrUnauthorized, c)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Empty(t, rec.Body.String())
+}
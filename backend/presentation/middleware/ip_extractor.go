@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"net"
+
+	"github.com/labstack/echo/v4"
+)
+
+// NewIPExtractor はクライアントのIPアドレス（c.RealIP()）の取得方法を返す
+// trustedProxiesが空の場合は接続元のアドレスのみを使い、クライアントが送るX-Forwarded-For・X-Real-IPは信頼しない
+// 指定した場合は、信頼するプロキシを経由したX-Forwarded-Forを右から辿り、最初の信頼しないアドレスを使う
+func NewIPExtractor(trustedProxies []string) echo.IPExtractor {
+	if len(trustedProxies) == 0 {
+		return echo.ExtractIPDirect()
+	}
+
+	// Echoの既定ではループバック・リンクローカル・プライベートアドレスを信頼するため、設定したCIDRのみに絞る
+	options := []echo.TrustOption{
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	}
+	for _, proxy := range trustedProxies {
+		// 設定の読み込み時に検証済み
+		if _, ipNet, err := net.ParseCIDR(proxy); err == nil {
+			options = append(options, echo.TrustIPRange(ipNet))
+		}
+	}
+	return echo.ExtractIPFromXFFHeader(options...)
+}
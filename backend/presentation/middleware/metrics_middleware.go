@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"stackies-backend/core"
+	"stackies-backend/core/metrics"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// unmatchedRoute はどのルートにも一致しなかったリクエストのrouteラベル
+// 実際のパスをラベルにするとスキャンなどで時系列が際限なく増えるためまとめる
+const unmatchedRoute = "unmatched"
+
+// Metrics はルートとステータスごとにリクエストの処理時間を記録するミドルウェアを返す
+func Metrics(m *metrics.Metrics) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+
+			err := next(c)
+			if err != nil {
+				// ステータスコードを確定させるため、ここでエラーハンドラーを呼ぶ
+				c.Error(err)
+			}
+
+			route := c.Path()
+			if route == "" || route == "/*" {
+				route = unmatchedRoute
+			}
+			m.ObserveHTTPRequest(c.Request().Method, route, c.Response().Status, time.Since(start))
+			return nil
+		}
+	}
+}
+
+// RequireMetricsToken は/metricsの取得にBearerトークンを要求するミドルウェアを返す
+// tokenが空の場合は制限しない（ネットワークやIngressで公開範囲を制限する前提）
+func RequireMetricsToken(token string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if token == "" {
+			return next
+		}
+		expected := []byte("Bearer " + token)
+		return func(c echo.Context) error {
+			actual := []byte(c.Request().Header.Get(echo.HeaderAuthorization))
+			if subtle.ConstantTimeCompare(actual, expected) != 1 {
+				return core.ErrUnauthorized
+			}
+			return next(c)
+		}
+	}
+}
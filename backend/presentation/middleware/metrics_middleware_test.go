@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"stackies-backend/core/metrics"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	m := metrics.New()
+	e := echo.New()
+	e.HTTPErrorHandler = NewHTTPErrorHandler()
+	e.Use(Metrics(m))
+	e.GET("/users/:id", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+	e.GET("/forbidden", func(c echo.Context) error {
+		return echo.ErrForbidden
+	})
+
+	for _, path := range []string{"/users/1", "/users/2", "/forbidden", "/unknown/path"} {
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+	// 実際のパスではなくルートのパターンごとに集計する
+	assert.Contains(t, body, `stackies_http_request_duration_seconds_count{method="GET",route="/users/:id",status="200"} 2`)
+	assert.Contains(t, body, `stackies_http_request_duration_seconds_count{method="GET",route="/forbidden",status="403"} 1`)
+	assert.Contains(t, body, `stackies_http_request_duration_seconds_count{method="GET",route="unmatched",status="404"} 1`)
+	assert.NotContains(t, body, "/unknown/path")
+}
+
+func TestRequireMetricsToken(t *testing.T) {
+	tests := []struct {
+		testName       string
+		token          string
+		authorization  string
+		expectedStatus int
+	}{
+		{testName: "トークン未設定は制限しない", token: "", expectedStatus: http.StatusOK},
+		{testName: "正しいトークン", token: "scrape-secret", authorization: "Bearer scrape-secret", expectedStatus: http.StatusOK},
+		{testName: "トークンなし", token: "scrape-secret", expectedStatus: http.StatusUnauthorized},
+		{testName: "誤ったトークン", token: "scrape-secret", authorization: "Bearer wrong", expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			e := echo.New()
+			e.HTTPErrorHandler = NewHTTPErrorHandler()
+			e.GET("/metrics", func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			}, RequireMetricsToken(tt.token))
+
+			req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			if tt.authorization != "" {
+				req.Header.Set(echo.HeaderAuthorization, tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+		})
+	}
+}
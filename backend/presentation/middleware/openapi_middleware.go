@@ -0,0 +1,174 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"stackies-backend/core"
+	"stackies-backend/presentation/openapi"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/labstack/echo/v4"
+)
+
+// レスポンスの検証方法
+const (
+	// ResponseValidationOff はレスポンスを検証しない
+	ResponseValidationOff = "off"
+	// ResponseValidationLog はドキュメントと一致しないレスポンスをログに出力し、そのまま返す
+	ResponseValidationLog = "log"
+	// ResponseValidationStrict はドキュメントと一致しないレスポンスを500に置き換える（テスト向け）
+	ResponseValidationStrict = "strict"
+)
+
+// ErrValidationFailed はリクエストがOpenAPIドキュメントと一致しない場合のエラー
+var ErrValidationFailed = core.NewAppError("validation_failed", http.StatusBadRequest, "Request does not match the API specification")
+
+// OpenAPIValidator はリクエストとレスポンスをOpenAPIドキュメントで検証するミドルウェアを返す
+// ドキュメントにないパス・メソッドは検証せず、ルーティングに任せる
+// 認証はAuthenticateミドルウェアで行うため、ここではセキュリティ要件を検証しない
+func OpenAPIValidator(spec *openapi.Spec, responseMode string) echo.MiddlewareFunc {
+	options := &openapi3filter.Options{AuthenticationFunc: openapi3filter.NoopAuthenticationFunc}
+	// スキーマ全体をメッセージに含めないよう、項目と理由のみを返す
+	options.WithCustomSchemaErrorFunc(func(err *openapi3.SchemaError) string {
+		if path := err.JSONPointer(); len(path) > 0 {
+			return "/" + strings.Join(path, "/") + ": " + err.Reason
+		}
+		return err.Reason
+	})
+	responseOptions := *options
+	responseOptions.IncludeResponseStatus = true
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			route, pathParams, err := spec.FindRoute(req)
+			if err != nil {
+				return next(c)
+			}
+
+			input := &openapi3filter.RequestValidationInput{
+				Request:    req,
+				PathParams: pathParams,
+				Route:      route,
+				Options:    options,
+			}
+			if err := openapi3filter.ValidateRequest(req.Context(), input); err != nil {
+				// BodyLimitの上限を超えたボディは検証の失敗ではなく413として返す
+				if errors.Is(err, echo.ErrStatusRequestEntityTooLarge) {
+					return echo.ErrStatusRequestEntityTooLarge
+				}
+				return ErrValidationFailed.WithMessage(validationMessage(err)).Wrap(err)
+			}
+
+			if responseMode == ResponseValidationOff || responseMode == "" || req.Method == http.MethodHead {
+				return next(c)
+			}
+
+			// 検証が終わるまでレスポンスを送信しないよう、バッファに書き込ませる
+			response := c.Response()
+			original := response.Writer
+			buffer := &bufferedResponseWriter{ResponseWriter: original}
+			response.Writer = buffer
+			if err := next(c); err != nil {
+				// ステータスコードとエラーレスポンスを確定させるため、ここでエラーハンドラーを呼ぶ
+				c.Error(err)
+			}
+			response.Writer = original
+
+			status := buffer.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			responseInput := &openapi3filter.ResponseValidationInput{
+				RequestValidationInput: input,
+				Status:                 status,
+				Header:                 original.Header(),
+				Options:                &responseOptions,
+			}
+			responseInput.SetBodyBytes(buffer.body.Bytes())
+			if err := openapi3filter.ValidateResponse(req.Context(), responseInput); err != nil {
+				slog.ErrorContext(req.Context(), "response does not match the openapi document",
+					slog.String("method", req.Method),
+					slog.String("route", route.Path),
+					slog.Int("status", status),
+					slog.String("error", validationMessage(err)),
+				)
+				if responseMode == ResponseValidationStrict {
+					return writeInvalidResponse(c, original)
+				}
+			}
+
+			original.WriteHeader(status)
+			_, err = original.Write(buffer.body.Bytes())
+			return err
+		}
+	}
+}
+
+// validationMessage はクライアントに返す検証エラーの文言を返す
+func validationMessage(err error) string {
+	var requestErr *openapi3filter.RequestError
+	if errors.As(err, &requestErr) {
+		return requestErr.Error()
+	}
+	var responseErr *openapi3filter.ResponseError
+	if errors.As(err, &responseErr) {
+		return responseErr.Error()
+	}
+	return err.Error()
+}
+
+// writeInvalidResponse はドキュメントと一致しないレスポンスの代わりに500を返す
+func writeInvalidResponse(c echo.Context, w http.ResponseWriter) error {
+	header := w.Header()
+	for key := range header {
+		if key != echo.HeaderXRequestID {
+			header.Del(key)
+		}
+	}
+	c.Response().Status = http.StatusInternalServerError
+
+	body, err := json.Marshal(&Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(core.ErrInternal.Status),
+		Status:   core.ErrInternal.Status,
+		Detail:   core.ErrInternal.Message,
+		Instance: c.Request().URL.Path,
+		Code:     core.ErrInternal.Code,
+	})
+	if err != nil {
+		return err
+	}
+	header.Set(echo.HeaderContentType, MIMEApplicationProblemJSON)
+	w.WriteHeader(http.StatusInternalServerError)
+	_, err = w.Write(body)
+	return err
+}
+
+// bufferedResponseWriter はステータスコードと本文を送信せずに保持するhttp.ResponseWriter
+// ヘッダーは元のhttp.ResponseWriterのものをそのまま使用する
+type bufferedResponseWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+// WriteHeader はステータスコードを保持する
+func (w *bufferedResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+// Write は本文を保持する
+func (w *bufferedResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(b)
+}
@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"stackies-backend/presentation/openapi"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newOpenAPITestEcho はOpenAPIの検証を行うテスト用のEchoを作成する
+// /auth/google/exchangeはresponseBodyをそのまま返す
+func newOpenAPITestEcho(t *testing.T, responseMode, responseBody string) *echo.Echo {
+	t.Helper()
+	spec, err := openapi.Load()
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.HTTPErrorHandler = NewHTTPErrorHandler()
+	e.Use(OpenAPIValidator(spec, responseMode))
+	e.POST("/auth/google/exchange", func(c echo.Context) error {
+		return c.JSONBlob(http.StatusOK, []byte(responseBody))
+	})
+	e.GET("/internal", func(c echo.Context) error {
+		return c.String(http.StatusOK, "ok")
+	})
+	return e
+}
+
+const validLoginResponse = `{"user":{"id":"user_1","email":"test@example.com","name":"Test","picture":"","role":"user","provider":"google",` +
+	`"created_at":"2025-01-01T00:00:00Z","updated_at":"2025-01-01T00:00:00Z","status":"active"},"accessToken":"a","refreshToken":"r","expiresIn":1}`
+
+func TestOpenAPIValidator_Request(t *testing.T) {
+	tests := []struct {
+		testName       string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{testName: "正常", body: `{"code":"login_code"}`, expectedStatus: http.StatusOK},
+		{testName: "必須項目がない", body: `{}`, expectedStatus: http.StatusBadRequest, expectedBody: `"code":"validation_failed"`},
+		{testName: "空のコード", body: `{"code":""}`, expectedStatus: http.StatusBadRequest, expectedBody: `"code":"validation_failed"`},
+		{testName: "型が異なる", body: `{"code":1}`, expectedStatus: http.StatusBadRequest, expectedBody: `"code":"validation_failed"`},
+		{testName: "ボディがない", body: ``, expectedStatus: http.StatusBadRequest, expectedBody: `"code":"validation_failed"`},
+	}
+
+	e := newOpenAPITestEcho(t, ResponseValidationStrict, validLoginResponse)
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/auth/google/exchange", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+		})
+	}
+
+	t.Run("ドキュメントにないルートは検証しない", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/internal", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "ok", rec.Body.String())
+	})
+}
+
+func TestOpenAPIValidator_Response(t *testing.T) {
+	// ドキュメントではaccessTokenなどはキャメルケース
+	invalidResponse := strings.Replace(validLoginResponse, "accessToken", "access_token", 1)
+
+	tests := []struct {
+		testName       string
+		responseMode   string
+		responseBody   string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{testName: "一致するレスポンス", responseMode: ResponseValidationStrict, responseBody: validLoginResponse, expectedStatus: http.StatusOK, expectedBody: `"accessToken":"a"`},
+		{testName: "strictでは一致しないレスポンスを500にする", responseMode: ResponseValidationStrict, responseBody: invalidResponse, expectedStatus: http.StatusInternalServerError, expectedBody: `"code":"internal_error"`},
+		{testName: "logでは一致しないレスポンスをそのまま返す", responseMode: ResponseValidationLog, responseBody: invalidResponse, expectedStatus: http.StatusOK, expectedBody: `"access_token":"a"`},
+		{testName: "offでは検証しない", responseMode: ResponseValidationOff, responseBody: invalidResponse, expectedStatus: http.StatusOK, expectedBody: `"access_token":"a"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			e := newOpenAPITestEcho(t, tt.responseMode, tt.responseBody)
+			req := httptest.NewRequest(http.MethodPost, "/auth/google/exchange", strings.NewReader(`{"code":"login_code"}`))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"log/slog"
+	"math"
+	"stackies-backend/core"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/service"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ClientIDHeader はクライアント識別子を送るリクエストヘッダー
+const ClientIDHeader = "X-Client-ID"
+
+// RateLimits はエンドポイントの種類ごとのレート制限ミドルウェアを表す
+type RateLimits struct {
+	// AuthURL は認証URL生成（IPアドレス単位）
+	AuthURL echo.MiddlewareFunc
+	// Login はログイン系（IPアドレス単位とクライアント単位）
+	Login echo.MiddlewareFunc
+	// Refresh はトークンリフレッシュ（IPアドレス単位とクライアント単位）
+	Refresh echo.MiddlewareFunc
+	// User は認証済みユーザーのAPI（ユーザー単位）
+	User echo.MiddlewareFunc
+}
+
+// RateLimitKey はレート制限のカウンタを分ける単位を表す
+type RateLimitKey string
+
+const (
+	// RateLimitKeyIP は接続元IPアドレス単位で制限する
+	RateLimitKeyIP RateLimitKey = "ip"
+	// RateLimitKeyUser は認証済みユーザー単位で制限する（Authenticateの後段でのみ有効）
+	RateLimitKeyUser RateLimitKey = "user"
+	// RateLimitKeyClient はX-Client-IDヘッダーのクライアント単位で制限する
+	RateLimitKeyClient RateLimitKey = "client"
+)
+
+// RateLimitPolicy はルートに適用するレート制限ルールとキーの組み合わせを表す
+type RateLimitPolicy struct {
+	Rule  model.RateLimitRule
+	KeyBy []RateLimitKey
+}
+
+// RateLimitMiddleware はレート制限ミドルウェアを表す
+type RateLimitMiddleware struct {
+	limiter service.RateLimiter
+}
+
+// NewRateLimitMiddleware はRateLimitMiddlewareの新しいインスタンスを作成する
+func NewRateLimitMiddleware(limiter service.RateLimiter) *RateLimitMiddleware {
+	return &RateLimitMiddleware{
+		limiter: limiter,
+	}
+}
+
+// Limit はルートごとのレート制限ミドルウェアを返す
+// 複数のポリシーを指定した場合はすべてを満たす必要があり、最も厳しい結果をヘッダーに反映する
+func (m *RateLimitMiddleware) Limit(route string, policies ...RateLimitPolicy) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			var strictest *model.RateLimitResult
+			var retryAfter time.Duration
+
+			for _, policy := range policies {
+				key, ok := buildRateLimitKey(c, route, policy.KeyBy)
+				if !ok {
+					continue
+				}
+
+				result, err := m.limiter.Allow(c.Request().Context(), key, policy.Rule)
+				if err != nil {
+					// バックエンド障害時は認証自体を止めないようにフェイルオープンとする
+					slog.WarnContext(c.Request().Context(), "rate limiter unavailable", slog.String("route", route), slog.Any("error", err))
+					continue
+				}
+
+				if strictest == nil || result.Remaining < strictest.Remaining {
+					strictest = result
+				}
+				if !result.Allowed && result.RetryAfter > retryAfter {
+					retryAfter = result.RetryAfter
+				}
+			}
+
+			if strictest == nil {
+				return next(c)
+			}
+
+			header := c.Response().Header()
+			header.Set("RateLimit-Limit", strconv.Itoa(strictest.Limit))
+			header.Set("RateLimit-Remaining", strconv.Itoa(max(strictest.Remaining, 0)))
+			header.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(strictest.ResetAfter)))
+
+			if retryAfter > 0 {
+				header.Set("Retry-After", strconv.Itoa(ceilSeconds(retryAfter)))
+				return core.ErrTooManyRequests
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// buildRateLimitKey はポリシーのキー定義からカウンタのキーを組み立てる
+// 必要な値が取得できない場合（未認証のユーザーキーなど）はfalseを返す
+func buildRateLimitKey(c echo.Context, route string, keyBy []RateLimitKey) (string, bool) {
+	parts := []string{route}
+	for _, key := range keyBy {
+		var value string
+		switch key {
+		case RateLimitKeyIP:
+			value = c.RealIP()
+		case RateLimitKeyUser:
+			value, _ = c.Get("user_id").(string)
+		case RateLimitKeyClient:
+			value = c.Request().Header.Get(ClientIDHeader)
+		}
+		if value == "" {
+			return "", false
+		}
+		parts = append(parts, string(key)+"="+value)
+	}
+	return strings.Join(parts, ":"), true
+}
+
+// ceilSeconds はDurationを切り上げた秒数に変換する
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
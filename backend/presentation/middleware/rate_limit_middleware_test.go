@@ -0,0 +1,212 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"stackies-backend/core"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/service"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockRateLimiter はRateLimiterのモック
+type MockRateLimiter struct {
+	mock.Mock
+}
+
+var _ service.RateLimiter = (*MockRateLimiter)(nil)
+
+func (m *MockRateLimiter) Allow(ctx context.Context, key string, rule model.RateLimitRule) (*model.RateLimitResult, error) {
+	args := m.Called(ctx, key, rule)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.RateLimitResult), args.Error(1)
+}
+
+func TestRateLimitMiddleware_Limit(t *testing.T) {
+	ipRule := model.RateLimitRule{Strategy: model.RateLimitStrategySlidingWindow, Limit: 10, Window: time.Minute}
+	clientRule := model.RateLimitRule{Strategy: model.RateLimitStrategyTokenBucket, Limit: 5, Window: time.Minute}
+	policies := []RateLimitPolicy{
+		{Rule: ipRule, KeyBy: []RateLimitKey{RateLimitKeyIP}},
+		{Rule: clientRule, KeyBy: []RateLimitKey{RateLimitKeyIP, RateLimitKeyClient}},
+	}
+
+	tests := []struct {
+		testName          string
+		clientID          string
+		setupMocks        func(*MockRateLimiter)
+		expectNext        bool
+		expectedRemaining string
+		expectedRetry     string
+	}{
+		{
+			testName: "制限内のリクエスト",
+			clientID: "web",
+			setupMocks: func(limiter *MockRateLimiter) {
+				limiter.On("Allow", mock.Anything, "login:ip=192.0.2.1", ipRule).
+					Return(&model.RateLimitResult{Allowed: true, Limit: 10, Remaining: 9, ResetAfter: 6 * time.Second}, nil)
+				limiter.On("Allow", mock.Anything, "login:ip=192.0.2.1:client=web", clientRule).
+					Return(&model.RateLimitResult{Allowed: true, Limit: 5, Remaining: 4, ResetAfter: 12 * time.Second}, nil)
+			},
+			expectNext:        true,
+			expectedRemaining: "4",
+		},
+		{
+			testName: "クライアントIDがない場合はクライアント単位の制限をスキップ",
+			setupMocks: func(limiter *MockRateLimiter) {
+				limiter.On("Allow", mock.Anything, "login:ip=192.0.2.1", ipRule).
+					Return(&model.RateLimitResult{Allowed: true, Limit: 10, Remaining: 9, ResetAfter: 6 * time.Second}, nil)
+			},
+			expectNext:        true,
+			expectedRemaining: "9",
+		},
+		{
+			testName: "制限超過で429",
+			clientID: "web",
+			setupMocks: func(limiter *MockRateLimiter) {
+				limiter.On("Allow", mock.Anything, "login:ip=192.0.2.1", ipRule).
+					Return(&model.RateLimitResult{Allowed: true, Limit: 10, Remaining: 3, ResetAfter: 40 * time.Second}, nil)
+				limiter.On("Allow", mock.Anything, "login:ip=192.0.2.1:client=web", clientRule).
+					Return(&model.RateLimitResult{Allowed: false, Limit: 5, Remaining: 0, ResetAfter: 60 * time.Second, RetryAfter: 1500 * time.Millisecond}, nil)
+			},
+			expectNext:        false,
+			expectedRemaining: "0",
+			expectedRetry:     "2",
+		},
+		{
+			testName: "バックエンド障害時は通過させる",
+			setupMocks: func(limiter *MockRateLimiter) {
+				limiter.On("Allow", mock.Anything, "login:ip=192.0.2.1", ipRule).Return(nil, errors.New("connection refused"))
+			},
+			expectNext: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			limiter := new(MockRateLimiter)
+			tt.setupMocks(limiter)
+
+			middleware := NewRateLimitMiddleware(limiter)
+			nextCalled := false
+			next := func(c echo.Context) error {
+				nextCalled = true
+				return c.NoContent(http.StatusOK)
+			}
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/auth/google/login", nil)
+			req.RemoteAddr = "192.0.2.1:12345"
+			if tt.clientID != "" {
+				req.Header.Set(ClientIDHeader, tt.clientID)
+			}
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := middleware.Limit("login", policies...)(next)(c)
+
+			assert.Equal(t, tt.expectNext, nextCalled)
+			if tt.expectNext {
+				assert.NoError(t, err)
+			} else {
+				if appErr := core.AsAppError(err); assert.NotNil(t, appErr) {
+					assert.Equal(t, http.StatusTooManyRequests, appErr.Status)
+				}
+				assert.Equal(t, tt.expectedRetry, rec.Header().Get("Retry-After"))
+			}
+			assert.Equal(t, tt.expectedRemaining, rec.Header().Get("RateLimit-Remaining"))
+
+			limiter.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRateLimitMiddleware_Limit_UserKey(t *testing.T) {
+	rule := model.RateLimitRule{Strategy: model.RateLimitStrategyTokenBucket, Limit: 1, Window: time.Minute}
+	limiter := new(MockRateLimiter)
+	limiter.On("Allow", mock.Anything, "me:user=user_123", rule).
+		Return(&model.RateLimitResult{Allowed: true, Limit: 1, Remaining: 0, ResetAfter: time.Minute}, nil)
+
+	middleware := NewRateLimitMiddleware(limiter)
+	next := func(c echo.Context) error { return c.NoContent(http.StatusOK) }
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/auth/me", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("user_id", "user_123")
+
+	err := middleware.Limit("me", RateLimitPolicy{Rule: rule, KeyBy: []RateLimitKey{RateLimitKeyUser}})(next)(c)
+	assert.NoError(t, err)
+	assert.Equal(t, "1", rec.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "60", rec.Header().Get("RateLimit-Reset"))
+	limiter.AssertExpectations(t)
+}
+
+func TestRateLimitMiddleware_Limit_IPExtractor(t *testing.T) {
+	rule := model.RateLimitRule{Strategy: model.RateLimitStrategySlidingWindow, Limit: 10, Window: time.Minute}
+
+	tests := []struct {
+		testName       string
+		trustedProxies []string
+		remoteAddr     string
+		forwardedFor   []string
+		expectedKeys   []string
+	}{
+		{
+			testName:     "プロキシを設定していない場合は偽装したX-Forwarded-Forを無視して同じカウンタを使う",
+			remoteAddr:   "192.0.2.1:12345",
+			forwardedFor: []string{"198.51.100.1", "198.51.100.2", "10.0.0.3"},
+			expectedKeys: []string{"login:ip=192.0.2.1", "login:ip=192.0.2.1", "login:ip=192.0.2.1"},
+		},
+		{
+			testName:       "信頼しない接続元からのX-Forwarded-Forは無視する",
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "192.0.2.1:12345",
+			forwardedFor:   []string{"198.51.100.1", "198.51.100.2"},
+			expectedKeys:   []string{"login:ip=192.0.2.1", "login:ip=192.0.2.1"},
+		},
+		{
+			testName:       "信頼するプロキシを経由した場合はプロキシが追加したアドレスを使う",
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "10.0.0.2:12345",
+			forwardedFor:   []string{"203.0.113.7", "198.51.100.1, 203.0.113.7"},
+			expectedKeys:   []string{"login:ip=203.0.113.7", "login:ip=203.0.113.7"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			limiter := new(MockRateLimiter)
+			for _, key := range tt.expectedKeys {
+				limiter.On("Allow", mock.Anything, key, rule).
+					Return(&model.RateLimitResult{Allowed: true, Limit: 10, Remaining: 9}, nil).Once()
+			}
+
+			e := echo.New()
+			e.IPExtractor = NewIPExtractor(tt.trustedProxies)
+			limit := NewRateLimitMiddleware(limiter).Limit("login", RateLimitPolicy{Rule: rule, KeyBy: []RateLimitKey{RateLimitKeyIP}})
+			next := func(c echo.Context) error { return c.NoContent(http.StatusOK) }
+
+			for _, forwardedFor := range tt.forwardedFor {
+				req := httptest.NewRequest(http.MethodPost, "/auth/google/login", nil)
+				req.RemoteAddr = tt.remoteAddr
+				req.Header.Set(echo.HeaderXForwardedFor, forwardedFor)
+				req.Header.Set(echo.HeaderXRealIP, forwardedFor)
+				c := e.NewContext(req, httptest.NewRecorder())
+
+				assert.NoError(t, limit(next)(c))
+			}
+
+			limiter.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"stackies-backend/core"
+	"stackies-backend/domain/repository"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ErrReauthenticationRequired は直近にログインしていないため再ログインが必要なエラー
+var ErrReauthenticationRequired = core.NewAppError("reauthentication_required", http.StatusForbidden, "Recent authentication required")
+
+// RecentAuthMiddleware はアカウントの削除など重要な操作に直近のログインを要求するミドルウェアを表す
+type RecentAuthMiddleware struct {
+	authRepo repository.AuthRepository
+	maxAge   time.Duration
+}
+
+// NewRecentAuthMiddleware はRecentAuthMiddlewareの新しいインスタンスを作成する
+func NewRecentAuthMiddleware(authRepo repository.AuthRepository, maxAge time.Duration) *RecentAuthMiddleware {
+	return &RecentAuthMiddleware{
+		authRepo: authRepo,
+		maxAge:   maxAge,
+	}
+}
+
+// Require はセッションのログインからmaxAge以内の場合のみ通過させる
+// トークンのリフレッシュではログイン時刻は更新されないため、Googleで再ログインする必要がある
+// Authenticateの後段に配置する
+func (m *RecentAuthMiddleware) Require(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userID, _ := c.Get("user_id").(string)
+		if userID == "" {
+			return core.ErrUnauthorized
+		}
+		token, err := extractToken(c)
+		if err != nil {
+			return err
+		}
+
+		stored, err := m.authRepo.GetToken(c.Request().Context(), userID)
+		if err != nil {
+			if errors.Is(err, repository.ErrTokenNotFound) {
+				return ErrReauthenticationRequired
+			}
+			return err
+		}
+		// 置き換えられた古いセッションのトークンでは通過させない
+		if subtle.ConstantTimeCompare([]byte(stored.AccessToken), []byte(token)) != 1 {
+			return ErrReauthenticationRequired
+		}
+		if !stored.AuthenticatedWithin(m.maxAge, time.Now()) {
+			return ErrReauthenticationRequired
+		}
+
+		return next(c)
+	}
+}
@@ -0,0 +1,155 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"stackies-backend/core"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/repository"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAuthRepository はAuthRepositoryのモック
+type MockAuthRepository struct {
+	mock.Mock
+}
+
+var _ repository.AuthRepository = (*MockAuthRepository)(nil)
+
+func (m *MockAuthRepository) SaveToken(ctx context.Context, userID string, token *model.AuthToken) error {
+	args := m.Called(ctx, userID, token)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) GetToken(ctx context.Context, userID string) (*model.AuthToken, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.AuthToken), args.Error(1)
+}
+
+func (m *MockAuthRepository) DeleteToken(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockAuthRepository) ValidateToken(ctx context.Context, token string) (string, error) {
+	args := m.Called(ctx, token)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockAuthRepository) CountTokens(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+func TestRecentAuthMiddleware_Require(t *testing.T) {
+	recent := time.Now().Add(-time.Minute)
+	stale := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		testName       string
+		userID         string
+		setupMocks     func(*MockAuthRepository)
+		expectedStatus int
+		expectedCode   string
+		expectNext     bool
+	}{
+		{
+			testName: "直近にログインしたセッションは通過できる",
+			userID:   "user_1",
+			setupMocks: func(authRepo *MockAuthRepository) {
+				authRepo.On("GetToken", mock.Anything, "user_1").Return(&model.AuthToken{AccessToken: "access_token", AuthenticatedAt: recent}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectNext:     true,
+		},
+		{
+			testName: "ログインから時間が経っている場合は再ログインが必要",
+			userID:   "user_1",
+			setupMocks: func(authRepo *MockAuthRepository) {
+				authRepo.On("GetToken", mock.Anything, "user_1").Return(&model.AuthToken{AccessToken: "access_token", AuthenticatedAt: stale}, nil)
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedCode:   "reauthentication_required",
+		},
+		{
+			testName: "置き換えられたセッションのトークン",
+			userID:   "user_1",
+			setupMocks: func(authRepo *MockAuthRepository) {
+				authRepo.On("GetToken", mock.Anything, "user_1").Return(&model.AuthToken{AccessToken: "new_access_token", AuthenticatedAt: recent}, nil)
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedCode:   "reauthentication_required",
+		},
+		{
+			testName: "セッションがない",
+			userID:   "user_1",
+			setupMocks: func(authRepo *MockAuthRepository) {
+				authRepo.On("GetToken", mock.Anything, "user_1").Return(nil, repository.ErrTokenNotFound)
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedCode:   "reauthentication_required",
+		},
+		{
+			testName: "リポジトリエラーは500",
+			userID:   "user_1",
+			setupMocks: func(authRepo *MockAuthRepository) {
+				authRepo.On("GetToken", mock.Anything, "user_1").Return(nil, errors.New("connection refused"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			testName:       "未認証は401",
+			setupMocks:     func(authRepo *MockAuthRepository) {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			authRepo := new(MockAuthRepository)
+			tt.setupMocks(authRepo)
+
+			middleware := NewRecentAuthMiddleware(authRepo, 10*time.Minute)
+			nextCalled := false
+			next := func(c echo.Context) error {
+				nextCalled = true
+				return c.NoContent(http.StatusOK)
+			}
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodDelete, "/users/me", nil)
+			req.Header.Set("Authorization", "Bearer access_token")
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			if tt.userID != "" {
+				c.Set("user_id", tt.userID)
+			}
+
+			err := middleware.Require(next)(c)
+
+			assert.Equal(t, tt.expectNext, nextCalled)
+			if tt.expectNext {
+				assert.NoError(t, err)
+			} else {
+				appErr := core.AsAppError(err)
+				if assert.NotNil(t, appErr) {
+					assert.Equal(t, tt.expectedStatus, appErr.Status)
+					if tt.expectedCode != "" {
+						assert.Equal(t, tt.expectedCode, appErr.Code)
+					}
+				}
+			}
+			authRepo.AssertExpectations(t)
+		})
+	}
+}
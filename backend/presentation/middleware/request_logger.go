@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"stackies-backend/core/logger"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// requestIDPattern はクライアントから受け取るX-Request-IDとして許可する形式
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestLogger はリクエストごとにリクエストIDを発行し、アクセスログを構造化して出力するミドルウェアを返す
+// リクエストIDはcontextとX-Request-IDレスポンスヘッダーに設定し、同じリクエスト中のログに付与される
+func RequestLogger(log *slog.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			req := c.Request()
+
+			requestID := req.Header.Get(echo.HeaderXRequestID)
+			if !requestIDPattern.MatchString(requestID) {
+				requestID = newRequestID()
+			}
+			c.Response().Header().Set(echo.HeaderXRequestID, requestID)
+			c.SetRequest(req.WithContext(logger.WithRequestID(req.Context(), requestID)))
+
+			err := next(c)
+			if err != nil {
+				// ステータスコードを確定させるため、ここでエラーハンドラーを呼ぶ
+				c.Error(err)
+			}
+
+			// ハンドラー内で設定されたユーザーIDを含むcontextで出力する
+			status := c.Response().Status
+			level := slog.LevelInfo
+			if status >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+			log.LogAttrs(c.Request().Context(), level, "request",
+				slog.String("method", req.Method),
+				slog.String("uri", req.RequestURI),
+				slog.Int("status", status),
+				slog.Int64("latency_ms", time.Since(start).Milliseconds()),
+				slog.Int64("bytes_out", c.Response().Size),
+				slog.String("ip", c.RealIP()),
+				slog.String("user_agent", req.UserAgent()),
+			)
+
+			return nil
+		}
+	}
+}
+
+// newRequestID はランダムなリクエストIDを生成する
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(b)
+}
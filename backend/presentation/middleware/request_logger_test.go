@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"stackies-backend/core"
+	"stackies-backend/core/logger"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestLogger(t *testing.T) {
+	tests := []struct {
+		testName          string
+		requestID         string
+		handler           echo.HandlerFunc
+		expectedStatus    int
+		expectedLevel     string
+		expectedRequestID string
+	}{
+		{
+			testName:  "正常なリクエスト",
+			requestID: "client-req-1",
+			handler: func(c echo.Context) error {
+				c.SetRequest(c.Request().WithContext(logger.WithUserID(c.Request().Context(), "user_1")))
+				return c.String(http.StatusOK, "ok")
+			},
+			expectedStatus:    http.StatusOK,
+			expectedLevel:     "INFO",
+			expectedRequestID: "client-req-1",
+		},
+		{
+			testName:  "不正なリクエストIDは再発行",
+			requestID: "bad id\n",
+			handler: func(c echo.Context) error {
+				return core.ErrNotFound
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedLevel:  "INFO",
+		},
+		{
+			testName: "サーバーエラー",
+			handler: func(c echo.Context) error {
+				return core.ErrInternal
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedLevel:  "ERROR",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			var buf bytes.Buffer
+			e := echo.New()
+			e.HTTPErrorHandler = NewHTTPErrorHandler()
+			req := httptest.NewRequest(http.MethodGet, "/auth/google/callback?code=secret_code&state=s", nil)
+			if tt.requestID != "" {
+				req.Header.Set(echo.HeaderXRequestID, tt.requestID)
+			}
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := RequestLogger(logger.New(&buf, slog.LevelInfo))(tt.handler)(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+
+			requestID := rec.Header().Get(echo.HeaderXRequestID)
+			if tt.expectedRequestID != "" {
+				assert.Equal(t, tt.expectedRequestID, requestID)
+			} else {
+				assert.Len(t, requestID, 32)
+			}
+
+			// エラーハンドラーのログの後にアクセスログが出力される
+			lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+			var entry map[string]any
+			if assert.NoError(t, json.Unmarshal(lines[len(lines)-1], &entry)) {
+				assert.Equal(t, "request", entry["msg"])
+				assert.Equal(t, tt.expectedLevel, entry["level"])
+				assert.Equal(t, requestID, entry[logger.RequestIDKey])
+				assert.Equal(t, float64(tt.expectedStatus), entry["status"])
+				assert.NotContains(t, entry["uri"], "secret_code")
+			}
+		})
+	}
+}
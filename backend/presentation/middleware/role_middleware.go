@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"errors"
+	"stackies-backend/core"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RoleMiddleware はロールによる認可ミドルウェアを表す
+type RoleMiddleware struct {
+	userRepo repository.UserRepository
+}
+
+// NewRoleMiddleware はRoleMiddlewareの新しいインスタンスを作成する
+func NewRoleMiddleware(userRepo repository.UserRepository) *RoleMiddleware {
+	return &RoleMiddleware{
+		userRepo: userRepo,
+	}
+}
+
+// RequireRole は指定したロールを持つユーザーのみ通過させるミドルウェアを返す
+// ロールはトークンではなく保存済みのユーザー情報から判定するため、降格は即時に反映される
+// Authenticateの後段に配置する
+func (m *RoleMiddleware) RequireRole(role model.Role) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			userID, _ := c.Get("user_id").(string)
+			if userID == "" {
+				return core.ErrUnauthorized
+			}
+
+			user, err := m.userRepo.FindByID(c.Request().Context(), userID)
+			if err != nil {
+				if errors.Is(err, repository.ErrUserNotFound) {
+					return core.ErrForbidden
+				}
+				return err
+			}
+
+			if !user.HasRole(role) {
+				return core.ErrForbidden
+			}
+
+			return next(c)
+		}
+	}
+}
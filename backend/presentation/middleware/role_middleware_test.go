@@ -0,0 +1,152 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"stackies-backend/core"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/repository"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockUserRepository はUserRepositoryのモック
+type MockUserRepository struct {
+	mock.Mock
+}
+
+var _ repository.UserRepository = (*MockUserRepository)(nil)
+
+func (m *MockUserRepository) Save(ctx context.Context, user *model.User) error {
+	args := m.Called(ctx, user)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) FindByID(ctx context.Context, id string) (*model.User, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.User), args.Error(1)
+}
+
+func (m *MockUserRepository) FindByEmail(ctx context.Context, email string) (*model.User, error) {
+	args := m.Called(ctx, email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.User), args.Error(1)
+}
+
+func (m *MockUserRepository) Update(ctx context.Context, user *model.User) error {
+	args := m.Called(ctx, user)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) FindAll(ctx context.Context) ([]*model.User, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.User), args.Error(1)
+}
+
+func (m *MockUserRepository) List(ctx context.Context, filter *model.UserFilter) (*model.UserPage, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.UserPage), args.Error(1)
+}
+
+func (m *MockUserRepository) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func TestRoleMiddleware_RequireRole(t *testing.T) {
+	tests := []struct {
+		testName       string
+		userID         string
+		setupMocks     func(*MockUserRepository)
+		expectedStatus int
+		expectNext     bool
+	}{
+		{
+			testName: "管理者は通過できる",
+			userID:   "admin_1",
+			setupMocks: func(userRepo *MockUserRepository) {
+				userRepo.On("FindByID", mock.Anything, "admin_1").Return(&model.User{ID: "admin_1", Role: model.RoleAdmin}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectNext:     true,
+		},
+		{
+			testName: "一般ユーザーは403",
+			userID:   "user_1",
+			setupMocks: func(userRepo *MockUserRepository) {
+				userRepo.On("FindByID", mock.Anything, "user_1").Return(&model.User{ID: "user_1", Role: model.RoleUser}, nil)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			testName: "存在しないユーザーは403",
+			userID:   "deleted",
+			setupMocks: func(userRepo *MockUserRepository) {
+				userRepo.On("FindByID", mock.Anything, "deleted").Return(nil, repository.ErrUserNotFound)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			testName: "リポジトリエラーは500",
+			userID:   "user_1",
+			setupMocks: func(userRepo *MockUserRepository) {
+				userRepo.On("FindByID", mock.Anything, "user_1").Return(nil, errors.New("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			testName:       "未認証は401",
+			setupMocks:     func(userRepo *MockUserRepository) {},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			userRepo := new(MockUserRepository)
+			tt.setupMocks(userRepo)
+
+			middleware := NewRoleMiddleware(userRepo)
+			nextCalled := false
+			next := func(c echo.Context) error {
+				nextCalled = true
+				return c.NoContent(http.StatusOK)
+			}
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/admin/audit-events", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			if tt.userID != "" {
+				c.Set("user_id", tt.userID)
+			}
+
+			err := middleware.RequireRole(model.RoleAdmin)(next)(c)
+
+			assert.Equal(t, tt.expectNext, nextCalled)
+			if tt.expectNext {
+				assert.NoError(t, err)
+			} else if appErr := core.AsAppError(err); assert.NotNil(t, appErr) {
+				assert.Equal(t, tt.expectedStatus, appErr.Status)
+			}
+
+			userRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"stackies-backend/core"
+	"strconv"
+
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// MIMEApplicationSCIMJSON はSCIM（RFC 7644）のリクエスト・レスポンスのContent-Type
+	MIMEApplicationSCIMJSON = "application/scim+json"
+	// scimErrorSchema はSCIMのエラーレスポンスのスキーマ
+	scimErrorSchema = "urn:ietf:params:scim:api:messages:2.0:Error"
+)
+
+// ErrSCIMDisabled はSCIMのトークンを設定していない場合のエラー（エンドポイントが存在しないものとして扱う）
+var ErrSCIMDisabled = core.NewAppError("scim_disabled", http.StatusNotFound, "SCIM provisioning is not enabled")
+
+// scimTypes はエラーコードに対応するSCIMのscimType
+var scimTypes = map[string]string{
+	"scim_invalid_filter": "invalidFilter",
+	"scim_invalid_syntax": "invalidSyntax",
+	"scim_invalid_path":   "invalidPath",
+	"scim_invalid_value":  "invalidValue",
+	"scim_uniqueness":     "uniqueness",
+	"bad_request":         "invalidSyntax",
+}
+
+func init() {
+	// SCIMのリクエスト・レスポンスをOpenAPIドキュメントで検証できるようにする
+	openapi3filter.RegisterBodyDecoder(MIMEApplicationSCIMJSON, openapi3filter.JSONBodyDecoder)
+}
+
+// SCIMError はSCIMのエラーレスポンスを表す（statusは文字列）
+type SCIMError struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	SCIMType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+}
+
+// SCIMAuthMiddleware は連携元のディレクトリからのSCIMのリクエストをBearerトークンで認証するミドルウェアを表す
+type SCIMAuthMiddleware struct {
+	token string
+}
+
+// NewSCIMAuthMiddleware はSCIMAuthMiddlewareの新しいインスタンスを作成する
+// tokenが空の場合はSCIMのエンドポイントを無効にする
+func NewSCIMAuthMiddleware(token string) *SCIMAuthMiddleware {
+	return &SCIMAuthMiddleware{
+		token: token,
+	}
+}
+
+// Authenticate は設定したトークンと一致するBearerトークンを要求する
+// ユーザーのJWTとは別のトークンで、ユーザー・ロールによる認可は行わない
+func (m *SCIMAuthMiddleware) Authenticate(next echo.HandlerFunc) echo.HandlerFunc {
+	if m.token == "" {
+		return func(c echo.Context) error {
+			return ErrSCIMDisabled
+		}
+	}
+	expected := []byte("Bearer " + m.token)
+	return func(c echo.Context) error {
+		actual := []byte(c.Request().Header.Get(echo.HeaderAuthorization))
+		if subtle.ConstantTimeCompare(actual, expected) != 1 {
+			c.Response().Header().Set(echo.HeaderWWWAuthenticate, "Bearer")
+			return core.ErrUnauthorized
+		}
+		return next(c)
+	}
+}
+
+// SCIMErrors はハンドラー・ミドルウェアが返したエラーをSCIMのエラーレスポンスに変換するミドルウェアを返す
+// 認証のエラーも変換するため、SCIMのルートで最も外側に置く
+func SCIMErrors() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			err := next(c)
+			if err == nil || c.Response().Committed {
+				return err
+			}
+
+			appErr := toAppError(err)
+			if appErr.Status >= http.StatusInternalServerError {
+				slog.ErrorContext(c.Request().Context(), "request failed",
+					slog.String("method", c.Request().Method),
+					slog.String("path", c.Request().URL.Path),
+					slog.Any("error", err),
+				)
+			}
+
+			body, err := json.Marshal(&SCIMError{
+				Schemas:  []string{scimErrorSchema},
+				Status:   strconv.Itoa(appErr.Status),
+				SCIMType: scimTypes[appErr.Code],
+				Detail:   appErr.Message,
+			})
+			if err != nil {
+				return err
+			}
+			return c.Blob(appErr.Status, MIMEApplicationSCIMJSON, body)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"stackies-backend/core"
+	"strconv"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSCIMAuthMiddleware_Authenticate(t *testing.T) {
+	tests := []struct {
+		testName       string
+		token          string
+		authorization  string
+		expectedStatus int
+		expectedType   string
+	}{
+		{testName: "正しいトークン", token: "scim-secret", authorization: "Bearer scim-secret", expectedStatus: http.StatusOK},
+		{testName: "トークンなし", token: "scim-secret", expectedStatus: http.StatusUnauthorized},
+		{testName: "誤ったトークン", token: "scim-secret", authorization: "Bearer wrong", expectedStatus: http.StatusUnauthorized},
+		{testName: "トークン未設定の場合はエンドポイントが存在しない", token: "", authorization: "Bearer ", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			e := echo.New()
+			e.HTTPErrorHandler = NewHTTPErrorHandler()
+			scim := e.Group("/scim/v2", SCIMErrors(), NewSCIMAuthMiddleware(tt.token).Authenticate)
+			scim.GET("/Users", func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/scim/v2/Users", nil)
+			if tt.authorization != "" {
+				req.Header.Set(echo.HeaderAuthorization, tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedStatus != http.StatusOK {
+				assert.Equal(t, MIMEApplicationSCIMJSON, rec.Header().Get(echo.HeaderContentType))
+			}
+		})
+	}
+}
+
+func TestSCIMErrors(t *testing.T) {
+	tests := []struct {
+		testName         string
+		err              error
+		expectedStatus   int
+		expectedSCIMType string
+		expectedDetail   string
+	}{
+		{
+			testName:         "エラーコードに対応するscimTypeを返す",
+			err:              core.NewAppError("scim_uniqueness", http.StatusConflict, "Resource already exists").WithMessage("userName is already in use"),
+			expectedStatus:   http.StatusConflict,
+			expectedSCIMType: "uniqueness",
+			expectedDetail:   "userName is already in use",
+		},
+		{
+			testName:       "ルーティングのエラー",
+			err:            echo.ErrNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedDetail: "Not Found",
+		},
+		{
+			testName:       "原因をレスポンスに含めない",
+			err:            errors.New("database is down"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedDetail: "Internal server error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			e := echo.New()
+			e.GET("/scim/v2/Users", func(c echo.Context) error {
+				return tt.err
+			}, SCIMErrors())
+
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/scim/v2/Users", nil))
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			var body SCIMError
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			assert.Equal(t, []string{scimErrorSchema}, body.Schemas)
+			assert.Equal(t, strconv.Itoa(tt.expectedStatus), body.Status)
+			assert.Equal(t, tt.expectedSCIMType, body.SCIMType)
+			assert.Equal(t, tt.expectedDetail, body.Detail)
+		})
+	}
+}
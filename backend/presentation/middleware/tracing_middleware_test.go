@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(noop.NewTracerProvider())
+	})
+
+	var handlerSpan trace.SpanContext
+	e := echo.New()
+	e.HTTPErrorHandler = NewHTTPErrorHandler()
+	e.Use(Tracing())
+	e.GET("/users/:id", func(c echo.Context) error {
+		handlerSpan = trace.SpanContextFromContext(c.Request().Context())
+		return c.NoContent(http.StatusOK)
+	})
+	e.GET("/fail", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	e.ServeHTTP(httptest.NewRecorder(), req)
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fail", nil))
+
+	spans := recorder.Ended()
+	if !assert.Len(t, spans, 2) {
+		return
+	}
+
+	// 呼び出し元のトレースに連結し、ハンドラーにcontextで伝播する
+	assert.Equal(t, "GET /users/:id", spans[0].Name())
+	assert.Equal(t, trace.SpanKindServer, spans[0].SpanKind())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent().SpanID().String())
+	assert.Equal(t, spans[0].SpanContext().SpanID(), handlerSpan.SpanID())
+	assert.Equal(t, int64(http.StatusOK), attributeOf(spans[0], "http.response.status_code").Value.AsInt64())
+
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Equal(t, int64(http.StatusInternalServerError), attributeOf(spans[1], "http.response.status_code").Value.AsInt64())
+}
+
+// attributeOf はスパンの属性を取得する
+func attributeOf(span sdktrace.ReadOnlySpan, key string) attribute.KeyValue {
+	for _, kv := range span.Attributes() {
+		if string(kv.Key) == key {
+			return kv
+		}
+	}
+	return attribute.KeyValue{}
+}
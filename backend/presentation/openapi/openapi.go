@@ -0,0 +1,64 @@
+// Package openapi はAPIのOpenAPI 3ドキュメントを提供する
+// ドキュメントはopenapi.yamlを正とし、リクエスト・レスポンスの検証とフロントエンドの型の生成に使用する
+package openapi
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+)
+
+//go:embed openapi.yaml
+var document []byte
+
+// Spec は読み込んだOpenAPIドキュメントを表す
+type Spec struct {
+	doc    *openapi3.T
+	router routers.Router
+	json   []byte
+}
+
+// Load は埋め込んだOpenAPIドキュメントを読み込み、内容を検証する
+func Load() (*Spec, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(document)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load openapi document: %w", err)
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("invalid openapi document: %w", err)
+	}
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build openapi router: %w", err)
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode openapi document: %w", err)
+	}
+	return &Spec{doc: doc, router: router, json: data}, nil
+}
+
+// Document はOpenAPIドキュメントを返す
+func (s *Spec) Document() *openapi3.T {
+	return s.doc
+}
+
+// FindRoute はリクエストに対応するOpenAPIのオペレーションを返す
+// ドキュメントにないパス・メソッドの場合はエラーを返す
+func (s *Spec) FindRoute(req *http.Request) (*routers.Route, map[string]string, error) {
+	return s.router.FindRoute(req)
+}
+
+// Handler はOpenAPIドキュメントをJSONで返すHTTPハンドラーを返す（/openapi.json）
+func (s *Spec) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(s.json)
+	})
+}
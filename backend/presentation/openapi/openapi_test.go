@@ -0,0 +1,52 @@
+package openapi
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	spec, err := Load()
+	require.NoError(t, err)
+
+	route, params, err := spec.FindRoute(httptest.NewRequest(http.MethodPut, "/admin/users/user_1/role", nil))
+	require.NoError(t, err)
+	assert.Equal(t, "changeRole", route.Operation.OperationID)
+	assert.Equal(t, "user_1", params["id"])
+
+	_, _, err = spec.FindRoute(httptest.NewRequest(http.MethodGet, "/unknown", nil))
+	assert.Error(t, err)
+}
+
+func TestSpec_Handler(t *testing.T) {
+	spec, err := Load()
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	spec.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), `"openapi":"3.0.3"`)
+	assert.Contains(t, rec.Body.String(), `"/auth/google/login"`)
+}
+
+// TestSpec_TypeScriptUpToDate はフロントエンドの生成済みの型がドキュメントと一致していることを確認する
+// 失敗した場合はbackendディレクトリで `go run . openapi-ts` を実行して再生成する
+func TestSpec_TypeScriptUpToDate(t *testing.T) {
+	generated, err := os.ReadFile("../../../frontend/src/shared/api/schema.gen.ts")
+	if errors.Is(err, os.ErrNotExist) {
+		t.Skip("frontend is not checked out")
+	}
+	require.NoError(t, err)
+	spec, err := Load()
+	require.NoError(t, err)
+
+	assert.Equal(t, string(spec.TypeScript()), string(generated), "run `go run . openapi-ts` to regenerate")
+}
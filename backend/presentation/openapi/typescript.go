@@ -0,0 +1,207 @@
+package openapi
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// TypeScriptHeader は生成したTypeScriptの先頭に付与するコメント
+const TypeScriptHeader = "// Code generated by `go run . openapi-ts` from backend/presentation/openapi/openapi.yaml. DO NOT EDIT.\n"
+
+// TypeScript はフロントエンド向けにスキーマの型とオペレーションの一覧をTypeScriptで返す
+// 出力はドキュメントの内容のみで決まり、同じドキュメントからは常に同じ結果になる
+func (s *Spec) TypeScript() []byte {
+	var buf bytes.Buffer
+	buf.WriteString(TypeScriptHeader)
+
+	schemas := s.doc.Components.Schemas
+	for _, name := range sortedKeys(schemas) {
+		schema := schemas[name].Value
+		buf.WriteString("\n")
+		writeComment(&buf, "", schema.Description)
+		if schema.Type.Is(openapi3.TypeObject) && len(schema.Properties) > 0 {
+			fmt.Fprintf(&buf, "export interface %s ", name)
+			writeObject(&buf, schema, "")
+			buf.WriteString("\n")
+		} else {
+			fmt.Fprintf(&buf, "export type %s = %s\n", name, tsType(schemas[name], ""))
+		}
+	}
+
+	type operation struct {
+		id, method, path string
+		op               *openapi3.Operation
+	}
+	var operations []operation
+	for path, item := range s.doc.Paths.Map() {
+		for method, op := range item.Operations() {
+			operations = append(operations, operation{id: op.OperationID, method: method, path: path, op: op})
+		}
+	}
+	slices.SortFunc(operations, func(a, b operation) int { return strings.Compare(a.id, b.id) })
+
+	buf.WriteString("\n/** オペレーションごとのパス・クエリパラメータ、リクエストボディ、成功時のレスポンス */\n")
+	buf.WriteString("export interface Operations {\n")
+	for _, o := range operations {
+		fmt.Fprintf(&buf, "  %s: {\n", o.id)
+		fmt.Fprintf(&buf, "    path: %s\n", parametersType(o.op, openapi3.ParameterInPath, "    "))
+		fmt.Fprintf(&buf, "    query: %s\n", parametersType(o.op, openapi3.ParameterInQuery, "    "))
+		fmt.Fprintf(&buf, "    body: %s\n", requestBodyType(o.op))
+		fmt.Fprintf(&buf, "    response: %s\n", responseType(o.op))
+		buf.WriteString("  }\n")
+	}
+	buf.WriteString("}\n")
+
+	buf.WriteString("\nexport type OperationId = keyof Operations\n")
+	buf.WriteString("\n/** オペレーションごとのHTTPメソッドとパス（パスパラメータは{name}の形式） */\n")
+	buf.WriteString("export const operations = {\n")
+	for _, o := range operations {
+		fmt.Fprintf(&buf, "  %s: { method: '%s', path: '%s' },\n", o.id, o.method, o.path)
+	}
+	buf.WriteString("} as const satisfies Record<OperationId, { method: string; path: string }>\n")
+
+	return buf.Bytes()
+}
+
+// tsType はスキーマに対応するTypeScriptの型を返す
+func tsType(ref *openapi3.SchemaRef, indent string) string {
+	if ref.Ref != "" {
+		return ref.Ref[strings.LastIndex(ref.Ref, "/")+1:]
+	}
+	schema := ref.Value
+
+	var t string
+	switch {
+	case len(schema.Enum) > 0:
+		values := make([]string, len(schema.Enum))
+		for i, value := range schema.Enum {
+			values[i] = fmt.Sprintf("'%v'", value)
+		}
+		t = strings.Join(values, " | ")
+	case schema.Type.Is(openapi3.TypeString):
+		t = "string"
+	case schema.Type.Is(openapi3.TypeInteger), schema.Type.Is(openapi3.TypeNumber):
+		t = "number"
+	case schema.Type.Is(openapi3.TypeBoolean):
+		t = "boolean"
+	case schema.Type.Is(openapi3.TypeArray):
+		t = tsType(schema.Items, indent) + "[]"
+	case schema.Type.Is(openapi3.TypeObject) && len(schema.Properties) > 0:
+		var buf bytes.Buffer
+		writeObject(&buf, schema, indent)
+		t = buf.String()
+	case schema.Type.Is(openapi3.TypeObject) && schema.AdditionalProperties.Schema != nil:
+		t = "Record<string, " + tsType(schema.AdditionalProperties.Schema, indent) + ">"
+	default:
+		t = "Record<string, unknown>"
+	}
+
+	if schema.Nullable {
+		t += " | null"
+	}
+	return t
+}
+
+// writeObject はプロパティを持つオブジェクトの型を書き込む（必須でないプロパティは省略可能にする）
+func writeObject(buf *bytes.Buffer, schema *openapi3.Schema, indent string) {
+	buf.WriteString("{\n")
+	for _, name := range sortedKeys(schema.Properties) {
+		property := schema.Properties[name]
+		optional := "?"
+		if slices.Contains(schema.Required, name) {
+			optional = ""
+		}
+		if property.Ref == "" {
+			writeComment(buf, indent+"  ", property.Value.Description)
+		}
+		fmt.Fprintf(buf, "%s  %s%s: %s\n", indent, name, optional, tsType(property, indent+"  "))
+	}
+	buf.WriteString(indent + "}")
+}
+
+// parametersType はパスまたはクエリパラメータの型を返す（パラメータがない場合はnever）
+func parametersType(op *openapi3.Operation, in, indent string) string {
+	var fields []string
+	for _, param := range op.Parameters {
+		p := param.Value
+		if p.In != in {
+			continue
+		}
+		optional := "?"
+		if p.Required {
+			optional = ""
+		}
+		fields = append(fields, fmt.Sprintf("%s  %s%s: %s\n", indent, p.Name, optional, tsType(p.Schema, indent+"  ")))
+	}
+	if len(fields) == 0 {
+		return "never"
+	}
+	slices.Sort(fields)
+	return "{\n" + strings.Join(fields, "") + indent + "}"
+}
+
+// requestBodyType はJSONのリクエストボディの型を返す（ボディがない場合はnever、省略可能な場合はundefinedを含む）
+func requestBodyType(op *openapi3.Operation) string {
+	if op.RequestBody == nil {
+		return "never"
+	}
+	media := op.RequestBody.Value.Content.Get("application/json")
+	if media == nil {
+		return "never"
+	}
+	t := tsType(media.Schema, "    ")
+	if !op.RequestBody.Value.Required {
+		t += " | undefined"
+	}
+	return t
+}
+
+// responseType は成功時（2xx）のJSONのレスポンスの型を返す（本文がない場合はvoid）
+func responseType(op *openapi3.Operation) string {
+	for status := http.StatusOK; status < http.StatusMultipleChoices; status++ {
+		response := op.Responses.Status(status)
+		if response == nil {
+			continue
+		}
+		if media := response.Value.Content.Get("application/json"); media != nil {
+			return tsType(media.Schema, "    ")
+		}
+		if media := response.Value.Content.Get("text/plain"); media != nil {
+			return "string"
+		}
+	}
+	return "void"
+}
+
+// writeComment は説明をJSDocのコメントとして書き込む
+func writeComment(buf *bytes.Buffer, indent, description string) {
+	description = strings.TrimSpace(description)
+	if description == "" {
+		return
+	}
+	lines := strings.Split(description, "\n")
+	if len(lines) == 1 {
+		fmt.Fprintf(buf, "%s/** %s */\n", indent, lines[0])
+		return
+	}
+	buf.WriteString(indent + "/**\n")
+	for _, line := range lines {
+		fmt.Fprintf(buf, "%s * %s\n", indent, line)
+	}
+	buf.WriteString(indent + " */\n")
+}
+
+// sortedKeys はmapのキーを昇順で返す
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"stackies-backend/core/config"
+	"stackies-backend/domain/model"
+	"stackies-backend/presentation/middleware"
+
+	"github.com/labstack/echo/v4"
+)
+
+// registerRoutes はハンドラーをルートに割り当てる
+func registerRoutes(e *echo.Echo, cfg config.ServerConfig, components Components) {
+	authMW := components.GetAuthMiddleware()
+	roleMW := components.GetRoleMiddleware()
+	recentAuthMW := components.GetRecentAuthMiddleware()
+	limits := components.GetRateLimits()
+	authHandler := components.GetAuthHandler()
+	oauthHandler := components.GetOAuthHandler()
+	auditHandler := components.GetAuditHandler()
+	adminHandler := components.GetAdminHandler()
+	userHandler := components.GetUserHandler()
+	accountHandler := components.GetAccountHandler()
+	securityHandler := components.GetSecurityHandler()
+	webhookHandler := components.GetWebhookHandler()
+	scimHandler := components.GetSCIMHandler()
+	healthHandler := components.GetHealthHandler()
+
+	e.GET("/livez", healthHandler.Livez)
+	e.GET("/readyz", healthHandler.Readyz)
+	// /health は既存の監視設定との互換のためReadinessと同じ結果を返す
+	e.GET("/health", healthHandler.Readyz)
+	e.GET("/metrics", echo.WrapHandler(components.GetMetrics().Handler()), middleware.RequireMetricsToken(cfg.MetricsToken))
+	e.GET("/openapi.json", echo.WrapHandler(components.GetOpenAPISpec().Handler()))
+	e.GET("/auth/google/url", oauthHandler.GoogleAuthURL, limits.AuthURL)
+	e.GET("/auth/google/callback", oauthHandler.GoogleCallback, limits.Login)
+	e.POST("/auth/google/exchange", oauthHandler.ExchangeLoginCode, limits.Login)
+	e.POST("/auth/google/login", authHandler.GoogleLogin, limits.Login)
+	e.POST("/auth/refresh", authHandler.RefreshToken, limits.Refresh)
+	// アカウントの変更・削除・データの持ち出しなど、本人の操作が必要なAPIはなりすましのトークンを拒否する
+	e.POST("/auth/logout", authHandler.Logout, authMW.Authenticate, authMW.DenyImpersonation, limits.User)
+	e.POST("/auth/not-me", securityHandler.ReportNotMe, limits.Login)
+	e.GET("/auth/me", authHandler.GetMe, authMW.Authenticate, limits.User)
+	e.PATCH("/users/me", userHandler.UpdateMe, authMW.Authenticate, authMW.DenyImpersonation, limits.User)
+	e.DELETE("/users/me", accountHandler.DeleteMe, authMW.Authenticate, authMW.DenyImpersonation, recentAuthMW.Require, limits.User)
+	e.GET("/users/me/export", accountHandler.ExportMe, authMW.Authenticate, authMW.DenyImpersonation, limits.User)
+	e.POST("/users/me/avatar", userHandler.UploadAvatar, authMW.Authenticate, authMW.DenyImpersonation, limits.User)
+	e.DELETE("/users/me/avatar", userHandler.DeleteAvatar, authMW.Authenticate, authMW.DenyImpersonation, limits.User)
+	e.GET("/users/me/audit-events", auditHandler.ListMyEvents, authMW.Authenticate, limits.User)
+	e.GET("/users/:id", userHandler.GetProfile, authMW.Authenticate, limits.User)
+	// <img>から認証情報なしで取得するため、プロフィール画像は認証を要求しない
+	e.GET("/users/:id/avatar", userHandler.GetAvatar)
+
+	// なりすましのトークンでは管理者APIを利用できない（管理者へのなりすましは発行時にも拒否する）
+	admin := e.Group("/admin", authMW.Authenticate, authMW.DenyImpersonation, roleMW.RequireRole(model.RoleAdmin))
+	admin.GET("/audit-events", auditHandler.ListEvents)
+	admin.GET("/users", adminHandler.ListUsers)
+	admin.PUT("/users/:id/role", adminHandler.ChangeRole)
+	admin.PUT("/users/:id/status", adminHandler.ChangeStatus)
+	admin.POST("/users/:id/impersonate", adminHandler.Impersonate)
+	admin.GET("/webhooks", webhookHandler.ListWebhooks)
+	admin.POST("/webhooks", webhookHandler.CreateWebhook)
+	admin.GET("/webhooks/:id", webhookHandler.GetWebhook)
+	admin.PATCH("/webhooks/:id", webhookHandler.UpdateWebhook)
+	admin.DELETE("/webhooks/:id", webhookHandler.DeleteWebhook)
+	admin.GET("/webhooks/:id/deliveries", webhookHandler.ListDeliveries)
+	admin.POST("/webhooks/:id/deliveries/:deliveryId/replay", webhookHandler.ReplayDelivery)
+
+	// 連携元のディレクトリからのプロビジョニング（ユーザーのJWTではなくSCIM_TOKENで認証し、エラーはSCIMの形式で返す）
+	scim := e.Group("/scim/v2", middleware.SCIMErrors(), components.GetSCIMAuthMiddleware().Authenticate)
+	scim.GET("/Users", scimHandler.ListUsers)
+	scim.POST("/Users", scimHandler.CreateUser)
+	scim.GET("/Users/:id", scimHandler.GetUser)
+	scim.PUT("/Users/:id", scimHandler.ReplaceUser)
+	scim.PATCH("/Users/:id", scimHandler.PatchUser)
+	scim.DELETE("/Users/:id", scimHandler.DeleteUser)
+	scim.GET("/Groups", scimHandler.ListGroups)
+	scim.POST("/Groups", scimHandler.CreateGroup)
+	scim.GET("/Groups/:id", scimHandler.GetGroup)
+	scim.PUT("/Groups/:id", scimHandler.ReplaceGroup)
+	scim.PATCH("/Groups/:id", scimHandler.PatchGroup)
+	scim.DELETE("/Groups/:id", scimHandler.DeleteGroup)
+}
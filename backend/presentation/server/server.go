@@ -0,0 +1,195 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"stackies-backend/core/config"
+	"stackies-backend/core/metrics"
+	"stackies-backend/presentation/handler"
+	"stackies-backend/presentation/middleware"
+	"stackies-backend/presentation/openapi"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	echoMiddleware "github.com/labstack/echo/v4/middleware"
+)
+
+// Components はルーティングに使うハンドラー・ミドルウェアを提供する（registry.Containerが実装する）
+type Components interface {
+	GetAuthHandler() *handler.AuthHandler
+	GetOAuthHandler() *handler.OAuthHandler
+	GetAuditHandler() *handler.AuditHandler
+	GetAdminHandler() *handler.AdminHandler
+	GetUserHandler() *handler.UserHandler
+	GetAccountHandler() *handler.AccountHandler
+	GetSecurityHandler() *handler.SecurityHandler
+	GetWebhookHandler() *handler.WebhookHandler
+	GetSCIMHandler() *handler.SCIMHandler
+	GetHealthHandler() *handler.HealthHandler
+	GetAuthMiddleware() *middleware.AuthMiddleware
+	GetRoleMiddleware() *middleware.RoleMiddleware
+	GetRecentAuthMiddleware() *middleware.RecentAuthMiddleware
+	GetSCIMAuthMiddleware() *middleware.SCIMAuthMiddleware
+	GetRateLimits() *middleware.RateLimits
+	GetMetrics() *metrics.Metrics
+	GetOpenAPISpec() *openapi.Spec
+}
+
+// Server はAPIサーバーを表す
+type Server struct {
+	cfg           config.ServerConfig
+	echo          *echo.Echo
+	httpServer    *http.Server
+	shutdownHooks []shutdownHook
+}
+
+// shutdownHook は停止時に実行する処理を表す
+type shutdownHook struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// New はミドルウェアとルートを登録した新しいServerを作成する
+func New(cfg config.ServerConfig, components Components, log *slog.Logger) *Server {
+	e := echo.New()
+	e.HideBanner = true
+	e.HidePort = true
+	e.HTTPErrorHandler = middleware.NewHTTPErrorHandler()
+	// レート制限・監査ログのIPアドレスをクライアントが偽装できないようにする
+	e.IPExtractor = middleware.NewIPExtractor(cfg.TrustedProxies)
+
+	// ステータスコードが確定した後に記録するため、RequestLoggerより外側に置く
+	// アクセスログにトレースIDを付与するため、Tracingを最も外側に置く
+	e.Use(middleware.Tracing())
+	e.Use(middleware.Metrics(components.GetMetrics()))
+	e.Use(middleware.RequestLogger(log))
+	e.Use(echoMiddleware.RecoverWithConfig(echoMiddleware.RecoverConfig{
+		LogErrorFunc: func(c echo.Context, err error, stack []byte) error {
+			slog.ErrorContext(c.Request().Context(), "panic recovered", slog.Any("error", err), slog.String("stack", string(stack)))
+			return err
+		},
+	}))
+	e.Use(echoMiddleware.CORSWithConfig(echoMiddleware.CORSConfig{
+		AllowOrigins:     cfg.CORSAllowOrigins,
+		AllowMethods:     []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions},
+		AllowHeaders:     []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization},
+		AllowCredentials: true,
+	}))
+	// 検証やハンドラーがリクエストボディをメモリに読み込む前にサイズを制限する
+	if cfg.BodyLimit > 0 {
+		e.Use(echoMiddleware.BodyLimitWithConfig(echoMiddleware.BodyLimitConfig{Limit: strconv.Itoa(cfg.BodyLimit)}))
+	}
+	// ハンドラーに渡す前にリクエストを、送信する前にレスポンスをOpenAPIドキュメントで検証する
+	e.Use(middleware.OpenAPIValidator(components.GetOpenAPISpec(), cfg.OpenAPIResponseValidation))
+
+	registerRoutes(e, cfg, components)
+
+	return &Server{
+		cfg:  cfg,
+		echo: e,
+		httpServer: &http.Server{
+			Addr:              ":" + strconv.Itoa(cfg.Port),
+			Handler:           e,
+			ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+			ReadTimeout:       cfg.ReadTimeout,
+			WriteTimeout:      cfg.WriteTimeout,
+			IdleTimeout:       cfg.IdleTimeout,
+			ErrorLog:          slog.NewLogLogger(log.Handler(), slog.LevelWarn),
+		},
+	}
+}
+
+// Echo はルートを登録したEchoのインスタンスを返す
+func (s *Server) Echo() *echo.Echo {
+	return s.echo
+}
+
+// OnShutdown はリクエストの処理が完了した後に実行する停止処理を登録する
+// 登録順に実行されるため、リポジトリやバックグラウンド処理は依存される側を後に登録する
+func (s *Server) OnShutdown(name string, fn func(ctx context.Context) error) {
+	s.shutdownHooks = append(s.shutdownHooks, shutdownHook{name: name, fn: fn})
+}
+
+// Run は設定されたポートで待ち受け、ctxがキャンセルされるまでリクエストを処理する
+func (s *Server) Run(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.httpServer.Addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(ctx, listener)
+}
+
+// Serve はlistenerでリクエストを処理し、ctxがキャンセルされたら停止する
+// 停止時は新しい接続の受け付けをやめ、処理中のリクエストの完了をShutdownTimeoutまで待ってから停止処理を実行する
+// 停止処理にはリクエストの待機で使い切った期限を渡さず、それぞれShutdownHookTimeoutまで待つ
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		var err error
+		if s.cfg.TLSCertFile != "" {
+			err = s.httpServer.ServeTLS(listener, s.cfg.TLSCertFile, s.cfg.TLSKeyFile)
+		} else {
+			err = s.httpServer.Serve(listener)
+		}
+		serveErr <- err
+	}()
+
+	slog.InfoContext(ctx, "server started",
+		slog.String("addr", listener.Addr().String()),
+		slog.Bool("tls", s.cfg.TLSCertFile != ""),
+	)
+
+	var errs []error
+	select {
+	case err := <-serveErr:
+		// 待ち受けに失敗した場合も停止処理は実行する
+		if !errors.Is(err, http.ErrServerClosed) {
+			errs = append(errs, err)
+		}
+	case <-ctx.Done():
+	}
+
+	slog.Info("shutting down server", slog.Duration("timeout", s.cfg.ShutdownTimeout))
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.cfg.ShutdownTimeout)
+	defer cancel()
+
+	if err := s.httpServer.Shutdown(shutdownCtx); err != nil {
+		errs = append(errs, err)
+		// 期限内に完了しなかった接続は強制的に閉じる
+		_ = s.httpServer.Close()
+	}
+
+	for _, hook := range s.shutdownHooks {
+		if err := s.runShutdownHook(hook); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	slog.Info("server stopped")
+	return errors.Join(errs...)
+}
+
+// runShutdownHook は停止処理をShutdownHookTimeoutの期限付きで実行する
+func (s *Server) runShutdownHook(hook shutdownHook) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.cfg.ShutdownHookTimeout)
+	defer cancel()
+
+	err := hook.fn(ctx)
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+		slog.Error("shutdown hook timed out",
+			slog.String("hook", hook.name),
+			slog.Duration("timeout", s.cfg.ShutdownHookTimeout),
+			slog.Any("error", err),
+		)
+	} else {
+		slog.Error("shutdown hook failed", slog.String("hook", hook.name), slog.Any("error", err))
+	}
+	return fmt.Errorf("shutdown hook %s: %w", hook.name, err)
+}
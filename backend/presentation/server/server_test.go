@@ -0,0 +1,373 @@
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"stackies-backend/core/config"
+	"stackies-backend/core/health"
+	"stackies-backend/core/metrics"
+	"stackies-backend/presentation/handler"
+	"stackies-backend/presentation/middleware"
+	"stackies-backend/presentation/openapi"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubComponents はルーティングの確認用に依存を持たないハンドラーを返すComponents
+type stubComponents struct {
+	metrics *metrics.Metrics
+	spec    *openapi.Spec
+}
+
+var _ Components = (*stubComponents)(nil)
+
+func (s *stubComponents) GetAuthHandler() *handler.AuthHandler {
+	return handler.NewAuthHandler(nil, nil)
+}
+
+func (s *stubComponents) GetOAuthHandler() *handler.OAuthHandler {
+	return handler.NewOAuthHandler(nil, handler.CallbackModeCode)
+}
+
+func (s *stubComponents) GetAuditHandler() *handler.AuditHandler {
+	return handler.NewAuditHandler(nil)
+}
+
+func (s *stubComponents) GetAdminHandler() *handler.AdminHandler {
+	return handler.NewAdminHandler(nil)
+}
+
+func (s *stubComponents) GetUserHandler() *handler.UserHandler {
+	return handler.NewUserHandler(nil, 1<<20)
+}
+
+func (s *stubComponents) GetAccountHandler() *handler.AccountHandler {
+	return handler.NewAccountHandler(nil)
+}
+
+func (s *stubComponents) GetSecurityHandler() *handler.SecurityHandler {
+	return handler.NewSecurityHandler(nil)
+}
+
+func (s *stubComponents) GetWebhookHandler() *handler.WebhookHandler {
+	return handler.NewWebhookHandler(nil)
+}
+
+func (s *stubComponents) GetSCIMHandler() *handler.SCIMHandler {
+	return handler.NewSCIMHandler(nil)
+}
+
+func (s *stubComponents) GetHealthHandler() *handler.HealthHandler {
+	registry := health.NewRegistry(time.Second, time.Second)
+	registry.Register("redis", health.KindReadiness, func(ctx context.Context) error { return nil })
+	return handler.NewHealthHandler(registry)
+}
+
+func (s *stubComponents) GetMetrics() *metrics.Metrics {
+	return s.metrics
+}
+
+func (s *stubComponents) GetOpenAPISpec() *openapi.Spec {
+	return s.spec
+}
+
+func (s *stubComponents) GetAuthMiddleware() *middleware.AuthMiddleware {
+	return middleware.NewAuthMiddleware(nil, nil, nil)
+}
+
+func (s *stubComponents) GetRoleMiddleware() *middleware.RoleMiddleware {
+	return middleware.NewRoleMiddleware(nil)
+}
+
+func (s *stubComponents) GetRecentAuthMiddleware() *middleware.RecentAuthMiddleware {
+	return middleware.NewRecentAuthMiddleware(nil, time.Minute)
+}
+
+func (s *stubComponents) GetSCIMAuthMiddleware() *middleware.SCIMAuthMiddleware {
+	return middleware.NewSCIMAuthMiddleware("scim-token-for-server-tests-0123456789")
+}
+
+func (s *stubComponents) GetRateLimits() *middleware.RateLimits {
+	pass := func(next echo.HandlerFunc) echo.HandlerFunc { return next }
+	return &middleware.RateLimits{AuthURL: pass, Login: pass, Refresh: pass, User: pass}
+}
+
+func testServerConfig() config.ServerConfig {
+	return config.ServerConfig{
+		ReadHeaderTimeout:   time.Second,
+		ReadTimeout:         time.Second,
+		WriteTimeout:        5 * time.Second,
+		IdleTimeout:         time.Second,
+		ShutdownTimeout:     2 * time.Second,
+		ShutdownHookTimeout: time.Second,
+
+		OpenAPIResponseValidation: middleware.ResponseValidationStrict,
+	}
+}
+
+func newTestServer(cfg config.ServerConfig) *Server {
+	spec, err := openapi.Load()
+	if err != nil {
+		panic(err)
+	}
+	return New(cfg, &stubComponents{metrics: metrics.New(), spec: spec}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestServer_Routes(t *testing.T) {
+	tests := []struct {
+		testName       string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{testName: "Liveness", method: http.MethodGet, path: "/livez", expectedStatus: http.StatusOK, expectedBody: `"status":"up"`},
+		{testName: "Readiness", method: http.MethodGet, path: "/readyz", expectedStatus: http.StatusOK, expectedBody: `"name":"redis"`},
+		{testName: "旧ヘルスチェックはReadinessと同じ", method: http.MethodGet, path: "/health", expectedStatus: http.StatusOK, expectedBody: `"name":"redis"`},
+		{testName: "認証が必要", method: http.MethodGet, path: "/auth/me", expectedStatus: http.StatusUnauthorized, expectedBody: `"code":"missing_token"`},
+		{testName: "プロフィールの編集は認証が必要", method: http.MethodPatch, path: "/users/me", body: `{"name":"Edited User"}`, expectedStatus: http.StatusUnauthorized, expectedBody: `"code":"missing_token"`},
+		{testName: "公開プロフィールは認証が必要", method: http.MethodGet, path: "/users/user_1", expectedStatus: http.StatusUnauthorized, expectedBody: `"code":"missing_token"`},
+		{testName: "アカウントの削除は認証が必要", method: http.MethodDelete, path: "/users/me", expectedStatus: http.StatusUnauthorized, expectedBody: `"code":"missing_token"`},
+		{testName: "画像の削除は認証が必要", method: http.MethodDelete, path: "/users/me/avatar", expectedStatus: http.StatusUnauthorized, expectedBody: `"code":"missing_token"`},
+		{testName: "管理者APIは認証が必要", method: http.MethodGet, path: "/admin/audit-events", expectedStatus: http.StatusUnauthorized},
+		{testName: "ユーザーの検索は認証が必要", method: http.MethodGet, path: "/admin/users?status=active", expectedStatus: http.StatusUnauthorized, expectedBody: `"code":"missing_token"`},
+		{testName: "状態の変更は認証が必要", method: http.MethodPut, path: "/admin/users/user_1/status", body: `{"status":"disabled","reason":"spam"}`, expectedStatus: http.StatusUnauthorized, expectedBody: `"code":"missing_token"`},
+		{testName: "なりすましは認証が必要", method: http.MethodPost, path: "/admin/users/user_1/impersonate", body: `{"reason":"ticket #42"}`, expectedStatus: http.StatusUnauthorized, expectedBody: `"code":"missing_token"`},
+		{testName: "Webhookの作成は認証が必要", method: http.MethodPost, path: "/admin/webhooks", body: `{"url":"https://example.com/hooks","event_types":["user.registered"]}`, expectedStatus: http.StatusUnauthorized, expectedBody: `"code":"missing_token"`},
+		{testName: "配信の再送は認証が必要", method: http.MethodPost, path: "/admin/webhooks/wh_1/deliveries/del_1/replay", expectedStatus: http.StatusUnauthorized, expectedBody: `"code":"missing_token"`},
+		{testName: "SCIMはトークンが必要", method: http.MethodGet, path: "/scim/v2/Users", expectedStatus: http.StatusUnauthorized, expectedBody: `"schemas":["urn:ietf:params:scim:api:messages:2.0:Error"]`},
+		{testName: "SCIMのグループもトークンが必要", method: http.MethodDelete, path: "/scim/v2/Groups/grp_1", expectedStatus: http.StatusUnauthorized, expectedBody: `"status":"401"`},
+		{testName: "メトリクス", method: http.MethodGet, path: "/metrics", expectedStatus: http.StatusOK, expectedBody: "stackies_http_request_duration_seconds"},
+		{testName: "OpenAPIドキュメント", method: http.MethodGet, path: "/openapi.json", expectedStatus: http.StatusOK, expectedBody: `"openapi":"3.0.3"`},
+		{testName: "ドキュメントと一致しないリクエスト", method: http.MethodPost, path: "/auth/google/login", body: `{"code":"","state":"state"}`, expectedStatus: http.StatusBadRequest, expectedBody: `"code":"validation_failed"`},
+		{testName: "存在しないルート", method: http.MethodGet, path: "/unknown", expectedStatus: http.StatusNotFound, expectedBody: `"code":"not_found"`},
+	}
+
+	srv := newTestServer(testServerConfig())
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+
+			srv.Echo().ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+			assert.NotEmpty(t, rec.Header().Get(echo.HeaderXRequestID))
+		})
+	}
+}
+
+func TestServer_GracefulShutdown(t *testing.T) {
+	srv := newTestServer(testServerConfig())
+	started := make(chan struct{})
+	srv.Echo().GET("/slow", func(c echo.Context) error {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		return c.String(http.StatusOK, "done")
+	})
+
+	var order []string
+	requestDone := make(chan struct{})
+	srv.OnShutdown("repositories", func(ctx context.Context) error {
+		<-requestDone
+		order = append(order, "repositories")
+		return nil
+	})
+	srv.OnShutdown("workers", func(ctx context.Context) error {
+		order = append(order, "workers")
+		return nil
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve(ctx, listener) }()
+
+	url := "http://" + listener.Addr().String()
+	responses := make(chan *http.Response, 1)
+	go func() {
+		resp, err := http.Get(url + "/slow")
+		if err != nil {
+			t.Error(err)
+			close(responses)
+			return
+		}
+		responses <- resp
+	}()
+
+	// 処理中のリクエストがある状態で停止を要求する
+	<-started
+	cancel()
+
+	resp, ok := <-responses
+	close(requestDone)
+	if assert.True(t, ok) {
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "done", string(body))
+	}
+
+	assert.NoError(t, <-served)
+	assert.Equal(t, []string{"repositories", "workers"}, order)
+
+	// 停止後は新しい接続を受け付けない
+	_, err = http.Get(url + "/health")
+	assert.Error(t, err)
+}
+
+func TestServer_ShutdownTimeout(t *testing.T) {
+	cfg := testServerConfig()
+	cfg.ShutdownTimeout = 50 * time.Millisecond
+	srv := newTestServer(cfg)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	srv.Echo().GET("/stuck", func(c echo.Context) error {
+		close(started)
+		<-release
+		return c.NoContent(http.StatusOK)
+	})
+	defer close(release)
+
+	hookCalled := false
+	srv.OnShutdown("repositories", func(ctx context.Context) error {
+		// リクエストの待機で期限を過ぎても、停止処理には新しい期限を渡す
+		hookCalled = ctx.Err() == nil
+		return nil
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve(ctx, listener) }()
+	go func() {
+		resp, err := http.Get("http://" + listener.Addr().String() + "/stuck")
+		if err == nil {
+			resp.Body.Close()
+		}
+	}()
+
+	<-started
+	cancel()
+
+	// 期限を過ぎた場合はエラーを返すが、停止処理は実行する
+	assert.ErrorIs(t, <-served, context.DeadlineExceeded)
+	assert.True(t, hookCalled)
+}
+
+func TestServer_ShutdownHookTimeout(t *testing.T) {
+	cfg := testServerConfig()
+	cfg.ShutdownHookTimeout = 50 * time.Millisecond
+	srv := newTestServer(cfg)
+
+	srv.OnShutdown("workers", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	nextCalled := false
+	srv.OnShutdown("repositories", func(ctx context.Context) error {
+		nextCalled = ctx.Err() == nil
+		return nil
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// 期限を過ぎた停止処理はエラーを返すが、次の停止処理には新しい期限を渡す
+	err = srv.Serve(ctx, listener)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorContains(t, err, "shutdown hook workers")
+	assert.True(t, nextCalled)
+}
+
+func TestServer_TLS(t *testing.T) {
+	cfg := testServerConfig()
+	cfg.TLSCertFile, cfg.TLSKeyFile = writeSelfSignedCert(t)
+	srv := newTestServer(cfg)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve(ctx, listener) }()
+
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, //nolint:gosec // 自己署名証明書のテスト
+	}}
+	resp, err := client.Get("https://" + listener.Addr().String() + "/health")
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	cancel()
+	assert.NoError(t, <-served)
+}
+
+// writeSelfSignedCert はテスト用の自己署名証明書と秘密鍵をファイルに書き出す
+func writeSelfSignedCert(t *testing.T) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
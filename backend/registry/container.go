@@ -1,47 +1,249 @@
 package registry
 
 import (
-	"os"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"stackies-backend/core/config"
+	"stackies-backend/core/health"
+	"stackies-backend/core/metrics"
+	"stackies-backend/core/tracing"
+	"stackies-backend/domain/repository"
 	"stackies-backend/domain/service"
 	"stackies-backend/infra/external"
+	"stackies-backend/presentation/handler"
+	"stackies-backend/presentation/middleware"
+	"stackies-backend/presentation/openapi"
+	"stackies-backend/usecase"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Container はDependency Injection用のコンテナ
+// 設定からリポジトリ・サービス・ユースケース・ハンドラー・ミドルウェアを構築して保持する
+// 各コンポーネントは最初に取得されたときに構築され、以降は同じインスタンスを返す
+// テストではGetより前にSetで任意のコンポーネントを差し替えられる
 type Container struct {
-	googleService service.GoogleService
-	jwtService    service.JWTService
+	config *config.Config
+
+	// 構築時のエラー（Buildでまとめて返す）
+	errs []error
+	// ライフサイクルフック（登録順に開始し、逆順に停止する）
+	startHooks []hook
+	stopHooks  []hook
+	// 各インフラコンポーネントが疎通確認を登録するヘルスチェック
+	healthRegistry *health.Registry
+	metrics        *metrics.Metrics
+	tracerProvider trace.TracerProvider
+
+	redisClient *redis.Client
+
+	userRepository            repository.UserRepository
+	authRepository            repository.AuthRepository
+	auditRepository           repository.AuditRepository
+	oauthRepository           repository.OAuthRepository
+	revocationTokenRepository repository.RevocationTokenRepository
+	dataExportRepository      repository.DataExportRepository
+	outboxRepository          repository.OutboxRepository
+	webhookRepository         repository.WebhookRepository
+	webhookDeliveryRepository repository.WebhookDeliveryRepository
+	groupRepository           repository.GroupRepository
+	transactor                repository.Transactor
+	migrator                  service.Migrator
+
+	httpClientFactory  *external.HTTPClientFactory
+	googleService      service.GoogleService
+	jwtService         service.JWTService
+	rateLimiter        service.RateLimiter
+	geoIPService       service.GeoIPService
+	geoIPResolved      bool
+	loginAlertNotifier service.LoginAlertNotifier
+	blobStore          service.BlobStore
+	imageProcessor     service.ImageProcessor
+	webhookSender      service.WebhookSender
+
+	authUsecase      usecase.AuthUsecase
+	oauthUsecase     usecase.OAuthUsecase
+	auditUsecase     usecase.AuditUsecase
+	adminUsecase     usecase.AdminUsecase
+	loginRiskUsecase usecase.LoginRiskUsecase
+	userUsecase      usecase.UserUsecase
+	accountUsecase   usecase.AccountUsecase
+	eventOutbox      *usecase.EventOutbox
+	eventDispatcher  *usecase.EventDispatcher
+	webhookUsecase   usecase.WebhookUsecase
+	scimUsecase      usecase.SCIMUsecase
+
+	authMiddleware       *middleware.AuthMiddleware
+	roleMiddleware       *middleware.RoleMiddleware
+	recentAuthMiddleware *middleware.RecentAuthMiddleware
+	rateLimitMiddleware  *middleware.RateLimitMiddleware
+	rateLimits           *middleware.RateLimits
+	scimAuthMiddleware   *middleware.SCIMAuthMiddleware
+
+	authHandler     *handler.AuthHandler
+	oauthHandler    *handler.OAuthHandler
+	auditHandler    *handler.AuditHandler
+	adminHandler    *handler.AdminHandler
+	userHandler     *handler.UserHandler
+	accountHandler  *handler.AccountHandler
+	securityHandler *handler.SecurityHandler
+	webhookHandler  *handler.WebhookHandler
+	scimHandler     *handler.SCIMHandler
+	healthHandler   *handler.HealthHandler
+	openAPISpec     *openapi.Spec
+}
+
+// hook はライフサイクルフックを表す
+type hook struct {
+	name string
+	fn   func(ctx context.Context) error
 }
 
-// NewContainer は新しいコンテナを作成する
-func NewContainer() *Container {
-	return &Container{}
+// NewContainer は読み込み済みの設定から新しいコンテナを作成する
+func NewContainer(cfg *config.Config) *Container {
+	return &Container{
+		config: cfg,
+	}
 }
 
-// GetGoogleService はGoogleServiceの実装を返す
-func (c *Container) GetGoogleService() service.GoogleService {
-	if c.googleService == nil {
-		clientID := os.Getenv("GOOGLE_CLIENT_ID")
-		clientSecret := os.Getenv("GOOGLE_CLIENT_SECRET")
-		c.googleService = external.NewGoogleService(clientID, clientSecret)
+// Config はアプリケーションの設定を返す
+func (c *Container) Config() *config.Config {
+	return c.config
+}
+
+// Build はすべてのハンドラー・ミドルウェアとその依存を構築する
+// 設定の不備や構築に失敗したコンポーネントがあれば、リクエストを受け付ける前にまとめてエラーを返す
+func (c *Container) Build() error {
+	if c.config == nil {
+		return errors.New("registry: config is required")
 	}
-	return c.googleService
+
+	// 他のコンポーネントより先に構築し、停止時は最後にスパンを送信する
+	c.GetTracerProvider()
+	c.GetAuthHandler()
+	c.GetOAuthHandler()
+	c.GetAuditHandler()
+	c.GetAdminHandler()
+	c.GetUserHandler()
+	c.GetAccountHandler()
+	c.GetSecurityHandler()
+	c.GetWebhookHandler()
+	c.GetSCIMHandler()
+	c.GetHealthHandler()
+	c.GetMetrics()
+	c.GetAuthMiddleware()
+	c.GetRoleMiddleware()
+	c.GetRecentAuthMiddleware()
+	c.GetRateLimits()
+	c.GetSCIMAuthMiddleware()
+	c.GetOpenAPISpec()
+	c.GetMigrator()
+	c.GetEventDispatcher()
+
+	return errors.Join(c.errs...)
 }
 
-// GetJWTService はJWTServiceの実装を返す
-func (c *Container) GetJWTService() service.JWTService {
-	if c.jwtService == nil {
-		secretKey := os.Getenv("JWT_SECRET")
-		c.jwtService = external.NewJWTService(secretKey)
+// GetHealthRegistry はヘルスチェックのレジストリを返す
+// インフラコンポーネントは構築時に疎通確認をここへ登録する
+func (c *Container) GetHealthRegistry() *health.Registry {
+	if c.healthRegistry == nil {
+		c.healthRegistry = health.NewRegistry(c.config.Health.CacheTTL, c.config.Health.CheckTimeout)
 	}
-	return c.jwtService
+	return c.healthRegistry
+}
+
+// SetHealthRegistry はテスト用にヘルスチェックのレジストリをセットする
+func (c *Container) SetHealthRegistry(r *health.Registry) {
+	c.healthRegistry = r
 }
 
-// SetGoogleService はテスト用にGoogleServiceをセットする
-func (c *Container) SetGoogleService(gs service.GoogleService) {
-	c.googleService = gs
+// GetMetrics はPrometheusのメトリクスを返す
+// 有効なセッション数は収集のたびにAuthRepositoryから取得する
+func (c *Container) GetMetrics() *metrics.Metrics {
+	if c.metrics == nil {
+		c.metrics = metrics.New()
+		c.metrics.RegisterActiveSessions(func() float64 {
+			count, err := c.GetAuthRepository().CountTokens(context.Background())
+			if err != nil {
+				slog.Warn("failed to count active sessions", slog.Any("error", err))
+				return 0
+			}
+			return float64(count)
+		})
+	}
+	return c.metrics
+}
+
+// SetMetrics はテスト用にメトリクスをセットする
+func (c *Container) SetMetrics(m *metrics.Metrics) {
+	c.metrics = m
+}
+
+// GetTracerProvider はTRACING_EXPORTERに応じたTracerProviderを返す
+// グローバルに設定するため、各パッケージのトレーサーはこのProviderでスパンを作成する
+func (c *Container) GetTracerProvider() trace.TracerProvider {
+	if c.tracerProvider == nil {
+		tracingConfig := c.config.Tracing
+		provider, shutdown, err := tracing.NewTracerProvider(context.Background(), tracing.Options{
+			ServiceName:  tracingConfig.ServiceName,
+			Exporter:     tracingConfig.Exporter,
+			OTLPEndpoint: tracingConfig.OTLPEndpoint,
+			SampleRatio:  tracingConfig.SampleRatio,
+		})
+		if err != nil {
+			c.fail("tracer provider", err)
+			return nil
+		}
+		c.OnStop("tracing", shutdown)
+		tracing.SetGlobal(provider)
+		c.tracerProvider = provider
+	}
+	return c.tracerProvider
+}
+
+// SetTracerProvider はテスト用にTracerProviderをセットする（グローバルには設定しない）
+func (c *Container) SetTracerProvider(provider trace.TracerProvider) {
+	c.tracerProvider = provider
+}
+
+// OnStart は起動時に実行するフックを登録する（DB・Redisの接続確認など）
+func (c *Container) OnStart(name string, fn func(ctx context.Context) error) {
+	c.startHooks = append(c.startHooks, hook{name: name, fn: fn})
+}
+
+// OnStop は停止時に実行するフックを登録する（接続のクローズなど）
+func (c *Container) OnStop(name string, fn func(ctx context.Context) error) {
+	c.stopHooks = append(c.stopHooks, hook{name: name, fn: fn})
+}
+
+// Start は登録順に起動フックを実行する（失敗した時点で中断する）
+func (c *Container) Start(ctx context.Context) error {
+	for _, h := range c.startHooks {
+		if err := h.fn(ctx); err != nil {
+			return fmt.Errorf("registry: failed to start %s: %w", h.name, err)
+		}
+	}
+	return nil
+}
+
+// Stop は登録と逆順に停止フックを実行する（失敗しても残りのフックは実行する）
+func (c *Container) Stop(ctx context.Context) error {
+	var errs []error
+	for i := len(c.stopHooks) - 1; i >= 0; i-- {
+		h := c.stopHooks[i]
+		if err := h.fn(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("registry: failed to stop %s: %w", h.name, err))
+			continue
+		}
+		slog.DebugContext(ctx, "component stopped", slog.String("component", h.name))
+	}
+	return errors.Join(errs...)
 }
 
-// SetJWTService はテスト用にJWTServiceをセットする
-func (c *Container) SetJWTService(js service.JWTService) {
-	c.jwtService = js
+// fail はコンポーネントの構築エラーを記録する
+func (c *Container) fail(name string, err error) {
+	c.errs = append(c.errs, fmt.Errorf("registry: failed to build %s: %w", name, err))
 }
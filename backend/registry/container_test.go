@@ -0,0 +1,870 @@
+package registry
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"image"
+	"image/png"
+	"io"
+	"log/slog"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"stackies-backend/core/config"
+	"stackies-backend/core/health"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/repository"
+	"stackies-backend/infra/external/fakeidp"
+	"stackies-backend/infra/persistence"
+	"stackies-backend/presentation/server"
+	"stackies-backend/usecase"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testConfig はテスト用の有効な設定を返す
+func testConfig() *config.Config {
+	return &config.Config{
+		Environment: config.EnvironmentTest,
+		Server: config.ServerConfig{
+			OpenAPIResponseValidation: "strict",
+		},
+		Auth: config.AuthConfig{
+			JWTSecret:            "test-secret",
+			CallbackMode:         "code",
+			FrontendRedirectURLs: []string{"http://localhost:5173/"},
+			ImpersonationTTL:     15 * time.Minute,
+		},
+		Google: config.GoogleConfig{
+			RedirectURL: "http://localhost:8080/auth/google/callback",
+		},
+		RateLimit: config.RateLimitConfig{
+			Backend: "memory",
+			AuthURL: "token_bucket:30/1m",
+			Login:   "sliding_window:10/1m",
+			Client:  "sliding_window:300/1m",
+			Refresh: "token_bucket:30/1m",
+			User:    "token_bucket:120/1m",
+		},
+		Security: config.SecurityConfig{
+			LoginAlertNotifier: "log",
+			RevokeURL:          "http://localhost:5173/security/not-me",
+		},
+		Health: config.HealthConfig{
+			CheckTimeout: time.Second,
+		},
+		// 画像を保存するテストではt.TempDir()に置き換える
+		Storage: config.StorageConfig{
+			Backend:  "local",
+			LocalDir: filepath.Join(os.TempDir(), "stackies-registry-test"),
+		},
+		Avatar: config.AvatarConfig{
+			MaxUploadBytes: 1 << 20,
+			BaseURL:        "http://localhost:8080",
+		},
+		Account: config.AccountConfig{
+			RecentAuthMaxAge:    10 * time.Minute,
+			DeletionGracePeriod: time.Hour,
+			ExportTTL:           time.Hour,
+		},
+		Webhook: config.WebhookConfig{
+			MaxAttempts: 3,
+		},
+		SCIM: config.SCIMConfig{
+			Token:      "scim-token-for-registry-tests-0123456789",
+			AdminGroup: "Admins",
+		},
+	}
+}
+
+// stubJWTService は常に同じユーザーIDを返すJWTService
+type stubJWTService struct {
+	userID string
+}
+
+func (s *stubJWTService) GenerateToken(userID string) (string, error) {
+	return "access", nil
+}
+
+func (s *stubJWTService) GenerateRefreshToken(userID string) (string, error) {
+	return "refresh", nil
+}
+
+func (s *stubJWTService) ValidateToken(token string) (string, error) {
+	return s.userID, nil
+}
+
+func (s *stubJWTService) GenerateImpersonationToken(userID, actorID string, ttl time.Duration) (string, error) {
+	return "impersonation", nil
+}
+
+func (s *stubJWTService) ParseToken(token string) (*model.TokenClaims, error) {
+	return &model.TokenClaims{UserID: s.userID}, nil
+}
+
+func TestContainer_Build(t *testing.T) {
+	container := NewContainer(testConfig())
+
+	assert.NoError(t, container.Build())
+
+	// 同じインスタンスを返す
+	assert.Same(t, container.GetAuthHandler(), container.GetAuthHandler())
+	assert.Equal(t, container.GetGoogleService(), container.GetGoogleService())
+	assert.NotNil(t, container.GetRateLimits().Login)
+	assert.Nil(t, container.GetGeoIPService())
+}
+
+func TestContainer_BuildErrors(t *testing.T) {
+	tests := []struct {
+		testName string
+		modify   func(cfg *config.Config)
+		wantErr  string
+	}{
+		{
+			testName: "不正なレート制限ルール",
+			modify:   func(cfg *config.Config) { cfg.RateLimit.Login = "unknown" },
+			wantErr:  "RATE_LIMIT_AUTH_LOGIN",
+		},
+		{
+			testName: "存在しないGeoIPデータベース",
+			modify:   func(cfg *config.Config) { cfg.Security.GeoIPDBPath = "/nonexistent/geoip.mmdb" },
+			wantErr:  "geoip service",
+		},
+		{
+			testName: "不正なRedis URL",
+			modify: func(cfg *config.Config) {
+				cfg.RateLimit.Backend = "redis"
+				cfg.RateLimit.RedisURL = "://invalid"
+			},
+			wantErr: "redis client",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			cfg := testConfig()
+			tt.modify(cfg)
+
+			err := NewContainer(cfg).Build()
+
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.wantErr)
+			}
+		})
+	}
+
+	assert.Error(t, NewContainer(nil).Build())
+}
+
+func TestContainer_Override(t *testing.T) {
+	container := NewContainer(testConfig())
+	userRepo := persistence.NewUserRepository()
+	container.SetUserRepository(userRepo)
+	container.SetJWTService(&stubJWTService{userID: "user_1"})
+
+	assert.NoError(t, container.Build())
+	assert.Same(t, userRepo, container.GetUserRepository())
+
+	user, err := model.NewUser("user_1", "test@example.com", "Test User", "")
+	require.NoError(t, err)
+	require.NoError(t, userRepo.Save(context.Background(), user))
+
+	// 差し替えたJWTService・UserRepositoryが依存先のミドルウェアに使われる
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/auth/me", nil)
+	req.Header.Set("Authorization", "Bearer any")
+	c := e.NewContext(req, httptest.NewRecorder())
+
+	var userID string
+	err = container.GetAuthMiddleware().Authenticate(func(c echo.Context) error {
+		userID = c.Get("user_id").(string)
+		return nil
+	})(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "user_1", userID)
+}
+
+func TestContainer_Lifecycle(t *testing.T) {
+	container := NewContainer(testConfig())
+	var calls []string
+	record := func(name string, err error) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			calls = append(calls, name)
+			return err
+		}
+	}
+
+	container.OnStart("db", record("start db", nil))
+	container.OnStart("redis", record("start redis", nil))
+	container.OnStop("db", record("stop db", nil))
+	container.OnStop("redis", record("stop redis", errors.New("close failed")))
+
+	assert.NoError(t, container.Start(context.Background()))
+	err := container.Stop(context.Background())
+
+	// 停止は逆順で、失敗しても残りのフックを実行する
+	assert.Equal(t, []string{"start db", "start redis", "stop redis", "stop db"}, calls)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "redis")
+	}
+}
+
+func TestContainer_StartFailure(t *testing.T) {
+	container := NewContainer(testConfig())
+	var started []string
+	container.OnStart("db", func(ctx context.Context) error { return errors.New("unreachable") })
+	container.OnStart("redis", func(ctx context.Context) error {
+		started = append(started, "redis")
+		return nil
+	})
+
+	err := container.Start(context.Background())
+
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "db")
+	}
+	assert.Empty(t, started)
+}
+
+func TestContainer_Redis(t *testing.T) {
+	server := miniredis.RunT(t)
+	cfg := testConfig()
+	cfg.RateLimit.Backend = "redis"
+	cfg.RateLimit.RedisURL = "redis://" + server.Addr()
+	container := NewContainer(cfg)
+
+	assert.NoError(t, container.Build())
+	assert.NotNil(t, container.GetRedisClient())
+	assert.NoError(t, container.Start(context.Background()))
+	assert.NoError(t, container.Stop(context.Background()))
+
+	// 停止後は接続が閉じられている
+	assert.Error(t, container.GetRedisClient().Ping(context.Background()).Err())
+}
+
+func TestContainer_HealthChecks(t *testing.T) {
+	google := httptest.NewServer(http.NotFoundHandler())
+	defer google.Close()
+	server := miniredis.RunT(t)
+	cfg := testConfig()
+	cfg.RateLimit.Backend = "redis"
+	cfg.RateLimit.RedisURL = "redis://" + server.Addr()
+	cfg.Health.GoogleProbeURL = google.URL
+	container := NewContainer(cfg)
+
+	if !assert.NoError(t, container.Build()) {
+		return
+	}
+	report := container.GetHealthRegistry().Run(context.Background(), health.KindReadiness)
+	assert.Equal(t, health.StatusUp, report.Status)
+	var names []string
+	for _, result := range report.Checks {
+		names = append(names, result.Name)
+	}
+	assert.ElementsMatch(t, []string{"redis", "google"}, names)
+
+	// Redisに到達できなくなったらReadinessは異常になる
+	server.Close()
+	report = container.GetHealthRegistry().Run(context.Background(), health.KindReadiness)
+	assert.Equal(t, health.StatusDown, report.Status)
+	assert.Equal(t, health.StatusUp, container.GetHealthRegistry().Run(context.Background(), health.KindLiveness).Status)
+}
+
+func TestContainer_Metrics(t *testing.T) {
+	container := NewContainer(testConfig())
+	if !assert.NoError(t, container.Build()) {
+		return
+	}
+
+	token := &model.AuthToken{AccessToken: "access_token", ExpiresIn: time.Now().Add(time.Hour).Unix()}
+	assert.NoError(t, container.GetAuthRepository().SaveToken(context.Background(), "user_1", token))
+	assert.NoError(t, container.GetAuditRepository().Append(context.Background(), &model.AuditEvent{
+		Type: model.AuditEventLogout, Outcome: model.AuditOutcomeSuccess,
+	}))
+
+	rec := httptest.NewRecorder()
+	container.GetMetrics().Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Contains(t, rec.Body.String(), "stackies_auth_active_sessions 1")
+	assert.Contains(t, rec.Body.String(), "stackies_auth_logouts_total 1")
+}
+
+func TestContainer_EventDispatcher(t *testing.T) {
+	cfg := testConfig()
+	cfg.Events.DispatchInterval = 10 * time.Millisecond
+	container := NewContainer(cfg)
+	require.NoError(t, container.Build())
+
+	received := make(chan *model.DomainEvent, 10)
+	container.GetEventDispatcher().Subscribe("test", func(ctx context.Context, event *model.DomainEvent) error {
+		received <- event
+		return nil
+	}, model.DomainEventSessionRevoked)
+	require.NoError(t, container.Start(context.Background()))
+
+	// ログアウトで保存したイベントが起動中に配信される
+	require.NoError(t, container.GetAuthUsecase().Logout(context.Background(), &usecase.LogoutInput{UserID: "user_1"}))
+	select {
+	case event := <-received:
+		assert.Equal(t, "user_1", event.UserID)
+		assert.Equal(t, "logout", event.Data["reason"])
+	case <-time.After(5 * time.Second):
+		t.Fatal("domain event was not dispatched")
+	}
+	require.NoError(t, container.Stop(context.Background()))
+
+	pending, err := container.GetOutboxRepository().FetchPending(context.Background(), time.Now(), 10)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+	rec := httptest.NewRecorder()
+	container.GetMetrics().Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Contains(t, rec.Body.String(), `stackies_domain_events_total{type="session.revoked"} 1`)
+}
+
+func TestContainer_Webhooks(t *testing.T) {
+	type request struct {
+		header http.Header
+		body   []byte
+	}
+	received := make(chan request, 10)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- request{header: r.Header, body: body}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	cfg := testConfig()
+	cfg.Events.DispatchInterval = 10 * time.Millisecond
+	cfg.Webhook.DeliveryInterval = 10 * time.Millisecond
+	// 受信側はループバックアドレスのhttpで待ち受ける
+	cfg.Webhook.AllowPrivateTargets = true
+	container := NewContainer(cfg)
+	require.NoError(t, container.Build())
+	require.NoError(t, container.Start(context.Background()))
+	t.Cleanup(func() {
+		assert.NoError(t, container.Stop(context.Background()))
+	})
+
+	ctx := context.Background()
+	subscription, err := container.GetWebhookUsecase().CreateSubscription(ctx, &usecase.CreateWebhookInput{
+		ActorID:    "admin_1",
+		URL:        receiver.URL,
+		EventTypes: []model.DomainEventType{model.DomainEventSessionRevoked},
+	})
+	require.NoError(t, err)
+
+	// ログアウトのイベントが署名付きで購読先に送信される
+	require.NoError(t, container.GetAuthUsecase().Logout(ctx, &usecase.LogoutInput{UserID: "user_1"}))
+	var got request
+	select {
+	case got = <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook was not delivered")
+	}
+	assert.Equal(t, "session.revoked", got.header.Get(model.WebhookEventHeader))
+	assert.Contains(t, string(got.body), `"user_id":"user_1"`)
+
+	signature := got.header.Get(model.WebhookSignatureHeader)
+	timestamp, _, found := strings.Cut(strings.TrimPrefix(signature, "t="), ",")
+	require.True(t, found, signature)
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	require.NoError(t, err)
+	assert.Equal(t, model.SignWebhookPayload(subscription.Secret, time.Unix(unix, 0), got.body), signature)
+
+	// 配信履歴に成功として記録される
+	assert.Eventually(t, func() bool {
+		page, err := container.GetWebhookUsecase().ListDeliveries(ctx, &usecase.ListWebhookDeliveriesInput{SubscriptionID: subscription.ID})
+		return err == nil && len(page.Deliveries) == 1 &&
+			page.Deliveries[0].Status == model.WebhookDeliverySucceeded &&
+			page.Deliveries[0].ID == got.header.Get(model.WebhookDeliveryHeader)
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestContainer_PurgeErasesEventPayloads(t *testing.T) {
+	// 受信側は常に失敗し、配信はデッドレターとして残る
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+
+	cfg := testConfig()
+	cfg.Events.DispatchInterval = 10 * time.Millisecond
+	cfg.Webhook.DeliveryInterval = 10 * time.Millisecond
+	cfg.Webhook.MaxAttempts = 1
+	cfg.Webhook.AllowPrivateTargets = true
+	cfg.Account.DeletionGracePeriod = 0
+	container := NewContainer(cfg)
+	require.NoError(t, container.Build())
+	require.NoError(t, container.Start(context.Background()))
+	t.Cleanup(func() {
+		assert.NoError(t, container.Stop(context.Background()))
+	})
+
+	ctx := context.Background()
+	subscription, err := container.GetWebhookUsecase().CreateSubscription(ctx, &usecase.CreateWebhookInput{
+		ActorID:    "admin_1",
+		URL:        receiver.URL,
+		EventTypes: []model.DomainEventType{model.DomainEventUserRegistered},
+	})
+	require.NoError(t, err)
+
+	erased, err := container.GetSCIMUsecase().CreateUser(ctx, &usecase.SCIMUserInput{UserName: "erased@example.com", DisplayName: "Erased", ClientIP: "192.0.2.10", UserAgent: "scim-client"})
+	require.NoError(t, err)
+	kept, err := container.GetSCIMUsecase().CreateUser(ctx, &usecase.SCIMUserInput{UserName: "kept@example.com", DisplayName: "Kept"})
+	require.NoError(t, err)
+
+	// payloads は購読の配信と配信待ちのイベントに含まれるJSONを返す
+	payloads := func() string {
+		page, err := container.GetWebhookDeliveryRepository().Query(ctx, &model.WebhookDeliveryFilter{SubscriptionID: subscription.ID, Limit: 100})
+		require.NoError(t, err)
+		var all []string
+		for _, delivery := range page.Deliveries {
+			all = append(all, string(delivery.Payload))
+		}
+		pending, err := container.GetOutboxRepository().FetchPending(ctx, time.Now().Add(time.Hour), 100)
+		require.NoError(t, err)
+		for _, message := range pending {
+			payload, err := json.Marshal(message.Event)
+			require.NoError(t, err)
+			all = append(all, string(payload))
+		}
+		return strings.Join(all, "\n")
+	}
+
+	// 登録のイベントはメールアドレスを含み、失敗した配信として残る
+	require.Eventually(t, func() bool {
+		page, err := container.GetWebhookDeliveryRepository().Query(ctx, &model.WebhookDeliveryFilter{
+			SubscriptionID: subscription.ID, Status: model.WebhookDeliveryDead, Limit: 100,
+		})
+		return err == nil && len(page.Deliveries) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Contains(t, payloads(), "erased@example.com")
+
+	_, err = container.GetAccountUsecase().DeleteAccount(ctx, &usecase.DeleteAccountInput{UserID: erased.ID, ClientIP: "192.0.2.10", UserAgent: "browser"})
+	require.NoError(t, err)
+	purged, err := container.GetAccountUsecase().PurgeDeletedAccounts(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, purged)
+
+	// 消去したユーザーのメールアドレスは配信・配信待ちのイベントから取得できない
+	assert.NotContains(t, payloads(), "erased@example.com")
+	assert.Contains(t, payloads(), "kept@example.com")
+	_, err = container.GetUserRepository().FindByID(ctx, kept.ID)
+	assert.NoError(t, err)
+
+	// 仮名化した監査ログにはIPアドレスが残らない
+	page, err := container.GetAuditRepository().Query(ctx, &model.AuditEventFilter{IPAddress: "192.0.2.10", Limit: 100})
+	require.NoError(t, err)
+	assert.Empty(t, page.Events)
+}
+
+func TestContainer_GoogleLoginWithFakeIdP(t *testing.T) {
+	// bobはSCIMで登録してからログインする
+	users := append(fakeidp.DefaultUsers(), fakeidp.User{
+		ID:            "fake-user-3",
+		Email:         "bob@example.com",
+		VerifiedEmail: true,
+		Name:          "Bob Example",
+	})
+	idp, err := fakeidp.New(fakeidp.Options{ClientID: "client", ClientSecret: "secret", Users: users})
+	require.NoError(t, err)
+	idpServer := httptest.NewServer(idp)
+	defer idpServer.Close()
+
+	cfg := testConfig()
+	cfg.Google.ClientID = "client"
+	cfg.Google.ClientSecret = "secret"
+	cfg.Google.EndpointBaseURL = idpServer.URL
+	cfg.Storage.LocalDir = t.TempDir()
+	// 各サブテストでログインを繰り返すため、ログインのレート制限を緩める
+	cfg.RateLimit.Login = "sliding_window:100/1m"
+	container := NewContainer(cfg)
+	require.NoError(t, container.Build())
+	app := server.New(cfg.Server, container, slog.New(slog.DiscardHandler)).Echo()
+	noRedirect := &http.Client{CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+
+	// login は認可URLの取得からコールバックまでを行い、フロントエンドへのリダイレクト先のクエリを返す
+	login := func(t *testing.T, email string) url.Values {
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/auth/google/url?redirect_url="+url.QueryEscape("http://localhost:5173/"), nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+		var authURL struct {
+			AuthURL string `json:"auth_url"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &authURL))
+		require.True(t, strings.HasPrefix(authURL.AuthURL, idpServer.URL+fakeidp.PathAuthorize))
+
+		resp, err := noRedirect.Get(authURL.AuthURL + "&login_hint=" + url.QueryEscape(email))
+		require.NoError(t, err)
+		resp.Body.Close()
+		callback, err := url.Parse(resp.Header.Get("Location"))
+		require.NoError(t, err)
+
+		// フローを開始したブラウザのnonceのCookieをコールバックに送信する
+		req := httptest.NewRequest(http.MethodGet, callback.RequestURI(), nil)
+		for _, cookie := range rec.Result().Cookies() {
+			req.AddCookie(cookie)
+		}
+		rec = httptest.NewRecorder()
+		app.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusFound, rec.Code)
+		redirect, err := url.Parse(rec.Header().Get("Location"))
+		require.NoError(t, err)
+		return redirect.Query()
+	}
+
+	// exchange はログインしてワンタイムコードをトークンと交換する
+	exchange := func(t *testing.T) (string, string) {
+		query := login(t, "alice@example.com")
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/auth/google/exchange", strings.NewReader(`{"code":"`+query.Get("login_code")+`"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		app.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var body struct {
+			User struct {
+				Name string `json:"name"`
+			} `json:"user"`
+			AccessToken string `json:"accessToken"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		return body.User.Name, body.AccessToken
+	}
+
+	t.Run("認証済みのユーザーはログインできる", func(t *testing.T) {
+		query := login(t, "alice@example.com")
+		require.NotEmpty(t, query.Get("login_code"))
+
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/auth/google/exchange", strings.NewReader(`{"code":"`+query.Get("login_code")+`"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		app.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"email":"alice@example.com"`)
+		assert.Contains(t, rec.Body.String(), `"accessToken"`)
+	})
+
+	t.Run("別のブラウザで開いたコールバックは受け付けない", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/auth/google/url?redirect=true", nil))
+		require.Equal(t, http.StatusFound, rec.Code)
+
+		resp, err := noRedirect.Get(rec.Header().Get("Location") + "&login_hint=" + url.QueryEscape("alice@example.com"))
+		require.NoError(t, err)
+		resp.Body.Close()
+		callback, err := url.Parse(resp.Header.Get("Location"))
+		require.NoError(t, err)
+
+		// フローを開始したブラウザのnonceのCookieを持たない
+		rec = httptest.NewRecorder()
+		app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, callback.RequestURI(), nil))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "invalid_state")
+	})
+
+	t.Run("編集した表示名は再ログインで上書きされない", func(t *testing.T) {
+		_, accessToken := exchange(t)
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPatch, "/users/me", strings.NewReader(`{"name":"Alice Edited"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+accessToken)
+		app.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		name, _ := exchange(t)
+		assert.Equal(t, "Alice Edited", name)
+	})
+
+	t.Run("アップロードした画像を取得できる", func(t *testing.T) {
+		_, accessToken := exchange(t)
+
+		var img bytes.Buffer
+		require.NoError(t, png.Encode(&img, image.NewNRGBA(image.Rect(0, 0, 80, 60))))
+		var form bytes.Buffer
+		writer := multipart.NewWriter(&form)
+		part, err := writer.CreateFormFile("file", "avatar.png")
+		require.NoError(t, err)
+		_, err = part.Write(img.Bytes())
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/users/me/avatar", &form)
+		req.Header.Set(echo.HeaderContentType, writer.FormDataContentType())
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+accessToken)
+		app.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var user struct {
+			Picture string `json:"picture"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &user))
+		picture, err := url.Parse(user.Picture)
+		require.NoError(t, err)
+
+		rec = httptest.NewRecorder()
+		app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, picture.RequestURI()+"&size=64", nil))
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Equal(t, "image/png", rec.Header().Get(echo.HeaderContentType))
+		decoded, err := png.Decode(rec.Body)
+		require.NoError(t, err)
+		assert.Equal(t, image.Rect(0, 0, 64, 64), decoded.Bounds())
+	})
+
+	t.Run("メールアドレスが未認証のユーザーはログインできない", func(t *testing.T) {
+		query := login(t, "unverified@example.com")
+
+		assert.Equal(t, "login_failed", query.Get("error"))
+		assert.Empty(t, query.Get("login_code"))
+	})
+
+	t.Run("一時停止したユーザーは再有効化するまで利用できない", func(t *testing.T) {
+		_, accessToken := exchange(t)
+		me := func() *httptest.ResponseRecorder {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/auth/me", nil)
+			req.Header.Set(echo.HeaderAuthorization, "Bearer "+accessToken)
+			app.ServeHTTP(rec, req)
+			return rec
+		}
+		require.Equal(t, http.StatusOK, me().Code)
+
+		alice, err := container.GetUserRepository().FindByEmail(context.Background(), "alice@example.com")
+		require.NoError(t, err)
+		changeStatus := func(status model.UserStatus, reason string) {
+			_, err := container.GetAdminUsecase().ChangeStatus(context.Background(), &usecase.ChangeStatusInput{
+				ActorID: "admin_1",
+				UserID:  alice.ID,
+				Status:  status,
+				Reason:  reason,
+			})
+			require.NoError(t, err)
+		}
+
+		// 有効期限内のアクセストークンにも即時に反映される
+		changeStatus(model.UserStatusSuspended, "spam")
+		rec := me()
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"code":"user_suspended"`)
+		assert.Equal(t, "login_failed", login(t, "alice@example.com").Get("error"))
+
+		changeStatus(model.UserStatusActive, "")
+		_, accessToken = exchange(t)
+		assert.Equal(t, http.StatusOK, me().Code)
+	})
+
+	t.Run("管理者はユーザーになりすまし、操作は監査ログに記録される", func(t *testing.T) {
+		exchange(t)
+		admin, err := model.NewUser("admin_impersonator", "admin@example.com", "Admin", "")
+		require.NoError(t, err)
+		require.NoError(t, admin.ChangeRole(model.RoleAdmin))
+		require.NoError(t, container.GetUserRepository().Save(context.Background(), admin))
+		alice, err := container.GetUserRepository().FindByEmail(context.Background(), "alice@example.com")
+		require.NoError(t, err)
+
+		output, err := container.GetAdminUsecase().Impersonate(context.Background(), &usecase.ImpersonateInput{
+			ActorID: admin.ID,
+			UserID:  alice.ID,
+			Reason:  "ticket #42",
+		})
+		require.NoError(t, err)
+		request := func(method, path string) *httptest.ResponseRecorder {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(method, path, nil)
+			req.Header.Set(echo.HeaderAuthorization, "Bearer "+output.AccessToken)
+			app.ServeHTTP(rec, req)
+			return rec
+		}
+
+		rec := request(http.MethodGet, "/auth/me")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Contains(t, rec.Body.String(), `"email":"alice@example.com"`)
+		rec = request(http.MethodDelete, "/users/me")
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"code":"impersonation_not_allowed"`)
+
+		page, err := container.GetAuditRepository().Query(context.Background(), &model.AuditEventFilter{
+			Type:    model.AuditEventImpersonatedRequest,
+			ActorID: admin.ID,
+		})
+		require.NoError(t, err)
+		require.Len(t, page.Events, 2)
+		for _, event := range page.Events {
+			assert.Equal(t, alice.ID, event.SubjectID)
+		}
+	})
+
+	t.Run("管理者はWebhookを購読し、配信履歴を確認できる", func(t *testing.T) {
+		admin, err := model.NewUser("admin_webhooks", "webhooks@example.com", "Admin", "")
+		require.NoError(t, err)
+		require.NoError(t, admin.ChangeRole(model.RoleAdmin))
+		require.NoError(t, container.GetUserRepository().Save(context.Background(), admin))
+		accessToken, err := container.GetJWTService().GenerateToken(admin.ID)
+		require.NoError(t, err)
+		request := func(method, path, body string) *httptest.ResponseRecorder {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(method, path, strings.NewReader(body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(echo.HeaderAuthorization, "Bearer "+accessToken)
+			app.ServeHTTP(rec, req)
+			return rec
+		}
+
+		rec := request(http.MethodPost, "/admin/webhooks", `{"url":"https://example.com/hooks","event_types":["user.registered","session.revoked"]}`)
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+		var created struct {
+			ID     string `json:"id"`
+			Secret string `json:"secret"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+		assert.NotEmpty(t, created.Secret)
+
+		// シークレットは作成時のみ返す
+		rec = request(http.MethodPatch, "/admin/webhooks/"+created.ID, `{"active":false}`)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Contains(t, rec.Body.String(), `"active":false`)
+		assert.NotContains(t, rec.Body.String(), created.Secret)
+		rec = request(http.MethodGet, "/admin/webhooks", "")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Contains(t, rec.Body.String(), created.ID)
+
+		rec = request(http.MethodGet, "/admin/webhooks/"+created.ID+"/deliveries?status=dead", "")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Contains(t, rec.Body.String(), `"deliveries":[]`)
+		rec = request(http.MethodPost, "/admin/webhooks/"+created.ID+"/deliveries/unknown/replay", "")
+		assert.Equal(t, http.StatusConflict, rec.Code, rec.Body.String())
+
+		rec = request(http.MethodDelete, "/admin/webhooks/"+created.ID, "")
+		assert.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+		rec = request(http.MethodGet, "/admin/webhooks/"+created.ID, "")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("SCIMで登録したユーザーはログインで有効になり、無効化するとセッションが無効になる", func(t *testing.T) {
+		request := func(method, path, body string) *httptest.ResponseRecorder {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(method, path, strings.NewReader(body))
+			req.Header.Set(echo.HeaderContentType, "application/scim+json")
+			req.Header.Set(echo.HeaderAuthorization, "Bearer "+cfg.SCIM.Token)
+			app.ServeHTTP(rec, req)
+			return rec
+		}
+
+		rec := request(http.MethodPost, "/scim/v2/Users", `{"schemas":["urn:ietf:params:scim:schemas:core:2.0:User"],"userName":"Bob@Example.com","externalId":"hr-42","name":{"givenName":"Bob","familyName":"Example"}}`)
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+		var created struct {
+			ID string `json:"id"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+		rec = request(http.MethodGet, "/scim/v2/Users?filter="+url.QueryEscape(`externalId eq "hr-42"`), "")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Contains(t, rec.Body.String(), `"totalResults":1`)
+		rec = request(http.MethodPost, "/scim/v2/Users", `{"userName":"bob@example.com"}`)
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"scimType":"uniqueness"`)
+
+		// 本人が認証済みのメールアドレスでログインした時点で有効になる（メールアドレスの大文字・小文字は区別しない）
+		bob, err := container.GetUserRepository().FindByID(context.Background(), created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, model.UserStatusPendingVerification, bob.Status)
+		require.NotEmpty(t, login(t, "bob@example.com").Get("login_code"))
+		_, err = container.GetAuthRepository().GetToken(context.Background(), created.ID)
+		require.NoError(t, err)
+
+		// 管理者のグループのメンバーには管理者のロールを付与する
+		rec = request(http.MethodPost, "/scim/v2/Groups", `{"displayName":"Admins","members":[{"value":"`+created.ID+`"}]}`)
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+		bob, err = container.GetUserRepository().FindByID(context.Background(), created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, model.UserStatusActive, bob.Status)
+		assert.Equal(t, model.RoleAdmin, bob.Role)
+		users, err := container.GetUserRepository().FindAll(context.Background())
+		require.NoError(t, err)
+		var bobs int
+		for _, user := range users {
+			if strings.EqualFold(user.Email, "bob@example.com") {
+				bobs++
+			}
+		}
+		assert.Equal(t, 1, bobs)
+
+		rec = request(http.MethodPatch, "/scim/v2/Users/"+created.ID, `{"schemas":["urn:ietf:params:scim:api:messages:2.0:PatchOp"],"Operations":[{"op":"replace","value":{"active":false}}]}`)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Contains(t, rec.Body.String(), `"active":false`)
+		bob, err = container.GetUserRepository().FindByID(context.Background(), created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, model.UserStatusDisabled, bob.Status)
+		_, err = container.GetAuthRepository().GetToken(context.Background(), created.ID)
+		assert.ErrorIs(t, err, repository.ErrTokenNotFound)
+		assert.Equal(t, "login_failed", login(t, "bob@example.com").Get("error"))
+
+		// DELETEしたユーザーは削除せずに無効化したまま返す
+		rec = request(http.MethodDelete, "/scim/v2/Users/"+created.ID, "")
+		assert.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+		rec = request(http.MethodGet, "/scim/v2/Users/"+created.ID, "")
+		assert.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Contains(t, rec.Body.String(), `"active":false`)
+
+		page, err := container.GetAuditRepository().Query(context.Background(), &model.AuditEventFilter{
+			Type:   model.AuditEventSCIMProvision,
+			UserID: created.ID,
+		})
+		require.NoError(t, err)
+		assert.NotEmpty(t, page.Events)
+	})
+
+	// 以降はaliceを削除するため最後に実行する
+	t.Run("データをエクスポートしてアカウントを削除できる", func(t *testing.T) {
+		_, accessToken := exchange(t)
+		request := func(method, path string) *httptest.ResponseRecorder {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(method, path, nil)
+			req.Header.Set(echo.HeaderAuthorization, "Bearer "+accessToken)
+			app.ServeHTTP(rec, req)
+			return rec
+		}
+
+		// 作成が完了するまで同じURLを確認する
+		var rec *httptest.ResponseRecorder
+		require.Eventually(t, func() bool {
+			rec = request(http.MethodGet, "/users/me/export")
+			return rec.Code != http.StatusAccepted
+		}, 5*time.Second, 10*time.Millisecond)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		archive, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+		require.NoError(t, err)
+		var names []string
+		for _, file := range archive.File {
+			names = append(names, file.Name)
+		}
+		assert.Contains(t, names, "user.json")
+		assert.Contains(t, names, "audit_events.json")
+
+		rec = request(http.MethodDelete, "/users/me")
+		require.Equal(t, http.StatusAccepted, rec.Code, rec.Body.String())
+		assert.Contains(t, rec.Body.String(), `"purge_at"`)
+
+		// セッションは削除され、消去されるまで再ログインできない
+		assert.Equal(t, http.StatusForbidden, request(http.MethodDelete, "/users/me").Code)
+		query := login(t, "alice@example.com")
+		assert.Equal(t, "login_failed", query.Get("error"))
+	})
+}
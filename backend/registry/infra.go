@@ -0,0 +1,435 @@
+package registry
+
+import (
+	"context"
+	"io"
+	"stackies-backend/core/health"
+	"stackies-backend/domain/repository"
+	"stackies-backend/domain/service"
+	"stackies-backend/infra/external"
+	"stackies-backend/infra/external/fakeidp"
+	"stackies-backend/infra/persistence"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// GetRedisClient はRedisクライアントを返す（REDIS_URLが未設定の場合はnil）
+// 起動時とReadinessのヘルスチェックで疎通を確認し、停止時に接続を閉じる
+func (c *Container) GetRedisClient() *redis.Client {
+	if c.redisClient == nil && c.config.RateLimit.RedisURL != "" {
+		options, err := redis.ParseURL(c.config.RateLimit.RedisURL)
+		if err != nil {
+			c.fail("redis client", err)
+			return nil
+		}
+		client := redis.NewClient(options)
+		c.OnStart("redis", func(ctx context.Context) error {
+			return client.Ping(ctx).Err()
+		})
+		c.OnStop("redis", func(ctx context.Context) error {
+			return client.Close()
+		})
+		c.GetHealthRegistry().Register("redis", health.KindReadiness, func(ctx context.Context) error {
+			return client.Ping(ctx).Err()
+		})
+		c.redisClient = client
+	}
+	return c.redisClient
+}
+
+// SetRedisClient はテスト用にRedisクライアントをセットする
+func (c *Container) SetRedisClient(client *redis.Client) {
+	c.redisClient = client
+}
+
+// GetUserRepository はUserRepositoryの実装を返す
+func (c *Container) GetUserRepository() repository.UserRepository {
+	if c.userRepository == nil {
+		c.userRepository = persistence.NewUserRepository()
+	}
+	return c.userRepository
+}
+
+// SetUserRepository はテスト用にUserRepositoryをセットする
+func (c *Container) SetUserRepository(r repository.UserRepository) {
+	c.userRepository = r
+}
+
+// GetAuthRepository はAuthRepositoryの実装を返す
+func (c *Container) GetAuthRepository() repository.AuthRepository {
+	if c.authRepository == nil {
+		c.authRepository = persistence.NewAuthRepository()
+	}
+	return c.authRepository
+}
+
+// SetAuthRepository はテスト用にAuthRepositoryをセットする
+func (c *Container) SetAuthRepository(r repository.AuthRepository) {
+	c.authRepository = r
+}
+
+// GetAuditRepository はAuditRepositoryの実装を返す
+// 追記される監査イベントからログイン・リフレッシュ・ログアウトなどのメトリクスを記録する
+func (c *Container) GetAuditRepository() repository.AuditRepository {
+	if c.auditRepository == nil {
+		c.auditRepository = persistence.NewMetricsAuditRepository(persistence.NewAuditRepository(), c.GetMetrics())
+	}
+	return c.auditRepository
+}
+
+// SetAuditRepository はテスト用にAuditRepositoryをセットする
+func (c *Container) SetAuditRepository(r repository.AuditRepository) {
+	c.auditRepository = r
+}
+
+// GetTransactor はTransactorの実装を返す
+func (c *Container) GetTransactor() repository.Transactor {
+	if c.transactor == nil {
+		c.transactor = persistence.NewTransactor()
+	}
+	return c.transactor
+}
+
+// SetTransactor はテスト用にTransactorをセットする
+func (c *Container) SetTransactor(t repository.Transactor) {
+	c.transactor = t
+}
+
+// GetOutboxRepository はOutboxRepositoryの実装を返す
+func (c *Container) GetOutboxRepository() repository.OutboxRepository {
+	if c.outboxRepository == nil {
+		c.outboxRepository = persistence.NewOutboxRepository()
+	}
+	return c.outboxRepository
+}
+
+// SetOutboxRepository はテスト用にOutboxRepositoryをセットする
+func (c *Container) SetOutboxRepository(r repository.OutboxRepository) {
+	c.outboxRepository = r
+}
+
+// GetWebhookRepository はWebhookRepositoryの実装を返す
+func (c *Container) GetWebhookRepository() repository.WebhookRepository {
+	if c.webhookRepository == nil {
+		c.webhookRepository = persistence.NewWebhookRepository()
+	}
+	return c.webhookRepository
+}
+
+// SetWebhookRepository はテスト用にWebhookRepositoryをセットする
+func (c *Container) SetWebhookRepository(r repository.WebhookRepository) {
+	c.webhookRepository = r
+}
+
+// GetWebhookDeliveryRepository はWebhookDeliveryRepositoryの実装を返す
+func (c *Container) GetWebhookDeliveryRepository() repository.WebhookDeliveryRepository {
+	if c.webhookDeliveryRepository == nil {
+		c.webhookDeliveryRepository = persistence.NewWebhookDeliveryRepository()
+	}
+	return c.webhookDeliveryRepository
+}
+
+// SetWebhookDeliveryRepository はテスト用にWebhookDeliveryRepositoryをセットする
+func (c *Container) SetWebhookDeliveryRepository(r repository.WebhookDeliveryRepository) {
+	c.webhookDeliveryRepository = r
+}
+
+// GetGroupRepository はGroupRepositoryの実装を返す
+func (c *Container) GetGroupRepository() repository.GroupRepository {
+	if c.groupRepository == nil {
+		c.groupRepository = persistence.NewGroupRepository()
+	}
+	return c.groupRepository
+}
+
+// SetGroupRepository はテスト用にGroupRepositoryをセットする
+func (c *Container) SetGroupRepository(r repository.GroupRepository) {
+	c.groupRepository = r
+}
+
+// GetOAuthRepository はOAuthRepositoryの実装を返す
+func (c *Container) GetOAuthRepository() repository.OAuthRepository {
+	if c.oauthRepository == nil {
+		c.oauthRepository = persistence.NewOAuthRepository()
+	}
+	return c.oauthRepository
+}
+
+// SetOAuthRepository はテスト用にOAuthRepositoryをセットする
+func (c *Container) SetOAuthRepository(r repository.OAuthRepository) {
+	c.oauthRepository = r
+}
+
+// GetRevocationTokenRepository はRevocationTokenRepositoryの実装を返す
+func (c *Container) GetRevocationTokenRepository() repository.RevocationTokenRepository {
+	if c.revocationTokenRepository == nil {
+		c.revocationTokenRepository = persistence.NewRevocationTokenRepository()
+	}
+	return c.revocationTokenRepository
+}
+
+// SetRevocationTokenRepository はテスト用にRevocationTokenRepositoryをセットする
+func (c *Container) SetRevocationTokenRepository(r repository.RevocationTokenRepository) {
+	c.revocationTokenRepository = r
+}
+
+// GetDataExportRepository はDataExportRepositoryの実装を返す
+func (c *Container) GetDataExportRepository() repository.DataExportRepository {
+	if c.dataExportRepository == nil {
+		c.dataExportRepository = persistence.NewDataExportRepository()
+	}
+	return c.dataExportRepository
+}
+
+// SetDataExportRepository はテスト用にDataExportRepositoryをセットする
+func (c *Container) SetDataExportRepository(r repository.DataExportRepository) {
+	c.dataExportRepository = r
+}
+
+// GetMigrator はデータストアのマイグレーションを実行するMigratorを返す
+func (c *Container) GetMigrator() service.Migrator {
+	if c.migrator == nil {
+		migrator, err := persistence.NewMigrator(persistence.Migrations())
+		if err != nil {
+			c.fail("migrator", err)
+			return nil
+		}
+		c.migrator = migrator
+	}
+	return c.migrator
+}
+
+// SetMigrator はテスト用にMigratorをセットする
+func (c *Container) SetMigrator(m service.Migrator) {
+	c.migrator = m
+}
+
+// HasSharedStorage はリポジトリが稼働中のサーバーと同じデータストアを使うかどうかを返す
+// 現在のリポジトリはメモリ上の実装で、プロセスごとに別のデータを持つためfalseを返す
+// TODO: データベース統合時にtrueを返す
+func (c *Container) HasSharedStorage() bool {
+	return false
+}
+
+// GetHTTPClientFactory はGoogleなど外部サービス向けのHTTPクライアントを作成するファクトリーを返す
+// 停止時にプール中の接続を閉じる
+func (c *Container) GetHTTPClientFactory() *external.HTTPClientFactory {
+	if c.httpClientFactory == nil {
+		factory := external.NewHTTPClientFactory(c.outboundClientOptions())
+		c.OnStop("http clients", func(ctx context.Context) error {
+			factory.CloseIdleConnections()
+			return nil
+		})
+		c.httpClientFactory = factory
+	}
+	return c.httpClientFactory
+}
+
+// outboundClientOptions はOUTBOUND_*の設定から外部サービス向けHTTPクライアントの設定を作成する
+func (c *Container) outboundClientOptions() external.HTTPClientOptions {
+	outbound := c.config.Outbound
+	return external.HTTPClientOptions{
+		Timeout:               outbound.Timeout,
+		DialTimeout:           outbound.DialTimeout,
+		TLSHandshakeTimeout:   outbound.DialTimeout,
+		ResponseHeaderTimeout: outbound.ResponseHeaderTimeout,
+		MaxIdleConnsPerHost:   outbound.MaxIdleConnsPerHost,
+		IdleConnTimeout:       outbound.IdleConnTimeout,
+		MaxRetries:            outbound.MaxRetries,
+		RetryBaseDelay:        outbound.RetryBaseDelay,
+		RetryMaxDelay:         outbound.RetryMaxDelay,
+		BreakerThreshold:      outbound.BreakerThreshold,
+		BreakerCooldown:       outbound.BreakerCooldown,
+	}
+}
+
+// SetHTTPClientFactory はテスト用にHTTPClientFactoryをセットする
+func (c *Container) SetHTTPClientFactory(f *external.HTTPClientFactory) {
+	c.httpClientFactory = f
+}
+
+// GetGoogleService はGoogleServiceの実装を返す
+// GOOGLE_ENDPOINT_BASE_URLが設定されている場合はGoogleの代わりにfake IdPを使用する
+// HEALTH_GOOGLE_PROBE_URLが設定されている場合はGoogleへの疎通をReadinessのヘルスチェックに登録する
+func (c *Container) GetGoogleService() service.GoogleService {
+	if c.googleService == nil {
+		google := c.config.Google
+		endpoints := external.DefaultGoogleEndpoints()
+		url := c.config.Health.GoogleProbeURL
+		if base := strings.TrimSuffix(google.EndpointBaseURL, "/"); base != "" {
+			endpoints = external.GoogleEndpoints{
+				AuthURL:     base + fakeidp.PathAuthorize,
+				TokenURL:    base + fakeidp.PathToken,
+				UserInfoURL: base + fakeidp.PathUserInfo,
+			}
+			url = base + fakeidp.PathDiscovery
+		}
+		c.googleService = external.NewGoogleService(
+			google.ClientID, google.ClientSecret, google.RedirectURL,
+			endpoints, c.GetHTTPClientFactory().Client("google"), c.GetMetrics(),
+		)
+		if url != "" {
+			c.GetHealthRegistry().Register("google", health.KindReadiness, external.NewHTTPProbe(c.GetHTTPClientFactory().Client("google_health"), url))
+		}
+	}
+	return c.googleService
+}
+
+// SetGoogleService はテスト用にGoogleServiceをセットする
+func (c *Container) SetGoogleService(gs service.GoogleService) {
+	c.googleService = gs
+}
+
+// GetJWTService はJWTServiceの実装を返す
+func (c *Container) GetJWTService() service.JWTService {
+	if c.jwtService == nil {
+		c.jwtService = external.NewJWTService(c.config.Auth.JWTSecret, c.config.Auth.JWTPreviousSecrets...)
+	}
+	return c.jwtService
+}
+
+// SetJWTService はテスト用にJWTServiceをセットする
+func (c *Container) SetJWTService(js service.JWTService) {
+	c.jwtService = js
+}
+
+// GetRateLimiter は設定で指定されたバックエンドのRateLimiterを返す
+func (c *Container) GetRateLimiter() service.RateLimiter {
+	if c.rateLimiter == nil {
+		if c.config.RateLimit.Backend == "redis" {
+			if client := c.GetRedisClient(); client != nil {
+				c.rateLimiter = external.NewRedisRateLimiter(client)
+			}
+		} else {
+			c.rateLimiter = external.NewMemoryRateLimiter()
+		}
+	}
+	return c.rateLimiter
+}
+
+// SetRateLimiter はテスト用にRateLimiterをセットする
+func (c *Container) SetRateLimiter(rl service.RateLimiter) {
+	c.rateLimiter = rl
+}
+
+// GetGeoIPService はGeoIPServiceの実装を返す（GEOIP_DB_PATHが未設定の場合はnilで、不可能な移動の判定を行わない）
+func (c *Container) GetGeoIPService() service.GeoIPService {
+	if !c.geoIPResolved {
+		c.geoIPResolved = true
+		if path := c.config.Security.GeoIPDBPath; path != "" {
+			geoIP, err := external.NewGeoIPService(path)
+			if err != nil {
+				c.fail("geoip service", err)
+				return nil
+			}
+			if closer, ok := geoIP.(io.Closer); ok {
+				c.OnStop("geoip", func(ctx context.Context) error {
+					return closer.Close()
+				})
+			}
+			c.geoIPService = geoIP
+		}
+	}
+	return c.geoIPService
+}
+
+// SetGeoIPService はテスト用にGeoIPServiceをセットする
+func (c *Container) SetGeoIPService(gs service.GeoIPService) {
+	c.geoIPService = gs
+	c.geoIPResolved = true
+}
+
+// GetLoginAlertNotifier は設定で指定された方式のLoginAlertNotifierを返す
+func (c *Container) GetLoginAlertNotifier() service.LoginAlertNotifier {
+	if c.loginAlertNotifier == nil {
+		if c.config.Security.LoginAlertNotifier == "smtp" {
+			smtp := c.config.SMTP
+			c.loginAlertNotifier = external.NewSMTPLoginAlertNotifier(smtp.Addr, smtp.From, smtp.Username, smtp.Password)
+		} else {
+			c.loginAlertNotifier = external.NewLogLoginAlertNotifier()
+		}
+	}
+	return c.loginAlertNotifier
+}
+
+// SetLoginAlertNotifier はテスト用にLoginAlertNotifierをセットする
+func (c *Container) SetLoginAlertNotifier(n service.LoginAlertNotifier) {
+	c.loginAlertNotifier = n
+}
+
+// GetWebhookSender はWebhookSenderの実装を返す
+// 送信先は管理者が指定する任意のURLのため、Googleとは別の接続プールを使い、
+// WEBHOOK_ALLOW_PRIVATE_TARGETSが無効な場合は内部ネットワークのアドレスへの接続を拒否する
+// サーキットブレーカーは購読ごとに持つ
+func (c *Container) GetWebhookSender() service.WebhookSender {
+	if c.webhookSender == nil {
+		options := c.outboundClientOptions()
+		if !c.config.Webhook.AllowPrivateTargets {
+			options.DialControl = external.WebhookDialControl
+		}
+		factory := external.NewHTTPClientFactory(options)
+		c.OnStop("webhook http clients", func(ctx context.Context) error {
+			factory.CloseIdleConnections()
+			return nil
+		})
+		c.webhookSender = external.NewHTTPWebhookSender(factory, c.GetMetrics())
+	}
+	return c.webhookSender
+}
+
+// SetWebhookSender はテスト用にWebhookSenderをセットする
+func (c *Container) SetWebhookSender(s service.WebhookSender) {
+	c.webhookSender = s
+}
+
+// GetBlobStore は設定で指定された保存先のBlobStoreを返す
+// s3の場合はGoogleと同じく再試行・サーキットブレーカーを適用したクライアントで接続する
+func (c *Container) GetBlobStore() service.BlobStore {
+	if c.blobStore == nil {
+		storage := c.config.Storage
+		var (
+			store service.BlobStore
+			err   error
+		)
+		if storage.Backend == "s3" {
+			store, err = external.NewS3BlobStore(external.S3BlobStoreOptions{
+				Endpoint:        storage.S3Endpoint,
+				Region:          storage.S3Region,
+				Bucket:          storage.S3Bucket,
+				AccessKeyID:     storage.S3AccessKeyID,
+				SecretAccessKey: storage.S3SecretAccessKey,
+				PathStyle:       storage.S3PathStyle,
+				PublicBaseURL:   storage.S3PublicBaseURL,
+				URLExpiry:       storage.URLExpiry,
+				Transport:       c.GetHTTPClientFactory().Client("s3").Transport,
+			})
+		} else {
+			store, err = external.NewLocalBlobStore(storage.LocalDir)
+		}
+		if err != nil {
+			c.fail("blob store", err)
+			return nil
+		}
+		c.blobStore = store
+	}
+	return c.blobStore
+}
+
+// SetBlobStore はテスト用にBlobStoreをセットする
+func (c *Container) SetBlobStore(s service.BlobStore) {
+	c.blobStore = s
+}
+
+// GetImageProcessor はImageProcessorの実装を返す
+func (c *Container) GetImageProcessor() service.ImageProcessor {
+	if c.imageProcessor == nil {
+		c.imageProcessor = external.NewImageProcessor()
+	}
+	return c.imageProcessor
+}
+
+// SetImageProcessor はテスト用にImageProcessorをセットする
+func (c *Container) SetImageProcessor(p service.ImageProcessor) {
+	c.imageProcessor = p
+}
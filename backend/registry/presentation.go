@@ -0,0 +1,273 @@
+package registry
+
+import (
+	"errors"
+	"stackies-backend/domain/model"
+	"stackies-backend/presentation/handler"
+	"stackies-backend/presentation/middleware"
+	"stackies-backend/presentation/openapi"
+)
+
+// GetAuthMiddleware はAuthMiddlewareを返す
+func (c *Container) GetAuthMiddleware() *middleware.AuthMiddleware {
+	if c.authMiddleware == nil {
+		c.authMiddleware = middleware.NewAuthMiddleware(c.GetJWTService(), c.GetUserRepository(), c.GetAuditRepository())
+	}
+	return c.authMiddleware
+}
+
+// SetAuthMiddleware はテスト用にAuthMiddlewareをセットする
+func (c *Container) SetAuthMiddleware(m *middleware.AuthMiddleware) {
+	c.authMiddleware = m
+}
+
+// GetRoleMiddleware はRoleMiddlewareを返す
+func (c *Container) GetRoleMiddleware() *middleware.RoleMiddleware {
+	if c.roleMiddleware == nil {
+		c.roleMiddleware = middleware.NewRoleMiddleware(c.GetUserRepository())
+	}
+	return c.roleMiddleware
+}
+
+// SetRoleMiddleware はテスト用にRoleMiddlewareをセットする
+func (c *Container) SetRoleMiddleware(m *middleware.RoleMiddleware) {
+	c.roleMiddleware = m
+}
+
+// GetRecentAuthMiddleware はRecentAuthMiddlewareを返す
+func (c *Container) GetRecentAuthMiddleware() *middleware.RecentAuthMiddleware {
+	if c.recentAuthMiddleware == nil {
+		c.recentAuthMiddleware = middleware.NewRecentAuthMiddleware(c.GetAuthRepository(), c.config.Account.RecentAuthMaxAge)
+	}
+	return c.recentAuthMiddleware
+}
+
+// SetRecentAuthMiddleware はテスト用にRecentAuthMiddlewareをセットする
+func (c *Container) SetRecentAuthMiddleware(m *middleware.RecentAuthMiddleware) {
+	c.recentAuthMiddleware = m
+}
+
+// GetSCIMAuthMiddleware はSCIMAuthMiddlewareを返す（SCIM_TOKENが空の場合はSCIMのエンドポイントを無効にする）
+func (c *Container) GetSCIMAuthMiddleware() *middleware.SCIMAuthMiddleware {
+	if c.scimAuthMiddleware == nil {
+		c.scimAuthMiddleware = middleware.NewSCIMAuthMiddleware(c.config.SCIM.Token)
+	}
+	return c.scimAuthMiddleware
+}
+
+// SetSCIMAuthMiddleware はテスト用にSCIMAuthMiddlewareをセットする
+func (c *Container) SetSCIMAuthMiddleware(m *middleware.SCIMAuthMiddleware) {
+	c.scimAuthMiddleware = m
+}
+
+// GetRateLimitMiddleware はRateLimitMiddlewareを返す
+func (c *Container) GetRateLimitMiddleware() *middleware.RateLimitMiddleware {
+	if c.rateLimitMiddleware == nil {
+		limiter := c.GetRateLimiter()
+		if limiter == nil {
+			c.fail("rate limit middleware", errors.New("rate limiter is not available"))
+			return nil
+		}
+		c.rateLimitMiddleware = middleware.NewRateLimitMiddleware(limiter)
+	}
+	return c.rateLimitMiddleware
+}
+
+// SetRateLimitMiddleware はテスト用にRateLimitMiddlewareをセットする
+func (c *Container) SetRateLimitMiddleware(m *middleware.RateLimitMiddleware) {
+	c.rateLimitMiddleware = m
+}
+
+// GetRateLimits は設定のルールからエンドポイントごとのレート制限ミドルウェアを返す
+func (c *Container) GetRateLimits() *middleware.RateLimits {
+	if c.rateLimits == nil {
+		rateLimit := c.GetRateLimitMiddleware()
+		if rateLimit == nil {
+			return nil
+		}
+
+		rules := c.config.RateLimit
+		byIP := []middleware.RateLimitKey{middleware.RateLimitKeyIP}
+		byClient := []middleware.RateLimitKey{middleware.RateLimitKeyClient}
+		byUser := []middleware.RateLimitKey{middleware.RateLimitKeyUser}
+		policy := func(name, value string, keyBy []middleware.RateLimitKey) middleware.RateLimitPolicy {
+			rule, err := model.ParseRateLimitRule(value)
+			if err != nil {
+				c.fail(name, err)
+				return middleware.RateLimitPolicy{KeyBy: keyBy}
+			}
+			return middleware.RateLimitPolicy{Rule: *rule, KeyBy: keyBy}
+		}
+
+		c.rateLimits = &middleware.RateLimits{
+			AuthURL: rateLimit.Limit("auth_url",
+				policy("RATE_LIMIT_AUTH_URL", rules.AuthURL, byIP),
+			),
+			Login: rateLimit.Limit("auth_login",
+				policy("RATE_LIMIT_AUTH_LOGIN", rules.Login, byIP),
+				policy("RATE_LIMIT_AUTH_CLIENT", rules.Client, byClient),
+			),
+			Refresh: rateLimit.Limit("auth_refresh",
+				policy("RATE_LIMIT_AUTH_REFRESH", rules.Refresh, byIP),
+				policy("RATE_LIMIT_AUTH_CLIENT", rules.Client, byClient),
+			),
+			User: rateLimit.Limit("auth_user",
+				policy("RATE_LIMIT_AUTH_USER", rules.User, byUser),
+			),
+		}
+	}
+	return c.rateLimits
+}
+
+// SetRateLimits はテスト用にレート制限ミドルウェアをセットする
+func (c *Container) SetRateLimits(r *middleware.RateLimits) {
+	c.rateLimits = r
+}
+
+// GetAuthHandler はAuthHandlerを返す
+func (c *Container) GetAuthHandler() *handler.AuthHandler {
+	if c.authHandler == nil {
+		c.authHandler = handler.NewAuthHandler(c.GetAuthUsecase(), c.GetUserRepository())
+	}
+	return c.authHandler
+}
+
+// SetAuthHandler はテスト用にAuthHandlerをセットする
+func (c *Container) SetAuthHandler(h *handler.AuthHandler) {
+	c.authHandler = h
+}
+
+// GetOAuthHandler はOAuthHandlerを返す
+func (c *Container) GetOAuthHandler() *handler.OAuthHandler {
+	if c.oauthHandler == nil {
+		c.oauthHandler = handler.NewOAuthHandler(c.GetOAuthUsecase(), handler.CallbackMode(c.config.Auth.CallbackMode))
+	}
+	return c.oauthHandler
+}
+
+// SetOAuthHandler はテスト用にOAuthHandlerをセットする
+func (c *Container) SetOAuthHandler(h *handler.OAuthHandler) {
+	c.oauthHandler = h
+}
+
+// GetAuditHandler はAuditHandlerを返す
+func (c *Container) GetAuditHandler() *handler.AuditHandler {
+	if c.auditHandler == nil {
+		c.auditHandler = handler.NewAuditHandler(c.GetAuditUsecase())
+	}
+	return c.auditHandler
+}
+
+// SetAuditHandler はテスト用にAuditHandlerをセットする
+func (c *Container) SetAuditHandler(h *handler.AuditHandler) {
+	c.auditHandler = h
+}
+
+// GetAdminHandler はAdminHandlerを返す
+func (c *Container) GetAdminHandler() *handler.AdminHandler {
+	if c.adminHandler == nil {
+		c.adminHandler = handler.NewAdminHandler(c.GetAdminUsecase())
+	}
+	return c.adminHandler
+}
+
+// SetAdminHandler はテスト用にAdminHandlerをセットする
+func (c *Container) SetAdminHandler(h *handler.AdminHandler) {
+	c.adminHandler = h
+}
+
+// GetWebhookHandler はWebhookHandlerを返す
+func (c *Container) GetWebhookHandler() *handler.WebhookHandler {
+	if c.webhookHandler == nil {
+		c.webhookHandler = handler.NewWebhookHandler(c.GetWebhookUsecase())
+	}
+	return c.webhookHandler
+}
+
+// SetWebhookHandler はテスト用にWebhookHandlerをセットする
+func (c *Container) SetWebhookHandler(h *handler.WebhookHandler) {
+	c.webhookHandler = h
+}
+
+// GetSCIMHandler はSCIMHandlerを返す
+func (c *Container) GetSCIMHandler() *handler.SCIMHandler {
+	if c.scimHandler == nil {
+		c.scimHandler = handler.NewSCIMHandler(c.GetSCIMUsecase())
+	}
+	return c.scimHandler
+}
+
+// SetSCIMHandler はテスト用にSCIMHandlerをセットする
+func (c *Container) SetSCIMHandler(h *handler.SCIMHandler) {
+	c.scimHandler = h
+}
+
+// GetUserHandler はUserHandlerを返す
+func (c *Container) GetUserHandler() *handler.UserHandler {
+	if c.userHandler == nil {
+		c.userHandler = handler.NewUserHandler(c.GetUserUsecase(), c.config.Avatar.MaxUploadBytes)
+	}
+	return c.userHandler
+}
+
+// SetUserHandler はテスト用にUserHandlerをセットする
+func (c *Container) SetUserHandler(h *handler.UserHandler) {
+	c.userHandler = h
+}
+
+// GetAccountHandler はAccountHandlerを返す
+func (c *Container) GetAccountHandler() *handler.AccountHandler {
+	if c.accountHandler == nil {
+		c.accountHandler = handler.NewAccountHandler(c.GetAccountUsecase())
+	}
+	return c.accountHandler
+}
+
+// SetAccountHandler はテスト用にAccountHandlerをセットする
+func (c *Container) SetAccountHandler(h *handler.AccountHandler) {
+	c.accountHandler = h
+}
+
+// GetSecurityHandler はSecurityHandlerを返す
+func (c *Container) GetSecurityHandler() *handler.SecurityHandler {
+	if c.securityHandler == nil {
+		c.securityHandler = handler.NewSecurityHandler(c.GetLoginRiskUsecase())
+	}
+	return c.securityHandler
+}
+
+// SetSecurityHandler はテスト用にSecurityHandlerをセットする
+func (c *Container) SetSecurityHandler(h *handler.SecurityHandler) {
+	c.securityHandler = h
+}
+
+// GetHealthHandler はHealthHandlerを返す
+func (c *Container) GetHealthHandler() *handler.HealthHandler {
+	if c.healthHandler == nil {
+		c.healthHandler = handler.NewHealthHandler(c.GetHealthRegistry())
+	}
+	return c.healthHandler
+}
+
+// SetHealthHandler はテスト用にHealthHandlerをセットする
+func (c *Container) SetHealthHandler(h *handler.HealthHandler) {
+	c.healthHandler = h
+}
+
+// GetOpenAPISpec はリクエスト・レスポンスの検証と/openapi.jsonに使うOpenAPIドキュメントを返す
+func (c *Container) GetOpenAPISpec() *openapi.Spec {
+	if c.openAPISpec == nil {
+		spec, err := openapi.Load()
+		if err != nil {
+			c.fail("openapi spec", err)
+			return nil
+		}
+		c.openAPISpec = spec
+	}
+	return c.openAPISpec
+}
+
+// SetOpenAPISpec はテスト用にOpenAPIドキュメントをセットする
+func (c *Container) SetOpenAPISpec(spec *openapi.Spec) {
+	c.openAPISpec = spec
+}
@@ -0,0 +1,334 @@
+package registry
+
+import (
+	"context"
+	"log/slog"
+	"stackies-backend/domain/model"
+	"stackies-backend/usecase"
+	"time"
+)
+
+// webhookPurgeInterval は保持期間を過ぎたWebhookの配信を削除する間隔
+const webhookPurgeInterval = time.Hour
+
+// GetAuthUsecase はAuthUsecaseの実装を返す
+func (c *Container) GetAuthUsecase() usecase.AuthUsecase {
+	if c.authUsecase == nil {
+		c.authUsecase = usecase.NewAuthUsecase(
+			c.GetUserRepository(),
+			c.GetAuthRepository(),
+			c.GetAuditRepository(),
+			c.GetEventOutbox(),
+			c.GetGoogleService(),
+			c.GetJWTService(),
+			c.GetLoginRiskUsecase(),
+			c.config.Auth.AdminEmails,
+		)
+	}
+	return c.authUsecase
+}
+
+// SetAuthUsecase はテスト用にAuthUsecaseをセットする
+func (c *Container) SetAuthUsecase(u usecase.AuthUsecase) {
+	c.authUsecase = u
+}
+
+// GetOAuthUsecase はOAuthUsecaseの実装を返す
+func (c *Container) GetOAuthUsecase() usecase.OAuthUsecase {
+	if c.oauthUsecase == nil {
+		c.oauthUsecase = usecase.NewOAuthUsecase(
+			c.GetAuthUsecase(),
+			c.GetOAuthRepository(),
+			c.GetUserRepository(),
+			c.GetGoogleService(),
+			c.config.Auth.FrontendRedirectURLs,
+		)
+	}
+	return c.oauthUsecase
+}
+
+// SetOAuthUsecase はテスト用にOAuthUsecaseをセットする
+func (c *Container) SetOAuthUsecase(u usecase.OAuthUsecase) {
+	c.oauthUsecase = u
+}
+
+// GetAuditUsecase はAuditUsecaseの実装を返す
+func (c *Container) GetAuditUsecase() usecase.AuditUsecase {
+	if c.auditUsecase == nil {
+		c.auditUsecase = usecase.NewAuditUsecase(c.GetAuditRepository())
+	}
+	return c.auditUsecase
+}
+
+// SetAuditUsecase はテスト用にAuditUsecaseをセットする
+func (c *Container) SetAuditUsecase(u usecase.AuditUsecase) {
+	c.auditUsecase = u
+}
+
+// GetAdminUsecase はAdminUsecaseの実装を返す
+func (c *Container) GetAdminUsecase() usecase.AdminUsecase {
+	if c.adminUsecase == nil {
+		c.adminUsecase = usecase.NewAdminUsecase(
+			c.GetUserRepository(),
+			c.GetAuthRepository(),
+			c.GetAuditRepository(),
+			c.GetEventOutbox(),
+			c.GetJWTService(),
+			c.config.Auth.ImpersonationTTL,
+		)
+	}
+	return c.adminUsecase
+}
+
+// SetAdminUsecase はテスト用にAdminUsecaseをセットする
+func (c *Container) SetAdminUsecase(u usecase.AdminUsecase) {
+	c.adminUsecase = u
+}
+
+// GetUserUsecase はUserUsecaseの実装を返す
+func (c *Container) GetUserUsecase() usecase.UserUsecase {
+	if c.userUsecase == nil {
+		c.userUsecase = usecase.NewUserUsecase(
+			c.GetUserRepository(),
+			c.GetEventOutbox(),
+			c.GetBlobStore(),
+			c.GetImageProcessor(),
+			c.config.Avatar.BaseURL,
+		)
+	}
+	return c.userUsecase
+}
+
+// SetUserUsecase はテスト用にUserUsecaseをセットする
+func (c *Container) SetUserUsecase(u usecase.UserUsecase) {
+	c.userUsecase = u
+}
+
+// GetAccountUsecase はAccountUsecaseの実装を返す
+// ACCOUNT_PURGE_INTERVALが0より大きい場合は、起動中に削除したアカウントと期限切れのエクスポートを定期的に消去する
+func (c *Container) GetAccountUsecase() usecase.AccountUsecase {
+	if c.accountUsecase == nil {
+		accountUsecase := usecase.NewAccountUsecase(
+			c.GetUserRepository(),
+			c.GetAuthRepository(),
+			c.GetAuditRepository(),
+			c.GetEventOutbox(),
+			c.GetWebhookDeliveryRepository(),
+			c.GetDataExportRepository(),
+			c.GetBlobStore(),
+			c.config.Account.DeletionGracePeriod,
+			c.config.Account.ExportTTL,
+		)
+		// 作成中のエクスポートを待ってから保存先を閉じる
+		c.OnStop("account exports", accountUsecase.Wait)
+		if interval := c.config.Account.PurgeInterval; interval > 0 {
+			c.registerPeriodicTask("account purger", interval, func(ctx context.Context) {
+				purgeAccounts(ctx, accountUsecase)
+			})
+		}
+		c.accountUsecase = accountUsecase
+	}
+	return c.accountUsecase
+}
+
+// SetAccountUsecase はテスト用にAccountUsecaseをセットする
+func (c *Container) SetAccountUsecase(u usecase.AccountUsecase) {
+	c.accountUsecase = u
+}
+
+// registerPeriodicTask は起動時にtaskの定期的な実行を開始し、停止時に終了を待つフックを登録する
+func (c *Container) registerPeriodicTask(name string, interval time.Duration, task func(ctx context.Context)) {
+	var cancel context.CancelFunc
+	done := make(chan struct{})
+
+	c.OnStart(name, func(ctx context.Context) error {
+		// 起動時のコンテキストはStartの完了後に破棄される場合があるため引き継がない
+		loopCtx, loopCancel := context.WithCancel(context.WithoutCancel(ctx))
+		cancel = loopCancel
+		go func() {
+			defer close(done)
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-loopCtx.Done():
+					return
+				case <-ticker.C:
+					task(loopCtx)
+				}
+			}
+		}()
+		return nil
+	})
+	c.OnStop(name, func(ctx context.Context) error {
+		if cancel == nil {
+			return nil
+		}
+		cancel()
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+}
+
+// purgeAccounts は削除したアカウントと期限切れのエクスポートを消去し、結果をログに出力する
+func purgeAccounts(ctx context.Context, accountUsecase usecase.AccountUsecase) {
+	accounts, err := accountUsecase.PurgeDeletedAccounts(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to purge deleted accounts", slog.Any("error", err))
+	}
+	exports, err := accountUsecase.PurgeExpiredExports(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to purge expired exports", slog.Any("error", err))
+	}
+	if accounts > 0 || exports > 0 {
+		slog.InfoContext(ctx, "purged account data", slog.Int("accounts", accounts), slog.Int("exports", exports))
+	}
+}
+
+// GetLoginRiskUsecase はLoginRiskUsecaseの実装を返す
+func (c *Container) GetLoginRiskUsecase() usecase.LoginRiskUsecase {
+	if c.loginRiskUsecase == nil {
+		c.loginRiskUsecase = usecase.NewLoginRiskUsecase(
+			c.GetUserRepository(),
+			c.GetAuditRepository(),
+			c.GetAuthRepository(),
+			c.GetRevocationTokenRepository(),
+			c.GetEventOutbox(),
+			c.GetGeoIPService(),
+			c.GetLoginAlertNotifier(),
+			c.config.Security.RevokeURL,
+		)
+	}
+	return c.loginRiskUsecase
+}
+
+// SetLoginRiskUsecase はテスト用にLoginRiskUsecaseをセットする
+func (c *Container) SetLoginRiskUsecase(u usecase.LoginRiskUsecase) {
+	c.loginRiskUsecase = u
+}
+
+// GetEventOutbox はリポジトリへの書き込みとドメインイベントを同じトランザクションで保存するEventOutboxを返す
+func (c *Container) GetEventOutbox() *usecase.EventOutbox {
+	if c.eventOutbox == nil {
+		c.eventOutbox = usecase.NewEventOutbox(c.GetTransactor(), c.GetOutboxRepository())
+	}
+	return c.eventOutbox
+}
+
+// SetEventOutbox はテスト用にEventOutboxをセットする
+func (c *Container) SetEventOutbox(o *usecase.EventOutbox) {
+	c.eventOutbox = o
+}
+
+// GetEventDispatcher はアウトボックスのドメインイベントを購読者に配信するEventDispatcherを返す
+// EVENTS_DISPATCH_INTERVALが0より大きい場合は、起動中に定期的に配信し、停止時に配信待ちのイベントを配信する
+func (c *Container) GetEventDispatcher() *usecase.EventDispatcher {
+	if c.eventDispatcher == nil {
+		dispatcher := usecase.NewEventDispatcher(c.GetOutboxRepository())
+		metrics := c.GetMetrics()
+		dispatcher.Subscribe("metrics", func(ctx context.Context, event *model.DomainEvent) error {
+			metrics.IncDomainEvent(string(event.Type))
+			return nil
+		})
+		dispatcher.Subscribe("webhooks", c.GetWebhookUsecase().HandleEvent)
+		if interval := c.config.Events.DispatchInterval; interval > 0 {
+			// 停止フックは逆順に実行するため、定期的な配信の終了後に残りを配信する
+			c.OnStop("pending events", func(ctx context.Context) error {
+				_, err := dispatcher.DispatchPending(ctx)
+				return err
+			})
+			c.registerPeriodicTask("event dispatcher", interval, func(ctx context.Context) {
+				dispatchEvents(ctx, dispatcher)
+			})
+		}
+		c.eventDispatcher = dispatcher
+	}
+	return c.eventDispatcher
+}
+
+// SetEventDispatcher はテスト用にEventDispatcherをセットする
+func (c *Container) SetEventDispatcher(d *usecase.EventDispatcher) {
+	c.eventDispatcher = d
+}
+
+// dispatchEvents は配信待ちのドメインイベントを配信し、失敗をログに出力する
+func dispatchEvents(ctx context.Context, dispatcher *usecase.EventDispatcher) {
+	if _, err := dispatcher.DispatchPending(ctx); err != nil && ctx.Err() == nil {
+		slog.ErrorContext(ctx, "failed to dispatch domain events", slog.Any("error", err))
+	}
+}
+
+// GetWebhookUsecase はWebhookUsecaseの実装を返す
+// WEBHOOK_DELIVERY_INTERVALが0より大きい場合は、起動中に配信待ちのWebhookを定期的に送信する
+func (c *Container) GetWebhookUsecase() usecase.WebhookUsecase {
+	if c.webhookUsecase == nil {
+		webhookUsecase := usecase.NewWebhookUsecase(
+			c.GetWebhookRepository(),
+			c.GetWebhookDeliveryRepository(),
+			c.GetAuditRepository(),
+			c.GetWebhookSender(),
+			c.config.Webhook.MaxAttempts,
+			c.config.Webhook.AllowPrivateTargets,
+			c.config.Webhook.DeliveryRetention,
+		)
+		if interval := c.config.Webhook.DeliveryInterval; interval > 0 {
+			c.registerPeriodicTask("webhook deliveries", interval, func(ctx context.Context) {
+				deliverWebhooks(ctx, webhookUsecase)
+			})
+			if c.config.Webhook.DeliveryRetention > 0 {
+				c.registerPeriodicTask("webhook delivery purger", webhookPurgeInterval, func(ctx context.Context) {
+					purgeWebhookDeliveries(ctx, webhookUsecase)
+				})
+			}
+		}
+		c.webhookUsecase = webhookUsecase
+	}
+	return c.webhookUsecase
+}
+
+// SetWebhookUsecase はテスト用にWebhookUsecaseをセットする
+func (c *Container) SetWebhookUsecase(u usecase.WebhookUsecase) {
+	c.webhookUsecase = u
+}
+
+// purgeWebhookDeliveries は保持期間を過ぎた成功済みの配信を削除し、結果をログに出力する
+func purgeWebhookDeliveries(ctx context.Context, webhookUsecase usecase.WebhookUsecase) {
+	purged, err := webhookUsecase.PurgeDeliveries(ctx)
+	if err != nil && ctx.Err() == nil {
+		slog.ErrorContext(ctx, "failed to purge webhook deliveries", slog.Any("error", err))
+	}
+	if purged > 0 {
+		slog.InfoContext(ctx, "purged webhook deliveries", slog.Int("count", purged))
+	}
+}
+
+// deliverWebhooks は配信待ちのWebhookを送信し、失敗をログに出力する
+func deliverWebhooks(ctx context.Context, webhookUsecase usecase.WebhookUsecase) {
+	if _, err := webhookUsecase.DeliverDue(ctx); err != nil && ctx.Err() == nil {
+		slog.ErrorContext(ctx, "failed to deliver webhooks", slog.Any("error", err))
+	}
+}
+
+// GetSCIMUsecase はSCIMUsecaseの実装を返す
+func (c *Container) GetSCIMUsecase() usecase.SCIMUsecase {
+	if c.scimUsecase == nil {
+		c.scimUsecase = usecase.NewSCIMUsecase(
+			c.GetUserRepository(),
+			c.GetGroupRepository(),
+			c.GetAuditRepository(),
+			c.GetEventOutbox(),
+			c.GetAdminUsecase(),
+			c.config.SCIM.AdminGroup,
+		)
+	}
+	return c.scimUsecase
+}
+
+// SetSCIMUsecase はテスト用にSCIMUsecaseをセットする
+func (c *Container) SetSCIMUsecase(u usecase.SCIMUsecase) {
+	c.scimUsecase = u
+}
@@ -0,0 +1,484 @@
+package usecase
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"stackies-backend/core"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/repository"
+	"stackies-backend/domain/service"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	ErrUserAlreadyDeleted = core.NewAppError("user_already_deleted", http.StatusConflict, "User is already scheduled for deletion")
+)
+
+const (
+	// dataExportTimeout はエクスポートの作成の最大時間
+	// 超えても完了しない場合は作成中に停止したとみなし、次の要求で作り直す
+	dataExportTimeout = 10 * time.Minute
+	// revokeReasonAccountDeleted はアカウントの削除によるセッションの無効化理由
+	revokeReasonAccountDeleted = "account_deleted"
+	// revokeReasonAccountPurged はアカウントの消去によるセッションの無効化理由
+	revokeReasonAccountPurged = "account_purged"
+)
+
+// AccountUsecase はアカウントの削除とデータのエクスポートのビジネスロジックを抽象化する
+type AccountUsecase interface {
+	DeleteAccount(ctx context.Context, input *DeleteAccountInput) (*DeleteAccountOutput, error)
+	PurgeDeletedAccounts(ctx context.Context) (int, error)
+	RequestExport(ctx context.Context, input *RequestExportInput) (*ExportOutput, error)
+	PurgeExpiredExports(ctx context.Context) (int, error)
+	// Wait は作成中のエクスポートの完了を待つ（停止時に使う）
+	Wait(ctx context.Context) error
+}
+
+type (
+	// DeleteAccountInput はアカウント削除の入力パラメータを表す
+	DeleteAccountInput struct {
+		UserID    string
+		ClientIP  string
+		UserAgent string
+	}
+
+	// DeleteAccountOutput はアカウント削除の出力パラメータを表す
+	DeleteAccountOutput struct {
+		// PurgeAt はデータを完全に消去する日時
+		PurgeAt time.Time
+	}
+
+	// RequestExportInput はデータのエクスポートの入力パラメータを表す
+	RequestExportInput struct {
+		UserID    string
+		ClientIP  string
+		UserAgent string
+	}
+
+	// ExportOutput はデータのエクスポートの状態を表す
+	// 作成が完了している場合、RedirectURLが空でなければ保存先から直接取得させ、空の場合はBlobを返す
+	ExportOutput struct {
+		Export      *model.DataExport
+		RedirectURL string
+		Blob        *model.Blob
+	}
+
+	// AccountUsecaseImpl はAccountUsecaseの実装
+	AccountUsecaseImpl struct {
+		userRepo     repository.UserRepository
+		authRepo     repository.AuthRepository
+		auditRepo    repository.AuditRepository
+		events       *EventOutbox
+		deliveryRepo repository.WebhookDeliveryRepository
+		exportRepo   repository.DataExportRepository
+		blobStore    service.BlobStore
+		gracePeriod  time.Duration
+		exportTTL    time.Duration
+		// jobs は作成中のエクスポート
+		jobs sync.WaitGroup
+	}
+)
+
+// NewAccountUsecase は新しいAccountUsecaseを作成する
+// gracePeriodは削除を受け付けてから完全に消去するまでの猶予期間、exportTTLはエクスポートしたデータを保持する期間
+// eventsがnilの場合、ドメインイベントは保存しない
+func NewAccountUsecase(
+	userRepo repository.UserRepository,
+	authRepo repository.AuthRepository,
+	auditRepo repository.AuditRepository,
+	events *EventOutbox,
+	deliveryRepo repository.WebhookDeliveryRepository,
+	exportRepo repository.DataExportRepository,
+	blobStore service.BlobStore,
+	gracePeriod time.Duration,
+	exportTTL time.Duration,
+) AccountUsecase {
+	return &AccountUsecaseImpl{
+		userRepo:     userRepo,
+		authRepo:     authRepo,
+		auditRepo:    auditRepo,
+		events:       events,
+		deliveryRepo: deliveryRepo,
+		exportRepo:   exportRepo,
+		blobStore:    blobStore,
+		gracePeriod:  gracePeriod,
+		exportTTL:    exportTTL,
+	}
+}
+
+// DeleteAccount はユーザー自身によるアカウントの削除を受け付ける
+// ユーザーは直ちにログインできなくなり、猶予期間の後にPurgeDeletedAccountsで完全に消去する
+func (a *AccountUsecaseImpl) DeleteAccount(ctx context.Context, input *DeleteAccountInput) (*DeleteAccountOutput, error) {
+	user, err := a.userRepo.FindByID(ctx, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsDeleted() {
+		return nil, ErrUserAlreadyDeleted
+	}
+
+	if err := user.MarkDeleted(); err != nil {
+		return nil, err
+	}
+	err = a.events.Write(ctx, func(ctx context.Context) error {
+		if err := a.userRepo.Update(ctx, user); err != nil {
+			return err
+		}
+		return a.authRepo.DeleteToken(ctx, user.ID)
+	}, sessionRevokedEvent(user.ID, user.ID, revokeReasonAccountDeleted))
+	if err != nil {
+		return nil, err
+	}
+	// 削除後はエクスポートしたデータも取得できないようにする
+	if err := a.deleteExport(ctx, user.ID); err != nil {
+		return nil, err
+	}
+
+	purgeAt := user.PurgeAt(a.gracePeriod)
+	event := newAuditEvent(model.AuditEventAccountDelete, model.AuditOutcomeSuccess, user.ID, user.ID, input.ClientIP, input.UserAgent)
+	event.Metadata = map[string]string{"purge_at": purgeAt.UTC().Format(time.RFC3339)}
+	recordAuditEvent(ctx, a.auditRepo, event)
+
+	return &DeleteAccountOutput{PurgeAt: purgeAt}, nil
+}
+
+// PurgeDeletedAccounts は猶予期間を過ぎたアカウントを完全に消去し、消去した数を返す
+// 失敗したアカウントは次回の実行で再度消去する
+func (a *AccountUsecaseImpl) PurgeDeletedAccounts(ctx context.Context) (int, error) {
+	users, err := a.userRepo.FindAll(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	purged := 0
+	var errs []error
+	for _, user := range users {
+		if !user.IsDeleted() || now.Before(user.PurgeAt(a.gracePeriod)) {
+			continue
+		}
+		if err := a.purge(ctx, user); err != nil {
+			errs = append(errs, fmt.Errorf("failed to purge user %s: %w", user.ID, err))
+			continue
+		}
+		purged++
+	}
+	return purged, errors.Join(errs...)
+}
+
+// purge はユーザーのセッション・画像・エクスポート・ドメインイベントを削除し、監査ログを仮名化してからユーザーを削除する
+// 監査ログはセキュリティ上の記録として残すが、ユーザーIDはユーザーごとのランダムな仮名に置き換え、IPアドレスとUser-Agentを削除する
+// ドメインイベントとWebhookの配信はメールアドレスなどを含むため、配信待ち・失敗したものを含めて削除する
+func (a *AccountUsecaseImpl) purge(ctx context.Context, user *model.User) error {
+	token, err := generateRandomToken(8)
+	if err != nil {
+		return err
+	}
+	pseudonym := "deleted_" + token
+
+	// 消去を通知するイベントを残すため、イベントの保存より前に削除する
+	deletedEvents, err := a.events.DeleteUserEvents(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	deletedDeliveries, err := a.deliveryRepo.DeleteByUserID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	err = a.events.Write(ctx, func(ctx context.Context) error {
+		return a.authRepo.DeleteToken(ctx, user.ID)
+	}, sessionRevokedEvent(user.ID, model.SystemActorID, revokeReasonAccountPurged))
+	if err != nil {
+		return err
+	}
+	if user.Avatar != nil {
+		deleteAvatarBlobs(ctx, a.blobStore, user.ID, user.Avatar)
+	}
+	if err := a.deleteExport(ctx, user.ID); err != nil {
+		return err
+	}
+	count, err := a.auditRepo.Pseudonymize(ctx, user.ID, pseudonym)
+	if err != nil {
+		return err
+	}
+	if err := a.userRepo.Delete(ctx, user.ID); err != nil {
+		return err
+	}
+
+	event := newAuditEvent(model.AuditEventAccountPurge, model.AuditOutcomeSuccess, model.SystemActorID, pseudonym, "", "")
+	event.Metadata = map[string]string{
+		"pseudonymized_events":       strconv.Itoa(count),
+		"deleted_domain_events":      strconv.Itoa(deletedEvents),
+		"deleted_webhook_deliveries": strconv.Itoa(deletedDeliveries),
+	}
+	recordAuditEvent(ctx, a.auditRepo, event)
+	return nil
+}
+
+// RequestExport はユーザーが保有するデータのZIPアーカイブを返す
+// 作成済みのアーカイブがなければバックグラウンドで作成を開始し、作成中の状態を返す
+func (a *AccountUsecaseImpl) RequestExport(ctx context.Context, input *RequestExportInput) (*ExportOutput, error) {
+	user, err := a.userRepo.FindByID(ctx, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsDeleted() {
+		return nil, repository.ErrUserNotFound
+	}
+
+	export, err := a.exportRepo.FindByUserID(ctx, user.ID)
+	if err != nil && !errors.Is(err, repository.ErrDataExportNotFound) {
+		return nil, err
+	}
+	if export != nil && export.IsReusable(time.Now(), dataExportTimeout) {
+		output, err := a.exportOutput(ctx, export)
+		if !errors.Is(err, service.ErrBlobNotFound) {
+			return output, err
+		}
+		// アーカイブが失われている場合は作り直す
+	}
+	if export != nil {
+		a.deleteExportBlob(ctx, export)
+	}
+
+	id, err := generateRandomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	export, err = model.NewDataExport(id, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.exportRepo.Save(ctx, export); err != nil {
+		return nil, err
+	}
+	recordAuditEvent(ctx, a.auditRepo, newAuditEvent(model.AuditEventDataExport, model.AuditOutcomeSuccess, user.ID, user.ID, input.ClientIP, input.UserAgent))
+
+	// リクエストの完了後も作成を続け、返す値とは別のインスタンスを更新する
+	job := *export
+	a.jobs.Add(1)
+	go func() {
+		defer a.jobs.Done()
+		a.buildExport(context.WithoutCancel(ctx), &job)
+	}()
+
+	return &ExportOutput{Export: export}, nil
+}
+
+// exportOutput はエクスポートの状態と、作成済みの場合はアーカイブの取得方法を返す
+func (a *AccountUsecaseImpl) exportOutput(ctx context.Context, export *model.DataExport) (*ExportOutput, error) {
+	if export.Status != model.DataExportReady {
+		return &ExportOutput{Export: export}, nil
+	}
+
+	redirectURL, err := a.blobStore.URL(ctx, export.Key())
+	if err != nil {
+		return nil, err
+	}
+	if redirectURL != "" {
+		return &ExportOutput{Export: export, RedirectURL: redirectURL}, nil
+	}
+
+	blob, err := a.blobStore.Get(ctx, export.Key())
+	if err != nil {
+		return nil, err
+	}
+	return &ExportOutput{Export: export, Blob: blob}, nil
+}
+
+// buildExport はアーカイブを作成して保存し、エクスポートの状態を更新する
+func (a *AccountUsecaseImpl) buildExport(ctx context.Context, export *model.DataExport) {
+	ctx, cancel := context.WithTimeout(ctx, dataExportTimeout)
+	defer cancel()
+
+	data, err := a.archive(ctx, export.UserID)
+	if err == nil {
+		err = a.blobStore.Put(ctx, export.Key(), &model.Blob{ContentType: "application/zip", Data: data})
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to build data export", slog.String("user_id", export.UserID), slog.Any("error", err))
+		export.Fail()
+	} else {
+		export.Complete(a.exportTTL)
+	}
+
+	// 作成中にアカウントが削除された場合や新しいエクスポートに置き換えられた場合は破棄する
+	current, err := a.exportRepo.FindByUserID(ctx, export.UserID)
+	if err != nil || current.ID != export.ID {
+		a.deleteExportBlob(ctx, export)
+		return
+	}
+	if err := a.exportRepo.Save(ctx, export); err != nil {
+		slog.ErrorContext(ctx, "failed to save data export", slog.String("user_id", export.UserID), slog.Any("error", err))
+	}
+}
+
+// exportedUser はエクスポートするユーザー情報（APIのレスポンスに含めない項目も含める）
+type exportedUser struct {
+	*model.User
+	NameEdited    bool          `json:"name_edited"`
+	PictureEdited bool          `json:"picture_edited"`
+	Avatar        *model.Avatar `json:"avatar,omitempty"`
+}
+
+// exportedSession はエクスポートするセッションの情報（トークンそのものは含めない）
+type exportedSession struct {
+	AuthenticatedAt time.Time `json:"authenticated_at"`
+	ExpiresAt       time.Time `json:"expires_at"`
+}
+
+// archive はユーザーのプロフィール・セッション・監査ログ・アップロードした画像をZIPにまとめる
+func (a *AccountUsecaseImpl) archive(ctx context.Context, userID string) ([]byte, error) {
+	user, err := a.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	writeJSON := func(name string, value any) error {
+		w, err := archive.Create(name)
+		if err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	}
+
+	if err := writeJSON("user.json", &exportedUser{
+		User:          user,
+		NameEdited:    user.NameEdited,
+		PictureEdited: user.PictureEdited,
+		Avatar:        user.Avatar,
+	}); err != nil {
+		return nil, err
+	}
+
+	sessions := []exportedSession{}
+	token, err := a.authRepo.GetToken(ctx, userID)
+	if err != nil && !errors.Is(err, repository.ErrTokenNotFound) {
+		return nil, err
+	}
+	if token != nil {
+		sessions = append(sessions, exportedSession{AuthenticatedAt: token.AuthenticatedAt, ExpiresAt: time.Unix(token.ExpiresIn, 0)})
+	}
+	if err := writeJSON("sessions.json", sessions); err != nil {
+		return nil, err
+	}
+
+	events, err := a.auditEvents(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeJSON("audit_events.json", events); err != nil {
+		return nil, err
+	}
+
+	if user.Avatar != nil {
+		for _, size := range model.AvatarSizes {
+			blob, err := a.blobStore.Get(ctx, user.Avatar.Key(userID, size))
+			if errors.Is(err, service.ErrBlobNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			w, err := archive.Create(fmt.Sprintf("avatar/%d.%s", size, user.Avatar.Format.Extension()))
+			if err != nil {
+				return nil, err
+			}
+			if _, err := w.Write(blob.Data); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// auditEvents はユーザーが操作した、またはユーザーが対象の監査イベントをすべて返す（新しい順）
+func (a *AccountUsecaseImpl) auditEvents(ctx context.Context, userID string) ([]*model.AuditEvent, error) {
+	events := []*model.AuditEvent{}
+	filter := &model.AuditEventFilter{UserID: userID, Limit: model.MaxAuditPageSize}
+	for {
+		page, err := a.auditRepo.Query(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, page.Events...)
+		if page.NextCursor == "" {
+			return events, nil
+		}
+		filter.Cursor = page.NextCursor
+	}
+}
+
+// PurgeExpiredExports は保持期限を過ぎたエクスポートを削除し、削除した数を返す
+func (a *AccountUsecaseImpl) PurgeExpiredExports(ctx context.Context) (int, error) {
+	exports, err := a.exportRepo.FindExpired(ctx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	var errs []error
+	purged := 0
+	for _, export := range exports {
+		if err := a.deleteExport(ctx, export.UserID); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		purged++
+	}
+	return purged, errors.Join(errs...)
+}
+
+// deleteExport はユーザーのエクスポートとアーカイブを削除する
+func (a *AccountUsecaseImpl) deleteExport(ctx context.Context, userID string) error {
+	export, err := a.exportRepo.FindByUserID(ctx, userID)
+	if errors.Is(err, repository.ErrDataExportNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	a.deleteExportBlob(ctx, export)
+	return a.exportRepo.DeleteByUserID(ctx, userID)
+}
+
+// deleteExportBlob はアーカイブを削除する
+// 削除に失敗しても参照されないため、ログに出力して処理を続ける
+func (a *AccountUsecaseImpl) deleteExportBlob(ctx context.Context, export *model.DataExport) {
+	if err := a.blobStore.Delete(ctx, export.Key()); err != nil {
+		slog.WarnContext(ctx, "failed to delete data export", slog.String("key", export.Key()), slog.Any("error", err))
+	}
+}
+
+// Wait は作成中のエクスポートの完了を待つ
+func (a *AccountUsecaseImpl) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		a.jobs.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
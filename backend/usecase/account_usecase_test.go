@@ -0,0 +1,374 @@
+package usecase
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/repository"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockDataExportRepository はDataExportRepositoryのモック
+type MockDataExportRepository struct {
+	mock.Mock
+}
+
+var _ repository.DataExportRepository = (*MockDataExportRepository)(nil)
+
+func (m *MockDataExportRepository) Save(ctx context.Context, export *model.DataExport) error {
+	args := m.Called(ctx, export)
+	return args.Error(0)
+}
+
+func (m *MockDataExportRepository) FindByUserID(ctx context.Context, userID string) (*model.DataExport, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.DataExport), args.Error(1)
+}
+
+func (m *MockDataExportRepository) DeleteByUserID(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockDataExportRepository) FindExpired(ctx context.Context, now time.Time) ([]*model.DataExport, error) {
+	args := m.Called(ctx, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.DataExport), args.Error(1)
+}
+
+// accountMocks はAccountUsecaseの依存のモックを表す
+type accountMocks struct {
+	userRepo     *MockUserRepository
+	authRepo     *MockAuthRepository
+	auditRepo    *MockAuditRepository
+	outboxRepo   *MockOutboxRepository
+	deliveryRepo *MockWebhookDeliveryRepository
+	exportRepo   *MockDataExportRepository
+	blobStore    *MockBlobStore
+}
+
+func newAccountMocks() *accountMocks {
+	return &accountMocks{
+		userRepo:     new(MockUserRepository),
+		authRepo:     new(MockAuthRepository),
+		auditRepo:    new(MockAuditRepository),
+		outboxRepo:   new(MockOutboxRepository),
+		deliveryRepo: new(MockWebhookDeliveryRepository),
+		exportRepo:   new(MockDataExportRepository),
+		blobStore:    new(MockBlobStore),
+	}
+}
+
+func (m *accountMocks) usecase() AccountUsecase {
+	return NewAccountUsecase(m.userRepo, m.authRepo, m.auditRepo, nil, m.deliveryRepo, m.exportRepo, m.blobStore, 30*24*time.Hour, time.Hour)
+}
+
+// usecaseWithEvents はドメインイベントをアウトボックスのモックに保存するAccountUsecaseを返す
+func (m *accountMocks) usecaseWithEvents() AccountUsecase {
+	return NewAccountUsecase(m.userRepo, m.authRepo, m.auditRepo, NewEventOutbox(stubTransactor{}, m.outboxRepo), m.deliveryRepo, m.exportRepo, m.blobStore, 30*24*time.Hour, time.Hour)
+}
+
+func (m *accountMocks) assertExpectations(t *testing.T) {
+	m.userRepo.AssertExpectations(t)
+	m.authRepo.AssertExpectations(t)
+	m.auditRepo.AssertExpectations(t)
+	m.outboxRepo.AssertExpectations(t)
+	m.deliveryRepo.AssertExpectations(t)
+	m.exportRepo.AssertExpectations(t)
+	m.blobStore.AssertExpectations(t)
+}
+
+func TestAccountUsecaseImpl_DeleteAccount(t *testing.T) {
+	deletedAt := time.Now()
+	input := &DeleteAccountInput{UserID: "user_1", ClientIP: "192.0.2.1", UserAgent: "test-agent"}
+
+	tests := []struct {
+		testName      string
+		setupMocks    func(*accountMocks)
+		expectedError error
+	}{
+		{
+			testName: "削除を受け付けてセッションとエクスポートを削除する",
+			setupMocks: func(m *accountMocks) {
+				export := &model.DataExport{ID: "export_1", UserID: "user_1", Status: model.DataExportReady}
+				m.userRepo.On("FindByID", mock.Anything, "user_1").Return(&model.User{ID: "user_1", Email: "test@example.com", Name: "Test User"}, nil)
+				m.userRepo.On("Update", mock.Anything, mock.MatchedBy(func(user *model.User) bool {
+					return user.IsDeleted()
+				})).Return(nil)
+				m.authRepo.On("DeleteToken", mock.Anything, "user_1").Return(nil)
+				m.exportRepo.On("FindByUserID", mock.Anything, "user_1").Return(export, nil)
+				m.blobStore.On("Delete", mock.Anything, "exports/user_1/export_1.zip").Return(nil)
+				m.exportRepo.On("DeleteByUserID", mock.Anything, "user_1").Return(nil)
+				m.auditRepo.On("Append", mock.Anything, mock.MatchedBy(func(event *model.AuditEvent) bool {
+					return event.Type == model.AuditEventAccountDelete && event.SubjectID == "user_1" && event.Metadata["purge_at"] != ""
+				})).Return(nil)
+			},
+		},
+		{
+			testName: "削除済みのユーザー",
+			setupMocks: func(m *accountMocks) {
+				m.userRepo.On("FindByID", mock.Anything, "user_1").Return(&model.User{ID: "user_1", DeletedAt: &deletedAt}, nil)
+			},
+			expectedError: ErrUserAlreadyDeleted,
+		},
+		{
+			testName: "存在しないユーザー",
+			setupMocks: func(m *accountMocks) {
+				m.userRepo.On("FindByID", mock.Anything, "user_1").Return(nil, repository.ErrUserNotFound)
+			},
+			expectedError: repository.ErrUserNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			m := newAccountMocks()
+			tt.setupMocks(m)
+
+			output, err := m.usecase().DeleteAccount(context.Background(), input)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				require.NoError(t, err)
+				assert.WithinDuration(t, time.Now().Add(30*24*time.Hour), output.PurgeAt, time.Minute)
+			}
+			m.assertExpectations(t)
+		})
+	}
+}
+
+func TestAccountUsecaseImpl_PurgeDeletedAccounts(t *testing.T) {
+	recent := time.Now().Add(-time.Hour)
+	expired := time.Now().Add(-31 * 24 * time.Hour)
+	avatar := &model.Avatar{Version: "v1", Format: model.AvatarFormatJPEG}
+
+	m := newAccountMocks()
+	m.userRepo.On("FindAll", mock.Anything).Return([]*model.User{
+		{ID: "active"},
+		{ID: "recent", DeletedAt: &recent},
+		{ID: "expired", DeletedAt: &expired, Avatar: avatar},
+	}, nil)
+
+	var pseudonym string
+	// メールアドレスなどを含むイベントと配信を、消去を通知するイベントの保存より前に削除する
+	deleteEvents := m.outboxRepo.On("DeleteByUserID", mock.Anything, "expired").Return(2, nil)
+	m.deliveryRepo.On("DeleteByUserID", mock.Anything, "expired").Return(4, nil)
+	m.outboxRepo.On("Append", mock.Anything, domainEventsOf(model.DomainEventSessionRevoked)).Return(nil).NotBefore(deleteEvents)
+	m.authRepo.On("DeleteToken", mock.Anything, "expired").Return(nil)
+	for _, size := range model.AvatarSizes {
+		m.blobStore.On("Delete", mock.Anything, avatar.Key("expired", size)).Return(nil)
+	}
+	m.exportRepo.On("FindByUserID", mock.Anything, "expired").Return(nil, repository.ErrDataExportNotFound)
+	m.auditRepo.On("Pseudonymize", mock.Anything, "expired", mock.MatchedBy(func(value string) bool {
+		return strings.HasPrefix(value, "deleted_")
+	})).Run(func(args mock.Arguments) {
+		pseudonym = args.String(2)
+	}).Return(3, nil)
+	m.userRepo.On("Delete", mock.Anything, "expired").Return(nil)
+	m.auditRepo.On("Append", mock.Anything, mock.MatchedBy(func(event *model.AuditEvent) bool {
+		// 消去の記録にも元のユーザーIDを残さない
+		return event.Type == model.AuditEventAccountPurge && event.ActorID == model.SystemActorID &&
+			event.SubjectID == pseudonym && event.Metadata["pseudonymized_events"] == "3" &&
+			event.Metadata["deleted_domain_events"] == "2" && event.Metadata["deleted_webhook_deliveries"] == "4"
+	})).Return(nil)
+
+	purged, err := m.usecaseWithEvents().PurgeDeletedAccounts(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	m.assertExpectations(t)
+}
+
+func TestAccountUsecaseImpl_PurgeDeletedAccounts_ContinuesOnError(t *testing.T) {
+	expired := time.Now().Add(-31 * 24 * time.Hour)
+
+	m := newAccountMocks()
+	m.userRepo.On("FindAll", mock.Anything).Return([]*model.User{
+		{ID: "user_1", DeletedAt: &expired},
+		{ID: "user_2", DeletedAt: &expired},
+	}, nil)
+	m.deliveryRepo.On("DeleteByUserID", mock.Anything, mock.Anything).Return(0, nil)
+	m.authRepo.On("DeleteToken", mock.Anything, "user_1").Return(errors.New("connection refused"))
+	m.authRepo.On("DeleteToken", mock.Anything, "user_2").Return(nil)
+	m.exportRepo.On("FindByUserID", mock.Anything, "user_2").Return(nil, repository.ErrDataExportNotFound)
+	m.auditRepo.On("Pseudonymize", mock.Anything, "user_2", mock.Anything).Return(0, nil)
+	m.userRepo.On("Delete", mock.Anything, "user_2").Return(nil)
+	m.auditRepo.On("Append", mock.Anything, auditEventOf(model.AuditEventAccountPurge, model.AuditOutcomeSuccess)).Return(nil)
+
+	purged, err := m.usecase().PurgeDeletedAccounts(context.Background())
+	assert.ErrorContains(t, err, "user_1")
+	assert.Equal(t, 1, purged)
+	// 消去に失敗したユーザーは削除しない
+	m.userRepo.AssertNotCalled(t, "Delete", mock.Anything, "user_1")
+}
+
+func TestAccountUsecaseImpl_RequestExport(t *testing.T) {
+	now := time.Now()
+	expiresAt := now.Add(time.Hour)
+	expiredAt := now.Add(-time.Minute)
+	user := &model.User{ID: "user_1", Email: "test@example.com", Name: "Test User"}
+	input := &RequestExportInput{UserID: "user_1"}
+	archive := &model.Blob{ContentType: "application/zip", Data: []byte("zip")}
+
+	tests := []struct {
+		testName       string
+		setupMocks     func(*accountMocks)
+		expectedError  error
+		expectedStatus model.DataExportStatus
+		expectedOutput func(*testing.T, *ExportOutput)
+	}{
+		{
+			testName: "作成中のエクスポートはそのまま返す",
+			setupMocks: func(m *accountMocks) {
+				m.userRepo.On("FindByID", mock.Anything, "user_1").Return(user, nil)
+				m.exportRepo.On("FindByUserID", mock.Anything, "user_1").Return(&model.DataExport{ID: "export_1", UserID: "user_1", Status: model.DataExportPending, RequestedAt: now}, nil)
+			},
+			expectedStatus: model.DataExportPending,
+		},
+		{
+			testName: "作成済みのアーカイブを返す",
+			setupMocks: func(m *accountMocks) {
+				m.userRepo.On("FindByID", mock.Anything, "user_1").Return(user, nil)
+				m.exportRepo.On("FindByUserID", mock.Anything, "user_1").Return(&model.DataExport{ID: "export_1", UserID: "user_1", Status: model.DataExportReady, RequestedAt: now, ExpiresAt: &expiresAt}, nil)
+				m.blobStore.On("URL", mock.Anything, "exports/user_1/export_1.zip").Return("", nil)
+				m.blobStore.On("Get", mock.Anything, "exports/user_1/export_1.zip").Return(archive, nil)
+			},
+			expectedStatus: model.DataExportReady,
+			expectedOutput: func(t *testing.T, output *ExportOutput) {
+				assert.Equal(t, archive, output.Blob)
+			},
+		},
+		{
+			testName: "保存先のURLがある場合はリダイレクト先を返す",
+			setupMocks: func(m *accountMocks) {
+				m.userRepo.On("FindByID", mock.Anything, "user_1").Return(user, nil)
+				m.exportRepo.On("FindByUserID", mock.Anything, "user_1").Return(&model.DataExport{ID: "export_1", UserID: "user_1", Status: model.DataExportReady, RequestedAt: now, ExpiresAt: &expiresAt}, nil)
+				m.blobStore.On("URL", mock.Anything, "exports/user_1/export_1.zip").Return("https://storage.example.com/export.zip", nil)
+			},
+			expectedStatus: model.DataExportReady,
+			expectedOutput: func(t *testing.T, output *ExportOutput) {
+				assert.Equal(t, "https://storage.example.com/export.zip", output.RedirectURL)
+				assert.Nil(t, output.Blob)
+			},
+		},
+		{
+			testName: "削除したユーザーはエクスポートできない",
+			setupMocks: func(m *accountMocks) {
+				m.userRepo.On("FindByID", mock.Anything, "user_1").Return(&model.User{ID: "user_1", DeletedAt: &now}, nil)
+			},
+			expectedError: repository.ErrUserNotFound,
+		},
+		{
+			testName: "期限切れの場合は新しいエクスポートを開始する",
+			setupMocks: func(m *accountMocks) {
+				old := &model.DataExport{ID: "old", UserID: "user_1", Status: model.DataExportReady, RequestedAt: now, ExpiresAt: &expiredAt}
+				m.userRepo.On("FindByID", mock.Anything, "user_1").Return(user, nil)
+				m.exportRepo.On("FindByUserID", mock.Anything, "user_1").Return(old, nil)
+				m.blobStore.On("Delete", mock.Anything, "exports/user_1/old.zip").Return(nil)
+				m.exportRepo.On("Save", mock.Anything, mock.MatchedBy(func(export *model.DataExport) bool {
+					return export.ID != "old" && export.Status == model.DataExportPending
+				})).Return(nil)
+				m.auditRepo.On("Append", mock.Anything, auditEventOf(model.AuditEventDataExport, model.AuditOutcomeSuccess)).Return(nil)
+
+				// バックグラウンドでの作成（作成中に置き換えられたとみなして破棄させる）
+				m.authRepo.On("GetToken", mock.Anything, "user_1").Return(nil, repository.ErrTokenNotFound)
+				m.auditRepo.On("Query", mock.Anything, mock.Anything).Return(&model.AuditEventPage{}, nil)
+				m.blobStore.On("Put", mock.Anything, mock.MatchedBy(func(key string) bool {
+					return strings.HasPrefix(key, "exports/user_1/")
+				}), mock.Anything).Return(nil)
+				m.blobStore.On("Delete", mock.Anything, mock.MatchedBy(func(key string) bool {
+					return key != "exports/user_1/old.zip"
+				})).Return(nil)
+			},
+			expectedStatus: model.DataExportPending,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			m := newAccountMocks()
+			tt.setupMocks(m)
+			uc := m.usecase()
+
+			output, err := uc.RequestExport(context.Background(), input)
+			require.NoError(t, uc.Wait(context.Background()))
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, output.Export.Status)
+			if tt.expectedOutput != nil {
+				tt.expectedOutput(t, output)
+			}
+			m.assertExpectations(t)
+		})
+	}
+}
+
+func TestAccountUsecaseImpl_Archive(t *testing.T) {
+	avatar := &model.Avatar{Version: "v1", Format: model.AvatarFormatPNG}
+	m := newAccountMocks()
+	m.userRepo.On("FindByID", mock.Anything, "user_1").Return(&model.User{ID: "user_1", Email: "test@example.com", Name: "Test User", NameEdited: true, Avatar: avatar}, nil)
+	m.authRepo.On("GetToken", mock.Anything, "user_1").Return(&model.AuthToken{AccessToken: "secret_access", RefreshToken: "secret_refresh", ExpiresIn: time.Now().Unix()}, nil)
+	m.auditRepo.On("Query", mock.Anything, mock.MatchedBy(func(filter *model.AuditEventFilter) bool {
+		return filter.Cursor == ""
+	})).Return(&model.AuditEventPage{Events: []*model.AuditEvent{{ID: "2", Type: model.AuditEventLogin}}, NextCursor: "2"}, nil)
+	m.auditRepo.On("Query", mock.Anything, mock.MatchedBy(func(filter *model.AuditEventFilter) bool {
+		return filter.Cursor == "2"
+	})).Return(&model.AuditEventPage{Events: []*model.AuditEvent{{ID: "1", Type: model.AuditEventLogin}}}, nil)
+	for _, size := range model.AvatarSizes {
+		m.blobStore.On("Get", mock.Anything, avatar.Key("user_1", size)).Return(&model.Blob{ContentType: "image/png", Data: []byte("png")}, nil)
+	}
+
+	data, err := m.usecase().(*AccountUsecaseImpl).archive(context.Background(), "user_1")
+	require.NoError(t, err)
+
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	files := map[string]string{}
+	for _, file := range reader.File {
+		r, err := file.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(r)
+		require.NoError(t, err)
+		files[file.Name] = string(content)
+	}
+
+	assert.Contains(t, files["user.json"], `"email": "test@example.com"`)
+	assert.Contains(t, files["user.json"], `"name_edited": true`)
+	assert.Contains(t, files["sessions.json"], `"authenticated_at"`)
+	// トークンそのものは含めない
+	assert.NotContains(t, files["sessions.json"], "secret_")
+	assert.Contains(t, files["audit_events.json"], `"id": "2"`)
+	assert.Contains(t, files["audit_events.json"], `"id": "1"`)
+	assert.Equal(t, "png", files["avatar/64.png"])
+	assert.Len(t, files, 3+len(model.AvatarSizes))
+}
+
+func TestAccountUsecaseImpl_PurgeExpiredExports(t *testing.T) {
+	m := newAccountMocks()
+	export := &model.DataExport{ID: "export_1", UserID: "user_1", Status: model.DataExportReady}
+	m.exportRepo.On("FindExpired", mock.Anything, mock.Anything).Return([]*model.DataExport{export}, nil)
+	m.exportRepo.On("FindByUserID", mock.Anything, "user_1").Return(export, nil)
+	m.blobStore.On("Delete", mock.Anything, "exports/user_1/export_1.zip").Return(nil)
+	m.exportRepo.On("DeleteByUserID", mock.Anything, "user_1").Return(nil)
+
+	purged, err := m.usecase().PurgeExpiredExports(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	m.assertExpectations(t)
+}
@@ -0,0 +1,328 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"stackies-backend/core"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/repository"
+	"stackies-backend/domain/service"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidRole             = core.NewAppError("invalid_role", http.StatusBadRequest, "Invalid role")
+	ErrCannotChangeOwnRole     = core.NewAppError("cannot_change_own_role", http.StatusBadRequest, "Cannot change own role")
+	ErrCannotChangeOwnStatus   = core.NewAppError("cannot_change_own_status", http.StatusBadRequest, "Cannot change own status")
+	ErrInvalidStatus           = core.NewAppError("invalid_status", http.StatusBadRequest, "Invalid status")
+	ErrInvalidStatusTransition = core.NewAppError("invalid_status_transition", http.StatusConflict, "Cannot change to the requested status")
+	ErrInvalidUserFilter       = core.NewAppError("invalid_user_filter", http.StatusBadRequest, "Invalid user filter")
+	ErrCannotImpersonateSelf   = core.NewAppError("cannot_impersonate_self", http.StatusBadRequest, "Cannot impersonate yourself")
+	ErrCannotImpersonateAdmin  = core.NewAppError("cannot_impersonate_admin", http.StatusForbidden, "Cannot impersonate an admin")
+	ErrImpersonationReason     = core.NewAppError("impersonation_reason_required", http.StatusBadRequest, "Reason is required to impersonate a user")
+)
+
+// AdminUsecase は管理者向け操作のビジネスロジックを抽象化する
+type AdminUsecase interface {
+	ChangeRole(ctx context.Context, input *ChangeRoleInput) (*model.User, error)
+	ListUsers(ctx context.Context) ([]*model.User, error)
+	SearchUsers(ctx context.Context, input *SearchUsersInput) (*model.UserPage, error)
+	GetUser(ctx context.Context, userID string) (*model.User, error)
+	ChangeStatus(ctx context.Context, input *ChangeStatusInput) (*model.User, error)
+	RevokeSessions(ctx context.Context, input *RevokeSessionsInput) error
+	Impersonate(ctx context.Context, input *ImpersonateInput) (*ImpersonateOutput, error)
+}
+
+type (
+	// ChangeRoleInput はロール変更の入力パラメータを表す
+	ChangeRoleInput struct {
+		ActorID   string
+		UserID    string
+		Role      model.Role
+		ClientIP  string
+		UserAgent string
+	}
+
+	// ChangeStatusInput はユーザーの状態変更の入力パラメータを表す
+	ChangeStatusInput struct {
+		ActorID string
+		UserID  string
+		Status  model.UserStatus
+		// Reason はsuspended・disabledの場合に必須
+		Reason string
+		// Until は一時停止の期限（suspendedの場合のみ指定でき、nilの場合は無期限）
+		Until     *time.Time
+		ClientIP  string
+		UserAgent string
+	}
+
+	// SearchUsersInput はユーザー検索の入力パラメータを表す（空の項目では絞り込まない）
+	SearchUsersInput struct {
+		Status      model.UserStatus
+		Role        model.Role
+		Provider    model.Provider
+		CreatedFrom time.Time
+		CreatedTo   time.Time
+		Query       string
+		Sort        model.UserSort
+		Limit       int
+		Cursor      string
+	}
+
+	// RevokeSessionsInput はセッション無効化の入力パラメータを表す
+	RevokeSessionsInput struct {
+		ActorID   string
+		UserID    string
+		Reason    string
+		ClientIP  string
+		UserAgent string
+	}
+
+	// ImpersonateInput はなりすましの入力パラメータを表す
+	ImpersonateInput struct {
+		ActorID string
+		UserID  string
+		// Reason はサポートの問い合わせ番号など、なりすましの理由（必須）
+		Reason    string
+		ClientIP  string
+		UserAgent string
+	}
+
+	// ImpersonateOutput はなりすましの出力を表す
+	ImpersonateOutput struct {
+		AccessToken string
+		ExpiresAt   time.Time
+		User        *model.User
+	}
+
+	// AdminUsecaseImpl はAdminUsecaseの実装
+	AdminUsecaseImpl struct {
+		userRepo         repository.UserRepository
+		authRepo         repository.AuthRepository
+		auditRepo        repository.AuditRepository
+		events           *EventOutbox
+		jwtSvc           service.JWTService
+		impersonationTTL time.Duration
+	}
+)
+
+// NewAdminUsecase は新しいAdminUsecaseを作成する（eventsがnilの場合、ドメインイベントは保存しない）
+func NewAdminUsecase(
+	userRepo repository.UserRepository,
+	authRepo repository.AuthRepository,
+	auditRepo repository.AuditRepository,
+	events *EventOutbox,
+	jwtSvc service.JWTService,
+	impersonationTTL time.Duration,
+) AdminUsecase {
+	return &AdminUsecaseImpl{
+		userRepo:         userRepo,
+		authRepo:         authRepo,
+		auditRepo:        auditRepo,
+		events:           events,
+		jwtSvc:           jwtSvc,
+		impersonationTTL: impersonationTTL,
+	}
+}
+
+// ChangeRole はユーザーのロールを変更し、監査ログに記録する
+func (a *AdminUsecaseImpl) ChangeRole(ctx context.Context, input *ChangeRoleInput) (*model.User, error) {
+	if !input.Role.IsValid() {
+		return nil, ErrInvalidRole
+	}
+	// 自分自身の降格による管理者不在を防ぐ
+	if input.ActorID == input.UserID {
+		return nil, ErrCannotChangeOwnRole
+	}
+
+	user, err := a.userRepo.FindByID(ctx, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	from := user.Role
+	if from == input.Role {
+		return user, nil
+	}
+
+	// SCIMによる変更は付与元を記録し、管理者グループから外れた場合にのみ戻せるようにする
+	changeRole := user.ChangeRole
+	if input.ActorID == model.SCIMActorID {
+		changeRole = user.ProvisionRole
+	}
+	if err := changeRole(input.Role); err != nil {
+		return nil, err
+	}
+	err = a.events.Write(ctx, func(ctx context.Context) error {
+		return a.userRepo.Update(ctx, user)
+	}, roleChangedEvent(user, from, input.ActorID))
+	if err != nil {
+		return nil, err
+	}
+
+	event := newAuditEvent(model.AuditEventRoleChange, model.AuditOutcomeSuccess, input.ActorID, user.ID, input.ClientIP, input.UserAgent)
+	event.Metadata = map[string]string{"from": string(from), "to": string(user.Role)}
+	recordAuditEvent(ctx, a.auditRepo, event)
+
+	return user, nil
+}
+
+// ListUsers はすべてのユーザーを作成日時の昇順で返す
+func (a *AdminUsecaseImpl) ListUsers(ctx context.Context) ([]*model.User, error) {
+	return a.userRepo.FindAll(ctx)
+}
+
+// SearchUsers は条件に一致するユーザーを指定した並び順で1ページ分返す
+func (a *AdminUsecaseImpl) SearchUsers(ctx context.Context, input *SearchUsersInput) (*model.UserPage, error) {
+	switch {
+	case input.Status != "" && !input.Status.IsValid():
+		return nil, ErrInvalidStatus
+	case input.Role != "" && !input.Role.IsValid():
+		return nil, ErrInvalidRole
+	case input.Provider != "" && !input.Provider.IsValid():
+		return nil, ErrInvalidUserFilter.WithMessage("Invalid provider")
+	case !input.Sort.IsValid():
+		return nil, ErrInvalidUserFilter.WithMessage("Invalid sort")
+	case !input.CreatedFrom.IsZero() && !input.CreatedTo.IsZero() && !input.CreatedFrom.Before(input.CreatedTo):
+		return nil, ErrInvalidUserFilter.WithMessage("created_from must be before created_to")
+	}
+
+	filter := &model.UserFilter{
+		Status:      input.Status,
+		Role:        input.Role,
+		Provider:    input.Provider,
+		CreatedFrom: input.CreatedFrom,
+		CreatedTo:   input.CreatedTo,
+		Query:       input.Query,
+		Sort:        input.Sort,
+		Limit:       input.Limit,
+		Cursor:      input.Cursor,
+	}
+	return a.userRepo.List(ctx, filter)
+}
+
+// GetUser はIDでユーザーを返す
+func (a *AdminUsecaseImpl) GetUser(ctx context.Context, userID string) (*model.User, error) {
+	return a.userRepo.FindByID(ctx, userID)
+}
+
+// ChangeStatus はユーザーの状態を変更してセッションを無効化し、監査ログに記録する
+// 一時停止の解除などを含め、状態を変更した場合は常にセッションを無効化する
+func (a *AdminUsecaseImpl) ChangeStatus(ctx context.Context, input *ChangeStatusInput) (*model.User, error) {
+	if !input.Status.IsValid() {
+		return nil, ErrInvalidStatus
+	}
+	// 自分自身の停止・無効化による管理者不在を防ぐ
+	if input.ActorID == input.UserID {
+		return nil, ErrCannotChangeOwnStatus
+	}
+
+	user, err := a.userRepo.FindByID(ctx, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	// 削除したユーザーは消去を待つのみで、状態は変更できない
+	if user.IsDeleted() {
+		return nil, repository.ErrUserNotFound
+	}
+
+	now := time.Now()
+	from := user.EffectiveStatus(now)
+	if !from.CanTransitionTo(input.Status) {
+		return nil, ErrInvalidStatusTransition
+	}
+	if err := user.ChangeStatus(input.Status, input.Reason, input.Until, now); err != nil {
+		return nil, ErrInvalidStatus.WithMessage(err.Error())
+	}
+	statusChanged := model.NewDomainEvent(model.DomainEventUserStatusChanged, user.ID, input.ActorID,
+		map[string]string{"from": string(from), "to": string(user.Status), "reason": user.StatusReason})
+	err = a.events.Write(ctx, func(ctx context.Context) error {
+		if err := a.userRepo.Update(ctx, user); err != nil {
+			return err
+		}
+		return a.authRepo.DeleteToken(ctx, user.ID)
+	}, statusChanged, sessionRevokedEvent(user.ID, input.ActorID, "status_change"))
+	if err != nil {
+		return nil, err
+	}
+
+	event := newAuditEvent(model.AuditEventUserStatusChange, model.AuditOutcomeSuccess, input.ActorID, user.ID, input.ClientIP, input.UserAgent)
+	event.Reason = user.StatusReason
+	event.Metadata = map[string]string{"from": string(from), "to": string(user.Status)}
+	if user.SuspendedUntil != nil {
+		event.Metadata["until"] = user.SuspendedUntil.UTC().Format(time.RFC3339)
+	}
+	recordAuditEvent(ctx, a.auditRepo, event)
+
+	return user, nil
+}
+
+// RevokeSessions はユーザーのすべてのセッションを無効化し、監査ログに記録する
+// リフレッシュトークンを削除し、発行済みのアクセストークンも認証時に拒否されるようにする
+func (a *AdminUsecaseImpl) RevokeSessions(ctx context.Context, input *RevokeSessionsInput) error {
+	user, err := a.userRepo.FindByID(ctx, input.UserID)
+	if err != nil {
+		return err
+	}
+	user.RevokeSessions(time.Now())
+	err = a.events.Write(ctx, func(ctx context.Context) error {
+		if err := a.userRepo.Update(ctx, user); err != nil {
+			return err
+		}
+		return a.authRepo.DeleteToken(ctx, input.UserID)
+	}, sessionRevokedEvent(input.UserID, input.ActorID, "admin"))
+	if err != nil {
+		return err
+	}
+
+	event := newAuditEvent(model.AuditEventSessionRevoke, model.AuditOutcomeSuccess, input.ActorID, input.UserID, input.ClientIP, input.UserAgent)
+	event.Reason = input.Reason
+	recordAuditEvent(ctx, a.auditRepo, event)
+
+	return nil
+}
+
+// Impersonate は管理者がユーザーになりすますための短期間のアクセストークンを発行し、監査ログに記録する
+// 管理者へのなりすましは権限の取得に使えるため許可しない。リフレッシュトークンは発行しない
+func (a *AdminUsecaseImpl) Impersonate(ctx context.Context, input *ImpersonateInput) (*ImpersonateOutput, error) {
+	reason := strings.TrimSpace(input.Reason)
+	if reason == "" {
+		return nil, ErrImpersonationReason
+	}
+	if input.ActorID == input.UserID {
+		return nil, ErrCannotImpersonateSelf
+	}
+
+	user, err := a.userRepo.FindByID(ctx, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsDeleted() {
+		return nil, repository.ErrUserNotFound
+	}
+	if user.HasRole(model.RoleAdmin) {
+		return nil, ErrCannotImpersonateAdmin
+	}
+	// 停止・無効化されたユーザーのトークンは認証で拒否されるため発行しない
+	if err := CheckUserStatus(user, time.Now()); err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(a.impersonationTTL)
+	accessToken, err := a.jwtSvc.GenerateImpersonationToken(user.ID, input.ActorID, a.impersonationTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	event := newAuditEvent(model.AuditEventImpersonationStart, model.AuditOutcomeSuccess, input.ActorID, user.ID, input.ClientIP, input.UserAgent)
+	event.Reason = reason
+	event.Metadata = map[string]string{"expires_at": expiresAt.UTC().Format(time.RFC3339)}
+	recordAuditEvent(ctx, a.auditRepo, event)
+
+	return &ImpersonateOutput{
+		AccessToken: accessToken,
+		ExpiresAt:   expiresAt,
+		User:        user,
+	}, nil
+}
@@ -0,0 +1,437 @@
+package usecase
+
+import (
+	"context"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAdminUsecaseImpl_ChangeRole(t *testing.T) {
+	tests := []struct {
+		testName      string
+		input         *ChangeRoleInput
+		setupMocks    func(*MockUserRepository, *MockAuditRepository)
+		expectedError error
+		expectedRole  model.Role
+	}{
+		{
+			testName: "一般ユーザーを管理者に昇格",
+			input:    &ChangeRoleInput{ActorID: "admin_1", UserID: "user_1", Role: model.RoleAdmin, ClientIP: "192.0.2.1"},
+			setupMocks: func(userRepo *MockUserRepository, auditRepo *MockAuditRepository) {
+				userRepo.On("FindByID", mock.Anything, "user_1").Return(&model.User{ID: "user_1", Role: model.RoleUser}, nil)
+				userRepo.On("Update", mock.Anything, mock.MatchedBy(func(user *model.User) bool {
+					return user.Role == model.RoleAdmin && !user.RoleProvisioned
+				})).Return(nil)
+				auditRepo.On("Append", mock.Anything, mock.MatchedBy(func(event *model.AuditEvent) bool {
+					return event.Type == model.AuditEventRoleChange &&
+						event.ActorID == "admin_1" &&
+						event.SubjectID == "user_1" &&
+						event.IPAddress == "192.0.2.1" &&
+						event.Metadata["from"] == "user" &&
+						event.Metadata["to"] == "admin"
+				})).Return(nil)
+			},
+			expectedRole: model.RoleAdmin,
+		},
+		{
+			testName: "SCIMによる昇格は付与元を記録する",
+			input:    &ChangeRoleInput{ActorID: model.SCIMActorID, UserID: "user_1", Role: model.RoleAdmin},
+			setupMocks: func(userRepo *MockUserRepository, auditRepo *MockAuditRepository) {
+				userRepo.On("FindByID", mock.Anything, "user_1").Return(&model.User{ID: "user_1", Role: model.RoleUser}, nil)
+				userRepo.On("Update", mock.Anything, mock.MatchedBy(func(user *model.User) bool {
+					return user.Role == model.RoleAdmin && user.RoleProvisioned
+				})).Return(nil)
+				auditRepo.On("Append", mock.Anything, mock.MatchedBy(func(event *model.AuditEvent) bool {
+					return event.Type == model.AuditEventRoleChange && event.ActorID == model.SCIMActorID
+				})).Return(nil)
+			},
+			expectedRole: model.RoleAdmin,
+		},
+		{
+			testName: "同じロールへの変更は記録しない",
+			input:    &ChangeRoleInput{ActorID: "admin_1", UserID: "user_1", Role: model.RoleUser},
+			setupMocks: func(userRepo *MockUserRepository, auditRepo *MockAuditRepository) {
+				userRepo.On("FindByID", mock.Anything, "user_1").Return(&model.User{ID: "user_1", Role: model.RoleUser}, nil)
+			},
+			expectedRole: model.RoleUser,
+		},
+		{
+			testName:      "不正なロール",
+			input:         &ChangeRoleInput{ActorID: "admin_1", UserID: "user_1", Role: "owner"},
+			setupMocks:    func(userRepo *MockUserRepository, auditRepo *MockAuditRepository) {},
+			expectedError: ErrInvalidRole,
+		},
+		{
+			testName:      "自分自身のロールは変更できない",
+			input:         &ChangeRoleInput{ActorID: "admin_1", UserID: "admin_1", Role: model.RoleUser},
+			setupMocks:    func(userRepo *MockUserRepository, auditRepo *MockAuditRepository) {},
+			expectedError: ErrCannotChangeOwnRole,
+		},
+		{
+			testName: "存在しないユーザー",
+			input:    &ChangeRoleInput{ActorID: "admin_1", UserID: "unknown", Role: model.RoleAdmin},
+			setupMocks: func(userRepo *MockUserRepository, auditRepo *MockAuditRepository) {
+				userRepo.On("FindByID", mock.Anything, "unknown").Return((*model.User)(nil), repository.ErrUserNotFound)
+			},
+			expectedError: repository.ErrUserNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			userRepo := new(MockUserRepository)
+			auditRepo := new(MockAuditRepository)
+			tt.setupMocks(userRepo, auditRepo)
+
+			usecase := NewAdminUsecase(userRepo, new(MockAuthRepository), auditRepo, nil, nil, 0)
+			user, err := usecase.ChangeRole(context.Background(), tt.input)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, user)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedRole, user.Role)
+			}
+
+			userRepo.AssertExpectations(t)
+			auditRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAdminUsecaseImpl_ChangeStatus(t *testing.T) {
+	until := time.Now().Add(24 * time.Hour)
+	deletedAt := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		testName       string
+		input          *ChangeStatusInput
+		setupMocks     func(*MockUserRepository, *MockAuthRepository, *MockAuditRepository)
+		expectedError  error
+		expectedStatus model.UserStatus
+	}{
+		{
+			testName: "ユーザーを無効化してセッションを無効化する",
+			input:    &ChangeStatusInput{ActorID: model.SystemActorID, UserID: "user_1", Status: model.UserStatusDisabled, Reason: "left the company"},
+			setupMocks: func(userRepo *MockUserRepository, authRepo *MockAuthRepository, auditRepo *MockAuditRepository) {
+				userRepo.On("FindByID", mock.Anything, "user_1").Return(&model.User{ID: "user_1", Role: model.RoleUser, Status: model.UserStatusActive}, nil)
+				userRepo.On("Update", mock.Anything, mock.MatchedBy(func(user *model.User) bool {
+					return user.Status == model.UserStatusDisabled
+				})).Return(nil)
+				authRepo.On("DeleteToken", mock.Anything, "user_1").Return(nil)
+				auditRepo.On("Append", mock.Anything, mock.MatchedBy(func(event *model.AuditEvent) bool {
+					return event.Type == model.AuditEventUserStatusChange &&
+						event.ActorID == model.SystemActorID &&
+						event.SubjectID == "user_1" &&
+						event.Reason == "left the company" &&
+						event.Metadata["from"] == "active" && event.Metadata["to"] == "disabled"
+				})).Return(nil)
+			},
+			expectedStatus: model.UserStatusDisabled,
+		},
+		{
+			testName: "期限付きで一時停止する",
+			input:    &ChangeStatusInput{ActorID: "admin_1", UserID: "user_1", Status: model.UserStatusSuspended, Reason: "spam", Until: &until},
+			setupMocks: func(userRepo *MockUserRepository, authRepo *MockAuthRepository, auditRepo *MockAuditRepository) {
+				userRepo.On("FindByID", mock.Anything, "user_1").Return(&model.User{ID: "user_1", Status: model.UserStatusActive}, nil)
+				userRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+				authRepo.On("DeleteToken", mock.Anything, "user_1").Return(nil)
+				auditRepo.On("Append", mock.Anything, mock.MatchedBy(func(event *model.AuditEvent) bool {
+					return event.Metadata["to"] == "suspended" && event.Metadata["until"] == until.UTC().Format(time.RFC3339)
+				})).Return(nil)
+			},
+			expectedStatus: model.UserStatusSuspended,
+		},
+		{
+			testName: "一時停止の解除でもセッションを無効化する",
+			input:    &ChangeStatusInput{ActorID: "admin_1", UserID: "user_1", Status: model.UserStatusActive},
+			setupMocks: func(userRepo *MockUserRepository, authRepo *MockAuthRepository, auditRepo *MockAuditRepository) {
+				userRepo.On("FindByID", mock.Anything, "user_1").Return(&model.User{ID: "user_1", Status: model.UserStatusSuspended, StatusReason: "spam"}, nil)
+				userRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+				authRepo.On("DeleteToken", mock.Anything, "user_1").Return(nil)
+				auditRepo.On("Append", mock.Anything, auditEventOf(model.AuditEventUserStatusChange, model.AuditOutcomeSuccess)).Return(nil)
+			},
+			expectedStatus: model.UserStatusActive,
+		},
+		{
+			testName: "無効化済みのユーザーは一時停止できない",
+			input:    &ChangeStatusInput{ActorID: "admin_1", UserID: "user_1", Status: model.UserStatusSuspended, Reason: "spam"},
+			setupMocks: func(userRepo *MockUserRepository, authRepo *MockAuthRepository, auditRepo *MockAuditRepository) {
+				userRepo.On("FindByID", mock.Anything, "user_1").Return(&model.User{ID: "user_1", Status: model.UserStatusDisabled}, nil)
+			},
+			expectedError: ErrInvalidStatusTransition,
+		},
+		{
+			testName: "理由のない無効化",
+			input:    &ChangeStatusInput{ActorID: "admin_1", UserID: "user_1", Status: model.UserStatusDisabled},
+			setupMocks: func(userRepo *MockUserRepository, authRepo *MockAuthRepository, auditRepo *MockAuditRepository) {
+				userRepo.On("FindByID", mock.Anything, "user_1").Return(&model.User{ID: "user_1", Status: model.UserStatusActive}, nil)
+			},
+			expectedError: ErrInvalidStatus,
+		},
+		{
+			testName:      "不明な状態",
+			input:         &ChangeStatusInput{ActorID: "admin_1", UserID: "user_1", Status: "banned"},
+			setupMocks:    func(userRepo *MockUserRepository, authRepo *MockAuthRepository, auditRepo *MockAuditRepository) {},
+			expectedError: ErrInvalidStatus,
+		},
+		{
+			testName:      "自分自身の状態は変更できない",
+			input:         &ChangeStatusInput{ActorID: "admin_1", UserID: "admin_1", Status: model.UserStatusDisabled, Reason: "test"},
+			setupMocks:    func(userRepo *MockUserRepository, authRepo *MockAuthRepository, auditRepo *MockAuditRepository) {},
+			expectedError: ErrCannotChangeOwnStatus,
+		},
+		{
+			testName: "削除したユーザー",
+			input:    &ChangeStatusInput{ActorID: "admin_1", UserID: "user_1", Status: model.UserStatusDisabled, Reason: "abuse"},
+			setupMocks: func(userRepo *MockUserRepository, authRepo *MockAuthRepository, auditRepo *MockAuditRepository) {
+				userRepo.On("FindByID", mock.Anything, "user_1").Return(&model.User{ID: "user_1", DeletedAt: &deletedAt}, nil)
+			},
+			expectedError: repository.ErrUserNotFound,
+		},
+		{
+			testName: "存在しないユーザー",
+			input:    &ChangeStatusInput{ActorID: "admin_1", UserID: "unknown", Status: model.UserStatusDisabled, Reason: "abuse"},
+			setupMocks: func(userRepo *MockUserRepository, authRepo *MockAuthRepository, auditRepo *MockAuditRepository) {
+				userRepo.On("FindByID", mock.Anything, "unknown").Return((*model.User)(nil), repository.ErrUserNotFound)
+			},
+			expectedError: repository.ErrUserNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			userRepo := new(MockUserRepository)
+			authRepo := new(MockAuthRepository)
+			auditRepo := new(MockAuditRepository)
+			tt.setupMocks(userRepo, authRepo, auditRepo)
+
+			usecase := NewAdminUsecase(userRepo, authRepo, auditRepo, nil, nil, 0)
+			user, err := usecase.ChangeStatus(context.Background(), tt.input)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, user)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedStatus, user.Status)
+			}
+
+			userRepo.AssertExpectations(t)
+			authRepo.AssertExpectations(t)
+			auditRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAdminUsecaseImpl_SearchUsers(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		testName      string
+		input         *SearchUsersInput
+		expectList    bool
+		expectedError error
+	}{
+		{
+			testName: "検索条件をそのままリポジトリに渡す",
+			input: &SearchUsersInput{
+				Status:      model.UserStatusSuspended,
+				Role:        model.RoleUser,
+				Provider:    model.ProviderGoogle,
+				CreatedFrom: now.Add(-time.Hour),
+				CreatedTo:   now,
+				Query:       "ali",
+				Sort:        model.UserSortEmailDesc,
+				Limit:       10,
+				Cursor:      "user_1",
+			},
+			expectList: true,
+		},
+		{testName: "不正な状態", input: &SearchUsersInput{Status: "deleted"}, expectedError: ErrInvalidStatus},
+		{testName: "不正なロール", input: &SearchUsersInput{Role: "owner"}, expectedError: ErrInvalidRole},
+		{testName: "不正なIDプロバイダー", input: &SearchUsersInput{Provider: "github"}, expectedError: ErrInvalidUserFilter},
+		{testName: "不正な並び順", input: &SearchUsersInput{Sort: "role"}, expectedError: ErrInvalidUserFilter},
+		{testName: "作成日時の範囲が逆", input: &SearchUsersInput{CreatedFrom: now, CreatedTo: now.Add(-time.Hour)}, expectedError: ErrInvalidUserFilter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			userRepo := new(MockUserRepository)
+			page := &model.UserPage{Users: []*model.User{{ID: "user_2"}}}
+			if tt.expectList {
+				userRepo.On("List", mock.Anything, &model.UserFilter{
+					Status:      tt.input.Status,
+					Role:        tt.input.Role,
+					Provider:    tt.input.Provider,
+					CreatedFrom: tt.input.CreatedFrom,
+					CreatedTo:   tt.input.CreatedTo,
+					Query:       tt.input.Query,
+					Sort:        tt.input.Sort,
+					Limit:       tt.input.Limit,
+					Cursor:      tt.input.Cursor,
+				}).Return(page, nil)
+			}
+
+			usecase := NewAdminUsecase(userRepo, new(MockAuthRepository), new(MockAuditRepository), nil, nil, 0)
+			result, err := usecase.SearchUsers(context.Background(), tt.input)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Same(t, page, result)
+			}
+			userRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAdminUsecaseImpl_RevokeSessions(t *testing.T) {
+	tests := []struct {
+		testName      string
+		input         *RevokeSessionsInput
+		setupMocks    func(*MockUserRepository, *MockAuthRepository, *MockAuditRepository)
+		expectedError error
+	}{
+		{
+			testName: "ユーザーのセッションを無効化する",
+			input:    &RevokeSessionsInput{ActorID: model.SystemActorID, UserID: "user_1", Reason: "cli"},
+			setupMocks: func(userRepo *MockUserRepository, authRepo *MockAuthRepository, auditRepo *MockAuditRepository) {
+				userRepo.On("FindByID", mock.Anything, "user_1").Return(&model.User{ID: "user_1"}, nil)
+				userRepo.On("Update", mock.Anything, mock.MatchedBy(func(user *model.User) bool {
+					return user.SessionsRevokedAt != nil
+				})).Return(nil)
+				authRepo.On("DeleteToken", mock.Anything, "user_1").Return(nil)
+				auditRepo.On("Append", mock.Anything, mock.MatchedBy(func(event *model.AuditEvent) bool {
+					return event.Type == model.AuditEventSessionRevoke &&
+						event.ActorID == model.SystemActorID &&
+						event.SubjectID == "user_1" &&
+						event.Reason == "cli"
+				})).Return(nil)
+			},
+		},
+		{
+			testName: "存在しないユーザー",
+			input:    &RevokeSessionsInput{ActorID: model.SystemActorID, UserID: "unknown"},
+			setupMocks: func(userRepo *MockUserRepository, authRepo *MockAuthRepository, auditRepo *MockAuditRepository) {
+				userRepo.On("FindByID", mock.Anything, "unknown").Return((*model.User)(nil), repository.ErrUserNotFound)
+			},
+			expectedError: repository.ErrUserNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			userRepo := new(MockUserRepository)
+			authRepo := new(MockAuthRepository)
+			auditRepo := new(MockAuditRepository)
+			tt.setupMocks(userRepo, authRepo, auditRepo)
+
+			usecase := NewAdminUsecase(userRepo, authRepo, auditRepo, nil, nil, 0)
+			err := usecase.RevokeSessions(context.Background(), tt.input)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			userRepo.AssertExpectations(t)
+			authRepo.AssertExpectations(t)
+			auditRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAdminUsecaseImpl_Impersonate(t *testing.T) {
+	deletedAt := time.Now()
+
+	tests := []struct {
+		testName      string
+		input         *ImpersonateInput
+		user          *model.User
+		expectToken   bool
+		expectedError error
+	}{
+		{
+			testName:    "なりすましのトークンを発行して監査ログに記録する",
+			input:       &ImpersonateInput{ActorID: "admin_1", UserID: "user_1", Reason: " ticket #42 ", ClientIP: "192.0.2.1"},
+			user:        &model.User{ID: "user_1", Role: model.RoleUser, Status: model.UserStatusActive},
+			expectToken: true,
+		},
+		{
+			testName:      "理由がない",
+			input:         &ImpersonateInput{ActorID: "admin_1", UserID: "user_1", Reason: " "},
+			expectedError: ErrImpersonationReason,
+		},
+		{
+			testName:      "自分自身にはなりすませない",
+			input:         &ImpersonateInput{ActorID: "admin_1", UserID: "admin_1", Reason: "test"},
+			expectedError: ErrCannotImpersonateSelf,
+		},
+		{
+			testName:      "管理者にはなりすませない",
+			input:         &ImpersonateInput{ActorID: "admin_1", UserID: "admin_2", Reason: "test"},
+			user:          &model.User{ID: "admin_2", Role: model.RoleAdmin, Status: model.UserStatusActive},
+			expectedError: ErrCannotImpersonateAdmin,
+		},
+		{
+			testName:      "停止されたユーザーにはなりすませない",
+			input:         &ImpersonateInput{ActorID: "admin_1", UserID: "user_1", Reason: "test"},
+			user:          &model.User{ID: "user_1", Role: model.RoleUser, Status: model.UserStatusSuspended},
+			expectedError: ErrUserSuspended,
+		},
+		{
+			testName:      "削除済みのユーザー",
+			input:         &ImpersonateInput{ActorID: "admin_1", UserID: "user_1", Reason: "test"},
+			user:          &model.User{ID: "user_1", Role: model.RoleUser, DeletedAt: &deletedAt},
+			expectedError: repository.ErrUserNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			userRepo := new(MockUserRepository)
+			jwtSvc := new(MockJWTService)
+			auditRepo := new(MockAuditRepository)
+			if tt.user != nil {
+				userRepo.On("FindByID", mock.Anything, tt.input.UserID).Return(tt.user, nil)
+			}
+			if tt.expectToken {
+				jwtSvc.On("GenerateImpersonationToken", "user_1", "admin_1", 15*time.Minute).Return("impersonation_token", nil)
+				auditRepo.On("Append", mock.Anything, mock.MatchedBy(func(event *model.AuditEvent) bool {
+					return event.Type == model.AuditEventImpersonationStart &&
+						event.ActorID == "admin_1" &&
+						event.SubjectID == "user_1" &&
+						event.Reason == "ticket #42" &&
+						event.IPAddress == "192.0.2.1" &&
+						event.Metadata["expires_at"] != ""
+				})).Return(nil)
+			}
+
+			usecase := NewAdminUsecase(userRepo, new(MockAuthRepository), auditRepo, nil, jwtSvc, 15*time.Minute)
+			output, err := usecase.Impersonate(context.Background(), tt.input)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, output)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "impersonation_token", output.AccessToken)
+				assert.Equal(t, tt.user, output.User)
+				assert.WithinDuration(t, time.Now().Add(15*time.Minute), output.ExpiresAt, time.Second)
+			}
+			userRepo.AssertExpectations(t)
+			jwtSvc.AssertExpectations(t)
+			auditRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/repository"
+	"time"
+)
+
+// recordAuditEvent は監査イベントを記録する
+// 記録に失敗しても本来の処理は継続させ、失敗はログに残す
+func recordAuditEvent(ctx context.Context, auditRepo repository.AuditRepository, event *model.AuditEvent) {
+	if auditRepo == nil || event == nil {
+		return
+	}
+	if err := auditRepo.Append(ctx, event); err != nil {
+		slog.ErrorContext(ctx, "failed to record audit event", slog.String("event_type", string(event.Type)), slog.Any("error", err))
+	}
+}
+
+// newAuditEvent はリクエスト元の情報を付与した監査イベントを作成する
+func newAuditEvent(eventType model.AuditEventType, outcome model.AuditOutcome, actorID, subjectID, clientIP, userAgent string) *model.AuditEvent {
+	return &model.AuditEvent{
+		Type:      eventType,
+		Outcome:   outcome,
+		ActorID:   actorID,
+		SubjectID: subjectID,
+		IPAddress: clientIP,
+		UserAgent: userAgent,
+		CreatedAt: time.Now(),
+	}
+}
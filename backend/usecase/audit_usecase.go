@@ -0,0 +1,54 @@
+package usecase
+
+import (
+	"context"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/repository"
+	"time"
+)
+
+// AuditUsecase は監査ログの参照に関するビジネスロジックを抽象化する
+type AuditUsecase interface {
+	ListEvents(ctx context.Context, input *ListAuditEventsInput) (*model.AuditEventPage, error)
+}
+
+type (
+	// ListAuditEventsInput は監査ログ検索の入力パラメータを表す
+	ListAuditEventsInput struct {
+		UserID  string
+		ActorID string
+		Type    model.AuditEventType
+		Outcome model.AuditOutcome
+		From    time.Time
+		To      time.Time
+		Limit   int
+		Cursor  string
+	}
+
+	// AuditUsecaseImpl はAuditUsecaseの実装
+	AuditUsecaseImpl struct {
+		auditRepo repository.AuditRepository
+	}
+)
+
+// NewAuditUsecase は新しいAuditUsecaseを作成する
+func NewAuditUsecase(auditRepo repository.AuditRepository) AuditUsecase {
+	return &AuditUsecaseImpl{
+		auditRepo: auditRepo,
+	}
+}
+
+// ListEvents は条件に一致する監査イベントを新しい順に返す
+func (a *AuditUsecaseImpl) ListEvents(ctx context.Context, input *ListAuditEventsInput) (*model.AuditEventPage, error) {
+	filter := &model.AuditEventFilter{
+		UserID:  input.UserID,
+		ActorID: input.ActorID,
+		Type:    input.Type,
+		Outcome: input.Outcome,
+		From:    input.From,
+		To:      input.To,
+		Limit:   input.Limit,
+		Cursor:  input.Cursor,
+	}
+	return a.auditRepo.Query(ctx, filter)
+}
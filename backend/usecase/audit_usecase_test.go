@@ -0,0 +1,43 @@
+package usecase
+
+import (
+	"context"
+	"stackies-backend/domain/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAuditUsecaseImpl_ListEvents(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	page := &model.AuditEventPage{
+		Events:     []*model.AuditEvent{{ID: "event_1", Type: model.AuditEventLogin}},
+		NextCursor: "event_1",
+	}
+
+	auditRepo := new(MockAuditRepository)
+	auditRepo.On("Query", mock.Anything, &model.AuditEventFilter{
+		UserID:  "user_1",
+		Type:    model.AuditEventLogin,
+		Outcome: model.AuditOutcomeFailure,
+		From:    from,
+		Limit:   10,
+		Cursor:  "event_0",
+	}).Return(page, nil)
+
+	usecase := NewAuditUsecase(auditRepo)
+	result, err := usecase.ListEvents(context.Background(), &ListAuditEventsInput{
+		UserID:  "user_1",
+		Type:    model.AuditEventLogin,
+		Outcome: model.AuditOutcomeFailure,
+		From:    from,
+		Limit:   10,
+		Cursor:  "event_0",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, page, result)
+	auditRepo.AssertExpectations(t)
+}
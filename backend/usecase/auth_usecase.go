@@ -3,12 +3,50 @@ package usecase
 import (
 	"context"
 	"errors"
+	"log/slog"
+	"net/http"
+	"stackies-backend/core"
+	"stackies-backend/core/tracing"
 	"stackies-backend/domain/model"
 	"stackies-backend/domain/repository"
 	"stackies-backend/domain/service"
+	"strconv"
+	"strings"
 	"time"
 )
 
+var (
+	ErrInvalidAuthorizationCode = core.NewAppError("invalid_authorization_code", http.StatusBadRequest, "Invalid authorization code")
+	ErrIdentityProviderFailed   = core.NewAppError("identity_provider_error", http.StatusBadGateway, "Failed to fetch user info from identity provider")
+	ErrEmailNotVerified         = core.NewAppError("email_not_verified", http.StatusForbidden, "Email address is not verified")
+	ErrInvalidRefreshToken      = core.NewAppError("invalid_refresh_token", http.StatusUnauthorized, "Invalid refresh token")
+	ErrUserDisabled             = core.NewAppError("user_disabled", http.StatusForbidden, "User is disabled")
+	ErrUserSuspended            = core.NewAppError("user_suspended", http.StatusForbidden, "User is suspended")
+	ErrUserPendingVerification  = core.NewAppError("user_pending_verification", http.StatusForbidden, "User has not signed in with a verified email yet")
+	ErrUserDeleted              = core.NewAppError("user_deleted", http.StatusForbidden, "User is scheduled for deletion")
+)
+
+// CheckUserStatus はユーザーがログイン・APIを利用できる状態かを確認し、できない場合は理由に応じたエラーを返す
+func CheckUserStatus(user *model.User, now time.Time) error {
+	if user.IsDeleted() {
+		return ErrUserDeleted
+	}
+
+	switch user.EffectiveStatus(now) {
+	case model.UserStatusActive:
+		return nil
+	case model.UserStatusSuspended:
+		if user.SuspendedUntil != nil {
+			return ErrUserSuspended.WithMessage("User is suspended until " + user.SuspendedUntil.UTC().Format(time.RFC3339))
+		}
+		return ErrUserSuspended
+	case model.UserStatusPendingVerification:
+		return ErrUserPendingVerification
+	default:
+		return ErrUserDisabled
+	}
+}
+
 // AuthUsecase は認証関連のビジネスロジックを抽象化する
 type AuthUsecase interface {
 	GoogleLogin(ctx context.Context, input *GoogleLoginInput) (*GoogleLoginOutput, error)
@@ -21,6 +59,8 @@ type (
 	GoogleLoginInput struct {
 		AuthorizationCode string
 		RedirectURI       string
+		ClientIP          string
+		UserAgent         string
 	}
 
 	// GoogleLoginOutput はGoogleログインの出力パラメータを表す
@@ -34,6 +74,8 @@ type (
 	// RefreshTokenInput はトークンリフレッシュの入力パラメータを表す
 	RefreshTokenInput struct {
 		RefreshToken string
+		ClientIP     string
+		UserAgent    string
 	}
 
 	// RefreshTokenOutput はトークンリフレッシュの出力パラメータを表す
@@ -45,77 +87,194 @@ type (
 
 	// LogoutInput はログアウトの入力パラメータを表す
 	LogoutInput struct {
-		UserID string
+		UserID    string
+		ClientIP  string
+		UserAgent string
 	}
 
 	// AuthUsecaseImpl はAuthUsecaseの実装
 	AuthUsecaseImpl struct {
-		userRepo  repository.UserRepository
-		authRepo  repository.AuthRepository
-		googleSvc service.GoogleService
-		jwtSvc    service.JWTService
+		userRepo    repository.UserRepository
+		authRepo    repository.AuthRepository
+		auditRepo   repository.AuditRepository
+		events      *EventOutbox
+		googleSvc   service.GoogleService
+		jwtSvc      service.JWTService
+		loginRisk   LoginRiskUsecase
+		adminEmails map[string]bool
 	}
 )
 
 // NewAuthUsecase は新しいAuthUsecaseを作成する
+// eventsがnilの場合、ドメインイベントは保存しない
+// loginRiskがnilの場合、ログインのリスク判定は行わない
+// adminEmailsに含まれるメールアドレスのユーザーはログイン時に管理者ロールが付与される
 func NewAuthUsecase(
 	userRepo repository.UserRepository,
 	authRepo repository.AuthRepository,
+	auditRepo repository.AuditRepository,
+	events *EventOutbox,
 	googleSvc service.GoogleService,
 	jwtSvc service.JWTService,
+	loginRisk LoginRiskUsecase,
+	adminEmails []string,
 ) AuthUsecase {
+	admins := make(map[string]bool, len(adminEmails))
+	for _, email := range adminEmails {
+		if email = strings.ToLower(strings.TrimSpace(email)); email != "" {
+			admins[email] = true
+		}
+	}
+
 	return &AuthUsecaseImpl{
-		userRepo:  userRepo,
-		authRepo:  authRepo,
-		googleSvc: googleSvc,
-		jwtSvc:    jwtSvc,
+		userRepo:    userRepo,
+		authRepo:    authRepo,
+		auditRepo:   auditRepo,
+		events:      events,
+		googleSvc:   googleSvc,
+		jwtSvc:      jwtSvc,
+		loginRisk:   loginRisk,
+		adminEmails: admins,
 	}
 }
 
-// GoogleLogin はGoogle OAuth2.0を使用したログインを処理する
+// GoogleLogin はGoogle OAuth2.0を使用したログインを処理し、結果を監査ログに記録する
 func (a *AuthUsecaseImpl) GoogleLogin(ctx context.Context, input *GoogleLoginInput) (*GoogleLoginOutput, error) {
+	ctx, span := tracer.Start(ctx, "AuthUsecase.GoogleLogin")
+	defer span.End()
+
+	output, err := a.googleLogin(ctx, input)
+	tracing.RecordError(span, err)
+
+	event := newAuditEvent(model.AuditEventLogin, model.AuditOutcomeSuccess, "", "", input.ClientIP, input.UserAgent)
+	event.Metadata = map[string]string{"provider": "google"}
+	if err != nil {
+		event.Outcome = model.AuditOutcomeFailure
+		event.Reason = err.Error()
+	} else {
+		event.ActorID = output.User.ID
+		event.SubjectID = output.User.ID
+		event.Metadata["device"] = model.DeviceFingerprint(input.UserAgent)
+		a.assessLoginRisk(ctx, output.User, input, event)
+	}
+	recordAuditEvent(ctx, a.auditRepo, event)
+
+	return output, err
+}
+
+// assessLoginRisk はログインのリスクを判定し、結果を監査イベントに付与する
+// 判定に失敗してもログイン自体は継続させる
+func (a *AuthUsecaseImpl) assessLoginRisk(ctx context.Context, user *model.User, input *GoogleLoginInput, event *model.AuditEvent) {
+	if a.loginRisk == nil {
+		return
+	}
+
+	assessment, err := a.loginRisk.Evaluate(ctx, &EvaluateLoginRiskInput{
+		User:      user,
+		ClientIP:  input.ClientIP,
+		UserAgent: input.UserAgent,
+	})
+	if err != nil {
+		slog.WarnContext(ctx, "failed to evaluate login risk", slog.String("subject_id", user.ID), slog.Any("error", err))
+		return
+	}
+
+	event.Metadata["risk_score"] = strconv.Itoa(assessment.Score)
+	event.Metadata["risk_level"] = string(assessment.Level)
+	if len(assessment.Signals) > 0 {
+		signals := make([]string, len(assessment.Signals))
+		for i, signal := range assessment.Signals {
+			signals[i] = string(signal)
+		}
+		event.Metadata["risk_signals"] = strings.Join(signals, ",")
+	}
+}
+
+// googleLogin はGoogleログインの本体処理
+func (a *AuthUsecaseImpl) googleLogin(ctx context.Context, input *GoogleLoginInput) (*GoogleLoginOutput, error) {
 	// 1. 認証コードをアクセストークンに交換
 	googleToken, err := a.googleSvc.ExchangeCode(ctx, input.AuthorizationCode, input.RedirectURI)
 	if err != nil {
-		return nil, err
+		return nil, ErrInvalidAuthorizationCode.Wrap(err)
 	}
 
 	// 2. Googleからユーザー情報を取得
 	googleUser, err := a.googleSvc.GetUserInfo(ctx, googleToken.AccessToken)
 	if err != nil {
-		return nil, err
+		return nil, ErrIdentityProviderFailed.Wrap(err)
 	}
 
 	// 3. メールアドレスが認証済みかチェック
 	if !googleUser.IsVerified() {
-		return nil, errors.New("email not verified")
+		return nil, ErrEmailNotVerified
 	}
 
 	// 4. 既存ユーザーかどうかチェック
 	var user *model.User
 	existingUser, err := a.userRepo.FindByEmail(ctx, googleUser.Email)
-	if err != nil && err != repository.ErrUserNotFound {
+	if err != nil && !errors.Is(err, repository.ErrUserNotFound) {
 		return nil, err
 	}
 
 	if existingUser != nil {
-		// 既存ユーザーの場合、プロフィールを更新
-		err = existingUser.UpdateProfile(googleUser.Name, googleUser.Picture)
+		// 管理者が登録したユーザーは、本人が認証済みのメールアドレスでログインした時点で有効化する
+		now := time.Now()
+		from := existingUser.EffectiveStatus(now)
+		activated := from == model.UserStatusPendingVerification && !existingUser.IsDeleted()
+		if activated {
+			if err := existingUser.ChangeStatus(model.UserStatusActive, "", nil, now); err != nil {
+				return nil, err
+			}
+		}
+		// 停止・無効化されたユーザーと、削除したユーザー（消去されるまで）はログイン・再登録できない
+		if err := CheckUserStatus(existingUser, now); err != nil {
+			return nil, err
+		}
+		// 既存ユーザーの場合、ユーザーが編集していない項目をGoogleのプロフィールで更新
+		err = existingUser.SyncProfile(googleUser.Name, googleUser.Picture)
 		if err != nil {
 			return nil, err
 		}
-		err = a.userRepo.Update(ctx, existingUser)
+		promoted := a.promoteAdmin(existingUser)
+		var events []*model.DomainEvent
+		if activated {
+			events = append(events, model.NewDomainEvent(model.DomainEventUserStatusChanged, existingUser.ID, existingUser.ID,
+				map[string]string{"from": string(from), "to": string(existingUser.Status)}))
+		}
+		if promoted {
+			events = append(events, roleChangedEvent(existingUser, model.RoleUser, model.SystemActorID))
+		}
+		err = a.events.Write(ctx, func(ctx context.Context) error {
+			return a.userRepo.Update(ctx, existingUser)
+		}, events...)
 		if err != nil {
 			return nil, err
 		}
 		user = existingUser
+		if activated {
+			event := newAuditEvent(model.AuditEventUserStatusChange, model.AuditOutcomeSuccess, user.ID, user.ID, input.ClientIP, input.UserAgent)
+			event.Reason = "signed in with a verified email"
+			event.Metadata = map[string]string{"from": string(from), "to": string(user.Status)}
+			recordAuditEvent(ctx, a.auditRepo, event)
+		}
+		if promoted {
+			a.recordRoleChange(ctx, user, model.RoleUser, input.ClientIP, input.UserAgent)
+		}
 	} else {
 		// 新規ユーザーの場合、作成
 		user = googleUser.ToUser()
-		err = a.userRepo.Save(ctx, user)
+		promoted := a.promoteAdmin(user)
+		registered := model.NewDomainEvent(model.DomainEventUserRegistered, user.ID, user.ID,
+			map[string]string{"email": user.Email, "provider": string(user.Provider), "role": string(user.Role)})
+		err = a.events.Write(ctx, func(ctx context.Context) error {
+			return a.userRepo.Save(ctx, user)
+		}, registered)
 		if err != nil {
 			return nil, err
 		}
+		if promoted {
+			a.recordRoleChange(ctx, user, model.RoleUser, input.ClientIP, input.UserAgent)
+		}
 	}
 
 	// 5. JWTトークンを生成
@@ -129,14 +288,18 @@ func (a *AuthUsecaseImpl) GoogleLogin(ctx context.Context, input *GoogleLoginInp
 		return nil, err
 	}
 
-	// 6. トークンを保存
+	// 6. トークンを保存（アカウントの削除などで要求する直近の認証の判定のため、認証日時を記録する）
 	expiresIn := time.Now().Add(time.Hour).Unix()
 	authToken, err := model.NewAuthToken(accessToken, refreshToken, expiresIn, "Bearer")
 	if err != nil {
 		return nil, err
 	}
+	authToken.AuthenticatedAt = time.Now()
 
-	err = a.authRepo.SaveToken(ctx, user.ID, authToken)
+	loggedIn := model.NewDomainEvent(model.DomainEventUserLoggedIn, user.ID, user.ID, map[string]string{"provider": string(user.Provider)})
+	err = a.events.Write(ctx, func(ctx context.Context) error {
+		return a.authRepo.SaveToken(ctx, user.ID, authToken)
+	}, loggedIn)
 	if err != nil {
 		return nil, err
 	}
@@ -150,14 +313,76 @@ func (a *AuthUsecaseImpl) GoogleLogin(ctx context.Context, input *GoogleLoginInp
 }
 
 // RefreshToken はリフレッシュトークンを使用してアクセストークンを更新する
-func (a *AuthUsecaseImpl) RefreshToken(ctx context.Context, input *RefreshTokenInput) (*RefreshTokenOutput, error) {
+func (a *AuthUsecaseImpl) RefreshToken(ctx context.Context, input *RefreshTokenInput) (_ *RefreshTokenOutput, err error) {
+	ctx, span := tracer.Start(ctx, "AuthUsecase.RefreshToken")
+	defer func() {
+		tracing.RecordError(span, err)
+		span.End()
+	}()
+
 	// 1. リフレッシュトークンを検証
 	userID, err := a.jwtSvc.ValidateToken(input.RefreshToken)
 	if err != nil {
-		return nil, errors.New("invalid refresh token")
+		event := newAuditEvent(model.AuditEventRefresh, model.AuditOutcomeFailure, "", "", input.ClientIP, input.UserAgent)
+		event.Reason = "invalid refresh token"
+		recordAuditEvent(ctx, a.auditRepo, event)
+		return nil, ErrInvalidRefreshToken.Wrap(err)
+	}
+
+	// 2. 保存済みのトークンと照合（ローテーション済みのトークンの再利用を検知）
+	stored, err := a.authRepo.GetToken(ctx, userID)
+	if err != nil {
+		if !errors.Is(err, repository.ErrTokenNotFound) {
+			return nil, err
+		}
+		event := newAuditEvent(model.AuditEventRefresh, model.AuditOutcomeFailure, userID, userID, input.ClientIP, input.UserAgent)
+		event.Reason = "session not found"
+		recordAuditEvent(ctx, a.auditRepo, event)
+		return nil, ErrInvalidRefreshToken.Wrap(err)
+	}
+	if stored.RefreshToken != input.RefreshToken {
+		// 盗まれたトークンが使われた可能性があるため、セッションごと無効化する
+		// 盗まれたトークンで取得したアクセストークンも拒否されるよう、無効化した日時を記録する
+		user, err := a.userRepo.FindByID(ctx, userID)
+		if err != nil && !errors.Is(err, repository.ErrUserNotFound) {
+			return nil, err
+		}
+		if user != nil {
+			user.RevokeSessions(time.Now())
+		}
+		err = a.events.Write(ctx, func(ctx context.Context) error {
+			if user != nil {
+				if err := a.userRepo.Update(ctx, user); err != nil {
+					return err
+				}
+			}
+			return a.authRepo.DeleteToken(ctx, userID)
+		}, sessionRevokedEvent(userID, userID, "token_reuse"))
+		if err != nil {
+			return nil, err
+		}
+		event := newAuditEvent(model.AuditEventTokenReuse, model.AuditOutcomeFailure, userID, userID, input.ClientIP, input.UserAgent)
+		event.Reason = "rotated refresh token was reused; session revoked"
+		recordAuditEvent(ctx, a.auditRepo, event)
+		return nil, ErrInvalidRefreshToken
+	}
+
+	// 3. 停止・無効化されたユーザーはリフレッシュできない
+	user, err := a.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrInvalidRefreshToken.Wrap(err)
+		}
+		return nil, err
+	}
+	if err := CheckUserStatus(user, time.Now()); err != nil {
+		event := newAuditEvent(model.AuditEventRefresh, model.AuditOutcomeFailure, userID, userID, input.ClientIP, input.UserAgent)
+		event.Reason = err.Error()
+		recordAuditEvent(ctx, a.auditRepo, event)
+		return nil, err
 	}
 
-	// 2. 新しいトークンを生成
+	// 4. 新しいトークンを生成
 	accessToken, err := a.jwtSvc.GenerateToken(userID)
 	if err != nil {
 		return nil, err
@@ -168,18 +393,21 @@ func (a *AuthUsecaseImpl) RefreshToken(ctx context.Context, input *RefreshTokenI
 		return nil, err
 	}
 
-	// 3. トークンを保存
+	// 5. トークンを保存（リフレッシュはIdPでの認証ではないため、認証日時は引き継ぐ）
 	expiresIn := time.Now().Add(time.Hour).Unix()
 	authToken, err := model.NewAuthToken(accessToken, refreshToken, expiresIn, "Bearer")
 	if err != nil {
 		return nil, err
 	}
+	authToken.AuthenticatedAt = stored.AuthenticatedAt
 
 	err = a.authRepo.SaveToken(ctx, userID, authToken)
 	if err != nil {
 		return nil, err
 	}
 
+	recordAuditEvent(ctx, a.auditRepo, newAuditEvent(model.AuditEventRefresh, model.AuditOutcomeSuccess, userID, userID, input.ClientIP, input.UserAgent))
+
 	return &RefreshTokenOutput{
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
@@ -189,6 +417,34 @@ func (a *AuthUsecaseImpl) RefreshToken(ctx context.Context, input *RefreshTokenI
 
 // Logout はユーザーのログアウトを処理する
 func (a *AuthUsecaseImpl) Logout(ctx context.Context, input *LogoutInput) error {
+	ctx, span := tracer.Start(ctx, "AuthUsecase.Logout")
+	defer span.End()
+
 	// トークンを削除
-	return a.authRepo.DeleteToken(ctx, input.UserID)
+	err := a.events.Write(ctx, func(ctx context.Context) error {
+		return a.authRepo.DeleteToken(ctx, input.UserID)
+	}, sessionRevokedEvent(input.UserID, input.UserID, "logout"))
+	if err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+
+	recordAuditEvent(ctx, a.auditRepo, newAuditEvent(model.AuditEventLogout, model.AuditOutcomeSuccess, input.UserID, input.UserID, input.ClientIP, input.UserAgent))
+	return nil
+}
+
+// promoteAdmin は管理者として設定されたメールアドレスのユーザーに管理者ロールを付与する
+func (a *AuthUsecaseImpl) promoteAdmin(user *model.User) bool {
+	if user.HasRole(model.RoleAdmin) || !a.adminEmails[strings.ToLower(user.Email)] {
+		return false
+	}
+	return user.ChangeRole(model.RoleAdmin) == nil
+}
+
+// recordRoleChange は設定によるロール変更を監査ログに記録する
+func (a *AuthUsecaseImpl) recordRoleChange(ctx context.Context, user *model.User, from model.Role, clientIP, userAgent string) {
+	event := newAuditEvent(model.AuditEventRoleChange, model.AuditOutcomeSuccess, model.SystemActorID, user.ID, clientIP, userAgent)
+	event.Reason = "granted by admin email configuration"
+	event.Metadata = map[string]string{"from": string(from), "to": string(user.Role)}
+	recordAuditEvent(ctx, a.auditRepo, event)
 }
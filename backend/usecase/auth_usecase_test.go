@@ -11,6 +11,12 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
 // MockUserRepository はUserRepositoryのモック
@@ -46,6 +52,27 @@ func (m *MockUserRepository) Update(ctx context.Context, user *model.User) error
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) FindAll(ctx context.Context) ([]*model.User, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.User), args.Error(1)
+}
+
+func (m *MockUserRepository) List(ctx context.Context, filter *model.UserFilter) (*model.UserPage, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.UserPage), args.Error(1)
+}
+
+func (m *MockUserRepository) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
 // MockAuthRepository はAuthRepositoryのモック
 type MockAuthRepository struct {
 	mock.Mock
@@ -76,6 +103,43 @@ func (m *MockAuthRepository) ValidateToken(ctx context.Context, token string) (s
 	return args.String(0), args.Error(1)
 }
 
+func (m *MockAuthRepository) CountTokens(ctx context.Context) (int, error) {
+	args := m.Called(ctx)
+	return args.Int(0), args.Error(1)
+}
+
+// MockAuditRepository はAuditRepositoryのモック
+type MockAuditRepository struct {
+	mock.Mock
+}
+
+var _ repository.AuditRepository = (*MockAuditRepository)(nil)
+
+func (m *MockAuditRepository) Append(ctx context.Context, event *model.AuditEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (m *MockAuditRepository) Query(ctx context.Context, filter *model.AuditEventFilter) (*model.AuditEventPage, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.AuditEventPage), args.Error(1)
+}
+
+func (m *MockAuditRepository) Pseudonymize(ctx context.Context, userID, pseudonym string) (int, error) {
+	args := m.Called(ctx, userID, pseudonym)
+	return args.Int(0), args.Error(1)
+}
+
+// auditEventOf は指定した種類と結果の監査イベントに一致するmatcherを返す
+func auditEventOf(eventType model.AuditEventType, outcome model.AuditOutcome) interface{} {
+	return mock.MatchedBy(func(event *model.AuditEvent) bool {
+		return event.Type == eventType && event.Outcome == outcome
+	})
+}
+
 // MockGoogleService はGoogleサービスのモック
 type MockGoogleService struct {
 	mock.Mock
@@ -126,13 +190,28 @@ func (m *MockJWTService) GenerateRefreshToken(userID string) (string, error) {
 	return args.String(0), args.Error(1)
 }
 
+func (m *MockJWTService) GenerateImpersonationToken(userID, actorID string, ttl time.Duration) (string, error) {
+	args := m.Called(userID, actorID, ttl)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockJWTService) ParseToken(token string) (*model.TokenClaims, error) {
+	args := m.Called(token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.TokenClaims), args.Error(1)
+}
+
 func TestAuthUsecaseImpl_GoogleLogin(t *testing.T) {
 	tests := []struct {
-		testName    string
-		input       *GoogleLoginInput
-		setupMocks  func(*MockUserRepository, *MockAuthRepository, *MockGoogleService, *MockJWTService)
-		expectError bool
-		expectUser  bool
+		testName     string
+		input        *GoogleLoginInput
+		setupMocks   func(*MockUserRepository, *MockAuthRepository, *MockGoogleService, *MockJWTService)
+		expectAudits []interface{}
+		expectError  bool
+		expectUser   bool
+		expectRole   model.Role
 	}{
 		{
 			testName: "正常なGoogleログイン - 新規ユーザー",
@@ -161,10 +240,15 @@ func TestAuthUsecaseImpl_GoogleLogin(t *testing.T) {
 				userRepo.On("Save", mock.Anything, mock.AnythingOfType("*model.User")).Return(nil)
 				jwtSvc.On("GenerateToken", "google_123").Return("jwt_access_token", nil)
 				jwtSvc.On("GenerateRefreshToken", "google_123").Return("jwt_refresh_token", nil)
-				authRepo.On("SaveToken", mock.Anything, "google_123", mock.AnythingOfType("*model.AuthToken")).Return(nil)
+				// ログインでは認証日時を記録する
+				authRepo.On("SaveToken", mock.Anything, "google_123", mock.MatchedBy(func(token *model.AuthToken) bool {
+					return time.Since(token.AuthenticatedAt) < time.Minute
+				})).Return(nil)
 			},
-			expectError: false,
-			expectUser:  true,
+			expectAudits: []interface{}{auditEventOf(model.AuditEventLogin, model.AuditOutcomeSuccess)},
+			expectError:  false,
+			expectUser:   true,
+			expectRole:   model.RoleUser,
 		},
 		{
 			testName: "正常なGoogleログイン - 既存ユーザー",
@@ -203,8 +287,190 @@ func TestAuthUsecaseImpl_GoogleLogin(t *testing.T) {
 				jwtSvc.On("GenerateRefreshToken", "google_123").Return("jwt_refresh_token", nil)
 				authRepo.On("SaveToken", mock.Anything, "google_123", mock.AnythingOfType("*model.AuthToken")).Return(nil)
 			},
+			expectAudits: []interface{}{auditEventOf(model.AuditEventLogin, model.AuditOutcomeSuccess)},
+			expectError:  false,
+			expectUser:   true,
+		},
+		{
+			testName: "管理者メールアドレスのユーザーは管理者ロールが付与される",
+			input: &GoogleLoginInput{
+				AuthorizationCode: "admin_code",
+				RedirectURI:       "http://localhost:3000/callback",
+			},
+			setupMocks: func(userRepo *MockUserRepository, authRepo *MockAuthRepository, googleSvc *MockGoogleService, jwtSvc *MockJWTService) {
+				googleToken := &model.AuthToken{
+					AccessToken:  "google_access_token",
+					RefreshToken: "google_refresh_token",
+					ExpiresIn:    time.Now().Add(time.Hour).Unix(),
+					TokenType:    "Bearer",
+				}
+				googleUser := &model.GoogleUserInfo{
+					ID:            "google_admin",
+					Email:         "Admin@example.com",
+					VerifiedEmail: true,
+					Name:          "Admin User",
+				}
+
+				googleSvc.On("ExchangeCode", mock.Anything, "admin_code", "http://localhost:3000/callback").Return(googleToken, nil)
+				googleSvc.On("GetUserInfo", mock.Anything, "google_access_token").Return(googleUser, nil)
+				userRepo.On("FindByEmail", mock.Anything, "Admin@example.com").Return((*model.User)(nil), repository.ErrUserNotFound)
+				userRepo.On("Save", mock.Anything, mock.AnythingOfType("*model.User")).Return(nil)
+				jwtSvc.On("GenerateToken", "google_admin").Return("jwt_access_token", nil)
+				jwtSvc.On("GenerateRefreshToken", "google_admin").Return("jwt_refresh_token", nil)
+				authRepo.On("SaveToken", mock.Anything, "google_admin", mock.AnythingOfType("*model.AuthToken")).Return(nil)
+			},
+			expectAudits: []interface{}{
+				auditEventOf(model.AuditEventRoleChange, model.AuditOutcomeSuccess),
+				auditEventOf(model.AuditEventLogin, model.AuditOutcomeSuccess),
+			},
 			expectError: false,
 			expectUser:  true,
+			expectRole:  model.RoleAdmin,
+		},
+		{
+			testName: "ユーザーが編集した表示名はGoogleのプロフィールで上書きしない",
+			input: &GoogleLoginInput{
+				AuthorizationCode: "test_code",
+				RedirectURI:       "http://localhost:3000/callback",
+			},
+			setupMocks: func(userRepo *MockUserRepository, authRepo *MockAuthRepository, googleSvc *MockGoogleService, jwtSvc *MockJWTService) {
+				googleSvc.On("ExchangeCode", mock.Anything, "test_code", "http://localhost:3000/callback").Return(&model.AuthToken{AccessToken: "google_access_token"}, nil)
+				googleSvc.On("GetUserInfo", mock.Anything, "google_access_token").Return(&model.GoogleUserInfo{
+					ID:            "google_123",
+					Email:         "test@example.com",
+					VerifiedEmail: true,
+					Name:          "Google Name",
+					Picture:       "https://example.com/google.jpg",
+				}, nil)
+				userRepo.On("FindByEmail", mock.Anything, "test@example.com").Return(&model.User{
+					ID:         "google_123",
+					Email:      "test@example.com",
+					Name:       "Edited Name",
+					Picture:    "https://example.com/picture.jpg",
+					NameEdited: true,
+				}, nil)
+				userRepo.On("Update", mock.Anything, mock.MatchedBy(func(user *model.User) bool {
+					return user.Name == "Edited Name" && user.Picture == "https://example.com/google.jpg"
+				})).Return(nil)
+				jwtSvc.On("GenerateToken", "google_123").Return("jwt_access_token", nil)
+				jwtSvc.On("GenerateRefreshToken", "google_123").Return("jwt_refresh_token", nil)
+				authRepo.On("SaveToken", mock.Anything, "google_123", mock.AnythingOfType("*model.AuthToken")).Return(nil)
+			},
+			expectAudits: []interface{}{auditEventOf(model.AuditEventLogin, model.AuditOutcomeSuccess)},
+			expectError:  false,
+			expectUser:   true,
+		},
+		{
+			testName: "無効化されたユーザーはログインできない",
+			input: &GoogleLoginInput{
+				AuthorizationCode: "test_code",
+				RedirectURI:       "http://localhost:3000/callback",
+			},
+			setupMocks: func(userRepo *MockUserRepository, authRepo *MockAuthRepository, googleSvc *MockGoogleService, jwtSvc *MockJWTService) {
+				googleSvc.On("ExchangeCode", mock.Anything, "test_code", "http://localhost:3000/callback").Return(&model.AuthToken{AccessToken: "google_access_token"}, nil)
+				googleSvc.On("GetUserInfo", mock.Anything, "google_access_token").Return(&model.GoogleUserInfo{
+					ID:            "google_123",
+					Email:         "test@example.com",
+					VerifiedEmail: true,
+					Name:          "Test User",
+				}, nil)
+				userRepo.On("FindByEmail", mock.Anything, "test@example.com").Return(&model.User{
+					ID:     "google_123",
+					Email:  "test@example.com",
+					Name:   "Test User",
+					Status: model.UserStatusDisabled,
+				}, nil)
+			},
+			expectAudits: []interface{}{auditEventOf(model.AuditEventLogin, model.AuditOutcomeFailure)},
+			expectError:  true,
+			expectUser:   false,
+		},
+		{
+			testName: "一時停止中のユーザーはログインできない",
+			input: &GoogleLoginInput{
+				AuthorizationCode: "test_code",
+				RedirectURI:       "http://localhost:3000/callback",
+			},
+			setupMocks: func(userRepo *MockUserRepository, authRepo *MockAuthRepository, googleSvc *MockGoogleService, jwtSvc *MockJWTService) {
+				until := time.Now().Add(time.Hour)
+				googleSvc.On("ExchangeCode", mock.Anything, "test_code", "http://localhost:3000/callback").Return(&model.AuthToken{AccessToken: "google_access_token"}, nil)
+				googleSvc.On("GetUserInfo", mock.Anything, "google_access_token").Return(&model.GoogleUserInfo{
+					ID:            "google_123",
+					Email:         "test@example.com",
+					VerifiedEmail: true,
+					Name:          "Test User",
+				}, nil)
+				userRepo.On("FindByEmail", mock.Anything, "test@example.com").Return(&model.User{
+					ID:             "google_123",
+					Email:          "test@example.com",
+					Name:           "Test User",
+					Status:         model.UserStatusSuspended,
+					SuspendedUntil: &until,
+				}, nil)
+			},
+			expectAudits: []interface{}{auditEventOf(model.AuditEventLogin, model.AuditOutcomeFailure)},
+			expectError:  true,
+			expectUser:   false,
+		},
+		{
+			testName: "認証待ちのユーザーは認証済みのメールアドレスでのログインで有効化される",
+			input: &GoogleLoginInput{
+				AuthorizationCode: "test_code",
+				RedirectURI:       "http://localhost:3000/callback",
+			},
+			setupMocks: func(userRepo *MockUserRepository, authRepo *MockAuthRepository, googleSvc *MockGoogleService, jwtSvc *MockJWTService) {
+				googleSvc.On("ExchangeCode", mock.Anything, "test_code", "http://localhost:3000/callback").Return(&model.AuthToken{AccessToken: "google_access_token"}, nil)
+				googleSvc.On("GetUserInfo", mock.Anything, "google_access_token").Return(&model.GoogleUserInfo{
+					ID:            "google_123",
+					Email:         "test@example.com",
+					VerifiedEmail: true,
+					Name:          "Test User",
+				}, nil)
+				userRepo.On("FindByEmail", mock.Anything, "test@example.com").Return(&model.User{
+					ID:     "google_123",
+					Email:  "test@example.com",
+					Name:   "Test User",
+					Status: model.UserStatusPendingVerification,
+				}, nil)
+				userRepo.On("Update", mock.Anything, mock.MatchedBy(func(user *model.User) bool {
+					return user.Status == model.UserStatusActive
+				})).Return(nil)
+				jwtSvc.On("GenerateToken", "google_123").Return("jwt_access_token", nil)
+				jwtSvc.On("GenerateRefreshToken", "google_123").Return("jwt_refresh_token", nil)
+				authRepo.On("SaveToken", mock.Anything, "google_123", mock.AnythingOfType("*model.AuthToken")).Return(nil)
+			},
+			expectAudits: []interface{}{
+				auditEventOf(model.AuditEventUserStatusChange, model.AuditOutcomeSuccess),
+				auditEventOf(model.AuditEventLogin, model.AuditOutcomeSuccess),
+			},
+			expectError: false,
+			expectUser:  true,
+		},
+		{
+			testName: "削除したユーザーは消去されるまでログインできない",
+			input: &GoogleLoginInput{
+				AuthorizationCode: "test_code",
+				RedirectURI:       "http://localhost:3000/callback",
+			},
+			setupMocks: func(userRepo *MockUserRepository, authRepo *MockAuthRepository, googleSvc *MockGoogleService, jwtSvc *MockJWTService) {
+				deletedAt := time.Now().Add(-time.Minute)
+				googleSvc.On("ExchangeCode", mock.Anything, "test_code", "http://localhost:3000/callback").Return(&model.AuthToken{AccessToken: "google_access_token"}, nil)
+				googleSvc.On("GetUserInfo", mock.Anything, "google_access_token").Return(&model.GoogleUserInfo{
+					ID:            "google_123",
+					Email:         "test@example.com",
+					VerifiedEmail: true,
+					Name:          "Test User",
+				}, nil)
+				userRepo.On("FindByEmail", mock.Anything, "test@example.com").Return(&model.User{
+					ID:        "google_123",
+					Email:     "test@example.com",
+					Name:      "Test User",
+					DeletedAt: &deletedAt,
+				}, nil)
+			},
+			expectAudits: []interface{}{auditEventOf(model.AuditEventLogin, model.AuditOutcomeFailure)},
+			expectError:  true,
+			expectUser:   false,
 		},
 		{
 			testName: "Google認証コード交換エラー",
@@ -215,8 +481,9 @@ func TestAuthUsecaseImpl_GoogleLogin(t *testing.T) {
 			setupMocks: func(userRepo *MockUserRepository, authRepo *MockAuthRepository, googleSvc *MockGoogleService, jwtSvc *MockJWTService) {
 				googleSvc.On("ExchangeCode", mock.Anything, "invalid_code", "http://localhost:3000/callback").Return((*model.AuthToken)(nil), errors.New("invalid code"))
 			},
-			expectError: true,
-			expectUser:  false,
+			expectAudits: []interface{}{auditEventOf(model.AuditEventLogin, model.AuditOutcomeFailure)},
+			expectError:  true,
+			expectUser:   false,
 		},
 	}
 
@@ -227,9 +494,14 @@ func TestAuthUsecaseImpl_GoogleLogin(t *testing.T) {
 			googleSvc := new(MockGoogleService)
 			jwtSvc := new(MockJWTService)
 
+			auditRepo := new(MockAuditRepository)
+
 			tt.setupMocks(userRepo, authRepo, googleSvc, jwtSvc)
+			for _, audit := range tt.expectAudits {
+				auditRepo.On("Append", mock.Anything, audit).Return(nil).Once()
+			}
 
-			usecase := NewAuthUsecase(userRepo, authRepo, googleSvc, jwtSvc)
+			usecase := NewAuthUsecase(userRepo, authRepo, auditRepo, nil, googleSvc, jwtSvc, nil, []string{"admin@example.com"})
 			result, err := usecase.GoogleLogin(context.Background(), tt.input)
 
 			if tt.expectError {
@@ -244,22 +516,36 @@ func TestAuthUsecaseImpl_GoogleLogin(t *testing.T) {
 					assert.NotEmpty(t, result.RefreshToken)
 					assert.Greater(t, result.ExpiresIn, int64(0))
 				}
+				if tt.expectRole != "" {
+					assert.Equal(t, tt.expectRole, result.User.Role)
+				}
 			}
 
 			userRepo.AssertExpectations(t)
 			authRepo.AssertExpectations(t)
 			googleSvc.AssertExpectations(t)
 			jwtSvc.AssertExpectations(t)
+			auditRepo.AssertExpectations(t)
 		})
 	}
 }
 
 func TestAuthUsecaseImpl_RefreshToken(t *testing.T) {
+	storedToken := &model.AuthToken{
+		AccessToken:  "current_access_token",
+		RefreshToken: "valid_refresh_token",
+		ExpiresIn:    time.Now().Add(time.Hour).Unix(),
+		TokenType:    "Bearer",
+		// AuthenticatedAt はリフレッシュで更新しない
+		AuthenticatedAt: time.Now().Add(-time.Hour).Truncate(time.Second),
+	}
+
 	tests := []struct {
-		testName    string
-		input       *RefreshTokenInput
-		setupMocks  func(*MockUserRepository, *MockAuthRepository, *MockGoogleService, *MockJWTService)
-		expectError bool
+		testName     string
+		input        *RefreshTokenInput
+		setupMocks   func(*MockUserRepository, *MockAuthRepository, *MockGoogleService, *MockJWTService)
+		expectAudits []interface{}
+		expectError  bool
 	}{
 		{
 			testName: "正常なトークンリフレッシュ",
@@ -268,11 +554,16 @@ func TestAuthUsecaseImpl_RefreshToken(t *testing.T) {
 			},
 			setupMocks: func(userRepo *MockUserRepository, authRepo *MockAuthRepository, googleSvc *MockGoogleService, jwtSvc *MockJWTService) {
 				jwtSvc.On("ValidateToken", "valid_refresh_token").Return("user_123", nil)
+				authRepo.On("GetToken", mock.Anything, "user_123").Return(storedToken, nil)
+				userRepo.On("FindByID", mock.Anything, "user_123").Return(&model.User{ID: "user_123", Status: model.UserStatusActive}, nil)
 				jwtSvc.On("GenerateToken", "user_123").Return("new_access_token", nil)
 				jwtSvc.On("GenerateRefreshToken", "user_123").Return("new_refresh_token", nil)
-				authRepo.On("SaveToken", mock.Anything, "user_123", mock.AnythingOfType("*model.AuthToken")).Return(nil)
+				authRepo.On("SaveToken", mock.Anything, "user_123", mock.MatchedBy(func(token *model.AuthToken) bool {
+					return token.AccessToken == "new_access_token" && token.AuthenticatedAt.Equal(storedToken.AuthenticatedAt)
+				})).Return(nil)
 			},
-			expectError: false,
+			expectAudits: []interface{}{auditEventOf(model.AuditEventRefresh, model.AuditOutcomeSuccess)},
+			expectError:  false,
 		},
 		{
 			testName: "無効なリフレッシュトークン",
@@ -282,6 +573,76 @@ func TestAuthUsecaseImpl_RefreshToken(t *testing.T) {
 			setupMocks: func(userRepo *MockUserRepository, authRepo *MockAuthRepository, googleSvc *MockGoogleService, jwtSvc *MockJWTService) {
 				jwtSvc.On("ValidateToken", "invalid_refresh_token").Return("", errors.New("invalid token"))
 			},
+			expectAudits: []interface{}{auditEventOf(model.AuditEventRefresh, model.AuditOutcomeFailure)},
+			expectError:  true,
+		},
+		{
+			testName: "ログアウト済みのセッション",
+			input: &RefreshTokenInput{
+				RefreshToken: "valid_refresh_token",
+			},
+			setupMocks: func(userRepo *MockUserRepository, authRepo *MockAuthRepository, googleSvc *MockGoogleService, jwtSvc *MockJWTService) {
+				jwtSvc.On("ValidateToken", "valid_refresh_token").Return("user_123", nil)
+				authRepo.On("GetToken", mock.Anything, "user_123").Return(nil, repository.ErrTokenNotFound)
+			},
+			expectAudits: []interface{}{auditEventOf(model.AuditEventRefresh, model.AuditOutcomeFailure)},
+			expectError:  true,
+		},
+		{
+			testName: "ローテーション済みトークンの再利用でセッションを無効化",
+			input: &RefreshTokenInput{
+				RefreshToken: "rotated_refresh_token",
+			},
+			setupMocks: func(userRepo *MockUserRepository, authRepo *MockAuthRepository, googleSvc *MockGoogleService, jwtSvc *MockJWTService) {
+				jwtSvc.On("ValidateToken", "rotated_refresh_token").Return("user_123", nil)
+				authRepo.On("GetToken", mock.Anything, "user_123").Return(storedToken, nil)
+				userRepo.On("FindByID", mock.Anything, "user_123").Return(&model.User{ID: "user_123", Status: model.UserStatusActive}, nil)
+				// 盗まれたトークンで取得したアクセストークンも使えなくする
+				userRepo.On("Update", mock.Anything, mock.MatchedBy(func(user *model.User) bool {
+					return user.SessionsRevokedAt != nil && user.IsSessionRevoked(time.Now().Add(-time.Minute))
+				})).Return(nil)
+				authRepo.On("DeleteToken", mock.Anything, "user_123").Return(nil)
+			},
+			expectAudits: []interface{}{auditEventOf(model.AuditEventTokenReuse, model.AuditOutcomeFailure)},
+			expectError:  true,
+		},
+		{
+			testName: "消去されたユーザーのローテーション済みトークンの再利用",
+			input: &RefreshTokenInput{
+				RefreshToken: "rotated_refresh_token",
+			},
+			setupMocks: func(userRepo *MockUserRepository, authRepo *MockAuthRepository, googleSvc *MockGoogleService, jwtSvc *MockJWTService) {
+				jwtSvc.On("ValidateToken", "rotated_refresh_token").Return("user_123", nil)
+				authRepo.On("GetToken", mock.Anything, "user_123").Return(storedToken, nil)
+				userRepo.On("FindByID", mock.Anything, "user_123").Return(nil, repository.ErrUserNotFound)
+				authRepo.On("DeleteToken", mock.Anything, "user_123").Return(nil)
+			},
+			expectAudits: []interface{}{auditEventOf(model.AuditEventTokenReuse, model.AuditOutcomeFailure)},
+			expectError:  true,
+		},
+		{
+			testName: "一時停止中のユーザーはリフレッシュできない",
+			input: &RefreshTokenInput{
+				RefreshToken: "valid_refresh_token",
+			},
+			setupMocks: func(userRepo *MockUserRepository, authRepo *MockAuthRepository, googleSvc *MockGoogleService, jwtSvc *MockJWTService) {
+				jwtSvc.On("ValidateToken", "valid_refresh_token").Return("user_123", nil)
+				authRepo.On("GetToken", mock.Anything, "user_123").Return(storedToken, nil)
+				userRepo.On("FindByID", mock.Anything, "user_123").Return(&model.User{ID: "user_123", Status: model.UserStatusSuspended, StatusReason: "spam"}, nil)
+			},
+			expectAudits: []interface{}{auditEventOf(model.AuditEventRefresh, model.AuditOutcomeFailure)},
+			expectError:  true,
+		},
+		{
+			testName: "消去されたユーザーのトークン",
+			input: &RefreshTokenInput{
+				RefreshToken: "valid_refresh_token",
+			},
+			setupMocks: func(userRepo *MockUserRepository, authRepo *MockAuthRepository, googleSvc *MockGoogleService, jwtSvc *MockJWTService) {
+				jwtSvc.On("ValidateToken", "valid_refresh_token").Return("user_123", nil)
+				authRepo.On("GetToken", mock.Anything, "user_123").Return(storedToken, nil)
+				userRepo.On("FindByID", mock.Anything, "user_123").Return(nil, repository.ErrUserNotFound)
+			},
 			expectError: true,
 		},
 	}
@@ -293,9 +654,14 @@ func TestAuthUsecaseImpl_RefreshToken(t *testing.T) {
 			googleSvc := new(MockGoogleService)
 			jwtSvc := new(MockJWTService)
 
+			auditRepo := new(MockAuditRepository)
+
 			tt.setupMocks(userRepo, authRepo, googleSvc, jwtSvc)
+			for _, audit := range tt.expectAudits {
+				auditRepo.On("Append", mock.Anything, audit).Return(nil).Once()
+			}
 
-			usecase := NewAuthUsecase(userRepo, authRepo, googleSvc, jwtSvc)
+			usecase := NewAuthUsecase(userRepo, authRepo, auditRepo, nil, googleSvc, jwtSvc, nil, nil)
 			result, err := usecase.RefreshToken(context.Background(), tt.input)
 
 			if tt.expectError {
@@ -312,6 +678,7 @@ func TestAuthUsecaseImpl_RefreshToken(t *testing.T) {
 			authRepo.AssertExpectations(t)
 			googleSvc.AssertExpectations(t)
 			jwtSvc.AssertExpectations(t)
+			auditRepo.AssertExpectations(t)
 		})
 	}
 }
@@ -352,9 +719,14 @@ func TestAuthUsecaseImpl_Logout(t *testing.T) {
 			googleSvc := new(MockGoogleService)
 			jwtSvc := new(MockJWTService)
 
+			auditRepo := new(MockAuditRepository)
+
 			tt.setupMocks(userRepo, authRepo, googleSvc, jwtSvc)
+			if !tt.expectError {
+				auditRepo.On("Append", mock.Anything, auditEventOf(model.AuditEventLogout, model.AuditOutcomeSuccess)).Return(nil).Once()
+			}
 
-			usecase := NewAuthUsecase(userRepo, authRepo, googleSvc, jwtSvc)
+			usecase := NewAuthUsecase(userRepo, authRepo, auditRepo, nil, googleSvc, jwtSvc, nil, nil)
 			err := usecase.Logout(context.Background(), tt.input)
 
 			if tt.expectError {
@@ -367,6 +739,106 @@ func TestAuthUsecaseImpl_Logout(t *testing.T) {
 			authRepo.AssertExpectations(t)
 			googleSvc.AssertExpectations(t)
 			jwtSvc.AssertExpectations(t)
+			auditRepo.AssertExpectations(t)
 		})
 	}
 }
+
+// MockLoginRiskUsecase はLoginRiskUsecaseのモック
+type MockLoginRiskUsecase struct {
+	mock.Mock
+}
+
+var _ LoginRiskUsecase = (*MockLoginRiskUsecase)(nil)
+
+func (m *MockLoginRiskUsecase) Evaluate(ctx context.Context, input *EvaluateLoginRiskInput) (*model.RiskAssessment, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.RiskAssessment), args.Error(1)
+}
+
+func (m *MockLoginRiskUsecase) ReportNotMe(ctx context.Context, input *ReportNotMeInput) error {
+	args := m.Called(ctx, input)
+	return args.Error(0)
+}
+
+func TestAuthUsecaseImpl_GoogleLogin_RiskAssessment(t *testing.T) {
+	userAgent := "Mozilla/5.0 (Macintosh) Chrome/120.0"
+	existingUser := &model.User{ID: "google_123", Email: "test@example.com", Name: "Test User", Role: model.RoleUser}
+
+	userRepo := new(MockUserRepository)
+	authRepo := new(MockAuthRepository)
+	auditRepo := new(MockAuditRepository)
+	googleSvc := new(MockGoogleService)
+	jwtSvc := new(MockJWTService)
+	loginRisk := new(MockLoginRiskUsecase)
+
+	googleSvc.On("ExchangeCode", mock.Anything, "valid_code", "").
+		Return(&model.AuthToken{AccessToken: "google_access_token"}, nil)
+	googleSvc.On("GetUserInfo", mock.Anything, "google_access_token").
+		Return(&model.GoogleUserInfo{ID: "google_123", Email: "test@example.com", VerifiedEmail: true, Name: "Test User"}, nil)
+	userRepo.On("FindByEmail", mock.Anything, "test@example.com").Return(existingUser, nil)
+	userRepo.On("Update", mock.Anything, mock.AnythingOfType("*model.User")).Return(nil)
+	jwtSvc.On("GenerateToken", "google_123").Return("jwt_access_token", nil)
+	jwtSvc.On("GenerateRefreshToken", "google_123").Return("jwt_refresh_token", nil)
+	authRepo.On("SaveToken", mock.Anything, "google_123", mock.AnythingOfType("*model.AuthToken")).Return(nil)
+
+	loginRisk.On("Evaluate", mock.Anything, mock.MatchedBy(func(input *EvaluateLoginRiskInput) bool {
+		return input.User.ID == "google_123" && input.ClientIP == "198.51.100.7" && input.UserAgent == userAgent
+	})).Return(model.NewRiskAssessment([]model.RiskSignal{model.RiskSignalNewDevice, model.RiskSignalNewIPRange}), nil)
+	auditRepo.On("Append", mock.Anything, mock.MatchedBy(func(event *model.AuditEvent) bool {
+		return event.Type == model.AuditEventLogin &&
+			event.Outcome == model.AuditOutcomeSuccess &&
+			event.Metadata["device"] == model.DeviceFingerprint(userAgent) &&
+			event.Metadata["risk_score"] == "50" &&
+			event.Metadata["risk_level"] == "medium" &&
+			event.Metadata["risk_signals"] == "new_device,new_ip_range"
+	})).Return(nil)
+
+	usecase := NewAuthUsecase(userRepo, authRepo, auditRepo, nil, googleSvc, jwtSvc, loginRisk, nil)
+	result, err := usecase.GoogleLogin(context.Background(), &GoogleLoginInput{
+		AuthorizationCode: "valid_code",
+		ClientIP:          "198.51.100.7",
+		UserAgent:         userAgent,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "jwt_access_token", result.AccessToken)
+	loginRisk.AssertExpectations(t)
+	auditRepo.AssertExpectations(t)
+}
+
+func TestAuthUsecaseImpl_Tracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() {
+		otel.SetTracerProvider(noop.NewTracerProvider())
+	})
+
+	authRepo := new(MockAuthRepository)
+	auditRepo := new(MockAuditRepository)
+	jwtSvc := new(MockJWTService)
+	// リポジトリにはユースケースのスパンを含むcontextが渡される
+	hasSpan := mock.MatchedBy(func(ctx context.Context) bool {
+		return trace.SpanContextFromContext(ctx).IsValid()
+	})
+	authRepo.On("DeleteToken", hasSpan, "user_123").Return(nil)
+	auditRepo.On("Append", hasSpan, mock.Anything).Return(nil)
+	jwtSvc.On("ValidateToken", "invalid_token").Return("", errors.New("invalid token"))
+
+	usecase := NewAuthUsecase(new(MockUserRepository), authRepo, auditRepo, nil, new(MockGoogleService), jwtSvc, nil, nil)
+	assert.NoError(t, usecase.Logout(context.Background(), &LogoutInput{UserID: "user_123"}))
+	_, err := usecase.RefreshToken(context.Background(), &RefreshTokenInput{RefreshToken: "invalid_token"})
+	assert.Error(t, err)
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 2) {
+		assert.Equal(t, "AuthUsecase.Logout", spans[0].Name())
+		assert.Equal(t, codes.Unset, spans[0].Status().Code)
+		assert.Equal(t, "AuthUsecase.RefreshToken", spans[1].Name())
+		assert.Equal(t, codes.Error, spans[1].Status().Code)
+	}
+	authRepo.AssertExpectations(t)
+}
@@ -0,0 +1,143 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/repository"
+	"sync"
+	"time"
+)
+
+const (
+	// eventDispatchBatchSize は1回の配信でアウトボックスから取り出す最大件数
+	eventDispatchBatchSize = 100
+	// eventRetryBaseDelay・eventRetryMaxDelayは配信に失敗したイベントを再配信するまでの待ち時間（指数バックオフ）
+	eventRetryBaseDelay = time.Second
+	eventRetryMaxDelay  = 5 * time.Minute
+)
+
+type (
+	// EventHandler はドメインイベントを処理する購読者（エラーを返すと後で再配信する）
+	EventHandler func(ctx context.Context, event *model.DomainEvent) error
+
+	// eventSubscriber は登録された購読者を表す
+	eventSubscriber struct {
+		name    string
+		handler EventHandler
+		// types は購読するイベントの種類（空の場合はすべて）
+		types []model.DomainEventType
+	}
+
+	// EventDispatcher はアウトボックスに保存されたドメインイベントを購読者に配信する
+	// 購読者のいずれかが失敗したイベントは、成功した購読者も含めて再配信する（少なくとも1回の配信）
+	// そのため購読者はイベントのIDで重複を判定する
+	EventDispatcher struct {
+		outboxRepo  repository.OutboxRepository
+		subscribers []eventSubscriber
+		mutex       sync.RWMutex
+		// dispatching は同じイベントを並行して配信しないための排他制御
+		dispatching sync.Mutex
+	}
+)
+
+// NewEventDispatcher は新しいEventDispatcherを作成する
+func NewEventDispatcher(outboxRepo repository.OutboxRepository) *EventDispatcher {
+	return &EventDispatcher{
+		outboxRepo: outboxRepo,
+	}
+}
+
+// Subscribe は購読者を登録する（typesを省略した場合はすべてのイベントを購読する）
+func (d *EventDispatcher) Subscribe(name string, handler EventHandler, types ...model.DomainEventType) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.subscribers = append(d.subscribers, eventSubscriber{name: name, handler: handler, types: types})
+}
+
+// DispatchPending は配信時刻を過ぎたイベントを古い順に購読者へ配信し、配信を完了した件数を返す
+// 配信を完了したイベントはアウトボックスから削除し、失敗したイベントは待ち時間を空けて再配信する
+func (d *EventDispatcher) DispatchPending(ctx context.Context) (int, error) {
+	d.dispatching.Lock()
+	defer d.dispatching.Unlock()
+
+	now := time.Now()
+	messages, err := d.outboxRepo.FetchPending(ctx, now, eventDispatchBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, message := range messages {
+		if err := ctx.Err(); err != nil {
+			return delivered, err
+		}
+
+		event := message.Event
+		if err := d.deliver(ctx, event); err != nil {
+			delay := eventRetryDelay(message.Attempts)
+			slog.WarnContext(ctx, "failed to dispatch domain event",
+				slog.String("event_id", event.ID),
+				slog.String("event_type", string(event.Type)),
+				slog.Int("attempts", message.Attempts+1),
+				slog.Duration("retry_in", delay),
+				slog.Any("error", err))
+			if err := d.outboxRepo.MarkFailed(ctx, event.ID, err.Error(), now.Add(delay)); err != nil {
+				return delivered, err
+			}
+			continue
+		}
+		if err := d.outboxRepo.Delete(ctx, event.ID); err != nil {
+			return delivered, err
+		}
+		delivered++
+	}
+	return delivered, nil
+}
+
+// deliver はイベントを購読しているすべての購読者に配信する（失敗しても残りの購読者には配信する）
+func (d *EventDispatcher) deliver(ctx context.Context, event *model.DomainEvent) error {
+	d.mutex.RLock()
+	subscribers := slices.Clone(d.subscribers)
+	d.mutex.RUnlock()
+
+	var failed []string
+	var firstErr error
+	for _, subscriber := range subscribers {
+		if len(subscriber.types) > 0 && !slices.Contains(subscriber.types, event.Type) {
+			continue
+		}
+		if err := callEventHandler(ctx, subscriber.handler, event); err != nil {
+			failed = append(failed, subscriber.name)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	if firstErr != nil {
+		return fmt.Errorf("subscribers %v failed: %w", failed, firstErr)
+	}
+	return nil
+}
+
+// callEventHandler は購読者を呼び出し、panicはエラーとして扱う
+func callEventHandler(ctx context.Context, handler EventHandler, event *model.DomainEvent) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler(ctx, event)
+}
+
+// eventRetryDelay は失敗した回数に応じた再配信までの待ち時間を返す
+func eventRetryDelay(attempts int) time.Duration {
+	delay := eventRetryBaseDelay
+	for i := 0; i < attempts && delay < eventRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, eventRetryMaxDelay)
+}
@@ -0,0 +1,263 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockOutboxRepository はOutboxRepositoryのモック
+type MockOutboxRepository struct {
+	mock.Mock
+}
+
+var _ repository.OutboxRepository = (*MockOutboxRepository)(nil)
+
+func (m *MockOutboxRepository) Append(ctx context.Context, events ...*model.DomainEvent) error {
+	args := m.Called(ctx, events)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) FetchPending(ctx context.Context, now time.Time, limit int) ([]*model.OutboxMessage, error) {
+	args := m.Called(ctx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.OutboxMessage), args.Error(1)
+}
+
+func (m *MockOutboxRepository) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) MarkFailed(ctx context.Context, id string, cause string, nextAttemptAt time.Time) error {
+	args := m.Called(ctx, id, cause, nextAttemptAt)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) DeleteByUserID(ctx context.Context, userID string) (int, error) {
+	args := m.Called(ctx, userID)
+	return args.Int(0), args.Error(1)
+}
+
+// stubTransactor はfnをそのまま実行するTransactor
+type stubTransactor struct{}
+
+func (stubTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// domainEventsOf は指定した種類のドメインイベントを順に含むかを判定するmatcherを返す
+func domainEventsOf(types ...model.DomainEventType) interface{} {
+	return mock.MatchedBy(func(events []*model.DomainEvent) bool {
+		if len(events) != len(types) {
+			return false
+		}
+		for i, event := range events {
+			if event.Type != types[i] {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func TestEventOutbox_Write(t *testing.T) {
+	tests := []struct {
+		testName     string
+		writeErr     error
+		expectAppend bool
+	}{
+		{testName: "書き込みが成功した場合はイベントを保存する", expectAppend: true},
+		{testName: "書き込みが失敗した場合はイベントを保存しない", writeErr: errors.New("update failed")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			outboxRepo := new(MockOutboxRepository)
+			if tt.expectAppend {
+				outboxRepo.On("Append", mock.Anything, domainEventsOf(model.DomainEventProfileUpdated)).Return(nil)
+			}
+
+			event := model.NewDomainEvent(model.DomainEventProfileUpdated, "user_1", "user_1", nil)
+			err := NewEventOutbox(stubTransactor{}, outboxRepo).Write(context.Background(), func(ctx context.Context) error {
+				return tt.writeErr
+			}, event)
+
+			assert.Equal(t, tt.writeErr, err)
+			outboxRepo.AssertExpectations(t)
+		})
+	}
+
+	t.Run("nilの場合は書き込みのみを行う", func(t *testing.T) {
+		var outbox *EventOutbox
+		called := false
+		err := outbox.Write(context.Background(), func(ctx context.Context) error {
+			called = true
+			return nil
+		}, model.NewDomainEvent(model.DomainEventProfileUpdated, "user_1", "user_1", nil))
+
+		assert.NoError(t, err)
+		assert.True(t, called)
+	})
+
+	t.Run("ログアウトでsession.revokedを保存する", func(t *testing.T) {
+		authRepo := new(MockAuthRepository)
+		authRepo.On("DeleteToken", mock.Anything, "user_1").Return(nil)
+		outboxRepo := new(MockOutboxRepository)
+		outboxRepo.On("Append", mock.Anything, mock.MatchedBy(func(events []*model.DomainEvent) bool {
+			return len(events) == 1 &&
+				events[0].Type == model.DomainEventSessionRevoked &&
+				events[0].UserID == "user_1" &&
+				events[0].Data["reason"] == "logout"
+		})).Return(nil)
+
+		usecase := NewAuthUsecase(new(MockUserRepository), authRepo, nil, NewEventOutbox(stubTransactor{}, outboxRepo), nil, nil, nil, nil)
+		err := usecase.Logout(context.Background(), &LogoutInput{UserID: "user_1"})
+
+		assert.NoError(t, err)
+		outboxRepo.AssertExpectations(t)
+	})
+
+	t.Run("「心当たりがない」報告でsession.revokedを保存する", func(t *testing.T) {
+		userRepo := new(MockUserRepository)
+		userRepo.On("FindByID", mock.Anything, "user_1").Return(&model.User{ID: "user_1"}, nil)
+		userRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+		authRepo := new(MockAuthRepository)
+		authRepo.On("DeleteToken", mock.Anything, "user_1").Return(nil)
+		revocationRepo := new(MockRevocationTokenRepository)
+		revocationRepo.On("ConsumeRevocationToken", mock.Anything, "valid_token").
+			Return(&model.RevocationToken{Token: "valid_token", UserID: "user_1"}, nil)
+		auditRepo := new(MockAuditRepository)
+		auditRepo.On("Append", mock.Anything, mock.Anything).Return(nil)
+		outboxRepo := new(MockOutboxRepository)
+		outboxRepo.On("Append", mock.Anything, mock.MatchedBy(func(events []*model.DomainEvent) bool {
+			return len(events) == 1 &&
+				events[0].Type == model.DomainEventSessionRevoked &&
+				events[0].Data["reason"] == "reported_not_me"
+		})).Return(nil)
+
+		usecase := NewLoginRiskUsecase(userRepo, auditRepo, authRepo, revocationRepo, NewEventOutbox(stubTransactor{}, outboxRepo), nil, nil, "")
+		err := usecase.ReportNotMe(context.Background(), &ReportNotMeInput{Token: "valid_token"})
+
+		assert.NoError(t, err)
+		outboxRepo.AssertExpectations(t)
+	})
+
+	t.Run("アカウントの削除でsession.revokedを保存する", func(t *testing.T) {
+		userRepo := new(MockUserRepository)
+		userRepo.On("FindByID", mock.Anything, "user_1").Return(&model.User{ID: "user_1"}, nil)
+		userRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+		authRepo := new(MockAuthRepository)
+		authRepo.On("DeleteToken", mock.Anything, "user_1").Return(nil)
+		exportRepo := new(MockDataExportRepository)
+		exportRepo.On("FindByUserID", mock.Anything, "user_1").Return(nil, repository.ErrDataExportNotFound)
+		auditRepo := new(MockAuditRepository)
+		auditRepo.On("Append", mock.Anything, mock.Anything).Return(nil)
+		outboxRepo := new(MockOutboxRepository)
+		outboxRepo.On("Append", mock.Anything, mock.MatchedBy(func(events []*model.DomainEvent) bool {
+			return len(events) == 1 &&
+				events[0].Type == model.DomainEventSessionRevoked &&
+				events[0].Data["reason"] == "account_deleted"
+		})).Return(nil)
+
+		usecase := NewAccountUsecase(userRepo, authRepo, auditRepo, NewEventOutbox(stubTransactor{}, outboxRepo), nil, exportRepo, nil, time.Hour, time.Hour)
+		_, err := usecase.DeleteAccount(context.Background(), &DeleteAccountInput{UserID: "user_1"})
+
+		assert.NoError(t, err)
+		outboxRepo.AssertExpectations(t)
+	})
+}
+
+func TestEventDispatcher_DispatchPending(t *testing.T) {
+	registered := &model.DomainEvent{ID: "event_1", Type: model.DomainEventUserRegistered, UserID: "user_1"}
+	loggedIn := &model.DomainEvent{ID: "event_2", Type: model.DomainEventUserLoggedIn, UserID: "user_1"}
+
+	tests := []struct {
+		testName          string
+		messages          []*model.OutboxMessage
+		mailErr           error
+		mailPanics        bool
+		expectedDelivered int
+		expectedMail      []string
+		expectedAll       []string
+		expectedFailed    map[string]time.Duration
+	}{
+		{
+			testName:          "購読しているイベントのみを配信して削除する",
+			messages:          []*model.OutboxMessage{{Event: registered}, {Event: loggedIn}},
+			expectedDelivered: 2,
+			expectedMail:      []string{"event_1"},
+			expectedAll:       []string{"event_1", "event_2"},
+		},
+		{
+			testName:          "購読者が失敗したイベントは他の購読者に配信したうえで再配信を待つ",
+			messages:          []*model.OutboxMessage{{Event: registered, Attempts: 2}, {Event: loggedIn}},
+			mailErr:           errors.New("smtp unavailable"),
+			expectedDelivered: 1,
+			expectedMail:      []string{"event_1"},
+			expectedAll:       []string{"event_1", "event_2"},
+			expectedFailed:    map[string]time.Duration{"event_1": 4 * time.Second},
+		},
+		{
+			testName:          "購読者のpanicは失敗として扱う",
+			messages:          []*model.OutboxMessage{{Event: registered}},
+			mailPanics:        true,
+			expectedDelivered: 0,
+			expectedAll:       []string{"event_1"},
+			expectedFailed:    map[string]time.Duration{"event_1": time.Second},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			outboxRepo := new(MockOutboxRepository)
+			outboxRepo.On("FetchPending", mock.Anything, mock.Anything, eventDispatchBatchSize).Return(tt.messages, nil)
+			for _, message := range tt.messages {
+				if delay, failed := tt.expectedFailed[message.Event.ID]; failed {
+					outboxRepo.On("MarkFailed", mock.Anything, message.Event.ID, mock.Anything, mock.MatchedBy(func(next time.Time) bool {
+						return next.Sub(time.Now()) > delay-time.Second && next.Sub(time.Now()) <= delay
+					})).Return(nil)
+				} else {
+					outboxRepo.On("Delete", mock.Anything, message.Event.ID).Return(nil)
+				}
+			}
+
+			var mail, all []string
+			dispatcher := NewEventDispatcher(outboxRepo)
+			dispatcher.Subscribe("mail", func(ctx context.Context, event *model.DomainEvent) error {
+				if tt.mailPanics {
+					panic("boom")
+				}
+				mail = append(mail, event.ID)
+				return tt.mailErr
+			}, model.DomainEventUserRegistered)
+			dispatcher.Subscribe("analytics", func(ctx context.Context, event *model.DomainEvent) error {
+				all = append(all, event.ID)
+				return nil
+			})
+
+			delivered, err := dispatcher.DispatchPending(context.Background())
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedDelivered, delivered)
+			assert.Equal(t, tt.expectedMail, mail)
+			assert.Equal(t, tt.expectedAll, all)
+			outboxRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestEventRetryDelay(t *testing.T) {
+	assert.Equal(t, time.Second, eventRetryDelay(0))
+	assert.Equal(t, 8*time.Second, eventRetryDelay(3))
+	assert.Equal(t, 5*time.Minute, eventRetryDelay(100))
+}
@@ -0,0 +1,57 @@
+package usecase
+
+import (
+	"context"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/repository"
+)
+
+// EventOutbox はリポジトリへの書き込みと、そこで発生したドメインイベントのアウトボックスへの保存を同じトランザクションで行う
+// 書き込みとイベントの保存が不可分になるかはTransactorの実装に依存する（in-memory実装は書き込みを取り消さない）
+// nilのEventOutboxは書き込みのみを行うため、イベントが不要なテストでは省略できる
+type EventOutbox struct {
+	tx         repository.Transactor
+	outboxRepo repository.OutboxRepository
+}
+
+// NewEventOutbox は新しいEventOutboxを作成する
+func NewEventOutbox(tx repository.Transactor, outboxRepo repository.OutboxRepository) *EventOutbox {
+	return &EventOutbox{
+		tx:         tx,
+		outboxRepo: outboxRepo,
+	}
+}
+
+// Write はwriteの書き込みとイベントの保存を同じトランザクションで確定する
+// writeが失敗した場合はイベントを保存しない
+func (o *EventOutbox) Write(ctx context.Context, write func(ctx context.Context) error, events ...*model.DomainEvent) error {
+	if o == nil {
+		return write(ctx)
+	}
+	return o.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := write(ctx); err != nil {
+			return err
+		}
+		return o.outboxRepo.Append(ctx, events...)
+	})
+}
+
+// DeleteUserEvents はユーザーを対象とする配信待ちのイベントを削除し、削除した件数を返す（アカウントの消去で使用する）
+// nilのEventOutboxはイベントを保存しないため0を返す
+func (o *EventOutbox) DeleteUserEvents(ctx context.Context, userID string) (int, error) {
+	if o == nil {
+		return 0, nil
+	}
+	return o.outboxRepo.DeleteByUserID(ctx, userID)
+}
+
+// sessionRevokedEvent はセッションを無効化したイベントを作成する（reasonはlogout・token_reuse・admin・reported_not_me・account_deletedなど）
+func sessionRevokedEvent(userID, actorID, reason string) *model.DomainEvent {
+	return model.NewDomainEvent(model.DomainEventSessionRevoked, userID, actorID, map[string]string{"reason": reason})
+}
+
+// roleChangedEvent はユーザーのロールを変更したイベントを作成する
+func roleChangedEvent(user *model.User, from model.Role, actorID string) *model.DomainEvent {
+	return model.NewDomainEvent(model.DomainEventUserRoleChanged, user.ID, actorID,
+		map[string]string{"from": string(from), "to": string(user.Role)})
+}
@@ -0,0 +1,333 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"stackies-backend/core"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/repository"
+	"stackies-backend/domain/service"
+	"time"
+)
+
+const (
+	// loginHistorySize はリスク判定で参照する過去のログイン成功件数
+	loginHistorySize = 50
+	// failureBurstWindow はログイン失敗の集中を判定する期間
+	failureBurstWindow = 15 * time.Minute
+	// failureBurstThreshold はfailureBurstWindow内にこの件数以上失敗していれば兆候とみなす
+	failureBurstThreshold = 5
+	// maxTravelSpeedKmh は前回ログイン地点からの移動速度の上限（旅客機程度）
+	maxTravelSpeedKmh = 1000.0
+	// minTravelDistanceKm はGeoIPの誤差を考慮し、不可能な移動とみなす最小距離
+	minTravelDistanceKm = 500.0
+	// revocationTokenTTL は「心当たりがない」リンクの有効期間
+	revocationTokenTTL = 7 * 24 * time.Hour
+	// revokeReasonNotMe はユーザーが「心当たりがない」と報告した場合の無効化理由
+	revokeReasonNotMe = "reported_not_me"
+)
+
+var (
+	ErrInvalidRevocationToken = core.NewAppError("invalid_revocation_token", http.StatusBadRequest, "Invalid or expired link")
+)
+
+// LoginRiskUsecase はログインのリスク判定と不審なログインへの対応を抽象化する
+type LoginRiskUsecase interface {
+	Evaluate(ctx context.Context, input *EvaluateLoginRiskInput) (*model.RiskAssessment, error)
+	ReportNotMe(ctx context.Context, input *ReportNotMeInput) error
+}
+
+type (
+	// EvaluateLoginRiskInput はログインのリスク判定の入力パラメータを表す
+	EvaluateLoginRiskInput struct {
+		User      *model.User
+		ClientIP  string
+		UserAgent string
+	}
+
+	// ReportNotMeInput は「心当たりがない」報告の入力パラメータを表す
+	ReportNotMeInput struct {
+		Token     string
+		ClientIP  string
+		UserAgent string
+	}
+
+	// LoginRiskUsecaseImpl はLoginRiskUsecaseの実装
+	LoginRiskUsecaseImpl struct {
+		userRepo       repository.UserRepository
+		auditRepo      repository.AuditRepository
+		authRepo       repository.AuthRepository
+		revocationRepo repository.RevocationTokenRepository
+		events         *EventOutbox
+		geoIP          service.GeoIPService
+		notifier       service.LoginAlertNotifier
+		revokeURL      string
+		now            func() time.Time
+	}
+)
+
+// NewLoginRiskUsecase は新しいLoginRiskUsecaseを作成する
+// geoIPがnilの場合、不可能な移動の判定は行わない
+// revokeURLは通知に含める「心当たりがない」リンクの遷移先で、tokenクエリが付与される
+// eventsがnilの場合、ドメインイベントは保存しない
+func NewLoginRiskUsecase(
+	userRepo repository.UserRepository,
+	auditRepo repository.AuditRepository,
+	authRepo repository.AuthRepository,
+	revocationRepo repository.RevocationTokenRepository,
+	events *EventOutbox,
+	geoIP service.GeoIPService,
+	notifier service.LoginAlertNotifier,
+	revokeURL string,
+) LoginRiskUsecase {
+	return &LoginRiskUsecaseImpl{
+		userRepo:       userRepo,
+		auditRepo:      auditRepo,
+		authRepo:       authRepo,
+		revocationRepo: revocationRepo,
+		events:         events,
+		geoIP:          geoIP,
+		notifier:       notifier,
+		revokeURL:      revokeURL,
+		now:            time.Now,
+	}
+}
+
+// Evaluate はログイン履歴と比較してリスクを判定し、必要に応じてユーザーに通知する
+// 今回のログインを監査ログに記録する前に呼び出す必要がある
+func (l *LoginRiskUsecaseImpl) Evaluate(ctx context.Context, input *EvaluateLoginRiskInput) (*model.RiskAssessment, error) {
+	now := l.now()
+	device := model.DeviceFingerprint(input.UserAgent)
+	ipRange := model.IPRange(input.ClientIP)
+
+	history, err := l.auditRepo.Query(ctx, &model.AuditEventFilter{
+		UserID:  input.User.ID,
+		Type:    model.AuditEventLogin,
+		Outcome: model.AuditOutcomeSuccess,
+		Limit:   loginHistorySize,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var signals []model.RiskSignal
+
+	// 初回ログインは比較対象がないため、端末とIPアドレス帯の判定を行わない
+	if len(history.Events) > 0 {
+		reportedDevices, reportedRanges, err := l.reportedEnvironments(ctx, input.User.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		knownDevice, knownRange := false, false
+		for _, event := range history.Events {
+			if d := event.Metadata["device"]; d != "" && d == device && !reportedDevices[d] {
+				knownDevice = true
+			}
+			if r := model.IPRange(event.IPAddress); r != "" && r == ipRange && !reportedRanges[r] {
+				knownRange = true
+			}
+		}
+		if !knownDevice {
+			signals = append(signals, model.RiskSignalNewDevice)
+		}
+		if !knownRange {
+			signals = append(signals, model.RiskSignalNewIPRange)
+		}
+	}
+
+	location := l.lookup(input.ClientIP)
+	if len(history.Events) > 0 && l.isImpossibleTravel(history.Events[0], location, now) {
+		signals = append(signals, model.RiskSignalImpossibleTravel)
+	}
+
+	if input.ClientIP != "" {
+		failures, err := l.auditRepo.Query(ctx, &model.AuditEventFilter{
+			IPAddress: input.ClientIP,
+			Type:      model.AuditEventLogin,
+			Outcome:   model.AuditOutcomeFailure,
+			From:      now.Add(-failureBurstWindow),
+			Limit:     failureBurstThreshold,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(failures.Events) >= failureBurstThreshold {
+			signals = append(signals, model.RiskSignalFailureBurst)
+		}
+	}
+
+	assessment := model.NewRiskAssessment(signals)
+	if action := assessment.AlertAction(); action != "" {
+		l.alert(ctx, action, assessment, input, device, ipRange, location, now)
+	}
+
+	return assessment, nil
+}
+
+// ReportNotMe は通知の「心当たりがない」リンクからの報告を受け、ユーザーのすべてのセッションを無効化する
+// リフレッシュトークンを削除し、発行済みのアクセストークンも認証時に拒否されるようにする
+func (l *LoginRiskUsecaseImpl) ReportNotMe(ctx context.Context, input *ReportNotMeInput) error {
+	if input.Token == "" {
+		return ErrInvalidRevocationToken
+	}
+
+	token, err := l.revocationRepo.ConsumeRevocationToken(ctx, input.Token)
+	if err != nil {
+		if errors.Is(err, repository.ErrRevocationTokenNotFound) {
+			return ErrInvalidRevocationToken.Wrap(err)
+		}
+		return err
+	}
+
+	user, err := l.userRepo.FindByID(ctx, token.UserID)
+	if err != nil {
+		// 通知の後に消去されたユーザーは無効化するセッションがない
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return ErrInvalidRevocationToken.Wrap(err)
+		}
+		return err
+	}
+	user.RevokeSessions(l.now())
+	err = l.events.Write(ctx, func(ctx context.Context) error {
+		if err := l.userRepo.Update(ctx, user); err != nil {
+			return err
+		}
+		return l.authRepo.DeleteToken(ctx, token.UserID)
+	}, sessionRevokedEvent(token.UserID, token.UserID, revokeReasonNotMe))
+	if err != nil {
+		return err
+	}
+
+	// 報告された端末とIPアドレス帯は以降のリスク判定で既知として扱わない
+	event := newAuditEvent(model.AuditEventSessionRevoke, model.AuditOutcomeSuccess, token.UserID, token.UserID, input.ClientIP, input.UserAgent)
+	event.Reason = revokeReasonNotMe
+	event.Metadata = map[string]string{"device": token.Device, "ip_range": token.IPRange}
+	recordAuditEvent(ctx, l.auditRepo, event)
+
+	return nil
+}
+
+// reportedEnvironments はユーザーが「心当たりがない」と報告した端末とIPアドレス帯を返す
+func (l *LoginRiskUsecaseImpl) reportedEnvironments(ctx context.Context, userID string) (map[string]bool, map[string]bool, error) {
+	page, err := l.auditRepo.Query(ctx, &model.AuditEventFilter{
+		UserID: userID,
+		Type:   model.AuditEventSessionRevoke,
+		Limit:  model.MaxAuditPageSize,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	devices := make(map[string]bool)
+	ranges := make(map[string]bool)
+	for _, event := range page.Events {
+		if event.Reason != revokeReasonNotMe {
+			continue
+		}
+		if d := event.Metadata["device"]; d != "" {
+			devices[d] = true
+		}
+		if r := event.Metadata["ip_range"]; r != "" {
+			ranges[r] = true
+		}
+	}
+	return devices, ranges, nil
+}
+
+// isImpossibleTravel は前回ログイン地点から現在地点への移動が物理的に不可能かどうかを判定する
+func (l *LoginRiskUsecaseImpl) isImpossibleTravel(previous *model.AuditEvent, current *model.GeoLocation, now time.Time) bool {
+	if current == nil || previous.IPAddress == "" {
+		return false
+	}
+	origin := l.lookup(previous.IPAddress)
+	if origin == nil {
+		return false
+	}
+
+	distance := origin.DistanceKm(current)
+	if distance < minTravelDistanceKm {
+		return false
+	}
+
+	hours := now.Sub(previous.CreatedAt).Hours()
+	if hours <= 0 {
+		return true
+	}
+	return distance/hours > maxTravelSpeedKmh
+}
+
+// lookup はIPアドレスの位置を返す（GeoIPが未設定または特定できない場合はnil）
+func (l *LoginRiskUsecaseImpl) lookup(ip string) *model.GeoLocation {
+	if l.geoIP == nil || ip == "" {
+		return nil
+	}
+	location, err := l.geoIP.Lookup(ip)
+	if err != nil {
+		slog.Warn("failed to lookup geoip", slog.String("ip", ip), slog.Any("error", err))
+		return nil
+	}
+	return location
+}
+
+// alert は「心当たりがない」リンクを発行し、ユーザーに通知する
+// 通知に失敗してもログイン自体は継続させ、失敗はログに残す
+func (l *LoginRiskUsecaseImpl) alert(
+	ctx context.Context,
+	action model.LoginAlertAction,
+	assessment *model.RiskAssessment,
+	input *EvaluateLoginRiskInput,
+	device, ipRange string,
+	location *model.GeoLocation,
+	now time.Time,
+) {
+	if l.notifier == nil {
+		return
+	}
+
+	tokenValue, err := generateRandomToken(32)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to generate revocation token", slog.Any("error", err))
+		return
+	}
+	token, err := model.NewRevocationToken(tokenValue, input.User.ID, device, ipRange, revocationTokenTTL)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to create revocation token", slog.Any("error", err))
+		return
+	}
+	if err := l.revocationRepo.SaveRevocationToken(ctx, token); err != nil {
+		slog.ErrorContext(ctx, "failed to save revocation token", slog.Any("error", err))
+		return
+	}
+
+	alert := &model.LoginAlert{
+		Action:     action,
+		UserID:     input.User.ID,
+		Email:      input.User.Email,
+		Name:       input.User.Name,
+		Assessment: assessment,
+		IPAddress:  input.ClientIP,
+		UserAgent:  input.UserAgent,
+		Location:   location,
+		OccurredAt: now,
+		RevokeURL:  l.buildRevokeURL(token.Token),
+	}
+	if err := l.notifier.Notify(ctx, alert); err != nil {
+		slog.ErrorContext(ctx, "failed to notify login alert", slog.String("subject_id", input.User.ID), slog.Any("error", err))
+	}
+}
+
+// buildRevokeURL は「心当たりがない」リンクのURLを組み立てる
+func (l *LoginRiskUsecaseImpl) buildRevokeURL(token string) string {
+	parsed, err := url.Parse(l.revokeURL)
+	if err != nil {
+		return l.revokeURL
+	}
+	query := parsed.Query()
+	query.Set("token", token)
+	parsed.RawQuery = query.Encode()
+	return parsed.String()
+}
@@ -0,0 +1,325 @@
+package usecase
+
+import (
+	"context"
+	"net/url"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/repository"
+	"stackies-backend/domain/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockRevocationTokenRepository はRevocationTokenRepositoryのモック
+type MockRevocationTokenRepository struct {
+	mock.Mock
+}
+
+var _ repository.RevocationTokenRepository = (*MockRevocationTokenRepository)(nil)
+
+func (m *MockRevocationTokenRepository) SaveRevocationToken(ctx context.Context, token *model.RevocationToken) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func (m *MockRevocationTokenRepository) ConsumeRevocationToken(ctx context.Context, token string) (*model.RevocationToken, error) {
+	args := m.Called(ctx, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.RevocationToken), args.Error(1)
+}
+
+// MockGeoIPService はGeoIPServiceのモック
+type MockGeoIPService struct {
+	mock.Mock
+}
+
+var _ service.GeoIPService = (*MockGeoIPService)(nil)
+
+func (m *MockGeoIPService) Lookup(ip string) (*model.GeoLocation, error) {
+	args := m.Called(ip)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.GeoLocation), args.Error(1)
+}
+
+// MockLoginAlertNotifier はLoginAlertNotifierのモック
+type MockLoginAlertNotifier struct {
+	mock.Mock
+}
+
+var _ service.LoginAlertNotifier = (*MockLoginAlertNotifier)(nil)
+
+func (m *MockLoginAlertNotifier) Notify(ctx context.Context, alert *model.LoginAlert) error {
+	args := m.Called(ctx, alert)
+	return args.Error(0)
+}
+
+// auditQueryOf は指定した種類と結果の監査ログ検索に一致するmatcherを返す
+func auditQueryOf(eventType model.AuditEventType, outcome model.AuditOutcome) interface{} {
+	return mock.MatchedBy(func(filter *model.AuditEventFilter) bool {
+		return filter.Type == eventType && filter.Outcome == outcome
+	})
+}
+
+func TestLoginRiskUsecaseImpl_Evaluate(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	user := &model.User{ID: "user_1", Email: "user@example.com", Name: "Test User"}
+	knownUA := "Mozilla/5.0 (Macintosh) Chrome/120.0"
+	knownLogin := &model.AuditEvent{
+		Type:      model.AuditEventLogin,
+		Outcome:   model.AuditOutcomeSuccess,
+		SubjectID: "user_1",
+		IPAddress: "203.0.113.10",
+		Metadata:  map[string]string{"device": model.DeviceFingerprint(knownUA)},
+		CreatedAt: now.Add(-time.Hour),
+	}
+	tokyo := &model.GeoLocation{Country: "JP", City: "Tokyo", Latitude: 35.6762, Longitude: 139.6503}
+	newYork := &model.GeoLocation{Country: "US", City: "New York", Latitude: 40.7128, Longitude: -74.0060}
+
+	page := func(events ...*model.AuditEvent) *model.AuditEventPage {
+		return &model.AuditEventPage{Events: events}
+	}
+	failures := func(n int) *model.AuditEventPage {
+		events := make([]*model.AuditEvent, n)
+		for i := range events {
+			events[i] = &model.AuditEvent{Type: model.AuditEventLogin, Outcome: model.AuditOutcomeFailure}
+		}
+		return page(events...)
+	}
+
+	tests := []struct {
+		testName        string
+		clientIP        string
+		userAgent       string
+		setupMocks      func(*MockAuditRepository, *MockGeoIPService)
+		expectedLevel   model.RiskLevel
+		expectedSignals []model.RiskSignal
+		expectedAction  model.LoginAlertAction
+	}{
+		{
+			testName:  "初回ログインは比較対象がないため低リスク",
+			clientIP:  "198.51.100.7",
+			userAgent: knownUA,
+			setupMocks: func(auditRepo *MockAuditRepository, geoIP *MockGeoIPService) {
+				auditRepo.On("Query", mock.Anything, auditQueryOf(model.AuditEventLogin, model.AuditOutcomeSuccess)).Return(page(), nil)
+				auditRepo.On("Query", mock.Anything, auditQueryOf(model.AuditEventLogin, model.AuditOutcomeFailure)).Return(failures(0), nil)
+				geoIP.On("Lookup", "198.51.100.7").Return(nil, nil)
+			},
+			expectedLevel:   model.RiskLevelLow,
+			expectedSignals: []model.RiskSignal{},
+		},
+		{
+			testName:  "既知の端末とIPアドレス帯",
+			clientIP:  "203.0.113.20",
+			userAgent: knownUA,
+			setupMocks: func(auditRepo *MockAuditRepository, geoIP *MockGeoIPService) {
+				auditRepo.On("Query", mock.Anything, auditQueryOf(model.AuditEventLogin, model.AuditOutcomeSuccess)).Return(page(knownLogin), nil)
+				auditRepo.On("Query", mock.Anything, auditQueryOf(model.AuditEventSessionRevoke, "")).Return(page(), nil)
+				auditRepo.On("Query", mock.Anything, auditQueryOf(model.AuditEventLogin, model.AuditOutcomeFailure)).Return(failures(0), nil)
+				geoIP.On("Lookup", "203.0.113.20").Return(tokyo, nil)
+				geoIP.On("Lookup", "203.0.113.10").Return(tokyo, nil)
+			},
+			expectedLevel:   model.RiskLevelLow,
+			expectedSignals: []model.RiskSignal{},
+		},
+		{
+			testName:  "新しい端末と新しいIPアドレス帯はメール通知",
+			clientIP:  "198.51.100.7",
+			userAgent: "curl/8.0",
+			setupMocks: func(auditRepo *MockAuditRepository, geoIP *MockGeoIPService) {
+				auditRepo.On("Query", mock.Anything, auditQueryOf(model.AuditEventLogin, model.AuditOutcomeSuccess)).Return(page(knownLogin), nil)
+				auditRepo.On("Query", mock.Anything, auditQueryOf(model.AuditEventSessionRevoke, "")).Return(page(), nil)
+				auditRepo.On("Query", mock.Anything, auditQueryOf(model.AuditEventLogin, model.AuditOutcomeFailure)).Return(failures(0), nil)
+				geoIP.On("Lookup", "198.51.100.7").Return(tokyo, nil)
+				geoIP.On("Lookup", "203.0.113.10").Return(tokyo, nil)
+			},
+			expectedLevel:   model.RiskLevelMedium,
+			expectedSignals: []model.RiskSignal{model.RiskSignalNewDevice, model.RiskSignalNewIPRange},
+			expectedAction:  model.LoginAlertActionEmail,
+		},
+		{
+			testName:  "不可能な移動は追加認証を要求",
+			clientIP:  "198.51.100.7",
+			userAgent: knownUA,
+			setupMocks: func(auditRepo *MockAuditRepository, geoIP *MockGeoIPService) {
+				auditRepo.On("Query", mock.Anything, auditQueryOf(model.AuditEventLogin, model.AuditOutcomeSuccess)).Return(page(knownLogin), nil)
+				auditRepo.On("Query", mock.Anything, auditQueryOf(model.AuditEventSessionRevoke, "")).Return(page(), nil)
+				auditRepo.On("Query", mock.Anything, auditQueryOf(model.AuditEventLogin, model.AuditOutcomeFailure)).Return(failures(0), nil)
+				geoIP.On("Lookup", "198.51.100.7").Return(newYork, nil)
+				geoIP.On("Lookup", "203.0.113.10").Return(tokyo, nil)
+			},
+			expectedLevel:   model.RiskLevelHigh,
+			expectedSignals: []model.RiskSignal{model.RiskSignalNewIPRange, model.RiskSignalImpossibleTravel},
+			expectedAction:  model.LoginAlertActionUrgentEmail,
+		},
+		{
+			testName:  "報告済みの端末は既知として扱わない",
+			clientIP:  "203.0.113.20",
+			userAgent: knownUA,
+			setupMocks: func(auditRepo *MockAuditRepository, geoIP *MockGeoIPService) {
+				reported := &model.AuditEvent{
+					Type:     model.AuditEventSessionRevoke,
+					Outcome:  model.AuditOutcomeSuccess,
+					Reason:   revokeReasonNotMe,
+					Metadata: map[string]string{"device": model.DeviceFingerprint(knownUA)},
+				}
+				auditRepo.On("Query", mock.Anything, auditQueryOf(model.AuditEventLogin, model.AuditOutcomeSuccess)).Return(page(knownLogin), nil)
+				auditRepo.On("Query", mock.Anything, auditQueryOf(model.AuditEventSessionRevoke, "")).Return(page(reported), nil)
+				auditRepo.On("Query", mock.Anything, auditQueryOf(model.AuditEventLogin, model.AuditOutcomeFailure)).Return(failures(0), nil)
+				geoIP.On("Lookup", "203.0.113.20").Return(nil, nil)
+			},
+			expectedLevel:   model.RiskLevelMedium,
+			expectedSignals: []model.RiskSignal{model.RiskSignalNewDevice},
+			expectedAction:  model.LoginAlertActionEmail,
+		},
+		{
+			testName:  "同じIPアドレスからのログイン失敗の集中",
+			clientIP:  "198.51.100.7",
+			userAgent: knownUA,
+			setupMocks: func(auditRepo *MockAuditRepository, geoIP *MockGeoIPService) {
+				auditRepo.On("Query", mock.Anything, auditQueryOf(model.AuditEventLogin, model.AuditOutcomeSuccess)).Return(page(), nil)
+				auditRepo.On("Query", mock.Anything, mock.MatchedBy(func(filter *model.AuditEventFilter) bool {
+					return filter.Outcome == model.AuditOutcomeFailure &&
+						filter.IPAddress == "198.51.100.7" &&
+						filter.From.Equal(now.Add(-failureBurstWindow))
+				})).Return(failures(failureBurstThreshold), nil)
+				geoIP.On("Lookup", "198.51.100.7").Return(nil, nil)
+			},
+			expectedLevel:   model.RiskLevelMedium,
+			expectedSignals: []model.RiskSignal{model.RiskSignalFailureBurst},
+			expectedAction:  model.LoginAlertActionEmail,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			auditRepo := new(MockAuditRepository)
+			authRepo := new(MockAuthRepository)
+			revocationRepo := new(MockRevocationTokenRepository)
+			geoIP := new(MockGeoIPService)
+			notifier := new(MockLoginAlertNotifier)
+			tt.setupMocks(auditRepo, geoIP)
+
+			if tt.expectedAction != "" {
+				revocationRepo.On("SaveRevocationToken", mock.Anything, mock.MatchedBy(func(token *model.RevocationToken) bool {
+					return token.UserID == "user_1" &&
+						token.Device == model.DeviceFingerprint(tt.userAgent) &&
+						token.IPRange == model.IPRange(tt.clientIP)
+				})).Return(nil)
+				notifier.On("Notify", mock.Anything, mock.MatchedBy(func(alert *model.LoginAlert) bool {
+					revokeURL, err := url.Parse(alert.RevokeURL)
+					return err == nil &&
+						alert.Action == tt.expectedAction &&
+						alert.Email == "user@example.com" &&
+						alert.IPAddress == tt.clientIP &&
+						revokeURL.Path == "/security/not-me" &&
+						revokeURL.Query().Get("token") != ""
+				})).Return(nil)
+			}
+
+			usecase := NewLoginRiskUsecase(new(MockUserRepository), auditRepo, authRepo, revocationRepo, nil, geoIP, notifier, "http://localhost:5173/security/not-me")
+			usecase.(*LoginRiskUsecaseImpl).now = func() time.Time { return now }
+
+			assessment, err := usecase.Evaluate(context.Background(), &EvaluateLoginRiskInput{
+				User:      user,
+				ClientIP:  tt.clientIP,
+				UserAgent: tt.userAgent,
+			})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedLevel, assessment.Level)
+			assert.Equal(t, tt.expectedSignals, assessment.Signals)
+
+			auditRepo.AssertExpectations(t)
+			revocationRepo.AssertExpectations(t)
+			geoIP.AssertExpectations(t)
+			notifier.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLoginRiskUsecaseImpl_ReportNotMe(t *testing.T) {
+	tests := []struct {
+		testName      string
+		token         string
+		setupMocks    func(*MockUserRepository, *MockAuditRepository, *MockAuthRepository, *MockRevocationTokenRepository)
+		expectedError error
+	}{
+		{
+			testName: "全セッションを無効化して記録",
+			token:    "valid_token",
+			setupMocks: func(userRepo *MockUserRepository, auditRepo *MockAuditRepository, authRepo *MockAuthRepository, revocationRepo *MockRevocationTokenRepository) {
+				revocationRepo.On("ConsumeRevocationToken", mock.Anything, "valid_token").
+					Return(&model.RevocationToken{Token: "valid_token", UserID: "user_1", Device: "device_1", IPRange: "198.51.100.0/24"}, nil)
+				userRepo.On("FindByID", mock.Anything, "user_1").Return(&model.User{ID: "user_1"}, nil)
+				// 発行済みのアクセストークンも使えないようにする
+				userRepo.On("Update", mock.Anything, mock.MatchedBy(func(user *model.User) bool {
+					return user.SessionsRevokedAt != nil
+				})).Return(nil)
+				authRepo.On("DeleteToken", mock.Anything, "user_1").Return(nil)
+				auditRepo.On("Append", mock.Anything, mock.MatchedBy(func(event *model.AuditEvent) bool {
+					return event.Type == model.AuditEventSessionRevoke &&
+						event.SubjectID == "user_1" &&
+						event.Reason == revokeReasonNotMe &&
+						event.Metadata["device"] == "device_1" &&
+						event.Metadata["ip_range"] == "198.51.100.0/24"
+				})).Return(nil)
+			},
+		},
+		{
+			testName: "消去されたユーザー",
+			token:    "valid_token",
+			setupMocks: func(userRepo *MockUserRepository, auditRepo *MockAuditRepository, authRepo *MockAuthRepository, revocationRepo *MockRevocationTokenRepository) {
+				revocationRepo.On("ConsumeRevocationToken", mock.Anything, "valid_token").
+					Return(&model.RevocationToken{Token: "valid_token", UserID: "user_1"}, nil)
+				userRepo.On("FindByID", mock.Anything, "user_1").Return((*model.User)(nil), repository.ErrUserNotFound)
+			},
+			expectedError: ErrInvalidRevocationToken,
+		},
+		{
+			testName: "無効なトークン",
+			token:    "used_token",
+			setupMocks: func(userRepo *MockUserRepository, auditRepo *MockAuditRepository, authRepo *MockAuthRepository, revocationRepo *MockRevocationTokenRepository) {
+				revocationRepo.On("ConsumeRevocationToken", mock.Anything, "used_token").Return(nil, repository.ErrRevocationTokenNotFound)
+			},
+			expectedError: ErrInvalidRevocationToken,
+		},
+		{
+			testName: "トークンなし",
+			token:    "",
+			setupMocks: func(userRepo *MockUserRepository, auditRepo *MockAuditRepository, authRepo *MockAuthRepository, revocationRepo *MockRevocationTokenRepository) {
+			},
+			expectedError: ErrInvalidRevocationToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			userRepo := new(MockUserRepository)
+			auditRepo := new(MockAuditRepository)
+			authRepo := new(MockAuthRepository)
+			revocationRepo := new(MockRevocationTokenRepository)
+			tt.setupMocks(userRepo, auditRepo, authRepo, revocationRepo)
+
+			usecase := NewLoginRiskUsecase(userRepo, auditRepo, authRepo, revocationRepo, nil, nil, nil, "http://localhost:5173/security/not-me")
+			err := usecase.ReportNotMe(context.Background(), &ReportNotMeInput{Token: tt.token, ClientIP: "192.0.2.1"})
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			userRepo.AssertExpectations(t)
+			auditRepo.AssertExpectations(t)
+			authRepo.AssertExpectations(t)
+			revocationRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,286 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"stackies-backend/core"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/repository"
+	"stackies-backend/domain/service"
+	"strings"
+	"time"
+)
+
+const (
+	// oauthStateTTL はstateの有効期間
+	oauthStateTTL = 10 * time.Minute
+	// loginCodeTTL はワンタイムコードの有効期間
+	loginCodeTTL = time.Minute
+)
+
+var (
+	ErrInvalidState        = core.NewAppError("invalid_state", http.StatusBadRequest, "Invalid state parameter")
+	ErrRedirectNotAllowed  = core.NewAppError("redirect_not_allowed", http.StatusBadRequest, "Redirect URL is not allowed")
+	ErrInvalidLoginCode    = core.NewAppError("invalid_login_code", http.StatusBadRequest, "Invalid login code")
+	ErrNoRedirectURLConfig = core.NewAppError("no_redirect_url_configured", http.StatusInternalServerError, "No allowed redirect url configured")
+)
+
+// OAuthUsecase はサーバーサイドOAuthフローのビジネスロジックを抽象化する
+type OAuthUsecase interface {
+	GenerateAuthURL(ctx context.Context, input *GenerateAuthURLInput) (*GenerateAuthURLOutput, error)
+	HandleCallback(ctx context.Context, input *OAuthCallbackInput) (*OAuthCallbackOutput, error)
+	ExchangeLoginCode(ctx context.Context, input *ExchangeLoginCodeInput) (*GoogleLoginOutput, error)
+}
+
+type (
+	// GenerateAuthURLInput は認証URL生成の入力パラメータを表す
+	GenerateAuthURLInput struct {
+		RedirectURL string
+	}
+
+	// GenerateAuthURLOutput は認証URL生成の出力パラメータを表す
+	// Nonceはフローを開始したブラウザのCookieに保存し、コールバックで照合する
+	GenerateAuthURLOutput struct {
+		AuthURL   string
+		State     string
+		Nonce     string
+		ExpiresAt time.Time
+	}
+
+	// OAuthCallbackInput はOAuthコールバックの入力パラメータを表す
+	// Nonceはフローを開始したブラウザのCookieから取得した値
+	OAuthCallbackInput struct {
+		Code           string
+		State          string
+		Nonce          string
+		Error          string
+		IssueLoginCode bool
+		ClientIP       string
+		UserAgent      string
+	}
+
+	// OAuthCallbackOutput はOAuthコールバックの出力パラメータを表す
+	// Errorが空でない場合、ログインは失敗しておりRedirectURLにのみ意味がある
+	OAuthCallbackOutput struct {
+		RedirectURL string
+		Login       *GoogleLoginOutput
+		LoginCode   string
+		Error       string
+	}
+
+	// ExchangeLoginCodeInput はワンタイムコード交換の入力パラメータを表す
+	ExchangeLoginCodeInput struct {
+		Code string
+	}
+
+	// OAuthUsecaseImpl はOAuthUsecaseの実装
+	OAuthUsecaseImpl struct {
+		authUsecase         AuthUsecase
+		oauthRepo           repository.OAuthRepository
+		userRepo            repository.UserRepository
+		googleSvc           service.GoogleService
+		allowedRedirectURLs []*url.URL
+	}
+)
+
+// NewOAuthUsecase は新しいOAuthUsecaseを作成する
+// allowedRedirectURLsの先頭はリダイレクト先未指定時のデフォルトとして使用される
+func NewOAuthUsecase(
+	authUsecase AuthUsecase,
+	oauthRepo repository.OAuthRepository,
+	userRepo repository.UserRepository,
+	googleSvc service.GoogleService,
+	allowedRedirectURLs []string,
+) OAuthUsecase {
+	allowed := make([]*url.URL, 0, len(allowedRedirectURLs))
+	for _, raw := range allowedRedirectURLs {
+		parsed, err := url.Parse(strings.TrimSpace(raw))
+		if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			continue
+		}
+		allowed = append(allowed, parsed)
+	}
+
+	return &OAuthUsecaseImpl{
+		authUsecase:         authUsecase,
+		oauthRepo:           oauthRepo,
+		userRepo:            userRepo,
+		googleSvc:           googleSvc,
+		allowedRedirectURLs: allowed,
+	}
+}
+
+// GenerateAuthURL はstateを発行してGoogle認証URLを生成する
+func (o *OAuthUsecaseImpl) GenerateAuthURL(ctx context.Context, input *GenerateAuthURLInput) (*GenerateAuthURLOutput, error) {
+	redirectURL, err := o.resolveRedirectURL(input.RedirectURL)
+	if err != nil {
+		return nil, err
+	}
+
+	stateValue, err := generateRandomToken(16)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := generateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := model.NewOAuthState(stateValue, nonce, redirectURL, oauthStateTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := o.oauthRepo.SaveState(ctx, state); err != nil {
+		return nil, err
+	}
+
+	return &GenerateAuthURLOutput{
+		AuthURL:   o.googleSvc.GenerateAuthURL(stateValue),
+		State:     stateValue,
+		Nonce:     nonce,
+		ExpiresAt: state.ExpiresAt,
+	}, nil
+}
+
+// HandleCallback はstateを検証してログインを完了させる
+func (o *OAuthUsecaseImpl) HandleCallback(ctx context.Context, input *OAuthCallbackInput) (*OAuthCallbackOutput, error) {
+	// 1. stateを検証（一度使用したら削除される）
+	if input.State == "" {
+		return nil, ErrInvalidState
+	}
+	state, err := o.oauthRepo.ConsumeState(ctx, input.State)
+	if err != nil {
+		if errors.Is(err, repository.ErrStateNotFound) {
+			return nil, ErrInvalidState
+		}
+		return nil, err
+	}
+	// フローを開始したブラウザ以外でコールバックを開かせるログインCSRFを防ぐ
+	if !state.MatchesNonce(input.Nonce) {
+		return nil, ErrInvalidState
+	}
+
+	output := &OAuthCallbackOutput{RedirectURL: state.RedirectURL}
+
+	// 2. Google側でエラーになった場合はフロントエンドに通知する
+	if input.Error != "" {
+		output.Error = input.Error
+		return output, nil
+	}
+	if input.Code == "" {
+		output.Error = "invalid_request"
+		return output, nil
+	}
+
+	// 3. ログイン処理
+	login, err := o.authUsecase.GoogleLogin(ctx, &GoogleLoginInput{
+		AuthorizationCode: input.Code,
+		ClientIP:          input.ClientIP,
+		UserAgent:         input.UserAgent,
+	})
+	if err != nil {
+		output.Error = "login_failed"
+		return output, nil
+	}
+	output.Login = login
+
+	// 4. 必要であればSPA用のワンタイムコードを発行
+	if input.IssueLoginCode {
+		codeValue, err := generateRandomToken(32)
+		if err != nil {
+			return nil, err
+		}
+		code, err := model.NewLoginCode(codeValue, login.User.ID, login.AccessToken, login.RefreshToken, login.ExpiresIn, loginCodeTTL)
+		if err != nil {
+			return nil, err
+		}
+		if err := o.oauthRepo.SaveLoginCode(ctx, code); err != nil {
+			return nil, err
+		}
+		output.LoginCode = codeValue
+	}
+
+	return output, nil
+}
+
+// ExchangeLoginCode はワンタイムコードをトークンと交換する
+func (o *OAuthUsecaseImpl) ExchangeLoginCode(ctx context.Context, input *ExchangeLoginCodeInput) (*GoogleLoginOutput, error) {
+	if input.Code == "" {
+		return nil, ErrInvalidLoginCode
+	}
+
+	code, err := o.oauthRepo.ConsumeLoginCode(ctx, input.Code)
+	if err != nil {
+		if errors.Is(err, repository.ErrLoginCodeNotFound) {
+			return nil, ErrInvalidLoginCode
+		}
+		return nil, err
+	}
+
+	user, err := o.userRepo.FindByID(ctx, code.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GoogleLoginOutput{
+		User:         user,
+		AccessToken:  code.AccessToken,
+		RefreshToken: code.RefreshToken,
+		ExpiresIn:    code.ExpiresIn,
+	}, nil
+}
+
+// resolveRedirectURL はリダイレクト先が許可リストに含まれるかを検証する
+func (o *OAuthUsecaseImpl) resolveRedirectURL(redirectURL string) (string, error) {
+	if redirectURL == "" {
+		if len(o.allowedRedirectURLs) == 0 {
+			return "", ErrNoRedirectURLConfig
+		}
+		return o.allowedRedirectURLs[0].String(), nil
+	}
+
+	target, err := url.Parse(redirectURL)
+	if err != nil || target.User != nil {
+		return "", ErrRedirectNotAllowed
+	}
+
+	for _, allowed := range o.allowedRedirectURLs {
+		if target.Scheme == allowed.Scheme &&
+			target.Host == allowed.Host &&
+			redirectPathAllowed(target.Path, allowed.Path) {
+			return target.String(), nil
+		}
+	}
+
+	return "", ErrRedirectNotAllowed
+}
+
+// redirectPathAllowed はリダイレクト先のパスが許可されたパスと一致するか、その配下にあるかを判定する
+// 配下とみなすのは許可されたパスが/で終わる場合のみ（/appは/app-evilや/applicationを許可しない）
+// ブラウザが解決すると許可されたパスの外に出られるため、.や..のセグメントを含むパスは許可しない
+func redirectPathAllowed(target, allowed string) bool {
+	for _, segment := range strings.Split(target, "/") {
+		if segment == "." || segment == ".." {
+			return false
+		}
+	}
+	if target == allowed {
+		return true
+	}
+	return strings.HasSuffix(allowed, "/") && strings.HasPrefix(target, allowed)
+}
+
+// generateRandomToken はURLに埋め込めるランダムな文字列を生成する
+func generateRandomToken(size int) (string, error) {
+	bytes := make([]byte, size)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
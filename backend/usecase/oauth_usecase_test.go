@@ -0,0 +1,350 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockOAuthRepository はOAuthRepositoryのモック
+type MockOAuthRepository struct {
+	mock.Mock
+}
+
+var _ repository.OAuthRepository = (*MockOAuthRepository)(nil)
+
+func (m *MockOAuthRepository) SaveState(ctx context.Context, state *model.OAuthState) error {
+	args := m.Called(ctx, state)
+	return args.Error(0)
+}
+
+func (m *MockOAuthRepository) ConsumeState(ctx context.Context, state string) (*model.OAuthState, error) {
+	args := m.Called(ctx, state)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.OAuthState), args.Error(1)
+}
+
+func (m *MockOAuthRepository) SaveLoginCode(ctx context.Context, code *model.LoginCode) error {
+	args := m.Called(ctx, code)
+	return args.Error(0)
+}
+
+func (m *MockOAuthRepository) ConsumeLoginCode(ctx context.Context, code string) (*model.LoginCode, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.LoginCode), args.Error(1)
+}
+
+// MockAuthUsecase はAuthUsecaseのモック
+type MockAuthUsecase struct {
+	mock.Mock
+}
+
+var _ AuthUsecase = (*MockAuthUsecase)(nil)
+
+func (m *MockAuthUsecase) GoogleLogin(ctx context.Context, input *GoogleLoginInput) (*GoogleLoginOutput, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*GoogleLoginOutput), args.Error(1)
+}
+
+func (m *MockAuthUsecase) RefreshToken(ctx context.Context, input *RefreshTokenInput) (*RefreshTokenOutput, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*RefreshTokenOutput), args.Error(1)
+}
+
+func (m *MockAuthUsecase) Logout(ctx context.Context, input *LogoutInput) error {
+	args := m.Called(ctx, input)
+	return args.Error(0)
+}
+
+var allowedRedirectURLs = []string{"http://localhost:5173/", "https://app.example.com/auth/", "https://app.example.com/app"}
+
+func TestOAuthUsecaseImpl_GenerateAuthURL(t *testing.T) {
+	tests := []struct {
+		testName         string
+		redirectURL      string
+		expectError      error
+		expectedRedirect string
+	}{
+		{
+			testName:         "リダイレクト先未指定の場合はデフォルトを使用",
+			redirectURL:      "",
+			expectError:      nil,
+			expectedRedirect: "http://localhost:5173/",
+		},
+		{
+			testName:         "許可リストに含まれるリダイレクト先",
+			redirectURL:      "https://app.example.com/auth/done?next=/home",
+			expectError:      nil,
+			expectedRedirect: "https://app.example.com/auth/done?next=/home",
+		},
+		{
+			testName:    "許可されていないホスト",
+			redirectURL: "https://evil.example.com/auth/",
+			expectError: ErrRedirectNotAllowed,
+		},
+		{
+			testName:    "許可されていないパス",
+			redirectURL: "https://app.example.com/other",
+			expectError: ErrRedirectNotAllowed,
+		},
+		{
+			testName:         "末尾が/でない許可パスと完全に一致",
+			redirectURL:      "https://app.example.com/app?next=/home",
+			expectedRedirect: "https://app.example.com/app?next=/home",
+		},
+		{
+			testName:    "許可パスの続きに文字が続くパス",
+			redirectURL: "https://app.example.com/app-evil",
+			expectError: ErrRedirectNotAllowed,
+		},
+		{
+			testName:    "許可パスを前方に含む別のパス",
+			redirectURL: "https://app.example.com/application",
+			expectError: ErrRedirectNotAllowed,
+		},
+		{
+			testName:    "末尾が/でない許可パスの配下",
+			redirectURL: "https://app.example.com/app/settings",
+			expectError: ErrRedirectNotAllowed,
+		},
+		{
+			testName:    "..で許可パスの外に出るパス",
+			redirectURL: "https://app.example.com/auth/../admin",
+			expectError: ErrRedirectNotAllowed,
+		},
+		{
+			testName:    "エンコードした..で許可パスの外に出るパス",
+			redirectURL: "https://app.example.com/auth/%2e%2e/admin",
+			expectError: ErrRedirectNotAllowed,
+		},
+		{
+			testName:    "ユーザー情報を含むURL",
+			redirectURL: "https://user@app.example.com/auth/",
+			expectError: ErrRedirectNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			oauthRepo := new(MockOAuthRepository)
+			googleSvc := new(MockGoogleService)
+			if tt.expectError == nil {
+				oauthRepo.On("SaveState", mock.Anything, mock.MatchedBy(func(s *model.OAuthState) bool {
+					return s.RedirectURL == tt.expectedRedirect && s.NonceHash != ""
+				})).Return(nil)
+				googleSvc.On("GenerateAuthURL", mock.AnythingOfType("string")).Return("https://accounts.google.com/o/oauth2/auth")
+			}
+
+			usecase := NewOAuthUsecase(new(MockAuthUsecase), oauthRepo, new(MockUserRepository), googleSvc, allowedRedirectURLs)
+			result, err := usecase.GenerateAuthURL(context.Background(), &GenerateAuthURLInput{RedirectURL: tt.redirectURL})
+
+			if tt.expectError != nil {
+				assert.ErrorIs(t, err, tt.expectError)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.NotEmpty(t, result.State)
+				assert.NotEmpty(t, result.Nonce)
+				assert.NotEmpty(t, result.AuthURL)
+			}
+
+			oauthRepo.AssertExpectations(t)
+			googleSvc.AssertExpectations(t)
+		})
+	}
+}
+
+func TestOAuthUsecaseImpl_HandleCallback(t *testing.T) {
+	state, err := model.NewOAuthState("valid_state", "browser_nonce", "http://localhost:5173/", time.Minute)
+	assert.NoError(t, err)
+	login := &GoogleLoginOutput{
+		User:         &model.User{ID: "user_123"},
+		AccessToken:  "access_token",
+		RefreshToken: "refresh_token",
+		ExpiresIn:    time.Now().Add(time.Hour).Unix(),
+	}
+
+	tests := []struct {
+		testName        string
+		input           *OAuthCallbackInput
+		setupMocks      func(*MockAuthUsecase, *MockOAuthRepository)
+		expectError     error
+		expectedError   string
+		expectLoginCode bool
+	}{
+		{
+			testName: "正常なコールバック - Cookieモード",
+			input:    &OAuthCallbackInput{Code: "test_code", State: "valid_state", Nonce: "browser_nonce"},
+			setupMocks: func(authUC *MockAuthUsecase, oauthRepo *MockOAuthRepository) {
+				oauthRepo.On("ConsumeState", mock.Anything, "valid_state").Return(state, nil)
+				authUC.On("GoogleLogin", mock.Anything, &GoogleLoginInput{AuthorizationCode: "test_code"}).Return(login, nil)
+			},
+		},
+		{
+			testName: "正常なコールバック - ワンタイムコード発行",
+			input:    &OAuthCallbackInput{Code: "test_code", State: "valid_state", Nonce: "browser_nonce", IssueLoginCode: true},
+			setupMocks: func(authUC *MockAuthUsecase, oauthRepo *MockOAuthRepository) {
+				oauthRepo.On("ConsumeState", mock.Anything, "valid_state").Return(state, nil)
+				authUC.On("GoogleLogin", mock.Anything, &GoogleLoginInput{AuthorizationCode: "test_code"}).Return(login, nil)
+				oauthRepo.On("SaveLoginCode", mock.Anything, mock.AnythingOfType("*model.LoginCode")).Return(nil)
+			},
+			expectLoginCode: true,
+		},
+		{
+			testName: "無効なstate",
+			input:    &OAuthCallbackInput{Code: "test_code", State: "unknown_state"},
+			setupMocks: func(authUC *MockAuthUsecase, oauthRepo *MockOAuthRepository) {
+				oauthRepo.On("ConsumeState", mock.Anything, "unknown_state").Return(nil, repository.ErrStateNotFound)
+			},
+			expectError: ErrInvalidState,
+		},
+		{
+			testName: "フローを開始したブラウザのnonceと一致しない",
+			input:    &OAuthCallbackInput{Code: "test_code", State: "valid_state", Nonce: "attacker_nonce"},
+			setupMocks: func(authUC *MockAuthUsecase, oauthRepo *MockOAuthRepository) {
+				oauthRepo.On("ConsumeState", mock.Anything, "valid_state").Return(state, nil)
+			},
+			expectError: ErrInvalidState,
+		},
+		{
+			testName: "nonceのCookieがない",
+			input:    &OAuthCallbackInput{Code: "test_code", State: "valid_state"},
+			setupMocks: func(authUC *MockAuthUsecase, oauthRepo *MockOAuthRepository) {
+				oauthRepo.On("ConsumeState", mock.Anything, "valid_state").Return(state, nil)
+			},
+			expectError: ErrInvalidState,
+		},
+		{
+			testName:    "stateなし",
+			input:       &OAuthCallbackInput{Code: "test_code"},
+			setupMocks:  func(authUC *MockAuthUsecase, oauthRepo *MockOAuthRepository) {},
+			expectError: ErrInvalidState,
+		},
+		{
+			testName: "Google側で拒否された場合",
+			input:    &OAuthCallbackInput{State: "valid_state", Nonce: "browser_nonce", Error: "access_denied"},
+			setupMocks: func(authUC *MockAuthUsecase, oauthRepo *MockOAuthRepository) {
+				oauthRepo.On("ConsumeState", mock.Anything, "valid_state").Return(state, nil)
+			},
+			expectedError: "access_denied",
+		},
+		{
+			testName: "ログイン失敗",
+			input:    &OAuthCallbackInput{Code: "bad_code", State: "valid_state", Nonce: "browser_nonce"},
+			setupMocks: func(authUC *MockAuthUsecase, oauthRepo *MockOAuthRepository) {
+				oauthRepo.On("ConsumeState", mock.Anything, "valid_state").Return(state, nil)
+				authUC.On("GoogleLogin", mock.Anything, &GoogleLoginInput{AuthorizationCode: "bad_code"}).Return(nil, errors.New("exchange failed"))
+			},
+			expectedError: "login_failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			authUC := new(MockAuthUsecase)
+			oauthRepo := new(MockOAuthRepository)
+			tt.setupMocks(authUC, oauthRepo)
+
+			usecase := NewOAuthUsecase(authUC, oauthRepo, new(MockUserRepository), new(MockGoogleService), allowedRedirectURLs)
+			result, err := usecase.HandleCallback(context.Background(), tt.input)
+
+			if tt.expectError != nil {
+				assert.ErrorIs(t, err, tt.expectError)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, state.RedirectURL, result.RedirectURL)
+				assert.Equal(t, tt.expectedError, result.Error)
+				if tt.expectedError == "" {
+					assert.Equal(t, login, result.Login)
+				}
+				assert.Equal(t, tt.expectLoginCode, result.LoginCode != "")
+			}
+
+			authUC.AssertExpectations(t)
+			oauthRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestOAuthUsecaseImpl_ExchangeLoginCode(t *testing.T) {
+	user := &model.User{ID: "user_123", Email: "test@example.com", Name: "Test User"}
+	code := &model.LoginCode{
+		Code:         "login_code",
+		UserID:       "user_123",
+		AccessToken:  "access_token",
+		RefreshToken: "refresh_token",
+		ExpiresIn:    3600,
+		ExpiresAt:    time.Now().Add(time.Minute),
+	}
+
+	tests := []struct {
+		testName    string
+		code        string
+		setupMocks  func(*MockOAuthRepository, *MockUserRepository)
+		expectError error
+	}{
+		{
+			testName: "正常なコード交換",
+			code:     "login_code",
+			setupMocks: func(oauthRepo *MockOAuthRepository, userRepo *MockUserRepository) {
+				oauthRepo.On("ConsumeLoginCode", mock.Anything, "login_code").Return(code, nil)
+				userRepo.On("FindByID", mock.Anything, "user_123").Return(user, nil)
+			},
+		},
+		{
+			testName: "無効なコード",
+			code:     "unknown_code",
+			setupMocks: func(oauthRepo *MockOAuthRepository, userRepo *MockUserRepository) {
+				oauthRepo.On("ConsumeLoginCode", mock.Anything, "unknown_code").Return(nil, repository.ErrLoginCodeNotFound)
+			},
+			expectError: ErrInvalidLoginCode,
+		},
+		{
+			testName:    "空のコード",
+			code:        "",
+			setupMocks:  func(oauthRepo *MockOAuthRepository, userRepo *MockUserRepository) {},
+			expectError: ErrInvalidLoginCode,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			oauthRepo := new(MockOAuthRepository)
+			userRepo := new(MockUserRepository)
+			tt.setupMocks(oauthRepo, userRepo)
+
+			usecase := NewOAuthUsecase(new(MockAuthUsecase), oauthRepo, userRepo, new(MockGoogleService), allowedRedirectURLs)
+			result, err := usecase.ExchangeLoginCode(context.Background(), &ExchangeLoginCodeInput{Code: tt.code})
+
+			if tt.expectError != nil {
+				assert.ErrorIs(t, err, tt.expectError)
+				assert.Nil(t, result)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, user, result.User)
+				assert.Equal(t, "access_token", result.AccessToken)
+			}
+
+			oauthRepo.AssertExpectations(t)
+			userRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,844 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"stackies-backend/core"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/repository"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// scimDeprovisionReason はSCIMで無効化したユーザーの状態の理由
+const scimDeprovisionReason = "deprovisioned via SCIM"
+
+var (
+	ErrSCIMInvalidFilter = core.NewAppError("scim_invalid_filter", http.StatusBadRequest, "Invalid filter")
+	ErrSCIMInvalidSyntax = core.NewAppError("scim_invalid_syntax", http.StatusBadRequest, "Invalid request")
+	ErrSCIMInvalidPath   = core.NewAppError("scim_invalid_path", http.StatusBadRequest, "Invalid patch path")
+	ErrSCIMInvalidValue  = core.NewAppError("scim_invalid_value", http.StatusBadRequest, "Invalid attribute value")
+	ErrSCIMUniqueness    = core.NewAppError("scim_uniqueness", http.StatusConflict, "Resource already exists")
+)
+
+// SCIMUsecase は連携元のディレクトリからのSCIMによるユーザー・グループのプロビジョニングを抽象化する
+// ユーザーの無効化・有効化とロールの変更はAdminUsecaseを通して行い、セッションの無効化・ドメインイベント・監査ログを共通にする
+type SCIMUsecase interface {
+	ListUsers(ctx context.Context, input *SCIMListInput) (*SCIMUserPage, error)
+	GetUser(ctx context.Context, id string) (*model.User, error)
+	CreateUser(ctx context.Context, input *SCIMUserInput) (*model.User, error)
+	ReplaceUser(ctx context.Context, input *SCIMUserInput) (*model.User, error)
+	PatchUser(ctx context.Context, input *SCIMPatchInput) (*model.User, error)
+	// DeleteUser はユーザーを無効化してセッションを無効化する（ユーザーは削除しない）
+	DeleteUser(ctx context.Context, input *SCIMDeleteInput) error
+	ListGroups(ctx context.Context, input *SCIMListInput) (*SCIMGroupPage, error)
+	GetGroup(ctx context.Context, id string) (*model.Group, error)
+	CreateGroup(ctx context.Context, input *SCIMGroupInput) (*model.Group, error)
+	ReplaceGroup(ctx context.Context, input *SCIMGroupInput) (*model.Group, error)
+	PatchGroup(ctx context.Context, input *SCIMPatchInput) (*model.Group, error)
+	DeleteGroup(ctx context.Context, input *SCIMDeleteInput) error
+}
+
+type (
+	// SCIMListInput は一覧の入力パラメータを表す
+	SCIMListInput struct {
+		Filter string
+		// StartIndex は1始まりの開始位置
+		StartIndex int
+		// Count は1ページの件数（nilの場合はデフォルト件数）
+		Count *int
+	}
+
+	// SCIMUserPage はユーザーの一覧の1ページを表す
+	SCIMUserPage struct {
+		Users        []*model.User
+		TotalResults int
+		StartIndex   int
+	}
+
+	// SCIMGroupPage はグループの一覧の1ページを表す
+	SCIMGroupPage struct {
+		Groups       []*model.Group
+		TotalResults int
+		StartIndex   int
+	}
+
+	// SCIMUserInput はユーザーの登録・置き換えの入力パラメータを表す
+	SCIMUserInput struct {
+		// ID は置き換えるユーザーのID（登録の場合は空）
+		ID string
+		// UserName はメールアドレスとして扱う
+		UserName    string
+		ExternalID  string
+		DisplayName string
+		// Active がfalseの場合はユーザーを無効化し、trueの場合はSCIMによる無効化を解除する
+		// nil（省略）の場合は状態を変更しない
+		Active    *bool
+		ClientIP  string
+		UserAgent string
+	}
+
+	// SCIMGroupInput はグループの登録・置き換えの入力パラメータを表す
+	SCIMGroupInput struct {
+		// ID は置き換えるグループのID（登録の場合は空）
+		ID          string
+		DisplayName string
+		ExternalID  string
+		MemberIDs   []string
+		ClientIP    string
+		UserAgent   string
+	}
+
+	// SCIMPatchInput はPATCHの入力パラメータを表す
+	SCIMPatchInput struct {
+		ID         string
+		Operations []SCIMPatchOperation
+		ClientIP   string
+		UserAgent  string
+	}
+
+	// SCIMPatchOperation はPATCHの操作（add, replace, remove）を表す
+	SCIMPatchOperation struct {
+		Op    string
+		Path  string
+		Value json.RawMessage
+	}
+
+	// SCIMDeleteInput は削除の入力パラメータを表す
+	SCIMDeleteInput struct {
+		ID        string
+		ClientIP  string
+		UserAgent string
+	}
+
+	// SCIMUsecaseImpl はSCIMUsecaseの実装
+	SCIMUsecaseImpl struct {
+		userRepo  repository.UserRepository
+		groupRepo repository.GroupRepository
+		auditRepo repository.AuditRepository
+		events    *EventOutbox
+		adminUC   AdminUsecase
+		// adminGroup はメンバーに管理者ロールを付与するグループの表示名（空の場合はロールを変更しない）
+		adminGroup string
+	}
+)
+
+// NewSCIMUsecase は新しいSCIMUsecaseを作成する
+func NewSCIMUsecase(
+	userRepo repository.UserRepository,
+	groupRepo repository.GroupRepository,
+	auditRepo repository.AuditRepository,
+	events *EventOutbox,
+	adminUC AdminUsecase,
+	adminGroup string,
+) SCIMUsecase {
+	return &SCIMUsecaseImpl{
+		userRepo:   userRepo,
+		groupRepo:  groupRepo,
+		auditRepo:  auditRepo,
+		events:     events,
+		adminUC:    adminUC,
+		adminGroup: strings.TrimSpace(adminGroup),
+	}
+}
+
+// ListUsers は絞り込み条件に一致するユーザーを作成日時の昇順で1ページ分返す（削除済みのユーザーは含めない）
+func (s *SCIMUsecaseImpl) ListUsers(ctx context.Context, input *SCIMListInput) (*SCIMUserPage, error) {
+	filter, err := model.ParseSCIMFilter(input.Filter)
+	if err != nil {
+		return nil, ErrSCIMInvalidFilter.WithMessage(err.Error())
+	}
+	if filter != nil && !slices.Contains([]string{"id", "username", "externalid", "displayname", "emails.value"}, filter.Attribute) {
+		return nil, ErrSCIMInvalidFilter.WithMessage("Unsupported filter attribute: " + filter.Attribute)
+	}
+
+	users, err := s.userRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	matched := make([]*model.User, 0, len(users))
+	for _, user := range users {
+		if !user.IsDeleted() && matchesSCIMUser(user, filter) {
+			matched = append(matched, user)
+		}
+	}
+
+	start, end := model.SCIMPageBounds(len(matched), input.StartIndex, input.Count)
+	return &SCIMUserPage{Users: matched[start:end], TotalResults: len(matched), StartIndex: start + 1}, nil
+}
+
+// GetUser はIDでユーザーを返す
+func (s *SCIMUsecaseImpl) GetUser(ctx context.Context, id string) (*model.User, error) {
+	return s.findUser(ctx, id)
+}
+
+// CreateUser は連携元のディレクトリのユーザーを登録する
+// 登録したユーザーは本人が認証済みのメールアドレスでログインした時点で有効になる
+func (s *SCIMUsecaseImpl) CreateUser(ctx context.Context, input *SCIMUserInput) (*model.User, error) {
+	if err := s.checkUserName(ctx, input.UserName, ""); err != nil {
+		return nil, err
+	}
+	id, err := generateRandomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	user, err := model.NewProvisionedUser(id, strings.TrimSpace(input.UserName), strings.TrimSpace(input.DisplayName), input.ExternalID)
+	if err != nil {
+		return nil, ErrSCIMInvalidValue.WithMessage(err.Error())
+	}
+
+	registered := model.NewDomainEvent(model.DomainEventUserRegistered, user.ID, model.SCIMActorID,
+		map[string]string{"email": user.Email, "provider": string(user.Provider), "role": string(user.Role)})
+	err = s.events.Write(ctx, func(ctx context.Context) error {
+		return s.userRepo.Save(ctx, user)
+	}, registered)
+	if err != nil {
+		return nil, err
+	}
+	s.recordProvision(ctx, "user", "create", user.ID, input.ClientIP, input.UserAgent)
+
+	return s.applyActive(ctx, user, input.Active, input.ClientIP, input.UserAgent)
+}
+
+// ReplaceUser はユーザーの属性を置き換える
+func (s *SCIMUsecaseImpl) ReplaceUser(ctx context.Context, input *SCIMUserInput) (*model.User, error) {
+	user, err := s.findUser(ctx, input.ID)
+	if err != nil {
+		return nil, err
+	}
+	return s.applyUser(ctx, user, input)
+}
+
+// PatchUser はPATCHの操作を順に適用する
+// 保存しない属性（name.givenNameやemailsなど）の操作は無視する
+func (s *SCIMUsecaseImpl) PatchUser(ctx context.Context, input *SCIMPatchInput) (*model.User, error) {
+	user, err := s.findUser(ctx, input.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	attrs := &SCIMUserInput{
+		ID:          user.ID,
+		UserName:    user.Email,
+		ExternalID:  user.ExternalID,
+		DisplayName: user.Name,
+		ClientIP:    input.ClientIP,
+		UserAgent:   input.UserAgent,
+	}
+	for _, operation := range input.Operations {
+		op, err := scimPatchOp(operation.Op)
+		if err != nil {
+			return nil, err
+		}
+		if operation.Path == "" {
+			values, err := scimPatchValues(operation.Value)
+			if err != nil {
+				return nil, err
+			}
+			for path, value := range values {
+				if err := patchSCIMUserAttribute(attrs, op, path, value); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+		if err := patchSCIMUserAttribute(attrs, op, operation.Path, operation.Value); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.applyUser(ctx, user, attrs)
+}
+
+// DeleteUser はユーザーを無効化してセッションを無効化する
+// 監査ログやドメインイベントの記録のため、ユーザー自体は削除しない（再度有効にする場合はactiveをtrueにする）
+func (s *SCIMUsecaseImpl) DeleteUser(ctx context.Context, input *SCIMDeleteInput) error {
+	user, err := s.findUser(ctx, input.ID)
+	if err != nil {
+		return err
+	}
+	inactive := false
+	if _, err := s.applyActive(ctx, user, &inactive, input.ClientIP, input.UserAgent); err != nil {
+		return err
+	}
+	s.recordProvision(ctx, "user", "delete", user.ID, input.ClientIP, input.UserAgent)
+	return nil
+}
+
+// ListGroups は絞り込み条件に一致するグループを作成日時の昇順で1ページ分返す
+func (s *SCIMUsecaseImpl) ListGroups(ctx context.Context, input *SCIMListInput) (*SCIMGroupPage, error) {
+	filter, err := model.ParseSCIMFilter(input.Filter)
+	if err != nil {
+		return nil, ErrSCIMInvalidFilter.WithMessage(err.Error())
+	}
+	if filter != nil && !slices.Contains([]string{"id", "displayname", "externalid"}, filter.Attribute) {
+		return nil, ErrSCIMInvalidFilter.WithMessage("Unsupported filter attribute: " + filter.Attribute)
+	}
+
+	groups, err := s.groupRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.memberSet(ctx)
+	if err != nil {
+		return nil, err
+	}
+	matched := make([]*model.Group, 0, len(groups))
+	for _, group := range groups {
+		if matchesSCIMGroup(group, filter) {
+			matched = append(matched, withExistingMembers(group, members))
+		}
+	}
+
+	start, end := model.SCIMPageBounds(len(matched), input.StartIndex, input.Count)
+	return &SCIMGroupPage{Groups: matched[start:end], TotalResults: len(matched), StartIndex: start + 1}, nil
+}
+
+// GetGroup はIDでグループを返す（消去されたユーザーはメンバーに含めない）
+func (s *SCIMUsecaseImpl) GetGroup(ctx context.Context, id string) (*model.Group, error) {
+	group, err := s.groupRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.memberSet(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return withExistingMembers(group, members), nil
+}
+
+// CreateGroup はグループを登録し、管理者グループの場合はメンバーに管理者ロールを付与する
+func (s *SCIMUsecaseImpl) CreateGroup(ctx context.Context, input *SCIMGroupInput) (*model.Group, error) {
+	if err := s.checkDisplayName(ctx, input.DisplayName, ""); err != nil {
+		return nil, err
+	}
+	if err := s.checkMembers(ctx, input.MemberIDs); err != nil {
+		return nil, err
+	}
+	id, err := generateRandomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	group, err := model.NewGroup(id, input.DisplayName, input.ExternalID, time.Now())
+	if err != nil {
+		return nil, ErrSCIMInvalidValue.WithMessage(err.Error())
+	}
+	group.AddMembers(input.MemberIDs, group.CreatedAt)
+
+	if err := s.groupRepo.Save(ctx, group); err != nil {
+		return nil, err
+	}
+	s.recordProvision(ctx, "group", "create", group.ID, input.ClientIP, input.UserAgent)
+
+	if err := s.syncAdminRole(ctx, nil, s.adminMembers(group), input.ClientIP, input.UserAgent); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// ReplaceGroup はグループの表示名・外部ID・メンバーを置き換える
+func (s *SCIMUsecaseImpl) ReplaceGroup(ctx context.Context, input *SCIMGroupInput) (*model.Group, error) {
+	group, err := s.groupRepo.FindByID(ctx, input.ID)
+	if err != nil {
+		return nil, err
+	}
+	before := s.adminMembers(group)
+
+	if err := s.checkDisplayName(ctx, input.DisplayName, group.ID); err != nil {
+		return nil, err
+	}
+	if err := s.checkMembers(ctx, input.MemberIDs); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if err := group.Rename(input.DisplayName, now); err != nil {
+		return nil, ErrSCIMInvalidValue.WithMessage(err.Error())
+	}
+	group.ExternalID = input.ExternalID
+	group.ReplaceMembers(input.MemberIDs, now)
+
+	return s.saveGroup(ctx, group, before, input.ClientIP, input.UserAgent)
+}
+
+// PatchGroup はPATCHの操作を順に適用する（メンバーの追加・削除、表示名・外部IDの変更）
+func (s *SCIMUsecaseImpl) PatchGroup(ctx context.Context, input *SCIMPatchInput) (*model.Group, error) {
+	group, err := s.groupRepo.FindByID(ctx, input.ID)
+	if err != nil {
+		return nil, err
+	}
+	before := s.adminMembers(group)
+	displayName := group.DisplayName
+
+	now := time.Now()
+	for _, operation := range input.Operations {
+		op, err := scimPatchOp(operation.Op)
+		if err != nil {
+			return nil, err
+		}
+		if operation.Path == "" {
+			values, err := scimPatchValues(operation.Value)
+			if err != nil {
+				return nil, err
+			}
+			for path, value := range values {
+				if err := patchSCIMGroupAttribute(group, op, path, value, now); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+		if err := patchSCIMGroupAttribute(group, op, operation.Path, operation.Value, now); err != nil {
+			return nil, err
+		}
+	}
+
+	if group.DisplayName != displayName {
+		if err := s.checkDisplayName(ctx, group.DisplayName, group.ID); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.checkMembers(ctx, group.MemberIDs); err != nil {
+		return nil, err
+	}
+	return s.saveGroup(ctx, group, before, input.ClientIP, input.UserAgent)
+}
+
+// DeleteGroup はグループを削除し、管理者グループの場合はメンバーの管理者ロールを外す
+func (s *SCIMUsecaseImpl) DeleteGroup(ctx context.Context, input *SCIMDeleteInput) error {
+	group, err := s.groupRepo.FindByID(ctx, input.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.groupRepo.Delete(ctx, group.ID); err != nil {
+		return err
+	}
+	s.recordProvision(ctx, "group", "delete", group.ID, input.ClientIP, input.UserAgent)
+
+	return s.syncAdminRole(ctx, s.adminMembers(group), nil, input.ClientIP, input.UserAgent)
+}
+
+// findUser はIDでユーザーを返す（削除済みのユーザーは存在しないものとして扱う）
+func (s *SCIMUsecaseImpl) findUser(ctx context.Context, id string) (*model.User, error) {
+	user, err := s.userRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsDeleted() {
+		return nil, repository.ErrUserNotFound
+	}
+	return user, nil
+}
+
+// applyUser はメールアドレス・名前・外部IDを反映してから、activeに応じてユーザーの状態を変更する
+func (s *SCIMUsecaseImpl) applyUser(ctx context.Context, user *model.User, input *SCIMUserInput) (*model.User, error) {
+	email := strings.TrimSpace(input.UserName)
+	name := strings.TrimSpace(input.DisplayName)
+	if err := s.checkUserName(ctx, email, user.ID); err != nil {
+		return nil, err
+	}
+
+	var fields []string
+	if email != user.Email {
+		fields = append(fields, "email")
+	}
+	if name != user.Name {
+		fields = append(fields, "name")
+	}
+	if len(fields) > 0 || input.ExternalID != user.ExternalID {
+		if err := user.Provision(email, name, input.ExternalID); err != nil {
+			return nil, ErrSCIMInvalidValue.WithMessage(err.Error())
+		}
+		var events []*model.DomainEvent
+		if len(fields) > 0 {
+			events = append(events, model.NewDomainEvent(model.DomainEventProfileUpdated, user.ID, model.SCIMActorID,
+				map[string]string{"fields": strings.Join(fields, ",")}))
+		}
+		err := s.events.Write(ctx, func(ctx context.Context) error {
+			return s.userRepo.Update(ctx, user)
+		}, events...)
+		if err != nil {
+			return nil, err
+		}
+		s.recordProvision(ctx, "user", "update", user.ID, input.ClientIP, input.UserAgent)
+	}
+
+	return s.applyActive(ctx, user, input.Active, input.ClientIP, input.UserAgent)
+}
+
+// applyActive はactiveがfalseの場合はユーザーを無効化し、trueの場合はSCIMによる無効化を解除する
+// 管理者による停止・無効化は解除しない（連携元のディレクトリは停止を知らずにactive=trueを送り続けるため）
+// 状態の変更はAdminUsecaseで行うため、変更した場合はセッションも無効化される
+// 本人のログインを待っているユーザー（pending_verification）はactiveがtrueでも状態を変更しない
+func (s *SCIMUsecaseImpl) applyActive(ctx context.Context, user *model.User, active *bool, clientIP, userAgent string) (*model.User, error) {
+	if active == nil {
+		return user, nil
+	}
+	now := time.Now()
+	var to model.UserStatus
+	var reason string
+	switch {
+	case !*active && user.EffectiveStatus(now) != model.UserStatusDisabled:
+		to, reason = model.UserStatusDisabled, scimDeprovisionReason
+	case *active && user.Status == model.UserStatusDisabled && user.StatusReason == scimDeprovisionReason:
+		to = model.UserStatusActive
+	default:
+		return user, nil
+	}
+
+	return s.adminUC.ChangeStatus(ctx, &ChangeStatusInput{
+		ActorID:   model.SCIMActorID,
+		UserID:    user.ID,
+		Status:    to,
+		Reason:    reason,
+		ClientIP:  clientIP,
+		UserAgent: userAgent,
+	})
+}
+
+// checkUserName は他のユーザー（削除済みを含む）が同じメールアドレスを使っていないことを確認する
+func (s *SCIMUsecaseImpl) checkUserName(ctx context.Context, userName, userID string) error {
+	userName = strings.TrimSpace(userName)
+	if userName == "" {
+		return ErrSCIMInvalidValue.WithMessage("userName is required")
+	}
+	users, err := s.userRepo.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+	for _, user := range users {
+		if user.ID != userID && strings.EqualFold(user.Email, userName) {
+			return ErrSCIMUniqueness.WithMessage("userName is already in use")
+		}
+	}
+	return nil
+}
+
+// checkDisplayName は他のグループが同じ表示名を使っていないことを確認する
+func (s *SCIMUsecaseImpl) checkDisplayName(ctx context.Context, displayName, groupID string) error {
+	groups, err := s.groupRepo.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+	for _, group := range groups {
+		if group.ID != groupID && strings.EqualFold(group.DisplayName, strings.TrimSpace(displayName)) {
+			return ErrSCIMUniqueness.WithMessage("displayName is already in use")
+		}
+	}
+	return nil
+}
+
+// checkMembers はメンバーがすべて存在する（削除済みでない）ユーザーであることを確認する
+func (s *SCIMUsecaseImpl) checkMembers(ctx context.Context, memberIDs []string) error {
+	if len(memberIDs) == 0 {
+		return nil
+	}
+	members, err := s.memberSet(ctx)
+	if err != nil {
+		return err
+	}
+	for _, memberID := range memberIDs {
+		if !members[memberID] {
+			return ErrSCIMInvalidValue.WithMessage("Unknown member: " + memberID)
+		}
+	}
+	return nil
+}
+
+// memberSet はグループのメンバーにできる（削除済みでない）ユーザーのIDの集合を返す
+func (s *SCIMUsecaseImpl) memberSet(ctx context.Context) (map[string]bool, error) {
+	users, err := s.userRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	members := make(map[string]bool, len(users))
+	for _, user := range users {
+		if !user.IsDeleted() {
+			members[user.ID] = true
+		}
+	}
+	return members, nil
+}
+
+// saveGroup はグループを保存し、管理者グループのメンバーの増減に応じてロールを変更する
+func (s *SCIMUsecaseImpl) saveGroup(ctx context.Context, group *model.Group, before []string, clientIP, userAgent string) (*model.Group, error) {
+	if err := s.groupRepo.Update(ctx, group); err != nil {
+		return nil, err
+	}
+	s.recordProvision(ctx, "group", "update", group.ID, clientIP, userAgent)
+
+	if err := s.syncAdminRole(ctx, before, s.adminMembers(group), clientIP, userAgent); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// adminMembers はグループが管理者グループの場合にメンバーを返す（表示名の変更で管理者グループになる・外れる場合を含む）
+func (s *SCIMUsecaseImpl) adminMembers(group *model.Group) []string {
+	if s.adminGroup == "" || !strings.EqualFold(group.DisplayName, s.adminGroup) {
+		return nil
+	}
+	return slices.Clone(group.MemberIDs)
+}
+
+// syncAdminRole は管理者グループに加わったユーザーに管理者ロールを付与し、外れたユーザーのロールをuserに戻す
+// 戻すのはSCIMで付与した管理者ロールのみで、管理者が付与したロールは変更しない
+func (s *SCIMUsecaseImpl) syncAdminRole(ctx context.Context, before, after []string, clientIP, userAgent string) error {
+	var errs []error
+	changes := make(map[string]model.Role)
+	for _, userID := range after {
+		if !slices.Contains(before, userID) {
+			changes[userID] = model.RoleAdmin
+		}
+	}
+	for _, userID := range before {
+		if slices.Contains(after, userID) {
+			continue
+		}
+		user, err := s.userRepo.FindByID(ctx, userID)
+		if err != nil {
+			// 消去されたユーザーはロールを変更する必要がない
+			if !errors.Is(err, repository.ErrUserNotFound) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		if user.RoleProvisioned {
+			changes[userID] = model.RoleUser
+		}
+	}
+
+	for userID, role := range changes {
+		_, err := s.adminUC.ChangeRole(ctx, &ChangeRoleInput{
+			ActorID:   model.SCIMActorID,
+			UserID:    userID,
+			Role:      role,
+			ClientIP:  clientIP,
+			UserAgent: userAgent,
+		})
+		if err != nil && !errors.Is(err, repository.ErrUserNotFound) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// recordProvision はSCIMによるユーザー・グループの変更を監査ログに記録する
+func (s *SCIMUsecaseImpl) recordProvision(ctx context.Context, resource, operation, subjectID, clientIP, userAgent string) {
+	event := newAuditEvent(model.AuditEventSCIMProvision, model.AuditOutcomeSuccess, model.SCIMActorID, subjectID, clientIP, userAgent)
+	event.Metadata = map[string]string{"resource": resource, "operation": operation}
+	recordAuditEvent(ctx, s.auditRepo, event)
+}
+
+// matchesSCIMUser はユーザーが絞り込み条件に一致するかどうかを確認する（userNameなどは大文字・小文字を区別しない）
+func matchesSCIMUser(user *model.User, filter *model.SCIMFilter) bool {
+	if filter == nil {
+		return true
+	}
+	switch filter.Attribute {
+	case "id":
+		return user.ID == filter.Value
+	case "username", "emails.value":
+		return strings.EqualFold(user.Email, filter.Value)
+	case "externalid":
+		return user.ExternalID == filter.Value
+	case "displayname":
+		return strings.EqualFold(user.Name, filter.Value)
+	default:
+		return false
+	}
+}
+
+// matchesSCIMGroup はグループが絞り込み条件に一致するかどうかを確認する
+func matchesSCIMGroup(group *model.Group, filter *model.SCIMFilter) bool {
+	if filter == nil {
+		return true
+	}
+	switch filter.Attribute {
+	case "id":
+		return group.ID == filter.Value
+	case "displayname":
+		return strings.EqualFold(group.DisplayName, filter.Value)
+	case "externalid":
+		return group.ExternalID == filter.Value
+	default:
+		return false
+	}
+}
+
+// withExistingMembers は消去されたユーザーをメンバーから除いたグループのコピーを返す
+func withExistingMembers(group *model.Group, members map[string]bool) *model.Group {
+	copied := *group
+	copied.MemberIDs = slices.DeleteFunc(slices.Clone(group.MemberIDs), func(memberID string) bool {
+		return !members[memberID]
+	})
+	return &copied
+}
+
+// scimPatchOp はPATCHの操作名を小文字にして検証する（Azure ADなどは先頭を大文字で送る）
+func scimPatchOp(op string) (string, error) {
+	op = strings.ToLower(op)
+	switch op {
+	case "add", "replace", "remove":
+		return op, nil
+	default:
+		return "", ErrSCIMInvalidSyntax.WithMessage("Unsupported patch operation: " + op)
+	}
+}
+
+// scimPatchValues はパスを省略した操作の値（属性名をキーとするオブジェクト）を解析する
+// name.formattedのように入れ子の属性は、パスと同じ「.」区切りのキーに展開する
+func scimPatchValues(value json.RawMessage) (map[string]json.RawMessage, error) {
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(value, &values); err != nil {
+		return nil, ErrSCIMInvalidSyntax.WithMessage("Patch value without path must be an object")
+	}
+	for key, nested := range values {
+		var object map[string]json.RawMessage
+		if !strings.EqualFold(key, "name") || json.Unmarshal(nested, &object) != nil {
+			continue
+		}
+		delete(values, key)
+		for nestedKey, nestedValue := range object {
+			values[key+"."+nestedKey] = nestedValue
+		}
+	}
+	return values, nil
+}
+
+// patchSCIMUserAttribute はユーザーの属性に操作を適用する（保存しない属性は無視する）
+func patchSCIMUserAttribute(attrs *SCIMUserInput, op, path string, value json.RawMessage) error {
+	var target *string
+	switch strings.ToLower(path) {
+	case "active":
+		if op == "remove" {
+			return ErrSCIMInvalidValue.WithMessage("active cannot be removed")
+		}
+		active, err := scimBool(value)
+		if err != nil {
+			return err
+		}
+		attrs.Active = &active
+		return nil
+	case "externalid":
+		if op == "remove" {
+			attrs.ExternalID = ""
+			return nil
+		}
+		target = &attrs.ExternalID
+	case "username":
+		target = &attrs.UserName
+	case "displayname", "name.formatted":
+		target = &attrs.DisplayName
+	default:
+		return nil
+	}
+
+	if op == "remove" {
+		return ErrSCIMInvalidValue.WithMessage(path + " cannot be removed")
+	}
+	var s string
+	if err := json.Unmarshal(value, &s); err != nil {
+		return ErrSCIMInvalidValue.WithMessage(path + " must be a string")
+	}
+	*target = s
+	return nil
+}
+
+// patchSCIMGroupAttribute はグループの属性に操作を適用する（保存しない属性は無視する）
+func patchSCIMGroupAttribute(group *model.Group, op, path string, value json.RawMessage, now time.Time) error {
+	attribute, memberFilter, hasFilter := strings.Cut(path, "[")
+	switch strings.ToLower(attribute) {
+	case "members":
+	case "displayname":
+		if op == "remove" {
+			return ErrSCIMInvalidValue.WithMessage("displayName cannot be removed")
+		}
+		var displayName string
+		if err := json.Unmarshal(value, &displayName); err != nil {
+			return ErrSCIMInvalidValue.WithMessage("displayName must be a string")
+		}
+		if err := group.Rename(displayName, now); err != nil {
+			return ErrSCIMInvalidValue.WithMessage(err.Error())
+		}
+		return nil
+	case "externalid":
+		if op == "remove" {
+			group.ExternalID = ""
+			return nil
+		}
+		if err := json.Unmarshal(value, &group.ExternalID); err != nil {
+			return ErrSCIMInvalidValue.WithMessage("externalId must be a string")
+		}
+		return nil
+	default:
+		return nil
+	}
+
+	// members[value eq "id"]の形式は、フィルターに一致するメンバーを対象にする
+	if hasFilter {
+		filter, err := model.ParseSCIMFilter(strings.TrimSuffix(memberFilter, "]"))
+		if err != nil || filter == nil || filter.Attribute != "value" || op != "remove" {
+			return ErrSCIMInvalidPath.WithMessage("Unsupported members path: " + path)
+		}
+		group.RemoveMembers([]string{filter.Value}, now)
+		return nil
+	}
+
+	var memberIDs []string
+	if op != "remove" || len(value) > 0 {
+		ids, err := scimMemberIDs(value)
+		if err != nil {
+			return err
+		}
+		memberIDs = ids
+	}
+	switch {
+	case op == "add":
+		group.AddMembers(memberIDs, now)
+	case op == "replace":
+		group.ReplaceMembers(memberIDs, now)
+	case len(value) == 0:
+		// 値のないremoveはすべてのメンバーを外す
+		group.ReplaceMembers(nil, now)
+	default:
+		group.RemoveMembers(memberIDs, now)
+	}
+	return nil
+}
+
+// scimMemberIDs はメンバーの配列（[{"value": "ユーザーID"}]）からユーザーIDを取り出す
+func scimMemberIDs(value json.RawMessage) ([]string, error) {
+	var members []struct {
+		Value string `json:"value"`
+	}
+	if err := json.Unmarshal(value, &members); err != nil {
+		return nil, ErrSCIMInvalidValue.WithMessage("members must be an array of objects with a value")
+	}
+	ids := make([]string, 0, len(members))
+	for _, member := range members {
+		if member.Value == "" {
+			return nil, ErrSCIMInvalidValue.WithMessage("member value is required")
+		}
+		ids = append(ids, member.Value)
+	}
+	return ids, nil
+}
+
+// scimBool は真偽値を解析する（Azure ADなどは"False"のような文字列で送る）
+func scimBool(value json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(value, &b); err == nil {
+		return b, nil
+	}
+	var s string
+	if err := json.Unmarshal(value, &s); err == nil {
+		if parsed, err := strconv.ParseBool(s); err == nil {
+			return parsed, nil
+		}
+	}
+	return false, ErrSCIMInvalidValue.WithMessage("active must be a boolean")
+}
@@ -0,0 +1,633 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"stackies-backend/core"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockGroupRepository はGroupRepositoryのモック
+type MockGroupRepository struct {
+	mock.Mock
+}
+
+var _ repository.GroupRepository = (*MockGroupRepository)(nil)
+
+func (m *MockGroupRepository) Save(ctx context.Context, group *model.Group) error {
+	args := m.Called(ctx, group)
+	return args.Error(0)
+}
+
+func (m *MockGroupRepository) FindByID(ctx context.Context, id string) (*model.Group, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Group), args.Error(1)
+}
+
+func (m *MockGroupRepository) FindAll(ctx context.Context) ([]*model.Group, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Group), args.Error(1)
+}
+
+func (m *MockGroupRepository) Update(ctx context.Context, group *model.Group) error {
+	args := m.Called(ctx, group)
+	return args.Error(0)
+}
+
+func (m *MockGroupRepository) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+// MockAdminUsecase はAdminUsecaseのモック
+type MockAdminUsecase struct {
+	mock.Mock
+}
+
+var _ AdminUsecase = (*MockAdminUsecase)(nil)
+
+func (m *MockAdminUsecase) ChangeRole(ctx context.Context, input *ChangeRoleInput) (*model.User, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.User), args.Error(1)
+}
+
+func (m *MockAdminUsecase) ListUsers(ctx context.Context) ([]*model.User, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.User), args.Error(1)
+}
+
+func (m *MockAdminUsecase) SearchUsers(ctx context.Context, input *SearchUsersInput) (*model.UserPage, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.UserPage), args.Error(1)
+}
+
+func (m *MockAdminUsecase) GetUser(ctx context.Context, userID string) (*model.User, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.User), args.Error(1)
+}
+
+func (m *MockAdminUsecase) ChangeStatus(ctx context.Context, input *ChangeStatusInput) (*model.User, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.User), args.Error(1)
+}
+
+func (m *MockAdminUsecase) RevokeSessions(ctx context.Context, input *RevokeSessionsInput) error {
+	args := m.Called(ctx, input)
+	return args.Error(0)
+}
+
+func (m *MockAdminUsecase) Impersonate(ctx context.Context, input *ImpersonateInput) (*ImpersonateOutput, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*ImpersonateOutput), args.Error(1)
+}
+
+// scimProvisionOf は指定したリソース・操作のSCIMの監査イベントに一致するmatcherを返す
+func scimProvisionOf(resource, operation, subjectID string) interface{} {
+	return mock.MatchedBy(func(event *model.AuditEvent) bool {
+		return event.Type == model.AuditEventSCIMProvision &&
+			event.ActorID == model.SCIMActorID &&
+			event.SubjectID == subjectID &&
+			event.Metadata["resource"] == resource &&
+			event.Metadata["operation"] == operation
+	})
+}
+
+// statusChangeTo はSCIMによる指定した状態への変更に一致するmatcherを返す
+func statusChangeTo(userID string, status model.UserStatus) interface{} {
+	return mock.MatchedBy(func(input *ChangeStatusInput) bool {
+		return input.ActorID == model.SCIMActorID && input.UserID == userID && input.Status == status
+	})
+}
+
+func TestSCIMUsecaseImpl_ListUsers(t *testing.T) {
+	count := func(n int) *int { return &n }
+	deletedAt := time.Now()
+	users := []*model.User{
+		{ID: "user_1", Email: "alice@example.com", Name: "Alice", ExternalID: "emp-1"},
+		{ID: "user_2", Email: "bob@example.com", Name: "Bob", ExternalID: "emp-2"},
+		{ID: "user_3", Email: "carol@example.com", Name: "Carol", DeletedAt: &deletedAt},
+	}
+
+	tests := []struct {
+		testName      string
+		input         *SCIMListInput
+		expectedIDs   []string
+		expectedTotal int
+		expectedError error
+	}{
+		{
+			testName:      "削除済みのユーザーは含めない",
+			input:         &SCIMListInput{},
+			expectedIDs:   []string{"user_1", "user_2"},
+			expectedTotal: 2,
+		},
+		{
+			testName:      "userNameは大文字・小文字を区別しない",
+			input:         &SCIMListInput{Filter: `userName eq "BOB@example.com"`},
+			expectedIDs:   []string{"user_2"},
+			expectedTotal: 1,
+		},
+		{
+			testName:      "externalIdで絞り込む",
+			input:         &SCIMListInput{Filter: `externalId eq "emp-1"`},
+			expectedIDs:   []string{"user_1"},
+			expectedTotal: 1,
+		},
+		{
+			testName:      "startIndexとcountで1ページ分返す",
+			input:         &SCIMListInput{StartIndex: 2, Count: count(1)},
+			expectedIDs:   []string{"user_2"},
+			expectedTotal: 2,
+		},
+		{
+			testName:      "未対応の属性",
+			input:         &SCIMListInput{Filter: `title eq "Engineer"`},
+			expectedError: ErrSCIMInvalidFilter,
+		},
+		{
+			testName:      "未対応の演算子",
+			input:         &SCIMListInput{Filter: `userName sw "alice"`},
+			expectedError: ErrSCIMInvalidFilter,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			userRepo := new(MockUserRepository)
+			userRepo.On("FindAll", mock.Anything).Return(users, nil).Maybe()
+
+			usecase := NewSCIMUsecase(userRepo, new(MockGroupRepository), nil, nil, new(MockAdminUsecase), "")
+			page, err := usecase.ListUsers(context.Background(), tt.input)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			ids := make([]string, 0, len(page.Users))
+			for _, user := range page.Users {
+				ids = append(ids, user.ID)
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+			assert.Equal(t, tt.expectedTotal, page.TotalResults)
+		})
+	}
+}
+
+func TestSCIMUsecaseImpl_CreateUser(t *testing.T) {
+	tests := []struct {
+		testName      string
+		input         *SCIMUserInput
+		setupMocks    func(*MockUserRepository, *MockAuditRepository, *MockAdminUsecase)
+		expectedError error
+	}{
+		{
+			testName: "本人のログインを待つユーザーとして登録",
+			input:    &SCIMUserInput{UserName: "alice@example.com", DisplayName: "Alice", ExternalID: "emp-1", Active: scimActive(true)},
+			setupMocks: func(userRepo *MockUserRepository, auditRepo *MockAuditRepository, adminUC *MockAdminUsecase) {
+				userRepo.On("FindAll", mock.Anything).Return([]*model.User{}, nil)
+				userRepo.On("Save", mock.Anything, mock.MatchedBy(func(user *model.User) bool {
+					return user.Email == "alice@example.com" &&
+						user.Name == "Alice" &&
+						user.ExternalID == "emp-1" &&
+						user.Status == model.UserStatusPendingVerification
+				})).Return(nil)
+				auditRepo.On("Append", mock.Anything, mock.MatchedBy(func(event *model.AuditEvent) bool {
+					return event.Type == model.AuditEventSCIMProvision && event.Metadata["operation"] == "create"
+				})).Return(nil)
+			},
+		},
+		{
+			testName: "activeがfalseの場合は登録後に無効化する",
+			input:    &SCIMUserInput{UserName: "alice@example.com", DisplayName: "Alice", Active: scimActive(false)},
+			setupMocks: func(userRepo *MockUserRepository, auditRepo *MockAuditRepository, adminUC *MockAdminUsecase) {
+				userRepo.On("FindAll", mock.Anything).Return([]*model.User{}, nil)
+				userRepo.On("Save", mock.Anything, mock.Anything).Return(nil)
+				auditRepo.On("Append", mock.Anything, mock.Anything).Return(nil)
+				adminUC.On("ChangeStatus", mock.Anything, mock.MatchedBy(func(input *ChangeStatusInput) bool {
+					return input.ActorID == model.SCIMActorID &&
+						input.Status == model.UserStatusDisabled &&
+						input.Reason == scimDeprovisionReason
+				})).Return(&model.User{ID: "user_1", Status: model.UserStatusDisabled}, nil)
+			},
+		},
+		{
+			testName: "同じメールアドレスのユーザーが存在する",
+			input:    &SCIMUserInput{UserName: "Alice@Example.com", DisplayName: "Alice", Active: scimActive(true)},
+			setupMocks: func(userRepo *MockUserRepository, auditRepo *MockAuditRepository, adminUC *MockAdminUsecase) {
+				userRepo.On("FindAll", mock.Anything).Return([]*model.User{{ID: "user_1", Email: "alice@example.com"}}, nil)
+			},
+			expectedError: ErrSCIMUniqueness,
+		},
+		{
+			testName: "不正なメールアドレス",
+			input:    &SCIMUserInput{UserName: "alice", DisplayName: "Alice", Active: scimActive(true)},
+			setupMocks: func(userRepo *MockUserRepository, auditRepo *MockAuditRepository, adminUC *MockAdminUsecase) {
+				userRepo.On("FindAll", mock.Anything).Return([]*model.User{}, nil)
+			},
+			expectedError: ErrSCIMInvalidValue,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			userRepo := new(MockUserRepository)
+			auditRepo := new(MockAuditRepository)
+			adminUC := new(MockAdminUsecase)
+			tt.setupMocks(userRepo, auditRepo, adminUC)
+
+			usecase := NewSCIMUsecase(userRepo, new(MockGroupRepository), auditRepo, nil, adminUC, "")
+			user, err := usecase.CreateUser(context.Background(), tt.input)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, user)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, user)
+			}
+			userRepo.AssertExpectations(t)
+			auditRepo.AssertExpectations(t)
+			adminUC.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSCIMUsecaseImpl_PatchUser(t *testing.T) {
+	op := func(op, path, value string) SCIMPatchOperation {
+		return SCIMPatchOperation{Op: op, Path: path, Value: json.RawMessage(value)}
+	}
+
+	tests := []struct {
+		testName      string
+		user          *model.User
+		operations    []SCIMPatchOperation
+		setupMocks    func(*MockUserRepository, *MockAuditRepository, *MockAdminUsecase)
+		expectedError error
+	}{
+		{
+			testName:   "文字列のFalseでactiveを変更すると無効化する",
+			user:       &model.User{ID: "user_1", Email: "alice@example.com", Name: "Alice", Status: model.UserStatusActive},
+			operations: []SCIMPatchOperation{op("Replace", "active", `"False"`)},
+			setupMocks: func(userRepo *MockUserRepository, auditRepo *MockAuditRepository, adminUC *MockAdminUsecase) {
+				adminUC.On("ChangeStatus", mock.Anything, statusChangeTo("user_1", model.UserStatusDisabled)).
+					Return(&model.User{ID: "user_1", Status: model.UserStatusDisabled}, nil)
+			},
+		},
+		{
+			testName:   "パスを省略したactiveの変更でSCIMによる無効化を解除する",
+			user:       &model.User{ID: "user_1", Email: "alice@example.com", Name: "Alice", Status: model.UserStatusDisabled, StatusReason: scimDeprovisionReason},
+			operations: []SCIMPatchOperation{op("replace", "", `{"active":true}`)},
+			setupMocks: func(userRepo *MockUserRepository, auditRepo *MockAuditRepository, adminUC *MockAdminUsecase) {
+				adminUC.On("ChangeStatus", mock.Anything, statusChangeTo("user_1", model.UserStatusActive)).
+					Return(&model.User{ID: "user_1", Status: model.UserStatusActive}, nil)
+			},
+		},
+		{
+			testName:   "管理者による無効化はactiveをtrueにしても解除しない",
+			user:       &model.User{ID: "user_1", Email: "alice@example.com", Name: "Alice", Status: model.UserStatusDisabled, StatusReason: "spam"},
+			operations: []SCIMPatchOperation{op("replace", "active", `true`)},
+			setupMocks: func(userRepo *MockUserRepository, auditRepo *MockAuditRepository, adminUC *MockAdminUsecase) {},
+		},
+		{
+			testName:   "userNameと名前を変更する",
+			user:       &model.User{ID: "user_1", Email: "alice@example.com", Name: "Alice", Status: model.UserStatusActive},
+			operations: []SCIMPatchOperation{op("replace", "userName", `"alice.smith@example.com"`), op("replace", "", `{"name":{"formatted":"Alice Smith"}}`)},
+			setupMocks: func(userRepo *MockUserRepository, auditRepo *MockAuditRepository, adminUC *MockAdminUsecase) {
+				userRepo.On("Update", mock.Anything, mock.MatchedBy(func(user *model.User) bool {
+					return user.Email == "alice.smith@example.com" && user.Name == "Alice Smith"
+				})).Return(nil)
+				auditRepo.On("Append", mock.Anything, scimProvisionOf("user", "update", "user_1")).Return(nil)
+			},
+		},
+		{
+			testName:   "保存しない属性の操作は無視する",
+			user:       &model.User{ID: "user_1", Email: "alice@example.com", Name: "Alice", Status: model.UserStatusActive},
+			operations: []SCIMPatchOperation{op("replace", "name.givenName", `"Alicia"`), op("add", "title", `"Engineer"`)},
+			setupMocks: func(userRepo *MockUserRepository, auditRepo *MockAuditRepository, adminUC *MockAdminUsecase) {},
+		},
+		{
+			testName:      "未対応の操作",
+			user:          &model.User{ID: "user_1", Email: "alice@example.com", Name: "Alice"},
+			operations:    []SCIMPatchOperation{op("move", "active", `false`)},
+			setupMocks:    func(userRepo *MockUserRepository, auditRepo *MockAuditRepository, adminUC *MockAdminUsecase) {},
+			expectedError: ErrSCIMInvalidSyntax,
+		},
+		{
+			testName:      "真偽値でないactive",
+			user:          &model.User{ID: "user_1", Email: "alice@example.com", Name: "Alice"},
+			operations:    []SCIMPatchOperation{op("replace", "active", `"maybe"`)},
+			setupMocks:    func(userRepo *MockUserRepository, auditRepo *MockAuditRepository, adminUC *MockAdminUsecase) {},
+			expectedError: ErrSCIMInvalidValue,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			userRepo := new(MockUserRepository)
+			auditRepo := new(MockAuditRepository)
+			adminUC := new(MockAdminUsecase)
+			userRepo.On("FindByID", mock.Anything, tt.user.ID).Return(tt.user, nil)
+			userRepo.On("FindAll", mock.Anything).Return([]*model.User{tt.user}, nil).Maybe()
+			tt.setupMocks(userRepo, auditRepo, adminUC)
+
+			usecase := NewSCIMUsecase(userRepo, new(MockGroupRepository), auditRepo, nil, adminUC, "")
+			_, err := usecase.PatchUser(context.Background(), &SCIMPatchInput{ID: tt.user.ID, Operations: tt.operations})
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			userRepo.AssertExpectations(t)
+			auditRepo.AssertExpectations(t)
+			adminUC.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSCIMUsecaseImpl_ReplaceUser(t *testing.T) {
+	until := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		testName   string
+		user       *model.User
+		input      *SCIMUserInput
+		setupMocks func(*MockAdminUsecase)
+	}{
+		{
+			testName:   "activeを省略した置き換えでは管理者による停止を解除しない",
+			user:       &model.User{ID: "user_1", Email: "alice@example.com", Name: "Alice", Status: model.UserStatusSuspended, StatusReason: "spam", SuspendedUntil: &until},
+			input:      &SCIMUserInput{ID: "user_1", UserName: "alice@example.com", DisplayName: "Alice"},
+			setupMocks: func(adminUC *MockAdminUsecase) {},
+		},
+		{
+			testName:   "activeがtrueでも管理者による停止は解除しない",
+			user:       &model.User{ID: "user_1", Email: "alice@example.com", Name: "Alice", Status: model.UserStatusSuspended, StatusReason: "spam", SuspendedUntil: &until},
+			input:      &SCIMUserInput{ID: "user_1", UserName: "alice@example.com", DisplayName: "Alice", Active: scimActive(true)},
+			setupMocks: func(adminUC *MockAdminUsecase) {},
+		},
+		{
+			testName: "activeをfalseにすると管理者が停止したユーザーも無効化する",
+			user:     &model.User{ID: "user_1", Email: "alice@example.com", Name: "Alice", Status: model.UserStatusSuspended, StatusReason: "spam", SuspendedUntil: &until},
+			input:    &SCIMUserInput{ID: "user_1", UserName: "alice@example.com", DisplayName: "Alice", Active: scimActive(false)},
+			setupMocks: func(adminUC *MockAdminUsecase) {
+				adminUC.On("ChangeStatus", mock.Anything, statusChangeTo("user_1", model.UserStatusDisabled)).
+					Return(&model.User{ID: "user_1", Status: model.UserStatusDisabled}, nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			userRepo := new(MockUserRepository)
+			adminUC := new(MockAdminUsecase)
+			userRepo.On("FindByID", mock.Anything, tt.user.ID).Return(tt.user, nil)
+			userRepo.On("FindAll", mock.Anything).Return([]*model.User{tt.user}, nil)
+			tt.setupMocks(adminUC)
+
+			usecase := NewSCIMUsecase(userRepo, new(MockGroupRepository), new(MockAuditRepository), nil, adminUC, "")
+			_, err := usecase.ReplaceUser(context.Background(), tt.input)
+
+			assert.NoError(t, err)
+			adminUC.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSCIMUsecaseImpl_DeleteUser(t *testing.T) {
+	deletedAt := time.Now()
+
+	tests := []struct {
+		testName      string
+		user          *model.User
+		setupMocks    func(*MockAuditRepository, *MockAdminUsecase)
+		expectedError error
+	}{
+		{
+			testName: "ユーザーを無効化する",
+			user:     &model.User{ID: "user_1", Status: model.UserStatusActive},
+			setupMocks: func(auditRepo *MockAuditRepository, adminUC *MockAdminUsecase) {
+				adminUC.On("ChangeStatus", mock.Anything, statusChangeTo("user_1", model.UserStatusDisabled)).
+					Return(&model.User{ID: "user_1", Status: model.UserStatusDisabled}, nil)
+				auditRepo.On("Append", mock.Anything, scimProvisionOf("user", "delete", "user_1")).Return(nil)
+			},
+		},
+		{
+			testName: "無効化済みのユーザーは状態を変更しない",
+			user:     &model.User{ID: "user_1", Status: model.UserStatusDisabled},
+			setupMocks: func(auditRepo *MockAuditRepository, adminUC *MockAdminUsecase) {
+				auditRepo.On("Append", mock.Anything, scimProvisionOf("user", "delete", "user_1")).Return(nil)
+			},
+		},
+		{
+			testName:      "削除済みのユーザーは存在しないものとして扱う",
+			user:          &model.User{ID: "user_1", Status: model.UserStatusActive, DeletedAt: &deletedAt},
+			setupMocks:    func(auditRepo *MockAuditRepository, adminUC *MockAdminUsecase) {},
+			expectedError: repository.ErrUserNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			userRepo := new(MockUserRepository)
+			auditRepo := new(MockAuditRepository)
+			adminUC := new(MockAdminUsecase)
+			userRepo.On("FindByID", mock.Anything, tt.user.ID).Return(tt.user, nil)
+			tt.setupMocks(auditRepo, adminUC)
+
+			usecase := NewSCIMUsecase(userRepo, new(MockGroupRepository), auditRepo, nil, adminUC, "")
+			err := usecase.DeleteUser(context.Background(), &SCIMDeleteInput{ID: tt.user.ID})
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			auditRepo.AssertExpectations(t)
+			adminUC.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSCIMUsecaseImpl_PatchGroup(t *testing.T) {
+	// user_1はSCIMで、user_2は管理者が管理者ロールを付与した
+	users := []*model.User{
+		{ID: "user_1", Role: model.RoleAdmin, RoleProvisioned: true},
+		{ID: "user_2", Role: model.RoleAdmin},
+	}
+	op := func(op, path, value string) SCIMPatchOperation {
+		return SCIMPatchOperation{Op: op, Path: path, Value: json.RawMessage(value)}
+	}
+	roleChangeTo := func(userID string, role model.Role) interface{} {
+		return mock.MatchedBy(func(input *ChangeRoleInput) bool {
+			return input.ActorID == model.SCIMActorID && input.UserID == userID && input.Role == role
+		})
+	}
+
+	tests := []struct {
+		testName        string
+		group           *model.Group
+		operations      []SCIMPatchOperation
+		setupMocks      func(*MockAdminUsecase)
+		expectedMembers []string
+		expectedName    string
+		expectedError   error
+	}{
+		{
+			testName:   "管理者グループに追加したメンバーを管理者にする",
+			group:      &model.Group{ID: "group_1", DisplayName: "Admins", MemberIDs: []string{"user_1"}},
+			operations: []SCIMPatchOperation{op("add", "members", `[{"value":"user_2"}]`)},
+			setupMocks: func(adminUC *MockAdminUsecase) {
+				adminUC.On("ChangeRole", mock.Anything, roleChangeTo("user_2", model.RoleAdmin)).Return(&model.User{ID: "user_2"}, nil)
+			},
+			expectedMembers: []string{"user_1", "user_2"},
+			expectedName:    "Admins",
+		},
+		{
+			testName:   "管理者グループから外したメンバーのロールを戻す",
+			group:      &model.Group{ID: "group_1", DisplayName: "admins", MemberIDs: []string{"user_1", "user_2"}},
+			operations: []SCIMPatchOperation{op("remove", `members[value eq "user_1"]`, ``)},
+			setupMocks: func(adminUC *MockAdminUsecase) {
+				adminUC.On("ChangeRole", mock.Anything, roleChangeTo("user_1", model.RoleUser)).Return(&model.User{ID: "user_1"}, nil)
+			},
+			expectedMembers: []string{"user_2"},
+			expectedName:    "admins",
+		},
+		{
+			testName:        "管理者が付与したロールは管理者グループから外しても戻さない",
+			group:           &model.Group{ID: "group_1", DisplayName: "Admins", MemberIDs: []string{"user_1", "user_2"}},
+			operations:      []SCIMPatchOperation{op("remove", `members[value eq "user_2"]`, ``)},
+			setupMocks:      func(adminUC *MockAdminUsecase) {},
+			expectedMembers: []string{"user_1"},
+			expectedName:    "Admins",
+		},
+		{
+			testName:   "表示名の変更で管理者グループでなくなる",
+			group:      &model.Group{ID: "group_1", DisplayName: "Admins", MemberIDs: []string{"user_1"}},
+			operations: []SCIMPatchOperation{op("replace", "", `{"displayName":"Former Admins"}`)},
+			setupMocks: func(adminUC *MockAdminUsecase) {
+				adminUC.On("ChangeRole", mock.Anything, roleChangeTo("user_1", model.RoleUser)).Return(&model.User{ID: "user_1"}, nil)
+			},
+			expectedMembers: []string{"user_1"},
+			expectedName:    "Former Admins",
+		},
+		{
+			testName:        "管理者グループ以外ではロールを変更しない",
+			group:           &model.Group{ID: "group_1", DisplayName: "Sales", MemberIDs: []string{}},
+			operations:      []SCIMPatchOperation{op("replace", "members", `[{"value":"user_1"},{"value":"user_2"}]`)},
+			setupMocks:      func(adminUC *MockAdminUsecase) {},
+			expectedMembers: []string{"user_1", "user_2"},
+			expectedName:    "Sales",
+		},
+		{
+			testName:      "存在しないユーザーはメンバーにできない",
+			group:         &model.Group{ID: "group_1", DisplayName: "Sales", MemberIDs: []string{}},
+			operations:    []SCIMPatchOperation{op("add", "members", `[{"value":"unknown"}]`)},
+			setupMocks:    func(adminUC *MockAdminUsecase) {},
+			expectedError: ErrSCIMInvalidValue,
+		},
+		{
+			testName:      "メンバーのフィルターはremoveのみ",
+			group:         &model.Group{ID: "group_1", DisplayName: "Sales", MemberIDs: []string{}},
+			operations:    []SCIMPatchOperation{op("add", `members[value eq "user_1"]`, `{}`)},
+			setupMocks:    func(adminUC *MockAdminUsecase) {},
+			expectedError: ErrSCIMInvalidPath,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			userRepo := new(MockUserRepository)
+			groupRepo := new(MockGroupRepository)
+			auditRepo := new(MockAuditRepository)
+			adminUC := new(MockAdminUsecase)
+			userRepo.On("FindAll", mock.Anything).Return(users, nil).Maybe()
+			for _, user := range users {
+				userRepo.On("FindByID", mock.Anything, user.ID).Return(user, nil).Maybe()
+			}
+			groupRepo.On("FindByID", mock.Anything, tt.group.ID).Return(tt.group, nil)
+			groupRepo.On("FindAll", mock.Anything).Return([]*model.Group{tt.group}, nil).Maybe()
+			groupRepo.On("Update", mock.Anything, mock.Anything).Return(nil).Maybe()
+			auditRepo.On("Append", mock.Anything, scimProvisionOf("group", "update", tt.group.ID)).Return(nil).Maybe()
+			tt.setupMocks(adminUC)
+
+			usecase := NewSCIMUsecase(userRepo, groupRepo, auditRepo, nil, adminUC, "Admins")
+			group, err := usecase.PatchGroup(context.Background(), &SCIMPatchInput{ID: tt.group.ID, Operations: tt.operations})
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				groupRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectedMembers, group.MemberIDs)
+				assert.Equal(t, tt.expectedName, group.DisplayName)
+			}
+			adminUC.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSCIMUsecaseImpl_CreateGroup(t *testing.T) {
+	userRepo := new(MockUserRepository)
+	groupRepo := new(MockGroupRepository)
+	auditRepo := new(MockAuditRepository)
+	adminUC := new(MockAdminUsecase)
+	userRepo.On("FindAll", mock.Anything).Return([]*model.User{{ID: "user_1"}}, nil)
+	groupRepo.On("FindAll", mock.Anything).Return([]*model.Group{{ID: "group_1", DisplayName: "Sales"}}, nil)
+	groupRepo.On("Save", mock.Anything, mock.Anything).Return(nil)
+	auditRepo.On("Append", mock.Anything, mock.Anything).Return(nil)
+	adminUC.On("ChangeRole", mock.Anything, mock.MatchedBy(func(input *ChangeRoleInput) bool {
+		return input.UserID == "user_1" && input.Role == model.RoleAdmin
+	})).Return(&model.User{ID: "user_1", Role: model.RoleAdmin}, nil)
+
+	usecase := NewSCIMUsecase(userRepo, groupRepo, auditRepo, nil, adminUC, "Admins")
+
+	// 管理者グループのメンバーは管理者になる
+	group, err := usecase.CreateGroup(context.Background(), &SCIMGroupInput{DisplayName: "Admins", ExternalID: "dir-1", MemberIDs: []string{"user_1"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user_1"}, group.MemberIDs)
+	adminUC.AssertExpectations(t)
+
+	// 表示名は大文字・小文字を区別せず重複できない
+	_, err = usecase.CreateGroup(context.Background(), &SCIMGroupInput{DisplayName: "sales"})
+	assert.ErrorIs(t, err, ErrSCIMUniqueness)
+	assert.Equal(t, http.StatusConflict, core.AsAppError(err).Status)
+}
+
+// scimActive はSCIMのactive属性の値を返す
+func scimActive(active bool) *bool {
+	return &active
+}
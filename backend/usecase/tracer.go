@@ -0,0 +1,7 @@
+package usecase
+
+import "go.opentelemetry.io/otel"
+
+// tracer はユースケースのスパンを作成するトレーサー
+// グローバルのTracerProviderに委譲するため、起動時の設定より前に初期化されてもよい
+var tracer = otel.Tracer("stackies-backend/usecase")
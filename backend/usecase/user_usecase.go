@@ -0,0 +1,237 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"stackies-backend/core"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/repository"
+	"stackies-backend/domain/service"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidProfile = core.NewAppError("invalid_profile", http.StatusBadRequest, "Invalid profile")
+	ErrInvalidAvatar  = core.NewAppError("invalid_avatar", http.StatusBadRequest, "Image must be a JPEG, PNG, GIF or WebP file")
+	ErrAvatarNotFound = core.NewAppError("avatar_not_found", http.StatusNotFound, "Avatar not found")
+)
+
+// UserUsecase はユーザーのプロフィールに関するビジネスロジックを抽象化する
+type UserUsecase interface {
+	UpdateProfile(ctx context.Context, input *UpdateProfileInput) (*model.User, error)
+	GetPublicProfile(ctx context.Context, userID string) (*model.PublicProfile, error)
+	UploadAvatar(ctx context.Context, userID string, data []byte) (*model.User, error)
+	DeleteAvatar(ctx context.Context, userID string) (*model.User, error)
+	GetAvatar(ctx context.Context, userID string, size int) (*AvatarOutput, error)
+}
+
+type (
+	// UpdateProfileInput はプロフィール編集の入力パラメータを表す（nilの項目は変更しない）
+	UpdateProfileInput struct {
+		UserID  string
+		Name    *string
+		Picture *string
+	}
+
+	// AvatarOutput はプロフィール画像の取得結果を表す
+	// RedirectURLが空でない場合は保存先から直接取得させ、空の場合はBlobを返す
+	AvatarOutput struct {
+		RedirectURL string
+		Blob        *model.Blob
+		// ETag はバージョンとサイズごとに一意な値（画像の内容が変わると変わる）
+		ETag string
+	}
+
+	// UserUsecaseImpl はUserUsecaseの実装
+	UserUsecaseImpl struct {
+		userRepo       repository.UserRepository
+		events         *EventOutbox
+		blobStore      service.BlobStore
+		imageProcessor service.ImageProcessor
+		avatarBaseURL  string
+	}
+)
+
+// NewUserUsecase は新しいUserUsecaseを作成する
+// avatarBaseURLはアップロードした画像のURLに使うバックエンドの公開URL（eventsがnilの場合、ドメインイベントは保存しない）
+func NewUserUsecase(userRepo repository.UserRepository, events *EventOutbox, blobStore service.BlobStore, imageProcessor service.ImageProcessor, avatarBaseURL string) UserUsecase {
+	return &UserUsecaseImpl{
+		userRepo:       userRepo,
+		events:         events,
+		blobStore:      blobStore,
+		imageProcessor: imageProcessor,
+		avatarBaseURL:  strings.TrimSuffix(avatarBaseURL, "/"),
+	}
+}
+
+// UpdateProfile はユーザー自身によるプロフィールの編集を保存する
+// 編集した項目は以降のログインでGoogleのプロフィールにより上書きしない
+func (u *UserUsecaseImpl) UpdateProfile(ctx context.Context, input *UpdateProfileInput) (*model.User, error) {
+	user, err := u.userRepo.FindByID(ctx, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := user.Avatar
+	if err := user.EditProfile(input.Name, input.Picture); err != nil {
+		return nil, ErrInvalidProfile.Wrap(err)
+	}
+	var fields []string
+	if input.Name != nil {
+		fields = append(fields, "name")
+	}
+	if input.Picture != nil {
+		fields = append(fields, "picture")
+	}
+	if err := u.updateProfile(ctx, user, fields...); err != nil {
+		return nil, err
+	}
+	// 画像のURLを指定した場合、アップロード済みの画像は使われなくなる
+	if previous != nil && user.Avatar == nil {
+		deleteAvatarBlobs(ctx, u.blobStore, user.ID, previous)
+	}
+
+	return user, nil
+}
+
+// GetPublicProfile は他のユーザーに公開するプロフィールを返す
+// 無効化されたユーザーは存在しないものとして扱う
+func (u *UserUsecaseImpl) GetPublicProfile(ctx context.Context, userID string) (*model.PublicProfile, error) {
+	user, err := u.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsBlocked(time.Now()) || user.IsDeleted() {
+		return nil, repository.ErrUserNotFound
+	}
+	return user.PublicProfile(), nil
+}
+
+// UploadAvatar はアップロードされた画像を検証・変換して保存し、プロフィール画像にする
+// 画像の形式はデータの内容から判定し、EXIFなどのメタデータは保存しない
+func (u *UserUsecaseImpl) UploadAvatar(ctx context.Context, userID string, data []byte) (*model.User, error) {
+	user, err := u.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	format, images, err := u.imageProcessor.ProcessAvatar(data)
+	if errors.Is(err, service.ErrUnsupportedImage) {
+		return nil, ErrInvalidAvatar.Wrap(err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := generateRandomToken(8)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate avatar version: %w", err)
+	}
+	avatar := &model.Avatar{Version: version, Format: format, UploadedAt: time.Now()}
+
+	// 新しいバージョンのキーに保存するため、保存中も以前の画像を返せる
+	for _, img := range images {
+		blob := &model.Blob{ContentType: format.ContentType(), Data: img.Data}
+		if err := u.blobStore.Put(ctx, avatar.Key(user.ID, img.Size), blob); err != nil {
+			deleteAvatarBlobs(ctx, u.blobStore, user.ID, avatar)
+			return nil, err
+		}
+	}
+
+	previous := user.Avatar
+	user.SetAvatar(avatar, u.avatarURL(user.ID, avatar))
+	if err := u.updateProfile(ctx, user, "picture"); err != nil {
+		deleteAvatarBlobs(ctx, u.blobStore, user.ID, avatar)
+		return nil, err
+	}
+	if previous != nil {
+		deleteAvatarBlobs(ctx, u.blobStore, user.ID, previous)
+	}
+
+	return user, nil
+}
+
+// DeleteAvatar はアップロードした画像を削除する（次回のログインでIdPの画像に戻る）
+func (u *UserUsecaseImpl) DeleteAvatar(ctx context.Context, userID string) (*model.User, error) {
+	user, err := u.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Avatar == nil {
+		return nil, ErrAvatarNotFound
+	}
+
+	previous := user.Avatar
+	user.RemoveAvatar()
+	if err := u.updateProfile(ctx, user, "picture"); err != nil {
+		return nil, err
+	}
+	deleteAvatarBlobs(ctx, u.blobStore, user.ID, previous)
+
+	return user, nil
+}
+
+// GetAvatar は要求されたサイズに最も近いプロフィール画像を返す
+// 保存先が直接取得できるURLを発行する場合はリダイレクト先を返す
+func (u *UserUsecaseImpl) GetAvatar(ctx context.Context, userID string, size int) (*AvatarOutput, error) {
+	user, err := u.userRepo.FindByID(ctx, userID)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return nil, ErrAvatarNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if user.Avatar == nil || user.IsBlocked(time.Now()) || user.IsDeleted() {
+		return nil, ErrAvatarNotFound
+	}
+
+	size = model.AvatarSizeFor(size)
+	key := user.Avatar.Key(user.ID, size)
+	etag := fmt.Sprintf(`"%s-%d"`, user.Avatar.Version, size)
+
+	redirectURL, err := u.blobStore.URL(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if redirectURL != "" {
+		return &AvatarOutput{RedirectURL: redirectURL, ETag: etag}, nil
+	}
+
+	blob, err := u.blobStore.Get(ctx, key)
+	if errors.Is(err, service.ErrBlobNotFound) {
+		return nil, ErrAvatarNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &AvatarOutput{Blob: blob, ETag: etag}, nil
+}
+
+// updateProfile はプロフィールの変更を保存し、変更した項目を含むprofile.updatedイベントを保存する
+func (u *UserUsecaseImpl) updateProfile(ctx context.Context, user *model.User, fields ...string) error {
+	event := model.NewDomainEvent(model.DomainEventProfileUpdated, user.ID, user.ID, map[string]string{"fields": strings.Join(fields, ",")})
+	return u.events.Write(ctx, func(ctx context.Context) error {
+		return u.userRepo.Update(ctx, user)
+	}, event)
+}
+
+// avatarURL はプロフィール画像のURLを返す（バージョンを含めてアップロードのたびに変わるようにする）
+func (u *UserUsecaseImpl) avatarURL(userID string, avatar *model.Avatar) string {
+	return u.avatarBaseURL + "/users/" + url.PathEscape(userID) + "/avatar?v=" + url.QueryEscape(avatar.Version)
+}
+
+// deleteAvatarBlobs は使われなくなった画像を削除する
+// 削除に失敗しても参照されないため、ログに出力して処理を続ける
+func deleteAvatarBlobs(ctx context.Context, blobStore service.BlobStore, userID string, avatar *model.Avatar) {
+	for _, size := range model.AvatarSizes {
+		key := avatar.Key(userID, size)
+		if err := blobStore.Delete(ctx, key); err != nil {
+			slog.WarnContext(ctx, "failed to delete avatar", slog.String("key", key), slog.Any("error", err))
+		}
+	}
+}
@@ -0,0 +1,420 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/repository"
+	"stackies-backend/domain/service"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockBlobStore はBlobStoreのモック
+type MockBlobStore struct {
+	mock.Mock
+}
+
+var _ service.BlobStore = (*MockBlobStore)(nil)
+
+func (m *MockBlobStore) Put(ctx context.Context, key string, blob *model.Blob) error {
+	args := m.Called(ctx, key, blob)
+	return args.Error(0)
+}
+
+func (m *MockBlobStore) Get(ctx context.Context, key string) (*model.Blob, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Blob), args.Error(1)
+}
+
+func (m *MockBlobStore) Delete(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *MockBlobStore) URL(ctx context.Context, key string) (string, error) {
+	args := m.Called(ctx, key)
+	return args.String(0), args.Error(1)
+}
+
+// MockImageProcessor はImageProcessorのモック
+type MockImageProcessor struct {
+	mock.Mock
+}
+
+var _ service.ImageProcessor = (*MockImageProcessor)(nil)
+
+func (m *MockImageProcessor) ProcessAvatar(data []byte) (model.AvatarFormat, []*model.AvatarImage, error) {
+	args := m.Called(data)
+	if args.Get(1) == nil {
+		return "", nil, args.Error(2)
+	}
+	return args.Get(0).(model.AvatarFormat), args.Get(1).([]*model.AvatarImage), args.Error(2)
+}
+
+// processedAvatar は各サイズに変換した画像を返す
+func processedAvatar() []*model.AvatarImage {
+	images := make([]*model.AvatarImage, len(model.AvatarSizes))
+	for i, size := range model.AvatarSizes {
+		images[i] = &model.AvatarImage{Size: size, Data: []byte("image")}
+	}
+	return images
+}
+
+func TestUserUsecaseImpl_UpdateProfile(t *testing.T) {
+	name := "Edited User"
+	empty := ""
+	javascriptPicture := "javascript:alert(document.cookie)"
+	dataPicture := "data:image/svg+xml;base64,PHN2Zy8+"
+	relativePicture := "/users/user_2/avatar"
+
+	tests := []struct {
+		testName      string
+		input         *UpdateProfileInput
+		setupMocks    func(*MockUserRepository)
+		expectedError error
+		expectedName  string
+	}{
+		{
+			testName: "表示名を編集",
+			input:    &UpdateProfileInput{UserID: "user_1", Name: &name},
+			setupMocks: func(userRepo *MockUserRepository) {
+				userRepo.On("FindByID", mock.Anything, "user_1").Return(&model.User{ID: "user_1", Name: "Test User"}, nil)
+				userRepo.On("Update", mock.Anything, mock.MatchedBy(func(user *model.User) bool {
+					return user.Name == "Edited User" && user.NameEdited && !user.PictureEdited
+				})).Return(nil)
+			},
+			expectedName: "Edited User",
+		},
+		{
+			testName: "空の表示名",
+			input:    &UpdateProfileInput{UserID: "user_1", Name: &empty},
+			setupMocks: func(userRepo *MockUserRepository) {
+				userRepo.On("FindByID", mock.Anything, "user_1").Return(&model.User{ID: "user_1", Name: "Test User"}, nil)
+			},
+			expectedError: ErrInvalidProfile,
+		},
+		{
+			testName: "javascriptスキームの画像URL",
+			input:    &UpdateProfileInput{UserID: "user_1", Picture: &javascriptPicture},
+			setupMocks: func(userRepo *MockUserRepository) {
+				userRepo.On("FindByID", mock.Anything, "user_1").Return(&model.User{ID: "user_1", Name: "Test User"}, nil)
+			},
+			expectedError: ErrInvalidProfile,
+		},
+		{
+			testName: "dataスキームの画像URL",
+			input:    &UpdateProfileInput{UserID: "user_1", Picture: &dataPicture},
+			setupMocks: func(userRepo *MockUserRepository) {
+				userRepo.On("FindByID", mock.Anything, "user_1").Return(&model.User{ID: "user_1", Name: "Test User"}, nil)
+			},
+			expectedError: ErrInvalidProfile,
+		},
+		{
+			testName: "相対URLの画像",
+			input:    &UpdateProfileInput{UserID: "user_1", Picture: &relativePicture},
+			setupMocks: func(userRepo *MockUserRepository) {
+				userRepo.On("FindByID", mock.Anything, "user_1").Return(&model.User{ID: "user_1", Name: "Test User"}, nil)
+			},
+			expectedError: ErrInvalidProfile,
+		},
+		{
+			testName: "存在しないユーザー",
+			input:    &UpdateProfileInput{UserID: "unknown", Name: &name},
+			setupMocks: func(userRepo *MockUserRepository) {
+				userRepo.On("FindByID", mock.Anything, "unknown").Return((*model.User)(nil), repository.ErrUserNotFound)
+			},
+			expectedError: repository.ErrUserNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			userRepo := new(MockUserRepository)
+			tt.setupMocks(userRepo)
+
+			usecase := NewUserUsecase(userRepo, nil, nil, nil, "http://localhost:8080")
+			user, err := usecase.UpdateProfile(context.Background(), tt.input)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, user)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedName, user.Name)
+			}
+
+			userRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUserUsecaseImpl_GetPublicProfile(t *testing.T) {
+	tests := []struct {
+		testName        string
+		user            *model.User
+		expectedError   error
+		expectedProfile *model.PublicProfile
+	}{
+		{
+			testName:        "公開プロフィールにはメールアドレスやロールを含めない",
+			user:            &model.User{ID: "user_1", Email: "test@example.com", Name: "Test User", Picture: "https://example.com/picture.jpg", Role: model.RoleAdmin},
+			expectedProfile: &model.PublicProfile{ID: "user_1", Name: "Test User", Picture: "https://example.com/picture.jpg"},
+		},
+		{
+			testName:      "無効化されたユーザーは存在しないものとして扱う",
+			user:          &model.User{ID: "user_1", Name: "Test User", Status: model.UserStatusDisabled},
+			expectedError: repository.ErrUserNotFound,
+		},
+		{
+			testName:      "一時停止されたユーザーは存在しないものとして扱う",
+			user:          &model.User{ID: "user_1", Name: "Test User", Status: model.UserStatusSuspended},
+			expectedError: repository.ErrUserNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			userRepo := new(MockUserRepository)
+			userRepo.On("FindByID", mock.Anything, "user_1").Return(tt.user, nil)
+
+			usecase := NewUserUsecase(userRepo, nil, nil, nil, "http://localhost:8080")
+			profile, err := usecase.GetPublicProfile(context.Background(), "user_1")
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, profile)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedProfile, profile)
+			}
+		})
+	}
+}
+
+func TestUserUsecaseImpl_UpdateProfile_ReplacesAvatar(t *testing.T) {
+	picture := "https://example.com/edited.jpg"
+	previous := &model.Avatar{Version: "old", Format: model.AvatarFormatJPEG}
+
+	userRepo := new(MockUserRepository)
+	blobStore := new(MockBlobStore)
+	userRepo.On("FindByID", mock.Anything, "user_1").Return(&model.User{ID: "user_1", Name: "Test User", Avatar: previous}, nil)
+	userRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+	for _, size := range model.AvatarSizes {
+		blobStore.On("Delete", mock.Anything, previous.Key("user_1", size)).Return(nil)
+	}
+
+	usecase := NewUserUsecase(userRepo, nil, blobStore, nil, "http://localhost:8080")
+	user, err := usecase.UpdateProfile(context.Background(), &UpdateProfileInput{UserID: "user_1", Picture: &picture})
+
+	// 画像のURLを指定した場合はアップロード済みの画像を削除する
+	assert.NoError(t, err)
+	assert.Nil(t, user.Avatar)
+	assert.Equal(t, picture, user.Picture)
+	blobStore.AssertExpectations(t)
+}
+
+func TestUserUsecaseImpl_UploadAvatar(t *testing.T) {
+	previous := &model.Avatar{Version: "old", Format: model.AvatarFormatPNG}
+
+	tests := []struct {
+		testName       string
+		user           *model.User
+		setupMocks     func(*MockUserRepository, *MockBlobStore, *MockImageProcessor)
+		expectedError  error
+		expectedErrMsg string
+	}{
+		{
+			testName: "各サイズを保存してプロフィール画像にする",
+			user:     &model.User{ID: "user_1", Name: "Test User", Picture: "https://example.com/google.jpg"},
+			setupMocks: func(userRepo *MockUserRepository, blobStore *MockBlobStore, processor *MockImageProcessor) {
+				processor.On("ProcessAvatar", []byte("upload")).Return(model.AvatarFormatJPEG, processedAvatar(), nil)
+				blobStore.On("Put", mock.Anything, mock.MatchedBy(func(key string) bool {
+					return len(key) > 0 && key[len(key)-4:] == ".jpg"
+				}), mock.MatchedBy(func(blob *model.Blob) bool {
+					return blob.ContentType == "image/jpeg"
+				})).Return(nil).Times(len(model.AvatarSizes))
+				userRepo.On("Update", mock.Anything, mock.MatchedBy(func(user *model.User) bool {
+					return user.Avatar != nil && user.PictureEdited
+				})).Return(nil)
+			},
+		},
+		{
+			testName: "以前の画像を削除する",
+			user:     &model.User{ID: "user_1", Name: "Test User", Avatar: previous},
+			setupMocks: func(userRepo *MockUserRepository, blobStore *MockBlobStore, processor *MockImageProcessor) {
+				processor.On("ProcessAvatar", []byte("upload")).Return(model.AvatarFormatJPEG, processedAvatar(), nil)
+				blobStore.On("Put", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+				userRepo.On("Update", mock.Anything, mock.Anything).Return(nil)
+				for _, size := range model.AvatarSizes {
+					blobStore.On("Delete", mock.Anything, previous.Key("user_1", size)).Return(nil)
+				}
+			},
+		},
+		{
+			testName: "画像でないデータ",
+			user:     &model.User{ID: "user_1", Name: "Test User"},
+			setupMocks: func(userRepo *MockUserRepository, blobStore *MockBlobStore, processor *MockImageProcessor) {
+				processor.On("ProcessAvatar", []byte("upload")).Return("", nil, service.ErrUnsupportedImage)
+			},
+			expectedError: ErrInvalidAvatar,
+		},
+		{
+			testName: "保存に失敗した場合は保存済みのサイズを削除する",
+			user:     &model.User{ID: "user_1", Name: "Test User"},
+			setupMocks: func(userRepo *MockUserRepository, blobStore *MockBlobStore, processor *MockImageProcessor) {
+				processor.On("ProcessAvatar", []byte("upload")).Return(model.AvatarFormatJPEG, processedAvatar(), nil)
+				blobStore.On("Put", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("storage unavailable"))
+				blobStore.On("Delete", mock.Anything, mock.Anything).Return(nil).Times(len(model.AvatarSizes))
+			},
+			expectedErrMsg: "storage unavailable",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			userRepo := new(MockUserRepository)
+			blobStore := new(MockBlobStore)
+			processor := new(MockImageProcessor)
+			userRepo.On("FindByID", mock.Anything, "user_1").Return(tt.user, nil)
+			tt.setupMocks(userRepo, blobStore, processor)
+
+			usecase := NewUserUsecase(userRepo, nil, blobStore, processor, "http://localhost:8080/")
+			user, err := usecase.UploadAvatar(context.Background(), "user_1", []byte("upload"))
+
+			switch {
+			case tt.expectedError != nil:
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, user)
+			case tt.expectedErrMsg != "":
+				assert.EqualError(t, err, tt.expectedErrMsg)
+				assert.Nil(t, user)
+			default:
+				assert.NoError(t, err)
+				assert.NotEqual(t, previous, user.Avatar)
+				assert.Equal(t, "http://localhost:8080/users/user_1/avatar?v="+user.Avatar.Version, user.Picture)
+			}
+
+			userRepo.AssertExpectations(t)
+			blobStore.AssertExpectations(t)
+			processor.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUserUsecaseImpl_DeleteAvatar(t *testing.T) {
+	avatar := &model.Avatar{Version: "v1", Format: model.AvatarFormatJPEG}
+
+	t.Run("画像を削除してIdPの画像に戻す", func(t *testing.T) {
+		userRepo := new(MockUserRepository)
+		blobStore := new(MockBlobStore)
+		userRepo.On("FindByID", mock.Anything, "user_1").Return(&model.User{ID: "user_1", Name: "Test User", Picture: "http://localhost:8080/users/user_1/avatar?v=v1", PictureEdited: true, Avatar: avatar}, nil)
+		userRepo.On("Update", mock.Anything, mock.MatchedBy(func(user *model.User) bool {
+			return user.Avatar == nil && !user.PictureEdited
+		})).Return(nil)
+		for _, size := range model.AvatarSizes {
+			blobStore.On("Delete", mock.Anything, avatar.Key("user_1", size)).Return(nil)
+		}
+
+		user, err := NewUserUsecase(userRepo, nil, blobStore, nil, "http://localhost:8080").DeleteAvatar(context.Background(), "user_1")
+		assert.NoError(t, err)
+		assert.Empty(t, user.Picture)
+		userRepo.AssertExpectations(t)
+		blobStore.AssertExpectations(t)
+	})
+
+	t.Run("アップロードしていない場合", func(t *testing.T) {
+		userRepo := new(MockUserRepository)
+		userRepo.On("FindByID", mock.Anything, "user_1").Return(&model.User{ID: "user_1", Name: "Test User"}, nil)
+
+		_, err := NewUserUsecase(userRepo, nil, nil, nil, "http://localhost:8080").DeleteAvatar(context.Background(), "user_1")
+		assert.ErrorIs(t, err, ErrAvatarNotFound)
+	})
+}
+
+func TestUserUsecaseImpl_GetAvatar(t *testing.T) {
+	avatar := &model.Avatar{Version: "v1", Format: model.AvatarFormatPNG}
+	blob := &model.Blob{ContentType: "image/png", Data: []byte("png")}
+
+	tests := []struct {
+		testName       string
+		user           *model.User
+		findErr        error
+		size           int
+		setupMocks     func(*MockBlobStore)
+		expectedError  error
+		expectedOutput *AvatarOutput
+	}{
+		{
+			testName: "保存先から取得したデータを返す",
+			user:     &model.User{ID: "user_1", Avatar: avatar},
+			size:     100,
+			setupMocks: func(blobStore *MockBlobStore) {
+				blobStore.On("URL", mock.Anything, "avatars/user_1/v1/128.png").Return("", nil)
+				blobStore.On("Get", mock.Anything, "avatars/user_1/v1/128.png").Return(blob, nil)
+			},
+			expectedOutput: &AvatarOutput{Blob: blob, ETag: `"v1-128"`},
+		},
+		{
+			testName: "保存先のURLがある場合はリダイレクト先を返す",
+			user:     &model.User{ID: "user_1", Avatar: avatar},
+			setupMocks: func(blobStore *MockBlobStore) {
+				blobStore.On("URL", mock.Anything, "avatars/user_1/v1/256.png").Return("https://cdn.example.com/avatars/user_1/v1/256.png", nil)
+			},
+			expectedOutput: &AvatarOutput{RedirectURL: "https://cdn.example.com/avatars/user_1/v1/256.png", ETag: `"v1-256"`},
+		},
+		{
+			testName:      "アップロードしていないユーザー",
+			user:          &model.User{ID: "user_1"},
+			setupMocks:    func(blobStore *MockBlobStore) {},
+			expectedError: ErrAvatarNotFound,
+		},
+		{
+			testName:      "無効化されたユーザー",
+			user:          &model.User{ID: "user_1", Avatar: avatar, Status: model.UserStatusDisabled},
+			setupMocks:    func(blobStore *MockBlobStore) {},
+			expectedError: ErrAvatarNotFound,
+		},
+		{
+			testName:      "存在しないユーザー",
+			findErr:       repository.ErrUserNotFound,
+			setupMocks:    func(blobStore *MockBlobStore) {},
+			expectedError: ErrAvatarNotFound,
+		},
+		{
+			testName: "保存先にデータがない",
+			user:     &model.User{ID: "user_1", Avatar: avatar},
+			size:     64,
+			setupMocks: func(blobStore *MockBlobStore) {
+				blobStore.On("URL", mock.Anything, "avatars/user_1/v1/64.png").Return("", nil)
+				blobStore.On("Get", mock.Anything, "avatars/user_1/v1/64.png").Return(nil, service.ErrBlobNotFound)
+			},
+			expectedError: ErrAvatarNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			userRepo := new(MockUserRepository)
+			blobStore := new(MockBlobStore)
+			userRepo.On("FindByID", mock.Anything, "user_1").Return(tt.user, tt.findErr)
+			tt.setupMocks(blobStore)
+
+			output, err := NewUserUsecase(userRepo, nil, blobStore, nil, "http://localhost:8080").GetAvatar(context.Background(), "user_1", tt.size)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, output)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedOutput, output)
+			}
+			blobStore.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,434 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"stackies-backend/core"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/repository"
+	"stackies-backend/domain/service"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// webhookDeliveryBatchSize は1回の送信で取り出す配信の最大件数
+	webhookDeliveryBatchSize = 100
+	// webhookRetryBaseDelay・webhookRetryMaxDelayは送信に失敗した配信を再試行するまでの待ち時間（指数バックオフ）
+	webhookRetryBaseDelay = 30 * time.Second
+	webhookRetryMaxDelay  = time.Hour
+	// webhookSecretSize はシークレットを指定しなかった場合に生成するシークレットのバイト数
+	webhookSecretSize = 32
+)
+
+var (
+	ErrInvalidWebhook         = core.NewAppError("invalid_webhook", http.StatusBadRequest, "Invalid webhook subscription")
+	ErrInvalidWebhookFilter   = core.NewAppError("invalid_webhook_filter", http.StatusBadRequest, "Invalid webhook delivery filter")
+	ErrWebhookInactive        = core.NewAppError("webhook_inactive", http.StatusConflict, "Webhook subscription is inactive")
+	ErrWebhookDeliveryPending = core.NewAppError("webhook_delivery_pending", http.StatusConflict, "Webhook delivery is still pending")
+)
+
+// WebhookUsecase はWebhookの購読の管理と配信のビジネスロジックを抽象化する
+type WebhookUsecase interface {
+	CreateSubscription(ctx context.Context, input *CreateWebhookInput) (*model.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context) ([]*model.WebhookSubscription, error)
+	GetSubscription(ctx context.Context, id string) (*model.WebhookSubscription, error)
+	UpdateSubscription(ctx context.Context, input *UpdateWebhookInput) (*model.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, input *DeleteWebhookInput) error
+	ListDeliveries(ctx context.Context, input *ListWebhookDeliveriesInput) (*model.WebhookDeliveryPage, error)
+	ReplayDelivery(ctx context.Context, input *ReplayWebhookDeliveryInput) (*model.WebhookDelivery, error)
+	// HandleEvent はドメインイベントを購読しているすべての購読先への配信を作成する（EventDispatcherの購読者）
+	HandleEvent(ctx context.Context, event *model.DomainEvent) error
+	// DeliverDue は送信時刻を過ぎた配信を送信し、成功した件数を返す
+	DeliverDue(ctx context.Context) (int, error)
+	// PurgeDeliveries は保持期間を過ぎた成功済みの配信を削除し、削除した件数を返す
+	PurgeDeliveries(ctx context.Context) (int, error)
+}
+
+type (
+	// CreateWebhookInput は購読の作成の入力パラメータを表す
+	CreateWebhookInput struct {
+		ActorID     string
+		URL         string
+		EventTypes  []model.DomainEventType
+		Description string
+		// Secret は署名に使うシークレット（空の場合は生成する）
+		Secret    string
+		ClientIP  string
+		UserAgent string
+	}
+
+	// UpdateWebhookInput は購読の変更の入力パラメータを表す（nilの項目は変更しない）
+	UpdateWebhookInput struct {
+		ActorID     string
+		ID          string
+		URL         *string
+		EventTypes  []model.DomainEventType
+		Description *string
+		Active      *bool
+		// Secret はシークレットのローテーションに使う
+		Secret    *string
+		ClientIP  string
+		UserAgent string
+	}
+
+	// DeleteWebhookInput は購読の削除の入力パラメータを表す
+	DeleteWebhookInput struct {
+		ActorID   string
+		ID        string
+		ClientIP  string
+		UserAgent string
+	}
+
+	// ListWebhookDeliveriesInput は購読ごとの配信履歴の検索の入力パラメータを表す
+	ListWebhookDeliveriesInput struct {
+		SubscriptionID string
+		Status         model.WebhookDeliveryStatus
+		Limit          int
+		Cursor         string
+	}
+
+	// ReplayWebhookDeliveryInput は配信の再送の入力パラメータを表す
+	ReplayWebhookDeliveryInput struct {
+		ActorID        string
+		SubscriptionID string
+		DeliveryID     string
+		ClientIP       string
+		UserAgent      string
+	}
+
+	// WebhookUsecaseImpl はWebhookUsecaseの実装
+	WebhookUsecaseImpl struct {
+		webhookRepo  repository.WebhookRepository
+		deliveryRepo repository.WebhookDeliveryRepository
+		auditRepo    repository.AuditRepository
+		sender       service.WebhookSender
+		maxAttempts  int
+		// allowPrivateTargets はhttpとループバック・プライベートアドレスの送信先を許可するかどうか（開発環境のみ）
+		allowPrivateTargets bool
+		// retention は成功した配信を保持する期間（0の場合は削除しない）
+		retention time.Duration
+		// delivering は同じ配信を並行して送信しないための排他制御
+		delivering sync.Mutex
+	}
+)
+
+// NewWebhookUsecase は新しいWebhookUsecaseを作成する
+// maxAttempts回失敗した配信はデッドレターとなり、管理者が再送するまで送信しない
+// 成功した配信はretentionの間だけ保持し、同じイベントの重複した配信の判定もその間に限る
+func NewWebhookUsecase(
+	webhookRepo repository.WebhookRepository,
+	deliveryRepo repository.WebhookDeliveryRepository,
+	auditRepo repository.AuditRepository,
+	sender service.WebhookSender,
+	maxAttempts int,
+	allowPrivateTargets bool,
+	retention time.Duration,
+) WebhookUsecase {
+	return &WebhookUsecaseImpl{
+		webhookRepo:         webhookRepo,
+		deliveryRepo:        deliveryRepo,
+		auditRepo:           auditRepo,
+		sender:              sender,
+		maxAttempts:         maxAttempts,
+		allowPrivateTargets: allowPrivateTargets,
+		retention:           retention,
+	}
+}
+
+// CreateSubscription は購読を作成し、監査ログに記録する
+// 返す購読のSecretは、このときのみ呼び出し元に知らせる
+func (w *WebhookUsecaseImpl) CreateSubscription(ctx context.Context, input *CreateWebhookInput) (*model.WebhookSubscription, error) {
+	if err := w.validateWebhook(input.URL, input.EventTypes); err != nil {
+		return nil, err
+	}
+	secret := input.Secret
+	if secret == "" {
+		generated, err := generateRandomToken(webhookSecretSize)
+		if err != nil {
+			return nil, err
+		}
+		secret = generated
+	} else if err := model.ValidateWebhookSecret(secret); err != nil {
+		return nil, ErrInvalidWebhook.WithMessage(err.Error())
+	}
+
+	id, err := generateRandomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	subscription := &model.WebhookSubscription{
+		ID:          id,
+		URL:         input.URL,
+		EventTypes:  input.EventTypes,
+		Description: strings.TrimSpace(input.Description),
+		Secret:      secret,
+		Active:      true,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := w.webhookRepo.Save(ctx, subscription); err != nil {
+		return nil, err
+	}
+
+	w.recordChange(ctx, "create", subscription.ID, input.ActorID, input.ClientIP, input.UserAgent)
+	return subscription, nil
+}
+
+// ListSubscriptions はすべての購読を作成日時の昇順で返す
+func (w *WebhookUsecaseImpl) ListSubscriptions(ctx context.Context) ([]*model.WebhookSubscription, error) {
+	return w.webhookRepo.FindAll(ctx)
+}
+
+// GetSubscription はIDで購読を返す
+func (w *WebhookUsecaseImpl) GetSubscription(ctx context.Context, id string) (*model.WebhookSubscription, error) {
+	return w.webhookRepo.FindByID(ctx, id)
+}
+
+// UpdateSubscription は購読を変更し、監査ログに記録する
+// 無効化した購読の配信待ちの配信は、次の送信時にデッドレターとなる
+func (w *WebhookUsecaseImpl) UpdateSubscription(ctx context.Context, input *UpdateWebhookInput) (*model.WebhookSubscription, error) {
+	subscription, err := w.webhookRepo.FindByID(ctx, input.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.URL != nil {
+		subscription.URL = *input.URL
+	}
+	if input.EventTypes != nil {
+		subscription.EventTypes = input.EventTypes
+	}
+	if err := w.validateWebhook(subscription.URL, subscription.EventTypes); err != nil {
+		return nil, err
+	}
+	if input.Secret != nil {
+		if err := model.ValidateWebhookSecret(*input.Secret); err != nil {
+			return nil, ErrInvalidWebhook.WithMessage(err.Error())
+		}
+		subscription.Secret = *input.Secret
+	}
+	if input.Description != nil {
+		subscription.Description = strings.TrimSpace(*input.Description)
+	}
+	if input.Active != nil {
+		subscription.Active = *input.Active
+	}
+	subscription.UpdatedAt = time.Now()
+	if err := w.webhookRepo.Update(ctx, subscription); err != nil {
+		return nil, err
+	}
+
+	w.recordChange(ctx, "update", subscription.ID, input.ActorID, input.ClientIP, input.UserAgent)
+	return subscription, nil
+}
+
+// DeleteSubscription は購読とその配信履歴を削除し、監査ログに記録する
+func (w *WebhookUsecaseImpl) DeleteSubscription(ctx context.Context, input *DeleteWebhookInput) error {
+	if err := w.webhookRepo.Delete(ctx, input.ID); err != nil {
+		return err
+	}
+	if err := w.deliveryRepo.DeleteBySubscriptionID(ctx, input.ID); err != nil {
+		return err
+	}
+	w.sender.Forget(input.ID)
+
+	w.recordChange(ctx, "delete", input.ID, input.ActorID, input.ClientIP, input.UserAgent)
+	return nil
+}
+
+// ListDeliveries は購読の配信履歴を新しい順に1ページ分返す
+func (w *WebhookUsecaseImpl) ListDeliveries(ctx context.Context, input *ListWebhookDeliveriesInput) (*model.WebhookDeliveryPage, error) {
+	if input.Status != "" && !input.Status.IsValid() {
+		return nil, ErrInvalidWebhookFilter.WithMessage("Invalid status")
+	}
+	if _, err := w.webhookRepo.FindByID(ctx, input.SubscriptionID); err != nil {
+		return nil, err
+	}
+
+	return w.deliveryRepo.Query(ctx, &model.WebhookDeliveryFilter{
+		SubscriptionID: input.SubscriptionID,
+		Status:         input.Status,
+		Limit:          input.Limit,
+		Cursor:         input.Cursor,
+	})
+}
+
+// ReplayDelivery は配信と同じ内容を送り直す新しい配信を作成し、監査ログに記録する
+// デッドレターとなった配信のほか、成功した配信も受信側の復旧のために再送できる
+func (w *WebhookUsecaseImpl) ReplayDelivery(ctx context.Context, input *ReplayWebhookDeliveryInput) (*model.WebhookDelivery, error) {
+	subscription, err := w.webhookRepo.FindByID(ctx, input.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if !subscription.Active {
+		return nil, ErrWebhookInactive
+	}
+
+	original, err := w.deliveryRepo.FindByID(ctx, input.DeliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if original.SubscriptionID != subscription.ID {
+		return nil, repository.ErrWebhookDeliveryNotFound
+	}
+	if original.Status == model.WebhookDeliveryPending {
+		return nil, ErrWebhookDeliveryPending
+	}
+
+	id, err := generateRandomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	replay := original.Replay(id)
+	if _, err := w.deliveryRepo.Save(ctx, replay); err != nil {
+		return nil, err
+	}
+
+	event := newAuditEvent(model.AuditEventWebhookChange, model.AuditOutcomeSuccess, input.ActorID, "", input.ClientIP, input.UserAgent)
+	event.Metadata = map[string]string{"action": "replay", "webhook_id": subscription.ID, "delivery_id": original.ID}
+	recordAuditEvent(ctx, w.auditRepo, event)
+
+	return replay, nil
+}
+
+// HandleEvent はドメインイベントを購読しているすべての購読先への配信を作成する
+// 同じイベントが再配信されても、購読先ごとの配信は1回しか作成しない
+func (w *WebhookUsecaseImpl) HandleEvent(ctx context.Context, event *model.DomainEvent) error {
+	subscriptions, err := w.webhookRepo.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	var payload []byte
+	for _, subscription := range subscriptions {
+		if !subscription.Subscribes(event.Type) {
+			continue
+		}
+		if payload == nil {
+			if payload, err = json.Marshal(event); err != nil {
+				return fmt.Errorf("failed to encode webhook payload: %w", err)
+			}
+		}
+		id, err := generateRandomToken(16)
+		if err != nil {
+			return err
+		}
+		if _, err := w.deliveryRepo.Save(ctx, model.NewWebhookDelivery(id, subscription, event, payload)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeliverDue は送信時刻を過ぎた配信を古い順に送信し、成功した件数を返す
+// 2xx以外の応答と接続の失敗は待ち時間を空けて再試行し、maxAttempts回失敗した配信はデッドレターとする
+func (w *WebhookUsecaseImpl) DeliverDue(ctx context.Context) (int, error) {
+	w.delivering.Lock()
+	defer w.delivering.Unlock()
+
+	deliveries, err := w.deliveryRepo.FindDue(ctx, time.Now(), webhookDeliveryBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	succeeded := 0
+	for _, delivery := range deliveries {
+		if err := ctx.Err(); err != nil {
+			return succeeded, err
+		}
+		if w.deliver(ctx, delivery) {
+			succeeded++
+		}
+		if err := w.deliveryRepo.Update(ctx, delivery); err != nil {
+			return succeeded, err
+		}
+	}
+	return succeeded, nil
+}
+
+// PurgeDeliveries は保持期間を過ぎた成功済みの配信を削除し、削除した件数を返す
+// 失敗・デッドレターの配信は再送できるよう削除しない
+func (w *WebhookUsecaseImpl) PurgeDeliveries(ctx context.Context) (int, error) {
+	if w.retention <= 0 {
+		return 0, nil
+	}
+	return w.deliveryRepo.DeleteSucceededBefore(ctx, time.Now().Add(-w.retention))
+}
+
+// deliver は配信を購読先に送信して結果を記録し、成功したかどうかを返す
+func (w *WebhookUsecaseImpl) deliver(ctx context.Context, delivery *model.WebhookDelivery) bool {
+	subscription, err := w.webhookRepo.FindByID(ctx, delivery.SubscriptionID)
+	if err != nil && !errors.Is(err, repository.ErrWebhookNotFound) {
+		w.fail(ctx, delivery, 0, err.Error())
+		return false
+	}
+	// 無効化・削除された購読先には送信しない（有効に戻した後に再送できる）
+	if subscription == nil || !subscription.Active {
+		delivery.Abandon("subscription is inactive")
+		return false
+	}
+
+	status, err := w.sender.Send(ctx, subscription, delivery)
+	switch {
+	case err != nil:
+		w.fail(ctx, delivery, 0, err.Error())
+		return false
+	case status < 200 || status >= 300:
+		w.fail(ctx, delivery, status, fmt.Sprintf("unexpected status: %d", status))
+		return false
+	}
+	delivery.Succeed(status, time.Now())
+	return true
+}
+
+// fail は送信の失敗を記録し、デッドレターとなった場合はログに残す
+func (w *WebhookUsecaseImpl) fail(ctx context.Context, delivery *model.WebhookDelivery, status int, cause string) {
+	delivery.Fail(status, cause, w.maxAttempts, webhookRetryDelay(delivery.Attempts), time.Now())
+
+	attrs := []any{
+		slog.String("delivery_id", delivery.ID),
+		slog.String("webhook_id", delivery.SubscriptionID),
+		slog.String("event_type", string(delivery.EventType)),
+		slog.Int("attempts", delivery.Attempts),
+		slog.String("error", cause),
+	}
+	if delivery.Status == model.WebhookDeliveryDead {
+		slog.ErrorContext(ctx, "webhook delivery moved to dead letter", attrs...)
+		return
+	}
+	slog.WarnContext(ctx, "failed to deliver webhook", append(attrs, slog.Time("next_attempt_at", delivery.NextAttemptAt))...)
+}
+
+// recordChange は購読の変更を監査ログに記録する
+func (w *WebhookUsecaseImpl) recordChange(ctx context.Context, action, subscriptionID, actorID, clientIP, userAgent string) {
+	event := newAuditEvent(model.AuditEventWebhookChange, model.AuditOutcomeSuccess, actorID, "", clientIP, userAgent)
+	event.Metadata = map[string]string{"action": action, "webhook_id": subscriptionID}
+	recordAuditEvent(ctx, w.auditRepo, event)
+}
+
+// validateWebhook は購読の送信先とイベントの種類を確認する
+func (w *WebhookUsecaseImpl) validateWebhook(url string, eventTypes []model.DomainEventType) error {
+	if err := model.ValidateWebhookURL(url, w.allowPrivateTargets); err != nil {
+		return ErrInvalidWebhook.WithMessage(err.Error())
+	}
+	if err := model.ValidateWebhookEventTypes(eventTypes); err != nil {
+		return ErrInvalidWebhook.WithMessage(err.Error())
+	}
+	return nil
+}
+
+// webhookRetryDelay はそれまでに失敗した回数に応じた再試行までの待ち時間を返す
+func webhookRetryDelay(attempts int) time.Duration {
+	delay := webhookRetryBaseDelay
+	for i := 0; i < attempts && delay < webhookRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, webhookRetryMaxDelay)
+}
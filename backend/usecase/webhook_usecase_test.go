@@ -0,0 +1,454 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"stackies-backend/domain/model"
+	"stackies-backend/domain/repository"
+	"stackies-backend/domain/service"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockWebhookRepository はWebhookRepositoryのモック
+type MockWebhookRepository struct {
+	mock.Mock
+}
+
+var _ repository.WebhookRepository = (*MockWebhookRepository)(nil)
+
+func (m *MockWebhookRepository) Save(ctx context.Context, subscription *model.WebhookSubscription) error {
+	args := m.Called(ctx, subscription)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) FindByID(ctx context.Context, id string) (*model.WebhookSubscription, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookRepository) FindAll(ctx context.Context) ([]*model.WebhookSubscription, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookRepository) Update(ctx context.Context, subscription *model.WebhookSubscription) error {
+	args := m.Called(ctx, subscription)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+// MockWebhookDeliveryRepository はWebhookDeliveryRepositoryのモック
+type MockWebhookDeliveryRepository struct {
+	mock.Mock
+}
+
+var _ repository.WebhookDeliveryRepository = (*MockWebhookDeliveryRepository)(nil)
+
+func (m *MockWebhookDeliveryRepository) Save(ctx context.Context, delivery *model.WebhookDelivery) (bool, error) {
+	args := m.Called(ctx, delivery)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockWebhookDeliveryRepository) FindByID(ctx context.Context, id string) (*model.WebhookDelivery, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookDeliveryRepository) Update(ctx context.Context, delivery *model.WebhookDelivery) error {
+	args := m.Called(ctx, delivery)
+	return args.Error(0)
+}
+
+func (m *MockWebhookDeliveryRepository) FindDue(ctx context.Context, now time.Time, limit int) ([]*model.WebhookDelivery, error) {
+	args := m.Called(ctx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookDeliveryRepository) Query(ctx context.Context, filter *model.WebhookDeliveryFilter) (*model.WebhookDeliveryPage, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.WebhookDeliveryPage), args.Error(1)
+}
+
+func (m *MockWebhookDeliveryRepository) DeleteBySubscriptionID(ctx context.Context, subscriptionID string) error {
+	args := m.Called(ctx, subscriptionID)
+	return args.Error(0)
+}
+
+func (m *MockWebhookDeliveryRepository) DeleteSucceededBefore(ctx context.Context, before time.Time) (int, error) {
+	args := m.Called(ctx, before)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockWebhookDeliveryRepository) DeleteByUserID(ctx context.Context, userID string) (int, error) {
+	args := m.Called(ctx, userID)
+	return args.Int(0), args.Error(1)
+}
+
+// MockWebhookSender はWebhookSenderのモック
+type MockWebhookSender struct {
+	mock.Mock
+}
+
+var _ service.WebhookSender = (*MockWebhookSender)(nil)
+
+func (m *MockWebhookSender) Send(ctx context.Context, subscription *model.WebhookSubscription, delivery *model.WebhookDelivery) (int, error) {
+	args := m.Called(ctx, subscription, delivery)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockWebhookSender) Forget(subscriptionID string) {
+	m.Called(subscriptionID)
+}
+
+func TestWebhookUsecaseImpl_CreateSubscription(t *testing.T) {
+	tests := []struct {
+		testName      string
+		input         *CreateWebhookInput
+		expectedError error
+	}{
+		{
+			testName: "シークレットを省略した場合は生成して購読を作成する",
+			input: &CreateWebhookInput{
+				ActorID:    "admin_1",
+				URL:        "https://example.com/hooks",
+				EventTypes: []model.DomainEventType{model.DomainEventUserRegistered},
+			},
+		},
+		{
+			testName: "指定したシークレットで購読を作成する",
+			input: &CreateWebhookInput{
+				ActorID:    "admin_1",
+				URL:        "https://example.com/hooks",
+				EventTypes: []model.DomainEventType{model.DomainEventUserRegistered},
+				Secret:     "0123456789abcdef",
+			},
+		},
+		{
+			testName: "送信先が不正な場合はエラー",
+			input: &CreateWebhookInput{
+				URL:        "example.com/hooks",
+				EventTypes: []model.DomainEventType{model.DomainEventUserRegistered},
+			},
+			expectedError: ErrInvalidWebhook,
+		},
+		{
+			testName: "httpの送信先はエラー",
+			input: &CreateWebhookInput{
+				URL:        "http://example.com/hooks",
+				EventTypes: []model.DomainEventType{model.DomainEventUserRegistered},
+			},
+			expectedError: ErrInvalidWebhook,
+		},
+		{
+			testName: "メタデータのアドレスへの送信はエラー",
+			input: &CreateWebhookInput{
+				URL:        "https://169.254.169.254/latest/meta-data",
+				EventTypes: []model.DomainEventType{model.DomainEventUserRegistered},
+			},
+			expectedError: ErrInvalidWebhook,
+		},
+		{
+			testName: "未定義のイベントを購読する場合はエラー",
+			input: &CreateWebhookInput{
+				URL:        "https://example.com/hooks",
+				EventTypes: []model.DomainEventType{"user.unknown"},
+			},
+			expectedError: ErrInvalidWebhook,
+		},
+		{
+			testName: "シークレットが短い場合はエラー",
+			input: &CreateWebhookInput{
+				URL:        "https://example.com/hooks",
+				EventTypes: []model.DomainEventType{model.DomainEventUserRegistered},
+				Secret:     "short",
+			},
+			expectedError: ErrInvalidWebhook,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			webhookRepo := new(MockWebhookRepository)
+			auditRepo := new(MockAuditRepository)
+			if tt.expectedError == nil {
+				webhookRepo.On("Save", mock.Anything, mock.AnythingOfType("*model.WebhookSubscription")).Return(nil)
+				auditRepo.On("Append", mock.Anything, mock.MatchedBy(func(event *model.AuditEvent) bool {
+					return event.Type == model.AuditEventWebhookChange &&
+						event.ActorID == "admin_1" &&
+						event.Metadata["action"] == "create" &&
+						event.Metadata["webhook_id"] != ""
+				})).Return(nil)
+			}
+
+			usecase := NewWebhookUsecase(webhookRepo, new(MockWebhookDeliveryRepository), auditRepo, nil, 3, false, 0)
+			subscription, err := usecase.CreateSubscription(context.Background(), tt.input)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, subscription)
+			} else {
+				require.NoError(t, err)
+				assert.NotEmpty(t, subscription.ID)
+				assert.True(t, subscription.Active)
+				assert.GreaterOrEqual(t, len(subscription.Secret), model.MinWebhookSecretLength)
+				if tt.input.Secret != "" {
+					assert.Equal(t, tt.input.Secret, subscription.Secret)
+				}
+			}
+			webhookRepo.AssertExpectations(t)
+			auditRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWebhookUsecaseImpl_HandleEvent(t *testing.T) {
+	event := &model.DomainEvent{ID: "event_1", Type: model.DomainEventUserRegistered, UserID: "user_1"}
+	subscriptions := []*model.WebhookSubscription{
+		{ID: "wh_1", EventTypes: []model.DomainEventType{model.DomainEventUserRegistered}, Active: true},
+		{ID: "wh_2", EventTypes: []model.DomainEventType{model.DomainEventUserLoggedIn}, Active: true},
+		{ID: "wh_3", EventTypes: []model.DomainEventType{model.DomainEventUserRegistered}, Active: false},
+	}
+
+	webhookRepo := new(MockWebhookRepository)
+	webhookRepo.On("FindAll", mock.Anything).Return(subscriptions, nil)
+	deliveryRepo := new(MockWebhookDeliveryRepository)
+	deliveryRepo.On("Save", mock.Anything, mock.MatchedBy(func(delivery *model.WebhookDelivery) bool {
+		return delivery.SubscriptionID == "wh_1" &&
+			delivery.EventID == "event_1" &&
+			delivery.Status == model.WebhookDeliveryPending &&
+			string(delivery.Payload) != ""
+	})).Return(true, nil).Once()
+
+	usecase := NewWebhookUsecase(webhookRepo, deliveryRepo, nil, nil, 3, false, 0)
+	err := usecase.HandleEvent(context.Background(), event)
+
+	require.NoError(t, err)
+	deliveryRepo.AssertExpectations(t)
+}
+
+func TestWebhookUsecaseImpl_DeliverDue(t *testing.T) {
+	active := &model.WebhookSubscription{ID: "wh_1", Active: true}
+	inactive := &model.WebhookSubscription{ID: "wh_1", Active: false}
+
+	tests := []struct {
+		testName          string
+		subscription      *model.WebhookSubscription
+		attempts          int
+		status            int
+		sendErr           error
+		expectSend        bool
+		expectedSucceeded int
+		expectedStatus    model.WebhookDeliveryStatus
+		expectedRetryIn   time.Duration
+	}{
+		{
+			testName:          "2xxが返った場合は成功とする",
+			subscription:      active,
+			status:            http.StatusOK,
+			expectSend:        true,
+			expectedSucceeded: 1,
+			expectedStatus:    model.WebhookDeliverySucceeded,
+		},
+		{
+			testName:        "2xx以外が返った場合は待ち時間を空けて再試行する",
+			subscription:    active,
+			attempts:        1,
+			status:          http.StatusInternalServerError,
+			expectSend:      true,
+			expectedStatus:  model.WebhookDeliveryPending,
+			expectedRetryIn: time.Minute,
+		},
+		{
+			testName:       "最大回数まで失敗した場合はデッドレターとする",
+			subscription:   active,
+			attempts:       2,
+			sendErr:        errors.New("connection refused"),
+			expectSend:     true,
+			expectedStatus: model.WebhookDeliveryDead,
+		},
+		{
+			testName:       "無効化された購読先には送信せずデッドレターとする",
+			subscription:   inactive,
+			expectedStatus: model.WebhookDeliveryDead,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			delivery := &model.WebhookDelivery{
+				ID:             "del_1",
+				SubscriptionID: "wh_1",
+				Status:         model.WebhookDeliveryPending,
+				Attempts:       tt.attempts,
+			}
+
+			webhookRepo := new(MockWebhookRepository)
+			webhookRepo.On("FindByID", mock.Anything, "wh_1").Return(tt.subscription, nil)
+			deliveryRepo := new(MockWebhookDeliveryRepository)
+			deliveryRepo.On("FindDue", mock.Anything, mock.Anything, webhookDeliveryBatchSize).Return([]*model.WebhookDelivery{delivery}, nil)
+			deliveryRepo.On("Update", mock.Anything, delivery).Return(nil)
+			sender := new(MockWebhookSender)
+			if tt.expectSend {
+				sender.On("Send", mock.Anything, tt.subscription, delivery).Return(tt.status, tt.sendErr)
+			}
+
+			usecase := NewWebhookUsecase(webhookRepo, deliveryRepo, nil, sender, 3, false, 0)
+			succeeded, err := usecase.DeliverDue(context.Background())
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedSucceeded, succeeded)
+			assert.Equal(t, tt.expectedStatus, delivery.Status)
+			if tt.expectedRetryIn > 0 {
+				retryIn := time.Until(delivery.NextAttemptAt)
+				assert.True(t, retryIn > tt.expectedRetryIn-time.Second && retryIn <= tt.expectedRetryIn, "retry in %s", retryIn)
+			}
+			sender.AssertExpectations(t)
+			deliveryRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWebhookUsecaseImpl_ReplayDelivery(t *testing.T) {
+	tests := []struct {
+		testName      string
+		subscription  *model.WebhookSubscription
+		delivery      *model.WebhookDelivery
+		expectedError error
+	}{
+		{
+			testName:     "デッドレターの配信を新しい配信として再送する",
+			subscription: &model.WebhookSubscription{ID: "wh_1", Active: true},
+			delivery:     &model.WebhookDelivery{ID: "del_1", SubscriptionID: "wh_1", EventID: "event_1", Status: model.WebhookDeliveryDead, Attempts: 3},
+		},
+		{
+			testName:      "配信待ちの配信は再送できない",
+			subscription:  &model.WebhookSubscription{ID: "wh_1", Active: true},
+			delivery:      &model.WebhookDelivery{ID: "del_1", SubscriptionID: "wh_1", Status: model.WebhookDeliveryPending},
+			expectedError: ErrWebhookDeliveryPending,
+		},
+		{
+			testName:      "他の購読の配信は見つからない",
+			subscription:  &model.WebhookSubscription{ID: "wh_1", Active: true},
+			delivery:      &model.WebhookDelivery{ID: "del_1", SubscriptionID: "wh_2", Status: model.WebhookDeliveryDead},
+			expectedError: repository.ErrWebhookDeliveryNotFound,
+		},
+		{
+			testName:      "無効化された購読には再送できない",
+			subscription:  &model.WebhookSubscription{ID: "wh_1", Active: false},
+			expectedError: ErrWebhookInactive,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			webhookRepo := new(MockWebhookRepository)
+			webhookRepo.On("FindByID", mock.Anything, "wh_1").Return(tt.subscription, nil)
+			deliveryRepo := new(MockWebhookDeliveryRepository)
+			if tt.delivery != nil {
+				deliveryRepo.On("FindByID", mock.Anything, "del_1").Return(tt.delivery, nil)
+			}
+			auditRepo := new(MockAuditRepository)
+			if tt.expectedError == nil {
+				deliveryRepo.On("Save", mock.Anything, mock.MatchedBy(func(delivery *model.WebhookDelivery) bool {
+					return delivery.ReplayOf == "del_1" && delivery.Status == model.WebhookDeliveryPending && delivery.Attempts == 0
+				})).Return(true, nil)
+				auditRepo.On("Append", mock.Anything, mock.MatchedBy(func(event *model.AuditEvent) bool {
+					return event.Type == model.AuditEventWebhookChange &&
+						event.Metadata["action"] == "replay" &&
+						event.Metadata["delivery_id"] == "del_1"
+				})).Return(nil)
+			}
+
+			usecase := NewWebhookUsecase(webhookRepo, deliveryRepo, auditRepo, nil, 3, false, 0)
+			replay, err := usecase.ReplayDelivery(context.Background(), &ReplayWebhookDeliveryInput{
+				ActorID:        "admin_1",
+				SubscriptionID: "wh_1",
+				DeliveryID:     "del_1",
+			})
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, replay)
+			} else {
+				require.NoError(t, err)
+				assert.NotEqual(t, "del_1", replay.ID)
+				assert.Equal(t, "event_1", replay.EventID)
+			}
+			deliveryRepo.AssertExpectations(t)
+			auditRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWebhookUsecaseImpl_DeleteSubscription(t *testing.T) {
+	webhookRepo := new(MockWebhookRepository)
+	webhookRepo.On("Delete", mock.Anything, "wh_1").Return(nil)
+	deliveryRepo := new(MockWebhookDeliveryRepository)
+	deliveryRepo.On("DeleteBySubscriptionID", mock.Anything, "wh_1").Return(nil)
+	sender := new(MockWebhookSender)
+	sender.On("Forget", "wh_1").Return()
+
+	usecase := NewWebhookUsecase(webhookRepo, deliveryRepo, nil, sender, 3, false, 0)
+	err := usecase.DeleteSubscription(context.Background(), &DeleteWebhookInput{ActorID: "admin_1", ID: "wh_1"})
+
+	require.NoError(t, err)
+	webhookRepo.AssertExpectations(t)
+	deliveryRepo.AssertExpectations(t)
+	// 削除した購読のHTTPクライアントを破棄する
+	sender.AssertExpectations(t)
+}
+
+func TestWebhookUsecaseImpl_PurgeDeliveries(t *testing.T) {
+	t.Run("保持期間より前に成功した配信を削除する", func(t *testing.T) {
+		deliveryRepo := new(MockWebhookDeliveryRepository)
+		deliveryRepo.On("DeleteSucceededBefore", mock.Anything, mock.MatchedBy(func(before time.Time) bool {
+			return time.Until(before) < -23*time.Hour && time.Until(before) > -25*time.Hour
+		})).Return(2, nil)
+
+		usecase := NewWebhookUsecase(new(MockWebhookRepository), deliveryRepo, nil, nil, 3, false, 24*time.Hour)
+		purged, err := usecase.PurgeDeliveries(context.Background())
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, purged)
+		deliveryRepo.AssertExpectations(t)
+	})
+
+	t.Run("保持期間が0の場合は削除しない", func(t *testing.T) {
+		usecase := NewWebhookUsecase(new(MockWebhookRepository), new(MockWebhookDeliveryRepository), nil, nil, 3, false, 0)
+		purged, err := usecase.PurgeDeliveries(context.Background())
+
+		require.NoError(t, err)
+		assert.Zero(t, purged)
+	})
+}
+
+func TestWebhookRetryDelay(t *testing.T) {
+	assert.Equal(t, 30*time.Second, webhookRetryDelay(0))
+	assert.Equal(t, 4*time.Minute, webhookRetryDelay(3))
+	assert.Equal(t, time.Hour, webhookRetryDelay(100))
+}
@@ -61,10 +61,14 @@ describe('Auth Store', () => {
               id: 'test-id',
               email: 'test@example.com',
               name: 'Test User',
-              picture: 'https://example.com/picture.jpg'
+              picture: 'https://example.com/picture.jpg',
+              role: 'user',
+              created_at: '2025-01-01T00:00:00Z',
+              updated_at: '2025-01-01T00:00:00Z'
             },
             accessToken: 'access-token',
-            refreshToken: 'refresh-token'
+            refreshToken: 'refresh-token',
+            expiresIn: 1735693200
           }), 100))
         )
 
@@ -88,13 +92,17 @@ describe('Auth Store', () => {
           id: 'test-id',
           email: 'test@example.com',
           name: 'Test User',
-          picture: 'https://example.com/picture.jpg'
+          picture: 'https://example.com/picture.jpg',
+          role: 'user',
+          created_at: '2025-01-01T00:00:00Z',
+          updated_at: '2025-01-01T00:00:00Z'
         }
 
         vi.mocked(authClient.login).mockResolvedValue({
           user: mockUser,
           accessToken: 'access-token',
-          refreshToken: 'refresh-token'
+          refreshToken: 'refresh-token',
+          expiresIn: 1735693200
         })
 
         const loginRequest: LoginRequest = {
@@ -120,10 +128,14 @@ describe('Auth Store', () => {
             id: 'test-id',
             email: 'test@example.com',
             name: 'Test User',
-            picture: 'https://example.com/picture.jpg'
+            picture: 'https://example.com/picture.jpg',
+            role: 'user',
+            created_at: '2025-01-01T00:00:00Z',
+            updated_at: '2025-01-01T00:00:00Z'
           },
           accessToken: 'access-token',
-          refreshToken: 'refresh-token'
+          refreshToken: 'refresh-token',
+          expiresIn: 1735693200
         })
 
         const loginRequest: LoginRequest = {
@@ -169,7 +181,10 @@ describe('Auth Store', () => {
           id: 'test-id',
           email: 'test@example.com',
           name: 'Test User',
-          picture: 'https://example.com/picture.jpg'
+          picture: 'https://example.com/picture.jpg',
+          role: 'user',
+          created_at: '2025-01-01T00:00:00Z',
+          updated_at: '2025-01-01T00:00:00Z'
         }
         store.accessToken = 'access-token'
         store.refreshToken = 'refresh-token'
@@ -214,7 +229,10 @@ describe('Auth Store', () => {
           id: 'test-id',
           email: 'test@example.com',
           name: 'Test User',
-          picture: 'https://example.com/picture.jpg'
+          picture: 'https://example.com/picture.jpg',
+          role: 'user',
+          created_at: '2025-01-01T00:00:00Z',
+          updated_at: '2025-01-01T00:00:00Z'
         })
 
         await store.initializeAuth()
@@ -17,10 +17,14 @@ describe('Auth API Client', () => {
           id: 'test-id',
           email: 'test@example.com',
           name: 'Test User',
-          picture: 'https://example.com/picture.jpg'
+          picture: 'https://example.com/picture.jpg',
+          role: 'user',
+          created_at: '2025-01-01T00:00:00Z',
+          updated_at: '2025-01-01T00:00:00Z'
         },
         accessToken: 'access-token',
-        refreshToken: 'refresh-token'
+        refreshToken: 'refresh-token',
+        expiresIn: 1735693200
       }
 
       const mockFetch = vi.mocked(fetch)
@@ -1,9 +1,10 @@
 import type { LoginRequest, LoginResponse, RefreshTokenRequest, RefreshTokenResponse, User } from '../auth/types'
+import { operations } from './schema.gen'
 
 class AuthClient {
   async login(request: LoginRequest): Promise<LoginResponse> {
-    const response = await fetch(import.meta.env.VITE_APP_API_BASE_URL + '/auth/google/login', {
-      method: 'POST',
+    const response = await fetch(import.meta.env.VITE_APP_API_BASE_URL + operations.googleLogin.path, {
+      method: operations.googleLogin.method,
       headers: {
         'Content-Type': 'application/json'
       },
@@ -20,8 +21,8 @@ class AuthClient {
   }
 
   async refreshToken(request: RefreshTokenRequest): Promise<RefreshTokenResponse> {
-    const response = await fetch(import.meta.env.VITE_APP_API_BASE_URL + '/auth/refresh', {
-      method: 'POST',
+    const response = await fetch(import.meta.env.VITE_APP_API_BASE_URL + operations.refreshToken.path, {
+      method: operations.refreshToken.method,
       headers: {
         'Content-Type': 'application/json'
       },
@@ -36,8 +37,8 @@ class AuthClient {
   }
 
   async getUser(accessToken: string): Promise<User> {
-    const response = await fetch(import.meta.env.VITE_APP_API_BASE_URL + '/auth/me', {
-      method: 'GET',
+    const response = await fetch(import.meta.env.VITE_APP_API_BASE_URL + operations.getMe.path, {
+      method: operations.getMe.method,
       headers: {
         'Authorization': `Bearer ${accessToken}`,
         'Content-Type': 'application/json'
@@ -52,8 +53,8 @@ class AuthClient {
   }
 
   async logout(accessToken: string): Promise<void> {
-    const response = await fetch(import.meta.env.VITE_APP_API_BASE_URL + '/auth/logout', {
-      method: 'POST',
+    const response = await fetch(import.meta.env.VITE_APP_API_BASE_URL + operations.logout.path, {
+      method: operations.logout.method,
       headers: {
         'Authorization': `Bearer ${accessToken}`,
         'Content-Type': 'application/json'
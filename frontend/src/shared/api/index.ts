@@ -1 +1,2 @@
-export * from './authClient'
\ No newline at end of file
+export * from './authClient'
+export * from './schema.gen'
\ No newline at end of file
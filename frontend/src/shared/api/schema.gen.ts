@@ -0,0 +1,706 @@
+// Code generated by `go run . openapi-ts` from backend/presentation/openapi/openapi.yaml. DO NOT EDIT.
+
+export interface AccountDeletion {
+  /** データを完全に消去する日時 */
+  purge_at: string
+}
+
+export interface AuditEvent {
+  actor_id?: string
+  created_at: string
+  id: string
+  ip_address?: string
+  metadata?: Record<string, string>
+  outcome: AuditOutcome
+  reason?: string
+  subject_id?: string
+  type: AuditEventType
+  user_agent?: string
+}
+
+export interface AuditEventPage {
+  events: AuditEvent[] | null
+  next_cursor?: string
+}
+
+export type AuditEventType = 'login' | 'token_refresh' | 'logout' | 'token_reuse' | 'role_change' | 'session_revoke' | 'user_status_change' | 'account_delete' | 'account_purge' | 'data_export' | 'impersonation_start' | 'impersonated_request' | 'webhook_change' | 'scim_provision'
+
+export type AuditOutcome = 'success' | 'failure'
+
+export interface ChangeRoleRequest {
+  role: Role
+}
+
+export interface ChangeStatusRequest {
+  /** 停止・無効化の理由（suspended・disabledの場合は必須） */
+  reason?: string
+  status: UserStatus
+  /** 一時停止の期限（suspendedの場合のみ指定でき、省略した場合は無期限） */
+  until?: string
+}
+
+export interface CreateWebhookRequest {
+  description?: string
+  event_types: DomainEventType[]
+  /** 署名に使うシークレット（省略した場合は生成する） */
+  secret?: string
+  /** 送信先のhttpsの絶対URL（ループバック・プライベート・リンクローカルのアドレスは不可。開発環境ではhttpとこれらのアドレスも許可する） */
+  url: string
+}
+
+export interface DataExport {
+  completed_at?: string
+  expires_at?: string
+  id: string
+  requested_at: string
+  status: DataExportStatus
+}
+
+export type DataExportStatus = 'pending' | 'ready' | 'failed'
+
+export type DomainEventType = 'user.registered' | 'user.logged_in' | 'session.revoked' | 'profile.updated' | 'user.role_changed' | 'user.status_changed'
+
+export interface ExchangeLoginCodeRequest {
+  code: string
+}
+
+export interface GoogleAuthURLResponse {
+  auth_url: string
+  state: string
+}
+
+export interface GoogleLoginRequest {
+  code: string
+  state: string
+}
+
+export interface GoogleLoginResponse {
+  accessToken: string
+  /** アクセストークンの有効期限（Unix時間） */
+  expiresIn: number
+  refreshToken: string
+  user: User
+}
+
+export interface HealthCheckResult {
+  name: string
+  status: HealthStatus
+}
+
+/** 認証なしで公開するため状態とチェック名のみを返す（失敗の理由はサーバーのログに出力する） */
+export interface HealthReport {
+  checks: HealthCheckResult[]
+  status: HealthStatus
+}
+
+export type HealthStatus = 'up' | 'down'
+
+export interface ImpersonateRequest {
+  /** なりすましの理由（サポートの問い合わせ番号など） */
+  reason: string
+}
+
+export interface ImpersonationResponse {
+  access_token: string
+  expires_at: string
+  token_type: string
+  user: User
+}
+
+export interface MessageResponse {
+  message: string
+}
+
+export interface Problem {
+  /** エラーの種類（invalid_token, validation_failedなど） */
+  code: string
+  detail?: string
+  instance?: string
+  status: number
+  title: string
+  type: string
+}
+
+/** ログインに使うIDプロバイダー */
+export type Provider = 'google'
+
+export interface PublicProfile {
+  id: string
+  name: string
+  picture: string
+}
+
+export interface RefreshTokenRequest {
+  refreshToken?: string
+}
+
+export interface RefreshTokenResponse {
+  accessToken: string
+  expiresIn: number
+  refreshToken: string
+}
+
+export interface ReportNotMeRequest {
+  token: string
+}
+
+export type Role = 'user' | 'admin'
+
+export interface SCIMError {
+  detail?: string
+  schemas: string[]
+  scimType?: string
+  /** HTTPのステータスコード（文字列） */
+  status: string
+}
+
+export interface SCIMGroup {
+  displayName: string
+  externalId?: string
+  id: string
+  members?: {
+    display?: string
+    /** ユーザーのID */
+    value: string
+  }[]
+  meta: SCIMMeta
+  schemas: string[]
+}
+
+export interface SCIMGroupList {
+  Resources: SCIMGroup[]
+  itemsPerPage: number
+  schemas: string[]
+  startIndex: number
+  totalResults: number
+}
+
+export interface SCIMMeta {
+  created: string
+  lastModified: string
+  location: string
+  resourceType: 'User' | 'Group'
+}
+
+export interface SCIMPatchRequest {
+  Operations: {
+    /** add・replace・remove（大文字・小文字を区別しない） */
+    op: string
+    path?: string
+    value?: Record<string, unknown>
+  }[]
+  schemas?: string[]
+}
+
+export interface SCIMUser {
+  /** 停止・無効化されていない場合にtrue（本人のログインを待っているユーザーを含む） */
+  active: boolean
+  displayName: string
+  emails: {
+    primary?: boolean
+    type?: string
+    value: string
+  }[]
+  externalId?: string
+  id: string
+  meta: SCIMMeta
+  name: {
+    formatted?: string
+  }
+  schemas: string[]
+  /** メールアドレス */
+  userName: string
+}
+
+export interface SCIMUserList {
+  Resources: SCIMUser[]
+  itemsPerPage: number
+  schemas: string[]
+  startIndex: number
+  totalResults: number
+}
+
+export interface UpdateProfileRequest {
+  name?: string
+  /** httpまたはhttpsの絶対URL（空文字の場合は画像を使わない）。javascript:やdata:などは400になる */
+  picture?: string
+}
+
+export interface UpdateWebhookRequest {
+  active?: boolean
+  description?: string
+  event_types?: DomainEventType[]
+  /** シークレットのローテーションに使う */
+  secret?: string
+  url?: string
+}
+
+export interface UploadAvatarRequest {
+  file: string
+}
+
+export interface User {
+  created_at: string
+  email: string
+  id: string
+  name: string
+  picture: string
+  provider: Provider
+  role: Role
+  status: UserStatus
+  status_changed_at?: string
+  /** 停止・無効化の理由 */
+  status_reason?: string
+  /** 一時停止の期限（省略した場合は無期限） */
+  suspended_until?: string
+  updated_at: string
+}
+
+export interface UserPage {
+  next_cursor?: string
+  users: User[]
+}
+
+/**
+ * アカウントの状態。suspended・disabledのユーザーはログイン・APIの利用ができない（403）。
+ * 期限を過ぎた一時停止は自動的にactiveとして扱う。
+ */
+export type UserStatus = 'active' | 'suspended' | 'disabled' | 'pending_verification'
+
+export interface Webhook {
+  active: boolean
+  created_at: string
+  description?: string
+  event_types: DomainEventType[]
+  id: string
+  updated_at: string
+  url: string
+}
+
+export interface WebhookDelivery {
+  /** 送信を試みた回数 */
+  attempts: number
+  created_at: string
+  delivered_at?: string
+  event_id: string
+  event_type: DomainEventType
+  id: string
+  last_error?: string
+  next_attempt_at: string
+  /** 再送元の配信のID */
+  replay_of?: string
+  /** 最後の送信で送信先が返したステータスコード */
+  response_status?: number
+  status: WebhookDeliveryStatus
+  subscription_id: string
+}
+
+export interface WebhookDeliveryPage {
+  deliveries: WebhookDelivery[]
+  next_cursor?: string
+}
+
+export type WebhookDeliveryStatus = 'pending' | 'succeeded' | 'dead'
+
+export interface WebhookList {
+  webhooks: Webhook[] | null
+}
+
+export type WebhookWithSecret = Record<string, unknown>
+
+/** オペレーションごとのパス・クエリパラメータ、リクエストボディ、成功時のレスポンス */
+export interface Operations {
+  changeRole: {
+    path: {
+      id: string
+    }
+    query: never
+    body: ChangeRoleRequest
+    response: User
+  }
+  changeUserStatus: {
+    path: {
+      id: string
+    }
+    query: never
+    body: ChangeStatusRequest
+    response: User
+  }
+  createSCIMGroup: {
+    path: never
+    query: never
+    body: Record<string, unknown>
+    response: void
+  }
+  createSCIMUser: {
+    path: never
+    query: never
+    body: Record<string, unknown>
+    response: void
+  }
+  createWebhook: {
+    path: never
+    query: never
+    body: CreateWebhookRequest
+    response: WebhookWithSecret
+  }
+  deleteAvatar: {
+    path: never
+    query: never
+    body: never
+    response: User
+  }
+  deleteMe: {
+    path: never
+    query: never
+    body: never
+    response: AccountDeletion
+  }
+  deleteSCIMGroup: {
+    path: never
+    query: never
+    body: never
+    response: void
+  }
+  deleteSCIMUser: {
+    path: never
+    query: never
+    body: never
+    response: void
+  }
+  deleteWebhook: {
+    path: {
+      id: string
+    }
+    query: never
+    body: never
+    response: void
+  }
+  exchangeLoginCode: {
+    path: never
+    query: never
+    body: ExchangeLoginCodeRequest
+    response: GoogleLoginResponse
+  }
+  exportMe: {
+    path: never
+    query: never
+    body: never
+    response: DataExport
+  }
+  getAvatar: {
+    path: {
+      id: string
+    }
+    query: {
+      size?: number
+      v?: string
+    }
+    body: never
+    response: void
+  }
+  getMe: {
+    path: never
+    query: never
+    body: never
+    response: User
+  }
+  getOpenAPI: {
+    path: never
+    query: never
+    body: never
+    response: Record<string, unknown>
+  }
+  getSCIMGroup: {
+    path: never
+    query: {
+      excludedAttributes?: string
+    }
+    body: never
+    response: void
+  }
+  getSCIMUser: {
+    path: never
+    query: never
+    body: never
+    response: void
+  }
+  getUserProfile: {
+    path: {
+      id: string
+    }
+    query: never
+    body: never
+    response: PublicProfile
+  }
+  getWebhook: {
+    path: {
+      id: string
+    }
+    query: never
+    body: never
+    response: Webhook
+  }
+  googleAuthURL: {
+    path: never
+    query: {
+      redirect?: 'true' | 'false'
+      redirect_url?: string
+    }
+    body: never
+    response: GoogleAuthURLResponse
+  }
+  googleCallback: {
+    path: never
+    query: {
+      code?: string
+      error?: string
+      state?: string
+    }
+    body: never
+    response: void
+  }
+  googleLogin: {
+    path: never
+    query: never
+    body: GoogleLoginRequest
+    response: GoogleLoginResponse
+  }
+  health: {
+    path: never
+    query: never
+    body: never
+    response: HealthReport
+  }
+  impersonateUser: {
+    path: {
+      id: string
+    }
+    query: never
+    body: ImpersonateRequest
+    response: ImpersonationResponse
+  }
+  listAuditEvents: {
+    path: never
+    query: {
+      actor_id?: string
+      cursor?: string
+      from?: string
+      limit?: number
+      outcome?: AuditOutcome
+      to?: string
+      type?: AuditEventType
+      user_id?: string
+    }
+    body: never
+    response: AuditEventPage
+  }
+  listMyAuditEvents: {
+    path: never
+    query: {
+      cursor?: string
+      from?: string
+      limit?: number
+      outcome?: AuditOutcome
+      to?: string
+      type?: AuditEventType
+    }
+    body: never
+    response: AuditEventPage
+  }
+  listSCIMGroups: {
+    path: never
+    query: {
+      count?: number
+      excludedAttributes?: string
+      filter?: string
+      startIndex?: number
+    }
+    body: never
+    response: void
+  }
+  listSCIMUsers: {
+    path: never
+    query: {
+      count?: number
+      filter?: string
+      startIndex?: number
+    }
+    body: never
+    response: void
+  }
+  listUsers: {
+    path: never
+    query: {
+      created_from?: string
+      created_to?: string
+      cursor?: string
+      limit?: number
+      provider?: Provider
+      q?: string
+      role?: Role
+      sort?: 'created_at' | '-created_at' | 'email' | '-email' | 'name' | '-name'
+      status?: UserStatus
+    }
+    body: never
+    response: UserPage
+  }
+  listWebhookDeliveries: {
+    path: {
+      id: string
+    }
+    query: {
+      cursor?: string
+      limit?: number
+      status?: WebhookDeliveryStatus
+    }
+    body: never
+    response: WebhookDeliveryPage
+  }
+  listWebhooks: {
+    path: never
+    query: never
+    body: never
+    response: WebhookList
+  }
+  livez: {
+    path: never
+    query: never
+    body: never
+    response: HealthReport
+  }
+  logout: {
+    path: never
+    query: never
+    body: never
+    response: MessageResponse
+  }
+  metrics: {
+    path: never
+    query: never
+    body: never
+    response: string
+  }
+  patchSCIMGroup: {
+    path: never
+    query: never
+    body: SCIMPatchRequest
+    response: void
+  }
+  patchSCIMUser: {
+    path: never
+    query: never
+    body: SCIMPatchRequest
+    response: void
+  }
+  readyz: {
+    path: never
+    query: never
+    body: never
+    response: HealthReport
+  }
+  refreshToken: {
+    path: never
+    query: never
+    body: RefreshTokenRequest | undefined
+    response: RefreshTokenResponse
+  }
+  replaceSCIMGroup: {
+    path: never
+    query: never
+    body: Record<string, unknown>
+    response: void
+  }
+  replaceSCIMUser: {
+    path: never
+    query: never
+    body: Record<string, unknown>
+    response: void
+  }
+  replayWebhookDelivery: {
+    path: {
+      deliveryId: string
+      id: string
+    }
+    query: never
+    body: never
+    response: WebhookDelivery
+  }
+  reportNotMe: {
+    path: never
+    query: never
+    body: ReportNotMeRequest
+    response: MessageResponse
+  }
+  updateMe: {
+    path: never
+    query: never
+    body: UpdateProfileRequest
+    response: User
+  }
+  updateWebhook: {
+    path: {
+      id: string
+    }
+    query: never
+    body: UpdateWebhookRequest
+    response: Webhook
+  }
+  uploadAvatar: {
+    path: never
+    query: never
+    body: never
+    response: User
+  }
+}
+
+export type OperationId = keyof Operations
+
+/** オペレーションごとのHTTPメソッドとパス（パスパラメータは{name}の形式） */
+export const operations = {
+  changeRole: { method: 'PUT', path: '/admin/users/{id}/role' },
+  changeUserStatus: { method: 'PUT', path: '/admin/users/{id}/status' },
+  createSCIMGroup: { method: 'POST', path: '/scim/v2/Groups' },
+  createSCIMUser: { method: 'POST', path: '/scim/v2/Users' },
+  createWebhook: { method: 'POST', path: '/admin/webhooks' },
+  deleteAvatar: { method: 'DELETE', path: '/users/me/avatar' },
+  deleteMe: { method: 'DELETE', path: '/users/me' },
+  deleteSCIMGroup: { method: 'DELETE', path: '/scim/v2/Groups/{id}' },
+  deleteSCIMUser: { method: 'DELETE', path: '/scim/v2/Users/{id}' },
+  deleteWebhook: { method: 'DELETE', path: '/admin/webhooks/{id}' },
+  exchangeLoginCode: { method: 'POST', path: '/auth/google/exchange' },
+  exportMe: { method: 'GET', path: '/users/me/export' },
+  getAvatar: { method: 'GET', path: '/users/{id}/avatar' },
+  getMe: { method: 'GET', path: '/auth/me' },
+  getOpenAPI: { method: 'GET', path: '/openapi.json' },
+  getSCIMGroup: { method: 'GET', path: '/scim/v2/Groups/{id}' },
+  getSCIMUser: { method: 'GET', path: '/scim/v2/Users/{id}' },
+  getUserProfile: { method: 'GET', path: '/users/{id}' },
+  getWebhook: { method: 'GET', path: '/admin/webhooks/{id}' },
+  googleAuthURL: { method: 'GET', path: '/auth/google/url' },
+  googleCallback: { method: 'GET', path: '/auth/google/callback' },
+  googleLogin: { method: 'POST', path: '/auth/google/login' },
+  health: { method: 'GET', path: '/health' },
+  impersonateUser: { method: 'POST', path: '/admin/users/{id}/impersonate' },
+  listAuditEvents: { method: 'GET', path: '/admin/audit-events' },
+  listMyAuditEvents: { method: 'GET', path: '/users/me/audit-events' },
+  listSCIMGroups: { method: 'GET', path: '/scim/v2/Groups' },
+  listSCIMUsers: { method: 'GET', path: '/scim/v2/Users' },
+  listUsers: { method: 'GET', path: '/admin/users' },
+  listWebhookDeliveries: { method: 'GET', path: '/admin/webhooks/{id}/deliveries' },
+  listWebhooks: { method: 'GET', path: '/admin/webhooks' },
+  livez: { method: 'GET', path: '/livez' },
+  logout: { method: 'POST', path: '/auth/logout' },
+  metrics: { method: 'GET', path: '/metrics' },
+  patchSCIMGroup: { method: 'PATCH', path: '/scim/v2/Groups/{id}' },
+  patchSCIMUser: { method: 'PATCH', path: '/scim/v2/Users/{id}' },
+  readyz: { method: 'GET', path: '/readyz' },
+  refreshToken: { method: 'POST', path: '/auth/refresh' },
+  replaceSCIMGroup: { method: 'PUT', path: '/scim/v2/Groups/{id}' },
+  replaceSCIMUser: { method: 'PUT', path: '/scim/v2/Users/{id}' },
+  replayWebhookDelivery: { method: 'POST', path: '/admin/webhooks/{id}/deliveries/{deliveryId}/replay' },
+  reportNotMe: { method: 'POST', path: '/auth/not-me' },
+  updateMe: { method: 'PATCH', path: '/users/me' },
+  updateWebhook: { method: 'PATCH', path: '/admin/webhooks/{id}' },
+  uploadAvatar: { method: 'POST', path: '/users/me/avatar' },
+} as const satisfies Record<OperationId, { method: string; path: string }>
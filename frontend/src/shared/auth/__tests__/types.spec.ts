@@ -8,7 +8,10 @@ describe('Auth Types', () => {
         id: 'test-id',
         email: 'test@example.com',
         name: 'Test User',
-        picture: 'https://example.com/picture.jpg'
+        picture: 'https://example.com/picture.jpg',
+        role: 'user',
+        created_at: '2025-01-01T00:00:00Z',
+        updated_at: '2025-01-01T00:00:00Z'
       }
 
       expect(user.id).toBe('test-id')
@@ -40,7 +43,10 @@ describe('Auth Types', () => {
         id: 'test-id',
         email: 'test@example.com',
         name: 'Test User',
-        picture: 'https://example.com/picture.jpg'
+        picture: 'https://example.com/picture.jpg',
+        role: 'user',
+        created_at: '2025-01-01T00:00:00Z',
+        updated_at: '2025-01-01T00:00:00Z'
       }
 
       const authState: AuthState = {
@@ -75,13 +81,17 @@ describe('Auth Types', () => {
         id: 'test-id',
         email: 'test@example.com',
         name: 'Test User',
-        picture: 'https://example.com/picture.jpg'
+        picture: 'https://example.com/picture.jpg',
+        role: 'user',
+        created_at: '2025-01-01T00:00:00Z',
+        updated_at: '2025-01-01T00:00:00Z'
       }
 
       const loginResponse: LoginResponse = {
         user,
         accessToken: 'access-token',
-        refreshToken: 'refresh-token'
+        refreshToken: 'refresh-token',
+        expiresIn: 1735693200
       }
 
       expect(loginResponse.user).toBe(user)
@@ -1,9 +1,13 @@
-export interface User {
-  id: string
-  email: string
-  name: string
-  picture: string
-}
+import type {
+  GoogleLoginRequest,
+  GoogleLoginResponse,
+  RefreshTokenRequest as ApiRefreshTokenRequest,
+  RefreshTokenResponse as ApiRefreshTokenResponse,
+  User as ApiUser
+} from '../api/schema.gen'
+
+// APIの型はバックエンドのOpenAPIドキュメントから生成した型を使用する
+export type User = ApiUser
 
 export interface AuthState {
   user: User | null
@@ -13,25 +17,13 @@ export interface AuthState {
   error: string | null
 }
 
-export interface LoginRequest {
-  code: string
-  state: string
-}
+export type LoginRequest = GoogleLoginRequest
 
-export interface LoginResponse {
-  user: User
-  accessToken: string
-  refreshToken: string
-}
+export type LoginResponse = GoogleLoginResponse
 
-export interface RefreshTokenRequest {
-  refreshToken: string
-}
+export type RefreshTokenRequest = Required<ApiRefreshTokenRequest>
 
-export interface RefreshTokenResponse {
-  accessToken: string
-  refreshToken: string
-}
+export type RefreshTokenResponse = ApiRefreshTokenResponse
 
 // Google OAuth関連の型定義
 export interface GoogleOAuthResponse {